@@ -0,0 +1,204 @@
+package tinkerdown
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/runtime"
+)
+
+var (
+	// actionAttrRegex matches event attributes whose value names an action.
+	actionAttrRegex = regexp.MustCompile(`\blvt-(?:click|submit|click-away|on:[a-z]+|window-[a-z]+)="([^"]*)"`)
+	// namedActionRegex matches buttons and forms, whose name attribute is the action.
+	namedActionRegex = regexp.MustCompile(`<(?:button|form)\b[^>]*?\sname="([^"]*)"`)
+	// actionListRegex matches lvt-actions, a comma-separated list of row actions
+	// with optional labels ("edit:Edit,delete:×").
+	actionListRegex = regexp.MustCompile(`\blvt-actions="([^"]*)"`)
+)
+
+// CheckActionRefs checks the actions triggered from lvt blocks against the
+// actions the runtime handles for the block's sources (see
+// runtime.BuiltinActions) and the custom actions declared in the page's
+// frontmatter or sharedActions. Blocks without an lvt-source are skipped, as
+// are template expressions and exec: commands.
+func CheckActionRefs(content []byte, shared map[string]SourceConfig, sharedActions []string) []SourceRefIssue {
+	fm, body, err := extractFrontmatter(content)
+	if err != nil {
+		return nil
+	}
+	lineOffset := strings.Count(string(content[:len(content)-len(body)]), "\n")
+
+	declared := make(map[string]SourceConfig)
+	for name, cfg := range shared {
+		declared[name] = cfg
+	}
+	for name, cfg := range fm.Sources {
+		declared[name] = cfg
+	}
+	custom := append([]string(nil), sharedActions...)
+	for name := range fm.Actions {
+		custom = append(custom, name)
+	}
+
+	var issues []SourceRefIssue
+	for _, block := range lvtFences(string(body)) {
+		var sources []string
+		for _, m := range lvtSourceTagRegex.FindAllStringSubmatch(block.text, -1) {
+			if _, ok := declared[m[1]]; ok && !containsFlag(sources, m[1]) {
+				sources = append(sources, m[1])
+			}
+		}
+		if len(sources) == 0 {
+			continue
+		}
+
+		for _, ref := range actionRefs(block.text) {
+			if ref.name == "" || strings.Contains(ref.name, "{{") || strings.HasPrefix(ref.name, "exec:") ||
+				containsFlag(custom, ref.name) || actionHandled(ref.name, sources, declared, false) {
+				continue
+			}
+
+			line := lineOffset + block.line + ref.line
+			if actionHandled(ref.name, sources, declared, true) {
+				issues = append(issues, SourceRefIssue{
+					Line:    line,
+					Message: fmt.Sprintf("action %q can't write to read-only %s", ref.name, describeSources(sources)),
+					Hint:    "set readonly: false on the source",
+				})
+				continue
+			}
+
+			if runtime.IsBuiltinAction(ref.name, "sqlite", false) {
+				issues = append(issues, SourceRefIssue{
+					Line:    line,
+					Message: fmt.Sprintf("action %q can't write to %s", ref.name, describeSources(sources)),
+					Hint:    "only markdown and sqlite sources support writes",
+				})
+				continue
+			}
+
+			known := knownActions(sources, declared, custom)
+			hint := "available actions: " + strings.Join(known, ", ")
+			if n := ClosestName(ref.name, known); n != "" {
+				hint = fmt.Sprintf("did you mean %q?", n)
+			}
+			issues = append(issues, SourceRefIssue{
+				Line:    line,
+				Message: fmt.Sprintf("unknown action %q for %s", ref.name, describeSources(sources)),
+				Hint:    hint,
+			})
+		}
+	}
+	return issues
+}
+
+// lvtFence is the content of a fenced lvt block.
+type lvtFence struct {
+	line int // Line of the block's first content line within the body (1-indexed)
+	text string
+}
+
+// lvtFences returns the fenced lvt blocks in a markdown body.
+func lvtFences(body string) []lvtFence {
+	var fences []lvtFence
+	var current []string
+	start, inFence, keep := 0, false, false
+	for i, line := range strings.Split(body, "\n") {
+		if codeFenceRegex.MatchString(line) {
+			if !inFence {
+				info := strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "`~"))
+				keep = len(info) > 0 && (info[0] == "lvt" || containsFlag(info[1:], "lvt"))
+				start, current = i+2, nil
+			} else if keep {
+				fences = append(fences, lvtFence{line: start, text: strings.Join(current, "\n")})
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence && keep {
+			current = append(current, line)
+		}
+	}
+	return fences
+}
+
+// actionRef is an action named in a template, with its 0-based line offset.
+type actionRef struct {
+	name string
+	line int
+}
+
+// actionRefs returns the actions named in an lvt template, in order.
+func actionRefs(text string) []actionRef {
+	type found struct {
+		pos  int
+		name string
+	}
+	var all []found
+	for _, re := range []*regexp.Regexp{actionAttrRegex, namedActionRegex} {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			all = append(all, found{m[2], text[m[2]:m[3]]})
+		}
+	}
+	for _, m := range actionListRegex.FindAllStringSubmatchIndex(text, -1) {
+		for _, item := range strings.Split(text[m[2]:m[3]], ",") {
+			name, _, _ := strings.Cut(item, ":") // "delete:Remove" labels the Delete button
+			all = append(all, found{m[2], strings.TrimSpace(name)})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].pos < all[j].pos })
+
+	refs := make([]actionRef, len(all))
+	for i, f := range all {
+		refs[i] = actionRef{name: f.name, line: strings.Count(text[:f.pos], "\n")}
+	}
+	return refs
+}
+
+// actionHandled reports whether any of the sources handles action. With
+// ignoreReadonly, write actions count even on read-only sources.
+func actionHandled(action string, sources []string, declared map[string]SourceConfig, ignoreReadonly bool) bool {
+	for _, name := range sources {
+		cfg := declared[name]
+		readonly := !ignoreReadonly && (cfg.Readonly == nil || *cfg.Readonly)
+		if runtime.IsBuiltinAction(action, cfg.Type, readonly) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownActions lists the actions available to a block, sorted.
+func knownActions(sources []string, declared map[string]SourceConfig, custom []string) []string {
+	var known []string
+	for _, name := range sources {
+		cfg := declared[name]
+		for _, a := range runtime.BuiltinActions(cfg.Type, cfg.Readonly == nil || *cfg.Readonly) {
+			if !containsFlag(known, a) {
+				known = append(known, a)
+			}
+		}
+	}
+	for _, a := range custom {
+		if !containsFlag(known, a) {
+			known = append(known, a)
+		}
+	}
+	sort.Strings(known)
+	return known
+}
+
+// describeSources names the sources in a message, e.g. `source "tasks"`.
+func describeSources(sources []string) string {
+	quoted := make([]string, len(sources))
+	for i, s := range sources {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	if len(sources) == 1 {
+		return "source " + quoted[0]
+	}
+	return "sources " + strings.Join(quoted, ", ")
+}
@@ -0,0 +1,67 @@
+package tinkerdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckActionRefs(t *testing.T) {
+	content := `---
+sources:
+  tasks:
+    type: markdown
+    anchor: "#tasks"
+    readonly: false
+  log:
+    type: sqlite
+    table: log
+  people:
+    type: json
+    file: people.json
+actions:
+  archive-done:
+    kind: sql
+    source: tasks
+    statement: DELETE FROM tasks WHERE done = 1
+---
+## Tasks
+
+` + "```lvt" + `
+<ul lvt-source="tasks">
+  <button lvt-on:click="toggle">Done</button>
+  <button name="Toggl">Typo</button>
+  <button name="archive-done">Archive</button>
+  <form name="Add"><input name="text"></form>
+</ul>
+` + "```" + `
+
+` + "```lvt" + `
+<table lvt-source="log" lvt-actions="Refresh,Delete"></table>
+` + "```" + `
+
+` + "```lvt" + `
+<table lvt-source="people" lvt-actions="edit:Edit,delete:×"></table>
+` + "```" + `
+`
+	issues := CheckActionRefs([]byte(content), nil, nil)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`line 24: unknown action "Toggl" for source "tasks" (did you mean "Toggle"?)`,
+		`line 31: action "Delete" can't write to read-only source "log" (set readonly: false on the source)`,
+		`line 35: action "delete" can't write to source "people" (only markdown and sqlite sources support writes)`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckActionRefs() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckActionRefsSkipsUnboundBlocks(t *testing.T) {
+	content := "# Counter\n\n```lvt\n<button name=\"Increment\">+</button>\n```\n"
+	if issues := CheckActionRefs([]byte(content), nil, nil); len(issues) != 0 {
+		t.Errorf("CheckActionRefs() = %v, want no issues for blocks without lvt-source", issues)
+	}
+}
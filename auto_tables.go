@@ -531,7 +531,7 @@ func generateWritableTableBlock(sourceName string, columns []string, columnTypes
 	b.WriteString("\n")
 	b.WriteString(`          Edit</button>`)
 	b.WriteString("\n")
-	b.WriteString(`        <button name="Delete" data-id="{{.Id}}" data-confirm="Delete this item?"`)
+	b.WriteString(`        <button name="Delete" data-id="{{.Id}}" data-confirm="Delete this item?" lvt-optimistic="delete"`)
 	b.WriteString("\n")
 	b.WriteString(`          style="padding: 4px 8px; background: #dc3545; color: white; border: none; border-radius: 4px; cursor: pointer; font-size: 0.85em;">`)
 	b.WriteString("\n")
@@ -443,6 +443,10 @@ func TestGenerateWritableBlock(t *testing.T) {
 	if !strings.Contains(block, `data-confirm=`) {
 		t.Error("expected data-confirm on delete")
 	}
+	// Should delete optimistically
+	if !strings.Contains(block, `lvt-optimistic="delete"`) {
+		t.Error("expected lvt-optimistic on delete")
+	}
 	// Should have input for each column
 	if !strings.Contains(block, `name="description"`) {
 		t.Error("expected input name=\"description\"")
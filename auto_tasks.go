@@ -214,7 +214,7 @@ func generateAutoTaskLvtBlock(sourceName string) string {
 	return fmt.Sprintf(`<div lvt-source="%s">
 {{range .Data}}
 <label style="display: block; padding: 4px 0; cursor: pointer;">
-  <input type="checkbox" {{if .Done}}checked{{end}} lvt-on:click="Toggle" data-id="{{.Id}}">
+  <input type="checkbox" {{if .Done}}checked{{end}} lvt-on:click="Toggle" lvt-optimistic="toggle" data-id="{{.Id}}">
   <span {{if .Done}}style="text-decoration: line-through; opacity: 0.6"{{end}}>{{.Text}}</span>
 </label>
 {{end}}
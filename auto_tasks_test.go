@@ -320,6 +320,9 @@ func TestGenerateAutoTaskLvtBlock(t *testing.T) {
 	if !strings.Contains(block, `lvt-on:click="Toggle"`) {
 		t.Error("block should contain Toggle action")
 	}
+	if !strings.Contains(block, `lvt-optimistic="toggle"`) {
+		t.Error("block should mark Toggle as optimistic")
+	}
 
 	// Should contain add form
 	if !strings.Contains(block, `name="Add"`) {
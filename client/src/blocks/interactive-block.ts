@@ -8,6 +8,14 @@ import { BlockConfig, ExecMeta, CacheMeta } from "../types";
 import { PersistenceManager } from "../core/persistence-manager";
 import "./exec-toolbar.css";
 import "./cache-indicator.css";
+import "./optimistic.css";
+
+/** An optimistic change awaiting the server: a class marking the element, and how to undo it */
+interface OptimisticUpdate {
+  element: HTMLElement;
+  className: string;
+  rollback?: () => void;
+}
 
 export class InteractiveBlock extends BaseBlock {
   private client: LiveTemplateClient | null = null;
@@ -21,10 +29,20 @@ export class InteractiveBlock extends BaseBlock {
   private readonly _handleSubmit = (e: Event) => this.handleSubmit(e);
   private readonly _handleChange = (e: Event) => this.handleChange(e);
 
+  // Optimistic updates (lvt-optimistic) applied since the last server answer
+  private optimistic: OptimisticUpdate[] = [];
+
+  // Poll state (data-poll): Refresh every pollInterval ms while the tab is visible
+  private pollTimer: ReturnType<typeof setInterval> | null = null;
+  private pollInterval = 0;
+  private lastPoll = 0;
+  private readonly _handleVisibilityChange = () => this.handleVisibilityChange();
+
   // Exec toolbar state
   private execToolbar: HTMLElement | null = null;
   private outputPanel: HTMLElement | null = null;
   private outputExpanded = false;
+  private execStatus = "idle";
 
   constructor(config: BlockConfig, persistence: PersistenceManager, debug = false) {
     super(config, persistence, debug);
@@ -72,6 +90,12 @@ export class InteractiveBlock extends BaseBlock {
       this.injectExecToolbar();
     }
 
+    // Auto-refresh blocks whose source sets options.poll
+    const poll = Number(this.element.dataset.poll);
+    if (poll > 0) {
+      this.startPolling(poll);
+    }
+
     this.log("Interactive block initialized");
   }
 
@@ -80,6 +104,7 @@ export class InteractiveBlock extends BaseBlock {
     this.element.removeEventListener("click", this._handleClick, true);
     this.element.removeEventListener("submit", this._handleSubmit, true);
     this.element.removeEventListener("change", this._handleChange, true);
+    this.stopPolling();
     this.client = null;
     this.pendingForm = null;
     this.pendingAction = null;
@@ -92,6 +117,8 @@ export class InteractiveBlock extends BaseBlock {
       case "tree":
         // Server sent a tree update - apply it to the DOM using LiveTemplateClient
         if (data && this.containerElement && this.client) {
+          // The server's state is authoritative now; drop optimistic markers
+          this.settleOptimistic(false);
           this.client.updateDOM(this.containerElement, data);
           this.log("DOM updated with tree");
 
@@ -125,6 +152,7 @@ export class InteractiveBlock extends BaseBlock {
 
       case "error":
         this.error("Server error:", data.message);
+        this.settleOptimistic(true);
         // Dispatch lvt:error event for pending form
         if (this.pendingForm) {
           const meta = { success: false, errors: data.errors || {}, action: this.pendingAction };
@@ -180,6 +208,7 @@ export class InteractiveBlock extends BaseBlock {
       }
 
       const data = this.extractData(button);
+      this.applyOptimistic(button);
       this.sendAction(button.name, data);
       this.log("Click action (button name):", button.name, data);
       return;
@@ -190,14 +219,19 @@ export class InteractiveBlock extends BaseBlock {
     if (lvtEl && this.element.contains(lvtEl)) {
       const action = lvtEl.getAttribute("lvt-on:click");
       if (action) {
-        e.preventDefault();
+        // An optimistic checkbox keeps the browser's own toggle as its update
+        if (!this.isOptimisticCheckbox(lvtEl)) {
+          e.preventDefault();
+        }
 
         if (!this.checkConfirm(lvtEl)) {
+          e.preventDefault();
           this.log("Click action cancelled by user:", action);
           return;
         }
 
         const data = this.extractData(lvtEl);
+        this.applyOptimistic(lvtEl);
         this.sendAction(action, data);
         this.log("Click action (lvt-on:click):", action, data);
       }
@@ -224,13 +258,21 @@ export class InteractiveBlock extends BaseBlock {
     }
 
     e.preventDefault();
+
+    // WebSocket messages can't carry files; post the form over HTTP instead
+    if (target.querySelector('input[type="file"]')) {
+      this.uploadForm(target, action);
+      return;
+    }
+
     const formData = new FormData(target);
     const data: Record<string, any> = {};
     formData.forEach((value, key) => {
       // Skip the submitter button's own name=value entry to avoid leaking
       // the action name as a spurious key in the data payload
       if (submitter instanceof HTMLButtonElement && key === submitter.name) return;
-      data[key] = value;
+      // Repeated names, like a checkbox per row, are sent comma-separated
+      data[key] = key in data ? `${data[key]},${value}` : value;
     });
 
     // Track form and action for lifecycle events
@@ -246,11 +288,79 @@ export class InteractiveBlock extends BaseBlock {
     this.log("Submit action:", action, data);
   }
 
+  /**
+   * Post a form with a file input to the /action endpoint. The server pushes
+   * the refreshed block over the WebSocket; the response only settles the
+   * form's lvt:success or lvt:error event.
+   */
+  private async uploadForm(form: HTMLFormElement, action: string): Promise<void> {
+    form.dispatchEvent(
+      new CustomEvent("lvt:pending", { bubbles: true, detail: { action } })
+    );
+
+    // The server reads the fields before the file, so append the file last
+    const body = new FormData();
+    body.append("page", this.pagePath());
+    body.append("block", this.id);
+    body.append("action", action);
+    let file: File | null = null;
+    new FormData(form).forEach((value, key) => {
+      if (value instanceof File) {
+        file = file || value;
+      } else {
+        body.append(key, value);
+      }
+    });
+    if (file) {
+      body.append("file", file);
+    }
+
+    let detail: { success: boolean; errors: Record<string, string>; action: string };
+    try {
+      const response = await fetch("/action", { method: "POST", body });
+      const result = await response.json().catch(() => ({}));
+      if (!response.ok) {
+        throw new Error(result.error || `Upload failed (${response.status})`);
+      }
+      detail = { success: true, errors: {}, action };
+      this.log("Uploaded", result.data);
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      this.error("Upload failed:", message);
+      detail = { success: false, errors: { file: message }, action };
+    }
+    form.dispatchEvent(
+      new CustomEvent(detail.success ? "lvt:success" : "lvt:error", { bubbles: true, detail })
+    );
+  }
+
+  /**
+   * The route of this page, as the server knows it from the WebSocket URL.
+   */
+  private pagePath(): string {
+    const wsMeta = document.querySelector<HTMLMetaElement>('meta[name="tinkerdown-ws-url"]');
+    const page = wsMeta ? new URL(wsMeta.content, window.location.href).searchParams.get("page") : null;
+    return page ?? window.location.pathname;
+  }
+
   /**
    * Handle change events (lvt-on:change).
    */
   private handleChange(e: Event): void {
     const target = e.target as HTMLInputElement;
+
+    // lvt-select-all="ids" checks or clears every checkbox named ids in the form
+    const group = target.getAttribute("lvt-select-all");
+    if (group !== null && target.type === "checkbox") {
+      const scope = target.form ?? this.element;
+      scope
+        .querySelectorAll<HTMLInputElement>(`input[type="checkbox"][name="${CSS.escape(group)}"]`)
+        .forEach((box) => {
+          box.checked = target.checked;
+        });
+      return;
+    }
+
     const action = target.getAttribute("lvt-on:change");
 
     if (action) {
@@ -300,6 +410,85 @@ export class InteractiveBlock extends BaseBlock {
     this.sendMessage(this.id, action, data);
   }
 
+  private isOptimisticCheckbox(element: HTMLElement): boolean {
+    return element.getAttribute("lvt-optimistic") === "toggle" &&
+      element instanceof HTMLInputElement && element.type === "checkbox";
+  }
+
+  /**
+   * Apply the change an lvt-optimistic hint declares before the server
+   * confirms it: "toggle" flips a checkbox (the browser already has) and
+   * marks its item, "delete" greys out the item's row. The next tree update
+   * replaces the change with the server's state; an error undoes it.
+   */
+  private applyOptimistic(element: HTMLElement): void {
+    const hint = element.getAttribute("lvt-optimistic");
+    const item = (element.closest("tr, li, label") as HTMLElement | null) ?? element;
+
+    if (hint === "toggle") {
+      let rollback: (() => void) | undefined;
+      if (this.isOptimisticCheckbox(element)) {
+        const input = element as HTMLInputElement;
+        const previous = !input.checked;
+        rollback = () => { input.checked = previous; };
+      }
+      item.classList.add("lvt-optimistic-pending");
+      this.optimistic.push({ element: item, className: "lvt-optimistic-pending", rollback });
+    } else if (hint === "delete") {
+      item.classList.add("lvt-optimistic-deleted");
+      this.optimistic.push({ element: item, className: "lvt-optimistic-deleted" });
+    }
+  }
+
+  /**
+   * Clear pending optimistic updates once the server answers, undoing them
+   * (newest first) when it reported an error.
+   */
+  private settleOptimistic(rollback: boolean): void {
+    for (const update of this.optimistic.reverse()) {
+      update.element.classList.remove(update.className);
+      if (rollback) {
+        update.rollback?.();
+      }
+    }
+    if (rollback && this.optimistic.length > 0) {
+      this.log("Rolled back", this.optimistic.length, "optimistic update(s)");
+    }
+    this.optimistic = [];
+  }
+
+  /**
+   * Start sending Refresh every interval ms. Ticks are skipped while the tab
+   * is hidden; a tab that comes back overdue refreshes straight away.
+   */
+  private startPolling(interval: number): void {
+    this.pollInterval = interval;
+    this.lastPoll = Date.now();
+    this.pollTimer = setInterval(() => this.poll(), interval);
+    document.addEventListener("visibilitychange", this._handleVisibilityChange);
+    this.log("Polling every", interval, "ms");
+  }
+
+  private stopPolling(): void {
+    if (this.pollTimer !== null) {
+      clearInterval(this.pollTimer);
+      this.pollTimer = null;
+    }
+    document.removeEventListener("visibilitychange", this._handleVisibilityChange);
+  }
+
+  private poll(): void {
+    if (document.hidden) return;
+    this.lastPoll = Date.now();
+    this.sendAction("Refresh", {});
+  }
+
+  private handleVisibilityChange(): void {
+    if (!document.hidden && Date.now() - this.lastPoll >= this.pollInterval) {
+      this.poll();
+    }
+  }
+
   /**
    * Inject exec toolbar for runbook/notebook-style command execution
    */
@@ -319,6 +508,7 @@ export class InteractiveBlock extends BaseBlock {
         </svg>
         Run
       </button>
+      <button class="exec-toolbar-cancel-btn" type="button" hidden>Cancel</button>
     `;
 
     // Create output panel
@@ -350,6 +540,8 @@ export class InteractiveBlock extends BaseBlock {
     // Attach event handlers
     this.execToolbar.querySelector(".exec-toolbar-run-btn")
       ?.addEventListener("click", () => this.handleExecRun());
+    this.execToolbar.querySelector(".exec-toolbar-cancel-btn")
+      ?.addEventListener("click", () => this.sendAction("CancelRun", {}));
     this.outputPanel.querySelector(".exec-output-toggle")
       ?.addEventListener("click", () => this.toggleOutput());
 
@@ -357,11 +549,12 @@ export class InteractiveBlock extends BaseBlock {
   }
 
   /**
-   * Handle Run button click - sends Run action to server
+   * Handle Run button click - sends Run action to server, or Confirm when
+   * the source is waiting for confirmation
    */
   private handleExecRun(): void {
     this.log("Run button clicked");
-    this.sendAction("Run", {});
+    this.sendAction(this.execStatus === "confirm" ? "Confirm" : "Run", {});
   }
 
   /**
@@ -383,6 +576,8 @@ export class InteractiveBlock extends BaseBlock {
     const statusEl = this.execToolbar?.querySelector(".exec-toolbar-status");
     const durationEl = this.execToolbar?.querySelector(".exec-toolbar-duration");
     const runBtn = this.execToolbar?.querySelector(".exec-toolbar-run-btn") as HTMLButtonElement | null;
+    const cancelBtn = this.execToolbar?.querySelector(".exec-toolbar-cancel-btn") as HTMLButtonElement | null;
+    this.execStatus = status;
 
     // Update status indicator
     if (statusEl) {
@@ -393,6 +588,8 @@ export class InteractiveBlock extends BaseBlock {
         statusEl.innerHTML = '<span>✓ Success</span>';
       } else if (status === "error") {
         statusEl.innerHTML = '<span>✗ Error</span>';
+      } else if (status === "confirm") {
+        statusEl.innerHTML = `<span>${this.escapeHtml(execMeta.confirm_message || "Run this command?")}</span>`;
       } else {
         statusEl.innerHTML = '<span>Ready</span>';
       }
@@ -420,10 +617,15 @@ export class InteractiveBlock extends BaseBlock {
           <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor">
             <path d="M8 5v14l11-7z"/>
           </svg>
-          Run
+          ${status === "confirm" ? "Confirm" : "Run"}
         `;
       }
     }
+    // Cancel answers a confirmation or stops a streaming run; only
+    // streaming runs report "running" while the command is still going
+    if (cancelBtn) {
+      cancelBtn.hidden = status !== "confirm" && status !== "running";
+    }
 
     // Update output panel content
     const stdoutEl = this.outputPanel?.querySelector(".exec-output-stdout");
@@ -436,6 +638,17 @@ export class InteractiveBlock extends BaseBlock {
       stderrEl.style.display = execMeta.stderr ? "block" : "none";
     }
 
+    // Follow streamed output while the command runs
+    if (status === "running" && execMeta.output) {
+      if (!this.outputExpanded) {
+        this.toggleOutput();
+      }
+      const contentEl = this.outputPanel?.querySelector(".exec-output-content");
+      if (contentEl) {
+        contentEl.scrollTop = contentEl.scrollHeight;
+      }
+    }
+
     this.log("Exec toolbar updated:", status, execMeta.duration);
   }
 
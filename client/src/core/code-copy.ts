@@ -9,6 +9,7 @@ export class CodeCopy {
   private static readonly COPY_BUTTON_CLASS = 'code-copy-btn';
   private static readonly CODE_WRAPPER_CLASS = 'code-block-wrapper';
   private static readonly COPIED_CLASS = 'copied';
+  private static readonly SKIP_SELECTOR = '.tinkerdown-interactive-block, [data-editable], .mermaid';
 
   constructor() {
     this.init();
@@ -27,7 +28,9 @@ export class CodeCopy {
   }
 
   /**
-   * Add copy buttons to all pre > code blocks
+   * Add copy buttons to all pre > code blocks. Mermaid diagrams and
+   * interactive or editable blocks are skipped, as their contents aren't
+   * meant to be copied as code.
    */
   private addCopyButtons(): void {
     const codeBlocks = document.querySelectorAll('pre > code');
@@ -38,6 +41,9 @@ export class CodeCopy {
         // Already has a copy button
         return;
       }
+      if (codeElement.classList.contains('language-mermaid') || pre.closest(CodeCopy.SKIP_SELECTOR)) {
+        return;
+      }
 
       // Wrap pre in a container for positioning
       if (!pre.parentElement?.classList.contains(CodeCopy.CODE_WRAPPER_CLASS)) {
@@ -64,6 +70,7 @@ export class CodeCopy {
    */
   private createCopyButton(): HTMLButtonElement {
     const button = document.createElement('button');
+    button.type = 'button';
     button.className = CodeCopy.COPY_BUTTON_CLASS;
     button.setAttribute('aria-label', 'Copy code to clipboard');
     button.innerHTML = this.getCopyIcon();
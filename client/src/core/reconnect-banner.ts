@@ -0,0 +1,42 @@
+/**
+ * Reconnect Banner
+ * Shows a small "Reconnecting…" notice while the WebSocket is down
+ */
+
+const BANNER_ID = "tinkerdown-reconnect-banner";
+
+/**
+ * Show the reconnect banner (no-op if it is already showing)
+ */
+export function showReconnectBanner(): void {
+  if (document.getElementById(BANNER_ID)) {
+    return;
+  }
+
+  const banner = document.createElement("div");
+  banner.id = BANNER_ID;
+  banner.setAttribute("role", "status");
+  banner.textContent = "Reconnecting…";
+  banner.style.cssText = `
+    position: fixed;
+    bottom: 20px;
+    left: 50%;
+    transform: translateX(-50%);
+    background: #1f2937;
+    color: white;
+    padding: 8px 16px;
+    border-radius: 8px;
+    box-shadow: 0 4px 12px rgba(0,0,0,0.15);
+    z-index: 100000;
+    font-family: system-ui, -apple-system, sans-serif;
+    font-size: 14px;
+  `;
+  document.body.appendChild(banner);
+}
+
+/**
+ * Remove the reconnect banner
+ */
+export function hideReconnectBanner(): void {
+  document.getElementById(BANNER_ID)?.remove();
+}
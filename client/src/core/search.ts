@@ -2,25 +2,34 @@
  * Search Functionality for Site Mode
  *
  * Provides client-side search across all pages with:
- * - Fuzzy search through titles and content
- * - Keyboard shortcuts (Ctrl+K / Cmd+K)
- * - Search result highlighting
+ * - Search through titles, headings and content, ranked in that order
+ * - Keyboard shortcuts (Ctrl+K / Cmd+K, and the "search" shortcut, / by default)
+ * - Snippets around the match, with the query terms highlighted
  * - Modal/overlay UI
  */
 
 import './search.css';
 
+export interface SearchHeading {
+  text: string;
+  id: string;
+  content?: string; // Start of the section's text
+}
+
 export interface SearchEntry {
   title: string;
   path: string;
   content: string;
   section?: string;
+  headings?: SearchHeading[];
 }
 
 export interface SearchResult extends SearchEntry {
   score: number;
   titleMatches: boolean;
   contentMatches: boolean;
+  heading?: SearchHeading; // Matched heading, linked to instead of the page top
+  excerpt: string; // Text the snippet is taken from
 }
 
 export class SiteSearch {
@@ -146,8 +155,9 @@ export class SiteSearch {
 
   private setupKeyboardShortcuts() {
     document.addEventListener('keydown', (e) => {
-      // Ctrl+K or Cmd+K
-      if ((e.ctrlKey || e.metaKey) && e.key === 'k') {
+      // Ctrl+K or Cmd+K, or the configurable search shortcut
+      const shortcut = (window as any).tinkerdownShortcut;
+      if (((e.ctrlKey || e.metaKey) && e.key === 'k') || (shortcut && shortcut(e, 'search'))) {
         e.preventDefault();
         this.openSearch();
       }
@@ -198,26 +208,42 @@ export class SiteSearch {
       const lowerContent = entry.content.toLowerCase();
 
       const titleMatches = lowerTitle.includes(lowerQuery);
-      const contentMatches = lowerContent.includes(lowerQuery);
-
-      if (titleMatches || contentMatches) {
-        // Calculate score (title matches are worth more)
+      const heading = entry.headings?.find((h) => h.text.toLowerCase().includes(lowerQuery));
+      // The section whose text holds the match, past the start of the page
+      const section = entry.headings?.find((h) => h.content?.toLowerCase().includes(lowerQuery));
+      const contentMatches = lowerContent.includes(lowerQuery) || section !== undefined;
+
+      if (titleMatches || heading || contentMatches) {
+        // Title matches rank above heading matches, which rank above
+        // content matches; a content match breaks ties
         let score = 0;
         if (titleMatches) {
-          score += 10;
+          score += 100;
           // Exact match or starts with gets bonus
           if (lowerTitle === lowerQuery) score += 50;
           else if (lowerTitle.startsWith(lowerQuery)) score += 20;
+        } else if (heading) {
+          score += 10;
         }
         if (contentMatches) {
           score += 1;
         }
 
+        // Snippets come from the matched heading's section, or wherever
+        // the text matched
+        const linked = titleMatches ? undefined : heading ?? section;
+        let excerpt = entry.content;
+        if (linked?.content && (linked === heading || !lowerContent.includes(lowerQuery))) {
+          excerpt = linked.content;
+        }
+
         results.push({
           ...entry,
           score,
           titleMatches,
           contentMatches,
+          heading: linked,
+          excerpt,
         });
       }
     }
@@ -237,21 +263,26 @@ export class SiteSearch {
       return;
     }
 
-    this.resultsContainer.innerHTML = results.map((result, index) => `
+    const query = this.searchInput?.value.trim() || '';
+    this.resultsContainer.innerHTML = results.map((result, index) => {
+      const href = result.heading ? `${result.path}#${encodeURIComponent(result.heading.id)}` : result.path;
+      const section = result.heading ? result.heading.text : result.section;
+      return `
       <a
-        href="${result.path}"
+        href="${this.escapeHTML(href)}"
         class="search-result ${index === this.selectedIndex ? 'selected' : ''}"
         data-index="${index}"
       >
         <div class="search-result-title">
-          ${this.highlightMatch(result.title, this.searchInput?.value || '')}
+          ${this.highlightMatch(this.escapeHTML(result.title), query)}
         </div>
-        ${result.section ? `<div class="search-result-section">${result.section}</div>` : ''}
+        ${section ? `<div class="search-result-section">${this.highlightMatch(this.escapeHTML(section), query)}</div>` : ''}
         <div class="search-result-content">
-          ${this.highlightMatch(this.truncateContent(result.content), this.searchInput?.value || '')}
+          ${this.highlightMatch(this.escapeHTML(this.snippet(result.excerpt, query)), query)}
         </div>
       </a>
-    `).join('');
+    `;
+    }).join('');
 
     // Add click handlers
     this.resultsContainer.querySelectorAll('.search-result').forEach((el) => {
@@ -259,11 +290,14 @@ export class SiteSearch {
     });
   }
 
+  // highlightMatch marks each term of query in text, which is HTML-escaped,
+  // leaving its character references whole.
   private highlightMatch(text: string, query: string): string {
-    if (!query) return text;
+    const terms = query.split(/\s+/).filter((term) => term).map((term) => this.escapeRegex(this.escapeHTML(term)));
+    if (terms.length === 0) return text;
 
-    const regex = new RegExp(`(${this.escapeRegex(query)})`, 'gi');
-    return text.replace(regex, '<mark>$1</mark>');
+    const regex = new RegExp(`(&[#\\w]+;)|(${terms.join('|')})`, 'gi');
+    return text.replace(regex, (match, entity) => (entity ? match : `<mark>${match}</mark>`));
   }
 
   private truncateContent(content: string, maxLength: number = 200): string {
@@ -271,6 +305,21 @@ export class SiteSearch {
     return content.substring(0, maxLength) + '...';
   }
 
+  // snippet returns up to maxLength characters of content around the first
+  // match of query, or its start when query isn't in it.
+  private snippet(content: string, query: string, maxLength: number = 200): string {
+    const index = content.toLowerCase().indexOf(query.toLowerCase());
+    if (index < 0 || content.length <= maxLength) return this.truncateContent(content, maxLength);
+
+    const start = Math.max(0, Math.min(index - 60, content.length - maxLength));
+    const end = start + maxLength;
+    return (start > 0 ? '...' : '') + content.substring(start, end) + (end < content.length ? '...' : '');
+  }
+
+  private escapeHTML(str: string): string {
+    return str.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+  }
+
   private escapeRegex(str: string): string {
     return str.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
   }
@@ -282,7 +331,9 @@ export class SiteSearch {
 
     switch (e.key) {
       case 'Escape':
+        // Don't also exit presentation mode or clear the sidebar filter
         e.preventDefault();
+        e.stopPropagation();
         this.closeSearch();
         break;
 
@@ -7,11 +7,16 @@ import { TinkerdownClientOptions, BlockConfig, BlockMetadata, MessageEnvelope }
 import { MessageRouter } from "./core/message-router";
 import { PersistenceManager } from "./core/persistence-manager";
 import { TabsController } from "./core/tabs";
+import { showReconnectBanner, hideReconnectBanner } from "./core/reconnect-banner";
 import { BaseBlock } from "./blocks/base-block";
 import { ServerBlock } from "./blocks/server-block";
 import { InteractiveBlock } from "./blocks/interactive-block";
 import { WasmBlock } from "./blocks/wasm-block";
 
+// Reconnect delays double from the base delay up to the max delay
+const RECONNECT_BASE_DELAY = 500;
+const RECONNECT_MAX_DELAY = 10000;
+
 export class TinkerdownClient {
   private options: TinkerdownClientOptions;
   private router: MessageRouter;
@@ -20,6 +25,7 @@ export class TinkerdownClient {
   private blocks: Map<string, BaseBlock> = new Map();
   private ws: WebSocket | null = null;
   private reconnectTimer: number | null = null;
+  private reconnectAttempts = 0;
   private isConnected = false;
 
   constructor(options: TinkerdownClientOptions) {
@@ -52,18 +58,33 @@ export class TinkerdownClient {
     }
 
     try {
-      this.ws = new WebSocket(this.options.wsUrl);
+      const ws = new WebSocket(this.options.wsUrl);
+      this.ws = ws;
 
       this.ws.onopen = () => {
         this.isConnected = true;
         console.log("[TinkerdownClient] Connected to server");
+        if (this.reconnectAttempts > 0) {
+          // The server restarted or dropped this connection, and the block
+          // state it held is gone; reload to start over with fresh content.
+          window.location.reload();
+          return;
+        }
         this.options.onConnect?.();
       };
 
       this.ws.onclose = () => {
+        if (this.ws !== ws) {
+          return; // Closed by disconnect()
+        }
+        const wasConnected = this.isConnected;
+        this.ws = null;
         this.isConnected = false;
-        console.log("[TinkerdownClient] Disconnected from server");
-        this.options.onDisconnect?.();
+        if (wasConnected) {
+          console.log("[TinkerdownClient] Disconnected from server");
+          this.options.onDisconnect?.();
+        }
+        showReconnectBanner();
         this.scheduleReconnect();
       };
 
@@ -89,6 +110,8 @@ export class TinkerdownClient {
       clearTimeout(this.reconnectTimer);
       this.reconnectTimer = null;
     }
+    this.reconnectAttempts = 0;
+    hideReconnectBanner();
 
     if (this.ws) {
       this.ws.close();
@@ -135,18 +158,24 @@ export class TinkerdownClient {
   }
 
   /**
-   * Schedule reconnection attempt
+   * Schedule reconnection attempt with exponential backoff
    */
   private scheduleReconnect(): void {
     if (this.reconnectTimer) {
       return;
     }
 
+    const delay = Math.min(
+      RECONNECT_BASE_DELAY * 2 ** this.reconnectAttempts,
+      RECONNECT_MAX_DELAY
+    );
+    this.reconnectAttempts++;
+
     this.reconnectTimer = window.setTimeout(() => {
       this.reconnectTimer = null;
       console.log("[TinkerdownClient] Attempting to reconnect...");
       this.connect();
-    }, 3000);
+    }, delay);
   }
 
   /**
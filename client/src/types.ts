@@ -19,6 +19,7 @@ export interface ExecMeta {
   output?: string;
   stderr?: string;
   command?: string;
+  confirm_message?: string;
 }
 
 export interface CacheMeta {
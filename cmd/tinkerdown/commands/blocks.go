@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
 )
 
 // BlocksCommand implements the blocks command.
@@ -15,10 +16,16 @@ func BlocksCommand(args []string) error {
 	// Parse arguments
 	dir := "."
 	verbose := false
+	validate := false
+	explain := ""
 
 	for i, arg := range args {
 		if arg == "--verbose" || arg == "-v" {
 			verbose = true
+		} else if arg == "--validate" {
+			validate = true
+		} else if val, ok := strings.CutPrefix(arg, "--explain="); ok {
+			explain = val
 		} else if i == 0 {
 			dir = arg
 		}
@@ -35,6 +42,13 @@ func BlocksCommand(args []string) error {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
+	if explain != "" {
+		return explainSource(absDir, explain)
+	}
+
+	// Configs loaded for --validate, keyed by directory
+	configs := make(map[string]*config.Config)
+
 	fmt.Printf("🔍 Inspecting blocks in: %s\n\n", absDir)
 
 	// Track statistics
@@ -43,6 +57,7 @@ func BlocksCommand(args []string) error {
 	var wasmCount int
 	var lvtCount int
 	var fileBlocks []fileBlockInfo
+	var sourceIssues []fileSourceIssue
 
 	// Discover and inspect all markdown files
 	err = filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
@@ -71,6 +86,31 @@ func BlocksCommand(args []string) error {
 			relPath = path
 		}
 
+		// Cross-check lvt-source references and actions against the declared sources
+		content, _ := os.ReadFile(path)
+		if validate {
+			// Sources and actions in the app's tinkerdown.yaml are available to every page
+			cfgDir := nearestConfigDir(filepath.Dir(path), absDir)
+			cfg, ok := configs[cfgDir]
+			if !ok {
+				if cfg, err = config.LoadFromDir(cfgDir); err != nil {
+					return fmt.Errorf("failed to load config in %s: %w", cfgDir, err)
+				}
+				configs[cfgDir] = cfg
+			}
+			sharedSources := pageSourceConfigs(cfg.Sources)
+			var sharedActions []string
+			for name := range cfg.Actions {
+				sharedActions = append(sharedActions, name)
+			}
+
+			issues := tinkerdown.CheckSourceRefs(content, sharedSources, filepath.Dir(path))
+			issues = append(issues, tinkerdown.CheckActionRefs(content, sharedSources, sharedActions)...)
+			for _, issue := range issues {
+				sourceIssues = append(sourceIssues, fileSourceIssue{file: relPath, issue: issue})
+			}
+		}
+
 		// Parse the file to get blocks
 		page, err := tinkerdown.ParseFile(path)
 		if err != nil {
@@ -80,7 +120,6 @@ func BlocksCommand(args []string) error {
 		}
 
 		// Parse raw markdown to get code blocks with line numbers
-		content, _ := os.ReadFile(path)
 		_, codeBlocks, _, err := tinkerdown.ParseMarkdown(content)
 		if err != nil {
 			return nil
@@ -110,28 +149,30 @@ func BlocksCommand(args []string) error {
 	// Display results
 	if len(fileBlocks) == 0 {
 		fmt.Println("No code blocks found.")
-		return nil
-	}
-
-	// Print blocks for each file
-	for _, fb := range fileBlocks {
-		if verbose {
-			printVerboseBlocks(fb)
-		} else {
-			printBasicBlocks(fb)
+	} else {
+		// Print blocks for each file
+		for _, fb := range fileBlocks {
+			if verbose {
+				printVerboseBlocks(fb)
+			} else {
+				printBasicBlocks(fb)
+			}
 		}
-	}
 
-	// Print summary
-	separator := strings.Repeat("─", 60) + "\n"
-	fmt.Print(separator)
-	fmt.Println("Summary:")
-	fmt.Printf("  Total blocks: %d\n", totalBlocks)
-	fmt.Printf("  Server blocks: %d\n", serverCount)
-	fmt.Printf("  WASM blocks: %d\n", wasmCount)
-	fmt.Printf("  Interactive blocks: %d\n", lvtCount)
-	fmt.Println()
+		// Print summary
+		separator := strings.Repeat("─", 60) + "\n"
+		fmt.Print(separator)
+		fmt.Println("Summary:")
+		fmt.Printf("  Total blocks: %d\n", totalBlocks)
+		fmt.Printf("  Server blocks: %d\n", serverCount)
+		fmt.Printf("  WASM blocks: %d\n", wasmCount)
+		fmt.Printf("  Interactive blocks: %d\n", lvtCount)
+		fmt.Println()
+	}
 
+	if validate {
+		return printSourceIssues(sourceIssues)
+	}
 	return nil
 }
 
@@ -141,6 +182,76 @@ type fileBlockInfo struct {
 	codeBlocks []*tinkerdown.CodeBlock
 }
 
+type fileSourceIssue struct {
+	file  string
+	issue tinkerdown.SourceRefIssue
+}
+
+// printSourceIssues prints lvt-source reference and action problems and returns an
+// error if any of them aren't warnings.
+func printSourceIssues(issues []fileSourceIssue) error {
+	if len(issues) == 0 {
+		fmt.Println("✓ All lvt-source references and actions match their sources")
+		return nil
+	}
+
+	failed := 0
+	fmt.Println("Source references:")
+	for _, fi := range issues {
+		mark := "⚠"
+		if !fi.issue.Warning {
+			mark = "✗"
+			failed++
+		}
+		fmt.Printf("  %s %s:%d: %s\n", mark, fi.file, fi.issue.Line, fi.issue.Message)
+		if fi.issue.Hint != "" {
+			fmt.Printf("      💡 %s\n", fi.issue.Hint)
+		}
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("found %d invalid source reference(s)", failed)
+	}
+	return nil
+}
+
+// nearestConfigDir returns the closest directory from dir up to root that has
+// a config file (see config.LoadFromDir), or root if none does.
+func nearestConfigDir(dir, root string) string {
+	for {
+		for _, name := range []string{"tinkerdown.yaml", "lmt.yaml", "livemdtools.yaml"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			return root
+		}
+		dir = parent
+	}
+}
+
+// pageSourceConfigs converts tinkerdown.yaml sources to the page source type.
+// Only the fields that the validate checks use are copied.
+func pageSourceConfigs(sources map[string]config.SourceConfig) map[string]tinkerdown.SourceConfig {
+	out := make(map[string]tinkerdown.SourceConfig, len(sources))
+	for name, src := range sources {
+		out[name] = tinkerdown.SourceConfig{
+			Type:     src.Type,
+			From:     src.From,
+			File:     src.File,
+			Anchor:   src.Anchor,
+			DB:       src.DB,
+			Table:    src.Table,
+			Readonly: src.Readonly,
+			Options:  src.Options,
+		}
+	}
+	return out
+}
+
 func printBasicBlocks(fb fileBlockInfo) {
 	fmt.Printf("%s:\n", fb.file)
 
@@ -171,6 +282,8 @@ func printBasicBlocks(fb fileBlockInfo) {
 		case "wasm":
 			lines := strings.Count(cb.Content, "\n") + 1
 			fmt.Printf("           Code: %d lines\n", lines)
+		case "embed":
+			fmt.Printf("           Embeds: %s#%s\n", cb.Metadata["page"], cb.Metadata["block"])
 		}
 		fmt.Println()
 	}
@@ -222,6 +335,10 @@ func printVerboseBlocks(fb fileBlockInfo) {
 			}
 			lines := strings.Count(cb.Content, "\n") + 1
 			fmt.Printf("  Code Lines: %d\n", lines)
+
+		case "embed":
+			fmt.Printf("  Page: %s\n", cb.Metadata["page"])
+			fmt.Printf("  Block: %s\n", cb.Metadata["block"])
 		}
 
 		fmt.Println()
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlocksValidateSourceRefs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Shared sources in tinkerdown.yaml are available to every page
+	if err := os.WriteFile(filepath.Join(tmpDir, "tinkerdown.yaml"), []byte("sources:\n  users:\n    type: rest\n    from: https://example.com/users\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	page := "---\ntitle: Users\n---\n# Users\n\n```lvt\n<table lvt-source=\"users\"></table>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+
+	if err := BlocksCommand([]string{tmpDir, "--validate"}); err != nil {
+		t.Fatalf("BlocksCommand() error = %v, want nil for valid references", err)
+	}
+
+	// A typo'd source name is reported
+	typo := "---\ntitle: Typo\n---\n# Typo\n\n```lvt\n<table lvt-source=\"usres\"></table>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "typo.md"), []byte(typo), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+
+	err := BlocksCommand([]string{tmpDir, "--validate"})
+	if err == nil || !strings.Contains(err.Error(), "1 invalid source reference") {
+		t.Errorf("BlocksCommand() error = %v, want 1 invalid source reference", err)
+	}
+
+	// Without --validate the command only lists blocks
+	if err := BlocksCommand([]string{tmpDir}); err != nil {
+		t.Errorf("BlocksCommand() without --validate error = %v", err)
+	}
+}
+
+func TestBlocksExplainSource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	page := "---\ntitle: Users\nsources:\n  users:\n    type: exec\n    cmd: \"grep -E '^a\\\\d+' users.txt\"\n---\n# Users\n\n```lvt\n<table lvt-source=\"users\"></table>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+
+	if err := BlocksCommand([]string{tmpDir, "--explain=users"}); err != nil {
+		t.Errorf("BlocksCommand(--explain=users) error = %v", err)
+	}
+
+	err := BlocksCommand([]string{tmpDir, "--explain=usres"})
+	if err == nil || !strings.Contains(err.Error(), `unknown source "usres"`) || !strings.Contains(err.Error(), `did you mean "users"?`) {
+		t.Errorf("BlocksCommand(--explain=usres) error = %v, want unknown source with suggestion", err)
+	}
+}
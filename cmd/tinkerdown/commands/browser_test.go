@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBrowserURL(t *testing.T) {
+	tests := []struct {
+		host string
+		port int
+		want string
+	}{
+		{"localhost", 8080, "http://localhost:8080"},
+		{"", 3000, "http://localhost:3000"},
+		{"0.0.0.0", 8080, "http://localhost:8080"},
+		{"::", 8080, "http://localhost:8080"},
+		{"192.168.1.5", 9000, "http://192.168.1.5:9000"},
+		{"::1", 8080, "http://[::1]:8080"},
+	}
+	for _, tt := range tests {
+		if got := browserURL(tt.host, tt.port); got != tt.want {
+			t.Errorf("browserURL(%q, %d) = %q, want %q", tt.host, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestBrowserCommand(t *testing.T) {
+	const url = "http://localhost:8080"
+	tests := []struct {
+		goos string
+		name string
+		args []string
+	}{
+		{"darwin", "open", []string{url}},
+		{"linux", "xdg-open", []string{url}},
+		{"windows", "rundll32", []string{"url.dll,FileProtocolHandler", url}},
+	}
+	for _, tt := range tests {
+		name, args, err := browserCommand(tt.goos, url)
+		if err != nil || name != tt.name || !slices.Equal(args, tt.args) {
+			t.Errorf("browserCommand(%q) = %q %q, %v, want %q %q", tt.goos, name, args, err, tt.name, tt.args)
+		}
+	}
+	if _, _, err := browserCommand("plan9", url); err == nil {
+		t.Error("browserCommand(plan9) error = nil, want unsupported")
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// changedFiles returns the files under root that differ from the git ref
+// base (HEAD when empty): committed since the branch left base, staged,
+// modified or untracked. Files changed only on base aren't included. Paths
+// are relative to root, with forward slashes. It returns
+// nil, without an error, when root isn't in a git work tree or git isn't
+// installed, so validation falls back to checking every file.
+func changedFiles(root, base string) (map[string]bool, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, nil
+	}
+	if _, err := runGit(root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, nil
+	}
+	if base == "" {
+		base = "HEAD"
+	}
+
+	// Resolve the ref first, so a base starting with "-" can't be taken for
+	// an option
+	commit, err := runGit(root, "rev-parse", "--verify", "--quiet", "--end-of-options", base+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown git ref %q", base)
+	}
+
+	// --merge-base compares against where the branch left base, and
+	// --relative limits the diff to root and makes the paths relative to it
+	diff, err := runGit(root, "diff", "--name-only", "--relative", "--merge-base", strings.TrimSpace(commit), "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed since %s: %w", base, err)
+	}
+	untracked, err := runGit(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	changed := make(map[string]bool)
+	for _, line := range strings.Split(diff+"\n"+untracked, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			changed[line] = true
+		}
+	}
+	return changed, nil
+}
+
+// runGit runs git in dir and returns its output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+// onlyChanged returns the problems in list that are in changed files. It
+// returns list as it is when changed is nil (every file is checked).
+func onlyChanged(list []fileValidationError, changed map[string]bool) []fileValidationError {
+	if changed == nil || list == nil {
+		return list
+	}
+	result := []fileValidationError{}
+	for _, p := range list {
+		if changed[filepath.ToSlash(p.file)] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/testutil"
+)
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := testutil.WriteFiles(t, map[string]string{
+		"README.md":          "# Repo\n",
+		"docs/index.md":      "# Home\n",
+		"docs/guide.md":      "# Guide\n",
+		"docs/reference.md":  "# Reference\n",
+		"docs/.gitignore":    "scratch.md\n",
+		"docs/scratch.md":    "# Ignored\n",
+		"docs/notes/todo.md": "# Todo\n",
+	})
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("branch", "base")
+
+	// Changed only on base after the branch left it, so never reported
+	git("checkout", "-q", "base")
+	write("docs/index.md", "# Home\n\nOn base.\n")
+	git("commit", "-q", "-am", "update home on base")
+	git("checkout", "-q", "-")
+
+	// Committed since base, modified, untracked and outside the site
+	write("docs/guide.md", "# Guide\n\nUpdated.\n")
+	git("commit", "-q", "-am", "update guide")
+	write("docs/reference.md", "# Reference\n\nEdited.\n")
+	write("docs/new.md", "# New\n")
+	write("README.md", "# Repo\n\nEdited.\n")
+
+	site := filepath.Join(repo, "docs")
+	tests := []struct {
+		base string
+		want []string
+	}{
+		{base: "", want: []string{"new.md", "reference.md"}},
+		{base: "base", want: []string{"guide.md", "new.md", "reference.md"}},
+	}
+	for _, tt := range tests {
+		changed, err := changedFiles(site, tt.base)
+		if err != nil {
+			t.Fatalf("changedFiles(%q) error = %v", tt.base, err)
+		}
+		var got []string
+		for file := range changed {
+			got = append(got, file)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("changedFiles(%q) = %v, want %v", tt.base, got, tt.want)
+		}
+	}
+
+	for _, ref := range []string{"no-such-ref", "--output=/tmp/x"} {
+		if _, err := changedFiles(site, ref); err == nil || !strings.Contains(err.Error(), "unknown git ref") {
+			t.Errorf("changedFiles(%q) error = %v, want an unknown ref", ref, err)
+		}
+	}
+
+	// Outside a repository, every file is checked
+	if changed, err := changedFiles(t.TempDir(), ""); err != nil || changed != nil {
+		t.Errorf("changedFiles() outside git = %v, %v, want nil", changed, err)
+	}
+}
+
+func TestOnlyChanged(t *testing.T) {
+	problems := []fileValidationError{
+		{file: "index.md", line: 3, error: "links to missing, which doesn't exist"},
+		{file: "guides/setup.md", error: "orphan page /guides/setup: not in the navigation or linked from any page"},
+		{file: "notes.md", error: "orphan page /notes: not in the navigation or linked from any page"},
+	}
+	changed := map[string]bool{"guides/setup.md": true, "other.md": true}
+
+	got := onlyChanged(problems, changed)
+	if len(got) != 1 || got[0].file != "guides/setup.md" {
+		t.Errorf("onlyChanged() = %v, want the problem in guides/setup.md", got)
+	}
+	if got := onlyChanged(problems[:1], changed); got == nil || len(got) != 0 {
+		t.Errorf("onlyChanged() = %#v, want an empty list", got)
+	}
+	if got := onlyChanged(problems, nil); len(got) != len(problems) {
+		t.Errorf("onlyChanged() without a changed set = %v, want every problem", got)
+	}
+}
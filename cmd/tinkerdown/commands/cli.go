@@ -228,12 +228,16 @@ func createCLISource(name string, cfg config.SourceConfig, siteDir, currentFile
 		return source.NewCSVFileSource(name, cfg.File, siteDir, cfg.Options)
 	case "markdown":
 		return source.NewMarkdownSource(name, cfg.File, cfg.Anchor, siteDir, currentFile, cfg.IsReadonly())
+	case "frontmatter":
+		return source.NewFrontmatterSource(name, cfg.File, siteDir, currentFile, cfg.Options)
 	case "rest":
 		return source.NewRestSourceWithConfig(name, cfg)
 	case "pg":
 		return source.NewPostgresSourceWithConfig(name, cfg.Query, cfg.Options, cfg)
 	case "graphql":
 		return source.NewGraphQLSource(name, cfg, siteDir)
+	case "upload":
+		return source.NewUploadSource(name, cfg, siteDir)
 	default:
 		return nil, fmt.Errorf("unsupported source type for CLI: %s", cfg.Type)
 	}
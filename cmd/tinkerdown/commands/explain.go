@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/runtime"
+	"gopkg.in/yaml.v3"
+)
+
+// explainedSource is one declaration of the source being explained.
+type explainedSource struct {
+	where   string // "index.md (frontmatter)" or "tinkerdown.yaml"
+	cfg     config.SourceConfig
+	baseDir string   // Directory its relative paths resolve against
+	usedBy  []string // "file:line" of the lvt blocks bound to it
+}
+
+// explainSource prints how the runtime sees a source: its configuration as
+// parsed, the files it resolves to and the actions it handles, for every page
+// or tinkerdown.yaml under root that declares it. Sources are interpreted at
+// runtime, so this is everything that decides how an lvt-source behaves.
+func explainSource(root, name string) error {
+	declared := make(map[string]*explainedSource)
+	var order []string
+	var allNames []string
+	configs := make(map[string]*config.Config)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if n := d.Name(); strings.HasPrefix(n, "_") || strings.HasPrefix(n, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			relPath = path
+		}
+
+		cfgDir := nearestConfigDir(filepath.Dir(path), root)
+		cfg, ok := configs[cfgDir]
+		if !ok {
+			if cfg, err = config.LoadFromDir(cfgDir); err != nil {
+				return fmt.Errorf("failed to load config in %s: %w", cfgDir, err)
+			}
+			configs[cfgDir] = cfg
+			for n := range cfg.Sources {
+				allNames = append(allNames, n)
+			}
+		}
+
+		page, err := tinkerdown.ParseFile(path)
+		if err != nil {
+			return nil
+		}
+		for n := range page.Config.Sources {
+			allNames = append(allNames, n)
+		}
+
+		// Page sources shadow the ones in tinkerdown.yaml
+		var src *explainedSource
+		if pageCfg, ok := page.Config.Sources[name]; ok {
+			runtimeCfg, err := runtimeSourceConfig(pageCfg)
+			if err != nil {
+				return fmt.Errorf("%s: source %q: %w", relPath, name, err)
+			}
+			src = &explainedSource{where: relPath + " (frontmatter)", cfg: runtimeCfg, baseDir: filepath.Dir(path)}
+			declared[src.where] = src
+			order = append(order, src.where)
+		} else if siteCfg, ok := cfg.Sources[name]; ok {
+			where, err := filepath.Rel(root, filepath.Join(cfgDir, "tinkerdown.yaml"))
+			if err != nil {
+				where = "tinkerdown.yaml"
+			}
+			if src = declared[where]; src == nil {
+				src = &explainedSource{where: where, cfg: siteCfg, baseDir: cfgDir}
+				declared[where] = src
+				order = append(order, where)
+			}
+		}
+		if src == nil {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		_, codeBlocks, _, err := tinkerdown.ParseMarkdown(content)
+		if err != nil {
+			return nil
+		}
+		for _, cb := range codeBlocks {
+			if cb.Type == "lvt" && strings.Contains(cb.Content, `lvt-source="`+name+`"`) {
+				src.usedBy = append(src.usedBy, fmt.Sprintf("%s:%d", relPath, cb.Line))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	if len(order) == 0 {
+		sort.Strings(allNames)
+		allNames = dedupe(allNames)
+		hint := "no sources are declared"
+		if n := tinkerdown.ClosestName(name, allNames); n != "" {
+			hint = fmt.Sprintf("did you mean %q?", n)
+		} else if len(allNames) > 0 {
+			hint = "available sources: " + strings.Join(allNames, ", ")
+		}
+		return fmt.Errorf("unknown source %q (%s)", name, hint)
+	}
+
+	for i, where := range order {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := printExplainedSource(name, declared[where]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printExplainedSource prints one declaration of a source.
+func printExplainedSource(name string, src *explainedSource) error {
+	fmt.Printf("Source %q\n", name)
+	fmt.Printf("  Declared in: %s\n\n", src.where)
+
+	out, err := yaml.Marshal(src.cfg)
+	if err != nil {
+		return fmt.Errorf("source %q: failed to format config: %w", name, err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		fmt.Printf("    %s\n", line)
+	}
+	fmt.Println()
+
+	paths := map[string]string{
+		"file":       src.cfg.File,
+		"path":       src.cfg.Path,
+		"query_file": src.cfg.QueryFile,
+	}
+	if src.cfg.Type == "sqlite" {
+		paths["db"] = src.cfg.DB
+		if paths["db"] == "" {
+			paths["db"] = "./tinkerdown.db"
+		}
+	}
+	var keys []string
+	for key, p := range paths {
+		if p != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		fmt.Println("  Resolved paths:")
+		for _, key := range keys {
+			p := paths[key]
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(src.baseDir, p)
+			}
+			status := ""
+			if _, err := os.Stat(p); err != nil {
+				status = " (missing)"
+			}
+			fmt.Printf("    %s: %s%s\n", key, p, status)
+		}
+	}
+
+	readonly := src.cfg.Readonly == nil || *src.cfg.Readonly
+	fmt.Printf("  Actions: %s\n", strings.Join(runtime.BuiltinActions(src.cfg.Type, readonly), ", "))
+
+	if len(src.usedBy) == 0 {
+		fmt.Println("  Used by: no lvt blocks")
+	} else {
+		fmt.Printf("  Used by: %s\n", strings.Join(src.usedBy, ", "))
+	}
+	return nil
+}
+
+// runtimeSourceConfig converts a page source to the config the runtime
+// creates it from. The two types share their YAML keys.
+func runtimeSourceConfig(src tinkerdown.SourceConfig) (config.SourceConfig, error) {
+	var cfg config.SourceConfig
+	data, err := yaml.Marshal(src)
+	if err != nil {
+		return cfg, err
+	}
+	err = yaml.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// dedupe removes repeated entries from a sorted slice.
+func dedupe(sorted []string) []string {
+	var out []string
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
 )
 
 // FixCommand implements the fix command to auto-fix common issues.
@@ -45,6 +47,8 @@ func FixCommand(args []string) error {
 	var fixedFiles int
 	var totalFixes int
 	var fileResults []fileFixResult
+	configs := make(map[string]*config.Config)
+	fixedSections := make(map[string]bool)
 
 	err = filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -102,6 +106,39 @@ func FixCommand(args []string) error {
 			})
 		}
 
+		// Repair item IDs in the page's markdown data sections
+		sections, err := pageMarkdownSections(path, absDir, configs)
+		if err != nil {
+			return nil
+		}
+		for _, sec := range sections {
+			if fixedSections[sec.key()] {
+				continue
+			}
+			fixedSections[sec.key()] = true
+
+			dataPath, err := filepath.Rel(absDir, sec.path)
+			if err != nil {
+				dataPath = sec.path
+			}
+			fixes, err := fixMarkdownSection(sec, dryRun)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // Nothing to repair until the data file exists
+			}
+			if err != nil {
+				fileResults = append(fileResults, fileFixResult{
+					file:  dataPath,
+					error: fmt.Sprintf("source %q: %v", sec.name, err),
+				})
+				continue
+			}
+			if len(fixes) > 0 {
+				fixedFiles++
+				totalFixes += len(fixes)
+				fileResults = append(fileResults, fileFixResult{file: dataPath, fixes: fixes})
+			}
+		}
+
 		return nil
 	})
 
@@ -0,0 +1,292 @@
+package commands
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/server"
+)
+
+// linkPattern matches link and image targets in a page's rendered HTML.
+// Quotes in code are escaped, so code samples don't match.
+var linkPattern = regexp.MustCompile(`\s(?:href|src)="([^"]*)"`)
+
+// idPattern matches element IDs, which fragments point at.
+var idPattern = regexp.MustCompile(`\sid="([^"]*)"`)
+
+// builtinPaths are served by the server itself rather than from a page or a
+// file under the site root.
+var builtinPaths = []string{"/assets/", "/api/", "/webhook/", "/playground", "/search-index.json", "/robots.txt", "/health", "/tags/"}
+
+const (
+	externalLinkWorkers = 8
+	externalLinkTimeout = 10 * time.Second
+)
+
+// pageLink is a link found in a page.
+type pageLink struct {
+	file   string // Page file, relative to the site root
+	source string // Page file's absolute path, to find the link's line
+	target string // Link as written (after rendering)
+	ref    *url.URL
+	err    error // Why target isn't a valid URL
+}
+
+// problem returns msg as a problem with the link, on its line when it can
+// be found in the page source.
+func (l pageLink) problem(msg string) fileValidationError {
+	e := fileValidationError{file: l.file, error: msg}
+	if content, err := os.ReadFile(l.source); err == nil {
+		for i, line := range strings.Split(string(content), "\n") {
+			if strings.Contains(line, l.target) {
+				e.line = i + 1
+				break
+			}
+		}
+	}
+	return e
+}
+
+// linkChecker resolves links against the pages and files of a site the way
+// the server would serve them.
+type linkChecker struct {
+	root    string
+	cfg     *config.Config
+	pages   []*server.Route            // In route order
+	routes  map[string]*server.Route   // Route pattern -> page
+	anchors map[string]map[string]bool // Route pattern -> element IDs in the page
+}
+
+// newLinkChecker discovers the pages of the site at root, as serve would.
+func newLinkChecker(root string) (*linkChecker, error) {
+	cfg, err := config.LoadFromDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	srv := server.NewWithConfig(root, cfg)
+	defer srv.StopRateLimiter()
+	if err := srv.Discover(); err != nil {
+		return nil, fmt.Errorf("failed to discover pages: %w", err)
+	}
+
+	c := &linkChecker{
+		root:    root,
+		cfg:     cfg,
+		pages:   srv.Routes(),
+		routes:  make(map[string]*server.Route),
+		anchors: make(map[string]map[string]bool),
+	}
+	for _, route := range c.pages {
+		c.routes[route.Pattern] = route
+		ids := make(map[string]bool)
+		for _, m := range idPattern.FindAllStringSubmatch(route.Page.StaticHTML, -1) {
+			ids[html.UnescapeString(m[1])] = true
+		}
+		c.anchors[route.Pattern] = ids
+	}
+	return c, nil
+}
+
+// links returns the links in a page.
+func (c *linkChecker) links(route *server.Route) []pageLink {
+	var links []pageLink
+	for _, m := range linkPattern.FindAllStringSubmatch(route.Page.StaticHTML, -1) {
+		link := pageLink{
+			file:   route.FilePath,
+			source: filepath.Join(c.root, route.FilePath),
+			target: html.UnescapeString(m[1]),
+		}
+		link.ref, link.err = url.Parse(link.target)
+		links = append(links, link)
+	}
+	return links
+}
+
+// checkLinks checks the links in every page of the site at root. Links to
+// pages, files or fragments that don't exist are returned as errors. With
+// checkExternal, links to other sites are requested too, and those that fail
+// or answer with an error status are returned as warnings: the network
+// shouldn't fail validation.
+func checkLinks(root string, checkExternal bool) (errs, warnings []fileValidationError, err error) {
+	c, err := newLinkChecker(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	external := make(map[string][]pageLink)
+	for _, route := range c.pages {
+		for _, link := range c.links(route) {
+			if link.err != nil {
+				errs = append(errs, link.problem(fmt.Sprintf("broken link %q: %v", link.target, link.err)))
+				continue
+			}
+			if link.ref.Scheme != "" || link.ref.Host != "" {
+				if link.ref.Scheme == "http" || link.ref.Scheme == "https" || link.ref.Scheme == "" {
+					external[link.target] = append(external[link.target], link)
+				}
+				continue
+			}
+			if _, problem := c.resolve(route.Pattern, link.ref); problem != "" {
+				errs = append(errs, link.problem(fmt.Sprintf("broken link %q: %s", link.target, problem)))
+			}
+		}
+	}
+
+	if checkExternal {
+		for target, problem := range checkExternalLinks(external) {
+			for _, link := range external[target] {
+				warnings = append(warnings, link.problem(fmt.Sprintf("external link %q: %s", target, problem)))
+			}
+		}
+		sort.Slice(warnings, func(i, j int) bool {
+			if warnings[i].file != warnings[j].file {
+				return warnings[i].file < warnings[j].file
+			}
+			return warnings[i].line < warnings[j].line
+		})
+	}
+	return errs, warnings, nil
+}
+
+// resolve returns the page a link on the page at pagePath leads to (nil for
+// files and the server's own paths), and what is wrong with the link, or ""
+// if it resolves.
+func (c *linkChecker) resolve(pagePath string, ref *url.URL) (*server.Route, string) {
+	if strings.Contains(ref.Path, "{{") {
+		return nil, "" // Filled in by a template when the page renders
+	}
+
+	target := pagePath
+	if ref.Path != "" {
+		target = (&url.URL{Path: pagePath}).ResolveReference(&url.URL{Path: ref.Path}).Path
+	}
+
+	route, ok := c.route(target)
+	if !ok {
+		if c.isFile(target) || isBuiltinPath(target) {
+			return nil, ""
+		}
+		return nil, fmt.Sprintf("no page or file at %s", target)
+	}
+	if ref.Fragment != "" && !c.anchors[route.Pattern][ref.Fragment] {
+		return route, fmt.Sprintf("no heading #%s in %s", ref.Fragment, route.FilePath)
+	}
+	return route, ""
+}
+
+// route returns the page a path is served from, following the redirects the
+// server makes for the other trailing-slash spelling and for paths outside
+// the locale directories.
+func (c *linkChecker) route(p string) (*server.Route, bool) {
+	candidates := []string{p}
+	if strings.HasSuffix(p, "/") && p != "/" {
+		candidates = append(candidates, strings.TrimSuffix(p, "/"))
+	} else if !strings.HasSuffix(p, "/") {
+		candidates = append(candidates, p+"/")
+	}
+	if len(c.cfg.Locales) > 0 && c.cfg.PathLocale(p) == "" {
+		candidates = append(candidates, "/"+c.cfg.Locales[0].Code+p)
+	}
+	for _, candidate := range candidates {
+		if route, ok := c.routes[candidate]; ok {
+			return route, true
+		}
+	}
+	return nil, false
+}
+
+// isFile reports whether p names a file under the site root. Page sources
+// are excluded: the server doesn't serve them.
+func (c *linkChecker) isFile(p string) bool {
+	rel := strings.TrimPrefix(path.Clean(p), "/")
+	if rel == "" || strings.EqualFold(path.Ext(rel), ".md") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(c.root, filepath.FromSlash(rel)))
+	return err == nil && !info.IsDir()
+}
+
+func isBuiltinPath(p string) bool {
+	for _, prefix := range builtinPaths {
+		if p == prefix || strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkExternalLinks requests each URL, a few at a time, and returns what
+// went wrong for those that failed or answered with an error status.
+func checkExternalLinks(links map[string][]pageLink) map[string]string {
+	client := &http.Client{Timeout: externalLinkTimeout}
+	jobs := make(chan string)
+	problems := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < externalLinkWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				if problem := checkExternalLink(client, target); problem != "" {
+					mu.Lock()
+					problems[target] = problem
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for target := range links {
+		jobs <- target
+	}
+	close(jobs)
+	wg.Wait()
+	return problems
+}
+
+// checkExternalLink requests target with HEAD, falling back to GET for
+// servers that don't allow HEAD.
+func checkExternalLink(client *http.Client, target string) string {
+	if strings.HasPrefix(target, "//") {
+		target = "https:" + target
+	}
+	status, err := requestStatus(client, http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = requestStatus(client, http.MethodGet, target)
+	}
+	switch {
+	case err != nil && os.IsTimeout(err):
+		return fmt.Sprintf("timed out after %s", externalLinkTimeout)
+	case err != nil:
+		return err.Error()
+	case status >= 400:
+		return fmt.Sprintf("HTTP %d", status)
+	}
+	return ""
+}
+
+func requestStatus(client *http.Client, method, target string) (int, error) {
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "tinkerdown-link-checker")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/testutil"
+)
+
+func TestCheckLinks(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md": "# Home\n\n" +
+			"See the [guide](/guides/intro), [setup](guides/setup#install) and [the logo](images/logo.png).\n\n" +
+			"Jump to [usage](#usage).\n\n" +
+			"This [page was moved](/guides/old-page).\n\n" +
+			"A [missing section](/guides/intro#nowhere).\n\n" +
+			"## Usage\n",
+		"guides/intro.md": "# Intro\n\nBack [home](../) or on to [setup](setup/).\n",
+		"guides/setup.md": "# Setup\n\n## Install\n",
+		"images/logo.png": "png",
+	})
+
+	errs, warnings, err := checkLinks(dir, false)
+	if err != nil {
+		t.Fatalf("checkLinks() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none without --check-external", warnings)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v, want 2", errs)
+	}
+	if errs[0].location() != "index.md:7" || !strings.Contains(errs[0].error, `"/guides/old-page": no page or file at /guides/old-page`) {
+		t.Errorf("errors[0] = %+v, want the dead link on index.md:7", errs[0])
+	}
+	if errs[1].location() != "index.md:9" || !strings.Contains(errs[1].error, "no heading #nowhere in guides/intro.md") {
+		t.Errorf("errors[1] = %+v, want the missing anchor on index.md:9", errs[1])
+	}
+
+	if err := ValidateCommand([]string{dir, "--check-links"}); err == nil {
+		t.Error("ValidateCommand(--check-links) error = nil, want a dead link failure")
+	}
+	if err := ValidateCommand([]string{dir}); err != nil {
+		t.Errorf("ValidateCommand() error = %v, links are only checked with --check-links", err)
+	}
+}
+
+func TestCheckExternalLinks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md": "# Home\n\n[ok](" + ts.URL + "/ok)\n\n[gone](" + ts.URL + "/gone)\n\n[no head](" + ts.URL + "/no-head)\n",
+	})
+
+	errs, warnings, err := checkLinks(dir, true)
+	if err != nil {
+		t.Fatalf("checkLinks() error = %v", err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("errors = %v, external links only warn", errs)
+	}
+	if len(warnings) != 1 || warnings[0].location() != "index.md:5" || !strings.Contains(warnings[0].error, "HTTP 404") {
+		t.Errorf("warnings = %v, want HTTP 404 for /gone on index.md:5", warnings)
+	}
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/source"
+)
+
+// markdownSection is a markdown data section used by a page's source.
+type markdownSection struct {
+	name     string // Source name
+	path     string // Absolute path of the data file
+	anchor   string
+	readonly bool
+}
+
+// key identifies the section, so sections shared by several pages are only handled once.
+func (s markdownSection) key() string {
+	return s.path + s.anchor
+}
+
+// pageMarkdownSections returns the markdown sources a page declares in its
+// frontmatter or inherits from its app's tinkerdown.yaml. Data files are
+// resolved against the page's directory, which the page's app is served from.
+// The sources ParseFile generates for plain task lists are left out: they
+// rely on content-based IDs by design.
+func pageMarkdownSections(path, root string, configs map[string]*config.Config) ([]markdownSection, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	fm, _, _, err := tinkerdown.ParseMarkdown(content)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgDir := nearestConfigDir(filepath.Dir(path), root)
+	cfg, ok := configs[cfgDir]
+	if !ok {
+		if cfg, err = config.LoadFromDir(cfgDir); err != nil {
+			return nil, fmt.Errorf("failed to load config in %s: %w", cfgDir, err)
+		}
+		configs[cfgDir] = cfg
+	}
+
+	sources := pageSourceConfigs(cfg.Sources)
+	for name, src := range fm.Sources {
+		sources[name] = src
+	}
+
+	var sections []markdownSection
+	for name, src := range sources {
+		if src.Type != "markdown" || src.File == "" || src.Anchor == "" {
+			continue
+		}
+		file := src.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+		sections = append(sections, markdownSection{
+			name:     name,
+			path:     file,
+			anchor:   src.Anchor,
+			readonly: src.Readonly == nil || *src.Readonly,
+		})
+	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].name < sections[j].name })
+	return sections, nil
+}
+
+// checkMarkdownSection checks the item IDs of a section (see
+// source.CheckMarkdownIDs) and returns its errors, as one message with a line
+// per issue, and its warnings.
+func checkMarkdownSection(sec markdownSection, relPath string) (errs []string, warnings []string) {
+	content, err := os.ReadFile(sec.path)
+	if err != nil {
+		return []string{fmt.Sprintf("source %q: failed to read %s: %v", sec.name, relPath, err)}, nil
+	}
+	issues, err := source.CheckMarkdownIDs(sec.name, string(content), sec.anchor, sec.readonly)
+	if err != nil {
+		return []string{fmt.Sprintf("source %q: %v", sec.name, err)}, nil
+	}
+	for _, issue := range issues {
+		msg := fmt.Sprintf("line %d (%s): %s", issue.Line, normalizedAnchor(sec.anchor), issue.Message)
+		if issue.Warning {
+			warnings = append(warnings, msg)
+		} else {
+			errs = append(errs, msg)
+		}
+	}
+	return errs, warnings
+}
+
+// fixMarkdownSection repairs the item IDs of a section (see
+// source.FixMarkdownIDs), writing the data file unless dryRun is set.
+func fixMarkdownSection(sec markdownSection, dryRun bool) ([]string, error) {
+	content, err := os.ReadFile(sec.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	fixed, fixes, err := source.FixMarkdownIDs(sec.name, string(content), sec.anchor, sec.readonly)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixes) > 0 && !dryRun {
+		if err := os.WriteFile(sec.path, []byte(fixed), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return fixes, nil
+}
+
+// normalizedAnchor returns the anchor with its leading #.
+func normalizedAnchor(anchor string) string {
+	return "#" + strings.TrimPrefix(anchor, "#")
+}
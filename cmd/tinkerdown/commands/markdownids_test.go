@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateAndFixMarkdownIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	page := "---\ntitle: Tasks\nsources:\n  tasks:\n    type: markdown\n    file: ./_data/tasks.md\n    anchor: \"#tasks\"\n    readonly: false\n---\n# Tasks\n\n```lvt\n<ul lvt-source=\"tasks\">{{range .Data}}<li>{{.Text}}</li>{{end}}</ul>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "_data"), 0755); err != nil {
+		t.Fatalf("Failed to create data dir: %v", err)
+	}
+	dataPath := filepath.Join(tmpDir, "_data", "tasks.md")
+	data := "# Tasks\n\n- [ ] Buy milk <!-- id:t1 -->\n- [ ] Walk dog <!-- id:t1 -->\n- [x] Pay rent\n"
+	if err := os.WriteFile(dataPath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+
+	if err := ValidateCommand([]string{tmpDir}); err == nil {
+		t.Fatal("ValidateCommand() error = nil, want duplicate ID failure")
+	}
+
+	// A dry run reports the fixes without applying them
+	if err := FixCommand([]string{tmpDir, "--dry-run"}); err != nil {
+		t.Fatalf("FixCommand(--dry-run) error = %v", err)
+	}
+	if content, _ := os.ReadFile(dataPath); string(content) != data {
+		t.Errorf("dry run changed the data file:\n%s", content)
+	}
+
+	if err := FixCommand([]string{tmpDir}); err != nil {
+		t.Fatalf("FixCommand() error = %v", err)
+	}
+	content, err := os.ReadFile(dataPath)
+	if err != nil {
+		t.Fatalf("Failed to read data: %v", err)
+	}
+	if strings.Count(string(content), "<!-- id:t1 -->") != 1 {
+		t.Errorf("duplicate ID not replaced:\n%s", content)
+	}
+	if strings.Count(string(content), "<!-- id:") != 3 {
+		t.Errorf("item without an ID not fixed:\n%s", content)
+	}
+
+	if err := ValidateCommand([]string{tmpDir}); err != nil {
+		t.Errorf("ValidateCommand() after fix error = %v", err)
+	}
+}
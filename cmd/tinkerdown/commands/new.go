@@ -1,13 +1,21 @@
 package commands
 
 import (
+	"bufio"
 	"embed"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/logging"
+	"github.com/livetemplate/tinkerdown/internal/suggest"
 )
 
 //go:embed all:templates
@@ -24,6 +32,7 @@ const (
 	categoryDataSources    = "Data Sources"
 	categoryPatterns       = "Patterns"
 	categoryAdvanced       = "Advanced"
+	categoryOther          = "Other" // Embedded templates missing from the catalog
 )
 
 var templateCatalog = []templateInfo{
@@ -43,20 +52,106 @@ var templateCatalog = []templateInfo{
 
 const templateListHint = "\n\nRun 'tinkerdown new --list' to see all templates with descriptions."
 
-// NewCommand implements the new command.
+// categorySlug returns the name of a category in qualified template names:
+// "Data Sources" -> "data-sources".
+func categorySlug(category string) string {
+	return strings.ToLower(strings.ReplaceAll(category, " ", "-"))
+}
+
+// availableTemplates returns the templates embedded under templates/, in
+// catalog order. Each directory there is a template; the catalog gives its
+// description and category, and ones missing from it are listed as Other.
+func availableTemplates() []templateInfo {
+	entries, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil
+	}
+	embedded := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() {
+			embedded[e.Name()] = true
+		}
+	}
+
+	var result []templateInfo
+	for _, t := range templateCatalog {
+		if embedded[t.Name] {
+			result = append(result, t)
+			delete(embedded, t.Name)
+		}
+	}
+	for _, e := range entries { // Sorted by name
+		if embedded[e.Name()] {
+			result = append(result, templateInfo{Name: e.Name(), Category: categoryOther})
+		}
+	}
+	return result
+}
+
+// resolveTemplate finds the template a name refers to: a template name
+// ("todo") or one qualified by its category ("data-sources/todo").
+func resolveTemplate(name string) (templateInfo, error) {
+	templates := availableTemplates()
+	category, base, qualified := strings.Cut(name, "/")
+	if !qualified {
+		base = name
+	}
+	for _, t := range templates {
+		if t.Name != base {
+			continue
+		}
+		if qualified && categorySlug(t.Category) != category {
+			return templateInfo{}, fmt.Errorf("unknown template '%s' (did you mean '%s/%s'?)%s", name, categorySlug(t.Category), t.Name, templateListHint)
+		}
+		return t, nil
+	}
+
+	candidates := make([]string, 0, 2*len(templates))
+	for _, t := range templates {
+		candidates = append(candidates, t.Name, categorySlug(t.Category)+"/"+t.Name)
+	}
+	if n := suggest.Closest(name, candidates); n != "" {
+		return templateInfo{}, fmt.Errorf("unknown template '%s' (did you mean '%s'?)%s", name, n, templateListHint)
+	}
+	return templateInfo{}, fmt.Errorf("unknown template '%s'\n\nAvailable templates: %s%s", name, strings.Join(templateNames(), ", "), templateListHint)
+}
+
+// Where the new wizard asks its questions. Tests replace them.
+var (
+	wizardIn  = os.Stdin
+	wizardOut = os.Stdout
+)
+
+// NewCommand implements the new command. args is the project name, or the
+// template and then the project name when templateName isn't given. Without
+// a template, and with a terminal to ask at, it runs the new wizard to pick
+// one (and the name, if missing); otherwise the template is basic.
 func NewCommand(args []string, templateName string) error {
+	if templateName == "" && len(args) < 2 && logging.IsTerminal(wizardIn) && logging.IsTerminal(wizardOut) {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		var err error
+		if templateName, name, err = newWizard(wizardIn, wizardOut, name); err != nil {
+			return err
+		}
+		args = []string{name}
+	}
+
 	if len(args) < 1 {
-		return fmt.Errorf("project name required\n\nUsage: tinkerdown new <project-name> [--template=<name>]\n\nAvailable templates: %s%s", strings.Join(templateNames(), ", "), templateListHint)
+		return fmt.Errorf("project name required\n\nUsage: tinkerdown new [<template>] <project-name>\n\nAvailable templates: %s%s", strings.Join(templateNames(), ", "), templateListHint)
+	}
+	if len(args) > 2 || (len(args) == 2 && templateName != "") {
+		return fmt.Errorf("too many arguments\n\nUsage: tinkerdown new [<template>] <project-name>")
+	}
+	if len(args) == 2 {
+		templateName, args = args[0], args[1:]
 	}
 
 	projectName := args[0]
-
-	// Validate project name
-	if projectName == "" {
-		return fmt.Errorf("project name cannot be empty")
-	}
-	if strings.Contains(projectName, " ") {
-		return fmt.Errorf("project name cannot contain spaces")
+	if err := validateProjectName(projectName); err != nil {
+		return err
 	}
 
 	// Default template
@@ -64,12 +159,14 @@ func NewCommand(args []string, templateName string) error {
 		templateName = "basic"
 	}
 
-	// Validate template name
-	if !isValidTemplate(templateName) {
-		return fmt.Errorf("unknown template '%s'\n\nAvailable templates: %s%s", templateName, strings.Join(templateNames(), ", "), templateListHint)
+	// Resolve the template, which may be qualified by its category
+	info, err := resolveTemplate(templateName)
+	if err != nil {
+		return err
 	}
+	templateName = info.Name
 
-	// Check if directory already exists
+	// Never overwrite: the project gets a directory of its own
 	if _, err := os.Stat(projectName); !os.IsNotExist(err) {
 		return fmt.Errorf("directory '%s' already exists", projectName)
 	}
@@ -84,28 +181,134 @@ func NewCommand(args []string, templateName string) error {
 	data := map[string]string{
 		"Title":       toTitle(baseName),
 		"ProjectName": baseName,
+		"Name":        baseName,
+		"Date":        time.Now().Format("2006-01-02"),
 	}
 
 	// Process template files
-	templateDir := "templates/" + templateName
-	err := fs.WalkDir(templatesFS, templateDir, func(path string, d fs.DirEntry, err error) error {
+	err = scaffoldTemplate(templatesFS, path.Join("templates", templateName), projectName, data)
+	if err != nil {
+		// Clean up on error
+		os.RemoveAll(projectName)
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	// Success message
+	fmt.Printf("✨ Created new app: %s (template: %s)\n\n", projectName, templateName)
+	printProjectStructure(projectName)
+	fmt.Printf("\n🚀 Next steps:\n")
+	fmt.Printf("   cd %s\n", projectName)
+	fmt.Printf("   tinkerdown serve\n\n")
+	fmt.Printf("📚 Your app will be available at http://localhost:8080\n")
+
+	return nil
+}
+
+// validateProjectName checks that name can be the new project's directory.
+func validateProjectName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project name cannot be empty")
+	}
+	if strings.Contains(name, " ") {
+		return fmt.Errorf("project name cannot contain spaces")
+	}
+	return nil
+}
+
+// newWizard asks which template to use, from a numbered list grouped by
+// category, and for the project name when name is empty. Answers are read a
+// line at a time from r; invalid ones are asked again.
+func newWizard(r io.Reader, w io.Writer, name string) (templateName, projectName string, err error) {
+	in := bufio.NewReader(r)
+	ask := func(question string) (string, error) {
+		fmt.Fprint(w, question)
+		answer, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || answer == "") {
+			fmt.Fprintln(w)
+			return "", fmt.Errorf("new cancelled: no answer")
+		}
+		return strings.TrimSpace(answer), nil
+	}
+
+	templates := availableTemplates()
+	fmt.Fprintln(w, "Pick a template for your app:")
+	category := ""
+	for i, t := range templates {
+		if t.Category != category {
+			category = t.Category
+			fmt.Fprintf(w, "\n  %s:\n", category)
+		}
+		fmt.Fprintf(w, "   %2d) %-20s %s\n", i+1, t.Name, t.Description)
+	}
+	fmt.Fprintln(w)
+
+	for templateName == "" {
+		answer, err := ask("Template (number or name) [basic]: ")
 		if err != nil {
-			return err
+			return "", "", err
 		}
+		if answer == "" {
+			answer = "basic"
+		}
+		if n, err := strconv.Atoi(answer); err == nil {
+			if n < 1 || n > len(templates) {
+				fmt.Fprintf(w, "  Pick a number from 1 to %d\n", len(templates))
+				continue
+			}
+			answer = templates[n-1].Name
+		}
+		t, err := resolveTemplate(answer)
+		if err != nil {
+			msg, _, _ := strings.Cut(err.Error(), "\n")
+			fmt.Fprintf(w, "  %s\n", msg)
+			continue
+		}
+		templateName = t.Name
+	}
 
-		// Calculate relative path from template root
-		relPath, err := filepath.Rel(templateDir, path)
+	for name == "" {
+		answer, err := ask("App name: ")
+		if err != nil {
+			return "", "", err
+		}
+		if err := validateProjectName(answer); err != nil {
+			fmt.Fprintf(w, "  %v\n", err)
+			continue
+		}
+		name = answer
+	}
+	fmt.Fprintln(w)
+	return templateName, name, nil
+}
+
+// scaffoldTemplate copies the template directory root of fsys into
+// targetDir, recursively. Placeholders are substituted from data in the
+// contents of .md, .yaml and .sh files (<<.Name>>) and in file and directory
+// names ({{.Name}}, since Windows doesn't allow < and > in names). A name
+// that would land outside targetDir is an error.
+func scaffoldTemplate(fsys fs.FS, root, targetDir string, data map[string]string) error {
+	return fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Calculate relative path from template root
+		relPath := strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+
 		// Skip the root directory itself
-		if relPath == "." {
+		if relPath == "" {
 			return nil
 		}
 
-		// Target path
-		targetPath := filepath.Join(projectName, relPath)
+		// Target path, with placeholders in names substituted
+		relPath, err = expandPlaceholders(relPath, data)
+		if err != nil {
+			return fmt.Errorf("failed to expand name %s: %w", path, err)
+		}
+		if !filepath.IsLocal(filepath.FromSlash(relPath)) {
+			return fmt.Errorf("template path %s expands to %s, outside the project", path, relPath)
+		}
+		targetPath := filepath.Join(targetDir, filepath.FromSlash(relPath))
 
 		if d.IsDir() {
 			// Create directory
@@ -113,7 +316,7 @@ func NewCommand(args []string, templateName string) error {
 		}
 
 		// Read file content
-		content, err := templatesFS.ReadFile(path)
+		content, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return fmt.Errorf("failed to read %s: %w", path, err)
 		}
@@ -153,32 +356,37 @@ func NewCommand(args []string, templateName string) error {
 
 		return nil
 	})
+}
 
+// expandPlaceholders substitutes data into the {{.Key}} placeholders of a
+// template file's path.
+func expandPlaceholders(name string, data map[string]string) (string, error) {
+	if !strings.Contains(name, "{{") {
+		return name, nil
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(name)
 	if err != nil {
-		// Clean up on error
-		os.RemoveAll(projectName)
-		return fmt.Errorf("failed to create project: %w", err)
+		return "", err
 	}
-
-	// Success message
-	fmt.Printf("✨ Created new app: %s (template: %s)\n\n", projectName, templateName)
-	printProjectStructure(projectName)
-	fmt.Printf("\n🚀 Next steps:\n")
-	fmt.Printf("   cd %s\n", projectName)
-	fmt.Printf("   tinkerdown serve\n\n")
-	fmt.Printf("📚 Your app will be available at http://localhost:8080\n")
-
-	return nil
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
 }
 
 // ListTemplates prints all available templates grouped by category.
 func ListTemplates() {
-	fmt.Println("Available templates:")
+	listTemplates(os.Stdout)
+}
+
+func listTemplates(w io.Writer) {
+	fmt.Fprintln(w, "Available templates:")
 
 	// Single pass: collect templates by category, preserving order of first appearance.
 	var categories []string
 	grouped := map[string][]templateInfo{}
-	for _, t := range templateCatalog {
+	for _, t := range availableTemplates() {
 		if _, exists := grouped[t.Category]; !exists {
 			categories = append(categories, t.Category)
 		}
@@ -186,29 +394,23 @@ func ListTemplates() {
 	}
 
 	for _, cat := range categories {
-		fmt.Printf("\n  %s:\n", cat)
+		fmt.Fprintf(w, "\n  %s (%s/):\n", cat, categorySlug(cat))
 		for _, t := range grouped[cat] {
-			fmt.Printf("    %-20s %s\n", t.Name, t.Description)
+			fmt.Fprintf(w, "    %-20s %s\n", t.Name, t.Description)
 		}
 	}
 
-	fmt.Println()
-	fmt.Println("Usage: tinkerdown new <project-name> --template=<name>")
-	fmt.Println("Default template: basic")
-}
-
-func isValidTemplate(name string) bool {
-	for _, t := range templateCatalog {
-		if t.Name == name {
-			return true
-		}
-	}
-	return false
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage: tinkerdown new <template> <project-name>")
+	fmt.Fprintln(w, "   or: tinkerdown new <project-name> --template=<template>")
+	fmt.Fprintln(w, "Templates can be qualified by category, e.g. data-sources/todo")
+	fmt.Fprintln(w, "Default template: basic")
 }
 
 func templateNames() []string {
-	names := make([]string, len(templateCatalog))
-	for i, t := range templateCatalog {
+	templates := availableTemplates()
+	names := make([]string, len(templates))
+	for i, t := range templates {
 		names[i] = t.Name
 	}
 	return names
@@ -1,10 +1,13 @@
 package commands
 
 import (
+	"bytes"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestNewCommandBasicTemplate(t *testing.T) {
@@ -359,6 +362,224 @@ func TestListTemplates(t *testing.T) {
 	}
 }
 
+func TestListTemplatesOutput(t *testing.T) {
+	var buf bytes.Buffer
+	listTemplates(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"  Getting Started (getting-started/):\n    basic ",
+		"  Data Sources (data-sources/):\n    todo ",
+		"graphql-explorer     Countries browser via GraphQL API",
+		"Usage: tinkerdown new <template> <project-name>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("listing missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "CONTRIBUTING") {
+		t.Error("listing should only include template directories")
+	}
+}
+
+func TestNewCommandPositionalMultiFileTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "countries")
+
+	defer chdir(t, tmpDir)()
+
+	// Template first, qualified by its category
+	if err := NewCommand([]string{"data-sources/graphql-explorer", "countries"}, ""); err != nil {
+		t.Fatalf("NewCommand failed: %v", err)
+	}
+
+	// Every file of the template directory is scaffolded, nested ones too
+	assertFileExists(t, projectDir, "index.md")
+	assertFileExists(t, projectDir, "README.md")
+	assertFileExists(t, projectDir, filepath.Join("queries", "countries.graphql"))
+	if content := readFile(t, filepath.Join(projectDir, "README.md")); !strings.Contains(content, "Countries") {
+		t.Errorf("Expected title to be substituted in README.md, got: %s", content)
+	}
+
+	// Running it again refuses to overwrite the project
+	if err := os.WriteFile(filepath.Join(projectDir, "index.md"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := NewCommand([]string{"graphql-explorer", "countries"}, "")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected 'already exists' error, got: %v", err)
+	}
+	if content := readFile(t, filepath.Join(projectDir, "index.md")); content != "edited" {
+		t.Errorf("index.md was overwritten: %q", content)
+	}
+}
+
+func TestScaffoldTemplateMultiFile(t *testing.T) {
+	projectDir := filepath.Join(t.TempDir(), "my-app")
+	data := map[string]string{"Title": "My App", "ProjectName": "my-app", "Name": "my-app", "Date": "2026-01-02"}
+
+	if err := scaffoldTemplate(os.DirFS("testdata/templates"), "multi", projectDir, data); err != nil {
+		t.Fatalf("scaffoldTemplate failed: %v", err)
+	}
+
+	// Placeholders are substituted in names and in text files; other files
+	// are copied as they are
+	want := map[string]string{
+		"my-app.md":              "# My App\n\nCreated 2026-01-02.\n",
+		"tinkerdown.yaml":        "title: \"My App\"\n",
+		"_data/tasks.md":         "# Tasks for my-app\n\n- [ ] Write the first page\n",
+		"my-app-notes/README.md": "# Notes\n\nStarted 2026-01-02.\n",
+		"assets/data.csv":        "name,<<.Name>>\n",
+	}
+	for name, content := range want {
+		if got := readFile(t, filepath.Join(projectDir, filepath.FromSlash(name))); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+	var files []string
+	filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(projectDir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if len(files) != len(want) {
+		t.Errorf("scaffolded files = %v, want %d", files, len(want))
+	}
+}
+
+func TestScaffoldTemplatePathSafety(t *testing.T) {
+	fsys := fstest.MapFS{"tmpl/{{.Name}}/index.md": {Data: []byte("# Escaped\n")}}
+	targetDir := filepath.Join(t.TempDir(), "project")
+
+	err := scaffoldTemplate(fsys, "tmpl", targetDir, map[string]string{"Name": ".."})
+	if err == nil || !strings.Contains(err.Error(), "outside the project") {
+		t.Errorf("scaffoldTemplate() error = %v, want a path outside the project", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(targetDir), "index.md")); !os.IsNotExist(err) {
+		t.Error("scaffoldTemplate() wrote a file outside the project")
+	}
+
+	// Unknown placeholders in names are errors, not "<no value>"
+	err = scaffoldTemplate(fsys, "tmpl", targetDir, map[string]string{"Title": "App"})
+	if err == nil {
+		t.Error("scaffoldTemplate() with a missing placeholder value should fail")
+	}
+}
+
+func TestNewWizard(t *testing.T) {
+	var out bytes.Buffer
+	// A typo and a number out of range are asked again, as is a bad name
+	answers := "tood\n99\n3\nmy app\nmy-app\n"
+	templateName, name, err := newWizard(strings.NewReader(answers), &out, "")
+	if err != nil {
+		t.Fatalf("newWizard failed: %v", err)
+	}
+	if templateName != "todo" || name != "my-app" {
+		t.Errorf("newWizard() = %q, %q, want todo, my-app", templateName, name)
+	}
+	for _, want := range []string{
+		"  Data Sources:\n    3) todo ",
+		"did you mean 'todo'?",
+		"Pick a number from 1 to",
+		"project name cannot contain spaces",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("wizard output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// The default template, and the name from the command line
+	templateName, name, err = newWizard(strings.NewReader("\n"), &out, "given")
+	if err != nil || templateName != "basic" || name != "given" {
+		t.Errorf("newWizard() = %q, %q, %v, want basic, given", templateName, name, err)
+	}
+
+	// Running out of input cancels
+	if _, _, err := newWizard(strings.NewReader("todo\n"), &out, ""); err == nil {
+		t.Error("newWizard() without a name should fail")
+	}
+}
+
+func TestNewCommandNonInteractiveNeverPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+	defer chdir(t, tmpDir)()
+
+	// Neither end is a terminal, as in CI: an answer waiting on stdin must
+	// not be read, and nothing is asked
+	in, err := os.Create(filepath.Join(tmpDir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	in.WriteString("3\n")
+	in.Seek(0, 0)
+	out, err := os.Create(filepath.Join(tmpDir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	oldIn, oldOut := wizardIn, wizardOut
+	wizardIn, wizardOut = in, out
+	defer func() { wizardIn, wizardOut = oldIn, oldOut }()
+
+	if err := NewCommand([]string{"ci-app"}, ""); err != nil {
+		t.Fatalf("NewCommand failed: %v", err)
+	}
+	assertFileExists(t, filepath.Join(tmpDir, "ci-app"), "get-pods.sh") // basic template
+
+	if offset, _ := in.Seek(0, 1); offset != 0 {
+		t.Errorf("NewCommand read %d bytes of stdin", offset)
+	}
+	if prompt := readFile(t, out.Name()); prompt != "" {
+		t.Errorf("NewCommand prompted: %q", prompt)
+	}
+
+	// Without a name it's still an error rather than a question
+	if err := NewCommand(nil, ""); err == nil || !strings.Contains(err.Error(), "project name required") {
+		t.Errorf("NewCommand() without a name error = %v", err)
+	}
+}
+
+func TestResolveTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "todo", want: "todo"},
+		{name: "data-sources/todo", want: "todo"},
+		{name: "advanced/wasm-source", want: "wasm-source"},
+		{name: "tood", wantErr: "did you mean 'todo'?"},
+		{name: "data-source/todo", wantErr: "did you mean 'data-sources/todo'?"},
+		{name: "patterns/todo", wantErr: "did you mean 'data-sources/todo'?"},
+		{name: "spreadsheet", wantErr: "Available templates: basic, tutorial"},
+	}
+	for _, tt := range tests {
+		got, err := resolveTemplate(tt.name)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveTemplate(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.Name != tt.want {
+			t.Errorf("resolveTemplate(%q) = %q, %v, want %q", tt.name, got.Name, err, tt.want)
+		}
+	}
+}
+
+func TestNewCommandTooManyArguments(t *testing.T) {
+	if err := NewCommand([]string{"todo", "app", "extra"}, ""); err == nil || !strings.Contains(err.Error(), "too many arguments") {
+		t.Errorf("NewCommand() with three arguments error = %v", err)
+	}
+	if err := NewCommand([]string{"todo", "app"}, "form"); err == nil || !strings.Contains(err.Error(), "too many arguments") {
+		t.Errorf("NewCommand() with --template and a positional template error = %v", err)
+	}
+}
+
 func TestNewCommandInvalidTemplate(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/livetemplate/tinkerdown/internal/server"
+)
+
+// findOrphans audits how readers get around the site at root. It returns
+// the pages nothing leads to: routable, but neither linked from another page
+// nor in the site navigation (the sidebar and prev/next links of site
+// mode). Home pages are entry points, and nav_hidden pages are left out of
+// the navigation on purpose, so neither is reported. It also returns the
+// links to pages that don't exist, where the page they link from is.
+func findOrphans(root string) ([]fileValidationError, error) {
+	c, err := newLinkChecker(root)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[string]bool)
+	var missing []fileValidationError
+	for _, route := range c.pages {
+		for _, link := range c.links(route) {
+			if link.err != nil || link.ref.Scheme != "" || link.ref.Host != "" {
+				continue
+			}
+			target, problem := c.resolve(route.Pattern, link.ref)
+			if target == nil {
+				if problem != "" {
+					missing = append(missing, link.problem(fmt.Sprintf("links to %s, which doesn't exist", link.target)))
+				}
+				continue
+			}
+			if target != route {
+				linked[target.Pattern] = true
+			}
+		}
+	}
+
+	// Site mode's sidebar and prev/next links lead to every page it serves
+	// that isn't hidden (the config's navigation, or else every page)
+	navigated := c.cfg.IsSiteMode()
+
+	var orphans []fileValidationError
+	for _, route := range c.pages {
+		if linked[route.Pattern] || route.Page.NavHidden || navigated || c.isHome(route) {
+			continue
+		}
+		orphans = append(orphans, fileValidationError{
+			file:  route.FilePath,
+			error: fmt.Sprintf("orphan page %s: not in the navigation or linked from any page", route.Pattern),
+		})
+	}
+	return append(orphans, missing...), nil
+}
+
+// isHome reports whether the page is where readers enter the site: its home
+// page, or a locale's.
+func (c *linkChecker) isHome(route *server.Route) bool {
+	if route.Pattern == "/" || (c.cfg.Site != nil && route.FilePath == c.cfg.Site.Home) {
+		return true
+	}
+	locale := c.cfg.PathLocale(route.Pattern)
+	return locale != "" && route.Pattern == "/"+locale+"/"
+}
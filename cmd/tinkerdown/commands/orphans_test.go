@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/testutil"
+)
+
+func TestFindOrphans(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":        "# Home\n\nStart with the [guide](guides/intro).\n",
+		"guides/intro.md": "# Intro\n\nNext: [setup](setup), then [the old page](old-page).\n",
+		"guides/setup.md": "# Setup\n\nBack to the [intro](intro).\n",
+		"notes.md":        "# Notes\n\nNothing links here.\n",
+		"draft.md":        "---\nnav_hidden: true\n---\n# Draft\n",
+	})
+
+	orphans, err := findOrphans(dir)
+	if err != nil {
+		t.Fatalf("findOrphans() error = %v", err)
+	}
+	var got []string
+	for _, o := range orphans {
+		got = append(got, o.location()+": "+o.error)
+	}
+	want := []string{
+		"notes.md: orphan page /notes: not in the navigation or linked from any page",
+		"guides/intro.md:3: links to old-page, which doesn't exist",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("orphans:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Site mode's navigation leads to every page
+	siteDir := testutil.WriteFiles(t, map[string]string{
+		"tinkerdown.yaml": "type: site\n",
+		"index.md":        "# Home\n",
+		"notes.md":        "# Notes\n",
+	})
+	if orphans, err := findOrphans(siteDir); err != nil || len(orphans) != 0 {
+		t.Errorf("findOrphans() in site mode = %v, %v, want none", orphans, err)
+	}
+}
+
+func TestValidateOrphansJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeValidationJSON(&buf, 1, 1, false, nil, nil, []fileValidationError{}); err != nil {
+		t.Fatalf("writeValidationJSON() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"orphans": []`) {
+		t.Errorf("JSON = %s, want an empty orphans list when the audit ran", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeValidationJSON(&buf, 1, 1, false, nil, nil, nil); err != nil {
+		t.Fatalf("writeValidationJSON() error = %v", err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := result["orphans"]; ok {
+		t.Error("orphans should be left out when the audit didn't run")
+	}
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// Prose checks for validate --prose. They read the page source line by line
+// and only look at prose: frontmatter, fenced code (lvt blocks included),
+// inline code, HTML tags and link targets are skipped.
+
+// commonMisspellings maps frequent typos to their spelling. They're flagged
+// with --prose even without a dictionary.
+var commonMisspellings = map[string]string{
+	"accomodate":   "accommodate",
+	"acheive":      "achieve",
+	"adress":       "address",
+	"agressive":    "aggressive",
+	"alot":         "a lot",
+	"apparantly":   "apparently",
+	"arguement":    "argument",
+	"begining":     "beginning",
+	"beleive":      "believe",
+	"calender":     "calendar",
+	"commited":     "committed",
+	"comming":      "coming",
+	"completly":    "completely",
+	"concious":     "conscious",
+	"definately":   "definitely",
+	"dependancy":   "dependency",
+	"enviroment":   "environment",
+	"existance":    "existence",
+	"explaination": "explanation",
+	"familar":      "familiar",
+	"finaly":       "finally",
+	"foward":       "forward",
+	"goverment":    "government",
+	"guarentee":    "guarantee",
+	"immediatly":   "immediately",
+	"independant":  "independent",
+	"lenght":       "length",
+	"neccessary":   "necessary",
+	"occured":      "occurred",
+	"occurence":    "occurrence",
+	"paramater":    "parameter",
+	"persistant":   "persistent",
+	"posible":      "possible",
+	"prefered":     "preferred",
+	"recieve":      "receive",
+	"reciever":     "receiver",
+	"recomend":     "recommend",
+	"refered":      "referred",
+	"relevent":     "relevant",
+	"seperate":     "separate",
+	"sucess":       "success",
+	"succesful":    "successful",
+	"supress":      "suppress",
+	"teh":          "the",
+	"tommorow":     "tomorrow",
+	"truely":       "truly",
+	"untill":       "until",
+	"wich":         "which",
+	"writting":     "writing",
+}
+
+// legitDoubles are words that are often correctly written twice in a row
+// ("what it is is", "he had had enough").
+var legitDoubles = map[string]bool{"had": true, "that": true, "is": true}
+
+var (
+	inlineCodePattern = regexp.MustCompile("`+[^`]*`+")
+	linkTargetPattern = regexp.MustCompile(`\]\([^)]*\)`)
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	urlPattern        = regexp.MustCompile(`\b(?:https?|ftp)://\S+`)
+	fencePattern      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	wordPattern       = regexp.MustCompile(`[\p{L}][\p{L}'’]*`)
+)
+
+// proseChecker holds the words the spell check accepts.
+type proseChecker struct {
+	words map[string]bool // Accepted words, lowercased; nil when no dictionary is configured
+	extra map[string]bool // prose.words, lowercased
+}
+
+// newProseChecker loads the dictionaries prose.dictionary lists, resolving
+// relative paths against the site root.
+func newProseChecker(root string, cfg config.ProseConfig) (*proseChecker, error) {
+	c := &proseChecker{extra: make(map[string]bool)}
+	for _, w := range cfg.Words {
+		c.extra[strings.ToLower(w)] = true
+	}
+	for _, dict := range cfg.Dictionary {
+		if !filepath.IsAbs(dict) {
+			dict = filepath.Join(root, dict)
+		}
+		f, err := os.Open(dict)
+		if err != nil {
+			return nil, fmt.Errorf("prose.dictionary: %w", err)
+		}
+		if c.words == nil {
+			c.words = make(map[string]bool)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if w := strings.TrimSpace(scanner.Text()); w != "" && !strings.HasPrefix(w, "#") {
+				c.words[strings.ToLower(w)] = true
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("prose.dictionary: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// check returns the prose warnings for the source of the page file.
+func (c *proseChecker) check(file, content string) []fileValidationError {
+	var warnings []fileValidationError
+	warn := func(line int, format string, args ...any) {
+		warnings = append(warnings, fileValidationError{file: file, line: line, error: fmt.Sprintf(format, args...)})
+	}
+	lines := strings.Split(content, "\n")
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	fence, fenceLine := "", 0
+	prev := "" // Last word of the paragraph so far, lowercased
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\r")
+		n := i + 1
+
+		if trailing := len(line) - len(strings.TrimRight(line, " \t")); trailing > 0 && strings.TrimSpace(line) != "" {
+			// Two spaces are a markdown line break
+			if fence != "" || line[len(line)-trailing:] != "  " {
+				warn(n, "trailing whitespace")
+			}
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence, fenceLine = m[1], n
+			} else if m[1][0] == fence[0] && len(m[1]) >= len(fence) && strings.TrimSpace(line[len(m[0]):]) == "" {
+				fence = ""
+			}
+			prev = ""
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			prev = "" // Paragraph break or indented code
+			continue
+		}
+
+		text := inlineCodePattern.ReplaceAllString(line, " ` ")
+		text = linkTargetPattern.ReplaceAllString(text, "] ")
+		text = htmlTagPattern.ReplaceAllString(text, " ")
+		text = urlPattern.ReplaceAllString(text, " ` ")
+
+		// Tokens are words and the breaks between them: anything other than
+		// whitespace between two words (punctuation, code) isn't a repeat
+		rest := text
+		for {
+			loc := wordPattern.FindStringIndex(rest)
+			if loc == nil {
+				break
+			}
+			if strings.TrimSpace(rest[:loc[0]]) != "" {
+				prev = ""
+			}
+			word := rest[loc[0]:loc[1]]
+			rest = rest[loc[1]:]
+
+			lower := strings.ToLower(word)
+			if lower == prev && !legitDoubles[lower] {
+				warn(n, "repeated word %q", word)
+			}
+			prev = lower
+
+			if problem := c.spelling(word); problem != "" {
+				warn(n, "%s", problem)
+			}
+		}
+		if strings.TrimSpace(rest) != "" {
+			prev = ""
+		}
+	}
+
+	if fence != "" {
+		warn(fenceLine, "code fence %s is never closed", fence)
+	}
+	return warnings
+}
+
+// spelling returns what is wrong with the spelling of word, or "". Words
+// with capitals after the first letter (names, acronyms, identifiers) are
+// left alone.
+func (c *proseChecker) spelling(word string) string {
+	word = strings.TrimRight(word, "'’")
+	for _, r := range []rune(word)[1:] {
+		if unicode.IsUpper(r) {
+			return ""
+		}
+	}
+	lower := strings.ToLower(word)
+	if c.extra[lower] {
+		return ""
+	}
+	if fix, ok := commonMisspellings[lower]; ok {
+		return fmt.Sprintf("%q is misspelled (%s)", word, fix)
+	}
+	if c.words == nil || len([]rune(lower)) < 2 || c.words[lower] {
+		return ""
+	}
+	for _, suffix := range []string{"'s", "’s"} {
+		if stem, ok := strings.CutSuffix(lower, suffix); ok && c.words[stem] {
+			return ""
+		}
+	}
+	return fmt.Sprintf("unknown word %q", word)
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func proseWarnings(t *testing.T, c *proseChecker, content string) []string {
+	t.Helper()
+	var got []string
+	for _, w := range c.check("page.md", content) {
+		got = append(got, w.location()+": "+w.error)
+	}
+	return got
+}
+
+func TestProseChecks(t *testing.T) {
+	c, err := newProseChecker(t.TempDir(), config.ProseConfig{})
+	if err != nil {
+		t.Fatalf("newProseChecker() error = %v", err)
+	}
+
+	content := "---\ntitle: The the title\n---\n" + // Frontmatter is skipped
+		"# Intro\n" +
+		"Read the\nthe guide first.\n" + // Repeated across a line break
+		"What it is is fine, and `x x` or [the](the) the link too.\n" +
+		"Line break  \n" + // Two spaces are a line break
+		"Trailing space \n" +
+		"We recieve it.\n" +
+		"```lvt\n<p>the the</p>\n```\n" +
+		"~~~go\nfunc f() {}\n"
+	want := []string{
+		`page.md:6: repeated word "the"`,
+		"page.md:9: trailing whitespace",
+		`page.md:10: "recieve" is misspelled (receive)`,
+		"page.md:14: code fence ~~~ is never closed",
+	}
+	got := proseWarnings(t, c, content)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestProseDictionary(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "words.txt"), []byte("# Site words\nthe\nguide\nis\nshort\nsee\nand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := newProseChecker(dir, config.ProseConfig{Dictionary: []string{"words.txt"}, Words: []string{"tinkerdown"}})
+	if err != nil {
+		t.Fatalf("newProseChecker() error = %v", err)
+	}
+
+	got := proseWarnings(t, c, "The Tinkerdown guide's gide is short, see `gide` and LiveTemplate.\n")
+	want := []string{`page.md:1: unknown word "gide"`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings = %v, want %v", got, want)
+	}
+
+	if _, err := newProseChecker(dir, config.ProseConfig{Dictionary: []string{"missing.txt"}}); err == nil {
+		t.Error("newProseChecker() with a missing dictionary should fail")
+	}
+}
+
+func TestValidateProse(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), []byte("# Home\n\nThis is the the home page.\n\n```go\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Prose problems are warnings: validation still passes
+	if err := ValidateCommand([]string{dir, "--prose"}); err != nil {
+		t.Errorf("ValidateCommand(--prose) error = %v", err)
+	}
+	if err := ValidateCommand([]string{dir, "--format=yaml"}); err == nil {
+		t.Error("ValidateCommand(--format=yaml) should reject the format")
+	}
+	if err := ValidateCommand([]string{dir, "--format", "yaml"}); err == nil {
+		t.Error("ValidateCommand(--format yaml) should reject the format")
+	}
+	if err := ValidateCommand([]string{dir, "--prose", "--format", "json"}); err != nil {
+		t.Errorf("ValidateCommand(--format json) error = %v", err)
+	}
+	if err := ValidateCommand([]string{dir, "--format"}); err == nil || !strings.Contains(err.Error(), "requires a value") {
+		t.Errorf("ValidateCommand(--format) error = %v, want a missing value error", err)
+	}
+	if err := ValidateCommand([]string{dir, "--formt=json"}); err == nil || !strings.Contains(err.Error(), `unknown flag "--formt=json"`) {
+		t.Errorf("ValidateCommand(--formt=json) error = %v, want an unknown flag error", err)
+	}
+
+	var buf bytes.Buffer
+	warnings := []fileValidationError{
+		{file: "index.md", line: 3, error: `repeated word "the"`},
+		{file: "index.md", line: 5, error: "code fence ``` is never closed"},
+	}
+	if err := writeValidationJSON(&buf, 1, 1, false, nil, warnings, nil); err != nil {
+		t.Fatalf("writeValidationJSON() error = %v", err)
+	}
+	var result struct {
+		Files    int
+		Errors   []validationProblem
+		Warnings []validationProblem
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if result.Files != 1 || result.Errors == nil || len(result.Errors) != 0 || len(result.Warnings) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	if w := result.Warnings[1]; w.File != "index.md" || w.Line != 5 || !strings.Contains(w.Message, "never closed") {
+		t.Errorf("warning = %+v", w)
+	}
+}
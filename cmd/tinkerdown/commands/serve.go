@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,7 +15,10 @@ import (
 	"time"
 
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/logging"
+	"github.com/livetemplate/tinkerdown/internal/metrics"
 	"github.com/livetemplate/tinkerdown/internal/server"
+	"github.com/livetemplate/tinkerdown/internal/tracing"
 )
 
 // ServeCommand implements the serve command.
@@ -22,12 +26,21 @@ func ServeCommand(args []string) error {
 	// Parse arguments
 	dir := "."
 	var configPath string
+	var envFile string
 	var port string
 	var host string
 	var watch *bool
 	var operator string
 	var allowExec bool
+	var untrusted bool
 	var headless bool
+	var staging bool
+	var warmup bool
+	openInBrowser := logging.IsTerminal(os.Stdout) // Not when output goes to a pipe or file
+	var enableMetrics bool
+	var accessLog bool
+	accessLogFormat := string(server.AccessLogCombined)
+	logLevel := "info"
 
 	// Parse flags
 	for i := 0; i < len(args); i++ {
@@ -50,6 +63,11 @@ func ServeCommand(args []string) error {
 				configPath = args[i+1]
 				i++
 			}
+		} else if arg == "--env-file" {
+			if i+1 < len(args) {
+				envFile = args[i+1]
+				i++
+			}
 		} else if arg == "--operator" || arg == "-o" {
 			if i+1 < len(args) {
 				operator = args[i+1]
@@ -57,20 +75,53 @@ func ServeCommand(args []string) error {
 			}
 		} else if arg == "--allow-exec" {
 			allowExec = true
+		} else if arg == "--untrusted" {
+			untrusted = true
 		} else if arg == "--headless" {
 			headless = true
+		} else if arg == "--staging" {
+			staging = true
+		} else if arg == "--warmup" {
+			warmup = true
+		} else if arg == "--access-log" {
+			accessLog = true
+		} else if arg == "--access-log-format" {
+			if i+1 < len(args) {
+				accessLogFormat = args[i+1]
+				accessLog = true
+				i++
+			}
+		} else if arg == "--metrics" {
+			enableMetrics = true
+		} else if arg == "--open" {
+			openInBrowser = true
+		} else if arg == "--no-open" {
+			openInBrowser = false
+		} else if arg == "--log-level" {
+			if i+1 < len(args) {
+				logLevel = args[i+1]
+				i++
+			}
 		} else if !strings.HasPrefix(arg, "-") {
 			// Positional argument (directory)
 			dir = arg
 		}
 	}
 
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	logging.SetLevel(level)
+
+	logFormat, err := server.ParseAccessLogFormat(accessLogFormat)
+	if err != nil {
+		return err
+	}
+
 	// Set operator identity (defaults to $USER if not specified)
 	config.SetOperator(operator)
 
-	// Set exec permission (disabled by default for security)
-	config.SetAllowExec(allowExec)
-
 	// Check if directory exists
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		return fmt.Errorf("directory does not exist: %s", dir)
@@ -82,6 +133,22 @@ func ServeCommand(args []string) error {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
+	// Load .env before the config and sources read the environment. The
+	// default file is optional; one named with --env-file must exist.
+	if envFile == "" {
+		if _, err := os.Stat(filepath.Join(absDir, config.DefaultEnvFile)); err == nil {
+			envFile = filepath.Join(absDir, config.DefaultEnvFile)
+		}
+	}
+	if envFile != "" {
+		names, err := config.LoadEnvFile(envFile)
+		if err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+		// Only the count: values, and often names, are secrets
+		fmt.Printf("🔑 Loaded %d variable(s) from %s\n", len(names), envFile)
+	}
+
 	// Load configuration
 	var cfg *config.Config
 	if configPath != "" {
@@ -98,6 +165,12 @@ func ServeCommand(args []string) error {
 		}
 	}
 
+	if untrusted && allowExec {
+		fmt.Printf("⚠️  --allow-exec ignored: exec is disabled with --untrusted\n")
+	}
+	// Exec is disabled by default for security
+	applyExecPolicy(allowExec, untrusted, cfg.ExecAllowlist)
+
 	// CLI flags override config
 	if port != "" {
 		portInt, err := strconv.Atoi(port)
@@ -115,6 +188,9 @@ func ServeCommand(args []string) error {
 	if headless {
 		cfg.Features.Headless = true
 	}
+	if staging {
+		cfg.Robots = "disallow"
+	}
 
 	if cfg.Features.Headless {
 		fmt.Printf("📚 Tinkerdown Headless Server\n\n")
@@ -130,6 +206,14 @@ func ServeCommand(args []string) error {
 		}
 	}
 
+	// Export spans over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		fmt.Printf("⚠️  Tracing disabled: %v\n", err)
+	} else if tracing.Enabled() {
+		fmt.Printf("🔭 Tracing to %s\n", os.Getenv(tracing.EndpointEnv))
+	}
+
 	// Create server
 	srv := server.NewWithConfig(absDir, cfg)
 
@@ -141,11 +225,30 @@ func ServeCommand(args []string) error {
 	// Print discovered pages (only in non-headless mode)
 	if !cfg.Features.Headless {
 		fmt.Printf("\nPages discovered:\n")
+		baseURL := browserURL(cfg.Server.Host, cfg.Server.Port)
+		fmt.Print(server.FormatRouteTable(srv.Routes(), baseURL, logging.IsTerminal(os.Stdout)))
+	}
+
+	// Serve Prometheus metrics, ahead of any page at the same path
+	if enableMetrics {
+		metrics.Enable()
 		for _, route := range srv.Routes() {
-			fmt.Printf("  %-30s %s\n", route.Pattern, route.FilePath)
+			if route.Pattern == metrics.Path {
+				fmt.Printf("⚠️  %s is hidden by the metrics endpoint\n", route.FilePath)
+			}
 		}
 	}
 
+	// Build block templates up front so first page visits are fast
+	if warmup && !cfg.Features.Headless {
+		start := time.Now()
+		errs := srv.Warmup(0)
+		for _, err := range errs {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+		fmt.Printf("\n🔥 Warmed up block templates in %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
 	// Enable watch mode if requested (and not in headless mode)
 	if cfg.Features.HotReload && !cfg.Features.Headless {
 		if err := srv.EnableWatch(true); err != nil {
@@ -178,10 +281,16 @@ func ServeCommand(args []string) error {
 	}
 	if config.IsExecAllowed() {
 		fmt.Printf("⚠️  Exec sources enabled (--allow-exec)\n")
+		if cfg.ExecAllowlist != nil {
+			fmt.Printf("🔒 Exec limited to %d allowed executable(s)\n", len(cfg.ExecAllowlist))
+		}
 	}
 	if cfg.Features.Headless {
 		fmt.Printf("🏥 Health endpoint at /health\n")
 	}
+	if enableMetrics {
+		fmt.Printf("📈 Metrics at %s\n", metrics.Path)
+	}
 	if cfg.IsAPIEnabled() {
 		fmt.Printf("🔌 REST API enabled at /api/sources/{name}\n")
 		if cfg.API.IsAuthEnabled() {
@@ -200,6 +309,9 @@ func ServeCommand(args []string) error {
 	if !cfg.Features.Headless {
 		fmt.Printf("⚡ Gzip compression enabled\n")
 	}
+	if accessLog {
+		fmt.Printf("📜 Access log (%s) on stdout\n", logFormat)
+	}
 	fmt.Printf("Press Ctrl+C to stop\n\n")
 
 	// Set up HTTP handler - compression is skipped in headless mode as it primarily serves JSON
@@ -207,6 +319,9 @@ func ServeCommand(args []string) error {
 	if !cfg.Features.Headless {
 		handler = server.WithCompression(srv)
 	}
+	if accessLog {
+		handler = server.WithAccessLog(handler, os.Stdout, logFormat)
+	}
 
 	// Set up graceful shutdown
 	httpServer := &http.Server{
@@ -215,7 +330,9 @@ func ServeCommand(args []string) error {
 	}
 
 	// Handle shutdown signals
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
@@ -231,6 +348,17 @@ func ServeCommand(args []string) error {
 			fmt.Printf("Warning: HTTP server shutdown error: %v\n", err)
 		}
 
+		// Shutdown doesn't track WebSocket connections, so close them and
+		// their sources along with the watcher and API sources
+		if err := srv.Close(); err != nil {
+			fmt.Printf("Warning: Failed to stop file watcher: %v\n", err)
+		}
+
+		// Send spans still buffered
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			fmt.Printf("Warning: Failed to flush traces: %v\n", err)
+		}
+
 		// Cancel schedule runner context
 		cancel()
 
@@ -243,13 +371,39 @@ func ServeCommand(args []string) error {
 		srv.StopRateLimiter()
 	}()
 
-	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	// Listen before opening the browser so its first request can't fail
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
 		return fmt.Errorf("server error: %w", err)
 	}
+	if openInBrowser && !cfg.Features.Headless {
+		if err := openBrowser(browserURL(cfg.Server.Host, cfg.Server.Port)); err != nil {
+			fmt.Printf("⚠️  Could not open a browser: %v\n", err)
+		}
+	}
+
+	if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server error: %w", err)
+	}
+
+	// Let the shutdown finish closing connections and sources before exiting
+	<-shutdownDone
 
 	return nil
 }
 
+// applyExecPolicy sets which commands exec sources and actions may run.
+// --untrusted disables exec entirely, overriding --allow-exec and the site's
+// exec_allowlist.
+func applyExecPolicy(allowExec, untrusted bool, allowlist []string) {
+	config.SetAllowExec(allowExec && !untrusted)
+	if untrusted {
+		config.SetExecAllowlist([]string{})
+		return
+	}
+	config.SetExecAllowlist(allowlist)
+}
+
 func init() {
 	log.SetFlags(0) // Remove timestamp from logs
 }
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestApplyExecPolicy(t *testing.T) {
+	defer applyExecPolicy(false, false, nil)
+
+	applyExecPolicy(true, false, nil)
+	if !config.IsExecAllowed() || config.CheckExecutable("anything", ".") != nil {
+		t.Error("--allow-exec without an allowlist should allow any executable")
+	}
+
+	applyExecPolicy(true, false, []string{"git"})
+	if config.CheckExecutable("git", ".") != nil || config.CheckExecutable("rm", ".") == nil {
+		t.Error("exec_allowlist should allow only the listed executables")
+	}
+
+	// --untrusted wins over --allow-exec and the site's allowlist
+	applyExecPolicy(true, true, []string{"git"})
+	if config.IsExecAllowed() {
+		t.Error("--untrusted should disable exec")
+	}
+	if config.CheckExecutable("git", ".") == nil {
+		t.Error("--untrusted should leave no executable allowed")
+	}
+}
@@ -2,7 +2,9 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -12,14 +14,67 @@ import (
 
 	"github.com/chromedp/chromedp"
 	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
 )
 
 // ValidateCommand implements the validate command.
 func ValidateCommand(args []string) error {
 	// Parse arguments
 	dir := "."
-	if len(args) > 0 {
-		dir = args[0]
+	linkCheck := false
+	externalCheck := false
+	proseCheck := false
+	orphanCheck := false
+	strict := false
+	changedOnly := false
+	since := ""
+	format := "text"
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--check-links" {
+			linkCheck = true
+		} else if arg == "--check-external" {
+			linkCheck = true
+			externalCheck = true
+		} else if arg == "--prose" {
+			proseCheck = true
+		} else if arg == "--orphans" {
+			orphanCheck = true
+		} else if arg == "--strict" {
+			strict = true
+		} else if arg == "--changed" {
+			changedOnly = true
+		} else if arg == "--since" || arg == "--format" {
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			if arg == "--since" {
+				changedOnly = true
+				since = args[i+1]
+			} else {
+				format = args[i+1]
+			}
+			i++
+		} else if strings.HasPrefix(arg, "--since=") {
+			changedOnly = true
+			since = strings.TrimPrefix(arg, "--since=")
+		} else if strings.HasPrefix(arg, "--format=") {
+			format = strings.TrimPrefix(arg, "--format=")
+		} else if strings.HasPrefix(arg, "--") {
+			return fmt.Errorf("unknown flag %q", arg)
+		} else if !strings.HasPrefix(arg, "-") {
+			dir = arg
+		}
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q (must be text or json)", format)
+	}
+
+	// Progress and the report go to stdout, unless the result is printed
+	// as JSON instead
+	var out io.Writer = os.Stdout
+	if format == "json" {
+		out = io.Discard
 	}
 
 	// Check if directory exists
@@ -33,13 +88,49 @@ func ValidateCommand(args []string) error {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
-	fmt.Printf("🔍 Validating tinkerdown files in: %s\n\n", absDir)
+	cfg, err := config.LoadFromDir(absDir)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	strict = strict || cfg.Validate.Strict
+
+	// With --changed, only check the files changed in git, and only report
+	// the problems of cross-file checks that are in them
+	var changed map[string]bool
+	if changedOnly {
+		if changed, err = changedFiles(absDir, since); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "🔍 Validating tinkerdown files in: %s\n", absDir)
+	if changedOnly {
+		switch {
+		case changed == nil:
+			fmt.Fprintf(out, "   Not a git repository, so checking every file\n")
+		case since != "":
+			fmt.Fprintf(out, "   Only checking files changed since %s\n", since)
+		default:
+			fmt.Fprintf(out, "   Only checking uncommitted changes\n")
+		}
+	}
+	fmt.Fprintf(out, "\n")
 
 	// Discover and validate all markdown files
 	var totalFiles int
 	var validFiles int
 	var totalErrors int
 	var fileErrors []fileValidationError
+	var fileWarnings []fileValidationError
+	configs := make(map[string]*config.Config)
+	checkedSections := make(map[string]bool)
+
+	var prose *proseChecker
+	if proseCheck {
+		if prose, err = newProseChecker(absDir, cfg.Prose); err != nil {
+			return err
+		}
+	}
 
 	err = filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -73,6 +164,9 @@ func ValidateCommand(args []string) error {
 		if err != nil {
 			relPath = path
 		}
+		if changed != nil && !changed[filepath.ToSlash(relPath)] {
+			return nil
+		}
 
 		totalFiles++
 
@@ -86,6 +180,48 @@ func ValidateCommand(args []string) error {
 			})
 			totalErrors++
 		} else {
+			// Warn about footnotes that won't render as written
+			if content, err := os.ReadFile(path); err == nil {
+				for _, w := range tinkerdown.FootnoteDuplicates(content) {
+					fileWarnings = append(fileWarnings, fileValidationError{file: relPath, error: w})
+				}
+
+				// Opinionated prose checks, when asked for
+				if prose != nil {
+					fileWarnings = append(fileWarnings, prose.check(relPath, string(content))...)
+				}
+			}
+
+			// Check the item IDs of the page's markdown data sections
+			idErrors := 0
+			sections, err := pageMarkdownSections(path, absDir, configs)
+			if err != nil {
+				return err
+			}
+			for _, sec := range sections {
+				if checkedSections[sec.key()] {
+					continue
+				}
+				checkedSections[sec.key()] = true
+
+				dataPath, err := filepath.Rel(absDir, sec.path)
+				if err != nil {
+					dataPath = sec.path
+				}
+				errs, warnings := checkMarkdownSection(sec, dataPath)
+				if len(errs) > 0 {
+					fileErrors = append(fileErrors, fileValidationError{
+						file:  dataPath,
+						error: strings.Join(errs, "\n"),
+					})
+					idErrors += len(errs)
+				}
+				for _, w := range warnings {
+					fileWarnings = append(fileWarnings, fileValidationError{file: dataPath, error: w})
+				}
+			}
+			totalErrors += idErrors
+
 			// Also validate Mermaid diagrams
 			mermaidErrors, err := validateMermaidDiagrams(path)
 			if err != nil {
@@ -101,9 +237,9 @@ func ValidateCommand(args []string) error {
 					error: fmt.Sprintf("Mermaid errors:\n  %s", errorMsg),
 				})
 				totalErrors += len(mermaidErrors)
-			} else {
+			} else if idErrors == 0 {
 				validFiles++
-				fmt.Printf("✓ %s\n", relPath)
+				fmt.Fprintf(out, "✓ %s\n", relPath)
 			}
 		}
 
@@ -114,45 +250,151 @@ func ValidateCommand(args []string) error {
 		return fmt.Errorf("failed to walk directory: %w", err)
 	}
 
+	// Check links between pages, and to other sites when asked
+	if linkCheck {
+		if externalCheck {
+			fmt.Fprintf(out, "\n🔗 Checking links (including external links)...\n")
+		} else {
+			fmt.Fprintf(out, "\n🔗 Checking links...\n")
+		}
+		linkErrors, linkWarnings, err := checkLinks(absDir, externalCheck)
+		if err != nil {
+			return err
+		}
+		linkErrors = onlyChanged(linkErrors, changed)
+		fileErrors = append(fileErrors, linkErrors...)
+		fileWarnings = append(fileWarnings, onlyChanged(linkWarnings, changed)...)
+		totalErrors += len(linkErrors)
+	}
+
+	// Audit pages nothing leads to, and links to pages that don't exist
+	var orphans []fileValidationError
+	if orphanCheck {
+		fmt.Fprintf(out, "\n🧭 Looking for orphan pages...\n")
+		if orphans, err = findOrphans(absDir); err != nil {
+			return err
+		}
+		if orphans == nil {
+			orphans = []fileValidationError{}
+		}
+		orphans = onlyChanged(orphans, changed)
+	}
+
 	// Print errors
 	if len(fileErrors) > 0 {
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
 		for _, fe := range fileErrors {
-			fmt.Printf("✗ %s:\n", fe.file)
+			fmt.Fprintf(out, "✗ %s:\n", fe.location())
 			// Indent error message
 			lines := strings.Split(fe.error, "\n")
 			for _, line := range lines {
 				if line != "" {
-					fmt.Printf("  %s\n", line)
+					fmt.Fprintf(out, "  %s\n", line)
 				}
 			}
-			fmt.Printf("\n")
+			fmt.Fprintf(out, "\n")
+		}
+	}
+
+	// Print warnings (they only fail validation in strict mode)
+	if len(fileWarnings) > 0 {
+		fmt.Fprintf(out, "\n")
+		for _, fw := range fileWarnings {
+			fmt.Fprintf(out, "⚠ %s: %s\n", fw.location(), fw.error)
+		}
+	}
+
+	// Print orphans (warnings too)
+	if len(orphans) > 0 {
+		fmt.Fprintf(out, "\nOrphans:\n")
+		for _, o := range orphans {
+			fmt.Fprintf(out, "⚠ %s: %s\n", o.location(), o.error)
 		}
 	}
 
 	// Print summary
 	separator := "\n" + strings.Repeat("─", 60) + "\n"
-	fmt.Print(separator)
-	fmt.Println("Summary:")
-	fmt.Printf("  Total files: %d\n", totalFiles)
-	fmt.Printf("  Valid:       %d\n", validFiles)
-	fmt.Printf("  Errors:      %d\n", totalErrors)
-	fmt.Printf("\n")
+	fmt.Fprint(out, separator)
+	fmt.Fprintln(out, "Summary:")
+	fmt.Fprintf(out, "  Total files: %d\n", totalFiles)
+	fmt.Fprintf(out, "  Valid:       %d\n", validFiles)
+	fmt.Fprintf(out, "  Errors:      %d\n", totalErrors)
+	if len(fileWarnings) > 0 {
+		fmt.Fprintf(out, "  Warnings:    %d\n", len(fileWarnings))
+	}
+	if len(orphans) > 0 {
+		fmt.Fprintf(out, "  Orphans:     %d\n", len(orphans))
+	}
+	fmt.Fprintf(out, "\n")
+
+	if format == "json" {
+		if err := writeValidationJSON(os.Stdout, totalFiles, validFiles, strict, fileErrors, fileWarnings, orphans); err != nil {
+			return err
+		}
+	}
 
 	if totalErrors > 0 {
-		fmt.Printf("✗ Validation failed with %d error(s)\n", totalErrors)
+		fmt.Fprintf(out, "✗ Validation failed with %d error(s)\n", totalErrors)
+		return fmt.Errorf("validation failed")
+	}
+	if warnings := len(fileWarnings) + len(orphans); strict && warnings > 0 {
+		fmt.Fprintf(out, "✗ Validation failed with %d warning(s) in strict mode\n", warnings)
 		return fmt.Errorf("validation failed")
 	}
 
-	fmt.Printf("✓ All checks passed!\n")
+	fmt.Fprintf(out, "✓ All checks passed!\n")
 	return nil
 }
 
 type fileValidationError struct {
 	file  string
+	line  int // 0 when the problem isn't on one line
 	error string
 }
 
+// location returns the file the problem is in, with its line when known.
+func (e fileValidationError) location() string {
+	if e.line > 0 {
+		return fmt.Sprintf("%s:%d", e.file, e.line)
+	}
+	return e.file
+}
+
+// validationProblem is an error or warning in validate --format=json output.
+type validationProblem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// writeValidationJSON writes the result of a validation as JSON. orphans is
+// nil when the orphan audit didn't run; strict records that warnings and
+// orphans failed the validation too.
+func writeValidationJSON(w io.Writer, files, valid int, strict bool, errs, warnings, orphans []fileValidationError) error {
+	problems := func(list []fileValidationError) []validationProblem {
+		result := make([]validationProblem, 0, len(list))
+		for _, p := range list {
+			result = append(result, validationProblem{File: p.file, Line: p.line, Message: p.error})
+		}
+		return result
+	}
+	result := struct {
+		Files    int                  `json:"files"`
+		Valid    int                  `json:"valid"`
+		Strict   bool                 `json:"strict,omitempty"`
+		Errors   []validationProblem  `json:"errors"`
+		Warnings []validationProblem  `json:"warnings"`
+		Orphans  *[]validationProblem `json:"orphans,omitempty"` // Set even when empty, if the audit ran
+	}{Files: files, Valid: valid, Strict: strict, Errors: problems(errs), Warnings: problems(warnings)}
+	if orphans != nil {
+		list := problems(orphans)
+		result.Orphans = &list
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
 // validateMermaidDiagrams validates Mermaid diagrams in a markdown file
 func validateMermaidDiagrams(filePath string) ([]string, error) {
 	// Read file content
@@ -97,7 +97,7 @@ func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "  tinkerdown validate [directory]  Validate markdown files")
 	fmt.Fprintln(w, "  tinkerdown fix [directory]       Auto-fix common issues")
 	fmt.Fprintln(w, "  tinkerdown blocks [directory]    Inspect code blocks")
-	fmt.Fprintln(w, "  tinkerdown new <name>            Create new app from template")
+	fmt.Fprintln(w, "  tinkerdown new [template] <name> Create new app from template")
 	fmt.Fprintln(w, "  tinkerdown new --list            List available templates")
 	fmt.Fprintln(w, "  tinkerdown cli <path> <action> <source>  CLI mode for CRUD operations")
 	fmt.Fprintln(w, "  tinkerdown version               Show version")
@@ -107,17 +107,31 @@ func printUsage(w io.Writer) {
 	fmt.Fprintln(w, "  tinkerdown serve                 # Serve current directory")
 	fmt.Fprintln(w, "  tinkerdown serve ./tutorials     # Serve tutorials directory")
 	fmt.Fprintln(w, "  tinkerdown serve --watch         # Serve with live reload")
+	fmt.Fprintln(w, "  tinkerdown serve --warmup        # Build all block templates at startup")
+	fmt.Fprintln(w, "  tinkerdown serve --no-open       # Don't open the site in your browser")
+	fmt.Fprintln(w, "  tinkerdown serve --metrics       # Serve Prometheus metrics at /metrics")
+	fmt.Fprintln(w, "  tinkerdown serve --access-log-format json  # Log each request as JSON")
+	fmt.Fprintln(w, "  tinkerdown serve --log-level debug  # Show debug logs")
+	fmt.Fprintln(w, "  tinkerdown serve --env-file .env.local  # Load variables from another file")
+	fmt.Fprintln(w, "  tinkerdown serve --untrusted     # Serve content you didn't write, with exec disabled")
+	fmt.Fprintln(w, "  tinkerdown serve --staging       # Keep search engines out (robots.txt disallows all)")
 	fmt.Fprintln(w, "  tinkerdown build app.md -o myapp # Build single-file app")
 	fmt.Fprintln(w, "  tinkerdown build ./docs -o docs  # Build directory into binary")
 	fmt.Fprintln(w, "  tinkerdown build app.md --target=linux/amd64  # Cross-compile")
 	fmt.Fprintln(w, "  tinkerdown validate              # Validate current directory")
 	fmt.Fprintln(w, "  tinkerdown validate examples/    # Validate specific directory")
+	fmt.Fprintln(w, "  tinkerdown validate --check-links  # Also report broken links between pages")
+	fmt.Fprintln(w, "  tinkerdown validate --strict     # Also fail on warnings")
+	fmt.Fprintln(w, "  tinkerdown validate --since main # Only files changed since main")
 	fmt.Fprintln(w, "  tinkerdown fix                   # Auto-fix issues in current directory")
 	fmt.Fprintln(w, "  tinkerdown fix --dry-run         # Preview fixes without applying")
 	fmt.Fprintln(w, "  tinkerdown blocks examples/      # Inspect blocks in examples/")
 	fmt.Fprintln(w, "  tinkerdown blocks . --verbose    # Show detailed block info")
-	fmt.Fprintln(w, "  tinkerdown new my-app            # Create new app (basic template)")
+	fmt.Fprintln(w, "  tinkerdown blocks . --validate   # Check lvt-source references")
+	fmt.Fprintln(w, "  tinkerdown blocks . --explain=tasks  # Show how a source is configured")
+	fmt.Fprintln(w, "  tinkerdown new my-app            # Create new app (pick a template, or basic)")
 	fmt.Fprintln(w, "  tinkerdown new my-app --template=todo  # Use todo template")
+	fmt.Fprintln(w, "  tinkerdown new todo my-app       # Same, template first")
 	fmt.Fprintln(w, "  tinkerdown new --list            # List all available templates")
 	fmt.Fprintln(w, "  tinkerdown cli app.md list tasks # List items from source")
 	fmt.Fprintln(w, "  tinkerdown cli . add tasks --text=\"New task\"  # Add item")
@@ -0,0 +1,217 @@
+package tinkerdown
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Code block line highlighting and line numbers.
+//
+// Fenced code blocks accept a line spec and a linenos flag after the language:
+//
+//	```go {1,3-5} linenos
+//
+// Blocks that use either render one <span class="code-line"> per line, with
+// "highlighted" added to the listed lines, inside <pre class="code-lines">.
+// linenos=false turns line numbers off for a block when they're enabled
+// site-wide. Other code blocks render exactly as goldmark's default.
+
+// codeLineSpecPattern matches a line highlight spec like "{1,3-5}" in a fence info string.
+var codeLineSpecPattern = regexp.MustCompile(`\{([\d\s,-]*)\}`)
+
+// lineRange is an inclusive range of 1-based line numbers.
+type lineRange struct {
+	start, end int
+}
+
+// parseLineRanges parses a spec like "1,3-5" into sorted, merged ranges.
+// Malformed or non-positive entries are ignored.
+func parseLineRanges(spec string) []lineRange {
+	var ranges []lineRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || start < 1 {
+			continue
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil || end < start {
+				continue
+			}
+		}
+		ranges = append(ranges, lineRange{start, end})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	var merged []lineRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end+1 {
+			if r.end > merged[n-1].end {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// formatLineRanges renders ranges back into the "1,3-5" spec form.
+func formatLineRanges(ranges []lineRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		if r.start == r.end {
+			parts[i] = strconv.Itoa(r.start)
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", r.start, r.end)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// containsLine reports whether line falls in any of the ranges.
+func containsLine(ranges []lineRange, line int) bool {
+	for _, r := range ranges {
+		if line >= r.start && line <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// codeLinesRenderer renders fenced code blocks, adding per-line markup when
+// a block asks for highlighted lines or line numbers.
+type codeLinesRenderer struct {
+	lineNumbers bool // Site-wide default for linenos
+}
+
+func (r *codeLinesRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *codeLinesRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.FencedCodeBlock)
+	language := n.Language(source)
+
+	var ranges []lineRange
+	lineNumbers := false
+	info := ""
+	if n.Info != nil {
+		info = string(n.Info.Text(source))
+	}
+	if !fenceSkipsCodeLines(info) {
+		if m := codeLineSpecPattern.FindStringSubmatch(info); m != nil {
+			ranges = parseLineRanges(m[1])
+		}
+		lineNumbers = r.lineNumbers
+		for _, field := range strings.Fields(info) {
+			switch field {
+			case "linenos", "linenos=true":
+				lineNumbers = true
+			case "linenos=false":
+				lineNumbers = false
+			}
+		}
+	}
+
+	if len(ranges) == 0 && !lineNumbers {
+		// Same markup as goldmark's default renderer
+		_, _ = w.WriteString("<pre><code")
+		writeLanguageClass(w, language)
+		_ = w.WriteByte('>')
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			html.DefaultWriter.RawWrite(w, line.Value(source))
+		}
+		_, _ = w.WriteString("</code></pre>\n")
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString(`<pre class="code-lines`)
+	if lineNumbers {
+		_, _ = w.WriteString(" line-numbers")
+	}
+	_ = w.WriteByte('"')
+	if len(ranges) > 0 {
+		fmt.Fprintf(w, ` data-line="%s"`, formatLineRanges(ranges))
+	}
+	_, _ = w.WriteString("><code")
+	writeLanguageClass(w, language)
+	_ = w.WriteByte('>')
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		class := "code-line"
+		if containsLine(ranges, i+1) {
+			class += " highlighted"
+		}
+		fmt.Fprintf(w, `<span class="%s" data-line-number="%d">`, class, i+1)
+		value := line.Value(source)
+		html.DefaultWriter.RawWrite(w, []byte(strings.TrimRight(string(value), "\r\n")))
+		_, _ = w.WriteString("</span>\n")
+	}
+	_, _ = w.WriteString("</code></pre>\n")
+	return ast.WalkContinue, nil
+}
+
+// writeLanguageClass writes the class="language-..." attribute goldmark uses.
+func writeLanguageClass(w util.BufWriter, language []byte) {
+	if language == nil {
+		return
+	}
+	_, _ = w.WriteString(` class="language-`)
+	html.DefaultWriter.Write(w, language)
+	_ = w.WriteByte('"')
+}
+
+// fenceSkipsCodeLines reports whether a fence info string marks a block that
+// isn't shown as plain code: lvt, server and wasm blocks are rewritten by
+// injectBlockAttributes, and mermaid blocks become diagrams.
+func fenceSkipsCodeLines(info string) bool {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "lvt", "embed-lvt", "mermaid":
+		return true
+	}
+	for _, f := range fields[1:] {
+		switch f {
+		case "lvt", "server", "wasm":
+			return true
+		}
+	}
+	return false
+}
+
+// codeLinesExtension registers codeLinesRenderer with goldmark.
+type codeLinesExtension struct {
+	lineNumbers bool
+}
+
+// Extend implements goldmark.Extender.
+func (e *codeLinesExtension) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&codeLinesRenderer{lineNumbers: e.lineNumbers}, 500),
+	))
+}
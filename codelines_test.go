@@ -0,0 +1,99 @@
+package tinkerdown
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var codeLineTagPattern = regexp.MustCompile(`<span class="code-line( highlighted)?" data-line-number="(\d+)">`)
+
+func TestCodeLineHighlighting(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "golden", "code_line_highlight.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error: %v", err)
+	}
+
+	if !strings.Contains(html, `<pre class="code-lines line-numbers" data-line="1,3-4"><code class="language-go">`) {
+		t.Errorf("code block missing line markup:\n%s", html)
+	}
+
+	var highlighted []string
+	matches := codeLineTagPattern.FindAllStringSubmatch(html, -1)
+	for _, m := range matches {
+		if m[1] != "" {
+			highlighted = append(highlighted, m[2])
+		}
+	}
+	if len(matches) != 4 {
+		t.Errorf("got %d code lines, want 4", len(matches))
+	}
+	if got := strings.Join(highlighted, ","); got != "1,3,4" {
+		t.Errorf("highlighted lines = %s, want 1,3,4", got)
+	}
+}
+
+func TestCodeLinesPlainBlocksUnchanged(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no spec", "```go\nx := 1\n```\n", `<pre><code class="language-go">x := 1
+</code></pre>`},
+		{"no language", "```\n<b>\n```\n", "<pre><code>&lt;b&gt;\n</code></pre>"},
+		{"mermaid ignores spec", "```mermaid {1}\ngraph TD\n```\n", `<pre><code class="language-mermaid">graph TD
+</code></pre>`},
+		{"disabled per block", "```go linenos=false\nx := 1\n```\n", `<pre><code class="language-go">x := 1
+</code></pre>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, html, err := ParseMarkdown([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseMarkdown() error: %v", err)
+			}
+			if !strings.Contains(html, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", html, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeLinesSiteWideLineNumbers(t *testing.T) {
+	_, _, html, err := parseMarkdown([]byte("```sh\necho hi\n```\n\n```sh linenos=false\necho bye\n```\n"), MarkdownOptions{LineNumbers: true})
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error: %v", err)
+	}
+	if !strings.Contains(html, `<pre class="code-lines line-numbers"><code class="language-sh"><span class="code-line" data-line-number="1">echo hi</span>`) {
+		t.Errorf("line_numbers should number blocks without linenos:\n%s", html)
+	}
+	if !strings.Contains(html, `<pre><code class="language-sh">echo bye`) {
+		t.Errorf("linenos=false should opt a block out:\n%s", html)
+	}
+}
+
+func TestParseLineRanges(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"1,3-5", "1,3-5"},
+		{" 5 , 1 ", "1,5"},
+		{"3-5,1-2", "1-5"},
+		{"2-4,3-6", "2-6"},
+		{"0,x,5-3,-2,7", "7"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := formatLineRanges(parseLineRanges(tt.spec)); got != tt.want {
+			t.Errorf("parseLineRanges(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package tinkerdown
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Fenced containers:
+//
+//	:::details Show answer
+//	Markdown content, rendered normally.
+//	:::
+//
+// "details" renders as a <details>/<summary> collapsible; "note", "warning"
+// and "tip" render as admonition boxes; "stats" lays its stat cards out in a
+// row. Containers nest when the outer fence is longer than the inner one
+// (::::note around :::details).
+
+// containerKinds lists the supported container names.
+var containerKinds = map[string]bool{
+	"details": true,
+	"note":    true,
+	"warning": true,
+	"tip":     true,
+	"stats":   true,
+}
+
+// KindContainer is the ast.NodeKind of Container nodes.
+var KindContainer = ast.NewNodeKind("Container")
+
+// Container is a block node for a :::kind fenced container.
+type Container struct {
+	ast.BaseBlock
+	ContainerKind string // details, note, warning, tip, stats
+	Title         string // text after the kind; may be empty
+	fenceLength   int
+}
+
+// Kind implements ast.Node.
+func (n *Container) Kind() ast.NodeKind {
+	return KindContainer
+}
+
+// Dump implements ast.Node.
+func (n *Container) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"ContainerKind": n.ContainerKind,
+		"Title":         n.Title,
+	}, nil)
+}
+
+type containerParser struct{}
+
+// parseContainerFence returns the fence length and the rest of the line for
+// a line starting with three or more colons.
+func parseContainerFence(line []byte) (int, string) {
+	i := 0
+	for i < len(line) && line[i] == ':' {
+		i++
+	}
+	if i < 3 {
+		return 0, ""
+	}
+	return i, strings.TrimSpace(string(line[i:]))
+}
+
+func (p *containerParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (p *containerParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	length, info := parseContainerFence(line[pos:])
+	if length == 0 || info == "" {
+		return nil, parser.NoChildren
+	}
+	kind, title, _ := strings.Cut(info, " ")
+	kind = strings.ToLower(kind)
+	if !containerKinds[kind] {
+		return nil, parser.NoChildren
+	}
+
+	node := &Container{
+		ContainerKind: kind,
+		Title:         strings.TrimSpace(title),
+		fenceLength:   length,
+	}
+	reader.Advance(segment.Len() - 1)
+	return node, parser.HasChildren
+}
+
+func (p *containerParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	c := node.(*Container)
+
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w < 4 {
+		length, rest := parseContainerFence(line[pos:])
+		if length >= c.fenceLength && rest == "" {
+			reader.Advance(segment.Len() - 1)
+			return parser.Close
+		}
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+func (p *containerParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p *containerParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *containerParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type containerRenderer struct{}
+
+func (r *containerRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindContainer, r.renderContainer)
+}
+
+func (r *containerRenderer) renderContainer(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	c := n.(*Container)
+
+	if c.ContainerKind == "details" {
+		if entering {
+			title := c.Title
+			if title == "" {
+				title = "Details"
+			}
+			fmt.Fprintf(w, "<details class=\"tinkerdown-details\">\n<summary>%s</summary>\n", html.EscapeString(title))
+		} else {
+			_, _ = w.WriteString("</details>\n")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if c.ContainerKind == "stats" {
+		if entering {
+			_, _ = w.WriteString("<div class=\"tinkerdown-stats\">\n")
+		} else {
+			_, _ = w.WriteString("</div>\n")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if entering {
+		title := c.Title
+		if title == "" {
+			title = strings.ToUpper(c.ContainerKind[:1]) + c.ContainerKind[1:]
+		}
+		fmt.Fprintf(w, "<div class=\"admonition admonition-%s\">\n<p class=\"admonition-title\">%s</p>\n",
+			c.ContainerKind, html.EscapeString(title))
+	} else {
+		_, _ = w.WriteString("</div>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+type containerExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *containerExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(&containerParser{}, 150),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&containerRenderer{}, 500),
+	))
+}
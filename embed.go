@@ -0,0 +1,119 @@
+package tinkerdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// embedHint shows the embed-lvt block syntax in parse errors.
+const embedHint = "Use: ```embed-lvt page=\"widgets/counter.md\" block=\"counter\""
+
+// resolveEmbed handles an embed-lvt block by copying the referenced lvt block
+// from another page into p, together with its state block and the page-level
+// source it reads from. The embedded block then runs on this page's WebSocket
+// like any other lvt block; site-level sources are shared already.
+//
+// Syntax:
+//
+//	```embed-lvt page="widgets/counter.md" block="counter"
+//	```
+//
+// page is resolved relative to the embedding file and must stay within the site root.
+// block is the id of an lvt block on that page (explicit id=... or lvt-N).
+func (p *Page) resolveEmbed(cb *CodeBlock, index int, sourceFile string) error {
+	ref, blockRef := cb.Metadata["page"], cb.Metadata["block"]
+	if ref == "" || blockRef == "" {
+		return NewParseError(sourceFile, cb.Line, "embed-lvt block requires page and block").WithHint(embedHint)
+	}
+	if !filepath.IsAbs(sourceFile) {
+		return NewParseError(sourceFile, cb.Line, "embed-lvt is only supported in page files")
+	}
+	if filepath.IsAbs(ref) {
+		return NewParseError(sourceFile, cb.Line, fmt.Sprintf("embed-lvt page %q must be a relative path", ref))
+	}
+
+	root := findSiteRoot(filepath.Dir(sourceFile))
+	targetPath := filepath.Join(filepath.Dir(sourceFile), filepath.FromSlash(ref))
+	if !withinRoot(root, targetPath) {
+		return NewParseError(sourceFile, cb.Line, fmt.Sprintf("embed-lvt page %q escapes the site root", ref))
+	}
+	for _, f := range p.embedStack {
+		if f == targetPath {
+			chain := make([]string, 0, len(p.embedStack)+1)
+			for _, s := range p.embedStack {
+				chain = append(chain, relToRoot(root, s))
+			}
+			chain = append(chain, relToRoot(root, targetPath))
+			return NewParseError(sourceFile, cb.Line, fmt.Sprintf("embed-lvt cycle detected: %s", strings.Join(chain, " -> ")))
+		}
+	}
+
+	target, err := parseFile(targetPath, p.embedStack, p.markdown)
+	if err != nil {
+		return NewParseError(sourceFile, cb.Line, fmt.Sprintf("embed-lvt page %q: %v", ref, err))
+	}
+
+	embedded, ok := target.InteractiveBlocks[blockRef]
+	if !ok {
+		ids := make([]string, 0, len(target.InteractiveBlocks))
+		for id := range target.InteractiveBlocks {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return NewParseError(sourceFile, cb.Line, fmt.Sprintf("embed-lvt page %q has no lvt block %q", ref, blockRef)).
+			WithHint(fmt.Sprintf("Available lvt blocks: %v", ids))
+	}
+	state := target.ServerBlocks[embedded.StateRef]
+
+	// Namespace the state block so it can't collide with this page's blocks
+	blockID := getBlockID(cb, index)
+	stateID := "embed-state-" + blockID
+	metadata := make(map[string]string, len(state.Metadata))
+	for k, v := range state.Metadata {
+		metadata[k] = v
+	}
+	p.ServerBlocks[stateID] = &ServerBlock{
+		ID:       stateID,
+		Language: state.Language,
+		Content:  state.Content,
+		Metadata: metadata,
+	}
+	p.InteractiveBlocks[blockID] = &InteractiveBlock{
+		ID:       blockID,
+		StateRef: stateID,
+		Content:  embedded.Content,
+		Metadata: cb.Metadata,
+	}
+
+	// Copy the page-level sources the state reads from. Computed sources also
+	// need their parent (chained computed sources aren't supported).
+	names := SourceNames(metadata["lvt-source"])
+	for _, name := range names {
+		if cfg, ok := target.Config.Sources[name]; ok && cfg.Type == "computed" && cfg.From != "" {
+			names = append(names, cfg.From)
+		}
+	}
+	for _, name := range names {
+		cfg, ok := target.Config.Sources[name]
+		if !ok {
+			continue // site-level source
+		}
+		if cfg.File == "" && (cfg.Type == "markdown" || cfg.Type == "frontmatter") {
+			// Same-file sources must keep reading the page they were defined on
+			cfg.File = target.SourceFile
+		}
+		if existing, ok := p.Config.Sources[name]; ok && !reflect.DeepEqual(existing, cfg) {
+			return NewParseError(sourceFile, cb.Line, fmt.Sprintf("embed-lvt page %q: source %q conflicts with a different source of the same name on this page", ref, name)).
+				WithHint("Rename one of the sources, or move the shared source to tinkerdown.yaml")
+		}
+		if p.Config.Sources == nil {
+			p.Config.Sources = make(map[string]SourceConfig)
+		}
+		p.Config.Sources[name] = cfg
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package tinkerdown
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const embedWidgetPage = "---\nsources:\n  clicks:\n    type: markdown\n    anchor: \"#clicks\"\n    readonly: false\n---\n# Counter\n\n## Clicks\n\n- click\n\n```lvt id=\"counter\"\n<div lvt-source=\"clicks\"><p>Count: {{len .Data}}</p></div>\n```\n"
+
+func TestParseFileEmbedLvt(t *testing.T) {
+	root := writeSiteFiles(t, map[string]string{
+		"index.md":           "# Home\n\n```embed-lvt page=\"widgets/counter.md\" block=\"counter\"\n```\n",
+		"widgets/counter.md": embedWidgetPage,
+	})
+
+	page, err := ParseFile(filepath.Join(root, "index.md"))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	block, ok := page.InteractiveBlocks["embed-0"]
+	if !ok {
+		t.Fatalf("embedded block not registered, got %v", page.InteractiveBlocks)
+	}
+	if !strings.Contains(block.Content, "Count: {{len .Data}}") {
+		t.Errorf("embedded block content = %q", block.Content)
+	}
+	state, ok := page.ServerBlocks[block.StateRef]
+	if !ok || state.Metadata["lvt-source"] != "clicks" {
+		t.Fatalf("embedded state block = %+v", state)
+	}
+
+	// Same-file markdown source must keep pointing at the widget page
+	src, ok := page.Config.Sources["clicks"]
+	if !ok {
+		t.Fatal("embedded source not copied to page")
+	}
+	if want := filepath.Join(root, "widgets", "counter.md"); src.File != want {
+		t.Errorf("embedded source file = %q, want %q", src.File, want)
+	}
+
+	if !strings.Contains(page.StaticHTML, `data-block-id="embed-0" data-block-type="lvt"`) {
+		t.Errorf("embed container missing from HTML:\n%s", page.StaticHTML)
+	}
+}
+
+func TestParseFileEmbedLvtErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "missing attributes",
+			files: map[string]string{
+				"index.md": "```embed-lvt page=\"widgets/counter.md\"\n```\n",
+			},
+			wantErr: "embed-lvt block requires page and block",
+		},
+		{
+			name: "unknown block",
+			files: map[string]string{
+				"index.md":           "```embed-lvt page=\"widgets/counter.md\" block=\"nope\"\n```\n",
+				"widgets/counter.md": embedWidgetPage,
+			},
+			wantErr: `has no lvt block "nope"`,
+		},
+		{
+			name: "escape site root",
+			files: map[string]string{
+				"index.md": "```embed-lvt page=\"../outside.md\" block=\"x\"\n```\n",
+			},
+			wantErr: "escapes the site root",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"index.md": "```embed-lvt page=\"other.md\" block=\"x\"\n```\n",
+				"other.md": "```embed-lvt page=\"index.md\" block=\"y\"\n```\n",
+			},
+			wantErr: "embed-lvt cycle detected: index.md -> other.md -> index.md",
+		},
+		{
+			name: "conflicting source",
+			files: map[string]string{
+				"index.md":           "---\nsources:\n  clicks:\n    type: json\n    file: clicks.json\n---\n```embed-lvt page=\"widgets/counter.md\" block=\"counter\"\n```\n",
+				"widgets/counter.md": embedWidgetPage,
+			},
+			wantErr: `source "clicks" conflicts`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeSiteFiles(t, tt.files)
+			_, err := ParseFile(filepath.Join(root, "index.md"))
+			if err == nil {
+				t.Fatal("ParseFile() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
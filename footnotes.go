@@ -0,0 +1,57 @@
+package tinkerdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// footnoteDefRegex matches a footnote definition line like "[^note]: text"
+var footnoteDefRegex = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:`)
+
+// FootnoteDuplicates reports footnote labels defined more than once in content.
+// Only the first definition of a label is rendered, so later ones are silently
+// dropped; each returned message names the label and the lines defining it.
+// Labels are case-sensitive, as in goldmark, and code blocks are ignored.
+func FootnoteDuplicates(content []byte) []string {
+	lines := make(map[string][]int)
+	var labels []string
+	inFence := false
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		if codeFenceRegex.Match(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		m := footnoteDefRegex.FindSubmatch(line)
+		if m == nil {
+			continue
+		}
+		label := string(m[1])
+		if _, seen := lines[label]; !seen {
+			labels = append(labels, label)
+		}
+		lines[label] = append(lines[label], i+1)
+	}
+
+	var warnings []string
+	sort.Strings(labels)
+	for _, label := range labels {
+		defs := lines[label]
+		if len(defs) < 2 {
+			continue
+		}
+		nums := make([]string, len(defs))
+		for i, n := range defs {
+			nums[i] = fmt.Sprint(n)
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"footnote [^%s] is defined more than once (lines %s); only the first definition is used",
+			label, strings.Join(nums, ", ")))
+	}
+	return warnings
+}
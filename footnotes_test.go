@@ -0,0 +1,75 @@
+package tinkerdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFootnotesCollectedInReferenceOrder(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "golden", "gfm_footnotes_order.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error: %v", err)
+	}
+
+	section := html[strings.Index(html, `class="footnotes"`):]
+	early := strings.Index(section, "Defined last, referenced first.")
+	late := strings.Index(section, "Defined first, referenced second.")
+	if early == -1 || late == -1 {
+		t.Fatalf("footnotes section missing definitions:\n%s", section)
+	}
+	if early > late {
+		t.Error("footnotes should be numbered in reference order, not definition order")
+	}
+	for _, want := range []string{
+		`<li id="fn:1">`,
+		`<li id="fn:2">`,
+		`href="#fnref:1" class="footnote-backref"`,
+		`href="#fnref1:1" class="footnote-backref"`, // second reference to the same footnote
+		`href="#fnref:2" class="footnote-backref"`,
+	} {
+		if !strings.Contains(section, want) {
+			t.Errorf("footnotes section missing %q", want)
+		}
+	}
+}
+
+func TestFootnoteDuplicates(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "no duplicates",
+			content: "Text[^a] and[^b].\n\n[^a]: One.\n[^b]: Two.\n",
+		},
+		{
+			name:    "duplicate label",
+			content: "Text[^a].\n\n[^a]: One.\n\nMore.\n\n[^a]: Again.\n",
+			want:    []string{"footnote [^a] is defined more than once (lines 3, 7); only the first definition is used"},
+		},
+		{
+			name:    "labels are case-sensitive",
+			content: "[^Note]: One.\n[^note]: Two.\n",
+		},
+		{
+			name:    "code block ignored",
+			content: "[^a]: One.\n\n```markdown\n[^a]: Example.\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FootnoteDuplicates([]byte(tt.content))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("FootnoteDuplicates() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,9 @@ var chartjsFS embed.FS
 //go:embed vendor/pico/*
 var picoFS embed.FS
 
+//go:embed page/*
+var pageFS embed.FS
+
 // ClientFS returns the embedded client files
 func ClientFS() fs.FS {
 	sub, err := fs.Sub(clientFS, "client")
@@ -87,3 +90,12 @@ func GetChartJS() ([]byte, error) {
 func GetPicoCSS() ([]byte, error) {
 	return picoFS.ReadFile("vendor/pico/pico.min.css")
 }
+
+// GetPageCSS returns the stylesheet of tinkerdown's pages, minified
+func GetPageCSS() ([]byte, error) {
+	css, err := pageFS.ReadFile("page/tinkerdown-page.css")
+	if err != nil {
+		return nil, err
+	}
+	return MinifyCSS(css), nil
+}
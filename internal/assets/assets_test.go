@@ -1,6 +1,7 @@
 package assets
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -120,3 +121,34 @@ func TestClientFS(t *testing.T) {
 	}
 	file.Close()
 }
+
+func TestMinifyCSS(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"comments and whitespace", "/* Theme */\n:root {\n    --accent: #06c;\n}\n", ":root{--accent:#06c}"},
+		{"selector lists and combinators", "a,\nb > c ,  d {\n  color: red;\n}", "a,b>c,d{color:red}"},
+		{"descendant pseudo-class", "nav :hover { x: 1 }", "nav :hover{x:1}"},
+		{"values keep their spaces", "p { margin: 0 auto; width: calc(100% - 2rem) }", "p{margin:0 auto;width:calc(100% - 2rem)}"},
+		{"strings", `a::after { content: " /* not a comment */ " }`, `a::after{content:" /* not a comment */ "}`},
+		{"media queries", "@media (max-width: 768px) and (hover: none) {\n  a { b: c; }\n}", "@media (max-width:768px) and (hover:none){a{b:c}}"},
+		{"comment between words", "a/* x */b { c: d }", "a b{c:d}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(MinifyCSS([]byte(tt.in))); got != tt.want {
+				t.Errorf("MinifyCSS() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPageCSS(t *testing.T) {
+	data, err := GetPageCSS()
+	if err != nil {
+		t.Fatalf("GetPageCSS failed: %v", err)
+	}
+	if len(data) == 0 || bytes.Contains(data, []byte("\n")) || bytes.Contains(data, []byte("/*")) {
+		t.Error("GetPageCSS should return the minified stylesheet")
+	}
+}
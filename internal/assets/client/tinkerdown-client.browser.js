@@ -62,7 +62,7 @@
           opacity: 1;
         }
       }
-    `,document.head.appendChild(n),document.body.appendChild(r)}createEnvelope(e,t,r={}){return{blockID:e,action:t,data:r}}getRegisteredBlocks(){return Array.from(this.handlers.keys())}clear(){this.handlers.clear(),this.debug&&console.log("[MessageRouter] Cleared all handlers")}};var re=class{constructor(e="livemdtools:persistence",t=!0,r=!1){this.storageKey=e,this.enabled=t&&this.isLocalStorageAvailable(),this.debug=r,!this.enabled&&t&&console.warn("[PersistenceManager] localStorage not available, persistence disabled")}isLocalStorageAvailable(){try{let e="__localStorage_test__";return localStorage.setItem(e,e),localStorage.removeItem(e),!0}catch{return!1}}saveCode(e,t){if(this.enabled)try{let r=this.loadAll();r.code[e]=t,r.timestamp=Date.now(),localStorage.setItem(this.storageKey,JSON.stringify(r)),this.debug&&console.log(`[PersistenceManager] Saved code for block: ${e}`)}catch(r){console.error("[PersistenceManager] Error saving code:",r)}}loadCode(e){if(!this.enabled)return null;try{return this.loadAll().code[e]||null}catch(t){return console.error("[PersistenceManager] Error loading code:",t),null}}loadAll(){if(!this.enabled)return{code:{},timestamp:Date.now()};try{let e=localStorage.getItem(this.storageKey);if(!e)return{code:{},timestamp:Date.now()};let t=JSON.parse(e);return!t.code||typeof t.code!="object"?(console.warn("[PersistenceManager] Invalid data structure, resetting"),{code:{},timestamp:Date.now()}):t}catch(e){return console.error("[PersistenceManager] Error loading data:",e),{code:{},timestamp:Date.now()}}}clearCode(e){if(this.enabled)try{let t=this.loadAll();delete t.code[e],t.timestamp=Date.now(),localStorage.setItem(this.storageKey,JSON.stringify(t)),this.debug&&console.log(`[PersistenceManager] Cleared code for block: ${e}`)}catch(t){console.error("[PersistenceManager] Error clearing code:",t)}}clearAll(){if(this.enabled)try{localStorage.removeItem(this.storageKey),this.debug&&console.log("[PersistenceManager] Cleared all persisted data")}catch(e){console.error("[PersistenceManager] Error clearing all data:",e)}}getPersistedBlocks(){if(!this.enabled)return[];let e=this.loadAll();return Object.keys(e.code)}hasPersistedCode(e){if(!this.enabled)return!1;let t=this.loadAll();return e in t.code}getLastUpdate(){return this.enabled?this.loadAll().timestamp:null}};var ne=class{constructor(e=!1){this.tabGroups=new Map;this.sendMessage=null;this.debug=e,this.init()}log(...e){this.debug&&console.log("[TabsController]",...e)}setMessageSender(e){this.sendMessage=e}init(){document.querySelectorAll(".tinkerdown-tabs").forEach(t=>{let r=t.dataset.tabsId;if(!r)return;let n=Array.from(t.querySelectorAll(".tinkerdown-tab")),i=t.querySelector("[data-tabs-content]");if(n.length===0)return;let a={id:r,container:t,tabs:n,activeIndex:0,contentPanel:i};this.tabGroups.set(r,a),n.forEach((o,l)=>{o.addEventListener("click",()=>this.handleTabClick(r,l)),o.addEventListener("keydown",c=>this.handleTabKeydown(c,r,l))}),this.wrapContent(t,i),this.log("Registered tab group:",r,"with",n.length,"tabs")}),this.log("Initialized with",this.tabGroups.size,"tab groups")}wrapContent(e,t){if(!t)return;let r=[],n=e.nextSibling;for(;n&&!(n instanceof HTMLElement&&n.classList.contains("tinkerdown-tabs")||n instanceof HTMLElement&&/^H[1-6]$/.test(n.tagName)&&!n.classList.contains("tinkerdown-tabs-heading"));)r.push(n),n=n.nextSibling;r.forEach(i=>{t.appendChild(i)})}handleTabClick(e,t){let r=this.tabGroups.get(e);if(!r)return;this.setActiveTab(r,t);let i=r.tabs[t].dataset.filter||"";this.log("Tab clicked:",e,"index:",t,"filter:",i),this.applyFilter(r,i)}handleTabKeydown(e,t,r){let n=this.tabGroups.get(t);if(!n)return;let i;switch(e.key){case"ArrowLeft":i=r===0?n.tabs.length-1:r-1;break;case"ArrowRight":i=r===n.tabs.length-1?0:r+1;break;case"Home":i=0;break;case"End":i=n.tabs.length-1;break;default:return}e.preventDefault(),this.setActiveTab(n,i),n.tabs[i].focus();let a=n.tabs[i].dataset.filter||"";this.applyFilter(n,a)}setActiveTab(e,t){e.tabs.forEach((r,n)=>{let i=n===t;r.classList.toggle("active",i),r.setAttribute("aria-selected",i?"true":"false"),r.setAttribute("tabindex",i?"0":"-1")}),e.activeIndex=t,e.contentPanel&&e.contentPanel.setAttribute("aria-labelledby",e.tabs[t].id||"")}applyFilter(e,t){let r=e.contentPanel?.querySelector(".tinkerdown-interactive-block");if(!r){this.log("No interactive block found for filtering");return}let n=r.dataset.blockId;if(!n){this.log("Interactive block has no ID");return}this.sendMessage?(this.log("Sending Filter action to block:",n,"filter:",t),this.sendMessage(n,"Filter",{filter:t})):this.log("No message sender configured")}getActiveIndex(e){return this.tabGroups.get(e)?.activeIndex??0}setTab(e,t){let r=this.tabGroups.get(e);!r||t<0||t>=r.tabs.length||this.handleTabClick(e,t)}};var q=class{constructor(e,t,r=!1){this.element=e.element,this.metadata=e.metadata,this.persistence=t,this.initialCode=e.initialCode||"",this.currentCode=this.initialCode,this.debug=r}get id(){return this.metadata.id}get type(){return this.metadata.type}getCode(){return this.currentCode}setCode(e){this.currentCode=e,this.metadata.editable&&this.persistence.saveCode(this.id,e)}reset(){this.setCode(this.initialCode),this.debug&&console.log(`[Block:${this.id}] Reset to initial code`)}loadPersistedCode(){if(!this.metadata.editable)return this.initialCode;let e=this.persistence.loadCode(this.id);return e?(this.debug&&console.log(`[Block:${this.id}] Loaded persisted code`),e):this.initialCode}createBlockWrapper(){let e=document.createElement("div");return e.className=`livemdtools-block livemdtools-block-${this.type}`,e.dataset.blockId=this.id,e.dataset.blockType=this.type,e}log(...e){this.debug&&console.log(`[Block:${this.id}]`,...e)}error(...e){console.error(`[Block:${this.id}]`,...e)}};var ie=class extends q{constructor(t,r,n=!1){super(t,r,n);this.codeElement=null}initialize(){this.log("Initializing server block"),this.codeElement=this.element.querySelector("code")||this.element,this.element.classList.add("livemdtools-server-block"),this.metadata.readonly&&this.element.classList.add("readonly"),this.element.dataset.blockId=this.id,this.element.dataset.language=this.metadata.language,this.render(),this.log("Server block initialized")}destroy(){this.log("Destroying server block")}handleMessage(t,r,n,i){this.log("Received message:",t,r),console.warn(`[ServerBlock:${this.id}] Received unexpected message:`,t)}render(){this.codeElement&&this.log("Rendered server block")}};var or=vt(ft());var se=class extends q{constructor(t,r,n=!1){super(t,r,n);this.client=null;this.containerElement=null;this.sendMessage=null;this.pendingForm=null;this.pendingAction=null;this._handleClick=t=>this.handleClick(t);this._handleSubmit=t=>this.handleSubmit(t);this._handleChange=t=>this.handleChange(t);this.execToolbar=null;this.outputPanel=null;this.outputExpanded=!1}initialize(){this.log("Initializing interactive block");let t=this.element.querySelector("[data-interactive-content]");if(t)this.containerElement=t;else{let r=document.createElement("div");for(r.className="exec-content-wrapper",r.dataset.interactiveContent="true";this.element.firstChild;)r.appendChild(this.element.firstChild);this.element.appendChild(r),this.containerElement=r}this.element.classList.add("livemdtools-interactive-block"),this.element.dataset.blockId=this.id,this.metadata.stateRef&&(this.element.dataset.stateRef=this.metadata.stateRef),this.client=new or.LiveTemplateClient,this.attachEventHandlers(),this.element.dataset.execSource==="true"&&this.injectExecToolbar(),this.log("Interactive block initialized")}destroy(){this.log("Destroying interactive block"),this.element.removeEventListener("click",this._handleClick,!0),this.element.removeEventListener("submit",this._handleSubmit,!0),this.element.removeEventListener("change",this._handleChange,!0),this.client=null,this.pendingForm=null,this.pendingAction=null}handleMessage(t,r,n,i){switch(this.log("Received message:",t,r),t){case"tree":if(r&&this.containerElement&&this.client&&(this.client.updateDOM(this.containerElement,r),this.log("DOM updated with tree"),i&&this.updateCacheAttributes(i),this.execToolbar&&n&&this.updateExecToolbar(n),this.pendingForm)){let a={success:!0,errors:{},action:this.pendingAction};this.pendingForm.dispatchEvent(new CustomEvent("lvt:success",{bubbles:!0,detail:a})),this.log("Dispatched lvt:success event"),this.pendingForm=null,this.pendingAction=null}break;case"error":if(this.error("Server error:",r.message),this.pendingForm){let a={success:!1,errors:r.errors||{},action:this.pendingAction};this.pendingForm.dispatchEvent(new CustomEvent("lvt:error",{bubbles:!0,detail:a})),this.pendingForm=null,this.pendingAction=null}break;default:this.log("Unknown action:",t)}}setMessageSender(t){this.sendMessage=t}attachEventHandlers(){this.element&&(this.element.addEventListener("click",this._handleClick,!0),this.element.addEventListener("submit",this._handleSubmit,!0),this.element.addEventListener("change",this._handleChange,!0))}handleClick(t){let r=t.target,n=r.closest("button[name]");if(n&&n.form===null&&this.element.contains(n)){if(t.preventDefault(),!this.checkConfirm(n)){this.log("Click action cancelled by user:",n.name);return}let a=this.extractData(n);this.sendAction(n.name,a),this.log("Click action (button name):",n.name,a);return}let i=r.closest("[lvt-on\\:click]");if(i&&this.element.contains(i)){let a=i.getAttribute("lvt-on:click");if(a){if(t.preventDefault(),!this.checkConfirm(i)){this.log("Click action cancelled by user:",a);return}let o=this.extractData(i);this.sendAction(a,o),this.log("Click action (lvt-on:click):",a,o)}}}handleSubmit(t){let r=t.target,n=t.submitter,i="";if(n instanceof HTMLButtonElement&&n.name)i=n.name;else if(r.getAttribute("name"))i=r.getAttribute("name");else return;t.preventDefault();let a=new FormData(r),o={};a.forEach((l,c)=>{n instanceof HTMLButtonElement&&c===n.name||(o[c]=l)}),this.pendingForm=r,this.pendingAction=i,r.dispatchEvent(new CustomEvent("lvt:pending",{bubbles:!0,detail:{action:i}})),this.sendAction(i,o),this.log("Submit action:",i,o)}handleChange(t){let r=t.target,n=r.getAttribute("lvt-on:change");if(n){let i={value:r.value};this.sendAction(n,i),this.log("Change action:",n,i)}}checkConfirm(t){let r=t.dataset.confirm;return!(r&&!window.confirm(r))}extractData(t){let r={};for(let n of Object.keys(t.dataset)){if(n==="confirm")continue;let i=t.dataset[n];i!==void 0&&(r[n]=i)}return r}sendAction(t,r={}){if(!this.sendMessage){this.error("Cannot send action - no message sender configured");return}this.sendMessage(this.id,t,r)}injectExecToolbar(){let t=this.element.dataset.execCommand||"...";this.execToolbar=document.createElement("div"),this.execToolbar.className="exec-toolbar",this.execToolbar.innerHTML=`
+    `,document.head.appendChild(n),document.body.appendChild(r)}createEnvelope(e,t,r={}){return{blockID:e,action:t,data:r}}getRegisteredBlocks(){return Array.from(this.handlers.keys())}clear(){this.handlers.clear(),this.debug&&console.log("[MessageRouter] Cleared all handlers")}};var re=class{constructor(e="livemdtools:persistence",t=!0,r=!1){this.storageKey=e,this.enabled=t&&this.isLocalStorageAvailable(),this.debug=r,!this.enabled&&t&&console.warn("[PersistenceManager] localStorage not available, persistence disabled")}isLocalStorageAvailable(){try{let e="__localStorage_test__";return localStorage.setItem(e,e),localStorage.removeItem(e),!0}catch{return!1}}saveCode(e,t){if(this.enabled)try{let r=this.loadAll();r.code[e]=t,r.timestamp=Date.now(),localStorage.setItem(this.storageKey,JSON.stringify(r)),this.debug&&console.log(`[PersistenceManager] Saved code for block: ${e}`)}catch(r){console.error("[PersistenceManager] Error saving code:",r)}}loadCode(e){if(!this.enabled)return null;try{return this.loadAll().code[e]||null}catch(t){return console.error("[PersistenceManager] Error loading code:",t),null}}loadAll(){if(!this.enabled)return{code:{},timestamp:Date.now()};try{let e=localStorage.getItem(this.storageKey);if(!e)return{code:{},timestamp:Date.now()};let t=JSON.parse(e);return!t.code||typeof t.code!="object"?(console.warn("[PersistenceManager] Invalid data structure, resetting"),{code:{},timestamp:Date.now()}):t}catch(e){return console.error("[PersistenceManager] Error loading data:",e),{code:{},timestamp:Date.now()}}}clearCode(e){if(this.enabled)try{let t=this.loadAll();delete t.code[e],t.timestamp=Date.now(),localStorage.setItem(this.storageKey,JSON.stringify(t)),this.debug&&console.log(`[PersistenceManager] Cleared code for block: ${e}`)}catch(t){console.error("[PersistenceManager] Error clearing code:",t)}}clearAll(){if(this.enabled)try{localStorage.removeItem(this.storageKey),this.debug&&console.log("[PersistenceManager] Cleared all persisted data")}catch(e){console.error("[PersistenceManager] Error clearing all data:",e)}}getPersistedBlocks(){if(!this.enabled)return[];let e=this.loadAll();return Object.keys(e.code)}hasPersistedCode(e){if(!this.enabled)return!1;let t=this.loadAll();return e in t.code}getLastUpdate(){return this.enabled?this.loadAll().timestamp:null}};var ne=class{constructor(e=!1){this.tabGroups=new Map;this.sendMessage=null;this.debug=e,this.init()}log(...e){this.debug&&console.log("[TabsController]",...e)}setMessageSender(e){this.sendMessage=e}init(){document.querySelectorAll(".tinkerdown-tabs").forEach(t=>{let r=t.dataset.tabsId;if(!r)return;let n=Array.from(t.querySelectorAll(".tinkerdown-tab")),i=t.querySelector("[data-tabs-content]");if(n.length===0)return;let a={id:r,container:t,tabs:n,activeIndex:0,contentPanel:i};this.tabGroups.set(r,a),n.forEach((o,l)=>{o.addEventListener("click",()=>this.handleTabClick(r,l)),o.addEventListener("keydown",c=>this.handleTabKeydown(c,r,l))}),this.wrapContent(t,i),this.log("Registered tab group:",r,"with",n.length,"tabs")}),this.log("Initialized with",this.tabGroups.size,"tab groups")}wrapContent(e,t){if(!t)return;let r=[],n=e.nextSibling;for(;n&&!(n instanceof HTMLElement&&n.classList.contains("tinkerdown-tabs")||n instanceof HTMLElement&&/^H[1-6]$/.test(n.tagName)&&!n.classList.contains("tinkerdown-tabs-heading"));)r.push(n),n=n.nextSibling;r.forEach(i=>{t.appendChild(i)})}handleTabClick(e,t){let r=this.tabGroups.get(e);if(!r)return;this.setActiveTab(r,t);let i=r.tabs[t].dataset.filter||"";this.log("Tab clicked:",e,"index:",t,"filter:",i),this.applyFilter(r,i)}handleTabKeydown(e,t,r){let n=this.tabGroups.get(t);if(!n)return;let i;switch(e.key){case"ArrowLeft":i=r===0?n.tabs.length-1:r-1;break;case"ArrowRight":i=r===n.tabs.length-1?0:r+1;break;case"Home":i=0;break;case"End":i=n.tabs.length-1;break;default:return}e.preventDefault(),this.setActiveTab(n,i),n.tabs[i].focus();let a=n.tabs[i].dataset.filter||"";this.applyFilter(n,a)}setActiveTab(e,t){e.tabs.forEach((r,n)=>{let i=n===t;r.classList.toggle("active",i),r.setAttribute("aria-selected",i?"true":"false"),r.setAttribute("tabindex",i?"0":"-1")}),e.activeIndex=t,e.contentPanel&&e.contentPanel.setAttribute("aria-labelledby",e.tabs[t].id||"")}applyFilter(e,t){let r=e.contentPanel?.querySelector(".tinkerdown-interactive-block");if(!r){this.log("No interactive block found for filtering");return}let n=r.dataset.blockId;if(!n){this.log("Interactive block has no ID");return}this.sendMessage?(this.log("Sending Filter action to block:",n,"filter:",t),this.sendMessage(n,"Filter",{filter:t})):this.log("No message sender configured")}getActiveIndex(e){return this.tabGroups.get(e)?.activeIndex??0}setTab(e,t){let r=this.tabGroups.get(e);!r||t<0||t>=r.tabs.length||this.handleTabClick(e,t)}};var q=class{constructor(e,t,r=!1){this.element=e.element,this.metadata=e.metadata,this.persistence=t,this.initialCode=e.initialCode||"",this.currentCode=this.initialCode,this.debug=r}get id(){return this.metadata.id}get type(){return this.metadata.type}getCode(){return this.currentCode}setCode(e){this.currentCode=e,this.metadata.editable&&this.persistence.saveCode(this.id,e)}reset(){this.setCode(this.initialCode),this.debug&&console.log(`[Block:${this.id}] Reset to initial code`)}loadPersistedCode(){if(!this.metadata.editable)return this.initialCode;let e=this.persistence.loadCode(this.id);return e?(this.debug&&console.log(`[Block:${this.id}] Loaded persisted code`),e):this.initialCode}createBlockWrapper(){let e=document.createElement("div");return e.className=`livemdtools-block livemdtools-block-${this.type}`,e.dataset.blockId=this.id,e.dataset.blockType=this.type,e}log(...e){this.debug&&console.log(`[Block:${this.id}]`,...e)}error(...e){console.error(`[Block:${this.id}]`,...e)}};var ie=class extends q{constructor(t,r,n=!1){super(t,r,n);this.codeElement=null}initialize(){this.log("Initializing server block"),this.codeElement=this.element.querySelector("code")||this.element,this.element.classList.add("livemdtools-server-block"),this.metadata.readonly&&this.element.classList.add("readonly"),this.element.dataset.blockId=this.id,this.element.dataset.language=this.metadata.language,this.render(),this.log("Server block initialized")}destroy(){this.log("Destroying server block")}handleMessage(t,r,n,i){this.log("Received message:",t,r),console.warn(`[ServerBlock:${this.id}] Received unexpected message:`,t)}render(){this.codeElement&&this.log("Rendered server block")}};var or=vt(ft());var se=class extends q{constructor(t,r,n=!1){super(t,r,n);this.client=null;this.containerElement=null;this.sendMessage=null;this.pendingForm=null;this.pendingAction=null;this._handleClick=t=>this.handleClick(t);this._handleSubmit=t=>this.handleSubmit(t);this._handleChange=t=>this.handleChange(t);this.optimistic=[];this.pollTimer=null;this.pollInterval=0;this.lastPoll=0;this._handleVisibilityChange=()=>this.handleVisibilityChange();this.execToolbar=null;this.outputPanel=null;this.outputExpanded=!1;this.execStatus="idle"}initialize(){this.log("Initializing interactive block");let t=this.element.querySelector("[data-interactive-content]");if(t)this.containerElement=t;else{let r=document.createElement("div");for(r.className="exec-content-wrapper",r.dataset.interactiveContent="true";this.element.firstChild;)r.appendChild(this.element.firstChild);this.element.appendChild(r),this.containerElement=r}this.element.classList.add("livemdtools-interactive-block"),this.element.dataset.blockId=this.id,this.metadata.stateRef&&(this.element.dataset.stateRef=this.metadata.stateRef),this.client=new or.LiveTemplateClient,this.attachEventHandlers(),this.element.dataset.execSource==="true"&&this.injectExecToolbar();let n=Number(this.element.dataset.poll);n>0&&this.startPolling(n),this.log("Interactive block initialized")}destroy(){this.log("Destroying interactive block"),this.element.removeEventListener("click",this._handleClick,!0),this.element.removeEventListener("submit",this._handleSubmit,!0),this.element.removeEventListener("change",this._handleChange,!0),this.stopPolling(),this.client=null,this.pendingForm=null,this.pendingAction=null}handleMessage(t,r,n,i){switch(this.log("Received message:",t,r),t){case"tree":if(r&&this.containerElement&&this.client&&(this.settleOptimistic(!1),this.client.updateDOM(this.containerElement,r),this.log("DOM updated with tree"),i&&this.updateCacheAttributes(i),this.execToolbar&&n&&this.updateExecToolbar(n),this.pendingForm)){let a={success:!0,errors:{},action:this.pendingAction};this.pendingForm.dispatchEvent(new CustomEvent("lvt:success",{bubbles:!0,detail:a})),this.log("Dispatched lvt:success event"),this.pendingForm=null,this.pendingAction=null}break;case"error":if(this.error("Server error:",r.message),this.settleOptimistic(!0),this.pendingForm){let a={success:!1,errors:r.errors||{},action:this.pendingAction};this.pendingForm.dispatchEvent(new CustomEvent("lvt:error",{bubbles:!0,detail:a})),this.pendingForm=null,this.pendingAction=null}break;default:this.log("Unknown action:",t)}}setMessageSender(t){this.sendMessage=t}attachEventHandlers(){this.element&&(this.element.addEventListener("click",this._handleClick,!0),this.element.addEventListener("submit",this._handleSubmit,!0),this.element.addEventListener("change",this._handleChange,!0))}handleClick(t){let r=t.target,n=r.closest("button[name]");if(n&&n.form===null&&this.element.contains(n)){if(t.preventDefault(),!this.checkConfirm(n)){this.log("Click action cancelled by user:",n.name);return}let a=this.extractData(n);this.applyOptimistic(n),this.sendAction(n.name,a),this.log("Click action (button name):",n.name,a);return}let i=r.closest("[lvt-on\\:click]");if(i&&this.element.contains(i)){let a=i.getAttribute("lvt-on:click");if(a){if(this.isOptimisticCheckbox(i)||t.preventDefault(),!this.checkConfirm(i)){t.preventDefault(),this.log("Click action cancelled by user:",a);return}let o=this.extractData(i);this.applyOptimistic(i),this.sendAction(a,o),this.log("Click action (lvt-on:click):",a,o)}}}handleSubmit(t){let r=t.target,n=t.submitter,i="";if(n instanceof HTMLButtonElement&&n.name)i=n.name;else if(r.getAttribute("name"))i=r.getAttribute("name");else return;if(t.preventDefault(),r.querySelector('input[type="file"]')){this.uploadForm(r,i);return}let a=new FormData(r),o={};a.forEach((l,c)=>{n instanceof HTMLButtonElement&&c===n.name||(o[c]=c in o?`${o[c]},${l}`:l)}),this.pendingForm=r,this.pendingAction=i,r.dispatchEvent(new CustomEvent("lvt:pending",{bubbles:!0,detail:{action:i}})),this.sendAction(i,o),this.log("Submit action:",i,o)}async uploadForm(t,r){t.dispatchEvent(new CustomEvent("lvt:pending",{bubbles:!0,detail:{action:r}}));let n=new FormData;n.append("page",this.pagePath()),n.append("block",this.id),n.append("action",r);let i=null;new FormData(t).forEach((o,l)=>{o instanceof File?i||(i=o):n.append(l,o)}),i&&n.append("file",i);let a;try{let o=await fetch("/action",{method:"POST",body:n}),l=await o.json().catch(()=>({}));if(!o.ok)throw new Error(l.error||`Upload failed (${o.status})`);a={success:!0,errors:{},action:r},this.log("Uploaded",l.data)}catch(o){let l=o instanceof Error?o.message:String(o);this.error("Upload failed:",l),a={success:!1,errors:{file:l},action:r}}t.dispatchEvent(new CustomEvent(a.success?"lvt:success":"lvt:error",{bubbles:!0,detail:a}))}pagePath(){let t=document.querySelector('meta[name="tinkerdown-ws-url"]');return(t?new URL(t.content,window.location.href).searchParams.get("page"):null)??window.location.pathname}handleChange(t){let r=t.target,s=r.getAttribute("lvt-select-all");if(s!==null&&r.type==="checkbox"){(r.form??this.element).querySelectorAll(`input[type="checkbox"][name="${CSS.escape(s)}"]`).forEach(l=>{l.checked=r.checked});return}let n=r.getAttribute("lvt-on:change");if(n){let i={value:r.value};this.sendAction(n,i),this.log("Change action:",n,i)}}checkConfirm(t){let r=t.dataset.confirm;return!(r&&!window.confirm(r))}extractData(t){let r={};for(let n of Object.keys(t.dataset)){if(n==="confirm")continue;let i=t.dataset[n];i!==void 0&&(r[n]=i)}return r}sendAction(t,r={}){if(!this.sendMessage){this.error("Cannot send action - no message sender configured");return}this.sendMessage(this.id,t,r)}isOptimisticCheckbox(t){return t.getAttribute("lvt-optimistic")==="toggle"&&t instanceof HTMLInputElement&&t.type==="checkbox"}applyOptimistic(t){let r=t.getAttribute("lvt-optimistic"),n=t.closest("tr, li, label")??t;if(r==="toggle"){let i;if(this.isOptimisticCheckbox(t)){let a=t,o=!a.checked;i=()=>{a.checked=o}}n.classList.add("lvt-optimistic-pending"),this.optimistic.push({element:n,className:"lvt-optimistic-pending",rollback:i})}else r==="delete"&&(n.classList.add("lvt-optimistic-deleted"),this.optimistic.push({element:n,className:"lvt-optimistic-deleted"}))}settleOptimistic(t){for(let r of this.optimistic.reverse())r.element.classList.remove(r.className),t&&r.rollback?.();t&&this.optimistic.length>0&&this.log("Rolled back",this.optimistic.length,"optimistic update(s)"),this.optimistic=[]}startPolling(t){this.pollInterval=t,this.lastPoll=Date.now(),this.pollTimer=setInterval(()=>this.poll(),t),document.addEventListener("visibilitychange",this._handleVisibilityChange),this.log("Polling every",t,"ms")}stopPolling(){this.pollTimer!==null&&(clearInterval(this.pollTimer),this.pollTimer=null),document.removeEventListener("visibilitychange",this._handleVisibilityChange)}poll(){document.hidden||(this.lastPoll=Date.now(),this.sendAction("Refresh",{}))}handleVisibilityChange(){!document.hidden&&Date.now()-this.lastPoll>=this.pollInterval&&this.poll()}injectExecToolbar(){let t=this.element.dataset.execCommand||"...";this.execToolbar=document.createElement("div"),this.execToolbar.className="exec-toolbar",this.execToolbar.innerHTML=`
       <div class="exec-toolbar-command"><code>${this.escapeHtml(t)}</code></div>
       <div class="exec-toolbar-status idle"><span>Ready</span></div>
       <span class="exec-toolbar-duration"></span>
@@ -72,6 +72,7 @@
         </svg>
         Run
       </button>
+      <button class="exec-toolbar-cancel-btn" type="button" hidden>Cancel</button>
     `,this.outputPanel=document.createElement("div"),this.outputPanel.className="exec-output-panel",this.outputPanel.innerHTML=`
       <button class="exec-output-toggle" type="button">
         <svg class="exec-output-toggle-icon" width="12" height="12" viewBox="0 0 24 24" fill="currentColor">
@@ -83,7 +84,7 @@
         <pre class="exec-output-stdout"></pre>
         <pre class="exec-output-stderr"></pre>
       </div>
-    `,this.containerElement&&this.containerElement.parentNode===this.element?(this.element.insertBefore(this.execToolbar,this.containerElement),this.execToolbar.after(this.outputPanel)):(this.element.insertBefore(this.execToolbar,this.element.firstChild),this.execToolbar.after(this.outputPanel)),this.execToolbar.querySelector(".exec-toolbar-run-btn")?.addEventListener("click",()=>this.handleExecRun()),this.outputPanel.querySelector(".exec-output-toggle")?.addEventListener("click",()=>this.toggleOutput()),this.log("Exec toolbar injected")}handleExecRun(){this.log("Run button clicked"),this.sendAction("Run",{})}toggleOutput(){this.outputExpanded=!this.outputExpanded,this.outputPanel?.querySelector(".exec-output-toggle")?.classList.toggle("expanded",this.outputExpanded),this.outputPanel?.querySelector(".exec-output-content")?.classList.toggle("expanded",this.outputExpanded)}updateExecToolbar(t){let r=t.status||"idle",n=this.execToolbar?.querySelector(".exec-toolbar-status"),i=this.execToolbar?.querySelector(".exec-toolbar-duration"),a=this.execToolbar?.querySelector(".exec-toolbar-run-btn");n&&(n.className=`exec-toolbar-status ${r}`,r==="running"?n.innerHTML='<span class="exec-spinner"></span>':r==="success"?n.innerHTML="<span>\u2713 Success</span>":r==="error"?n.innerHTML="<span>\u2717 Error</span>":n.innerHTML="<span>Ready</span>"),i&&t.duration&&(i.textContent=`${t.duration}ms`),a&&(a.disabled=r==="running",r==="running"?(a.classList.add("running"),a.innerHTML=`
+    `,this.containerElement&&this.containerElement.parentNode===this.element?(this.element.insertBefore(this.execToolbar,this.containerElement),this.execToolbar.after(this.outputPanel)):(this.element.insertBefore(this.execToolbar,this.element.firstChild),this.execToolbar.after(this.outputPanel)),this.execToolbar.querySelector(".exec-toolbar-run-btn")?.addEventListener("click",()=>this.handleExecRun()),this.execToolbar.querySelector(".exec-toolbar-cancel-btn")?.addEventListener("click",()=>this.sendAction("CancelRun",{})),this.outputPanel.querySelector(".exec-output-toggle")?.addEventListener("click",()=>this.toggleOutput()),this.log("Exec toolbar injected")}handleExecRun(){this.log("Run button clicked"),this.sendAction(this.execStatus==="confirm"?"Confirm":"Run",{})}toggleOutput(){this.outputExpanded=!this.outputExpanded,this.outputPanel?.querySelector(".exec-output-toggle")?.classList.toggle("expanded",this.outputExpanded),this.outputPanel?.querySelector(".exec-output-content")?.classList.toggle("expanded",this.outputExpanded)}updateExecToolbar(t){let r=t.status||"idle",n=this.execToolbar?.querySelector(".exec-toolbar-status"),i=this.execToolbar?.querySelector(".exec-toolbar-duration"),a=this.execToolbar?.querySelector(".exec-toolbar-run-btn"),c=this.execToolbar?.querySelector(".exec-toolbar-cancel-btn");this.execStatus=r,n&&(n.className=`exec-toolbar-status ${r}`,r==="running"?n.innerHTML='<span class="exec-spinner"></span>':r==="success"?n.innerHTML="<span>\u2713 Success</span>":r==="error"?n.innerHTML="<span>\u2717 Error</span>":r==="confirm"?n.innerHTML=`<span>${this.escapeHtml(t.confirm_message||"Run this command?")}</span>`:n.innerHTML="<span>Ready</span>"),i&&t.duration&&(i.textContent=`${t.duration}ms`),a&&(a.disabled=r==="running",r==="running"?(a.classList.add("running"),a.innerHTML=`
           <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor">
             <path d="M8 5v14l11-7z"/>
           </svg>
@@ -92,8 +93,8 @@
           <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor">
             <path d="M8 5v14l11-7z"/>
           </svg>
-          Run
-        `));let o=this.outputPanel?.querySelector(".exec-output-stdout"),l=this.outputPanel?.querySelector(".exec-output-stderr");o&&(o.textContent=t.output||""),l&&(l.textContent=t.stderr||"",l.style.display=t.stderr?"block":"none"),this.log("Exec toolbar updated:",r,t.duration)}updateCacheAttributes(t){this.element&&(t.stale?this.element.dataset.cacheStale="true":delete this.element.dataset.cacheStale,t.refreshing?this.element.dataset.cacheRefreshing="true":delete this.element.dataset.cacheRefreshing,t.cached?(this.element.dataset.cacheCached="true",t.age&&(this.element.dataset.cacheAge=t.age),t.expires_in&&(this.element.dataset.cacheExpiresIn=t.expires_in)):(delete this.element.dataset.cacheCached,delete this.element.dataset.cacheAge,delete this.element.dataset.cacheExpiresIn),this.log("Cache attributes updated:",t))}escapeHtml(t){let r=document.createElement("div");return r.textContent=t,r.innerHTML}};var Cn="0.45.0",Y=`https://cdn.jsdelivr.net/npm/monaco-editor@${Cn}/min`,be=null,oe=null;function Tn(){return new Promise((s,e)=>{if(typeof window.require=="function"){s();return}let t=document.createElement("script");t.src=`${Y}/vs/loader.js`,t.async=!0,t.onload=()=>s(),t.onerror=()=>e(new Error("Failed to load Monaco loader from CDN")),document.head.appendChild(t)})}function Mn(){window.MonacoEnvironment={getWorkerUrl:function(s,e){let t=`${Y}/vs/base/worker/workerMain.js`;return e==="json"?`${Y}/vs/language/json/json.worker.js`:e==="css"||e==="scss"||e==="less"?`${Y}/vs/language/css/css.worker.js`:e==="html"||e==="handlebars"||e==="razor"?`${Y}/vs/language/html/html.worker.js`:e==="typescript"||e==="javascript"?`${Y}/vs/language/typescript/ts.worker.js`:t}}}async function ve(){if(be)return be;if(oe)return oe;console.log("[MonacoLoader] Loading Monaco Editor from CDN...");let s=performance.now();return oe=(async()=>{try{await Tn();let e=window.require;if(!e)throw new Error("AMD loader not available after loading");return e.config({paths:{vs:`${Y}/vs`}}),Mn(),await new Promise((t,r)=>{e(["vs/editor/editor.main"],n=>{if(!n){r(new Error("Monaco module loaded but is undefined"));return}be=n;let i=Math.round(performance.now()-s);console.log(`[MonacoLoader] Monaco Editor loaded from CDN in ${i}ms`),t(n)})})}catch(e){throw oe=null,e}})(),oe}function ar(){return be!==null}function qe(){!be&&!oe&&ve().catch(s=>{console.error("[MonacoLoader] Failed to preload Monaco from CDN:",s)})}function We(){return document.querySelectorAll('[data-block-type="wasm"]').length>0}var ae=class{constructor(e,t,r){this.editor=null;this.monaco=null;this.onChangeCallback=null;this.editorDiv=null;this.initPromise=null;this.container=e,this.initialCode=t,this.options=r,this.initPromise=this.initialize()}async initialize(){let e=document.createElement("div");e.className="livemdtools-monaco-loading",e.style.padding="2rem",e.style.textAlign="center",e.style.color="#999",e.textContent="Loading editor...",this.container.appendChild(e);try{this.monaco=await ve(),e.remove(),this.editorDiv=document.createElement("div"),this.editorDiv.className="livemdtools-monaco-editor",this.editorDiv.style.height="300px",this.editorDiv.style.width="100%",this.container.appendChild(this.editorDiv),this.editor=this.monaco.editor.create(this.editorDiv,{value:this.initialCode,language:this.options.language,theme:this.options.theme||"vs-dark",readOnly:this.options.readonly,minimap:{enabled:this.options.minimap??!0},lineNumbers:this.options.lineNumbers!==!1?"on":"off",scrollBeyondLastLine:!1,automaticLayout:!0,fontSize:14,tabSize:4,insertSpaces:!1}),this.editor.onDidChangeModelContent(()=>{this.onChangeCallback&&this.editor&&this.onChangeCallback(this.editor.getValue())})}catch(t){console.error("[MonacoEditor] Failed to load Monaco:",t),e.textContent="Failed to load editor",e.style.color="#f44"}}async ensureReady(){this.initPromise&&await this.initPromise}getValue(){return this.editor?.getValue()||""}async setValue(e){await this.ensureReady(),this.editor?.setValue(e)}onChange(e){this.onChangeCallback=e}async setReadOnly(e){await this.ensureReady(),this.editor?.updateOptions({readOnly:e})}async focus(){await this.ensureReady(),this.editor?.focus()}async layout(){await this.ensureReady(),this.editor?.layout()}destroy(){this.editor?.dispose(),this.editor=null}async getEditor(){return await this.ensureReady(),this.editor}};var le=class{constructor(e,t=1e3,r=!0){this.lines=[];this.maxLines=t,this.autoScroll=r,this.element=this.createPanel(),e.appendChild(this.element)}createPanel(){let e=document.createElement("div");e.className="livemdtools-output-panel",e.innerHTML=`
+          ${r==="confirm"?"Confirm":"Run"}
+        `)),c&&(c.hidden=r!=="confirm"&&r!=="running");let o=this.outputPanel?.querySelector(".exec-output-stdout"),l=this.outputPanel?.querySelector(".exec-output-stderr");o&&(o.textContent=t.output||""),l&&(l.textContent=t.stderr||"",l.style.display=t.stderr?"block":"none");if(r==="running"&&t.output){this.outputExpanded||this.toggleOutput();let u=this.outputPanel?.querySelector(".exec-output-content");u&&(u.scrollTop=u.scrollHeight)}this.log("Exec toolbar updated:",r,t.duration)}updateCacheAttributes(t){this.element&&(t.stale?this.element.dataset.cacheStale="true":delete this.element.dataset.cacheStale,t.refreshing?this.element.dataset.cacheRefreshing="true":delete this.element.dataset.cacheRefreshing,t.cached?(this.element.dataset.cacheCached="true",t.age&&(this.element.dataset.cacheAge=t.age),t.expires_in&&(this.element.dataset.cacheExpiresIn=t.expires_in)):(delete this.element.dataset.cacheCached,delete this.element.dataset.cacheAge,delete this.element.dataset.cacheExpiresIn),this.log("Cache attributes updated:",t))}escapeHtml(t){let r=document.createElement("div");return r.textContent=t,r.innerHTML}};var Cn="0.45.0",Y=`https://cdn.jsdelivr.net/npm/monaco-editor@${Cn}/min`,be=null,oe=null;function Tn(){return new Promise((s,e)=>{if(typeof window.require=="function"){s();return}let t=document.createElement("script");t.src=`${Y}/vs/loader.js`,t.async=!0,t.onload=()=>s(),t.onerror=()=>e(new Error("Failed to load Monaco loader from CDN")),document.head.appendChild(t)})}function Mn(){window.MonacoEnvironment={getWorkerUrl:function(s,e){let t=`${Y}/vs/base/worker/workerMain.js`;return e==="json"?`${Y}/vs/language/json/json.worker.js`:e==="css"||e==="scss"||e==="less"?`${Y}/vs/language/css/css.worker.js`:e==="html"||e==="handlebars"||e==="razor"?`${Y}/vs/language/html/html.worker.js`:e==="typescript"||e==="javascript"?`${Y}/vs/language/typescript/ts.worker.js`:t}}}async function ve(){if(be)return be;if(oe)return oe;console.log("[MonacoLoader] Loading Monaco Editor from CDN...");let s=performance.now();return oe=(async()=>{try{await Tn();let e=window.require;if(!e)throw new Error("AMD loader not available after loading");return e.config({paths:{vs:`${Y}/vs`}}),Mn(),await new Promise((t,r)=>{e(["vs/editor/editor.main"],n=>{if(!n){r(new Error("Monaco module loaded but is undefined"));return}be=n;let i=Math.round(performance.now()-s);console.log(`[MonacoLoader] Monaco Editor loaded from CDN in ${i}ms`),t(n)})})}catch(e){throw oe=null,e}})(),oe}function ar(){return be!==null}function qe(){!be&&!oe&&ve().catch(s=>{console.error("[MonacoLoader] Failed to preload Monaco from CDN:",s)})}function We(){return document.querySelectorAll('[data-block-type="wasm"]').length>0}var ae=class{constructor(e,t,r){this.editor=null;this.monaco=null;this.onChangeCallback=null;this.editorDiv=null;this.initPromise=null;this.container=e,this.initialCode=t,this.options=r,this.initPromise=this.initialize()}async initialize(){let e=document.createElement("div");e.className="livemdtools-monaco-loading",e.style.padding="2rem",e.style.textAlign="center",e.style.color="#999",e.textContent="Loading editor...",this.container.appendChild(e);try{this.monaco=await ve(),e.remove(),this.editorDiv=document.createElement("div"),this.editorDiv.className="livemdtools-monaco-editor",this.editorDiv.style.height="300px",this.editorDiv.style.width="100%",this.container.appendChild(this.editorDiv),this.editor=this.monaco.editor.create(this.editorDiv,{value:this.initialCode,language:this.options.language,theme:this.options.theme||"vs-dark",readOnly:this.options.readonly,minimap:{enabled:this.options.minimap??!0},lineNumbers:this.options.lineNumbers!==!1?"on":"off",scrollBeyondLastLine:!1,automaticLayout:!0,fontSize:14,tabSize:4,insertSpaces:!1}),this.editor.onDidChangeModelContent(()=>{this.onChangeCallback&&this.editor&&this.onChangeCallback(this.editor.getValue())})}catch(t){console.error("[MonacoEditor] Failed to load Monaco:",t),e.textContent="Failed to load editor",e.style.color="#f44"}}async ensureReady(){this.initPromise&&await this.initPromise}getValue(){return this.editor?.getValue()||""}async setValue(e){await this.ensureReady(),this.editor?.setValue(e)}onChange(e){this.onChangeCallback=e}async setReadOnly(e){await this.ensureReady(),this.editor?.updateOptions({readOnly:e})}async focus(){await this.ensureReady(),this.editor?.focus()}async layout(){await this.ensureReady(),this.editor?.layout()}destroy(){this.editor?.dispose(),this.editor=null}async getEditor(){return await this.ensureReady(),this.editor}};var le=class{constructor(e,t=1e3,r=!0){this.lines=[];this.maxLines=t,this.autoScroll=r,this.element=this.createPanel(),e.appendChild(this.element)}createPanel(){let e=document.createElement("div");e.className="livemdtools-output-panel",e.innerHTML=`
       <div class="output-header">
         <span class="output-title">Output</span>
         <button class="output-clear" title="Clear output">Clear</button>
@@ -101,7 +102,20 @@
       <div class="output-content"></div>
     `;let t=e.querySelector(".output-clear");return t&&t.addEventListener("click",()=>this.clear()),e}append(e,t){let r={type:e,text:t,timestamp:Date.now()};this.lines.push(r),this.lines.length>this.maxLines&&(this.lines=this.lines.slice(-this.maxLines)),this.render()}stdout(e){this.append("stdout",e)}stderr(e){this.append("stderr",e)}error(e){this.append("error",e)}info(e){this.append("info",e)}clear(){this.lines=[],this.render()}show(){this.element.style.display="block"}hide(){this.element.style.display="none"}render(){let e=this.element.querySelector(".output-content");if(e){e.innerHTML="";for(let t of this.lines){let r=document.createElement("div");r.className=`output-line output-${t.type}`,r.textContent=t.text,e.appendChild(r)}this.autoScroll&&(e.scrollTop=e.scrollHeight)}}getElement(){return this.element}destroy(){this.element.remove()}};var ce=class{constructor(e,t="Run"){this.callback=null;this.isRunning=!1;this.element=this.createButton(t),e.appendChild(this.element)}createButton(e){let t=document.createElement("button");return t.className="livemdtools-run-button",t.textContent=e,t.addEventListener("click",()=>this.handleClick()),t}onClick(e){this.callback=e}async handleClick(){if(!(this.isRunning||!this.callback)){this.setRunning(!0);try{await this.callback()}catch(e){console.error("[RunButton] Error executing callback:",e)}finally{this.setRunning(!1)}}}setRunning(e){this.isRunning=e,this.element.disabled=e,e?(this.element.classList.add("running"),this.element.textContent="Running..."):(this.element.classList.remove("running"),this.element.textContent="Run")}enable(){this.element.disabled=!1}disable(){this.element.disabled=!0}getElement(){return this.element}destroy(){this.element.remove()}};var de=class{constructor(e="/api/compile",t=!1){this.serverUrl=e,this.debug=t}async execute(e){this.debug&&console.log("[TinyGoExecutor] Executing code:",e);try{let t=await fetch(this.serverUrl,{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify({code:e})});if(!t.ok){let n=await t.text();return{stdout:"",stderr:n,error:`Compilation failed: ${n}`,exitCode:1}}let r=await t.arrayBuffer();return await this.executeWasm(r)}catch(t){return{stdout:"",stderr:"",error:`Execution failed: ${t instanceof Error?t.message:String(t)}`,exitCode:1}}}async executeWasm(e){let t="",r="";try{let n=new window.Go,i=console.log,a=console.error;console.log=(...l)=>{t+=l.join(" ")+`
 `},console.error=(...l)=>{r+=l.join(" ")+`
-`};let o=await WebAssembly.instantiate(e,n.importObject);return await n.run(o.instance),console.log=i,console.error=a,{stdout:t,stderr:r,exitCode:0}}catch(n){let i=n instanceof Error?n.message:String(n);return{stdout:t,stderr:r,error:`WASM execution failed: ${i}`,exitCode:1}}}static isSupported(){return typeof WebAssembly<"u"&&typeof window.Go<"u"}};async function lr(){if(!window.Go)try{let s=document.createElement("script");s.src="/assets/wasm_exec.js",await new Promise((e,t)=>{s.onload=()=>e(),s.onerror=()=>t(new Error("Failed to load wasm_exec.js")),document.head.appendChild(s)}),console.log("[TinyGoExecutor] WASM environment initialized")}catch(s){throw console.error("[TinyGoExecutor] Failed to initialize WASM:",s),s}}var ue=class extends q{constructor(t,r,n=!1){super(t,r,n);this.editor=null;this.outputPanel=null;this.runButton=null;this.executor=null;this.containerElement=null;this.editorContainer=null;this.controlsContainer=null}initialize(){this.log("Initializing WASM block"),this.currentCode=this.loadPersistedCode(),this.createBlockStructure(),this.initializeEditor(),this.initializeControls(),this.initializeExecutor(),this.log("WASM block initialized")}destroy(){this.log("Destroying WASM block"),this.editor?.destroy(),this.outputPanel?.destroy(),this.runButton?.destroy(),this.editor=null,this.outputPanel=null,this.runButton=null,this.executor=null}handleMessage(t,r,n,i){this.log("Received message:",t,r)}createBlockStructure(){this.containerElement=document.createElement("div"),this.containerElement.className="livemdtools-wasm-block",this.containerElement.dataset.blockId=this.id,this.editorContainer=document.createElement("div"),this.editorContainer.className="wasm-editor-container",this.containerElement.appendChild(this.editorContainer),this.controlsContainer=document.createElement("div"),this.controlsContainer.className="wasm-controls",this.containerElement.appendChild(this.controlsContainer),this.element.parentNode&&this.element.parentNode.replaceChild(this.containerElement,this.element)}initializeEditor(){this.editorContainer&&(this.editor=new ae(this.editorContainer,this.currentCode,{language:this.metadata.language||"go",readonly:this.metadata.readonly||!1,theme:"vs-dark",minimap:!1,lineNumbers:!0}),this.editor.onChange(t=>{this.setCode(t)}),this.log("Editor initialized"))}initializeControls(){if(!this.controlsContainer)return;let t=document.createElement("div");t.className="wasm-buttons",this.runButton=new ce(t,"Run"),this.runButton.onClick(()=>this.executeCode());let r=document.createElement("button");r.className="livemdtools-reset-button",r.textContent="Reset",r.addEventListener("click",()=>this.resetCode()),t.appendChild(r),this.controlsContainer.appendChild(t),this.outputPanel=new le(this.controlsContainer),this.outputPanel.hide(),this.log("Controls initialized")}initializeExecutor(){this.executor=new de("/api/compile",this.debug),this.log("Executor initialized")}async executeCode(){if(!this.editor||!this.executor||!this.outputPanel){this.error("Cannot execute - components not initialized");return}let t=this.editor.getValue();this.log("Executing code"),this.outputPanel.clear(),this.outputPanel.show(),this.outputPanel.info("Compiling and running...");try{let r=await this.executor.execute(t);this.outputPanel.clear(),r.stdout&&this.outputPanel.stdout(r.stdout),r.stderr&&this.outputPanel.stderr(r.stderr),r.error&&this.outputPanel.error(r.error),r.exitCode===0&&!r.stdout&&!r.stderr&&this.outputPanel.info("Program completed successfully (no output)"),this.log("Execution completed with exit code:",r.exitCode)}catch(r){let n=r instanceof Error?r.message:String(r);this.outputPanel.error(`Execution error: ${n}`),this.error("Execution error:",r)}}resetCode(){this.editor&&(this.editor.setValue(this.initialCode),this.setCode(this.initialCode),this.outputPanel?.clear(),this.log("Code reset to initial state"))}};var he=class{constructor(e){this.tabs=null;this.blocks=new Map;this.ws=null;this.reconnectTimer=null;this.isConnected=!1;this.options={debug:!1,persistence:!0,cdnFallback:!1,...e},this.router=new te(this.options.debug),this.persistence=new re("tinkerdown:persistence",this.options.persistence,this.options.debug),this.options.debug&&console.log("[TinkerdownClient] Initialized with options:",this.options)}connect(){if(this.ws){console.warn("[TinkerdownClient] Already connected");return}try{this.ws=new WebSocket(this.options.wsUrl),this.ws.onopen=()=>{this.isConnected=!0,console.log("[TinkerdownClient] Connected to server"),this.options.onConnect?.()},this.ws.onclose=()=>{this.isConnected=!1,console.log("[TinkerdownClient] Disconnected from server"),this.options.onDisconnect?.(),this.scheduleReconnect()},this.ws.onerror=e=>{console.error("[TinkerdownClient] WebSocket error:",e),this.options.onError?.(new Error("WebSocket error"))},this.ws.onmessage=e=>{this.handleMessage(e.data)}}catch(e){console.error("[TinkerdownClient] Failed to connect:",e),this.options.onError?.(e)}}disconnect(){this.reconnectTimer&&(clearTimeout(this.reconnectTimer),this.reconnectTimer=null),this.ws&&(this.ws.close(),this.ws=null),this.isConnected=!1}handleMessage(e){this.options.debug&&console.log("[TinkerdownClient] Received message:",e),this.router.route(e)}send(e,t,r={}){if(!this.isConnected||!this.ws){console.warn("[TinkerdownClient] Cannot send - not connected");return}let i=JSON.stringify({blockID:e,action:t,data:r});this.options.debug&&console.log("[TinkerdownClient] Sending message:",i),this.ws.send(i)}scheduleReconnect(){this.reconnectTimer||(this.reconnectTimer=window.setTimeout(()=>{this.reconnectTimer=null,console.log("[TinkerdownClient] Attempting to reconnect..."),this.connect()},3e3))}discoverBlocks(){console.log("[TinkerdownClient] Discovering blocks..."),(0,cr.setupReactiveAttributeListeners)(),this.tabs=new ne(this.options.debug),this.tabs.setMessageSender((t,r,n)=>{this.send(t,r,n)});let e=document.querySelectorAll("[data-tinkerdown-block]");for(let t of Array.from(e))try{let r=this.extractMetadata(t),n=this.extractCode(t),i={element:t,metadata:r,initialCode:n};this.registerBlock(i)}catch(r){console.error("[TinkerdownClient] Error discovering block:",r)}console.log(`[TinkerdownClient] Discovered ${this.blocks.size} blocks`)}extractMetadata(e){let t=e.dataset.blockId||this.generateBlockId(),r=e.dataset.blockType||"server",n=e.dataset.language||"go",i=e.dataset.readonly==="true",a=e.dataset.editable==="true",o=e.dataset.stateRef;return{id:t,type:r,language:n,readonly:i,editable:a,stateRef:o}}extractCode(e){let t=e.querySelector("code");return t?t.textContent||"":e.textContent||""}generateBlockId(){return`block-${Date.now()}-${Math.random().toString(36).substr(2,9)}`}registerBlock(e){let{metadata:t}=e;if(this.blocks.has(t.id)){console.warn(`[TinkerdownClient] Block already registered: ${t.id}`);return}let r;switch(t.type){case"server":r=new ie(e,this.persistence,this.options.debug);break;case"interactive":case"lvt":r=new se(e,this.persistence,this.options.debug),r.setMessageSender((n,i,a)=>{this.send(n,i,a)});break;case"wasm":r=new ue(e,this.persistence,this.options.debug);break;default:console.warn(`[TinkerdownClient] Unknown block type: ${t.type}`);return}r.initialize(),this.router.register(t.id,(n,i,a,o)=>{r.handleMessage(n,i,a,o)}),this.blocks.set(t.id,r),this.options.debug&&console.log(`[TinkerdownClient] Registered block: ${t.id} (${t.type})`)}unregisterBlock(e){let t=this.blocks.get(e);t&&(t.destroy(),this.router.unregister(e),this.blocks.delete(e),this.options.debug&&console.log(`[TinkerdownClient] Unregistered block: ${e}`))}getBlock(e){return this.blocks.get(e)}getBlockIds(){return Array.from(this.blocks.keys())}destroy(){console.log("[TinkerdownClient] Destroying client");for(let e of this.blocks.values())e.destroy();this.blocks.clear(),this.router.clear(),this.disconnect()}};var je=class{constructor(){this.steps=[];this.currentStepIndex=0;this.sidebar=null;this.bottomNav=null;this.init()}init(){if(document.querySelector(".tinkerdown-nav-sidebar"))return;let e=document.querySelector('meta[name="tinkerdown-sidebar"]');e&&e.getAttribute("content")==="false"||(this.parseSteps(),this.steps.length!==0&&(this.createSidebar(),this.createBottomNav(),this.setupKeyboardShortcuts(),this.setupHashNavigation(),this.setupScrollTracking(),this.initializeFromHash()))}parseSteps(){document.querySelectorAll("h2").forEach((t,r)=>{let n=t.id||this.generateId(t.textContent||"");t.id||(t.id=n),this.steps.push({id:n,title:t.textContent||"",element:t,index:r})})}generateId(e){return e.toLowerCase().replace(/[^\w\s-]/g,"").replace(/\s+/g,"-").replace(/-+/g,"-").trim()}createSidebar(){this.sidebar=document.createElement("nav"),this.sidebar.className="tinkerdown-nav-sidebar",this.sidebar.innerHTML=`
+`};let o=await WebAssembly.instantiate(e,n.importObject);return await n.run(o.instance),console.log=i,console.error=a,{stdout:t,stderr:r,exitCode:0}}catch(n){let i=n instanceof Error?n.message:String(n);return{stdout:t,stderr:r,error:`WASM execution failed: ${i}`,exitCode:1}}}static isSupported(){return typeof WebAssembly<"u"&&typeof window.Go<"u"}};async function lr(){if(!window.Go)try{let s=document.createElement("script");s.src="/assets/wasm_exec.js",await new Promise((e,t)=>{s.onload=()=>e(),s.onerror=()=>t(new Error("Failed to load wasm_exec.js")),document.head.appendChild(s)}),console.log("[TinyGoExecutor] WASM environment initialized")}catch(s){throw console.error("[TinyGoExecutor] Failed to initialize WASM:",s),s}}var ue=class extends q{constructor(t,r,n=!1){super(t,r,n);this.editor=null;this.outputPanel=null;this.runButton=null;this.executor=null;this.containerElement=null;this.editorContainer=null;this.controlsContainer=null}initialize(){this.log("Initializing WASM block"),this.currentCode=this.loadPersistedCode(),this.createBlockStructure(),this.initializeEditor(),this.initializeControls(),this.initializeExecutor(),this.log("WASM block initialized")}destroy(){this.log("Destroying WASM block"),this.editor?.destroy(),this.outputPanel?.destroy(),this.runButton?.destroy(),this.editor=null,this.outputPanel=null,this.runButton=null,this.executor=null}handleMessage(t,r,n,i){this.log("Received message:",t,r)}createBlockStructure(){this.containerElement=document.createElement("div"),this.containerElement.className="livemdtools-wasm-block",this.containerElement.dataset.blockId=this.id,this.editorContainer=document.createElement("div"),this.editorContainer.className="wasm-editor-container",this.containerElement.appendChild(this.editorContainer),this.controlsContainer=document.createElement("div"),this.controlsContainer.className="wasm-controls",this.containerElement.appendChild(this.controlsContainer),this.element.parentNode&&this.element.parentNode.replaceChild(this.containerElement,this.element)}initializeEditor(){this.editorContainer&&(this.editor=new ae(this.editorContainer,this.currentCode,{language:this.metadata.language||"go",readonly:this.metadata.readonly||!1,theme:"vs-dark",minimap:!1,lineNumbers:!0}),this.editor.onChange(t=>{this.setCode(t)}),this.log("Editor initialized"))}initializeControls(){if(!this.controlsContainer)return;let t=document.createElement("div");t.className="wasm-buttons",this.runButton=new ce(t,"Run"),this.runButton.onClick(()=>this.executeCode());let r=document.createElement("button");r.className="livemdtools-reset-button",r.textContent="Reset",r.addEventListener("click",()=>this.resetCode()),t.appendChild(r),this.controlsContainer.appendChild(t),this.outputPanel=new le(this.controlsContainer),this.outputPanel.hide(),this.log("Controls initialized")}initializeExecutor(){this.executor=new de("/api/compile",this.debug),this.log("Executor initialized")}async executeCode(){if(!this.editor||!this.executor||!this.outputPanel){this.error("Cannot execute - components not initialized");return}let t=this.editor.getValue();this.log("Executing code"),this.outputPanel.clear(),this.outputPanel.show(),this.outputPanel.info("Compiling and running...");try{let r=await this.executor.execute(t);this.outputPanel.clear(),r.stdout&&this.outputPanel.stdout(r.stdout),r.stderr&&this.outputPanel.stderr(r.stderr),r.error&&this.outputPanel.error(r.error),r.exitCode===0&&!r.stdout&&!r.stderr&&this.outputPanel.info("Program completed successfully (no output)"),this.log("Execution completed with exit code:",r.exitCode)}catch(r){let n=r instanceof Error?r.message:String(r);this.outputPanel.error(`Execution error: ${n}`),this.error("Execution error:",r)}}resetCode(){this.editor&&(this.editor.setValue(this.initialCode),this.setCode(this.initialCode),this.outputPanel?.clear(),this.log("Code reset to initial state"))}};var reconnectBannerID="tinkerdown-reconnect-banner";function showReconnectBanner(){if(document.getElementById(reconnectBannerID))return;let o=document.createElement("div");o.id=reconnectBannerID,o.setAttribute("role","status"),o.textContent="Reconnecting…",o.style.cssText=`
+    position: fixed;
+    bottom: 20px;
+    left: 50%;
+    transform: translateX(-50%);
+    background: #1f2937;
+    color: white;
+    padding: 8px 16px;
+    border-radius: 8px;
+    box-shadow: 0 4px 12px rgba(0,0,0,0.15);
+    z-index: 100000;
+    font-family: system-ui, -apple-system, sans-serif;
+    font-size: 14px;
+  `,document.body.appendChild(o)}function hideReconnectBanner(){document.getElementById(reconnectBannerID)?.remove()}var he=class{constructor(e){this.tabs=null;this.blocks=new Map;this.ws=null;this.reconnectTimer=null;this.reconnectAttempts=0;this.isConnected=!1;this.options={debug:!1,persistence:!0,cdnFallback:!1,...e},this.router=new te(this.options.debug),this.persistence=new re("tinkerdown:persistence",this.options.persistence,this.options.debug),this.options.debug&&console.log("[TinkerdownClient] Initialized with options:",this.options)}connect(){if(this.ws){console.warn("[TinkerdownClient] Already connected");return}try{let e=new WebSocket(this.options.wsUrl);this.ws=e,this.ws.onopen=()=>{if(this.isConnected=!0,console.log("[TinkerdownClient] Connected to server"),this.reconnectAttempts>0){window.location.reload();return}this.options.onConnect?.()},this.ws.onclose=()=>{if(this.ws!==e)return;let t=this.isConnected;this.ws=null,this.isConnected=!1,t&&(console.log("[TinkerdownClient] Disconnected from server"),this.options.onDisconnect?.()),showReconnectBanner(),this.scheduleReconnect()},this.ws.onerror=e=>{console.error("[TinkerdownClient] WebSocket error:",e),this.options.onError?.(new Error("WebSocket error"))},this.ws.onmessage=e=>{this.handleMessage(e.data)}}catch(e){console.error("[TinkerdownClient] Failed to connect:",e),this.options.onError?.(e)}}disconnect(){this.reconnectTimer&&(clearTimeout(this.reconnectTimer),this.reconnectTimer=null),this.reconnectAttempts=0,hideReconnectBanner(),this.ws&&(this.ws.close(),this.ws=null),this.isConnected=!1}handleMessage(e){this.options.debug&&console.log("[TinkerdownClient] Received message:",e),this.router.route(e)}send(e,t,r={}){if(!this.isConnected||!this.ws){console.warn("[TinkerdownClient] Cannot send - not connected");return}let i=JSON.stringify({blockID:e,action:t,data:r});this.options.debug&&console.log("[TinkerdownClient] Sending message:",i),this.ws.send(i)}scheduleReconnect(){if(this.reconnectTimer)return;let e=Math.min(500*2**this.reconnectAttempts,1e4);this.reconnectAttempts++,this.reconnectTimer=window.setTimeout(()=>{this.reconnectTimer=null,console.log("[TinkerdownClient] Attempting to reconnect..."),this.connect()},e)}discoverBlocks(){console.log("[TinkerdownClient] Discovering blocks..."),(0,cr.setupReactiveAttributeListeners)(),this.tabs=new ne(this.options.debug),this.tabs.setMessageSender((t,r,n)=>{this.send(t,r,n)});let e=document.querySelectorAll("[data-tinkerdown-block]");for(let t of Array.from(e))try{let r=this.extractMetadata(t),n=this.extractCode(t),i={element:t,metadata:r,initialCode:n};this.registerBlock(i)}catch(r){console.error("[TinkerdownClient] Error discovering block:",r)}console.log(`[TinkerdownClient] Discovered ${this.blocks.size} blocks`)}extractMetadata(e){let t=e.dataset.blockId||this.generateBlockId(),r=e.dataset.blockType||"server",n=e.dataset.language||"go",i=e.dataset.readonly==="true",a=e.dataset.editable==="true",o=e.dataset.stateRef;return{id:t,type:r,language:n,readonly:i,editable:a,stateRef:o}}extractCode(e){let t=e.querySelector("code");return t?t.textContent||"":e.textContent||""}generateBlockId(){return`block-${Date.now()}-${Math.random().toString(36).substr(2,9)}`}registerBlock(e){let{metadata:t}=e;if(this.blocks.has(t.id)){console.warn(`[TinkerdownClient] Block already registered: ${t.id}`);return}let r;switch(t.type){case"server":r=new ie(e,this.persistence,this.options.debug);break;case"interactive":case"lvt":r=new se(e,this.persistence,this.options.debug),r.setMessageSender((n,i,a)=>{this.send(n,i,a)});break;case"wasm":r=new ue(e,this.persistence,this.options.debug);break;default:console.warn(`[TinkerdownClient] Unknown block type: ${t.type}`);return}r.initialize(),this.router.register(t.id,(n,i,a,o)=>{r.handleMessage(n,i,a,o)}),this.blocks.set(t.id,r),this.options.debug&&console.log(`[TinkerdownClient] Registered block: ${t.id} (${t.type})`)}unregisterBlock(e){let t=this.blocks.get(e);t&&(t.destroy(),this.router.unregister(e),this.blocks.delete(e),this.options.debug&&console.log(`[TinkerdownClient] Unregistered block: ${e}`))}getBlock(e){return this.blocks.get(e)}getBlockIds(){return Array.from(this.blocks.keys())}destroy(){console.log("[TinkerdownClient] Destroying client");for(let e of this.blocks.values())e.destroy();this.blocks.clear(),this.router.clear(),this.disconnect()}};var je=class{constructor(){this.steps=[];this.currentStepIndex=0;this.sidebar=null;this.bottomNav=null;this.init()}init(){if(document.querySelector(".tinkerdown-nav-sidebar"))return;let e=document.querySelector('meta[name="tinkerdown-sidebar"]');e&&e.getAttribute("content")==="false"||(this.parseSteps(),this.steps.length!==0&&(this.createSidebar(),this.createBottomNav(),this.setupKeyboardShortcuts(),this.setupHashNavigation(),this.setupScrollTracking(),this.initializeFromHash()))}parseSteps(){document.querySelectorAll("h2").forEach((t,r)=>{let n=t.id||this.generateId(t.textContent||"");t.id||(t.id=n),this.steps.push({id:n,title:t.textContent||"",element:t,index:r})})}generateId(e){return e.toLowerCase().replace(/[^\w\s-]/g,"").replace(/\s+/g,"-").replace(/-+/g,"-").trim()}createSidebar(){this.sidebar=document.createElement("nav"),this.sidebar.className="tinkerdown-nav-sidebar",this.sidebar.innerHTML=`
       <div class="nav-sidebar-header">
         <h3>Contents</h3>
       </div>
@@ -162,21 +176,21 @@
       </svg>
       <span>Search</span>
       <kbd>\u2318K</kbd>
-    `,r.addEventListener("click",()=>this.openSearch()),t.appendChild(r)}setupKeyboardShortcuts(){document.addEventListener("keydown",e=>{(e.ctrlKey||e.metaKey)&&e.key==="k"&&(e.preventDefault(),this.openSearch()),e.key==="Escape"&&this.searchModal?.classList.contains("open")&&this.closeSearch()})}openSearch(){this.searchModal?.classList.add("open"),this.searchInput?.focus(),this.selectedIndex=0}closeSearch(){this.searchModal?.classList.remove("open"),this.searchInput&&(this.searchInput.value=""),this.resultsContainer&&(this.resultsContainer.innerHTML="")}handleSearch(){let e=this.searchInput?.value.trim()||"";if(e.length===0){this.resultsContainer&&(this.resultsContainer.innerHTML="");return}let t=this.search(e);this.renderResults(t)}search(e){let t=e.toLowerCase(),r=[];for(let n of this.searchIndex){let i=n.title.toLowerCase(),a=n.content.toLowerCase(),o=i.includes(t),l=a.includes(t);if(o||l){let c=0;o&&(c+=10,i===t?c+=50:i.startsWith(t)&&(c+=20)),l&&(c+=1),r.push({...n,score:c,titleMatches:o,contentMatches:l})}}return r.sort((n,i)=>i.score-n.score),r.slice(0,10)}renderResults(e){if(this.resultsContainer){if(e.length===0){this.resultsContainer.innerHTML='<div class="search-no-results">No results found</div>';return}this.resultsContainer.innerHTML=e.map((t,r)=>`
+    `,r.addEventListener("click",()=>this.openSearch()),t.appendChild(r)}setupKeyboardShortcuts(){document.addEventListener("keydown",e=>{let t=window.tinkerdownShortcut;((e.ctrlKey||e.metaKey)&&e.key==="k"||t&&t(e,"search"))&&(e.preventDefault(),this.openSearch()),e.key==="Escape"&&this.searchModal?.classList.contains("open")&&this.closeSearch()})}openSearch(){this.searchModal?.classList.add("open"),this.searchInput?.focus(),this.selectedIndex=0}closeSearch(){this.searchModal?.classList.remove("open"),this.searchInput&&(this.searchInput.value=""),this.resultsContainer&&(this.resultsContainer.innerHTML="")}handleSearch(){let e=this.searchInput?.value.trim()||"";if(e.length===0){this.resultsContainer&&(this.resultsContainer.innerHTML="");return}let t=this.search(e);this.renderResults(t)}search(e){let t=e.toLowerCase(),r=[];for(let n of this.searchIndex){let i=n.title.toLowerCase(),a=n.content.toLowerCase(),o=i.includes(t),d=n.headings?.find(h=>h.text.toLowerCase().includes(t)),u=n.headings?.find(h=>h.content?.toLowerCase().includes(t)),l=a.includes(t)||u!==void 0;if(o||d||l){let c=0;o?(c+=100,i===t?c+=50:i.startsWith(t)&&(c+=20)):d&&(c+=10),l&&(c+=1);let p=o?void 0:d??u,f=n.content;p?.content&&(p===d||!a.includes(t))&&(f=p.content),r.push({...n,score:c,titleMatches:o,contentMatches:l,heading:p,excerpt:f})}}return r.sort((n,i)=>i.score-n.score),r.slice(0,10)}renderResults(e){if(this.resultsContainer){if(e.length===0){this.resultsContainer.innerHTML='<div class="search-no-results">No results found</div>';return}let n=this.searchInput?.value.trim()||"";this.resultsContainer.innerHTML=e.map((t,r)=>{let i=t.heading?`${t.path}#${encodeURIComponent(t.heading.id)}`:t.path,a=t.heading?t.heading.text:t.section;return`
       <a
-        href="${t.path}"
+        href="${this.escapeHTML(i)}"
         class="search-result ${r===this.selectedIndex?"selected":""}"
         data-index="${r}"
       >
         <div class="search-result-title">
-          ${this.highlightMatch(t.title,this.searchInput?.value||"")}
+          ${this.highlightMatch(this.escapeHTML(t.title),n)}
         </div>
-        ${t.section?`<div class="search-result-section">${t.section}</div>`:""}
+        ${a?`<div class="search-result-section">${this.highlightMatch(this.escapeHTML(a),n)}</div>`:""}
         <div class="search-result-content">
-          ${this.highlightMatch(this.truncateContent(t.content),this.searchInput?.value||"")}
+          ${this.highlightMatch(this.escapeHTML(this.snippet(t.excerpt,n)),n)}
         </div>
       </a>
-    `).join(""),this.resultsContainer.querySelectorAll(".search-result").forEach(t=>{t.addEventListener("click",()=>this.closeSearch())})}}highlightMatch(e,t){if(!t)return e;let r=new RegExp(`(${this.escapeRegex(t)})`,"gi");return e.replace(r,"<mark>$1</mark>")}truncateContent(e,t=200){return e.length<=t?e:e.substring(0,t)+"..."}escapeRegex(e){return e.replace(/[.*+?^${}()|[\]\\]/g,"\\$&")}handleKeyDown(e){if(!this.resultsContainer)return;let t=this.resultsContainer.querySelectorAll(".search-result");switch(e.key){case"Escape":e.preventDefault(),this.closeSearch();break;case"ArrowDown":if(t.length===0)return;e.preventDefault(),this.selectedIndex=Math.min(this.selectedIndex+1,t.length-1),this.updateSelection();break;case"ArrowUp":if(t.length===0)return;e.preventDefault(),this.selectedIndex=Math.max(this.selectedIndex-1,0),this.updateSelection();break;case"Enter":if(t.length===0)return;e.preventDefault();let r=t[this.selectedIndex];r&&(window.location.href=r.href,this.closeSearch());break}}updateSelection(){if(!this.resultsContainer)return;let e=this.resultsContainer.querySelectorAll(".search-result");e.forEach((r,n)=>{r.classList.toggle("selected",n===this.selectedIndex)});let t=e[this.selectedIndex];t&&t.scrollIntoView({block:"nearest",behavior:"smooth"})}};var ze=class s{static{this.COPY_BUTTON_CLASS="code-copy-btn"}static{this.CODE_WRAPPER_CLASS="code-block-wrapper"}static{this.COPIED_CLASS="copied"}constructor(){this.init()}init(){document.readyState==="loading"?document.addEventListener("DOMContentLoaded",()=>this.addCopyButtons()):this.addCopyButtons()}addCopyButtons(){document.querySelectorAll("pre > code").forEach(t=>{let r=t.parentElement;if(!r||r.querySelector(`.${s.COPY_BUTTON_CLASS}`))return;if(!r.parentElement?.classList.contains(s.CODE_WRAPPER_CLASS)){let i=document.createElement("div");i.className=s.CODE_WRAPPER_CLASS,r.parentNode?.insertBefore(i,r),i.appendChild(r)}let n=this.createCopyButton();r.appendChild(n),n.addEventListener("click",i=>{i.preventDefault(),this.copyCode(t,n)})})}createCopyButton(){let e=document.createElement("button");return e.className=s.COPY_BUTTON_CLASS,e.setAttribute("aria-label","Copy code to clipboard"),e.innerHTML=this.getCopyIcon(),e}async copyCode(e,t){let r=e.textContent||"";try{await navigator.clipboard.writeText(r),this.showCopiedFeedback(t)}catch(n){console.error("Failed to copy code:",n),this.fallbackCopy(r,t)}}showCopiedFeedback(e){e.classList.add(s.COPIED_CLASS),e.innerHTML=this.getCheckIcon(),e.setAttribute("aria-label","Code copied!"),setTimeout(()=>{e.classList.remove(s.COPIED_CLASS),e.innerHTML=this.getCopyIcon(),e.setAttribute("aria-label","Copy code to clipboard")},2e3)}fallbackCopy(e,t){let r=document.createElement("textarea");r.value=e,r.style.position="fixed",r.style.opacity="0",document.body.appendChild(r),r.select();try{document.execCommand("copy"),this.showCopiedFeedback(t)}catch(n){console.error("Fallback copy failed:",n)}finally{document.body.removeChild(r)}}getCopyIcon(){return`
+    `}).join(""),this.resultsContainer.querySelectorAll(".search-result").forEach(t=>{t.addEventListener("click",()=>this.closeSearch())})}}highlightMatch(e,t){let r=t.split(/\s+/).filter(i=>i).map(i=>this.escapeRegex(this.escapeHTML(i)));if(r.length===0)return e;let n=new RegExp(`(&[#\\w]+;)|(${r.join("|")})`,"gi");return e.replace(n,(i,a)=>a?i:`<mark>${i}</mark>`)}truncateContent(e,t=200){return e.length<=t?e:e.substring(0,t)+"..."}snippet(e,t,r=200){let n=e.toLowerCase().indexOf(t.toLowerCase());if(n<0||e.length<=r)return this.truncateContent(e,r);let i=Math.max(0,Math.min(n-60,e.length-r)),a=i+r;return(i>0?"...":"")+e.substring(i,a)+(a<e.length?"...":"")}escapeHTML(e){return e.replace(/&/g,"&amp;").replace(/</g,"&lt;").replace(/>/g,"&gt;").replace(/"/g,"&quot;")}escapeRegex(e){return e.replace(/[.*+?^${}()|[\]\\]/g,"\\$&")}handleKeyDown(e){if(!this.resultsContainer)return;let t=this.resultsContainer.querySelectorAll(".search-result");switch(e.key){case"Escape":e.preventDefault(),e.stopPropagation(),this.closeSearch();break;case"ArrowDown":if(t.length===0)return;e.preventDefault(),this.selectedIndex=Math.min(this.selectedIndex+1,t.length-1),this.updateSelection();break;case"ArrowUp":if(t.length===0)return;e.preventDefault(),this.selectedIndex=Math.max(this.selectedIndex-1,0),this.updateSelection();break;case"Enter":if(t.length===0)return;e.preventDefault();let r=t[this.selectedIndex];r&&(window.location.href=r.href,this.closeSearch());break}}updateSelection(){if(!this.resultsContainer)return;let e=this.resultsContainer.querySelectorAll(".search-result");e.forEach((r,n)=>{r.classList.toggle("selected",n===this.selectedIndex)});let t=e[this.selectedIndex];t&&t.scrollIntoView({block:"nearest",behavior:"smooth"})}};var ze=class s{static{this.COPY_BUTTON_CLASS="code-copy-btn"}static{this.CODE_WRAPPER_CLASS="code-block-wrapper"}static{this.COPIED_CLASS="copied"}constructor(){this.init()}init(){document.readyState==="loading"?document.addEventListener("DOMContentLoaded",()=>this.addCopyButtons()):this.addCopyButtons()}addCopyButtons(){document.querySelectorAll("pre > code").forEach(t=>{let r=t.parentElement;if(!r||r.querySelector(`.${s.COPY_BUTTON_CLASS}`))return;if(!r.parentElement?.classList.contains(s.CODE_WRAPPER_CLASS)){let i=document.createElement("div");i.className=s.CODE_WRAPPER_CLASS,r.parentNode?.insertBefore(i,r),i.appendChild(r)}let n=this.createCopyButton();r.appendChild(n),n.addEventListener("click",i=>{i.preventDefault(),this.copyCode(t,n)})})}createCopyButton(){let e=document.createElement("button");return e.className=s.COPY_BUTTON_CLASS,e.setAttribute("aria-label","Copy code to clipboard"),e.innerHTML=this.getCopyIcon(),e}async copyCode(e,t){let r=e.textContent||"";try{await navigator.clipboard.writeText(r),this.showCopiedFeedback(t)}catch(n){console.error("Failed to copy code:",n),this.fallbackCopy(r,t)}}showCopiedFeedback(e){e.classList.add(s.COPIED_CLASS),e.innerHTML=this.getCheckIcon(),e.setAttribute("aria-label","Code copied!"),setTimeout(()=>{e.classList.remove(s.COPIED_CLASS),e.innerHTML=this.getCopyIcon(),e.setAttribute("aria-label","Copy code to clipboard")},2e3)}fallbackCopy(e,t){let r=document.createElement("textarea");r.value=e,r.style.position="fixed",r.style.opacity="0",document.body.appendChild(r),r.select();try{document.execCommand("copy"),this.showCopiedFeedback(t)}catch(n){console.error("Fallback copy failed:",n)}finally{document.body.removeChild(r)}}getCopyIcon(){return`
       <svg width="16" height="16" viewBox="0 0 16 16" fill="none" xmlns="http://www.w3.org/2000/svg">
         <path d="M10.5 2H3.5C2.67 2 2 2.67 2 3.5V10.5" stroke="currentColor" stroke-width="1.5" stroke-linecap="round" stroke-linejoin="round"/>
         <rect x="5.5" y="5.5" width="8" height="8" rx="1" stroke="currentColor" stroke-width="1.5"/>
@@ -0,0 +1,66 @@
+package assets
+
+import "bytes"
+
+// MinifyCSS removes comments and the whitespace CSS doesn't need: runs of
+// whitespace become one space, and spaces next to { } ; , > and after : are
+// dropped, as is the ; before }. Spaces before : are kept, since
+// "a :hover" and "a:hover" are different selectors, and strings are copied
+// as they are.
+func MinifyCSS(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	space := false // Whitespace or a comment since the last byte written
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '/' && i+1 < len(src) && src[i+1] == '*' {
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				break
+			}
+			i += end + 3
+			space = true
+			continue
+		}
+		if isCSSSpace(c) {
+			space = true
+			continue
+		}
+
+		if space && len(out) > 0 {
+			last := out[len(out)-1]
+			if !isCSSTight(last) && last != ':' && !isCSSTight(c) {
+				out = append(out, ' ')
+			}
+		}
+		space = false
+
+		switch {
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j, len(src)-1)
+			out = append(out, src[i:j+1]...)
+			i = j
+		case c == '}' && len(out) > 0 && out[len(out)-1] == ';':
+			out[len(out)-1] = '}'
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// isCSSSpace reports whether c is CSS whitespace.
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isCSSTight reports whether whitespace next to c can be dropped.
+func isCSSTight(c byte) bool {
+	return c == '{' || c == '}' || c == ';' || c == ',' || c == '>'
+}
@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/source"
 )
 
 // ExecArg represents a parsed command-line argument
@@ -27,7 +29,10 @@ type ExecArg struct {
 // All type hints are stripped from the returned args - they're only used to determine
 // the input type for form generation.
 func ParseExecCommand(cmd string) (executable string, args []ExecArg, err error) {
-	parts := strings.Fields(cmd)
+	parts, err := source.SplitCommand(cmd)
+	if err != nil {
+		return "", nil, err
+	}
 	if len(parts) == 0 {
 		return "", nil, nil
 	}
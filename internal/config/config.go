@@ -2,14 +2,22 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/livetemplate/tinkerdown/internal/logging"
 	"gopkg.in/yaml.v3"
 )
 
+var configLog = logging.New("Config")
+
 // Config represents the tinkerdown configuration
 type Config struct {
 	Title       string                  `yaml:"title"`
@@ -21,12 +29,28 @@ type Config struct {
 	Styling     StylingConfig           `yaml:"styling"`
 	Blocks      BlocksConfig            `yaml:"blocks"`
 	Features    FeaturesConfig          `yaml:"features"`
+	Markdown    MarkdownConfig          `yaml:"markdown,omitempty"`
+	ReadingTime ReadingTimeConfig       `yaml:"reading_time,omitempty"`
+	Images      ImagesConfig            `yaml:"images,omitempty"`
+	JumpNav     JumpNavConfig           `yaml:"jump_nav,omitempty"`
+	CSP         CSPConfig               `yaml:"csp,omitempty"`
+	Robots      string                  `yaml:"robots,omitempty"` // "allow" or "disallow" crawlers in /robots.txt (default: allow)
+	BaseURL     string                  `yaml:"base_url,omitempty"` // Public URL of the site, for canonical links (e.g. https://docs.example.com)
+	Locales     []LocaleConfig          `yaml:"locales,omitempty"` // Languages served from top-level directories; the first is the default
+	Prose       ProseConfig             `yaml:"prose,omitempty"` // Spell check settings for validate --prose
+	Validate    ValidateConfig          `yaml:"validate,omitempty"` // Defaults for the validate command
+	Vars        map[string]string       `yaml:"vars,omitempty"` // {{ site.name }} placeholders in page content
+	Shortcuts   map[string]string       `yaml:"shortcuts,omitempty"` // Keyboard shortcut action -> key ("" or "none" disables)
 	Ignore      []string                `yaml:"ignore"`
 	Sources     map[string]SourceConfig `yaml:"sources,omitempty"`
 	Actions     map[string]*Action      `yaml:"actions,omitempty"`
 	API         *APIConfig              `yaml:"api,omitempty"`
 	Webhooks    map[string]*Webhook     `yaml:"webhooks,omitempty"`
 	Outputs     map[string]*OutputConfig `yaml:"outputs,omitempty"`
+
+	// Executables exec sources and actions may run, as base names or
+	// absolute paths. Unset allows any; serve --untrusted allows none.
+	ExecAllowlist []string `yaml:"exec_allowlist,omitempty"`
 }
 
 // OutputConfig defines an output destination for notifications.
@@ -115,7 +139,7 @@ func (c *Config) ValidateOutputs() error {
 
 // SourceConfig defines a data source for lvt-source blocks
 type SourceConfig struct {
-	Type        string                 `yaml:"type"`                   // "exec", "pg", "rest", "csv", "json", "markdown", "sqlite", "wasm", "graphql"
+	Type        string                 `yaml:"type"`                   // "exec", "pg", "rest", "csv", "json", "markdown", "frontmatter", "sqlite", "wasm", "graphql", "upload"
 	Cmd         string                 `yaml:"cmd,omitempty"`          // For exec: command to run
 	Query       string                 `yaml:"query,omitempty"`        // For pg: SQL query
 	From        string                 `yaml:"from,omitempty"`         // For rest/graphql: API endpoint URL
@@ -123,7 +147,7 @@ type SourceConfig struct {
 	Anchor      string                 `yaml:"anchor,omitempty"`       // For markdown: section anchor (e.g., "#todos")
 	DB          string                 `yaml:"db,omitempty"`           // For sqlite: database file path (default: ./tinkerdown.db)
 	Table       string                 `yaml:"table,omitempty"`        // For sqlite: table name
-	Path        string                 `yaml:"path,omitempty"`         // For wasm: path to .wasm file
+	Path        string                 `yaml:"path,omitempty"`         // For wasm: path to .wasm file; for upload: storage directory (default: _uploads)
 	QueryFile   string                 `yaml:"query_file,omitempty"`   // For graphql: path to .graphql file
 	Variables   map[string]interface{} `yaml:"variables,omitempty"`    // For graphql: query variables
 	Headers     map[string]string      `yaml:"headers,omitempty"`      // For rest/graphql: HTTP headers (env vars expanded)
@@ -139,6 +163,11 @@ type SourceConfig struct {
 	Retry       *RetryConfig           `yaml:"retry,omitempty"`        // Retry configuration
 	Cache       *CacheConfig           `yaml:"cache,omitempty"`        // Cache configuration
 	AutoBind    *bool                  `yaml:"auto_bind,omitempty"`    // Set to false to exclude from auto-table matching
+	Fields      map[string]FieldRule   `yaml:"fields,omitempty"`       // For markdown/sqlite: constraints checked on Add and Update
+
+	// For exec sources with side effects: never auto-run, and ask before each Run
+	Confirm    bool   `yaml:"confirm,omitempty"`
+	ConfirmMsg string `yaml:"confirm_message,omitempty"` // Question asked before running (default: "Run this command?")
 
 	// For computed sources: derive data from another source
 	GroupBy   string            `yaml:"group_by,omitempty"`   // Field to group by (e.g., "category")
@@ -161,6 +190,14 @@ type CacheConfig struct {
 	MaxBytes int    `yaml:"max_bytes,omitempty"` // Maximum bytes to cache (truncates if exceeded). Default: unlimited
 }
 
+// FieldRule constrains the value of one field in Add and Update actions on
+// a writable source. Values that break a rule are rejected before any write.
+type FieldRule struct {
+	Required bool   `yaml:"required,omitempty"` // Must be present and not blank on Add; must not be blanked on Update
+	MaxLen   int    `yaml:"maxlen,omitempty"`   // Maximum length in characters (0 = unlimited)
+	Pattern  string `yaml:"pattern,omitempty"`  // Regular expression the whole value must match, like HTML's pattern attribute
+}
+
 // IsReadonly returns true if the source is read-only (default: true for markdown sources)
 func (c SourceConfig) IsReadonly() bool {
 	if c.Readonly == nil {
@@ -176,12 +213,156 @@ func (c SourceConfig) GetTimeout() time.Duration {
 	}
 	d, err := time.ParseDuration(c.Timeout)
 	if err != nil {
-		log.Printf("[Config] Warning: invalid timeout %q, using default 10s", c.Timeout)
+		configLog.Warnf("invalid timeout %q, using default 10s", c.Timeout)
 		return 10 * time.Second
 	}
 	return d
 }
 
+// minPollInterval is the shortest options["poll"] interval honored, so a
+// typo like "1ms" can't make every open tab re-fetch continuously.
+const minPollInterval = time.Second
+
+// GetPollInterval returns how often blocks bound to this source refresh
+// themselves, from options["poll"] (e.g. "30s"). Zero means no polling.
+func (c SourceConfig) GetPollInterval() time.Duration {
+	poll := c.Options["poll"]
+	if poll == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(poll)
+	if err != nil || d <= 0 {
+		configLog.Warnf("invalid poll %q, polling disabled", poll)
+		return 0
+	}
+	if d < minPollInterval {
+		configLog.Warnf("poll %q is below the minimum, using %s", poll, minPollInterval)
+		return minPollInterval
+	}
+	return d
+}
+
+// defaultUploadDir is where upload sources store files unless path is set.
+// The leading underscore keeps it out of page discovery and static serving.
+const defaultUploadDir = "_uploads"
+
+// defaultUploadMaxSize bounds one uploaded file unless options["max_size"]
+// is set.
+const defaultUploadMaxSize = 10 << 20
+
+// GetUploadDir returns the directory an upload source stores files in
+// (default: _uploads).
+func (c SourceConfig) GetUploadDir() string {
+	if c.Path == "" {
+		return defaultUploadDir
+	}
+	return c.Path
+}
+
+// GetUploadMaxSize returns the largest file an upload source accepts, in
+// bytes, from options["max_size"]: a byte count or a size such as "500KB"
+// or "5MB" (default: 10MB).
+func (c SourceConfig) GetUploadMaxSize() int64 {
+	size := c.Options["max_size"]
+	if size == "" {
+		return defaultUploadMaxSize
+	}
+	n, err := parseByteSize(size)
+	if err != nil || n <= 0 {
+		configLog.Warnf("invalid max_size %q, using default 10MB", size)
+		return defaultUploadMaxSize
+	}
+	return n
+}
+
+// GetUploadTypes returns the file types an upload source accepts, from the
+// comma-separated options["types"]: MIME types ("text/csv"), MIME
+// wildcards ("image/*") or extensions (".pdf"). Nil accepts any type.
+func (c SourceConfig) GetUploadTypes() []string {
+	var types []string
+	for _, t := range strings.Split(c.Options["types"], ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// defaultConfirmMessage is asked before running a confirm exec source
+// without a confirm_message.
+const defaultConfirmMessage = "Run this command?"
+
+// GetConfirmMessage returns the question asked before running a confirm exec
+// source.
+func (c SourceConfig) GetConfirmMessage() string {
+	if msg := strings.TrimSpace(c.ConfirmMsg); msg != "" {
+		return msg
+	}
+	return defaultConfirmMessage
+}
+
+// GetSecretArgs returns the exec argument names listed in
+// options["secret_args"] (comma-separated), whose values are hidden in the
+// displayed command.
+func (c SourceConfig) GetSecretArgs() []string {
+	var names []string
+	for _, name := range strings.Split(c.Options["secret_args"], ",") {
+		if name = strings.TrimLeft(strings.TrimSpace(name), "-"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetMaxOutput returns how many bytes of stdout, and separately of stderr,
+// an exec source keeps, from options["max_output"]: a byte count or a size
+// such as "64KB" or "1MB". Zero means no limit.
+func (c SourceConfig) GetMaxOutput() int64 {
+	size := c.Options["max_output"]
+	if size == "" {
+		return 0
+	}
+	n, err := parseByteSize(size)
+	if err != nil || n <= 0 {
+		configLog.Warnf("invalid max_output %q, output not limited", size)
+		return 0
+	}
+	return n
+}
+
+// IsStreaming reports whether an exec source shows its output line by line
+// as the command runs, from options["stream"], instead of parsing it once
+// the command exits.
+func (c SourceConfig) IsStreaming() bool {
+	stream, _ := strconv.ParseBool(c.Options["stream"])
+	return stream
+}
+
+// parseByteSize parses a byte count with an optional KB, MB or GB suffix
+// (powers of 1024, case-insensitive).
+func parseByteSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return n * multiplier, nil
+}
+
 // GetRetryMaxRetries returns the max retries (default: 3, set to 0 to disable retries)
 func (c SourceConfig) GetRetryMaxRetries() int {
 	if c.Retry == nil {
@@ -200,7 +381,7 @@ func (c SourceConfig) GetRetryBaseDelay() time.Duration {
 	}
 	d, err := time.ParseDuration(c.Retry.BaseDelay)
 	if err != nil {
-		log.Printf("[Config] Warning: invalid retry base_delay %q, using default 100ms", c.Retry.BaseDelay)
+		configLog.Warnf("invalid retry base_delay %q, using default 100ms", c.Retry.BaseDelay)
 		return 100 * time.Millisecond
 	}
 	return d
@@ -213,7 +394,7 @@ func (c SourceConfig) GetRetryMaxDelay() time.Duration {
 	}
 	d, err := time.ParseDuration(c.Retry.MaxDelay)
 	if err != nil {
-		log.Printf("[Config] Warning: invalid retry max_delay %q, using default 5s", c.Retry.MaxDelay)
+		configLog.Warnf("invalid retry max_delay %q, using default 5s", c.Retry.MaxDelay)
 		return 5 * time.Second
 	}
 	return d
@@ -231,7 +412,7 @@ func (c SourceConfig) GetCacheTTL() time.Duration {
 	}
 	d, err := time.ParseDuration(c.Cache.TTL)
 	if err != nil {
-		log.Printf("[Config] Warning: invalid cache ttl %q, caching disabled", c.Cache.TTL)
+		configLog.Warnf("invalid cache ttl %q, caching disabled", c.Cache.TTL)
 		return 0
 	}
 	return d
@@ -415,6 +596,7 @@ type SiteConfig struct {
 	Home       string `yaml:"home"`        // Homepage markdown file (e.g., "index.md")
 	Logo       string `yaml:"logo"`        // Logo path (e.g., "/assets/logo.svg")
 	Repository string `yaml:"repository"`  // GitHub repository URL
+	NavWrap    bool   `yaml:"nav_wrap"`    // Prev/next wrap around from the last page to the first
 }
 
 // NavSection represents a navigation section with pages
@@ -436,6 +618,25 @@ type ServerConfig struct {
 	Port  int    `yaml:"port"`
 	Host  string `yaml:"host"`
 	Debug bool   `yaml:"debug"`
+
+	// MaxConnections caps concurrent WebSocket connections (0 = unlimited).
+	MaxConnections int `yaml:"max_connections,omitempty"`
+	// IdleTimeout is how long a WebSocket connection may go without
+	// answering a ping before it is dropped (e.g., "60s").
+	IdleTimeout string `yaml:"idle_timeout,omitempty"`
+}
+
+// GetIdleTimeout returns the parsed WebSocket idle timeout (default: 60s)
+func (c ServerConfig) GetIdleTimeout() time.Duration {
+	if c.IdleTimeout == "" {
+		return 60 * time.Second
+	}
+	d, err := time.ParseDuration(c.IdleTimeout)
+	if err != nil || d <= 0 {
+		configLog.Warnf("invalid server idle_timeout %q, using default 60s", c.IdleTimeout)
+		return 60 * time.Second
+	}
+	return d
 }
 
 // StylingConfig holds styling-related configuration
@@ -443,6 +644,55 @@ type StylingConfig struct {
 	Theme        string `yaml:"theme"`
 	PrimaryColor string `yaml:"primary_color"`
 	Font         string `yaml:"font"`
+	ScrollOffset string `yaml:"scroll_offset,omitempty"` // Space kept above anchor targets (e.g., "80px", "5rem")
+
+	// Color presets
+	Preset    string   `yaml:"preset,omitempty"`     // Preset pages start with (e.g., "nord"); readers can pick another
+	Presets   []string `yaml:"presets,omitempty"`    // Names of the site's own presets, defined in CustomCSS
+	CustomCSS string   `yaml:"custom_css,omitempty"` // Stylesheet loaded after the built-in styles (e.g., "static/theme.css")
+}
+
+// BuiltinPresets are the names of the built-in color presets, in picker order.
+var BuiltinPresets = []string{"solarized", "nord", "high-contrast"}
+
+// presetNamePattern matches a preset name that is safe in a CSS attribute
+// selector.
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ValidPresetName reports whether name can be used as a preset name.
+func ValidPresetName(name string) bool {
+	return presetNamePattern.MatchString(name)
+}
+
+// ValidatePresets checks that the names in presets are usable and that
+// preset names a built-in preset or one of them.
+func (c StylingConfig) ValidatePresets() error {
+	for _, name := range c.Presets {
+		if !ValidPresetName(name) {
+			return &settingError{"styling.presets", fmt.Sprintf("%q is not a valid preset name (use lowercase letters, digits and '-')", name)}
+		}
+	}
+	if c.Preset != "" && !slices.Contains(BuiltinPresets, c.Preset) && !slices.Contains(c.Presets, c.Preset) {
+		return &settingError{"styling.preset", fmt.Sprintf("unknown preset %q (expected one of: %s)", c.Preset, strings.Join(append(slices.Clone(BuiltinPresets), c.Presets...), ", "))}
+	}
+	return nil
+}
+
+// scrollOffsetPattern matches a CSS length; bare numbers are pixels.
+var scrollOffsetPattern = regexp.MustCompile(`^\d+(\.\d+)?(px|rem|em|vh)?$`)
+
+// GetScrollOffset returns the configured anchor scroll offset as a CSS length,
+// or "" when unset or not a valid length (the stylesheet default applies).
+func (c StylingConfig) GetScrollOffset() string {
+	offset := strings.TrimSpace(c.ScrollOffset)
+	m := scrollOffsetPattern.FindStringSubmatch(offset)
+	if m == nil {
+		return ""
+	}
+	if m[2] == "" {
+		return offset + "px"
+	}
+	return offset
 }
 
 // BlocksConfig holds block-related configuration
@@ -457,6 +707,113 @@ type FeaturesConfig struct {
 	HotReload bool `yaml:"hot_reload"`
 	Sidebar   bool `yaml:"sidebar"`  // Show navigation sidebar (default: false)
 	Headless  bool `yaml:"headless"` // Run without web UI, only API/webhooks/schedules
+
+	// SidebarCollapsible makes sidebar sections collapsible, remembering
+	// their open/closed state in the browser (default: false)
+	SidebarCollapsible bool `yaml:"sidebar_collapsible"`
+}
+
+// MarkdownConfig toggles optional GitHub-flavored markdown extensions.
+// All extensions are enabled unless explicitly set to false.
+type MarkdownConfig struct {
+	Footnotes     *bool `yaml:"footnotes,omitempty"`
+	Strikethrough *bool `yaml:"strikethrough,omitempty"`
+	Autolinks     *bool `yaml:"autolinks,omitempty"`
+	TaskLists     *bool `yaml:"task_lists,omitempty"`
+	LineNumbers   bool  `yaml:"line_numbers,omitempty"` // Number every code block's lines (default: false)
+}
+
+// ReadingTimeConfig controls the estimated reading time and scroll progress bar.
+type ReadingTimeConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty"` // Show reading time and progress bar (default: true)
+	WPM     int   `yaml:"wpm,omitempty"`     // Reading speed in words per minute (default: 200)
+}
+
+// IsEnabled returns whether reading time is shown (default: true)
+func (c ReadingTimeConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// GetWPM returns the reading speed in words per minute (default: 200)
+func (c ReadingTimeConfig) GetWPM() int {
+	if c.WPM <= 0 {
+		return 200
+	}
+	return c.WPM
+}
+
+// JumpNavConfig controls the floating back-to-top and section jump buttons.
+type JumpNavConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty"` // Show the buttons on long pages (default: true)
+}
+
+// IsEnabled returns whether the jump buttons are shown (default: true)
+func (c JumpNavConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// CSPConfig sets the Content-Security-Policy header of the web UI.
+type CSPConfig struct {
+	Strict bool   `yaml:"strict,omitempty"` // Allow inline scripts by a per-response nonce instead of 'unsafe-inline'
+	Policy string `yaml:"policy,omitempty"` // The whole policy, overriding strict; 'nonce-{nonce}' stands for the response's nonce
+}
+
+// ProseConfig configures the spell check of validate --prose.
+type ProseConfig struct {
+	Dictionary []string `yaml:"dictionary,omitempty"` // Word list files, one word per line; when set, words missing from them are flagged
+	Words      []string `yaml:"words,omitempty"`      // Extra accepted words (product names, jargon)
+}
+
+// ValidateConfig configures the validate command.
+type ValidateConfig struct {
+	Strict bool `yaml:"strict,omitempty"` // Fail on warnings too, like validate --strict
+}
+
+// ImagesConfig controls the resized variants offered for local content images.
+type ImagesConfig struct {
+	Responsive *bool `yaml:"responsive,omitempty"` // Add srcset and lazy loading to local images (default: true)
+	Widths     []int `yaml:"widths,omitempty"`     // Variant widths in pixels (default: 480, 960, 1600)
+}
+
+// IsResponsive returns whether local images get srcset variants (default: true)
+func (c ImagesConfig) IsResponsive() bool {
+	return c.Responsive == nil || *c.Responsive
+}
+
+// GetWidths returns the variant widths in ascending order, ignoring
+// non-positive and duplicate entries (default: 480, 960, 1600)
+func (c ImagesConfig) GetWidths() []int {
+	var widths []int
+	for _, w := range c.Widths {
+		if w > 0 && !slices.Contains(widths, w) {
+			widths = append(widths, w)
+		}
+	}
+	if len(widths) == 0 {
+		return []int{480, 960, 1600}
+	}
+	slices.Sort(widths)
+	return widths
+}
+
+// IsFootnotesEnabled returns whether footnotes are rendered (default: true)
+func (c MarkdownConfig) IsFootnotesEnabled() bool {
+	return c.Footnotes == nil || *c.Footnotes
+}
+
+// IsStrikethroughEnabled returns whether ~~strikethrough~~ is rendered (default: true)
+func (c MarkdownConfig) IsStrikethroughEnabled() bool {
+	return c.Strikethrough == nil || *c.Strikethrough
+}
+
+// IsAutolinksEnabled returns whether bare URLs are linked (default: true)
+func (c MarkdownConfig) IsAutolinksEnabled() bool {
+	return c.Autolinks == nil || *c.Autolinks
+}
+
+// IsTaskListsEnabled returns whether task list checkboxes are rendered (default: true)
+func (c MarkdownConfig) IsTaskListsEnabled() bool {
+	return c.TaskLists == nil || *c.TaskLists
 }
 
 // APIConfig holds REST API configuration
@@ -604,6 +961,134 @@ func (c *Config) IsAPIEnabled() bool {
 	return c.API != nil && c.API.Enabled
 }
 
+// DefaultShortcuts maps keyboard shortcut actions to their default keys.
+// Keys are written like "f", "?" or "Ctrl+Shift+D".
+var DefaultShortcuts = map[string]string{
+	"help":         "?",
+	"theme":        "Ctrl+Shift+D",
+	"presentation": "f",
+	"presenter":    "n",
+	"search":       "/",
+	"filter":       "Ctrl+/",
+}
+
+// GetShortcuts returns the effective keymap: DefaultShortcuts overridden by
+// the shortcuts config. Disabled actions ("" or "none") are omitted.
+func (c *Config) GetShortcuts() map[string]string {
+	keymap := make(map[string]string, len(DefaultShortcuts))
+	for action, key := range DefaultShortcuts {
+		keymap[action] = key
+	}
+	for action, key := range c.Shortcuts {
+		if _, known := DefaultShortcuts[action]; !known {
+			continue
+		}
+		if key == "" || strings.EqualFold(key, "none") {
+			delete(keymap, action)
+			continue
+		}
+		keymap[action] = key
+	}
+	return keymap
+}
+
+// ValidateShortcuts checks that shortcut actions exist and that no two
+// actions share a key.
+func (c *Config) ValidateShortcuts() error {
+	actions := make([]string, 0, len(c.Shortcuts))
+	for action := range c.Shortcuts {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		if _, known := DefaultShortcuts[action]; !known {
+			return &settingError{"shortcuts." + action, "unknown action (must be one of help, theme, presentation, presenter, search, filter)"}
+		}
+	}
+
+	keymap := c.GetShortcuts()
+	actions = actions[:0]
+	for action := range keymap {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	seen := make(map[string]string)
+	for _, action := range actions {
+		key := strings.ToLower(keymap[action])
+		if other, dup := seen[key]; dup {
+			if _, set := c.Shortcuts[action]; !set {
+				action, other = other, action // Report the one the config remaps
+			}
+			return &settingError{"shortcuts." + action, fmt.Sprintf("key %q is already used by %q", keymap[action], other)}
+		}
+		seen[key] = action
+	}
+	return nil
+}
+
+// RobotsAllowed returns whether /robots.txt lets crawlers index the site.
+// Anything other than "disallow" allows them.
+func (c *Config) RobotsAllowed() bool {
+	return !strings.EqualFold(c.Robots, "disallow")
+}
+
+// ValidateRobots checks that robots is "allow" or "disallow" when set.
+func (c *Config) ValidateRobots() error {
+	switch strings.ToLower(c.Robots) {
+	case "", "allow", "disallow":
+		return nil
+	}
+	return &settingError{"robots", fmt.Sprintf("%q must be allow or disallow", c.Robots)}
+}
+
+// LocaleConfig is a language the site is written in. Its pages live in the
+// top-level directory named after its code, so fr/intro.md is served at
+// /fr/intro.
+type LocaleConfig struct {
+	Code  string `yaml:"code"`            // Language tag, e.g. "en", "fr" or "pt-BR"
+	Label string `yaml:"label,omitempty"` // Name shown in the language switcher (default: the code)
+}
+
+// GetLabel returns the name shown for the locale in the language switcher.
+func (l LocaleConfig) GetLabel() string {
+	if l.Label != "" {
+		return l.Label
+	}
+	return l.Code
+}
+
+// localeCodePattern matches a language tag usable as a directory name.
+var localeCodePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// PathLocale returns the code of the configured locale a URL path belongs
+// to, taken from its first segment ("/fr/intro" is in "fr"), or "" when the
+// path isn't under a locale.
+func (c *Config) PathLocale(urlPath string) string {
+	first, _, _ := strings.Cut(strings.TrimPrefix(urlPath, "/"), "/")
+	for _, l := range c.Locales {
+		if first == l.Code {
+			return l.Code
+		}
+	}
+	return ""
+}
+
+// ValidateLocales checks that locale codes are language tags and that none
+// is listed twice.
+func (c *Config) ValidateLocales() error {
+	seen := make(map[string]bool)
+	for _, l := range c.Locales {
+		if !localeCodePattern.MatchString(l.Code) {
+			return &settingError{"locales", fmt.Sprintf("%q is not a language tag (e.g. en, fr or pt-BR)", l.Code)}
+		}
+		if seen[strings.ToLower(l.Code)] {
+			return &settingError{"locales", fmt.Sprintf("%q is listed more than once", l.Code)}
+		}
+		seen[strings.ToLower(l.Code)] = true
+	}
+	return nil
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -664,15 +1149,61 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse YAML
+	// Parse YAML, then check it against the schema before decoding so
+	// typos and wrong types are reported where they are
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	if err := checkSchema(configPath, &doc); err != nil {
+		return nil, err
+	}
+
 	config := DefaultConfig() // Start with defaults
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if doc.Kind != 0 {
+		if err := doc.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+		}
+	}
+
+	if err := config.checkSettings(configPath, &doc); err != nil {
+		return nil, err
+	}
+	if err := config.checkSiteMode(configPath, &doc); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// checkSiteMode checks that a multi-page site has a home page: site.home must
+// name an existing file, and without it index.md must exist next to the
+// config file.
+func (c *Config) checkSiteMode(configPath string, doc *yaml.Node) error {
+	if !c.IsSiteMode() {
+		return nil
+	}
+	dir := filepath.Dir(configPath)
+
+	if c.Site == nil || c.Site.Home == "" {
+		if _, err := os.Stat(filepath.Join(dir, "index.md")); err == nil {
+			return nil
+		}
+		return &SchemaError{File: configPath, Key: "site.home",
+			Msg: `type "site" needs a home page: set site.home or add an index.md`}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, c.Site.Home)); err != nil {
+		e := &SchemaError{File: configPath, Key: "site.home",
+			Msg: fmt.Sprintf("home page %q not found", c.Site.Home)}
+		if node := findValue(doc, "site", "home"); node != nil {
+			e.Line, e.Column = node.Line, node.Column
+		}
+		return e
+	}
+	return nil
+}
+
 // LoadFromDir looks for tinkerdown.yaml, lmt.yaml, or livemdtools.yaml in the given directory
 // tinkerdown.yaml is checked first, then lmt.yaml (short form), then livemdtools.yaml (legacy)
 // If none is found, returns the default configuration
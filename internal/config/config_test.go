@@ -1,6 +1,7 @@
 package config
 
 import (
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -49,6 +50,97 @@ func TestSourceConfigGetTimeout(t *testing.T) {
 	}
 }
 
+func TestSourceConfigGetPollInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		poll     string
+		expected time.Duration
+	}{
+		{"unset disables polling", "", 0},
+		{"valid duration", "30s", 30 * time.Second},
+		{"invalid duration disables polling", "often", 0},
+		{"negative disables polling", "-5s", 0},
+		{"below minimum is raised", "100ms", time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := SourceConfig{Options: map[string]string{"poll": tt.poll}}
+			if got := cfg.GetPollInterval(); got != tt.expected {
+				t.Errorf("GetPollInterval() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSourceConfigGetUploadMaxSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxSize  string
+		expected int64
+	}{
+		{"unset defaults to 10MB", "", 10 << 20},
+		{"bytes", "2048", 2048},
+		{"kilobytes", "500KB", 500 << 10},
+		{"megabytes with space, lowercase", "5 mb", 5 << 20},
+		{"invalid defaults to 10MB", "big", 10 << 20},
+		{"zero defaults to 10MB", "0", 10 << 20},
+		{"overflow defaults to 10MB", "9999999999999GB", 10 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := SourceConfig{Options: map[string]string{"max_size": tt.maxSize}}
+			if got := cfg.GetUploadMaxSize(); got != tt.expected {
+				t.Errorf("GetUploadMaxSize() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServerConfigGetIdleTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  string
+		expected time.Duration
+	}{
+		{"empty defaults to 60s", "", 60 * time.Second},
+		{"valid duration", "2m", 2 * time.Minute},
+		{"invalid duration defaults to 60s", "soon", 60 * time.Second},
+		{"non-positive defaults to 60s", "0s", 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ServerConfig{IdleTimeout: tt.timeout}
+			if got := cfg.GetIdleTimeout(); got != tt.expected {
+				t.Errorf("GetIdleTimeout() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImagesConfigGetWidths(t *testing.T) {
+	tests := []struct {
+		name     string
+		widths   []int
+		expected []int
+	}{
+		{"empty uses defaults", nil, []int{480, 960, 1600}},
+		{"sorted and deduplicated", []int{1200, 640, 1200}, []int{640, 1200}},
+		{"only invalid uses defaults", []int{0, -100}, []int{480, 960, 1600}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ImagesConfig{Widths: tt.widths}
+			if got := cfg.GetWidths(); !slices.Equal(got, tt.expected) {
+				t.Errorf("GetWidths() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestSourceConfigGetRetryBaseDelay(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -479,3 +571,91 @@ func TestConfigValidateOutputs(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkdownConfigDefaults(t *testing.T) {
+	var cfg MarkdownConfig
+	if !cfg.IsFootnotesEnabled() || !cfg.IsStrikethroughEnabled() || !cfg.IsAutolinksEnabled() || !cfg.IsTaskListsEnabled() {
+		t.Error("all markdown extensions should be enabled by default")
+	}
+
+	off := false
+	cfg = MarkdownConfig{Footnotes: &off, Autolinks: &off}
+	if cfg.IsFootnotesEnabled() || cfg.IsAutolinksEnabled() {
+		t.Error("explicitly disabled extensions should report false")
+	}
+	if !cfg.IsStrikethroughEnabled() || !cfg.IsTaskListsEnabled() {
+		t.Error("unset extensions should stay enabled")
+	}
+}
+
+func TestConfigGetShortcuts(t *testing.T) {
+	cfg := &Config{Shortcuts: map[string]string{
+		"presentation": "p",
+		"help":         "none",
+		"filter":       "",
+		"bogus":        "x",
+	}}
+	keymap := cfg.GetShortcuts()
+
+	if keymap["presentation"] != "p" {
+		t.Errorf("presentation = %q, want remapped key p", keymap["presentation"])
+	}
+	if keymap["theme"] != DefaultShortcuts["theme"] {
+		t.Errorf("theme = %q, want default %q", keymap["theme"], DefaultShortcuts["theme"])
+	}
+	for _, action := range []string{"help", "filter", "bogus"} {
+		if _, ok := keymap[action]; ok {
+			t.Errorf("keymap should not contain %q", action)
+		}
+	}
+}
+
+func TestConfigValidateShortcuts(t *testing.T) {
+	tests := []struct {
+		name      string
+		shortcuts map[string]string
+		wantError string
+	}{
+		{name: "defaults"},
+		{name: "remap", shortcuts: map[string]string{"presentation": "p", "help": "none"}},
+		{name: "unknown action", shortcuts: map[string]string{"bogus": "s"}, wantError: "unknown action"},
+		{name: "collision", shortcuts: map[string]string{"presentation": "/"}, wantError: "already used"},
+		{name: "collision ignores case", shortcuts: map[string]string{"help": "ctrl+shift+d"}, wantError: "already used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Shortcuts: tt.shortcuts}).ValidateShortcuts()
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("ValidateShortcuts() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("ValidateShortcuts() error = %v, want containing %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStylingConfigGetScrollOffset(t *testing.T) {
+	tests := []struct {
+		offset string
+		want   string
+	}{
+		{"", ""},
+		{"80", "80px"},
+		{"80px", "80px"},
+		{" 5.5rem ", "5.5rem"},
+		{"10vh", "10vh"},
+		{"80px; color: red", ""},
+		{"-1rem", ""},
+	}
+	for _, tt := range tests {
+		got := StylingConfig{ScrollOffset: tt.offset}.GetScrollOffset()
+		if got != tt.want {
+			t.Errorf("GetScrollOffset(%q) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	input := strings.Join([]string{
+		"# Database",
+		"DB_URL=postgres://localhost/docs",
+		"export API_TOKEN = abc123 # from the dashboard",
+		"",
+		`QUOTED="two words # not a comment"`,
+		`ESCAPED="line1\nline2 \"quoted\""`,
+		`LITERAL='$HOME\n'`,
+		"EMPTY=",
+		"URL=https://example.com/#anchor",
+	}, "\n")
+
+	vars, err := ParseEnvFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseEnvFile() error = %v", err)
+	}
+	want := [][2]string{
+		{"DB_URL", "postgres://localhost/docs"},
+		{"API_TOKEN", "abc123"},
+		{"QUOTED", "two words # not a comment"},
+		{"ESCAPED", "line1\nline2 \"quoted\""},
+		{"LITERAL", `$HOME\n`},
+		{"EMPTY", ""},
+		{"URL", "https://example.com/#anchor"},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("ParseEnvFile() = %v, want %v", vars, want)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Errorf("var %d = %q, want %q", i, vars[i], want[i])
+		}
+	}
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"JUST_A_NAME", "line 1: expected KEY=VALUE"},
+		{"OK=1\n1BAD=x", "line 2: expected KEY=VALUE"},
+		{`TOKEN="s3cret`, "line 1: TOKEN: unterminated double quote"},
+		{"TOKEN='s3cret", "line 1: TOKEN: unterminated single quote"},
+	}
+	for _, tt := range tests {
+		_, err := ParseEnvFile(strings.NewReader(tt.input))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ParseEnvFile(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+		if err != nil && strings.Contains(err.Error(), "s3cret") {
+			t.Errorf("error %q reveals the value", err)
+		}
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("TINKERDOWN_TEST_NEW=from-file\nTINKERDOWN_TEST_SET=from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TINKERDOWN_TEST_SET", "from-env")
+	t.Cleanup(func() { os.Unsetenv("TINKERDOWN_TEST_NEW") })
+
+	set, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile() error = %v", err)
+	}
+	if len(set) != 1 || set[0] != "TINKERDOWN_TEST_NEW" {
+		t.Errorf("LoadEnvFile() set = %v, want [TINKERDOWN_TEST_NEW]", set)
+	}
+	if got := os.Getenv("TINKERDOWN_TEST_NEW"); got != "from-file" {
+		t.Errorf("TINKERDOWN_TEST_NEW = %q, want from-file", got)
+	}
+	// Real environment variables win
+	if got := os.Getenv("TINKERDOWN_TEST_SET"); got != "from-env" {
+		t.Errorf("TINKERDOWN_TEST_SET = %q, want from-env", got)
+	}
+}
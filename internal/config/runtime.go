@@ -1,16 +1,21 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
 // RuntimeConfig stores configuration set at runtime via CLI flags.
 // These values are not persisted to config files.
 type RuntimeConfig struct {
-	mu        sync.RWMutex
-	operator  string
-	allowExec bool
+	mu            sync.RWMutex
+	operator      string
+	allowExec     bool
+	execAllowlist []string // nil allows any executable, empty allows none
 }
 
 var globalRuntime = &RuntimeConfig{}
@@ -30,6 +35,58 @@ func IsExecAllowed() bool {
 	return globalRuntime.allowExec
 }
 
+// SetExecAllowlist limits the executables exec sources and actions may run
+// to names, each a base name looked up on PATH ("git") or an absolute path
+// ("/usr/local/bin/deploy"). A nil list allows any executable; an empty one
+// allows none.
+func SetExecAllowlist(names []string) {
+	globalRuntime.mu.Lock()
+	defer globalRuntime.mu.Unlock()
+	if names == nil {
+		globalRuntime.execAllowlist = nil
+		return
+	}
+	globalRuntime.execAllowlist = append([]string{}, names...)
+}
+
+// CheckExecutable returns an error unless the exec allowlist permits
+// executable, the first word of a command run in dir. A base name entry
+// only matches the same bare name, which runs from PATH; an absolute path
+// entry matches the file the executable resolves to, so relative commands
+// like ./deploy.sh must be listed by absolute path.
+func CheckExecutable(executable, dir string) error {
+	globalRuntime.mu.RLock()
+	allowlist := globalRuntime.execAllowlist
+	globalRuntime.mu.RUnlock()
+	if allowlist == nil {
+		return nil
+	}
+
+	bare := !strings.ContainsAny(executable, `/\`)
+	var path string
+	switch {
+	case bare:
+		if found, err := exec.LookPath(executable); err == nil {
+			path, _ = filepath.Abs(found)
+		}
+	case filepath.IsAbs(executable):
+		path = filepath.Clean(executable)
+	default:
+		path = filepath.Join(dir, executable)
+	}
+
+	for _, entry := range allowlist {
+		if filepath.IsAbs(entry) {
+			if path != "" && filepath.Clean(entry) == path {
+				return nil
+			}
+		} else if bare && entry == executable {
+			return nil
+		}
+	}
+	return fmt.Errorf("executable %q is not in exec_allowlist", executable)
+}
+
 // SetOperator sets the operator identity for this session.
 // If empty, defaults to the current user from $USER environment variable.
 func SetOperator(op string) {
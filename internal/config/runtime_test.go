@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -62,3 +64,32 @@ func TestSetAllowExec(t *testing.T) {
 	SetAllowExec(false)
 	assert.False(t, IsExecAllowed())
 }
+
+func TestCheckExecutable(t *testing.T) {
+	defer SetExecAllowlist(nil)
+	siteDir := t.TempDir()
+	lsPath, err := exec.LookPath("ls")
+	if err != nil {
+		t.Skip("ls not on PATH")
+	}
+
+	// No allowlist: anything goes
+	SetExecAllowlist(nil)
+	assert.NoError(t, CheckExecutable("rm", siteDir))
+
+	SetExecAllowlist([]string{"git", lsPath, filepath.Join(siteDir, "scripts", "deploy.sh")})
+	for _, executable := range []string{"git", "ls", lsPath, "./scripts/deploy.sh", filepath.Join(siteDir, "scripts", "deploy.sh")} {
+		assert.NoError(t, CheckExecutable(executable, siteDir), executable)
+	}
+	// A base name entry doesn't cover a file of that name elsewhere
+	for _, executable := range []string{"rm", "./git", "/tmp/git", "./deploy.sh", "scripts/../other.sh"} {
+		err := CheckExecutable(executable, siteDir)
+		if assert.Error(t, err, executable) {
+			assert.Contains(t, err.Error(), "exec_allowlist")
+		}
+	}
+
+	// An empty allowlist allows nothing
+	SetExecAllowlist([]string{})
+	assert.Error(t, CheckExecutable("git", siteDir))
+}
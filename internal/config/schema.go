@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/suggest"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaError is a config value that doesn't fit the config schema: an
+// unknown key, a value of the wrong type or a missing required setting.
+type SchemaError struct {
+	File   string // Config file path
+	Line   int    // 1-indexed; 0 when the problem has no single location
+	Column int
+	Key    string // Dotted path to the value, e.g. "server.port"
+	Msg    string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", e.File, e.Key, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Key, e.Msg)
+}
+
+// settingError is a setting that fits the schema but can't be used, such as
+// an unknown preset or two shortcuts on one key.
+type settingError struct {
+	key string // Dotted path to the value, e.g. "styling.preset"
+	msg string
+}
+
+func (e *settingError) Error() string {
+	return e.key + ": " + e.msg
+}
+
+// checkSettings runs the checks the schema can't express and returns a
+// *SchemaError for each failing one, located at its value in doc.
+func (c *Config) checkSettings(file string, doc *yaml.Node) error {
+	var errs []error
+	for _, err := range []error{c.ValidateShortcuts(), c.Styling.ValidatePresets(), c.ValidateRobots(), c.ValidateLocales()} {
+		var se *settingError
+		if !errors.As(err, &se) {
+			continue
+		}
+		e := &SchemaError{File: file, Key: se.key, Msg: se.msg}
+		if node := findValue(doc, strings.Split(se.key, ".")...); node != nil {
+			e.Line, e.Column = node.Line, node.Column
+		}
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
+// checkSchema walks a parsed config document alongside the Config type and
+// returns a *SchemaError for each unknown key, suggesting the closest known
+// key when one is a likely typo, and for each value that can't be decoded
+// into its field's type. Multiple problems are joined with errors.Join.
+func checkSchema(file string, doc *yaml.Node) error {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+	var errs []error
+	checkNode(file, "", doc.Content[0], reflect.TypeOf(Config{}), &errs)
+	return errors.Join(errs...)
+}
+
+func checkNode(file, path string, node *yaml.Node, t reflect.Type, errs *[]error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fail := func(n *yaml.Node, key, msg string) {
+		*errs = append(*errs, &SchemaError{File: file, Line: n.Line, Column: n.Column, Key: key, Msg: msg})
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			fail(node, path, "expected a mapping of settings, got "+describeNode(node))
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := joinKey(path, keyNode.Value)
+			field, ok := fields[keyNode.Value]
+			if !ok {
+				fail(keyNode, key, unknownKeyMessage(keyNode.Value, fields))
+				continue
+			}
+			checkNode(file, key, valueNode, field.Type, errs)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			fail(node, path, "expected a mapping, got "+describeNode(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkNode(file, joinKey(path, node.Content[i].Value), node.Content[i+1], t.Elem(), errs)
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			fail(node, path, "expected a list, got "+describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			checkNode(file, fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), errs)
+		}
+
+	case reflect.Interface:
+		// Any value is accepted
+
+	default:
+		if node.Kind != yaml.ScalarNode {
+			fail(node, path, fmt.Sprintf("expected %s, got %s", describeKind(t), describeNode(node)))
+			return
+		}
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			fail(node, path, fmt.Sprintf("expected %s, got %q", describeKind(t), node.Value))
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of struct type t to their fields.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name) // yaml.v3's default key
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// unknownKeyMessage explains an unknown key, suggesting the closest known
+// key or listing them all.
+func unknownKeyMessage(key string, fields map[string]reflect.StructField) string {
+	known := make([]string, 0, len(fields))
+	for name := range fields {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	if s := suggest.Closest(key, known); s != "" {
+		return fmt.Sprintf("unknown key (did you mean %q?)", s)
+	}
+	return "unknown key (expected one of: " + strings.Join(known, ", ") + ")"
+}
+
+// findValue returns the value node at the given key path in a parsed
+// document, or nil if there is none.
+func findValue(doc *yaml.Node, keys ...string) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	node := doc.Content[0]
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describeKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	}
+	return t.String()
+}
+
+func describeNode(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", n.Value)
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		files     []string // Created next to the config file
+		wantError []string // Substrings of the error; none means Load succeeds
+	}{
+		{
+			name: "valid",
+			yaml: "title: Docs\nserver:\n  port: 9000\nsources:\n  tasks:\n    type: sqlite\n    table: tasks\n",
+		},
+		{
+			name:      "misspelled key",
+			yaml:      "titel: Docs\n",
+			wantError: []string{"tinkerdown.yaml:1:1: titel: unknown key", `did you mean "title"?`},
+		},
+		{
+			name:      "misspelled nested key",
+			yaml:      "sources:\n  tasks:\n    type: sqlite\n    tabel: tasks\n",
+			wantError: []string{"tinkerdown.yaml:4:5: sources.tasks.tabel", `did you mean "table"?`},
+		},
+		{
+			name:      "invalid type",
+			yaml:      "server:\n  port: abc\n",
+			wantError: []string{`tinkerdown.yaml:2:9: server.port: expected a whole number, got "abc"`},
+		},
+		{
+			name:      "scalar for a section",
+			yaml:      "server: 8080\n",
+			wantError: []string{`server: expected a mapping of settings, got "8080"`},
+		},
+		{
+			name:      "reports every problem",
+			yaml:      "titel: Docs\nserver:\n  port: abc\n",
+			wantError: []string{"titel: unknown key", "server.port: expected a whole number"},
+		},
+		{
+			name:      "site without home",
+			yaml:      "type: site\n",
+			wantError: []string{"site.home", "set site.home or add an index.md"},
+		},
+		{
+			name:  "site with index.md",
+			yaml:  "type: site\n",
+			files: []string{"index.md"},
+		},
+		{
+			name:      "site home missing",
+			yaml:      "type: site\nsite:\n  home: start.md\n",
+			wantError: []string{`tinkerdown.yaml:3:9: site.home: home page "start.md" not found`},
+		},
+		{
+			name:  "site home present",
+			yaml:  "type: site\nsite:\n  home: start.md\n",
+			files: []string{"start.md"},
+		},
+		{
+			name:      "unknown shortcut action",
+			yaml:      "shortcuts:\n  bogus: s\n",
+			wantError: []string{"tinkerdown.yaml:2:10: shortcuts.bogus: unknown action"},
+		},
+		{
+			name:      "shortcut collision",
+			yaml:      "shortcuts:\n  presentation: /\n",
+			wantError: []string{`tinkerdown.yaml:2:17: shortcuts.presentation: key "/" is already used by "search"`},
+		},
+		{
+			name:      "unknown preset",
+			yaml:      "styling:\n  preset: sepia\n",
+			wantError: []string{`tinkerdown.yaml:2:11: styling.preset: unknown preset "sepia"`},
+		},
+		{
+			name: "custom preset",
+			yaml: "styling:\n  preset: sepia\n  presets: [sepia]\n",
+		},
+		{
+			name:      "invalid preset name",
+			yaml:      "styling:\n  presets: [Bad Name]\n",
+			wantError: []string{`styling.presets: "Bad Name" is not a valid preset name`},
+		},
+		{
+			name:      "invalid robots",
+			yaml:      "robots: block\n",
+			wantError: []string{`tinkerdown.yaml:1:9: robots: "block" must be allow or disallow`},
+		},
+		{
+			name:      "invalid locale code",
+			yaml:      "locales:\n  - code: french\n",
+			wantError: []string{`locales: "french" is not a language tag`},
+		},
+		{
+			name:      "duplicate locale",
+			yaml:      "locales:\n  - code: fr\n  - code: FR\n",
+			wantError: []string{`locales: "FR" is listed more than once`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "tinkerdown.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("# Page\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := Load(path)
+			if len(tt.wantError) == 0 {
+				if err != nil {
+					t.Errorf("Load() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Load() error = nil, want %q", tt.wantError)
+			}
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Errorf("Load() error = %T, want a *SchemaError", err)
+			}
+			for _, want := range tt.wantError {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %v, want containing %q", err, want)
+				}
+			}
+		})
+	}
+}
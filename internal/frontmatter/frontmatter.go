@@ -0,0 +1,27 @@
+// Package frontmatter splits the YAML frontmatter from the top of a page. The
+// page parser and the frontmatter source both use it, so they agree on what
+// a page's frontmatter is.
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrUnclosed is returned for content that opens frontmatter but never
+// closes it.
+var ErrUnclosed = errors.New("unclosed frontmatter")
+
+// Split returns the YAML between the opening "---" line and the closing one,
+// and the content after it. ok is false, and body is content, when content
+// doesn't start with frontmatter.
+func Split(content []byte) (yaml, body []byte, ok bool, err error) {
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return nil, content, false, nil
+	}
+	end := bytes.Index(content[4:], []byte("\n---\n"))
+	if end == -1 {
+		return nil, nil, false, ErrUnclosed
+	}
+	return content[4 : 4+end], content[4+end+5:], true, nil
+}
@@ -0,0 +1,33 @@
+package frontmatter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantYAML string
+		wantBody string
+		wantOK   bool
+		wantErr  error
+	}{
+		{"frontmatter", "---\ntitle: A\n---\n# A\n", "title: A", "# A\n", true, nil},
+		{"empty frontmatter", "---\n\n---\nBody", "", "Body", true, nil},
+		{"none", "# A\n", "", "# A\n", false, nil},
+		{"unclosed", "---\ntitle: A\n# A\n", "", "", false, ErrUnclosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml, body, ok, err := Split([]byte(tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Split() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(yaml) != tt.wantYAML || string(body) != tt.wantBody || ok != tt.wantOK {
+				t.Errorf("Split() = %q, %q, %v; want %q, %q, %v", yaml, body, ok, tt.wantYAML, tt.wantBody, tt.wantOK)
+			}
+		})
+	}
+}
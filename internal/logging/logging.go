@@ -0,0 +1,148 @@
+// Package logging provides the leveled, per-component logger used by the server.
+//
+// Messages go through the standard log package, so log.SetOutput and
+// log.SetFlags still apply. Each line is prefixed with its component, as in
+// "[Watch] File changed: index.md"; warnings and errors also carry their
+// level, colored when writing to a terminal unless NO_COLOR is set.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Level is the severity of a log message.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// String returns the level's name as accepted by ParseLevel.
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int32(l))
+}
+
+// ParseLevel parses "debug", "info", "warn" (or "warning") or "error".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", s)
+}
+
+var minLevel atomic.Int32
+
+func init() {
+	minLevel.Store(int32(LevelInfo))
+}
+
+// SetLevel sets the lowest level that is logged. The default is LevelInfo.
+func SetLevel(l Level) {
+	minLevel.Store(int32(l))
+}
+
+// Enabled reports whether messages at level l are logged.
+func Enabled(l Level) bool {
+	return int32(l) >= minLevel.Load()
+}
+
+// Logger logs messages for one component of the server.
+type Logger struct {
+	component string
+}
+
+// New returns a logger whose messages are tagged with component.
+func New(component string) *Logger {
+	return &Logger{component: component}
+}
+
+// Debugf logs a message that is only useful when tracking down a problem.
+func (l *Logger) Debugf(format string, args ...any) {
+	l.logf(LevelDebug, format, args...)
+}
+
+// Infof logs a routine message.
+func (l *Logger) Infof(format string, args ...any) {
+	l.logf(LevelInfo, format, args...)
+}
+
+// Warnf logs a problem the server recovered from.
+func (l *Logger) Warnf(format string, args ...any) {
+	l.logf(LevelWarn, format, args...)
+}
+
+// Errorf logs a failed operation.
+func (l *Logger) Errorf(format string, args ...any) {
+	l.logf(LevelError, format, args...)
+}
+
+func (l *Logger) logf(level Level, format string, args ...any) {
+	if !Enabled(level) {
+		return
+	}
+	var b strings.Builder
+	if level != LevelInfo {
+		b.WriteString(levelTag(level))
+		b.WriteByte(' ')
+	}
+	if l.component != "" {
+		b.WriteString("[" + l.component + "] ")
+	}
+	fmt.Fprintf(&b, format, args...)
+	log.Output(3, b.String())
+}
+
+// levelColors are the ANSI colors of the level tags.
+var levelColors = map[Level]string{
+	LevelDebug: "\033[90m",
+	LevelWarn:  "\033[33m",
+	LevelError: "\033[31m",
+}
+
+// levelTag returns the upper-case level name, colored when the standard
+// logger writes to a terminal and NO_COLOR is unset.
+func levelTag(level Level) string {
+	tag := strings.ToUpper(level.String())
+	if useColor() {
+		return levelColors[level] + tag + "\033[0m"
+	}
+	return tag
+}
+
+func useColor() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := log.Writer().(*os.File)
+	return ok && IsTerminal(f)
+}
+
+// IsTerminal reports whether f is a terminal rather than a file or pipe.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// captureLog runs fn with the standard logger writing to a buffer.
+func captureLog(t *testing.T, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	origWriter, origFlags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(origWriter)
+		log.SetFlags(origFlags)
+		SetLevel(LevelInfo)
+	})
+	fn()
+	return buf.String()
+}
+
+func TestLoggerLevels(t *testing.T) {
+	l := New("Watch")
+
+	out := captureLog(t, func() {
+		l.Debugf("hidden %d", 1)
+		l.Infof("File changed: %s", "index.md")
+		l.Warnf("slow reload")
+		l.Errorf("reload failed: %v", "boom")
+	})
+	want := "[Watch] File changed: index.md\nWARN [Watch] slow reload\nERROR [Watch] reload failed: boom\n"
+	if out != want {
+		t.Errorf("default level output = %q, want %q", out, want)
+	}
+
+	out = captureLog(t, func() {
+		SetLevel(LevelDebug)
+		l.Debugf("shown %d", 2)
+		SetLevel(LevelError)
+		l.Warnf("hidden")
+		l.Errorf("shown")
+	})
+	if want := "DEBUG [Watch] shown 2\nERROR [Watch] shown\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"debug":   LevelDebug,
+		"INFO":    LevelInfo,
+		"warn":    LevelWarn,
+		"warning": LevelWarn,
+		" error ": LevelError,
+	}
+	for in, want := range tests {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil || !strings.Contains(err.Error(), `"verbose"`) {
+		t.Errorf("ParseLevel(verbose) error = %v, want invalid level", err)
+	}
+}
+
+func TestLevelTagNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if got := levelTag(LevelWarn); got != "WARN" {
+		t.Errorf("levelTag() = %q, want plain WARN with NO_COLOR", got)
+	}
+}
@@ -0,0 +1,199 @@
+// Package metrics collects Prometheus metrics of the server: HTTP requests
+// by route, open WebSocket connections, source fetches and block template
+// builds.
+//
+// Metrics are off unless Enable is called, as `tinkerdown serve --metrics`
+// does; while off, the Observe functions return after a single atomic load.
+// They're kept in their own registry, served by Handler, rather than the
+// Prometheus default one.
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where the metrics are served.
+const Path = "/metrics"
+
+var enabled atomic.Bool
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tinkerdown_http_requests_total",
+		Help: "HTTP requests served, by route and status code.",
+	}, []string{"route", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tinkerdown_http_request_duration_seconds",
+		Help:    "Time to serve HTTP requests, by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route"})
+
+	websocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tinkerdown_websocket_connections",
+		Help: "Open WebSocket connections of interactive pages.",
+	})
+
+	sourceFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tinkerdown_source_fetch_duration_seconds",
+		Help:    "Time to fetch data sources, by source.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"source"})
+
+	sourceFetchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tinkerdown_source_fetch_errors_total",
+		Help: "Failed data source fetches, by source.",
+	}, []string{"source"})
+
+	blockCompileDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "tinkerdown_block_compile_duration_seconds",
+		Help:    "Time to build the templates of interactive blocks, not counting those from the template cache.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		websocketConnections,
+		sourceFetchDuration,
+		sourceFetchErrors,
+		blockCompileDuration,
+	)
+}
+
+// Enable starts collecting metrics.
+func Enable() {
+	enabled.Store(true)
+}
+
+// Disable stops collecting metrics. Those already collected are kept.
+func Disable() {
+	enabled.Store(false)
+}
+
+// Enabled reports whether metrics are being collected.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a request to route answered with code after d.
+// The route must come from a small set, such as page patterns, never the
+// raw request path. WebSocket upgrades are counted without their duration,
+// which is how long the connection stayed open.
+func ObserveRequest(route string, code int, d time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	httpRequests.WithLabelValues(route, strconv.Itoa(code)).Inc()
+	if code != http.StatusSwitchingProtocols {
+		httpDuration.WithLabelValues(route).Observe(d.Seconds())
+	}
+}
+
+// SetWebSocketConnections records the number of open WebSocket connections.
+func SetWebSocketConnections(n int) {
+	if !enabled.Load() {
+		return
+	}
+	websocketConnections.Set(float64(n))
+}
+
+// ObserveSourceFetch records a fetch of source that took d, and failed
+// when err isn't nil.
+func ObserveSourceFetch(source string, d time.Duration, err error) {
+	if !enabled.Load() {
+		return
+	}
+	sourceFetchDuration.WithLabelValues(source).Observe(d.Seconds())
+	if err != nil {
+		sourceFetchErrors.WithLabelValues(source).Inc()
+	}
+}
+
+// ObserveBlockCompile records a block template build that took d.
+func ObserveBlockCompile(d time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	blockCompileDuration.Observe(d.Seconds())
+}
+
+// StatusRecorder is a ResponseWriter that remembers the status code written
+// through it. It passes on flushes and hijacks, so it can wrap any handler.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// NewStatusRecorder wraps w.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+// Status returns the status code written, or 200, which net/http sends for
+// handlers that write none.
+func (r *StatusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *StatusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (r *StatusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (r *StatusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, for WebSocket upgrades.
+func (r *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("metrics: response writer doesn't support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (r *StatusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
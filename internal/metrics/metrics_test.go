@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveWhileDisabled(t *testing.T) {
+	Disable()
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("/off", "200"))
+	ObserveRequest("/off", http.StatusOK, time.Millisecond)
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("/off", "200")); got != before {
+		t.Errorf("requests while disabled = %v, want %v", got, before)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	Enable()
+	defer Disable()
+
+	ObserveRequest("/docs", http.StatusNotFound, time.Millisecond)
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("/docs", "404")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+
+	ObserveSourceFetch("tasks", time.Millisecond, nil)
+	ObserveSourceFetch("tasks", time.Millisecond, errors.New("timeout"))
+	if got := testutil.ToFloat64(sourceFetchErrors.WithLabelValues("tasks")); got != 1 {
+		t.Errorf("fetch errors = %v, want 1", got)
+	}
+
+	SetWebSocketConnections(3)
+	if got := testutil.ToFloat64(websocketConnections); got != 3 {
+		t.Errorf("connections = %v, want 3", got)
+	}
+
+	ObserveBlockCompile(time.Millisecond)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	for _, want := range []string{
+		`tinkerdown_source_fetch_duration_seconds_count{source="tasks"} 2`,
+		`tinkerdown_block_compile_duration_seconds_count 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+}
+
+func TestStatusRecorder(t *testing.T) {
+	rec := NewStatusRecorder(httptest.NewRecorder())
+	if rec.Status() != http.StatusOK {
+		t.Errorf("Status() before a write = %d, want 200", rec.Status())
+	}
+	rec.WriteHeader(http.StatusTeapot)
+	rec.WriteHeader(http.StatusOK)
+	if rec.Status() != http.StatusTeapot {
+		t.Errorf("Status() = %d, want the first code written", rec.Status())
+	}
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Error("Hijack() of a writer that can't be hijacked should fail")
+	}
+}
@@ -3,6 +3,7 @@ package runtime
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,11 +18,12 @@ import (
 	"github.com/livetemplate/tinkerdown/internal/source"
 )
 
-// buildCommandString rebuilds the command string from executable and current arg values
+// buildCommandString rebuilds the command string from executable and current arg values.
+// The string is for display, so secret values are masked.
 func buildCommandString(origCmd string, args []Arg) string {
 	// Get executable from original command
-	parts := strings.Fields(origCmd)
-	if len(parts) == 0 {
+	parts, err := source.SplitCommand(origCmd)
+	if err != nil || len(parts) == 0 {
 		return origCmd
 	}
 	executable := parts[0]
@@ -29,9 +31,13 @@ func buildCommandString(origCmd string, args []Arg) string {
 	// Build new command with current arg values
 	cmdParts := []string{executable}
 	for _, arg := range args {
-		cmdParts = append(cmdParts, "--"+arg.Name, arg.Value)
+		value := arg.Value
+		if arg.Secret {
+			value = redactedValue
+		}
+		cmdParts = append(cmdParts, "--"+arg.Name, value)
 	}
-	return strings.Join(cmdParts, " ")
+	return source.JoinCommand(cmdParts)
 }
 
 // runExec handles the Run action for exec sources
@@ -50,6 +56,7 @@ func (s *GenericState) runExec(data map[string]interface{}) error {
 	ctx := context.Background()
 	var result []map[string]interface{}
 	var err error
+	var argsMap map[string]string
 
 	// Check if form data was submitted
 	if len(data) > 0 {
@@ -66,14 +73,19 @@ func (s *GenericState) runExec(data map[string]interface{}) error {
 		}
 
 		// Build args map from submitted data
-		argsMap := make(map[string]string)
+		argsMap = make(map[string]string)
 		for k, v := range data {
 			argsMap[k] = fmt.Sprintf("%v", v)
 		}
 
 		// Update the Command string to show current argument values
 		s.Command = buildCommandString(s.sourceCfg.Cmd, s.Args)
+	}
 
+	if execSrc.Streams() {
+		return s.startStream(execSrc, argsMap)
+	}
+	if argsMap != nil {
 		// Execute with custom arguments
 		result, err = execSrc.FetchWithArgs(ctx, argsMap)
 	} else {
@@ -88,12 +100,157 @@ func (s *GenericState) runExec(data map[string]interface{}) error {
 	}
 
 	s.Data = result
+	s.updateCounts()
 	s.Status = "success"
 	s.Error = ""
+	s.noteTruncation()
+	if s.elementType == "chart" {
+		s.buildChart()
+	}
+	if s.elementType == "stat" {
+		card := NewStatCard(s.Data, s.statOpts)
+		s.Stat = &card
+	}
 	return nil
 }
 
-// handleWriteAction handles Add, Toggle, Delete, Update actions for writable sources
+// truncatedNotice is the Notice of an exec source whose output was cut off.
+const truncatedNotice = "Output was longer than max_output and has been cut off"
+
+// noteTruncation sets Notice when the exec source's last run printed more
+// than its max_output, and clears it once a run fits.
+func (s *GenericState) noteTruncation() {
+	execSrc, ok := s.source.(*source.ExecSource)
+	if !ok {
+		return
+	}
+	if execSrc.OutputTruncated() {
+		s.Notice = truncatedNotice
+	} else if s.Notice == truncatedNotice {
+		s.Notice = ""
+	}
+}
+
+// maxStreamOutput bounds the Output a streaming exec source keeps; the
+// oldest lines are dropped first.
+const maxStreamOutput = 1 << 20
+
+// streamUpdateInterval is how often a streaming exec source re-renders its
+// block while output arrives.
+const streamUpdateInterval = 100 * time.Millisecond
+
+// startStream runs a streaming exec source, appending each line it prints
+// to Output. With an onUpdate function the command runs in the background
+// and the block re-renders as output arrives; startStream returns at once
+// with Status "running". CancelRun or Close stops the command. Without
+// onUpdate, startStream waits for the command to finish.
+func (s *GenericState) startStream(execSrc *source.ExecSource, args map[string]string) error {
+	if s.stopStream != nil {
+		return fmt.Errorf("command is already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopStream = cancel
+	s.Output = ""
+	start := time.Now()
+
+	if s.onUpdate == nil {
+		err := execSrc.Stream(ctx, args, s.appendOutput)
+		s.finishStream(err, start)
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- execSrc.Stream(ctx, args, func(line string) {
+			s.mu.Lock()
+			s.appendOutput(line)
+			s.mu.Unlock()
+		})
+	}()
+	go func() {
+		ticker := time.NewTicker(streamUpdateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.mu.Lock()
+				dirty := s.streamDirty
+				s.streamDirty = false
+				update := s.onUpdate
+				s.mu.Unlock()
+				if dirty && update != nil {
+					update()
+				}
+			case err := <-done:
+				s.mu.Lock()
+				s.finishStream(err, start)
+				update := s.onUpdate
+				s.mu.Unlock()
+				if update != nil {
+					update()
+				}
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// appendOutput adds a line of streamed output. The caller holds s.mu.
+func (s *GenericState) appendOutput(line string) {
+	s.Output += line + "\n"
+	if len(s.Output) > maxStreamOutput {
+		trimmed := s.Output[len(s.Output)-maxStreamOutput:]
+		if i := strings.IndexByte(trimmed, '\n'); i >= 0 {
+			trimmed = trimmed[i+1:]
+		}
+		s.Output = trimmed
+	}
+	s.streamDirty = true
+}
+
+// finishStream records how a streaming run ended. The caller holds s.mu.
+func (s *GenericState) finishStream(err error, start time.Time) {
+	s.stopStream()
+	s.stopStream = nil
+	s.streamDirty = false
+	s.Duration = time.Since(start).Milliseconds()
+	switch {
+	case errors.Is(err, context.Canceled):
+		s.Status = "error"
+		s.Error = "command was cancelled"
+	case err != nil:
+		s.Status = "error"
+		s.Error = err.Error()
+	default:
+		s.Status = "success"
+		s.Error = ""
+	}
+	s.noteTruncation()
+}
+
+// requestConfirm holds a Run of a confirm exec source until it is confirmed.
+// The block shows Status "confirm" and ConfirmMessage meanwhile.
+func (s *GenericState) requestConfirm(data map[string]interface{}) error {
+	if s.sourceType != "exec" {
+		return fmt.Errorf("Run action only valid for exec sources")
+	}
+	s.pendingRun = data
+	s.Status = "confirm"
+	return nil
+}
+
+// confirmRun runs the exec command held by requestConfirm.
+func (s *GenericState) confirmRun() error {
+	if s.Status != "confirm" {
+		return fmt.Errorf("no run is waiting for confirmation")
+	}
+	data := s.pendingRun
+	s.pendingRun = nil
+	return s.runExec(data)
+}
+
+// handleWriteAction handles Add, Toggle, Delete, Update and Import actions for writable sources
 func (s *GenericState) handleWriteAction(action string, data map[string]interface{}) error {
 	writable, ok := s.source.(source.WritableSource)
 	if !ok {
@@ -104,18 +261,38 @@ func (s *GenericState) handleWriteAction(action string, data map[string]interfac
 		return fmt.Errorf("source %q is read-only", s.sourceName)
 	}
 
+	actionLower := strings.ToLower(action)
+
 	// Resolve template expressions in action data (e.g., {{timestamp}}, {{today}}, {{.operator}})
-	// This enables auto-filling timestamps and operator identity on form submission
-	resolver := NewDefaultResolver(s.getOperator())
-	resolvedData, err := resolver.ResolveMap(data)
-	if err != nil {
-		s.Error = err.Error()
-		return fmt.Errorf("failed to resolve template expressions: %w", err)
+	// This enables auto-filling timestamps and operator identity on form submission.
+	// Imported CSV is data, so it's written as-is.
+	resolvedData := data
+	if actionLower != "import" {
+		resolver := NewDefaultResolver(s.getOperator())
+		var err error
+		resolvedData, err = resolver.ResolveMap(data)
+		if err != nil {
+			s.Error = err.Error()
+			return fmt.Errorf("failed to resolve template expressions: %w", err)
+		}
+	}
+
+	if actionLower == "add" || actionLower == "update" {
+		if err := s.checkFields(actionLower, resolvedData); err != nil {
+			return err
+		}
+	}
+	if actionLower == "import" {
+		text, _ := resolvedData["csv"].(string)
+		if err := CheckImport(s.sourceName, s.sourceCfg.Fields, text); err != nil {
+			s.Error = err.Error()
+			return err
+		}
 	}
 
 	// Delegate to the source's WriteItem
 	ctx := context.Background()
-	if err := writable.WriteItem(ctx, strings.ToLower(action), resolvedData); err != nil {
+	if err := writable.WriteItem(ctx, actionLower, resolvedData); err != nil {
 		s.Error = err.Error()
 		return err
 	}
@@ -124,6 +301,53 @@ func (s *GenericState) handleWriteAction(action string, data map[string]interfac
 	return s.refresh()
 }
 
+// handleBulkAction handles BulkDelete and BulkToggle, applying delete or
+// toggle to every item in the comma-separated "ids" field in one write. IDs
+// that no longer match an item are skipped and counted in Notice.
+func (s *GenericState) handleBulkAction(action string, data map[string]interface{}) error {
+	bulk, ok := s.source.(source.BulkWritableSource)
+	if !ok {
+		return fmt.Errorf("source %q does not support bulk actions", s.sourceName)
+	}
+	if bulk.IsReadonly() {
+		return fmt.Errorf("source %q is read-only", s.sourceName)
+	}
+
+	list, _ := data["ids"].(string)
+	ids := parseIDList(list)
+	if len(ids) == 0 {
+		s.Error = "No items selected"
+		return fmt.Errorf("bulk %s requires an 'ids' field", action)
+	}
+
+	missing, err := bulk.WriteItems(context.Background(), action, ids)
+	if err != nil {
+		s.Error = err.Error()
+		return err
+	}
+	if len(missing) > 0 {
+		s.Notice = fmt.Sprintf("Skipped %d of %d items that no longer exist", len(missing), len(ids))
+	}
+	s.EditingID = ""
+
+	return s.refresh()
+}
+
+// parseIDList splits a comma-separated list of IDs, dropping blanks and
+// repeats.
+func parseIDList(list string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(list, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // getOperator returns the current operator identity from config.
 func (s *GenericState) getOperator() string {
 	return config.GetOperator()
@@ -165,11 +389,17 @@ func (s *GenericState) handleDatatableAction(action string, data map[string]inte
 		return s.sortData(column)
 
 	case "nextpage":
-		// For now, pagination is handled client-side or via datatable component
-		// This is a placeholder for future implementation
+		// Datatable pagination is handled by the datatable component; only
+		// sources with options["page_size"] paginate on the server
+		if s.pageSize > 0 && s.Page < s.TotalPages {
+			s.Page++
+		}
 		return nil
 
 	case "prevpage":
+		if s.pageSize > 0 && s.Page > 1 {
+			s.Page--
+		}
 		return nil
 
 	default:
@@ -188,26 +418,30 @@ func (s *GenericState) sortData(column string) error {
 		return fmt.Errorf("column %q not found in data", column)
 	}
 
-	// Toggle between ascending and descending based on current order
-	ascending := true
-	if len(s.Data) >= 2 {
-		first := s.Data[0][column]
-		last := s.Data[len(s.Data)-1][column]
-		ascending = compareValues(first, last) > 0 // If already desc, make it asc
+	// Sorting by the same column again reverses the order
+	s.sortDesc = s.sortColumn == column && !s.sortDesc
+	s.sortColumn = column
+	s.applySort()
+	if s.Table != nil {
+		s.Table = s.buildDataTable()
 	}
+	return nil
+}
 
-	// Use sort.Slice for O(n log n) performance
-	sort.Slice(s.Data, func(i, j int) bool {
-		a := s.Data[i][column]
-		b := s.Data[j][column]
-		cmp := compareValues(a, b)
-		if ascending {
-			return cmp < 0
+// applySort sorts Data by sortColumn, if any. The sort is stable, so rows
+// with equal values keep the source's order.
+func (s *GenericState) applySort() {
+	if s.sortColumn == "" {
+		return
+	}
+	column, desc := s.sortColumn, s.sortDesc
+	sort.SliceStable(s.Data, func(i, j int) bool {
+		cmp := compareValues(s.Data[i][column], s.Data[j][column])
+		if desc {
+			return cmp > 0
 		}
-		return cmp > 0
+		return cmp < 0
 	})
-
-	return nil
 }
 
 // compareValues compares two interface{} values for sorting
@@ -539,6 +773,11 @@ func (s *GenericState) executeExecAction(action *config.Action, data map[string]
 	if err := sanitizeExecCommand(cmdStr); err != nil {
 		return err
 	}
+	if fields := strings.Fields(cmdStr); len(fields) > 0 {
+		if err := config.CheckExecutable(fields[0], s.siteDir); err != nil {
+			return err
+		}
+	}
 
 	// Create command with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -0,0 +1,256 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ChartOptions customizes one chart, from the page frontmatter's charts map.
+type ChartOptions struct {
+	Colors     []string `yaml:"colors,omitempty" json:"colors,omitempty"`
+	Stacked    bool     `yaml:"stacked,omitempty" json:"stacked,omitempty"`
+	Horizontal bool     `yaml:"horizontal,omitempty" json:"horizontal,omitempty"`
+	Legend     *bool    `yaml:"legend,omitempty" json:"legend,omitempty"`
+}
+
+// ChartTable is the text of a markdown table a chart is drawn from: its
+// header cells, then its body rows.
+type ChartTable struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// ChartConfig is the configuration passed to new Chart() in the browser.
+// Colors that follow the page theme (title, legend and axes) are left to the
+// client, which sets them when it draws the chart and when the theme changes.
+type ChartConfig struct {
+	Type    string             `json:"type"`
+	Data    ChartData          `json:"data"`
+	Options ChartConfigOptions `json:"options"`
+}
+
+// ChartData holds a chart's labels and one dataset per series.
+type ChartData struct {
+	Labels   []string       `json:"labels"`
+	Datasets []ChartDataset `json:"datasets"`
+}
+
+// ChartDataset is one series of a chart. Pie and doughnut charts color each
+// value, so their colors are lists; other charts color the whole series.
+type ChartDataset struct {
+	Label           string    `json:"label"`
+	Data            []float64 `json:"data"`
+	BackgroundColor any       `json:"backgroundColor"`
+	BorderColor     any       `json:"borderColor"`
+	BorderWidth     int       `json:"borderWidth"`
+}
+
+// ChartConfigOptions are Chart.js chart options.
+type ChartConfigOptions struct {
+	Responsive          bool                  `json:"responsive"`
+	MaintainAspectRatio bool                  `json:"maintainAspectRatio"`
+	IndexAxis           string                `json:"indexAxis,omitempty"` // "y" for horizontal bars
+	Plugins             ChartPlugins          `json:"plugins"`
+	Scales              map[string]ChartScale `json:"scales,omitempty"` // Not for pie and doughnut
+}
+
+// ChartPlugins configures the title and legend of a chart.
+type ChartPlugins struct {
+	Title  ChartTitle  `json:"title"`
+	Legend ChartLegend `json:"legend"`
+}
+
+// ChartTitle is the title drawn above a chart.
+type ChartTitle struct {
+	Display bool   `json:"display"`
+	Text    string `json:"text"`
+}
+
+// ChartLegend is the legend of a chart's series.
+type ChartLegend struct {
+	Display bool `json:"display"`
+}
+
+// ChartScale is an axis of a bar or line chart.
+type ChartScale struct {
+	Stacked bool `json:"stacked"`
+}
+
+// chartTypes are the types a {chart:type} annotation can ask for. An empty
+// type, or auto, picks one from the shape of the data.
+var chartTypes = map[string]bool{
+	"bar": true, "line": true, "pie": true, "doughnut": true, "auto": true, "": true,
+}
+
+// defaultChartColors color series (or pie slices) in turn.
+var defaultChartColors = []string{
+	"rgba(54, 162, 235, 0.8)",
+	"rgba(255, 99, 132, 0.8)",
+	"rgba(75, 192, 192, 0.8)",
+	"rgba(255, 205, 86, 0.8)",
+	"rgba(153, 102, 255, 0.8)",
+	"rgba(255, 159, 64, 0.8)",
+	"rgba(201, 203, 207, 0.8)",
+}
+
+// rgbaAlphaPattern matches the alpha of an rgba() color.
+var rgbaAlphaPattern = regexp.MustCompile(`[\d.]+\)$`)
+
+// errNoChartData is returned for tables without a label column and at least
+// one numeric column.
+var errNoChartData = errors.New("table has no numeric columns to chart")
+
+// errNoChartFields is returned for source charts without an lvt-y field.
+var errNoChartFields = errors.New("chart has no lvt-y field to plot")
+
+// IsChartType reports whether t can follow chart: in a heading annotation.
+func IsChartType(t string) bool {
+	return chartTypes[t]
+}
+
+// NewChartConfig builds the Chart.js configuration for a chart of table.
+// Labels come from the first column that isn't all numbers (the first column
+// when every one is), and each numeric column is a dataset. An empty or auto
+// chartType is a pie for a single series of up to 8 values, or else a bar
+// chart. It returns an error when there's nothing to chart.
+func NewChartConfig(chartType, title string, table ChartTable, opts ChartOptions) (*ChartConfig, error) {
+	if len(table.Headers) < 2 || len(table.Rows) == 0 {
+		return nil, errNoChartData
+	}
+
+	// Find the label column and the numeric data columns
+	labelCol := -1
+	var dataCols []int
+	for col := range table.Headers {
+		if isNumericColumn(table.Rows, col) {
+			dataCols = append(dataCols, col)
+		} else if labelCol < 0 {
+			labelCol = col
+		}
+	}
+	if labelCol < 0 && len(dataCols) > 0 {
+		labelCol, dataCols = dataCols[0], dataCols[1:] // All numeric
+	}
+	if len(dataCols) == 0 {
+		return nil, errNoChartData
+	}
+
+	labels := make([]string, len(table.Rows))
+	for r, row := range table.Rows {
+		if labelCol < len(row) {
+			labels[r] = strings.TrimSpace(row[labelCol])
+		}
+	}
+	series := make([]ChartDataset, len(dataCols))
+	for i, col := range dataCols {
+		series[i] = ChartDataset{Label: table.Headers[col], Data: make([]float64, len(table.Rows))}
+		for r, row := range table.Rows {
+			if col < len(row) {
+				series[i].Data[r], _ = strconv.ParseFloat(strings.TrimSpace(row[col]), 64)
+			}
+		}
+	}
+	return buildChartConfig(chartType, title, labels, series, opts), nil
+}
+
+// NewSourceChartConfig builds the Chart.js configuration for a chart of
+// source rows, for a <canvas lvt-source> element. Labels come from the x
+// field and each y field is a dataset; values that aren't numbers chart as
+// zero. Rows may be empty, so a chart can wait for its source's data. It
+// returns an error when there's no y field.
+func NewSourceChartConfig(chartType string, rows []map[string]interface{}, x string, ys []string, opts ChartOptions) (*ChartConfig, error) {
+	if len(ys) == 0 {
+		return nil, errNoChartFields
+	}
+	labels := make([]string, len(rows))
+	for r, row := range rows {
+		if val := getFieldValue(row, x); val != nil {
+			labels[r] = fmt.Sprint(val)
+		}
+	}
+	series := make([]ChartDataset, len(ys))
+	for i, y := range ys {
+		series[i] = ChartDataset{Label: columnLabel(y), Data: make([]float64, len(rows))}
+		for r, row := range rows {
+			series[i].Data[r], _ = tryFloat64(getFieldValue(row, y))
+		}
+	}
+	return buildChartConfig(chartType, "", labels, series, opts), nil
+}
+
+// buildChartConfig colors series, given their labels and data, and sets the
+// options for chartType, picking one first when it's empty or auto.
+func buildChartConfig(chartType, title string, labels []string, series []ChartDataset, opts ChartOptions) *ChartConfig {
+	if chartType == "" || chartType == "auto" {
+		if len(series) == 1 && len(labels) <= 8 {
+			chartType = "pie"
+		} else {
+			chartType = "bar"
+		}
+	}
+	pie := chartType == "pie" || chartType == "doughnut"
+
+	colors := defaultChartColors
+	if len(opts.Colors) > 0 {
+		colors = opts.Colors
+	}
+	for i := range series {
+		ds := &series[i]
+		ds.BorderWidth = 1
+		if pie {
+			background := make([]string, len(ds.Data))
+			border := make([]string, len(ds.Data))
+			for j := range ds.Data {
+				background[j] = colors[j%len(colors)]
+				border[j] = chartBorderColor(background[j])
+			}
+			ds.BackgroundColor, ds.BorderColor = background, border
+		} else {
+			ds.BackgroundColor = colors[i%len(colors)]
+			ds.BorderColor = chartBorderColor(colors[i%len(colors)])
+		}
+	}
+
+	cfg := &ChartConfig{
+		Type: chartType,
+		Data: ChartData{Labels: labels, Datasets: series},
+		Options: ChartConfigOptions{
+			Responsive:          true,
+			MaintainAspectRatio: true,
+			Plugins: ChartPlugins{
+				Title:  ChartTitle{Display: title != "", Text: title},
+				Legend: ChartLegend{Display: opts.Legend == nil || *opts.Legend},
+			},
+		},
+	}
+	if !pie {
+		cfg.Options.Scales = map[string]ChartScale{"x": {Stacked: opts.Stacked}, "y": {Stacked: opts.Stacked}}
+		if opts.Horizontal && chartType == "bar" {
+			cfg.Options.IndexAxis = "y"
+		}
+	}
+	return cfg
+}
+
+// isNumericColumn reports whether every row's cell in col is a number.
+func isNumericColumn(rows [][]string, col int) bool {
+	for _, row := range rows {
+		if col < len(row) {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(row[col]), 64); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// chartBorderColor returns color made opaque, for the border around it.
+func chartBorderColor(color string) string {
+	if strings.HasPrefix(color, "rgba") {
+		return rgbaAlphaPattern.ReplaceAllString(color, "1)")
+	}
+	return color
+}
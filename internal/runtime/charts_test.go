@@ -0,0 +1,219 @@
+package runtime
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// sampleChartTable is a quarterly sales table with two series.
+var sampleChartTable = ChartTable{
+	Headers: []string{"Region", "Q1", "Q2"},
+	Rows: [][]string{
+		{"North", "100", "120.5"},
+		{"South", "80", "95"},
+		{"East", "60", "70"},
+	},
+}
+
+func TestNewChartConfigJSON(t *testing.T) {
+	cfg, err := NewChartConfig("bar", "Sales", sampleChartTable, ChartOptions{})
+	if err != nil {
+		t.Fatalf("NewChartConfig() error = %v", err)
+	}
+	got, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"bar",` +
+		`"data":{"labels":["North","South","East"],"datasets":[` +
+		`{"label":"Q1","data":[100,80,60],"backgroundColor":"rgba(54, 162, 235, 0.8)","borderColor":"rgba(54, 162, 235, 1)","borderWidth":1},` +
+		`{"label":"Q2","data":[120.5,95,70],"backgroundColor":"rgba(255, 99, 132, 0.8)","borderColor":"rgba(255, 99, 132, 1)","borderWidth":1}]},` +
+		`"options":{"responsive":true,"maintainAspectRatio":true,` +
+		`"plugins":{"title":{"display":true,"text":"Sales"},"legend":{"display":true}},` +
+		`"scales":{"x":{"stacked":false},"y":{"stacked":false}}}}`
+	if string(got) != want {
+		t.Errorf("config JSON =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNewChartConfigTypes(t *testing.T) {
+	single := ChartTable{Headers: []string{"Product", "Share"}, Rows: [][]string{{"A", "60"}, {"B", "40"}}}
+
+	tests := []struct {
+		name      string
+		chartType string
+		table     ChartTable
+		wantType  string
+	}{
+		{name: "line", chartType: "line", table: sampleChartTable, wantType: "line"},
+		{name: "auto single series", chartType: "", table: single, wantType: "pie"},
+		{name: "auto several series", chartType: "auto", table: sampleChartTable, wantType: "bar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewChartConfig(tt.chartType, "", tt.table, ChartOptions{})
+			if err != nil {
+				t.Fatalf("NewChartConfig() error = %v", err)
+			}
+			if cfg.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", cfg.Type, tt.wantType)
+			}
+			if cfg.Options.Plugins.Title.Display {
+				t.Error("title should be hidden when there is none")
+			}
+		})
+	}
+
+	// Pie charts color each value, and have no axes
+	cfg, err := NewChartConfig("pie", "Share", single, ChartOptions{Colors: []string{"#ff0000"}})
+	if err != nil {
+		t.Fatalf("NewChartConfig() error = %v", err)
+	}
+	colors, ok := cfg.Data.Datasets[0].BackgroundColor.([]string)
+	if !ok || len(colors) != 2 || colors[0] != "#ff0000" || colors[1] != "#ff0000" {
+		t.Errorf("pie colors = %v, want one per value, cycling the custom colors", cfg.Data.Datasets[0].BackgroundColor)
+	}
+	if cfg.Options.Scales != nil {
+		t.Errorf("pie scales = %v, want none", cfg.Options.Scales)
+	}
+}
+
+func TestNewChartConfigOptions(t *testing.T) {
+	hide := false
+	cfg, err := NewChartConfig("bar", "Sales", sampleChartTable, ChartOptions{
+		Colors:     []string{"rgba(1, 2, 3, 0.5)"},
+		Stacked:    true,
+		Horizontal: true,
+		Legend:     &hide,
+	})
+	if err != nil {
+		t.Fatalf("NewChartConfig() error = %v", err)
+	}
+	if cfg.Options.IndexAxis != "y" {
+		t.Errorf("IndexAxis = %q, want y for horizontal bars", cfg.Options.IndexAxis)
+	}
+	if !cfg.Options.Scales["x"].Stacked || !cfg.Options.Scales["y"].Stacked {
+		t.Errorf("Scales = %v, want stacked", cfg.Options.Scales)
+	}
+	if cfg.Options.Plugins.Legend.Display {
+		t.Error("legend should be hidden")
+	}
+	ds := cfg.Data.Datasets[1]
+	if ds.BackgroundColor != "rgba(1, 2, 3, 0.5)" || ds.BorderColor != "rgba(1, 2, 3, 1)" {
+		t.Errorf("second series colors = %v, %v, want the custom color, opaque border", ds.BackgroundColor, ds.BorderColor)
+	}
+
+	// Horizontal only applies to bar charts
+	cfg, _ = NewChartConfig("line", "", sampleChartTable, ChartOptions{Horizontal: true})
+	if cfg.Options.IndexAxis != "" {
+		t.Errorf("line IndexAxis = %q, want none", cfg.Options.IndexAxis)
+	}
+}
+
+func TestNewChartConfigNothingToChart(t *testing.T) {
+	tables := map[string]ChartTable{
+		"no numbers":    {Headers: []string{"Name", "City"}, Rows: [][]string{{"Alice", "NYC"}}},
+		"no rows":       {Headers: []string{"Name", "Score"}},
+		"single column": {Headers: []string{"Score"}, Rows: [][]string{{"1"}}},
+	}
+	for name, table := range tables {
+		if cfg, err := NewChartConfig("bar", "", table, ChartOptions{}); err == nil {
+			t.Errorf("%s: NewChartConfig() = %+v, want an error", name, cfg)
+		}
+	}
+
+	// An all-numeric table labels its rows with the first column
+	years := ChartTable{Headers: []string{"Year", "Users"}, Rows: [][]string{{"2024", "10"}, {"2025", "25"}}}
+	cfg, err := NewChartConfig("line", "", years, ChartOptions{})
+	if err != nil {
+		t.Fatalf("NewChartConfig() error = %v", err)
+	}
+	if len(cfg.Data.Datasets) != 1 || cfg.Data.Datasets[0].Label != "Users" || cfg.Data.Labels[1] != "2025" {
+		t.Errorf("config = %+v, want Years as labels and Users as the series", cfg.Data)
+	}
+}
+
+func TestNewSourceChartConfig(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"day": "Mon", "value": float64(3), "target": "5"},
+		{"day": "Tue", "value": float64(7), "target": "n/a"},
+	}
+	cfg, err := NewSourceChartConfig("line", rows, "day", []string{"value", "target"}, ChartOptions{})
+	if err != nil {
+		t.Fatalf("NewSourceChartConfig() error = %v", err)
+	}
+	if cfg.Type != "line" || len(cfg.Data.Labels) != 2 || cfg.Data.Labels[1] != "Tue" {
+		t.Errorf("config = %+v, want a line chart labelled by day", cfg)
+	}
+	if len(cfg.Data.Datasets) != 2 || cfg.Data.Datasets[0].Label != "Value" || cfg.Data.Datasets[0].Data[1] != 7 {
+		t.Errorf("datasets = %+v, want Value then Target", cfg.Data.Datasets)
+	}
+	if got := cfg.Data.Datasets[1].Data; got[0] != 5 || got[1] != 0 {
+		t.Errorf("target data = %v, want [5 0]", got)
+	}
+
+	// A chart waits for rows, but needs a field to plot
+	if cfg, err := NewSourceChartConfig("bar", nil, "day", []string{"value"}, ChartOptions{}); err != nil || len(cfg.Data.Labels) != 0 {
+		t.Errorf("NewSourceChartConfig() without rows = %+v, %v, want an empty chart", cfg, err)
+	}
+	if _, err := NewSourceChartConfig("bar", rows, "day", nil, ChartOptions{}); err == nil {
+		t.Error("NewSourceChartConfig() without lvt-y should fail")
+	}
+}
+
+func TestLiveChartRefresh(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeMetrics := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, "metrics.json"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeMetrics(`[{"day": "Mon", "value": 3}, {"day": "Tue", "value": 7}]`)
+
+	metadata := map[string]string{
+		"lvt-source":  "metrics",
+		"lvt-element": "chart",
+		"lvt-chart":   "line",
+		"lvt-x":       "day",
+		"lvt-y":       "value",
+	}
+	s, err := NewGenericStateWithMetadata("metrics", config.SourceConfig{Type: "json", File: "metrics.json"}, tmpDir, "", metadata)
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	defer s.Close()
+
+	chartData := func() ChartData {
+		t.Helper()
+		state, err := s.GetStateAsInterface()
+		if err != nil {
+			t.Fatalf("GetStateAsInterface() error = %v", err)
+		}
+		var cfg ChartConfig
+		if err := json.Unmarshal([]byte(state.(map[string]interface{})["Chart"].(string)), &cfg); err != nil {
+			t.Fatalf("chart config is not JSON: %v", err)
+		}
+		return cfg.Data
+	}
+	if got := chartData(); strings.Join(got.Labels, ",") != "Mon,Tue" || got.Datasets[0].Data[1] != 7 {
+		t.Errorf("chart data = %+v, want Mon and Tue", got)
+	}
+
+	writeMetrics(`[{"day": "Mon", "value": 3}, {"day": "Tue", "value": 9}, {"day": "Wed", "value": 4}]`)
+	if err := s.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("HandleAction(Refresh) error = %v", err)
+	}
+	got := chartData()
+	if strings.Join(got.Labels, ",") != "Mon,Tue,Wed" {
+		t.Errorf("labels after Refresh = %v, want Mon,Tue,Wed", got.Labels)
+	}
+	if data := got.Datasets[0].Data; len(data) != 3 || data[1] != 9 || data[2] != 4 {
+		t.Errorf("data after Refresh = %v, want [3 9 4]", data)
+	}
+}
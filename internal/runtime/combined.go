@@ -0,0 +1,166 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CombinedState backs a block bound to several sources, e.g.
+// lvt-source="orders,rates". Each source keeps its own GenericState, which
+// templates see under the source name in PascalCase ({{.Orders.Data}},
+// {{.Rates.Data}}). With a join key, the rows of all sources that share a
+// value for that field are also merged into {{.Joined}}.
+type CombinedState struct {
+	names   []string
+	states  map[string]*GenericState
+	joinKey string
+}
+
+// NewCombinedState combines the states of the named sources. states must be
+// in the same order as names. joinKey may be empty to expose the sources side
+// by side only.
+func NewCombinedState(names []string, states []*GenericState, joinKey string) *CombinedState {
+	c := &CombinedState{
+		names:   names,
+		states:  make(map[string]*GenericState, len(names)),
+		joinKey: joinKey,
+	}
+	for i, name := range names {
+		c.states[name] = states[i]
+	}
+	return c
+}
+
+// HandleAction routes an action to one source, named by the "source" data
+// field (data-source on the button). Refresh without a source refreshes
+// every source.
+func (c *CombinedState) HandleAction(action string, data map[string]interface{}) error {
+	name, _ := data["source"].(string)
+	if name == "" {
+		if strings.EqualFold(action, "refresh") {
+			var errs []error
+			for _, n := range c.names {
+				errs = append(errs, c.states[n].HandleAction(action, data))
+			}
+			return errors.Join(errs...)
+		}
+		return fmt.Errorf("action %q needs data-source naming one of %s", action, strings.Join(c.names, ", "))
+	}
+
+	state, ok := c.states[name]
+	if !ok {
+		return fmt.Errorf("action %q: source %q is not part of this block (%s)", action, name, strings.Join(c.names, ", "))
+	}
+	// The routing field isn't part of the row, so don't let writes store it
+	forwarded := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "source" {
+			forwarded[k] = v
+		}
+	}
+	return state.HandleAction(action, forwarded)
+}
+
+// GetStateAsInterface returns each source's template state under its name,
+// in both the original and PascalCase form, plus the joined rows when a join
+// key is set.
+func (c *CombinedState) GetStateAsInterface() (interface{}, error) {
+	result := make(map[string]interface{}, 2*len(c.names)+2)
+	var rows [][]map[string]interface{}
+	for _, name := range c.names {
+		state, err := c.states[name].GetStateAsInterface()
+		if err != nil {
+			return nil, fmt.Errorf("source %q: %w", name, err)
+		}
+		result[name] = state
+		result[snakeToPascal(name)] = state
+		rows = append(rows, stateRows(state))
+	}
+
+	if c.joinKey != "" {
+		joined := joinRows(rows, c.joinKey)
+		result["joined"] = joined
+		result["Joined"] = joined
+	}
+	return result, nil
+}
+
+// SetOnUpdate sets the function that re-renders the block when any of its
+// sources changes outside an action, as GenericState.SetOnUpdate does.
+func (c *CombinedState) SetOnUpdate(fn func()) {
+	for _, name := range c.names {
+		c.states[name].SetOnUpdate(fn)
+	}
+}
+
+// Close closes every source, returning all errors.
+func (c *CombinedState) Close() error {
+	var errs []error
+	for _, name := range c.names {
+		errs = append(errs, c.states[name].Close())
+	}
+	return errors.Join(errs...)
+}
+
+// stateRows returns the data rows of a state map from GetStateAsInterface.
+func stateRows(state interface{}) []map[string]interface{} {
+	m, _ := state.(map[string]interface{})
+	items, _ := m["data"].([]interface{})
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if row, ok := item.(map[string]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// joinRows inner-joins the rows of each source on key, in the order of the
+// first source. A joined row holds the fields of every matching row; where
+// sources share a field name, the earlier source's value wins. Rows without
+// a match in every source are dropped, and a source with several rows for one
+// key value contributes its first.
+func joinRows(sources [][]map[string]interface{}, key string) []interface{} {
+	joined := []interface{}{}
+	if len(sources) == 0 {
+		return joined
+	}
+
+	index := make([]map[string]map[string]interface{}, len(sources))
+	for i, rows := range sources[1:] {
+		index[i+1] = make(map[string]map[string]interface{}, len(rows))
+		for _, row := range rows {
+			if v, ok := row[key]; ok {
+				k := fmt.Sprint(v)
+				if _, dup := index[i+1][k]; !dup {
+					index[i+1][k] = row
+				}
+			}
+		}
+	}
+
+rows:
+	for _, row := range sources[0] {
+		v, ok := row[key]
+		if !ok {
+			continue
+		}
+		k := fmt.Sprint(v)
+		merged := make(map[string]interface{}, len(row))
+		for i := len(sources) - 1; i > 0; i-- {
+			match, ok := index[i][k]
+			if !ok {
+				continue rows
+			}
+			for f, fv := range match {
+				merged[f] = fv
+			}
+		}
+		for f, fv := range row {
+			merged[f] = fv
+		}
+		joined = append(joined, merged)
+	}
+	return joined
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestJoinRows(t *testing.T) {
+	orders := []map[string]interface{}{
+		{"id": "o1", "currency": "EUR", "note": "order"},
+		{"id": "o2", "currency": "USD"},
+		{"id": "o3"},
+	}
+	rates := []map[string]interface{}{
+		{"currency": "EUR", "rate": 1.1, "note": "rate"},
+		{"currency": "EUR", "rate": 9.9},
+	}
+
+	joined := joinRows([][]map[string]interface{}{orders, rates}, "currency")
+	if len(joined) != 1 {
+		t.Fatalf("joinRows() = %v, want only o1", joined)
+	}
+	row := joined[0].(map[string]interface{})
+	if row["id"] != "o1" || row["rate"] != 1.1 || row["note"] != "order" {
+		t.Errorf("joined row = %v, want o1 with the first EUR rate and its own note", row)
+	}
+}
+
+func TestCombinedStateActions(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 2)
+	if err := os.WriteFile(filepath.Join(tmpDir, "owners.json"), []byte(`[{"id":"t1","owner":"ana"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := NewGenericState("tasks", config.SourceConfig{Type: "markdown", File: "tasks.md", Anchor: "#tasks", Readonly: new(bool)}, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState(tasks) error = %v", err)
+	}
+	owners, err := NewGenericState("owners", config.SourceConfig{Type: "json", File: "owners.json"}, tmpDir, "")
+	if err != nil {
+		t.Fatalf("NewGenericState(owners) error = %v", err)
+	}
+	c := NewCombinedState([]string{"tasks", "owners"}, []*GenericState{tasks, owners}, "id")
+	defer c.Close()
+
+	c.SetOnUpdate(func() {})
+	for _, s := range []*GenericState{tasks, owners} {
+		s.mu.Lock()
+		set := s.onUpdate != nil
+		s.mu.Unlock()
+		if !set {
+			t.Error("SetOnUpdate() didn't reach every source")
+		}
+	}
+
+	if err := c.HandleAction("Toggle", map[string]interface{}{"id": "t1"}); err == nil || !strings.Contains(err.Error(), "data-source") {
+		t.Errorf("Toggle without a source error = %v, want a hint to name one", err)
+	}
+	if err := c.HandleAction("Toggle", map[string]interface{}{"source": "users", "id": "t1"}); err == nil {
+		t.Error("Toggle on an unknown source error = nil")
+	}
+	if err := c.HandleAction("Toggle", map[string]interface{}{"source": "tasks", "id": "t1"}); err != nil {
+		t.Fatalf("Toggle error = %v", err)
+	}
+	if err := c.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("Refresh error = %v", err)
+	}
+
+	state, err := c.GetStateAsInterface()
+	if err != nil {
+		t.Fatalf("GetStateAsInterface() error = %v", err)
+	}
+	m := state.(map[string]interface{})
+	if _, ok := m["Tasks"]; !ok {
+		t.Errorf("state keys missing Tasks: %v", m)
+	}
+	joined, _ := m["Joined"].([]interface{})
+	if len(joined) != 1 {
+		t.Fatalf("Joined = %v, want one row", joined)
+	}
+	row := joined[0].(map[string]interface{})
+	if row["owner"] != "ana" || row["done"] != true {
+		t.Errorf("joined row = %v, want t1 done and owned by ana", row)
+	}
+}
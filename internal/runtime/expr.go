@@ -283,15 +283,19 @@ func filterData(data []map[string]interface{}, where *WhereClause) []map[string]
 	return result
 }
 
-// getFieldValue gets a field value from a row, checking both lowercase and titlecase.
+// getFieldValue gets a field value from a row. A field matches a key when
+// both normalize to the same template key, so "first_name", "FirstName" and
+// "firstName" all find the same column.
 func getFieldValue(row map[string]interface{}, field string) interface{} {
 	if val, ok := row[field]; ok {
 		return val
 	}
-	// Try titlecase
-	if len(field) > 0 {
-		titleField := strings.ToUpper(field[:1]) + field[1:]
-		if val, ok := row[titleField]; ok {
+	want := snakeToPascal(field)
+	if val, ok := row[want]; ok {
+		return val
+	}
+	for k, val := range row {
+		if snakeToPascal(k) == want {
 			return val
 		}
 	}
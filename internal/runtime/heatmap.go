@@ -0,0 +1,313 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeatmapOptions customizes one heatmap, from the page frontmatter's
+// heatmaps map or a heatmap element's attributes.
+type HeatmapOptions struct {
+	// Colors from no count to the highest; days with a count are split
+	// evenly, by quantile, among all but the first
+	Colors    []string `yaml:"colors,omitempty" json:"colors,omitempty"`
+	WeekStart string   `yaml:"week_start,omitempty" json:"week_start,omitempty"` // sunday (default) or monday
+}
+
+// HeatmapDay is the count for one date.
+type HeatmapDay struct {
+	Date  time.Time
+	Count float64
+}
+
+// defaultHeatmapColors are GitHub's contribution colors.
+var defaultHeatmapColors = []string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+
+// heatmapDateLayout is how heatmap dates are written; longer values, like
+// RFC 3339 timestamps, are cut to it.
+const heatmapDateLayout = "2006-01-02"
+
+// Sizes of the heatmap SVG, in pixels.
+const (
+	heatmapCellSize = 10
+	heatmapStep     = 13 // Cell plus gap
+	heatmapLeft     = 30 // Room for weekday labels
+	heatmapTop      = 15 // Room for month labels
+	heatmapLegend   = 20 // Room for the legend below the grid
+)
+
+// errNoHeatmapData is returned when there are no dates to draw.
+var errNoHeatmapData = errors.New("no dates to draw a heatmap of")
+
+// HeatmapDays reads the days of table, from its date and count columns.
+// Without columns of those names, dates come from the first column of dates,
+// and counts from the first numeric column. Without counts, each row counts
+// one, as for a log of days a habit was kept. Rows without a date are skipped.
+func HeatmapDays(table ChartTable) ([]HeatmapDay, error) {
+	dateCol, countCol := -1, -1
+	for col, header := range table.Headers {
+		switch strings.ToLower(strings.TrimSpace(header)) {
+		case "date":
+			dateCol = col
+		case "count":
+			countCol = col
+		}
+	}
+	for col := range table.Headers {
+		if dateCol < 0 && isDateColumn(table.Rows, col) {
+			dateCol = col
+		}
+	}
+	if dateCol < 0 {
+		return nil, errNoHeatmapData
+	}
+	for col := range table.Headers {
+		if countCol < 0 && col != dateCol && isNumericColumn(table.Rows, col) {
+			countCol = col
+		}
+	}
+
+	var days []HeatmapDay
+	for _, row := range table.Rows {
+		if dateCol >= len(row) {
+			continue
+		}
+		date, ok := parseHeatmapDate(row[dateCol])
+		if !ok {
+			continue
+		}
+		count := 1.0
+		if countCol >= 0 && countCol < len(row) {
+			count, _ = strconv.ParseFloat(strings.TrimSpace(row[countCol]), 64)
+		}
+		days = append(days, HeatmapDay{Date: date, Count: count})
+	}
+	return days, nil
+}
+
+// heatmapSourceDays reads the days of source rows, from their dateField
+// and countField ("date" and "count" when empty). Rows without a count
+// count one.
+func heatmapSourceDays(rows []map[string]interface{}, dateField, countField string) []HeatmapDay {
+	if dateField == "" {
+		dateField = "date"
+	}
+	if countField == "" {
+		countField = "count"
+	}
+	var days []HeatmapDay
+	for _, row := range rows {
+		date, ok := parseHeatmapDate(fmt.Sprint(getFieldValue(row, dateField)))
+		if !ok {
+			continue
+		}
+		count := 1.0
+		if val := getFieldValue(row, countField); val != nil {
+			count, _ = tryFloat64(val)
+		}
+		days = append(days, HeatmapDay{Date: date, Count: count})
+	}
+	return days
+}
+
+// setHeatmapFields reads the lvt-date, lvt-count, lvt-colors and
+// lvt-week-start attributes of a heatmap element from block metadata.
+func (s *GenericState) setHeatmapFields(metadata map[string]string) {
+	s.heatmapDate = strings.TrimSpace(metadata["lvt-date"])
+	s.heatmapCount = strings.TrimSpace(metadata["lvt-count"])
+	s.heatmapOpts.WeekStart = strings.TrimSpace(metadata["lvt-week-start"])
+	for _, color := range strings.Split(metadata["lvt-colors"], ",") {
+		if color = strings.TrimSpace(color); color != "" {
+			s.heatmapOpts.Colors = append(s.heatmapOpts.Colors, color)
+		}
+	}
+}
+
+// addHeatmap adds the heatmap SVG of the current Data to a processed state
+// map, as Heatmap, for heatmap elements. It's empty when there are no dates.
+func (s *GenericState) addHeatmap(state map[string]interface{}) {
+	if s.elementType != "heatmap" {
+		return
+	}
+	svg, _ := NewHeatmapSVG(heatmapSourceDays(s.Data, s.heatmapDate, s.heatmapCount), s.heatmapOpts)
+	state["Heatmap"] = htmltemplate.HTML(svg)
+}
+
+// NewHeatmapSVG draws days as a GitHub-style calendar: a column per week,
+// for the year up to the latest date, with each day colored by the quantile
+// of its count. It returns an error when there are no days.
+func NewHeatmapSVG(days []HeatmapDay, opts HeatmapOptions) (string, error) {
+	if len(days) == 0 {
+		return "", errNoHeatmapData
+	}
+	colors := defaultHeatmapColors
+	if len(opts.Colors) >= 2 {
+		colors = opts.Colors
+	}
+	weekStart := time.Sunday
+	if strings.EqualFold(opts.WeekStart, "monday") {
+		weekStart = time.Monday
+	}
+
+	counts := make(map[string]float64)
+	var end time.Time
+	for _, d := range days {
+		counts[d.Date.Format(heatmapDateLayout)] += d.Count
+		if d.Date.After(end) {
+			end = d.Date
+		}
+	}
+	weeks := heatmapWeeks(counts, end, weekStart)
+
+	var inRange []float64
+	for _, week := range weeks {
+		for _, day := range week {
+			inRange = append(inRange, day.Count)
+		}
+	}
+	thresholds := heatmapThresholds(inRange, len(colors)-1)
+
+	width := heatmapLeft + len(weeks)*heatmapStep
+	height := heatmapTop + 7*heatmapStep + heatmapLegend
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="tinkerdown-heatmap" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="Heatmap to %s">`,
+		width, height, width, height, end.Format(heatmapDateLayout))
+	b.WriteString(`<g font-size="9" fill="currentColor">`)
+
+	// Month labels over the first week of each month, skipping those too
+	// close to the next to fit, and every other weekday
+	var labelWeeks []int
+	for w, week := range weeks {
+		if w == 0 || week[0].Date.Month() != weeks[w-1][0].Date.Month() {
+			labelWeeks = append(labelWeeks, w)
+		}
+	}
+	for i, w := range labelWeeks {
+		next := len(weeks)
+		if i+1 < len(labelWeeks) {
+			next = labelWeeks[i+1]
+		}
+		if next-w >= 3 {
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, heatmapLeft+w*heatmapStep, heatmapTop-4, weeks[w][0].Date.Month().String()[:3])
+		}
+	}
+	for row := 1; row < 7; row += 2 {
+		day := time.Weekday((int(weekStart) + row) % 7)
+		fmt.Fprintf(&b, `<text x="0" y="%d">%s</text>`, heatmapTop+row*heatmapStep+heatmapCellSize-1, day.String()[:3])
+	}
+	b.WriteString(`</g>`)
+
+	for w, week := range weeks {
+		for row, day := range week {
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s: %s</title></rect>`,
+				heatmapLeft+w*heatmapStep, heatmapTop+row*heatmapStep, heatmapCellSize, heatmapCellSize,
+				html.EscapeString(colors[heatmapLevel(day.Count, thresholds)]),
+				day.Date.Format(heatmapDateLayout), strconv.FormatFloat(day.Count, 'f', -1, 64))
+		}
+	}
+
+	// Legend: Less, each color, More
+	legendY := heatmapTop + 7*heatmapStep + 6
+	legendX := width - (len(colors)*heatmapStep + 60)
+	if legendX < heatmapLeft {
+		legendX = heatmapLeft
+	}
+	fmt.Fprintf(&b, `<g font-size="9" fill="currentColor"><text x="%d" y="%d">Less</text>`, legendX, legendY+heatmapCellSize-1)
+	for i, color := range colors {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/>`,
+			legendX+26+i*heatmapStep, legendY, heatmapCellSize, heatmapCellSize, html.EscapeString(color))
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d">More</text></g>`, legendX+30+len(colors)*heatmapStep, legendY+heatmapCellSize-1)
+	b.WriteString(`</svg>`)
+	return b.String(), nil
+}
+
+// heatmapWeeks lays out the year up to end as weeks of days, from weekStart.
+// The first week may begin up to six days more than a year before end; the
+// last holds the days up to end. Counts are keyed by date.
+func heatmapWeeks(counts map[string]float64, end time.Time, weekStart time.Weekday) [][]HeatmapDay {
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	first := end.AddDate(0, 0, -364)
+	first = first.AddDate(0, 0, -((int(first.Weekday()) - int(weekStart) + 7) % 7))
+
+	var weeks [][]HeatmapDay
+	for d, i := first, 0; !d.After(end); d, i = d.AddDate(0, 0, 1), i+1 {
+		if i%7 == 0 {
+			weeks = append(weeks, make([]HeatmapDay, 0, 7))
+		}
+		w := len(weeks) - 1
+		weeks[w] = append(weeks[w], HeatmapDay{Date: d, Count: counts[d.Format(heatmapDateLayout)]})
+	}
+	return weeks
+}
+
+// heatmapThresholds splits the positive counts into levels groups of about
+// the same size, returning the highest count of each group but the last.
+func heatmapThresholds(counts []float64, levels int) []float64 {
+	var positive []float64
+	for _, c := range counts {
+		if c > 0 {
+			positive = append(positive, c)
+		}
+	}
+	if len(positive) == 0 || levels < 2 {
+		return nil
+	}
+	sort.Float64s(positive)
+
+	thresholds := make([]float64, levels-1)
+	for i := range thresholds {
+		// Nearest-rank quantile
+		rank := int(math.Ceil(float64(i+1) / float64(levels) * float64(len(positive))))
+		thresholds[i] = positive[max(rank-1, 0)]
+	}
+	return thresholds
+}
+
+// heatmapLevel returns the color index of count: 0 for none, then 1 plus
+// the number of thresholds below it.
+func heatmapLevel(count float64, thresholds []float64) int {
+	if count <= 0 {
+		return 0
+	}
+	level := 1
+	for _, t := range thresholds {
+		if count > t {
+			level++
+		}
+	}
+	return level
+}
+
+// isDateColumn reports whether every row's cell in col is a date.
+func isDateColumn(rows [][]string, col int) bool {
+	if len(rows) == 0 {
+		return false
+	}
+	for _, row := range rows {
+		if col < len(row) {
+			if _, ok := parseHeatmapDate(row[col]); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseHeatmapDate parses a date written as 2006-01-02, or a timestamp
+// starting with one.
+func parseHeatmapDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > len(heatmapDateLayout) {
+		s = s[:len(heatmapDateLayout)]
+	}
+	date, err := time.Parse(heatmapDateLayout, s)
+	return date, err == nil
+}
@@ -0,0 +1,171 @@
+package runtime
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestHeatmapWeeks(t *testing.T) {
+	end := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC) // A Wednesday
+	counts := map[string]float64{"2024-01-10": 3, "2024-01-01": 1, "2023-01-09": 5}
+
+	tests := []struct {
+		weekStart  time.Weekday
+		wantFirst  string
+		wantLast   int // Days in the last week
+		wantEndRow int // Row of the end date
+	}{
+		{weekStart: time.Sunday, wantFirst: "2023-01-08", wantLast: 4, wantEndRow: 3},
+		{weekStart: time.Monday, wantFirst: "2023-01-09", wantLast: 3, wantEndRow: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.weekStart.String(), func(t *testing.T) {
+			weeks := heatmapWeeks(counts, end, tt.weekStart)
+			if len(weeks) != 53 {
+				t.Fatalf("got %d weeks, want 53", len(weeks))
+			}
+			if got := weeks[0][0].Date.Format(heatmapDateLayout); got != tt.wantFirst {
+				t.Errorf("first day = %s, want %s", got, tt.wantFirst)
+			}
+			for w, week := range weeks[:len(weeks)-1] {
+				if len(week) != 7 || week[0].Date.Weekday() != tt.weekStart {
+					t.Fatalf("week %d = %d days from %s, want 7 from %s", w, len(week), week[0].Date.Weekday(), tt.weekStart)
+				}
+			}
+			last := weeks[len(weeks)-1]
+			if len(last) != tt.wantLast || !last[tt.wantEndRow].Date.Equal(end) || last[tt.wantEndRow].Count != 3 {
+				t.Errorf("last week = %+v, want %d days ending on the 10th with its count", last, tt.wantLast)
+			}
+		})
+	}
+
+	// New Year's Day is the Monday of the last full week, with a Sunday start
+	weeks := heatmapWeeks(counts, end, time.Sunday)
+	if day := weeks[51][1]; day.Date.Format(heatmapDateLayout) != "2024-01-01" || day.Count != 1 {
+		t.Errorf("week 51, row 1 = %+v, want 2024-01-01 with a count of 1", day)
+	}
+}
+
+func TestHeatmapThresholds(t *testing.T) {
+	counts := []float64{0, 0, 8, 1, 2, 3, 4, 5, 6, 7}
+	thresholds := heatmapThresholds(counts, 4)
+	if want := []float64{2, 4, 6}; !reflect.DeepEqual(thresholds, want) {
+		t.Fatalf("heatmapThresholds() = %v, want %v", thresholds, want)
+	}
+
+	levels := map[float64]int{0: 0, 1: 1, 2: 1, 3: 2, 4: 2, 5: 3, 6: 3, 7: 4, 8: 4, 100: 4}
+	for count, want := range levels {
+		if got := heatmapLevel(count, thresholds); got != want {
+			t.Errorf("heatmapLevel(%v) = %d, want %d", count, got, want)
+		}
+	}
+
+	// Equal counts all get the first color above none
+	if got := heatmapLevel(2, heatmapThresholds([]float64{2, 2, 2}, 4)); got != 1 {
+		t.Errorf("heatmapLevel() of equal counts = %d, want 1", got)
+	}
+	if got := heatmapThresholds([]float64{0, 0}, 4); got != nil {
+		t.Errorf("heatmapThresholds() without counts = %v, want none", got)
+	}
+}
+
+func TestHeatmapDays(t *testing.T) {
+	table := ChartTable{
+		Headers: []string{"Note", "Count", "Date"},
+		Rows: [][]string{
+			{"run", "2", "2024-03-01"},
+			{"swim", "1.5", "2024-03-02T07:00:00Z"},
+			{"rest", "0", "someday"},
+		},
+	}
+	days, err := HeatmapDays(table)
+	if err != nil {
+		t.Fatalf("HeatmapDays() error = %v", err)
+	}
+	if len(days) != 2 || days[1].Date.Format(heatmapDateLayout) != "2024-03-02" || days[1].Count != 1.5 {
+		t.Errorf("HeatmapDays() = %+v, want two days from the named columns", days)
+	}
+
+	// A log of dates counts each row
+	log := ChartTable{Headers: []string{"Day", "Habit"}, Rows: [][]string{{"2024-03-01", "read"}, {"2024-03-01", "walk"}}}
+	days, err = HeatmapDays(log)
+	if err != nil || len(days) != 2 || days[0].Count != 1 {
+		t.Errorf("HeatmapDays() = %+v, %v, want a count of one per row", days, err)
+	}
+
+	if _, err := HeatmapDays(ChartTable{Headers: []string{"Name"}, Rows: [][]string{{"Alice"}}}); err == nil {
+		t.Error("HeatmapDays() without dates should fail")
+	}
+}
+
+func TestNewHeatmapSVG(t *testing.T) {
+	days := []HeatmapDay{
+		{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Count: 1},
+		{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Count: 1},
+		{Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Count: 9},
+	}
+	svg, err := NewHeatmapSVG(days, HeatmapOptions{Colors: []string{"#eee", "#ccc", "#000"}, WeekStart: "Monday"})
+	if err != nil {
+		t.Fatalf("NewHeatmapSVG() error = %v", err)
+	}
+	for _, want := range []string{
+		`<svg class="tinkerdown-heatmap"`,
+		`fill="#ccc"><title>2024-03-01: 2</title>`,
+		`fill="#000"><title>2024-03-02: 9</title>`,
+		`fill="#eee"><title>2024-02-29: 0</title>`,
+		`>Tue</text>`,
+		`>Mar</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("NewHeatmapSVG() is missing %s", want)
+		}
+	}
+
+	if _, err := NewHeatmapSVG(nil, HeatmapOptions{}); err == nil {
+		t.Error("NewHeatmapSVG() without days should fail")
+	}
+}
+
+func TestHeatmapSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := `[{"day": "2024-03-01", "minutes": 30}, {"day": "2024-03-02", "minutes": 5}, {"day": "not a date"}]`
+	if err := os.WriteFile(filepath.Join(tmpDir, "habits.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]string{
+		"lvt-element":    "heatmap",
+		"lvt-date":       "day",
+		"lvt-count":      "minutes",
+		"lvt-colors":     "#eee, #888, #000",
+		"lvt-week-start": "monday",
+	}
+	s, err := NewGenericStateWithMetadata("habits", config.SourceConfig{Type: "json", File: "habits.json"}, tmpDir, "", metadata)
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	defer s.Close()
+
+	state, err := s.GetStateAsInterface()
+	if err != nil {
+		t.Fatalf("GetStateAsInterface() error = %v", err)
+	}
+	svg, _ := state.(map[string]interface{})["Heatmap"].(template.HTML)
+	for _, want := range []string{`fill="#000"><title>2024-03-01: 30</title>`, `fill="#888"><title>2024-03-02: 5</title>`, `>Tue</text>`} {
+		if !strings.Contains(string(svg), want) {
+			t.Errorf("Heatmap is missing %s", want)
+		}
+	}
+
+	// Rows without a count field count one each
+	days := heatmapSourceDays([]map[string]interface{}{{"date": "2024-03-01"}, {"Date": "2024-03-01"}}, "", "")
+	if len(days) != 2 || days[0].Count != 1 {
+		t.Errorf("heatmapSourceDays() = %+v, want a count of one per row", days)
+	}
+}
@@ -0,0 +1,134 @@
+package runtime
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The size of a sparkline's viewBox. Points are kept sparklinePadding away
+// from the top and bottom so the stroke isn't clipped.
+const (
+	SparklineWidth   = 100
+	SparklineHeight  = 20
+	sparklinePadding = 1
+)
+
+// SparklinePath returns the SVG path data for a line through values, scaled
+// so the smallest value is at the bottom of a width by height box and the
+// largest at the top. A flat or single-value series is a line across the
+// middle. It returns "" for an empty series.
+func SparklinePath(values []float64, width, height float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if len(values) == 1 {
+		values = []float64{values[0], values[0]}
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	step := width / float64(len(values)-1)
+	span := height - 2*sparklinePadding
+
+	var b strings.Builder
+	for i, v := range values {
+		y := height / 2
+		if hi > lo {
+			y = height - sparklinePadding - (v-lo)/(hi-lo)*span
+		}
+		if i == 0 {
+			b.WriteString("M")
+		} else {
+			b.WriteString("L")
+		}
+		b.WriteString(formatSparklineCoord(float64(i) * step))
+		b.WriteString(",")
+		b.WriteString(formatSparklineCoord(y))
+	}
+	return b.String()
+}
+
+// SparklineSVG returns an inline SVG sparkline of values, drawn in the
+// current text color. It returns "" for an empty series.
+func SparklineSVG(values []float64) string {
+	path := SparklinePath(values, SparklineWidth, SparklineHeight)
+	if path == "" {
+		return ""
+	}
+	labels := make([]string, len(values))
+	for i, v := range values {
+		labels[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf(`<svg class="tinkerdown-sparkline" viewBox="0 0 %d %d" width="%d" height="%d" preserveAspectRatio="none" role="img" aria-label="%s">`+
+		`<path d="%s" fill="none" stroke="currentColor" stroke-width="1.5" vector-effect="non-scaling-stroke"/></svg>`,
+		SparklineWidth, SparklineHeight, SparklineWidth, SparklineHeight, strings.Join(labels, ", "), path)
+}
+
+// ParseSeries parses a comma-separated list of numbers, like "3, 5, 2". It
+// returns false when s is empty or any item isn't a number.
+func ParseSeries(s string) ([]float64, bool) {
+	if strings.TrimSpace(s) == "" {
+		return nil, false
+	}
+	parts := strings.Split(s, ",")
+	values := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// sparklineValues converts a field value to a series: a comma-separated
+// string, or a list of numbers.
+func sparklineValues(v interface{}) ([]float64, bool) {
+	switch val := v.(type) {
+	case string:
+		return ParseSeries(val)
+	case []interface{}:
+		values := make([]float64, len(val))
+		for i, item := range val {
+			f, ok := tryFloat64(item)
+			if !ok {
+				return nil, false
+			}
+			values[i] = f
+		}
+		return values, len(values) > 0
+	}
+	return nil, false
+}
+
+// addSparklines adds the SVG sparkline of each sparkline column to every
+// row of a processed state map, under the field name plus "_sparkline", for
+// the generated table template to show.
+func (s *GenericState) addSparklines(state map[string]interface{}) {
+	rows, _ := state["data"].([]interface{})
+	for _, col := range s.tableColumns {
+		if col.kind != "sparkline" {
+			continue
+		}
+		for _, item := range rows {
+			row, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if values, ok := sparklineValues(getFieldValue(row, col.field)); ok {
+				row[col.field+"_sparkline"] = template.HTML(SparklineSVG(values))
+			}
+		}
+	}
+}
+
+// formatSparklineCoord formats a coordinate with at most two decimals.
+func formatSparklineCoord(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestSparklinePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{name: "min at bottom, max at top", values: []float64{1, 3, 2}, want: "M0,19L50,1L100,10"},
+		{name: "negative values", values: []float64{-4, 0, 4, 0, -4}, want: "M0,19L25,10L50,1L75,10L100,19"},
+		{name: "fractional steps", values: []float64{0, 1, 2, 3}, want: "M0,19L33.33,13L66.67,7L100,1"},
+		{name: "flat series", values: []float64{5, 5, 5}, want: "M0,10L50,10L100,10"},
+		{name: "single value", values: []float64{7}, want: "M0,10L100,10"},
+		{name: "empty series", values: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SparklinePath(tt.values, SparklineWidth, SparklineHeight); got != tt.want {
+				t.Errorf("SparklinePath(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparklineSVG(t *testing.T) {
+	svg := SparklineSVG([]float64{1, 3, 2.5})
+	for _, want := range []string{
+		`<svg class="tinkerdown-sparkline" viewBox="0 0 100 20"`,
+		`aria-label="1, 3, 2.5"`,
+		`<path d="M0,19L50,1L100,5.5"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SparklineSVG() = %s, want it to contain %s", svg, want)
+		}
+	}
+	if svg := SparklineSVG(nil); svg != "" {
+		t.Errorf("SparklineSVG(nil) = %q, want none", svg)
+	}
+}
+
+func TestParseSeries(t *testing.T) {
+	if got, ok := ParseSeries(" 3, 5,-2.5 "); !ok || len(got) != 3 || got[2] != -2.5 {
+		t.Errorf("ParseSeries() = %v, %v, want [3 5 -2.5]", got, ok)
+	}
+	for _, s := range []string{"", "  ", "1,,2", "1, two"} {
+		if got, ok := ParseSeries(s); ok {
+			t.Errorf("ParseSeries(%q) = %v, want not a series", s, got)
+		}
+	}
+}
+
+func TestSparklineColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := `[{"name": "cpu", "trend": "1,3,2"}, {"name": "disk", "trend": [4, 4]}, {"name": "net", "trend": "n/a"}]`
+	if err := os.WriteFile(filepath.Join(tmpDir, "hosts.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]string{"lvt-element": "table", "lvt-columns": "name:Name,trend:Last hour:sparkline"}
+	s, err := NewGenericStateWithMetadata("hosts", config.SourceConfig{Type: "json", File: "hosts.json"}, tmpDir, "", metadata)
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	defer s.Close()
+
+	if s.tableColumns[1].label != "Last hour" || s.tableColumns[1].kind != "sparkline" {
+		t.Errorf("column = %+v, want a sparkline labelled Last hour", s.tableColumns[1])
+	}
+	rows := pagedData(t, s)
+	svg, _ := rows[0].(map[string]interface{})["trend_sparkline"].(template.HTML)
+	if !strings.Contains(string(svg), `d="M0,19L50,1L100,10"`) {
+		t.Errorf("cpu sparkline = %q, want the series drawn", svg)
+	}
+	if _, ok := rows[1].(map[string]interface{})["trend_sparkline"].(template.HTML); !ok {
+		t.Error("a list of numbers should be drawn as a sparkline")
+	}
+	if svg, ok := rows[2].(map[string]interface{})["trend_sparkline"]; ok {
+		t.Errorf("net sparkline = %v, want none for a value that isn't a series", svg)
+	}
+}
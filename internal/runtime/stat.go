@@ -0,0 +1,146 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// StatOptions configures a stat card, from its element's attributes.
+type StatOptions struct {
+	Value         string // Field holding the number (default "value")
+	Label         string // Text above the number; the row's label field, or the value field's name, when empty
+	Unit          string // Shown before a currency number ("$"), after any other ("ms")
+	Compare       string // Field holding the number to compare with, for the delta
+	LowerIsBetter bool   // A fall is good news, as for latency or costs
+}
+
+// StatCard is a single number with its label and its change from the
+// compared number, for a stat card.
+type StatCard struct {
+	Label   string `json:"label"`
+	Value   string `json:"value"`
+	Prefix  string `json:"prefix"`  // Unit shown before the number
+	Suffix  string `json:"suffix"`  // Unit shown after the number
+	Delta   string `json:"delta"`   // Signed change, like "+3" or "-1.5"
+	Percent string `json:"percent"` // Signed change as a percentage, when the compared number isn't zero
+	Trend   string `json:"trend"`   // "up", "down" or "flat"
+	Tone    string `json:"tone"`    // "good" or "bad"; empty when flat
+}
+
+// currencyUnits are units written before the number.
+var currencyUnits = map[string]bool{"$": true, "€": true, "£": true, "¥": true, "₹": true}
+
+// NewStatCard builds the stat card of the first of rows. A value that isn't
+// a number is shown as it is, without a delta; a missing one is shown as a
+// dash.
+func NewStatCard(rows []map[string]interface{}, opts StatOptions) StatCard {
+	valueField := opts.Value
+	if valueField == "" {
+		valueField = "value"
+	}
+	card := StatCard{Label: opts.Label, Value: "–"}
+	if currencyUnits[opts.Unit] {
+		card.Prefix = opts.Unit
+	} else {
+		card.Suffix = opts.Unit
+	}
+	if len(rows) == 0 {
+		if card.Label == "" {
+			card.Label = columnLabel(valueField)
+		}
+		return card
+	}
+
+	row := rows[0]
+	if card.Label == "" {
+		if label := getFieldValue(row, "label"); label != nil {
+			card.Label = fmt.Sprint(label)
+		} else {
+			card.Label = columnLabel(valueField)
+		}
+	}
+
+	raw := getFieldValue(row, valueField)
+	if raw == nil {
+		return card
+	}
+	value, ok := tryFloat64(raw)
+	if !ok {
+		card.Value = fmt.Sprint(raw)
+		return card
+	}
+	card.Value = formatStatNumber(value, false)
+
+	if opts.Compare == "" {
+		return card
+	}
+	previous, ok := tryFloat64(getFieldValue(row, opts.Compare))
+	if !ok {
+		return card
+	}
+	card.setDelta(value, previous, opts.LowerIsBetter)
+	return card
+}
+
+// setStatFields reads the lvt-value, lvt-label, lvt-unit, lvt-compare and
+// lvt-better attributes of a stat element from block metadata.
+func (s *GenericState) setStatFields(metadata map[string]string) {
+	s.statOpts = StatOptions{
+		Value:         strings.TrimSpace(metadata["lvt-value"]),
+		Label:         strings.TrimSpace(metadata["lvt-label"]),
+		Unit:          strings.TrimSpace(metadata["lvt-unit"]),
+		Compare:       strings.TrimSpace(metadata["lvt-compare"]),
+		LowerIsBetter: strings.EqualFold(strings.TrimSpace(metadata["lvt-better"]), "lower"),
+	}
+}
+
+// setDelta sets the change from previous to value, and whether it's good.
+func (c *StatCard) setDelta(value, previous float64, lowerIsBetter bool) {
+	diff := value - previous
+	c.Delta = formatStatNumber(diff, true)
+	if previous != 0 {
+		c.Percent = formatStatNumber(math.Round(diff/math.Abs(previous)*1000)/10, true) + "%"
+	}
+	switch {
+	case diff > 0:
+		c.Trend = "up"
+	case diff < 0:
+		c.Trend = "down"
+	default:
+		c.Trend = "flat"
+		return
+	}
+	if (diff > 0) != lowerIsBetter {
+		c.Tone = "good"
+	} else {
+		c.Tone = "bad"
+	}
+}
+
+// formatStatNumber formats f with thousands separators and at most two
+// decimals, with a + for positive numbers when signed.
+func formatStatNumber(f float64, signed bool) string {
+	s := strconv.FormatFloat(math.Abs(math.Round(f*100)/100), 'f', -1, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if frac != "" {
+		b.WriteString("." + frac)
+	}
+	switch {
+	case b.String() == "0":
+		return "0"
+	case f < 0:
+		return "-" + b.String()
+	case signed:
+		return "+" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestNewStatCard(t *testing.T) {
+	tests := []struct {
+		name string
+		row  map[string]interface{}
+		opts StatOptions
+		want StatCard
+	}{
+		{
+			name: "rise is good",
+			row:  map[string]interface{}{"revenue": 12500.0, "last_month": 10000.0},
+			opts: StatOptions{Value: "revenue", Compare: "last_month", Unit: "$"},
+			want: StatCard{Label: "Revenue", Value: "12,500", Prefix: "$", Delta: "+2,500", Percent: "+25%", Trend: "up", Tone: "good"},
+		},
+		{
+			name: "fall is bad",
+			row:  map[string]interface{}{"value": 90, "previous": "120", "label": "Signups"},
+			opts: StatOptions{Compare: "previous"},
+			want: StatCard{Label: "Signups", Value: "90", Delta: "-30", Percent: "-25%", Trend: "down", Tone: "bad"},
+		},
+		{
+			name: "fall is good when lower is better",
+			row:  map[string]interface{}{"p95": 180.5, "last_week": 200.0},
+			opts: StatOptions{Value: "p95", Label: "Latency", Unit: "ms", Compare: "last_week", LowerIsBetter: true},
+			want: StatCard{Label: "Latency", Value: "180.5", Suffix: "ms", Delta: "-19.5", Percent: "-9.8%", Trend: "down", Tone: "good"},
+		},
+		{
+			name: "rise is bad when lower is better",
+			row:  map[string]interface{}{"value": 3, "before": 1},
+			opts: StatOptions{Compare: "before", LowerIsBetter: true},
+			want: StatCard{Label: "Value", Value: "3", Delta: "+2", Percent: "+200%", Trend: "up", Tone: "bad"},
+		},
+		{
+			name: "no change",
+			row:  map[string]interface{}{"value": 7, "before": 7},
+			opts: StatOptions{Compare: "before"},
+			want: StatCard{Label: "Value", Value: "7", Delta: "0", Percent: "0%", Trend: "flat"},
+		},
+		{
+			name: "no percent from zero",
+			row:  map[string]interface{}{"value": 4, "before": 0},
+			opts: StatOptions{Compare: "before"},
+			want: StatCard{Label: "Value", Value: "4", Delta: "+4", Trend: "up", Tone: "good"},
+		},
+		{
+			name: "text value",
+			row:  map[string]interface{}{"status": "Healthy", "before": 1},
+			opts: StatOptions{Value: "status", Compare: "before"},
+			want: StatCard{Label: "Status", Value: "Healthy"},
+		},
+		{
+			name: "missing value",
+			row:  map[string]interface{}{"other": 1},
+			want: StatCard{Label: "Value", Value: "–"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewStatCard([]map[string]interface{}{tt.row}, tt.opts); got != tt.want {
+				t.Errorf("NewStatCard() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := NewStatCard(nil, StatOptions{Value: "total_users"}); got.Label != "Total Users" || got.Value != "–" {
+		t.Errorf("NewStatCard() without rows = %+v, want a dash labelled Total Users", got)
+	}
+}
+
+func TestFormatStatNumber(t *testing.T) {
+	tests := []struct {
+		f      float64
+		signed bool
+		want   string
+	}{
+		{f: 0, signed: true, want: "0"},
+		{f: 999, want: "999"},
+		{f: 1234567.891, want: "1,234,567.89"},
+		{f: -1000, want: "-1,000"},
+		{f: 1000, signed: true, want: "+1,000"},
+		{f: 0.004, signed: true, want: "0"},
+	}
+	for _, tt := range tests {
+		if got := formatStatNumber(tt.f, tt.signed); got != tt.want {
+			t.Errorf("formatStatNumber(%v, %v) = %q, want %q", tt.f, tt.signed, got, tt.want)
+		}
+	}
+}
+
+func TestStatSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := `[{"mrr": 4200, "last_month": 4000}, {"mrr": 1, "last_month": 2}]`
+	if err := os.WriteFile(filepath.Join(tmpDir, "mrr.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]string{
+		"lvt-element": "stat",
+		"lvt-value":   "mrr",
+		"lvt-label":   "MRR",
+		"lvt-unit":    "$",
+		"lvt-compare": "last_month",
+	}
+	s, err := NewGenericStateWithMetadata("mrr", config.SourceConfig{Type: "json", File: "mrr.json"}, tmpDir, "", metadata)
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	defer s.Close()
+
+	state, err := s.GetStateAsInterface()
+	if err != nil {
+		t.Fatalf("GetStateAsInterface() error = %v", err)
+	}
+	card, _ := state.(map[string]interface{})["Stat"].(map[string]interface{})
+	if card["Label"] != "MRR" || card["Value"] != "4,200" || card["Prefix"] != "$" || card["Delta"] != "+200" || card["Tone"] != "good" {
+		t.Errorf("Stat = %v, want the first row's MRR, up by $200", card)
+	}
+}
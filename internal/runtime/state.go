@@ -7,15 +7,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/livetemplate/lvt/components/datatable"
 
 	"github.com/livetemplate/tinkerdown/internal/cache"
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/metrics"
 	"github.com/livetemplate/tinkerdown/internal/source"
+	"github.com/livetemplate/tinkerdown/internal/tracing"
 	"github.com/livetemplate/tinkerdown/internal/wasm"
 )
 
@@ -34,16 +40,34 @@ type GenericState struct {
 	Data   []map[string]interface{} `json:"data"`
 	Error  string                   `json:"error,omitempty"`
 	Errors map[string]string        `json:"errors,omitempty"`
+	Notice string                   `json:"notice,omitempty"` // Outcome of an action that succeeded in part
 
 	// Datatable field - used when source is rendered in a table element
 	Table *datatable.DataTable `json:"table,omitempty"`
 
+	// Chart.js config JSON - used when source is rendered in a chart element
+	Chart string `json:"chart,omitempty"`
+
+	// Stat card - used when source is rendered in a stat element
+	Stat *StatCard `json:"stat,omitempty"`
+
 	// Cache metadata for UI display
 	CacheInfo *cache.CacheInfo `json:"cache_info,omitempty"`
 
 	// Inline edit state - tracks which row is being edited (empty = none)
 	EditingID string `json:"editingId,omitempty"`
 
+	// Item counts over all rows, before filtering and pagination. Done and
+	// Remaining count task list items and are zero for other data.
+	Total     int `json:"total"`
+	Done      int `json:"done"`
+	Remaining int `json:"remaining"`
+
+	// Pagination fields - only populated when options["page_size"] is set
+	Page       int `json:"page,omitempty"`
+	TotalPages int `json:"totalPages,omitempty"`
+	TotalCount int `json:"totalCount,omitempty"`
+
 	// Exec-specific fields
 	Output     string `json:"output,omitempty"`
 	Stderr     string `json:"stderr,omitempty"`
@@ -53,15 +77,34 @@ type GenericState struct {
 	Args       []Arg  `json:"args,omitempty"`
 	Executable string `json:"executable,omitempty"`
 
+	// Set for exec sources with confirm: the question asked while Status is
+	// "confirm", before a requested Run goes ahead
+	ConfirmMessage string `json:"confirmMessage,omitempty"`
+
 	// Private runtime fields (not serialized)
 	source       source.Source
 	sourceCfg    config.SourceConfig
 	sourceType   string
 	sourceName   string
 	siteDir      string
-	elementType  string   // "table", "select", or "div"
-	tableColumns []string // columns for datatable rendering
-	activeFilter string   // current filter expression (empty = show all)
+	elementType  string                 // "table", "select", "chart", "heatmap", "stat", or "div"
+	tableColumns []tableColumn          // columns for datatable rendering
+	chartType    string                 // lvt-chart: bar, line, pie, ...
+	chartX       string                 // lvt-x: the field that labels each point
+	chartY       []string               // lvt-y: the fields charted, one dataset each
+	heatmapDate  string                 // lvt-date: the field holding each row's date
+	heatmapCount string                 // lvt-count: the field holding each row's count
+	heatmapOpts  HeatmapOptions         // lvt-colors and lvt-week-start
+	statOpts     StatOptions            // lvt-value, lvt-label, lvt-unit, lvt-compare and lvt-better
+	activeFilter string                 // current filter expression (empty = show all)
+	sortColumn   string                 // field Data is sorted by, kept across refreshes (empty = source order)
+	sortDesc     bool                   // sortColumn sorts in descending order
+	pageSize     int                    // rows per page (0 = no pagination)
+	printAll     bool                   // render every row, for printing, between PrintAll and PrintDone
+	pendingRun   map[string]interface{} // run data awaiting Confirm (exec sources with confirm)
+	stopStream   context.CancelFunc     // stops the running command of a streaming exec source
+	streamDirty  bool                   // output arrived since the last streamed re-render
+	onUpdate     func()                 // re-renders the block after a background change
 	mu           sync.RWMutex
 
 	// Page-level configuration for custom actions.
@@ -82,6 +125,7 @@ type Arg struct {
 	Required    bool   `json:"required"`
 	Default     string `json:"default,omitempty"`
 	Value       string `json:"value,omitempty"`
+	Secret      bool   `json:"secret,omitempty"` // Value is masked in the displayed command
 }
 
 // NewGenericState creates a new state for the given source configuration.
@@ -134,6 +178,9 @@ func NewGenericStateForComputed(name string, cfg config.SourceConfig, siteDir, c
 
 	if metadata != nil {
 		s.elementType = metadata["lvt-element"]
+		s.setChartFields(metadata)
+		s.setHeatmapFields(metadata)
+		s.setStatFields(metadata)
 	}
 
 	// Initial fetch
@@ -145,7 +192,10 @@ func NewGenericStateForComputed(name string, cfg config.SourceConfig, siteDir, c
 }
 
 // NewGenericStateWithMetadata creates a new state with block metadata for datatable support.
-// Metadata should include "lvt-element" ("table", "select", or "div") and "lvt-columns" for tables.
+// Metadata should include "lvt-element" ("table", "select", "chart", "heatmap", "stat", or
+// "div"), "lvt-columns" for tables, "lvt-chart", "lvt-x" and "lvt-y" for charts, "lvt-date",
+// "lvt-count", "lvt-colors" and "lvt-week-start" for heatmaps, and "lvt-value", "lvt-label",
+// "lvt-unit", "lvt-compare" and "lvt-better" for stat cards.
 func NewGenericStateWithMetadata(name string, cfg config.SourceConfig, siteDir, currentFile string, metadata map[string]string) (*GenericState, error) {
 	// For computed sources, use the specialized constructor
 	if cfg.Type == "computed" {
@@ -170,25 +220,41 @@ func NewGenericStateWithMetadata(name string, cfg config.SourceConfig, siteDir,
 	// Parse metadata for element type and columns
 	if metadata != nil {
 		s.elementType = metadata["lvt-element"]
+		s.setChartFields(metadata)
+		s.setHeatmapFields(metadata)
+		s.setStatFields(metadata)
 		if columns := metadata["lvt-columns"]; columns != "" {
-			// Parse "name:Name,email:Email" format
+			// Parse "name:Name,email:Email,trend:Trend:sparkline" format
 			for _, pair := range strings.Split(columns, ",") {
-				parts := strings.SplitN(pair, ":", 2)
-				if len(parts) > 0 {
-					s.tableColumns = append(s.tableColumns, parts[0])
+				field, label, _ := strings.Cut(pair, ":")
+				label, kind := cutColumnKind(label)
+				if field = strings.TrimSpace(field); field != "" {
+					s.tableColumns = append(s.tableColumns, tableColumn{field: field, label: strings.TrimSpace(label), kind: kind})
 				}
 			}
 		}
 	}
 
+	// Enable read-side pagination for markdown sources
+	if cfg.Type == "markdown" {
+		if size, err := strconv.Atoi(cfg.Options["page_size"]); err == nil && size > 0 {
+			s.pageSize = size
+			s.Page = 1
+		}
+	}
+
 	// Set exec-specific fields if applicable
 	if cfg.Type == "exec" {
-		s.Command = cfg.Cmd
 		s.Status = "ready"
 		// Parse command-line arguments for form rendering
 		s.Args = parseExecArgs(cfg.Cmd)
-		// If manual mode, don't auto-fetch
-		if cfg.Manual {
+		markSecretArgs(s.Args, cfg.GetSecretArgs())
+		s.Command = redactCommand(cfg.Cmd, s.Args)
+		// Manual, confirm and streaming sources wait for a Run
+		if cfg.Confirm {
+			s.ConfirmMessage = cfg.GetConfirmMessage()
+		}
+		if cfg.Manual || cfg.Confirm || cfg.IsStreaming() {
 			return s, nil
 		}
 	}
@@ -233,12 +299,16 @@ func createSource(name string, cfg config.SourceConfig, siteDir, currentFile str
 		return source.NewCSVFileSource(name, cfg.File, siteDir, cfg.Options)
 	case "markdown":
 		return source.NewMarkdownSource(name, cfg.File, cfg.Anchor, siteDir, currentFile, cfg.IsReadonly())
+	case "frontmatter":
+		return source.NewFrontmatterSource(name, cfg.File, siteDir, currentFile, cfg.Options)
 	case "sqlite":
 		return source.NewSQLiteSource(name, cfg.DB, cfg.Table, siteDir, cfg.IsReadonly())
 	case "wasm":
 		return wasm.NewWasmSource(name, cfg.Path, siteDir, cfg.Options)
 	case "graphql":
 		return source.NewGraphQLSource(name, cfg, siteDir)
+	case "upload":
+		return source.NewUploadSource(name, cfg, siteDir)
 	case "computed":
 		// Computed sources are handled specially — they need a parent source.
 		// The parent is created by the caller and passed via sourceLookup.
@@ -262,11 +332,42 @@ func (s *GenericState) HandleAction(action string, data map[string]interface{})
 	// Normalize action to lowercase for matching
 	actionLower := strings.ToLower(action)
 
+	// Keep a notice across the Refresh that follows the file watcher seeing
+	// the write that produced it
+	if actionLower != "refresh" {
+		s.Notice = ""
+	}
+
 	switch actionLower {
 	case "refresh":
+		// Confirm and streaming exec sources only run on Run, never on refresh
+		if s.sourceType == "exec" && (s.sourceCfg.Confirm || s.sourceCfg.IsStreaming()) {
+			return nil
+		}
 		return s.refresh()
 	case "run":
+		if s.sourceCfg.Confirm {
+			return s.requestConfirm(data)
+		}
 		return s.runExec(data)
+	case "confirm":
+		return s.confirmRun()
+	case "cancelrun":
+		if s.Status == "confirm" {
+			s.Status = "ready"
+			s.pendingRun = nil
+		}
+		if s.stopStream != nil {
+			s.stopStream()
+		}
+		return nil
+	case "printall":
+		// Show every row, unfiltered and unpaged, until PrintDone
+		s.printAll = true
+		return nil
+	case "printdone":
+		s.printAll = false
+		return nil
 	case "filter":
 		return s.handleFilter(data)
 	case "edit":
@@ -274,6 +375,13 @@ func (s *GenericState) HandleAction(action string, data map[string]interface{})
 	case "canceledit":
 		s.EditingID = ""
 		return nil
+	case "import":
+		if s.sourceType != "markdown" {
+			return fmt.Errorf("Import action only valid for markdown sources")
+		}
+		return s.handleWriteAction(action, data)
+	case "bulkdelete", "bulktoggle":
+		return s.handleBulkAction(strings.TrimPrefix(actionLower, "bulk"), data)
 	case "add", "toggle", "delete", "update":
 		err := s.handleWriteAction(action, data)
 		if err == nil {
@@ -282,9 +390,7 @@ func (s *GenericState) HandleAction(action string, data map[string]interface{})
 		return err
 	default:
 		// Check for datatable actions (Sort_X, NextPage_X, PrevPage_X)
-		if strings.HasPrefix(actionLower, "sort") ||
-			strings.HasPrefix(actionLower, "nextpage") ||
-			strings.HasPrefix(actionLower, "prevpage") {
+		if isDatatableAction(actionLower) {
 			return s.handleDatatableAction(action, data)
 		}
 
@@ -297,6 +403,60 @@ func (s *GenericState) HandleAction(action string, data map[string]interface{})
 	}
 }
 
+// writableSourceTypes are the source types whose sources implement
+// source.WritableSource and so handle Add, Toggle, Delete and Update.
+// Markdown sources also handle Import, BulkDelete and BulkToggle.
+var writableSourceTypes = map[string]bool{"markdown": true, "sqlite": true}
+
+// datatableActions are handled by handleDatatableAction. They may carry a
+// suffix naming their target, e.g. Sort_title.
+var datatableActions = []string{"Sort", "NextPage", "PrevPage"}
+
+// isDatatableAction reports whether a lowercased action is a datatable action.
+func isDatatableAction(actionLower string) bool {
+	for _, a := range datatableActions {
+		if strings.HasPrefix(actionLower, strings.ToLower(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuiltinActions returns the actions HandleAction handles itself for a source
+// of the given type, so templates can be checked before they run. Write
+// actions are only listed for writable sources that aren't read-only, Import
+// and the bulk actions only for markdown sources and Run only for exec
+// sources. Custom actions declared in frontmatter aren't included.
+func BuiltinActions(sourceType string, readonly bool) []string {
+	actions := []string{"Refresh", "Filter", "Edit", "CancelEdit", "PrintAll", "PrintDone"}
+	if sourceType == "exec" {
+		actions = append(actions, "Run", "Confirm", "CancelRun")
+	}
+	if writableSourceTypes[sourceType] && !readonly {
+		actions = append(actions, "Add", "Toggle", "Delete", "Update")
+		if sourceType == "markdown" {
+			actions = append(actions, "Import", "BulkDelete", "BulkToggle")
+		}
+	}
+	return append(actions, datatableActions...)
+}
+
+// IsBuiltinAction reports whether HandleAction handles action for a source of
+// the given type. Like HandleAction, it ignores case and accepts datatable
+// actions with a suffix.
+func IsBuiltinAction(action, sourceType string, readonly bool) bool {
+	actionLower := strings.ToLower(action)
+	if isDatatableAction(actionLower) {
+		return true
+	}
+	for _, a := range BuiltinActions(sourceType, readonly) {
+		if strings.ToLower(a) == actionLower {
+			return true
+		}
+	}
+	return false
+}
+
 // GetState returns the current state for template rendering.
 // This replaces the RPC GetState() call.
 func (s *GenericState) GetState() (json.RawMessage, error) {
@@ -322,15 +482,18 @@ func (s *GenericState) GetStateAsInterface() (interface{}, error) {
 		return nil, err
 	}
 
-	// Apply active filter to data if present
-	if s.activeFilter != "" {
-		filteredData := s.GetFilteredData()
-		rawMap["data"] = filteredData
+	// Apply active filter and pagination to data if present, unless every
+	// row is being printed
+	if !s.printAll && (s.activeFilter != "" || s.pageSize > 0) {
+		rawMap["data"] = s.getPagedData(s.GetFilteredData())
 	}
 
 	// Process state map to add titlecase keys for template access
 	// This allows templates to use both {{.data}} and {{.Data}}
-	return processStateMap(rawMap), nil
+	state := processStateMap(rawMap)
+	s.addSparklines(state)
+	s.addHeatmap(state)
+	return state, nil
 }
 
 // processStateMap processes a map to add titlecase keys alongside lowercase keys.
@@ -370,13 +533,10 @@ func processStateMap(m map[string]interface{}) map[string]interface{} {
 		// Keep original key (e.g., "status", "data", "items")
 		result[k] = processedValue
 
-		// Also add titlecased key if different (e.g., "Status", "Data", "Items")
+		// Also add the template key if different (e.g., "Status", "Data", "CacheInfo")
 		// This allows templates to use either {{.status}} or {{.Status}}
-		if len(k) > 0 {
-			titleKey := strings.ToUpper(k[:1]) + k[1:]
-			if titleKey != k {
-				result[titleKey] = processedValue
-			}
+		if tk := snakeToPascal(k); tk != k {
+			result[tk] = processedValue
 		}
 	}
 	return result
@@ -411,11 +571,8 @@ func processMapValues(m map[string]interface{}) map[string]interface{} {
 		result[k] = processedValue
 
 		// Also add PascalCase key if different (converts snake_case to PascalCase)
-		if len(k) > 0 {
-			pascalKey := snakeToPascal(k)
-			if pascalKey != k {
-				result[pascalKey] = processedValue
-			}
+		if pascalKey := snakeToPascal(k); pascalKey != k {
+			result[pascalKey] = processedValue
 		}
 	}
 	return result
@@ -423,6 +580,10 @@ func processMapValues(m map[string]interface{}) map[string]interface{} {
 
 // snakeToPascal converts snake_case to PascalCase.
 // Examples: "assigned_to" -> "AssignedTo", "id" -> "Id", "my_field_name" -> "MyFieldName"
+//
+// It is the one key normalization used for data fields: templates see it as
+// the alias of every key, and filters, sorting and datatable columns match a
+// field name to a row key when both normalize to the same string.
 func snakeToPascal(s string) string {
 	if s == "" {
 		return s
@@ -458,8 +619,25 @@ func convertNumber(v interface{}) interface{} {
 	return v
 }
 
-// Close releases any resources held by the source.
+// SetOnUpdate sets the function that re-renders the block when the state
+// changes outside an action, as when a streaming exec source prints a line.
+// It is called without the state's lock held.
+func (s *GenericState) SetOnUpdate(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onUpdate = fn
+}
+
+// Close releases any resources held by the source and stops a streaming
+// command.
 func (s *GenericState) Close() error {
+	s.mu.Lock()
+	s.onUpdate = nil
+	if s.stopStream != nil {
+		s.stopStream()
+	}
+	s.mu.Unlock()
+
 	if s.source != nil {
 		return s.source.Close()
 	}
@@ -473,29 +651,113 @@ func (s *GenericState) refresh() error {
 		return nil
 	}
 
-	ctx := context.Background()
+	ctx, span := tracing.Start(context.Background(), "source.fetch",
+		tracing.AttrSource.String(s.sourceName), tracing.AttrSourceType.String(s.sourceType))
+	start := time.Now()
 	data, err := s.source.Fetch(ctx)
+	metrics.ObserveSourceFetch(s.sourceName, time.Since(start), err)
 	if err != nil {
 		s.Error = err.Error()
+		tracing.End(span, err)
 		return err
 	}
 
+	// Keep the sort and page while the rows have the same fields, so
+	// polling doesn't undo them; updatePagination clamps the page
+	if !sameFields(s.Data, data) {
+		s.sortColumn, s.sortDesc = "", false
+		if s.pageSize > 0 {
+			s.Page = 1
+		}
+		if s.Table != nil {
+			s.Table.FirstPage()
+		}
+	}
 	s.Data = data
+	s.applySort()
 	s.Error = ""
+	s.updateCounts()
+	s.noteTruncation()
 
 	// Populate CacheInfo if source supports it
 	if provider, ok := s.source.(source.CacheInfoProvider); ok {
 		s.CacheInfo = provider.GetCacheInfo()
+		span.SetAttributes(tracing.AttrCacheHit.Bool(s.CacheInfo.Cached))
 	}
+	span.SetAttributes(tracing.AttrRows.Int(len(data)))
+	tracing.End(span, nil)
 
 	// Build DataTable if this is a table element
 	if s.elementType == "table" {
 		s.Table = s.buildDataTable()
 	}
+	if s.elementType == "chart" {
+		s.buildChart()
+	}
+	if s.elementType == "stat" {
+		card := NewStatCard(s.Data, s.statOpts)
+		s.Stat = &card
+	}
+
+	s.updatePagination()
 
 	return nil
 }
 
+// updateCounts recalculates Total, Done and Remaining from Data. Rows with
+// a boolean done field are task list items.
+func (s *GenericState) updateCounts() {
+	s.Total = len(s.Data)
+	s.Done, s.Remaining = 0, 0
+	for _, row := range s.Data {
+		if done, ok := row["done"].(bool); ok {
+			if done {
+				s.Done++
+			} else {
+				s.Remaining++
+			}
+		}
+	}
+}
+
+// updatePagination recalculates page counts from the (filtered) data and
+// clamps the current page into range. No-op when pagination is disabled.
+func (s *GenericState) updatePagination() {
+	if s.pageSize <= 0 {
+		return
+	}
+
+	s.TotalCount = len(s.GetFilteredData())
+	s.TotalPages = (s.TotalCount + s.pageSize - 1) / s.pageSize
+	if s.TotalPages < 1 {
+		s.TotalPages = 1
+	}
+	if s.Page < 1 {
+		s.Page = 1
+	}
+	if s.Page > s.TotalPages {
+		s.Page = s.TotalPages
+	}
+}
+
+// getPagedData returns the rows for the current page.
+// Returns data unchanged when pagination is disabled.
+func (s *GenericState) getPagedData(data []map[string]interface{}) []map[string]interface{} {
+	if s.pageSize <= 0 {
+		return data
+	}
+
+	start := (s.Page - 1) * s.pageSize
+	if start < 0 || start >= len(data) {
+		return []map[string]interface{}{}
+	}
+	end := start + s.pageSize
+	if end > len(data) {
+		end = len(data)
+	}
+	return data[start:end]
+}
+
 // handleEdit sets the editing ID to the row being edited.
 // The template checks EditingID to show inputs vs display text.
 func (s *GenericState) handleEdit(data map[string]interface{}) error {
@@ -531,6 +793,12 @@ func (s *GenericState) handleFilter(data map[string]interface{}) error {
 	// Store the active filter
 	s.activeFilter = filter
 
+	// Filtering changes the row count, so start again from the first page
+	if s.pageSize > 0 {
+		s.Page = 1
+		s.updatePagination()
+	}
+
 	// Re-render with filtered data
 	// Note: The actual filtering happens in GetFilteredData which is called during render
 	return nil
@@ -600,61 +868,23 @@ func filterDataByWhere(data []map[string]interface{}, where *WhereClause) []map[
 	return result
 }
 
-// buildDataTable creates a datatable.DataTable from the current Data
+// buildDataTable returns the datatable for the current Data. Table is
+// updated in place when there is one, so a refresh keeps the instance the
+// template renders; it starts over only when Data is empty.
 func (s *GenericState) buildDataTable() *datatable.DataTable {
 	if len(s.Data) == 0 {
 		return nil
 	}
 
-	// Determine columns - use explicit columns or auto-discover from first row
-	var columns []datatable.Column
-	if len(s.tableColumns) > 0 {
-		for _, col := range s.tableColumns {
-			label := col
-			if len(label) > 0 {
-				label = strings.ToUpper(label[:1]) + label[1:]
-			}
-			columns = append(columns, datatable.Column{
-				ID:       col,
-				Label:    label,
-				Sortable: true,
-			})
-		}
-	} else {
-		// Auto-discover columns from first row
-		for key := range s.Data[0] {
-			// Skip internal fields starting with uppercase (title-cased duplicates)
-			if len(key) > 0 && key[0] >= 'A' && key[0] <= 'Z' {
-				continue
-			}
-			label := key
-			if len(label) > 0 {
-				label = strings.ToUpper(label[:1]) + label[1:]
-			}
-			columns = append(columns, datatable.Column{
-				ID:       key,
-				Label:    label,
-				Sortable: true,
-			})
-		}
-	}
+	columns := s.dataTableColumns()
 
-	// Build rows
+	// Build rows, looking cells up the same way filters do
 	var rows []datatable.Row
 	for i, item := range s.Data {
 		data := make(map[string]any)
 		for _, col := range columns {
-			if val, ok := item[col.ID]; ok {
+			if val := getFieldValue(item, col.ID); val != nil {
 				data[col.ID] = val
-			} else {
-				// Try titlecase key
-				titleKey := col.ID
-				if len(titleKey) > 0 {
-					titleKey = strings.ToUpper(titleKey[:1]) + titleKey[1:]
-				}
-				if val, ok := item[titleKey]; ok {
-					data[col.ID] = val
-				}
 			}
 		}
 		// Generate row ID
@@ -667,7 +897,154 @@ func (s *GenericState) buildDataTable() *datatable.DataTable {
 		rows = append(rows, datatable.Row{ID: rowID, Data: data})
 	}
 
-	return datatable.New(s.sourceName, datatable.WithColumns(columns), datatable.WithRows(rows))
+	direction := datatable.SortNone
+	if s.sortColumn != "" {
+		direction = datatable.SortAsc
+		if s.sortDesc {
+			direction = datatable.SortDesc
+		}
+	}
+	if s.Table == nil {
+		return datatable.New(s.sourceName, datatable.WithColumns(columns), datatable.WithRows(rows),
+			datatable.WithSort(s.sortColumn, direction))
+	}
+	s.Table.Columns = columns
+	s.Table.SetData(rows)
+	s.Table.SortColumn, s.Table.SortDirection = s.sortColumn, direction
+	if s.Table.Page >= s.Table.TotalPages() {
+		s.Table.LastPage()
+	}
+	return s.Table
+}
+
+// sameFields reports whether the first rows of a and b have the same
+// fields, as they do when a source's rows change but its columns don't.
+func sameFields(a, b []map[string]interface{}) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	if len(a[0]) != len(b[0]) {
+		return false
+	}
+	for key := range a[0] {
+		if _, ok := b[0][key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// dataTableColumns returns the explicit lvt-columns, or columns discovered
+// from the first row. Discovered columns keep the order Table already has
+// while the row's fields are the same, as map order would shuffle them.
+func (s *GenericState) dataTableColumns() []datatable.Column {
+	var columns []datatable.Column
+	if len(s.tableColumns) > 0 {
+		for _, col := range s.tableColumns {
+			label := col.label
+			if label == "" {
+				label = columnLabel(col.field)
+			}
+			columns = append(columns, datatable.Column{
+				ID:       col.field,
+				Label:    label,
+				Sortable: true,
+			})
+		}
+		return columns
+	}
+
+	fields := make(map[string]bool)
+	for key := range s.Data[0] {
+		// Skip template aliases of other keys (e.g. "FirstName" for "first_name")
+		if !isKeyAlias(s.Data[0], key) {
+			fields[key] = true
+		}
+	}
+	if s.Table != nil && len(s.Table.Columns) == len(fields) {
+		same := true
+		for _, col := range s.Table.Columns {
+			same = same && fields[col.ID]
+		}
+		if same {
+			return s.Table.Columns
+		}
+	}
+	for key := range fields {
+		columns = append(columns, datatable.Column{
+			ID:       key,
+			Label:    columnLabel(key),
+			Sortable: true,
+		})
+	}
+	return columns
+}
+
+// setChartFields reads the lvt-chart, lvt-x and lvt-y attributes of a
+// chart element from block metadata. lvt-y may list several fields.
+func (s *GenericState) setChartFields(metadata map[string]string) {
+	s.chartType = metadata["lvt-chart"]
+	s.chartX = strings.TrimSpace(metadata["lvt-x"])
+	for _, field := range strings.Split(metadata["lvt-y"], ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			s.chartY = append(s.chartY, field)
+		}
+	}
+}
+
+// buildChart sets Chart to the Chart.js config for the current Data, which
+// the client redraws the chart from each time it changes.
+func (s *GenericState) buildChart() {
+	cfg, err := NewSourceChartConfig(s.chartType, s.Data, s.chartX, s.chartY, ChartOptions{})
+	if err != nil {
+		s.Chart = ""
+		s.Error = err.Error()
+		return
+	}
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		s.Chart = ""
+		s.Error = fmt.Sprintf("failed to encode chart: %v", err)
+		return
+	}
+	s.Chart = string(configJSON)
+}
+
+// tableColumn is one column from an lvt-columns attribute ("field:Label",
+// or "field:Label:kind").
+type tableColumn struct {
+	field string
+	label string // Empty when not given; derived from field
+	kind  string // "sparkline", or empty for a plain value
+}
+
+// cutColumnKind splits a trailing column kind off an lvt-columns label, as in
+// "Trend:sparkline". Labels without a known kind are returned as they are.
+func cutColumnKind(label string) (string, string) {
+	if i := strings.LastIndex(label, ":"); i >= 0 && strings.TrimSpace(label[i+1:]) == "sparkline" {
+		return label[:i], "sparkline"
+	}
+	return label, ""
+}
+
+// columnLabel derives a column heading from a field name.
+// Examples: "name" -> "Name", "first_name" -> "First Name"
+func columnLabel(field string) string {
+	words := strings.Fields(strings.ReplaceAll(field, "_", " "))
+	for i, w := range words {
+		words[i] = snakeToPascal(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// isKeyAlias reports whether key is the normalized alias of another key in row.
+func isKeyAlias(row map[string]interface{}, key string) bool {
+	for k := range row {
+		if k != key && snakeToPascal(k) == key {
+			return true
+		}
+	}
+	return false
 }
 
 // parseExecArgs parses command-line arguments from a command string.
@@ -675,8 +1052,8 @@ func (s *GenericState) buildDataTable() *datatable.DataTable {
 // Example: "./script.sh --name World --count 3 --verbose true"
 // Returns Args with Name, Label, Type, and Value set.
 func parseExecArgs(cmd string) []Arg {
-	parts := strings.Fields(cmd)
-	if len(parts) == 0 {
+	parts, err := source.SplitCommand(cmd)
+	if err != nil || len(parts) == 0 {
 		return nil
 	}
 
@@ -730,6 +1107,58 @@ func parseExecArgs(cmd string) []Arg {
 	return args
 }
 
+// secretArgPattern matches exec argument names whose values are secrets
+var secretArgPattern = regexp.MustCompile(`(?i)token|passw(or)?d|secret|api[-_]?key|private[-_]?key|credential|(^|[-_])auth($|[-_])`)
+
+// redactedValue replaces secret argument values in displayed commands
+const redactedValue = "***"
+
+// markSecretArgs flags args whose name looks secret or is listed in names.
+// For a --name=value flag only the part before "=" counts.
+func markSecretArgs(args []Arg, names []string) {
+	for i := range args {
+		name, _, _ := strings.Cut(args[i].Name, "=")
+		args[i].Secret = secretArgPattern.MatchString(name) || slices.Contains(names, name)
+	}
+}
+
+// redactCommand returns cmd for display with the values of secret args
+// masked. Without secret args it returns cmd unchanged.
+func redactCommand(cmd string, args []Arg) string {
+	secret := make(map[string]bool)
+	for _, arg := range args {
+		if arg.Secret {
+			secret[arg.Name] = true
+		}
+	}
+	if len(secret) == 0 {
+		return cmd
+	}
+	parts, err := source.SplitCommand(cmd)
+	if err != nil {
+		return cmd
+	}
+
+	// Match parseExecArgs: a flag's value is the next part unless it's a flag
+	for i := 1; i < len(parts); i++ {
+		if !strings.HasPrefix(parts[i], "-") {
+			continue
+		}
+		name := strings.TrimLeft(parts[i], "-")
+		if eq := strings.Index(parts[i], "="); eq >= 0 {
+			if secret[name] {
+				parts[i] = parts[i][:eq+1] + redactedValue
+			}
+			continue
+		}
+		if secret[name] && i+1 < len(parts) && !strings.HasPrefix(parts[i+1], "-") {
+			i++
+			parts[i] = redactedValue
+		}
+	}
+	return source.JoinCommand(parts)
+}
+
 // isNumeric checks if a string represents a number
 func isNumeric(s string) bool {
 	if s == "" {
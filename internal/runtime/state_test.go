@@ -0,0 +1,751 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/livetemplate/lvt/components/datatable"
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// writeTaskFile writes a markdown file with n task items under a #tasks heading
+func writeTaskFile(t *testing.T, dir string, n int) {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("# Tasks {#tasks}\n\n")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "- [ ] Task %d <!-- id:t%d -->\n", i, i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tasks.md"), []byte(b.String()), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+}
+
+// pagedData returns the data slice as rendered to templates
+func pagedData(t *testing.T, s *GenericState) []interface{} {
+	t.Helper()
+	state, err := s.GetStateAsInterface()
+	if err != nil {
+		t.Fatalf("GetStateAsInterface() error = %v", err)
+	}
+	data, _ := state.(map[string]interface{})["data"].([]interface{})
+	return data
+}
+
+func TestMarkdownPagination(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 7)
+
+	cfg := config.SourceConfig{
+		Type:    "markdown",
+		File:    "tasks.md",
+		Anchor:  "#tasks",
+		Options: map[string]string{"page_size": "3"},
+	}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	if s.Page != 1 || s.TotalPages != 3 || s.TotalCount != 7 {
+		t.Fatalf("got page=%d totalPages=%d totalCount=%d, want 1/3/7", s.Page, s.TotalPages, s.TotalCount)
+	}
+
+	data := pagedData(t, s)
+	if len(data) != 3 || data[0].(map[string]interface{})["id"] != "t1" {
+		t.Errorf("page 1 = %v, want t1..t3", data)
+	}
+
+	// Advance to the last page, which holds the remainder
+	for i := 0; i < 2; i++ {
+		if err := s.HandleAction("NextPage", nil); err != nil {
+			t.Fatalf("HandleAction(NextPage) error = %v", err)
+		}
+	}
+	data = pagedData(t, s)
+	if s.Page != 3 || len(data) != 1 || data[0].(map[string]interface{})["id"] != "t7" {
+		t.Errorf("page %d = %v, want page 3 with only t7", s.Page, data)
+	}
+
+	// NextPage on the last page stays put
+	if err := s.HandleAction("NextPage", nil); err != nil {
+		t.Fatalf("HandleAction(NextPage) error = %v", err)
+	}
+	if s.Page != 3 {
+		t.Errorf("page = %d after NextPage on last page, want 3", s.Page)
+	}
+
+	if err := s.HandleAction("PrevPage", nil); err != nil {
+		t.Fatalf("HandleAction(PrevPage) error = %v", err)
+	}
+	data = pagedData(t, s)
+	if s.Page != 2 || len(data) != 3 || data[0].(map[string]interface{})["id"] != "t4" {
+		t.Errorf("page %d = %v, want page 2 starting at t4", s.Page, data)
+	}
+
+	// Refresh keeps the page while the rows have the same fields
+	if err := s.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("HandleAction(Refresh) error = %v", err)
+	}
+	if s.Page != 2 {
+		t.Errorf("page = %d after Refresh, want 2", s.Page)
+	}
+	if err := s.HandleAction("PrevPage", nil); err != nil {
+		t.Fatalf("HandleAction(PrevPage) error = %v", err)
+	}
+
+	// PrevPage on the first page stays put
+	if err := s.HandleAction("PrevPage", nil); err != nil {
+		t.Fatalf("HandleAction(PrevPage) error = %v", err)
+	}
+	if s.Page != 1 {
+		t.Errorf("page = %d after PrevPage on first page, want 1", s.Page)
+	}
+}
+
+func TestRefreshKeepsSortAndPage(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 7)
+	cfg := config.SourceConfig{
+		Type:    "markdown",
+		File:    "tasks.md",
+		Anchor:  "#tasks",
+		Options: map[string]string{"page_size": "3"},
+	}
+	s, err := NewGenericStateWithMetadata("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"), map[string]string{"lvt-element": "table"})
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+
+	// Sorting twice by the same column sorts in descending order
+	for i := 0; i < 2; i++ {
+		if err := s.HandleAction("Sort", map[string]interface{}{"column": "id"}); err != nil {
+			t.Fatalf("HandleAction(Sort) error = %v", err)
+		}
+	}
+	if err := s.HandleAction("NextPage", nil); err != nil {
+		t.Fatalf("HandleAction(NextPage) error = %v", err)
+	}
+	if err := s.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("HandleAction(Refresh) error = %v", err)
+	}
+	data := pagedData(t, s)
+	if s.Page != 2 || len(data) != 3 || data[0].(map[string]interface{})["id"] != "t4" {
+		t.Errorf("after Refresh: page %d = %v, want page 2 of the descending order, starting at t4", s.Page, data)
+	}
+	if s.Table.SortColumn != "id" || s.Table.SortDirection != datatable.SortDesc {
+		t.Errorf("datatable sort = %q %v, want id descending", s.Table.SortColumn, s.Table.SortDirection)
+	}
+	if s.Table.Rows[0].ID != "t7" {
+		t.Errorf("first datatable row = %s, want t7", s.Table.Rows[0].ID)
+	}
+
+	// Fewer rows clamp the page to the last one
+	writeTaskFile(t, tmpDir, 2)
+	if err := s.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("HandleAction(Refresh) error = %v", err)
+	}
+	data = pagedData(t, s)
+	if s.Page != 1 || len(data) != 2 || data[0].(map[string]interface{})["id"] != "t2" {
+		t.Errorf("after the rows shrank: page %d = %v, want page 1 starting at t2", s.Page, data)
+	}
+}
+
+func TestMarkdownPaginationExactMultiple(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 6)
+
+	cfg := config.SourceConfig{
+		Type:    "markdown",
+		File:    "tasks.md",
+		Anchor:  "#tasks",
+		Options: map[string]string{"page_size": "3"},
+	}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	if s.TotalPages != 2 || s.TotalCount != 6 {
+		t.Errorf("got totalPages=%d totalCount=%d, want 2/6", s.TotalPages, s.TotalCount)
+	}
+}
+
+func TestMarkdownPaginationDisabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 7)
+
+	cfg := config.SourceConfig{
+		Type:   "markdown",
+		File:   "tasks.md",
+		Anchor: "#tasks",
+	}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	if s.Page != 0 || s.TotalPages != 0 {
+		t.Errorf("pagination fields should be unset, got page=%d totalPages=%d", s.Page, s.TotalPages)
+	}
+	if data := pagedData(t, s); len(data) != 7 {
+		t.Errorf("expected all 7 rows without page_size, got %d", len(data))
+	}
+}
+
+func TestMarkdownPaginationWithFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "# Tasks {#tasks}\n\n" +
+		"- [x] Done 1 <!-- id:d1 -->\n" +
+		"- [ ] Open 1 <!-- id:o1 -->\n" +
+		"- [x] Done 2 <!-- id:d2 -->\n" +
+		"- [ ] Open 2 <!-- id:o2 -->\n" +
+		"- [x] Done 3 <!-- id:d3 -->\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "tasks.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	cfg := config.SourceConfig{
+		Type:    "markdown",
+		File:    "tasks.md",
+		Anchor:  "#tasks",
+		Options: map[string]string{"page_size": "2"},
+	}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	if err := s.HandleAction("NextPage", nil); err != nil {
+		t.Fatalf("HandleAction(NextPage) error = %v", err)
+	}
+	if err := s.HandleAction("Filter", map[string]interface{}{"filter": "done"}); err != nil {
+		t.Fatalf("HandleAction(Filter) error = %v", err)
+	}
+
+	if s.Page != 1 || s.TotalCount != 3 || s.TotalPages != 2 {
+		t.Errorf("got page=%d totalCount=%d totalPages=%d, want 1/3/2", s.Page, s.TotalCount, s.TotalPages)
+	}
+	data := pagedData(t, s)
+	if len(data) != 2 || data[0].(map[string]interface{})["id"] != "d1" || data[1].(map[string]interface{})["id"] != "d2" {
+		t.Errorf("filtered page 1 = %v, want d1, d2", data)
+	}
+}
+
+func TestPrintAll(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 7)
+
+	cfg := config.SourceConfig{
+		Type:    "markdown",
+		File:    "tasks.md",
+		Anchor:  "#tasks",
+		Options: map[string]string{"page_size": "3"},
+	}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if err := s.HandleAction("NextPage", nil); err != nil {
+		t.Fatalf("HandleAction(NextPage) error = %v", err)
+	}
+	if err := s.HandleAction("Filter", map[string]interface{}{"filter": "done"}); err != nil {
+		t.Fatalf("HandleAction(Filter) error = %v", err)
+	}
+	viewed := len(pagedData(t, s))
+
+	if err := s.HandleAction("PrintAll", nil); err != nil {
+		t.Fatalf("HandleAction(PrintAll) error = %v", err)
+	}
+	if data := pagedData(t, s); len(data) != 7 {
+		t.Errorf("got %d rows while printing, want all 7", len(data))
+	}
+
+	// PrintDone restores the filtered page
+	if err := s.HandleAction("PrintDone", nil); err != nil {
+		t.Fatalf("HandleAction(PrintDone) error = %v", err)
+	}
+	if data := pagedData(t, s); len(data) != viewed {
+		t.Errorf("got %d rows after printing, want the %d viewed before", len(data), viewed)
+	}
+}
+
+func TestIsBuiltinAction(t *testing.T) {
+	tests := []struct {
+		action     string
+		sourceType string
+		readonly   bool
+		want       bool
+	}{
+		{"Refresh", "json", true, true},
+		{"refresh", "rest", true, true},
+		{"Run", "exec", true, true},
+		{"Run", "sqlite", false, false},
+		{"Toggle", "markdown", false, true},
+		{"Toggle", "markdown", true, false},
+		{"Delete", "json", false, false},
+		{"Sort_title", "csv", true, true},
+		{"NextPage", "sqlite", true, true},
+		{"Toggl", "markdown", false, false},
+		{"Import", "markdown", false, true},
+		{"Import", "markdown", true, false},
+		{"Import", "sqlite", false, false},
+		{"BulkDelete", "markdown", false, true},
+		{"bulktoggle", "markdown", true, false},
+		{"BulkDelete", "sqlite", false, false},
+		{"Confirm", "exec", true, true},
+		{"CancelRun", "exec", true, true},
+		{"Confirm", "markdown", false, false},
+		{"PrintAll", "rest", true, true},
+		{"printdone", "json", true, true},
+	}
+	for _, tt := range tests {
+		if got := IsBuiltinAction(tt.action, tt.sourceType, tt.readonly); got != tt.want {
+			t.Errorf("IsBuiltinAction(%q, %q, %v) = %v, want %v", tt.action, tt.sourceType, tt.readonly, got, tt.want)
+		}
+	}
+}
+
+func TestImportAction(t *testing.T) {
+	tmpDir := t.TempDir()
+	table := "# Products {#products}\n\n| Name | Price |\n|------|-------|\n| Widget | $10 | <!-- id:p1 -->\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "products.md"), []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readonly := false
+	cfg := config.SourceConfig{Type: "markdown", File: "products.md", Anchor: "#products", Readonly: &readonly}
+	s, err := NewGenericState("products", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	// CSV is written as-is, not resolved as a template
+	if err := s.HandleAction("Import", map[string]interface{}{"csv": "name,price\n{{.operator}},$5\n"}); err != nil {
+		t.Fatalf("HandleAction(Import) error = %v", err)
+	}
+	if len(s.Data) != 2 || s.Data[1]["Name"] != "{{.operator}}" {
+		t.Errorf("Data after import = %v", s.Data)
+	}
+
+	err = s.HandleAction("Import", map[string]interface{}{"csv": "Name,Qty\nSprocket,3\n"})
+	if err == nil {
+		t.Fatal("HandleAction(Import) with an unknown column succeeded")
+	}
+	if !strings.Contains(s.Error, `column "Qty" does not match a table header (Name, Price)`) {
+		t.Errorf("Error = %q, want the mismatched column and table headers", s.Error)
+	}
+}
+
+func TestBulkActions(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 3)
+	readonly := false
+	cfg := config.SourceConfig{Type: "markdown", File: "tasks.md", Anchor: "#tasks", Readonly: &readonly}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	if err := s.HandleAction("BulkDelete", map[string]interface{}{"ids": "t1, t3"}); err != nil {
+		t.Fatalf("HandleAction(BulkDelete) error = %v", err)
+	}
+	if len(s.Data) != 1 || s.Data[0]["id"] != "t2" {
+		t.Fatalf("Data after bulk delete = %v, want only t2", s.Data)
+	}
+	if s.Notice != "" {
+		t.Errorf("Notice = %q, want none", s.Notice)
+	}
+
+	// IDs that are already gone are skipped and counted
+	if err := s.HandleAction("BulkToggle", map[string]interface{}{"ids": "t1,t2"}); err != nil {
+		t.Fatalf("HandleAction(BulkToggle) error = %v", err)
+	}
+	if s.Data[0]["done"] != true {
+		t.Errorf("t2 done = %v after bulk toggle, want true", s.Data[0]["done"])
+	}
+	if s.Notice != "Skipped 1 of 2 items that no longer exist" {
+		t.Errorf("Notice = %q", s.Notice)
+	}
+
+	if err := s.HandleAction("BulkDelete", map[string]interface{}{"ids": " , "}); err == nil || s.Error == "" {
+		t.Errorf("BulkDelete without ids: error = %v, Error = %q", err, s.Error)
+	}
+}
+
+func TestItemCounts(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, tmpDir, 3)
+	readonly := false
+	cfg := config.SourceConfig{Type: "markdown", File: "tasks.md", Anchor: "#tasks", Readonly: &readonly}
+	s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if s.Total != 3 || s.Done != 0 || s.Remaining != 3 {
+		t.Errorf("initial counts = %d/%d/%d, want 3/0/3", s.Total, s.Done, s.Remaining)
+	}
+
+	if err := s.HandleAction("Toggle", map[string]interface{}{"id": "t2"}); err != nil {
+		t.Fatalf("HandleAction(Toggle) error = %v", err)
+	}
+	state, err := s.GetStateAsInterface()
+	if err != nil {
+		t.Fatalf("GetStateAsInterface() error = %v", err)
+	}
+	m := state.(map[string]interface{})
+	if got := fmt.Sprint(m["Total"], "/", m["Done"], "/", m["Remaining"]); got != "3/1/2" {
+		t.Errorf("counts after toggle = %s, want 3/1/2", got)
+	}
+
+	// Tables only have a total
+	table := "# Products {#products}\n\n| Name | Done |\n|------|------|\n| Widget | yes | <!-- id:p1 -->\n| Gadget | no | <!-- id:p2 -->\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "products.md"), []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg = config.SourceConfig{Type: "markdown", File: "products.md", Anchor: "#products"}
+	s, err = NewGenericState("products", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if s.Total != 2 || s.Done != 0 || s.Remaining != 0 {
+		t.Errorf("table counts = %d/%d/%d, want 2/0/0", s.Total, s.Done, s.Remaining)
+	}
+}
+
+func TestParseExecArgsQuotedValues(t *testing.T) {
+	args := parseExecArgs(`./greet.sh --name "Jane Doe" --pattern '^a\d+$' --count 3`)
+	want := map[string]string{"name": "Jane Doe", "pattern": `^a\d+$`, "count": "3"}
+	if len(args) != len(want) {
+		t.Fatalf("parseExecArgs() = %+v, want %d args", args, len(want))
+	}
+	for _, arg := range args {
+		if arg.Value != want[arg.Name] {
+			t.Errorf("arg %q = %q, want %q", arg.Name, arg.Value, want[arg.Name])
+		}
+	}
+
+	// The displayed command quotes values so it can be copied and rerun
+	if got := buildCommandString("./greet.sh --name x", args); got != `./greet.sh --name 'Jane Doe' --pattern '^a\d+$' --count 3` {
+		t.Errorf("buildCommandString() = %q", got)
+	}
+}
+
+func TestExecSecretArgsRedacted(t *testing.T) {
+	origState := config.IsExecAllowed()
+	defer config.SetAllowExec(origState)
+	config.SetAllowExec(true)
+
+	// The script reports the arguments it actually received
+	tmpDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '[{\"args\":\"%s\"}]' \"$*\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "deploy.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.SourceConfig{
+		Type:    "exec",
+		Cmd:     "./deploy.sh --token abc123 --region eu --pin 1234",
+		Manual:  true,
+		Options: map[string]string{"secret_args": "pin"},
+	}
+	s, err := NewGenericState("deploy", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if s.Command != "./deploy.sh --token *** --region eu --pin ***" {
+		t.Errorf("initial Command = %q, want secrets masked", s.Command)
+	}
+
+	err = s.HandleAction("Run", map[string]interface{}{"token": "real-token", "region": "us", "pin": "9876"})
+	if err != nil {
+		t.Fatalf("HandleAction(Run) error = %v", err)
+	}
+	if s.Command != "./deploy.sh --token *** --region us --pin ***" {
+		t.Errorf("Command after Run = %q, want secrets masked", s.Command)
+	}
+	if len(s.Data) != 1 || s.Data[0]["args"] != "--pin 9876 --region us --token real-token" {
+		t.Errorf("command ran with %v, want the real values", s.Data)
+	}
+}
+
+func TestExecConfirm(t *testing.T) {
+	origState := config.IsExecAllowed()
+	defer config.SetAllowExec(origState)
+	config.SetAllowExec(true)
+
+	// The script leaves a marker behind so the test can tell whether it ran
+	tmpDir := t.TempDir()
+	marker := filepath.Join(tmpDir, "ran")
+	script := "#!/bin/sh\necho x >> ran\nprintf '[{\"env\":\"%s\"}]' \"$2\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "deploy.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runs := func() int {
+		content, err := os.ReadFile(marker)
+		if err != nil {
+			return 0
+		}
+		return strings.Count(string(content), "x")
+	}
+
+	cfg := config.SourceConfig{
+		Type:       "exec",
+		Cmd:        "./deploy.sh --env staging",
+		Confirm:    true,
+		ConfirmMsg: "Deploy now?",
+	}
+	s, err := NewGenericState("deploy", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if runs() != 0 || s.Status != "ready" || s.ConfirmMessage != "Deploy now?" {
+		t.Fatalf("after load: runs=%d Status=%q ConfirmMessage=%q, want no run", runs(), s.Status, s.ConfirmMessage)
+	}
+	if err := s.HandleAction("Refresh", nil); err != nil || runs() != 0 {
+		t.Fatalf("Refresh error = %v, runs = %d, want no run", err, runs())
+	}
+
+	// Run only asks; Confirm runs with the values given to Run
+	if err := s.HandleAction("Run", map[string]interface{}{"env": "prod"}); err != nil {
+		t.Fatalf("HandleAction(Run) error = %v", err)
+	}
+	if s.Status != "confirm" || runs() != 0 {
+		t.Fatalf("after Run: Status=%q runs=%d, want confirm and no run", s.Status, runs())
+	}
+	if err := s.HandleAction("Confirm", nil); err != nil {
+		t.Fatalf("HandleAction(Confirm) error = %v", err)
+	}
+	if s.Status != "success" || runs() != 1 || len(s.Data) != 1 || s.Data[0]["env"] != "prod" {
+		t.Errorf("after Confirm: Status=%q runs=%d Data=%v", s.Status, runs(), s.Data)
+	}
+
+	// A confirmation can't be replayed, and a cancelled run never happens
+	if err := s.HandleAction("Confirm", nil); err == nil {
+		t.Error("Confirm without a pending run should fail")
+	}
+	s.HandleAction("Run", nil)
+	if err := s.HandleAction("CancelRun", nil); err != nil || s.Status != "ready" {
+		t.Errorf("CancelRun error = %v, Status = %q, want ready", err, s.Status)
+	}
+	if err := s.HandleAction("Confirm", nil); err == nil || runs() != 1 {
+		t.Errorf("Confirm after CancelRun: error = %v, runs = %d, want an error and no run", err, runs())
+	}
+}
+
+func TestExecStream(t *testing.T) {
+	origState := config.IsExecAllowed()
+	defer config.SetAllowExec(origState)
+	config.SetAllowExec(true)
+
+	tmpDir := t.TempDir()
+	script := "#!/bin/sh\necho compiling\nsleep 1\necho linking\nsleep 10\necho never\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "build.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.SourceConfig{Type: "exec", Cmd: "./build.sh", Options: map[string]string{"stream": "true"}}
+	s, err := NewGenericState("build", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if s.Status != "ready" || s.Output != "" {
+		t.Fatalf("streaming source ran on load: Status=%q Output=%q", s.Status, s.Output)
+	}
+
+	// Each re-render reports the output and status at that moment
+	type snapshot struct{ status, output, err string }
+	updates := make(chan snapshot, 100)
+	s.SetOnUpdate(func() {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		updates <- snapshot{s.Status, s.Output, s.Error}
+	})
+	next := func() snapshot {
+		t.Helper()
+		select {
+		case u := <-updates:
+			return u
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a re-render")
+			return snapshot{}
+		}
+	}
+
+	if err := s.HandleAction("Run", nil); err != nil {
+		t.Fatalf("HandleAction(Run) error = %v", err)
+	}
+	if s.Status != "running" {
+		t.Errorf("Status after Run = %q, want running", s.Status)
+	}
+	if err := s.HandleAction("Run", nil); err == nil {
+		t.Error("second Run while streaming should fail")
+	}
+
+	// Output shows up while the command is still running
+	if u := next(); u.status != "running" || u.output != "compiling\n" {
+		t.Errorf("first update = %+v, want the first line while running", u)
+	}
+	if u := next(); u.status != "running" || u.output != "compiling\nlinking\n" {
+		t.Errorf("second update = %+v, want both lines while running", u)
+	}
+
+	if err := s.HandleAction("CancelRun", nil); err != nil {
+		t.Fatalf("HandleAction(CancelRun) error = %v", err)
+	}
+	if u := next(); u.status != "error" || u.err != "command was cancelled" || strings.Contains(u.output, "never") {
+		t.Errorf("update after CancelRun = %+v, want the run stopped", u)
+	}
+}
+
+func TestRedactCommand(t *testing.T) {
+	cmd := `./run.sh --api-key=k1 --author "Jane Doe" --db_password 'p w' --mode=tokenless`
+	args := parseExecArgs(cmd)
+	markSecretArgs(args, nil)
+	for _, arg := range args {
+		if arg.Name == "author" && arg.Secret {
+			t.Error("author should not be treated as a secret")
+		}
+	}
+	got := redactCommand(cmd, args)
+	if want := `./run.sh --api-key=*** --author 'Jane Doe' --db_password *** --mode=tokenless`; got != want {
+		t.Errorf("redactCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestDataTableRefreshKeepsColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+	csv := "name,email,role\nAda,ada@example.com,admin\nGrace,grace@example.com,user\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "people.csv"), []byte(csv), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	cfg := config.SourceConfig{Type: "csv", File: "people.csv"}
+	s, err := NewGenericStateWithMetadata("people", cfg, tmpDir, filepath.Join(tmpDir, "index.md"), map[string]string{"lvt-element": "table"})
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	table := s.Table
+	columns := append([]datatable.Column(nil), table.Columns...)
+
+	for i := 0; i < 2; i++ {
+		table.Page = 1
+		if err := s.HandleAction("Refresh", nil); err != nil {
+			t.Fatalf("Refresh %d error = %v", i+1, err)
+		}
+		if s.Table != table {
+			t.Fatalf("Refresh %d replaced the datatable, want it reused", i+1)
+		}
+		if !reflect.DeepEqual(s.Table.Columns, columns) {
+			t.Fatalf("columns after refresh %d = %+v, want %+v", i+1, s.Table.Columns, columns)
+		}
+		if s.Table.Page != 0 || len(s.Table.Rows) != 2 {
+			t.Errorf("after refresh %d: page %d with %d rows, want page 0 with 2 rows", i+1, s.Table.Page, len(s.Table.Rows))
+		}
+	}
+
+	// A changed header is discovered again, and drops the sort
+	if err := s.HandleAction("Sort", map[string]interface{}{"column": "role"}); err != nil {
+		t.Fatalf("HandleAction(Sort) error = %v", err)
+	}
+	csv = "name,team\nAda,core\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "people.csv"), []byte(csv), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	if err := s.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("Refresh error = %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, col := range s.Table.Columns {
+		ids[col.ID] = true
+	}
+	if len(ids) != 2 || !ids["name"] || !ids["team"] {
+		t.Errorf("columns after the header changed = %+v, want name and team", s.Table.Columns)
+	}
+	if s.Table.SortColumn != "" {
+		t.Errorf("datatable sorted by %q after the header changed, want unsorted", s.Table.SortColumn)
+	}
+}
+
+func TestDataTableColumnKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	csv := "first_name,Email\nAda,ada@example.com\nGrace,grace@example.com\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "people.csv"), []byte(csv), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	cfg := config.SourceConfig{Type: "csv", File: "people.csv"}
+
+	// Explicit columns: spaces around fields are ignored and labels are kept
+	metadata := map[string]string{"lvt-element": "table", "lvt-columns": "first_name:First Name, email"}
+	s, err := NewGenericStateWithMetadata("people", cfg, tmpDir, filepath.Join(tmpDir, "index.md"), metadata)
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	dt := s.buildDataTable()
+	if len(dt.Columns) != 2 || dt.Columns[0].Label != "First Name" || dt.Columns[1].ID != "email" || dt.Columns[1].Label != "Email" {
+		t.Fatalf("columns = %+v, want first_name (First Name) and email (Email)", dt.Columns)
+	}
+	if len(dt.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(dt.Rows))
+	}
+	if got := dt.Rows[0].Data["first_name"]; got != "Ada" {
+		t.Errorf("first_name cell = %v, want Ada", got)
+	}
+	if got := dt.Rows[0].Data["email"]; got != "ada@example.com" {
+		t.Errorf("email cell = %v, want ada@example.com", got)
+	}
+
+	// Discovered columns keep capitalized keys that aren't aliases
+	s, err = NewGenericStateWithMetadata("people", cfg, tmpDir, filepath.Join(tmpDir, "index.md"), map[string]string{"lvt-element": "table"})
+	if err != nil {
+		t.Fatalf("NewGenericStateWithMetadata() error = %v", err)
+	}
+	dt = s.buildDataTable()
+	labels := make(map[string]string)
+	for _, col := range dt.Columns {
+		labels[col.ID] = col.Label
+	}
+	if labels["first_name"] != "First Name" || labels["Email"] != "Email" || len(labels) != 2 {
+		t.Errorf("discovered columns = %v, want first_name and Email", labels)
+	}
+
+	// Templates see the same normalized key as the datatable
+	data := pagedData(t, s)
+	if row, _ := data[0].(map[string]interface{}); row == nil || row["FirstName"] != "Ada" {
+		t.Errorf("template row = %v, want FirstName alias", data[0])
+	}
+}
+
+func TestExecTruncationNotice(t *testing.T) {
+	origState := config.IsExecAllowed()
+	defer config.SetAllowExec(origState)
+	config.SetAllowExec(true)
+
+	tmpDir := t.TempDir()
+	out := filepath.Join(tmpDir, "out.txt")
+	if err := os.WriteFile(out, []byte(strings.Repeat("a long line\n", 10)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.SourceConfig{Type: "exec", Cmd: "cat out.txt", Format: "lines", Options: map[string]string{"max_output": "20B"}}
+	s, err := NewGenericState("log", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+	if s.Notice != truncatedNotice {
+		t.Fatalf("Notice = %q, want the truncation notice", s.Notice)
+	}
+
+	// A run that fits clears the notice
+	if err := os.WriteFile(out, []byte("short\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.HandleAction("Refresh", nil); err != nil {
+		t.Fatalf("HandleAction(Refresh) error = %v", err)
+	}
+	if s.Notice != "" {
+		t.Errorf("Notice after a run that fits = %q, want none", s.Notice)
+	}
+}
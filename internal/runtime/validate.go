@@ -0,0 +1,149 @@
+package runtime
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/source"
+)
+
+// validateFields checks the data of an Add or Update action against the
+// source's field rules and returns a message per invalid field. Add must
+// supply every required field; Update only changes the fields it sends, so
+// only those are checked. Blank values skip the maxlen and pattern checks,
+// as in HTML form validation.
+func validateFields(rules map[string]config.FieldRule, action string, data map[string]interface{}) (map[string]string, error) {
+	invalid := make(map[string]string)
+	for field, rule := range rules {
+		raw, present := data[field]
+		if !present && action != "add" {
+			continue
+		}
+		value := ""
+		if raw != nil {
+			value = fmt.Sprint(raw)
+		}
+
+		if strings.TrimSpace(value) == "" {
+			if rule.Required {
+				invalid[field] = field + " is required"
+			}
+			continue
+		}
+		if rule.MaxLen > 0 && utf8.RuneCountInString(value) > rule.MaxLen {
+			invalid[field] = fmt.Sprintf("%s must be at most %d characters", field, rule.MaxLen)
+			continue
+		}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("field %q has an invalid pattern %q: %w", field, rule.Pattern, err)
+			}
+			if !re.MatchString(value) {
+				invalid[field] = field + " does not match the expected format"
+			}
+		}
+	}
+	return invalid, nil
+}
+
+// checkFields applies the source's field rules to a write action. Invalid
+// fields are recorded in s.Errors and summarized in s.Error, and the returned
+// error stops the write.
+func (s *GenericState) checkFields(action string, data map[string]interface{}) error {
+	if len(s.sourceCfg.Fields) == 0 {
+		return nil
+	}
+	invalid, err := validateFields(s.sourceCfg.Fields, action, data)
+	if err != nil {
+		s.Error = err.Error()
+		return &source.ValidationError{Source: s.sourceName, Reason: err.Error()}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	for field, msg := range invalid {
+		s.Errors[field] = msg
+	}
+	s.Error = joinInvalid(invalid)
+	return &source.ValidationError{Source: s.sourceName, Reason: s.Error}
+}
+
+// maxImportErrors caps the invalid rows CheckImport lists.
+const maxImportErrors = 10
+
+// CheckImport applies a source's field rules to each row of the CSV text of
+// an Import action, as Add would, and returns a *source.ValidationError
+// naming the invalid rows, so that no row is written. Rows are numbered as
+// in the file, with the header as row 1. CSV the import can't read is left
+// for the source to reject.
+func CheckImport(name string, rules map[string]config.FieldRule, text string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil || len(records) < 2 {
+		return nil
+	}
+
+	// Column names match table headers, and so field names, ignoring case
+	columns := make(map[string]int, len(rules))
+	for i, column := range records[0] {
+		for field := range rules {
+			if strings.EqualFold(strings.TrimSpace(column), field) {
+				columns[field] = i
+			}
+		}
+	}
+
+	var rows []string
+	bad := 0
+	for n, record := range records[1:] {
+		data := make(map[string]interface{}, len(columns))
+		for field, i := range columns {
+			if i < len(record) {
+				data[field] = record[i]
+			}
+		}
+		invalid, err := validateFields(rules, "add", data)
+		if err != nil {
+			return &source.ValidationError{Source: name, Reason: err.Error()}
+		}
+		if len(invalid) == 0 {
+			continue
+		}
+		bad++
+		if bad <= maxImportErrors {
+			rows = append(rows, fmt.Sprintf("row %d: %s", n+2, joinInvalid(invalid)))
+		}
+	}
+	if bad == 0 {
+		return nil
+	}
+	if bad > maxImportErrors {
+		rows = append(rows, fmt.Sprintf("and %d more rows", bad-maxImportErrors))
+	}
+	return &source.ValidationError{Source: name, Field: "csv", Reason: strings.Join(rows, "; ")}
+}
+
+// joinInvalid joins the messages of validateFields in field order.
+func joinInvalid(invalid map[string]string) string {
+	fields := make([]string, 0, len(invalid))
+	for field := range invalid {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = invalid[field]
+	}
+	return strings.Join(msgs, "; ")
+}
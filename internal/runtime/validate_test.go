@@ -0,0 +1,153 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/source"
+)
+
+func TestFieldRulesRejectInvalidWrites(t *testing.T) {
+	readonly := false
+	rules := map[string]config.FieldRule{
+		"text":  {Required: true, MaxLen: 20},
+		"owner": {Pattern: `@[a-z]+`},
+	}
+
+	tests := []struct {
+		name      string
+		action    string
+		data      map[string]interface{}
+		wantField string // Field expected in Errors; empty means the write succeeds
+		wantError string
+	}{
+		{"valid add", "Add", map[string]interface{}{"text": "Write docs", "owner": "@ana"}, "", ""},
+		{"optional field omitted", "Add", map[string]interface{}{"text": "Write docs"}, "", ""},
+		{"required missing", "Add", map[string]interface{}{"owner": "@ana"}, "text", "text is required"},
+		{"required blank", "Add", map[string]interface{}{"text": "   "}, "text", "text is required"},
+		{"too long", "Add", map[string]interface{}{"text": strings.Repeat("x", 21)}, "text", "text must be at most 20 characters"},
+		{"pattern mismatch", "Add", map[string]interface{}{"text": "Write docs", "owner": "ana"}, "owner", "owner does not match the expected format"},
+		{"pattern matches whole value", "Add", map[string]interface{}{"text": "Write docs", "owner": "cc @ana"}, "owner", "owner does not match"},
+		{"update blanks required", "Update", map[string]interface{}{"id": "t1", "text": ""}, "text", "text is required"},
+		{"update too long", "Update", map[string]interface{}{"id": "t1", "text": strings.Repeat("x", 21)}, "text", "at most 20 characters"},
+		{"valid update", "Update", map[string]interface{}{"id": "t1", "text": "Renamed"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeTaskFile(t, tmpDir, 1)
+			path := filepath.Join(tmpDir, "tasks.md")
+			before, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := config.SourceConfig{Type: "markdown", File: "tasks.md", Anchor: "#tasks", Readonly: &readonly, Fields: rules}
+			s, err := NewGenericState("tasks", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+			if err != nil {
+				t.Fatalf("NewGenericState() error = %v", err)
+			}
+
+			err = s.HandleAction(tt.action, tt.data)
+			after, readErr := os.ReadFile(path)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("HandleAction() error = %v", err)
+				}
+				if string(after) == string(before) {
+					t.Error("valid write did not change the file")
+				}
+				return
+			}
+
+			var validationErr *source.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("HandleAction() error = %v, want a *source.ValidationError", err)
+			}
+			if !strings.Contains(s.Error, tt.wantError) {
+				t.Errorf("Error = %q, want containing %q", s.Error, tt.wantError)
+			}
+			if !strings.Contains(s.Errors[tt.wantField], tt.wantError) {
+				t.Errorf("Errors[%q] = %q, want containing %q", tt.wantField, s.Errors[tt.wantField], tt.wantError)
+			}
+			if string(after) != string(before) {
+				t.Errorf("invalid write changed the file:\n%s", after)
+			}
+		})
+	}
+}
+
+func TestFieldRulesInvalidPattern(t *testing.T) {
+	invalid, err := validateFields(map[string]config.FieldRule{"text": {Pattern: "("}}, "add", map[string]interface{}{"text": "x"})
+	if err == nil || !strings.Contains(err.Error(), `field "text" has an invalid pattern`) {
+		t.Errorf("validateFields() = %v, %v, want an invalid pattern error", invalid, err)
+	}
+}
+
+func TestFieldRulesRejectInvalidImports(t *testing.T) {
+	tmpDir := t.TempDir()
+	table := "# Products {#products}\n\n| Name | Price |\n|------|-------|\n| Widget | $10 | <!-- id:p1 -->\n"
+	path := filepath.Join(tmpDir, "products.md")
+	if err := os.WriteFile(path, []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readonly := false
+	rules := map[string]config.FieldRule{
+		"Name":  {Required: true, MaxLen: 10},
+		"Price": {Pattern: `\$[0-9]+`},
+	}
+	cfg := config.SourceConfig{Type: "markdown", File: "products.md", Anchor: "#products", Readonly: &readonly, Fields: rules}
+	s, err := NewGenericState("products", cfg, tmpDir, filepath.Join(tmpDir, "index.md"))
+	if err != nil {
+		t.Fatalf("NewGenericState() error = %v", err)
+	}
+
+	// One bad row rejects the whole import; columns match fields ignoring case
+	err = s.HandleAction("Import", map[string]interface{}{"csv": "name,price\nSprocket,$5\n,$7\nGizmo,7\n"})
+	var validationErr *source.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("HandleAction(Import) error = %v, want a *source.ValidationError", err)
+	}
+	for _, want := range []string{"row 3: Name is required", "row 4: Price does not match the expected format"} {
+		if !strings.Contains(s.Error, want) {
+			t.Errorf("Error = %q, want containing %q", s.Error, want)
+		}
+	}
+	if strings.Contains(s.Error, "row 2") {
+		t.Errorf("Error = %q names the valid row", s.Error)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != table {
+		t.Errorf("rejected import changed the file:\n%s", after)
+	}
+
+	// A required column the CSV leaves out fails every row
+	if err := CheckImport("products", rules, "Price\n$1\n$2\n"); err == nil || !strings.Contains(err.Error(), "row 2: Name is required; row 3: Name is required") {
+		t.Errorf("CheckImport() without the Name column = %v", err)
+	}
+
+	// Long lists of bad rows are cut short
+	csv := "Name,Price\n" + strings.Repeat(",$1\n", maxImportErrors+2)
+	if err := CheckImport("products", rules, csv); err == nil || !strings.HasSuffix(err.Error(), "and 2 more rows") {
+		t.Errorf("CheckImport() with many bad rows = %v", err)
+	}
+
+	if err := s.HandleAction("Import", map[string]interface{}{"csv": "Name,Price\nSprocket,$5\n"}); err != nil {
+		t.Fatalf("HandleAction(Import) of valid rows error = %v", err)
+	}
+	if len(s.Data) != 2 {
+		t.Errorf("Data after valid import = %v", s.Data)
+	}
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat is the layout of access log lines.
+type AccessLogFormat string
+
+const (
+	// AccessLogCommon is the Common Log Format, followed by the duration.
+	AccessLogCommon AccessLogFormat = "common"
+	// AccessLogCombined adds the referer and user agent to AccessLogCommon.
+	AccessLogCombined AccessLogFormat = "combined"
+	// AccessLogJSON writes each request as a JSON object.
+	AccessLogJSON AccessLogFormat = "json"
+)
+
+// ParseAccessLogFormat parses "common", "combined" or "json".
+func ParseAccessLogFormat(s string) (AccessLogFormat, error) {
+	switch format := AccessLogFormat(strings.ToLower(strings.TrimSpace(s))); format {
+	case AccessLogCommon, AccessLogCombined, AccessLogJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid access log format %q (expected common, combined or json)", s)
+}
+
+// secretQueryParams are substrings of query parameter names whose values are
+// redacted from access logs, such as token, api_key and client_secret.
+var secretQueryParams = []string{"token", "key", "secret", "password", "passwd", "auth", "signature", "credential"}
+
+// redactedValue replaces the value of secret query parameters.
+const redactedValue = "REDACTED"
+
+// accessLogTimeLayout is the Common Log Format timestamp.
+const accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// accessLogEntry is one logged request. Its JSON form is the json format.
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	Remote    string    `json:"remote"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"` // With secret query values redacted
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Bytes     int64     `json:"bytes"`
+	Duration  float64   `json:"duration_ms"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	WebSocket bool      `json:"websocket,omitempty"`
+}
+
+// accessLogWriter records the status and body size of a response.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status   int
+	bytes    int64
+	hijacked bool
+}
+
+func (w *accessLogWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush passes flushes on, for streamed responses.
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack passes hijacks on, for WebSocket upgrades.
+func (w *accessLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+	w.hijacked = true
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// accessLogMiddleware writes a line to out for each request once it has been
+// served. WebSocket upgrades are logged when the connection closes, with
+// status 101, and their duration is how long it stayed open; the common and
+// combined formats end their lines with "websocket" and json sets websocket.
+func accessLogMiddleware(next http.Handler, out io.Writer, format AccessLogFormat) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		aw := &accessLogWriter{ResponseWriter: w}
+		next.ServeHTTP(aw, r)
+
+		entry := accessLogEntry{
+			Time:      start,
+			Remote:    getClientIP(r),
+			Method:    r.Method,
+			Path:      redactQuery(r.URL.RequestURI()),
+			Proto:     r.Proto,
+			Status:    aw.status,
+			Bytes:     aw.bytes,
+			Duration:  float64(time.Since(start).Microseconds()) / 1000,
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+			WebSocket: aw.hijacked,
+		}
+		if entry.WebSocket {
+			entry.Status = http.StatusSwitchingProtocols
+		} else if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+
+		line := formatAccessLog(entry, format)
+		mu.Lock()
+		defer mu.Unlock()
+		io.WriteString(out, line)
+	})
+}
+
+// formatAccessLog returns entry as a line in format.
+func formatAccessLog(e accessLogEntry, format AccessLogFormat) string {
+	if format == AccessLogJSON {
+		data, _ := json.Marshal(e)
+		return string(data) + "\n"
+	}
+
+	size := "-"
+	if e.Bytes > 0 {
+		size = strconv.FormatInt(e.Bytes, 10)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s - - [%s] %q %d %s", e.Remote, e.Time.Format(accessLogTimeLayout),
+		e.Method+" "+e.Path+" "+e.Proto, e.Status, size)
+	if format == AccessLogCombined {
+		fmt.Fprintf(&b, " %q %q", orDash(e.Referer), orDash(e.UserAgent))
+	}
+	fmt.Fprintf(&b, " %.3f", e.Duration/1000)
+	if e.WebSocket {
+		b.WriteString(" websocket")
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// orDash returns s, or "-" when it's empty, as log formats write missing fields.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// redactQuery replaces the values of query parameters in uri whose names
+// look secret, keeping everything else as it was sent.
+func redactQuery(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, _, hasValue := strings.Cut(param, "=")
+		if hasValue && isSecretParam(name) {
+			params[i] = name + "=" + redactedValue
+		}
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+// isSecretParam reports whether a query parameter called name, which may be
+// escaped, holds a secret.
+func isSecretParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretQueryParams {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithAccessLog wraps h to write a line in format to out for each request.
+// Wrap it around WithCompression, so logged sizes are the bytes sent.
+func WithAccessLog(h http.Handler, out io.Writer, format AccessLogFormat) http.Handler {
+	return accessLogMiddleware(h, out, format)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestAccessLogCommon(t *testing.T) {
+	var out bytes.Buffer
+	handler := WithAccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}), &out, AccessLogCommon)
+
+	req := httptest.NewRequest("GET", "/docs/intro?page=2&api_key=s3cret&Token=abc", nil)
+	req.RemoteAddr = "203.0.113.7:4242"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := regexp.MustCompile(`^203\.0\.113\.7 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /docs/intro\?page=2&api_key=REDACTED&Token=REDACTED HTTP/1\.1" 200 5 \d+\.\d{3}\n$`)
+	if !want.MatchString(out.String()) {
+		t.Errorf("logged %q, want a common log line with secrets redacted", out.String())
+	}
+}
+
+func TestAccessLogFormats(t *testing.T) {
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "missing", http.StatusNotFound)
+	})
+	req := httptest.NewRequest("GET", "/gone", nil)
+	req.Header.Set("User-Agent", "curl/8.5.0")
+
+	var out bytes.Buffer
+	WithAccessLog(notFound, &out, AccessLogCombined).ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(out.String(), `"GET /gone HTTP/1.1" 404 8 "-" "curl/8.5.0" `) {
+		t.Errorf("combined line = %q, want the status, size, referer and user agent", out.String())
+	}
+
+	out.Reset()
+	WithAccessLog(notFound, &out, AccessLogJSON).ServeHTTP(httptest.NewRecorder(), req)
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("json line %q: %v", out.String(), err)
+	}
+	if entry["method"] != "GET" || entry["path"] != "/gone" || entry["status"] != float64(404) || entry["bytes"] != float64(8) {
+		t.Errorf("json entry = %v", entry)
+	}
+	if _, ok := entry["duration_ms"]; !ok {
+		t.Error("json entry is missing duration_ms")
+	}
+
+	if _, err := ParseAccessLogFormat("apache"); err == nil {
+		t.Error("ParseAccessLogFormat() of an unknown format should fail")
+	}
+	if format, err := ParseAccessLogFormat(" JSON "); err != nil || format != AccessLogJSON {
+		t.Errorf("ParseAccessLogFormat(JSON) = %q, %v", format, err)
+	}
+}
+
+func TestAccessLogWebSocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	out := make(lineWriter, 1)
+	ts := httptest.NewServer(WithAccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.ReadMessage() // Until the client closes
+	}), out, AccessLogCommon))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?page=/", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	conn.Close()
+
+	if line := <-out; !strings.Contains(line, `"GET /ws?page=/ HTTP/1.1" 101 `) || !strings.HasSuffix(line, " websocket\n") {
+		t.Errorf("logged %q, want a 101 line marked websocket", line)
+	}
+}
+
+// lineWriter sends each write to the channel.
+type lineWriter chan string
+
+func (w lineWriter) Write(b []byte) (int, error) {
+	w <- string(b)
+	return len(b), nil
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/runtime"
+	"github.com/livetemplate/tinkerdown/internal/source"
+)
+
+// actionPath is the HTTP endpoint for actions that carry a file, which
+// WebSocket messages can't do well.
+const actionPath = "/action"
+
+// maxActionFieldSize bounds each non-file field of an /action request.
+const maxActionFieldSize = 4 << 10
+
+// maxImportSize bounds the CSV file of an Import action, which is read into
+// memory and written to the markdown file in one go.
+const maxImportSize = 1 << 20
+
+// maxActionParts bounds the parts of an /action request: its fields and the
+// file.
+const maxActionParts = 8
+
+// actionBodyMargin is allowed on top of the largest accepted file for the
+// fields and multipart headers of an /action request.
+const actionBodyMargin = maxActionParts * (maxActionFieldSize + 1<<10)
+
+// serveAction handles POST /action with a multipart/form-data body naming
+// the page (its path), the interactive block and the action, followed by
+// the file. Upload stores the file in the block's upload source; Import
+// appends the rows of a CSV file to the block's markdown table source. Both
+// refresh the blocks showing the source. A block combining several sources
+// also needs a source field naming the target source. Fields must come
+// before the file, as they do in FormData built from a form with the file
+// input last.
+func (s *Server) serveAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Same origin rule as the WebSocket, so other sites can't post files
+	var allowedOrigins []string
+	if s.config.API != nil {
+		allowedOrigins = s.config.API.GetCORSOrigins()
+	}
+	if !checkWebSocketOrigin(r, allowedOrigins) {
+		writeError(w, http.StatusForbidden, "origin not allowed")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxActionFileSize()+actionBodyMargin)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "expected a multipart/form-data body")
+		return
+	}
+
+	fields := make(map[string]string)
+	for parts := 1; ; parts++ {
+		part, err := mr.NextPart()
+		var tooLarge *http.MaxBytesError
+		switch {
+		case err == io.EOF:
+			writeError(w, http.StatusBadRequest, "missing file")
+			return
+		case errors.As(err, &tooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		case err != nil:
+			writeError(w, http.StatusBadRequest, "invalid multipart body")
+			return
+		case parts > maxActionParts:
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("too many fields (limit %d)", maxActionParts-1))
+			return
+		}
+
+		if part.FormName() != "file" {
+			value, err := io.ReadAll(io.LimitReader(part, maxActionFieldSize+1))
+			if err != nil || len(value) > maxActionFieldSize {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("field %q is too large", part.FormName()))
+				return
+			}
+			fields[part.FormName()] = string(value)
+			continue
+		}
+
+		action := strings.ToLower(fields["action"])
+		if action != "upload" && action != "import" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported action %q", fields["action"]))
+			return
+		}
+		name, cfg, err := s.actionSource(fields["page"], fields["block"], fields["source"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if action == "import" {
+			if cfg.Type != "markdown" {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("source %q is not a markdown source", name))
+				return
+			}
+			s.importCSV(w, name, cfg, part)
+			return
+		}
+		if cfg.Type != "upload" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("source %q is not an upload source", name))
+			return
+		}
+		s.storeUpload(w, name, cfg, part.FileName(), part)
+		return
+	}
+}
+
+// storeUpload stores an uploaded file in an upload source and responds with
+// the stored file's row.
+func (s *Server) storeUpload(w http.ResponseWriter, name string, cfg config.SourceConfig, filename string, file io.Reader) {
+	if cfg.IsReadonly() {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("source %q is read-only", name))
+		return
+	}
+	src, err := source.NewUploadSource(name, cfg, s.rootDir)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	row, err := src.Store(filename, file)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, source.ErrUploadTooLarge), errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	case errors.Is(err, source.ErrUploadType):
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	case err != nil:
+		actionLog.Errorf("Upload to %s failed: %v", name, err)
+		writeError(w, http.StatusInternalServerError, "failed to store file")
+		return
+	}
+
+	actionLog.Infof("Stored upload %s in source %s", row["name"], name)
+	dir, _ := source.UploadDir(cfg, s.rootDir) // Checked by NewUploadSource
+	s.RefreshSourcesForFile(rootRelative(s.rootDir, dir))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    row,
+	})
+}
+
+// importCSV appends the rows of an uploaded CSV file to a markdown table
+// source. Its source of the file shares the file's write lock with the
+// blocks' sources, so the import can't interleave with their writes.
+func (s *Server) importCSV(w http.ResponseWriter, name string, cfg config.SourceConfig, file io.Reader) {
+	if cfg.IsReadonly() {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("source %q is read-only", name))
+		return
+	}
+	text, err := io.ReadAll(io.LimitReader(file, maxImportSize+1))
+	var tooLarge *http.MaxBytesError
+	if err != nil && !errors.As(err, &tooLarge) {
+		writeError(w, http.StatusBadRequest, "failed to read file")
+		return
+	}
+	if err != nil || len(text) > maxImportSize {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file is too large (limit %d bytes)", maxImportSize))
+		return
+	}
+
+	if err := runtime.CheckImport(name, cfg.Fields, string(text)); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	src, err := source.NewMarkdownSource(name, cfg.File, cfg.Anchor, s.rootDir, "", false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	err = src.WriteItem(context.Background(), "import", map[string]interface{}{"csv": string(text)})
+	var validationErr *source.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		actionLog.Errorf("Import into %s failed: %v", name, err)
+		writeError(w, http.StatusInternalServerError, "failed to import file")
+		return
+	}
+	actionLog.Infof("Imported CSV into source %s", name)
+	s.RefreshSourcesForFile(rootRelative(s.rootDir, src.GetFilePath()))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+// maxActionFileSize returns the largest file an /action request may carry:
+// the largest max_size of the site's and pages' upload sources, or the CSV
+// import limit if that is larger.
+func (s *Server) maxActionFileSize() int64 {
+	size := int64(maxImportSize)
+	consider := func(cfg config.SourceConfig) {
+		if cfg.Type == "upload" {
+			size = max(size, cfg.GetUploadMaxSize())
+		}
+	}
+	for _, src := range s.config.Sources {
+		consider(src)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, route := range s.routes {
+		if route.Page == nil {
+			continue
+		}
+		for _, src := range route.Page.Config.Sources {
+			consider(pageSourceConfig(src))
+		}
+	}
+	return size
+}
+
+// actionSource resolves the source an /action request targets from the page
+// path and interactive block ID. sourceName picks one source of a block
+// that combines several.
+func (s *Server) actionSource(pagePath, blockID, sourceName string) (string, config.SourceConfig, error) {
+	s.mu.RLock()
+	var page *tinkerdown.Page
+	for _, route := range s.routes {
+		if route.Pattern == pagePath {
+			page = route.Page
+			break
+		}
+	}
+	s.mu.RUnlock()
+	if page == nil {
+		return "", config.SourceConfig{}, fmt.Errorf("page %q not found", pagePath)
+	}
+
+	block, ok := page.InteractiveBlocks[blockID]
+	if !ok {
+		return "", config.SourceConfig{}, fmt.Errorf("block %q not found on page %q", blockID, pagePath)
+	}
+	var names []string
+	if sb, ok := page.ServerBlocks[block.StateRef]; ok {
+		names = tinkerdown.SourceNames(sb.Metadata["lvt-source"])
+	}
+
+	name := sourceName
+	switch {
+	case len(names) == 0:
+		return "", config.SourceConfig{}, fmt.Errorf("block %q has no lvt-source", blockID)
+	case name == "" && len(names) == 1:
+		name = names[0]
+	case name == "":
+		return "", config.SourceConfig{}, fmt.Errorf("block %q combines sources; set source to one of %s", blockID, strings.Join(names, ", "))
+	case !slices.Contains(names, name):
+		return "", config.SourceConfig{}, fmt.Errorf("source %q is not part of block %q", name, blockID)
+	}
+
+	if src, ok := page.Config.Sources[name]; ok {
+		return name, pageSourceConfig(src), nil
+	}
+	if src, ok := s.config.Sources[name]; ok {
+		return name, src, nil
+	}
+	return "", config.SourceConfig{}, fmt.Errorf("source %q is not defined", name)
+}
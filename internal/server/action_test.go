@@ -0,0 +1,268 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// uploadRequest builds a POST /action request uploading content as filename.
+func uploadRequest(t *testing.T, url, block, filename, content string) *http.Request {
+	t.Helper()
+	return actionRequest(t, url, "Upload", block, filename, content)
+}
+
+// actionRequest builds a POST /action request sending content as filename.
+func actionRequest(t *testing.T, url, action, block, filename, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, f := range [][2]string{{"page", "/"}, {"block", block}, {"action", action}} {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(content))
+	mw.Close()
+
+	req, err := http.NewRequest(http.MethodPost, url+actionPath, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestUploadAction(t *testing.T) {
+	baseDir := t.TempDir()
+	rootDir := filepath.Join(baseDir, "site")
+	if err := os.Mkdir(rootDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	page := "---\nsources:\n  files:\n    type: upload\n    readonly: false\n    options:\n      max_size: 1KB\n      types: text/csv\n" +
+		"  archive:\n    type: upload\n    path: _archive\n    options:\n      max_size: 1KB\n---\n# Files\n\n" +
+		"```lvt id=\"files\"\n<ul lvt-source=\"files\">{{range .Data}}<li>{{.name}} ({{.size}})</li>{{end}}</ul>\n```\n\n" +
+		"```lvt id=\"archive\"\n<ul lvt-source=\"archive\">{{range .Data}}<li>{{.name}}</li>{{end}}</ul>\n```\n"
+	if err := os.WriteFile(filepath.Join(rootDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(rootDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+	receiveTrees(t, client, 2)
+
+	resp, err := http.DefaultClient.Do(uploadRequest(t, ts.URL, "files", "../../escape.csv", "name,qty\nbolts,3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("upload status = %d, want 201", resp.StatusCode)
+	}
+
+	// Stored under _uploads with the directories stripped, and nowhere else
+	stored, err := os.ReadFile(filepath.Join(rootDir, "_uploads", "escape.csv"))
+	if err != nil || string(stored) != "name,qty\nbolts,3\n" {
+		t.Errorf("stored file = %q, %v", stored, err)
+	}
+	for _, path := range []string{filepath.Join(baseDir, "escape.csv"), filepath.Join(filepath.Dir(baseDir), "escape.csv")} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("upload escaped the upload directory to %s", path)
+		}
+	}
+
+	// Blocks showing the source are refreshed with the new file
+	update, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive update: %v", err)
+	}
+	if !isTreeFor(update, "files", "escape.csv") {
+		t.Errorf("expected tree listing the upload, got %+v", update)
+	}
+
+	rejected := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"too large", uploadRequest(t, ts.URL, "files", "big.csv", strings.Repeat("x", 1025)), http.StatusRequestEntityTooLarge},
+		{"wrong type", uploadRequest(t, ts.URL, "files", "run.sh", "echo hi"), http.StatusUnsupportedMediaType},
+		{"unknown block", uploadRequest(t, ts.URL, "nope", "a.csv", "a"), http.StatusBadRequest},
+		{"read-only source", uploadRequest(t, ts.URL, "archive", "a.csv", "a"), http.StatusForbidden},
+		{"too many fields", func() *http.Request {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			for i := 0; i < maxActionParts; i++ {
+				mw.WriteField(fmt.Sprintf("extra%d", i), "x")
+			}
+			fw, _ := mw.CreateFormFile("file", "a.csv")
+			fw.Write([]byte("a"))
+			mw.Close()
+			req, _ := http.NewRequest(http.MethodPost, ts.URL+actionPath, &body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			return req
+		}(), http.StatusBadRequest},
+		{"body too large", func() *http.Request {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			// A preamble is skipped without a field limit applying
+			body.WriteString(strings.Repeat(strings.Repeat("x", 1000)+"\r\n", (maxImportSize+actionBodyMargin)/1000+1))
+			mw.WriteField("page", "/")
+			mw.Close()
+			req, _ := http.NewRequest(http.MethodPost, ts.URL+actionPath, &body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			return req
+		}(), http.StatusRequestEntityTooLarge},
+		{"cross-origin", func() *http.Request {
+			req := uploadRequest(t, ts.URL, "files", "a.csv", "a")
+			req.Header.Set("Origin", "https://evil.example")
+			return req
+		}(), http.StatusForbidden},
+		{"GET", httptest.NewRequest(http.MethodGet, ts.URL+actionPath, nil), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.RequestURI = ""
+			resp, err := http.DefaultClient.Do(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(filepath.Join(rootDir, "_uploads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("upload dir has %d entries, want only the accepted file", len(entries))
+	}
+}
+
+func TestImportAction(t *testing.T) {
+	rootDir := t.TempDir()
+	page := "---\nsources:\n  products:\n    type: markdown\n    file: products.md\n    anchor: products\n    readonly: false\n---\n# Products\n\n" +
+		"```lvt id=\"products\"\n<ul lvt-source=\"products\">{{range .Data}}<li>{{.Name}}</li>{{end}}</ul>\n```\n"
+	files := map[string]string{
+		"index.md":    page,
+		"products.md": "# Products {#products}\n\n| Name | Price |\n|------|-------|\n| Widget | $10 | <!-- id:p1 -->\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(rootDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(rootDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+	if _, err := client.receive(); err != nil {
+		t.Fatalf("receive initial tree: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(actionRequest(t, ts.URL, "Import", "products", "new.csv", "Name,Price\nSprocket,$5\nGizmo,$7\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("import status = %d, want 200", resp.StatusCode)
+	}
+
+	update, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive update: %v", err)
+	}
+	if !isTreeFor(update, "products", "Gizmo") {
+		t.Errorf("expected tree listing the imported rows, got %+v", update)
+	}
+
+	// A column the table lacks is rejected and leaves the file alone
+	resp, err = http.DefaultClient.Do(actionRequest(t, ts.URL, "Import", "products", "bad.csv", "Name,Qty\nBolt,3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("mismatched import status = %d, want 400", resp.StatusCode)
+	}
+	content, err := os.ReadFile(filepath.Join(rootDir, "products.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(content), "<!-- id:") != 3 || strings.Contains(string(content), "Bolt") {
+		t.Errorf("products.md after imports:\n%s", content)
+	}
+}
+
+func TestImportActionChecksFieldRules(t *testing.T) {
+	rootDir := t.TempDir()
+	page := "---\nsources:\n  products:\n    type: markdown\n    file: products.md\n    anchor: products\n    readonly: false\n" +
+		"    fields:\n      Name:\n        required: true\n---\n# Products\n\n" +
+		"```lvt id=\"products\"\n<ul lvt-source=\"products\">{{range .Data}}<li>{{.Name}}</li>{{end}}</ul>\n```\n"
+	table := "# Products {#products}\n\n| Name | Price |\n|------|-------|\n| Widget | $10 | <!-- id:p1 -->\n"
+	files := map[string]string{
+		"index.md":    page,
+		"products.md": table,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(rootDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(rootDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	resp, err := http.DefaultClient.Do(actionRequest(t, ts.URL, "Import", "products", "new.csv", "Name,Price\nSprocket,$5\n,$7\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("import status = %d, want 400", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "row 3: Name is required") {
+		t.Errorf("import response = %s, want the invalid row", body)
+	}
+	content, err := os.ReadFile(filepath.Join(rootDir, "products.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != table {
+		t.Errorf("rejected import changed products.md:\n%s", content)
+	}
+}
@@ -2,14 +2,16 @@ package server
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/metrics"
 	"github.com/livetemplate/tinkerdown/internal/source"
+	"github.com/livetemplate/tinkerdown/internal/tracing"
 )
 
 // maxRequestBodySize limits the size of incoming request bodies (1MB)
@@ -42,7 +44,7 @@ func (h *APIHandler) Close() error {
 
 	for _, src := range h.sources {
 		if err := src.Close(); err != nil {
-			log.Printf("[API] Error closing source: %v", err)
+			apiLog.Errorf("Error closing source: %v", err)
 		}
 	}
 	h.sources = make(map[string]source.Source)
@@ -135,12 +137,16 @@ func (h *APIHandler) createSource(name string, cfg config.SourceConfig) (source.
 		return source.NewCSVFileSource(name, cfg.File, h.rootDir, cfg.Options)
 	case "markdown":
 		return source.NewMarkdownSource(name, cfg.File, cfg.Anchor, h.rootDir, "", cfg.IsReadonly())
+	case "frontmatter":
+		return source.NewFrontmatterSource(name, cfg.File, h.rootDir, "", cfg.Options)
 	case "rest":
 		return source.NewRestSourceWithConfig(name, cfg)
 	case "pg":
 		return source.NewPostgresSourceWithConfig(name, cfg.Query, cfg.Options, cfg)
 	case "graphql":
 		return source.NewGraphQLSource(name, cfg, h.rootDir)
+	case "upload":
+		return source.NewUploadSource(name, cfg, h.rootDir)
 	default:
 		return nil, &unsupportedSourceTypeError{sourceType: cfg.Type}
 	}
@@ -148,11 +154,13 @@ func (h *APIHandler) createSource(name string, cfg config.SourceConfig) (source.
 
 // handleGet fetches data from a source.
 func (h *APIHandler) handleGet(w http.ResponseWriter, r *http.Request, src source.Source) {
-	ctx := r.Context()
-
+	ctx, span := tracing.Start(r.Context(), "source.fetch", tracing.AttrSource.String(src.Name()))
+	start := time.Now()
 	data, err := src.Fetch(ctx)
+	metrics.ObserveSourceFetch(src.Name(), time.Since(start), err)
+	tracing.End(span, err)
 	if err != nil {
-		log.Printf("[API] Failed to fetch from source %s: %v", src.Name(), err)
+		apiLog.Errorf("Failed to fetch from source %s: %v", src.Name(), err)
 		writeError(w, http.StatusInternalServerError, "failed to fetch data")
 		return
 	}
@@ -196,7 +204,7 @@ func (h *APIHandler) handlePost(w http.ResponseWriter, r *http.Request, src sour
 	}
 
 	if err := writable.WriteItem(r.Context(), "add", data); err != nil {
-		log.Printf("[API] Failed to create item in source %s: %v", src.Name(), err)
+		apiLog.Errorf("Failed to create item in source %s: %v", src.Name(), err)
 		writeError(w, http.StatusInternalServerError, "failed to create item")
 		return
 	}
@@ -233,7 +241,7 @@ func (h *APIHandler) handlePut(w http.ResponseWriter, r *http.Request, src sourc
 	data["id"] = itemID
 
 	if err := writable.WriteItem(r.Context(), "update", data); err != nil {
-		log.Printf("[API] Failed to update item %s in source %s: %v", itemID, src.Name(), err)
+		apiLog.Errorf("Failed to update item %s in source %s: %v", itemID, src.Name(), err)
 		writeError(w, http.StatusInternalServerError, "failed to update item")
 		return
 	}
@@ -259,7 +267,7 @@ func (h *APIHandler) handleDelete(w http.ResponseWriter, r *http.Request, src so
 	if err := writable.WriteItem(r.Context(), "delete", map[string]interface{}{
 		"id": itemID,
 	}); err != nil {
-		log.Printf("[API] Failed to delete item %s from source %s: %v", itemID, src.Name(), err)
+		apiLog.Errorf("Failed to delete item %s from source %s: %v", itemID, src.Name(), err)
 		writeError(w, http.StatusInternalServerError, "failed to delete item")
 		return
 	}
@@ -275,7 +283,7 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("[API] Error encoding JSON response: %v", err)
+		apiLog.Errorf("Error encoding JSON response: %v", err)
 	}
 }
 
@@ -283,7 +291,7 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
-		log.Printf("[API] Error encoding error response: %v", err)
+		apiLog.Errorf("Error encoding error response: %v", err)
 	}
 }
 
@@ -321,13 +329,13 @@ func applyFilter(data []map[string]interface{}, filter string) []map[string]inte
 		value = filter[idx+1:]
 	} else {
 		// Invalid filter format (no operator or empty field name)
-		log.Printf("[API] Invalid filter format: %q", filter)
+		apiLog.Warnf("Invalid filter format: %q", filter)
 		return data
 	}
 
 	// Validate field name
 	if field == "" {
-		log.Printf("[API] Invalid filter: empty field name")
+		apiLog.Warnf("Invalid filter: empty field name")
 		return data
 	}
 
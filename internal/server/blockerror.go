@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxBlockErrorMessage is how much of an error's first line the error card shows.
+const maxBlockErrorMessage = 200
+
+// blockErrorTemplate renders a blockError in place of the block that failed.
+// It reuses the admonition styles so it follows the page theme.
+const blockErrorTemplate = `<div class="admonition admonition-warning tinkerdown-block-error" role="alert">
+<p class="admonition-title">{{if .Source}}Source "{{.Source}}" failed to load{{else}}Block failed to load{{end}}</p>
+<p><code>{{.Message}}</code></p>
+{{if .Detail}}<details><summary>Details</summary><pre>{{.Detail}}</pre></details>{{end}}
+</div>`
+
+// blockError is the state of an interactive block whose template or source
+// couldn't be set up. It renders as an error card and rejects all actions.
+type blockError struct {
+	Source  string
+	Message string // First line of the error, truncated
+	Detail  string // Full error, only set in debug mode when it differs from Message
+}
+
+// newBlockError creates the error state for a block bound to sourceName.
+func newBlockError(sourceName string, err error, debug bool) *blockError {
+	full := err.Error()
+	msg, _, _ := strings.Cut(full, "\n")
+	if r := []rune(msg); len(r) > maxBlockErrorMessage {
+		msg = string(r[:maxBlockErrorMessage]) + "…"
+	}
+	b := &blockError{Source: sourceName, Message: msg}
+	if debug && full != msg {
+		b.Detail = full
+	}
+	return b
+}
+
+// HandleAction rejects every action since the block never started.
+func (b *blockError) HandleAction(action string, data map[string]interface{}) error {
+	return fmt.Errorf("block failed to load: %s", b.Message)
+}
+
+// Close is a no-op.
+func (b *blockError) Close() error {
+	return nil
+}
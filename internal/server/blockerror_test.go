@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewBlockError(t *testing.T) {
+	err := errors.New(strings.Repeat("x", 300) + "\nsecond line")
+
+	b := newBlockError("users", err, false)
+	if b.Source != "users" || len([]rune(b.Message)) != maxBlockErrorMessage+1 || !strings.HasSuffix(b.Message, "…") {
+		t.Errorf("newBlockError() = %+v, want a truncated first line", b)
+	}
+	if b.Detail != "" {
+		t.Errorf("Detail = %q, want empty outside debug mode", b.Detail)
+	}
+	if b := newBlockError("users", err, true); b.Detail != err.Error() {
+		t.Errorf("Detail = %q, want the full error in debug mode", b.Detail)
+	}
+	if b.HandleAction("add", nil) == nil {
+		t.Error("HandleAction() error = nil, want the load error")
+	}
+}
+
+func TestBrokenBlocksRenderErrorCards(t *testing.T) {
+	tmpDir := t.TempDir()
+	page := "---\nsources:\n  items:\n    type: json\n    file: items.json\n---\n# Home\n\n" +
+		"```lvt id=\"ok\"\n<div lvt-source=\"items\"><p>Count: {{len .Data}}</p></div>\n```\n\n" +
+		"```lvt id=\"broken\"\n<div lvt-source=\"items\"><p>{{len .Data}</p></div>\n```\n\n" +
+		"```lvt id=\"missing\"\n<div lvt-source=\"itmes\"><p>{{len .Data}}</p></div>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "items.json"), []byte(`[{"name":"a"},{"name":"b"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+
+	trees := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		msg, err := client.receive()
+		if err != nil {
+			t.Fatalf("receive initial tree %d: %v", i, err)
+		}
+		if msg.Action == "tree" {
+			trees[msg.BlockID] = string(msg.Data)
+		}
+	}
+
+	if !strings.Contains(trees["ok"], `"2"`) || strings.Contains(trees["ok"], "tinkerdown-block-error") {
+		t.Errorf("working block tree = %s, want count 2 and no error card", trees["ok"])
+	}
+	for id, want := range map[string]string{
+		"broken":  "bad character",
+		"missing": "is not defined",
+	} {
+		tree := trees[id]
+		if !strings.Contains(tree, "tinkerdown-block-error") || !strings.Contains(tree, want) {
+			t.Errorf("%s block tree = %s, want an error card mentioning %q", id, tree, want)
+		}
+	}
+}
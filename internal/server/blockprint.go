@@ -0,0 +1,131 @@
+package server
+
+import "strings"
+
+// blockPrintScript adds a Print button to each interactive block, for
+// printing that block alone as a report. Blocks holding a table offer two
+// choices: "This view" prints the rows as shown, with the current sort,
+// filter and page, and "All rows" first asks the server for every row with
+// the PrintAll action, then restores the view with PrintDone once the print
+// dialog closes.
+//
+// While printing, the body gets the printing-block class, the block gets
+// print-target and its ancestors print-path; the print stylesheet hides
+// everything else, including the sidebar, navigation and the block's own
+// buttons and forms. A block-print-header with the page title and date is
+// shown above the block.
+const blockPrintScript = `
+    <script>
+        (function() {
+            let printing = null;
+
+            // afterUpdate resolves after the block's next re-render, or after
+            // a second if nothing changes
+            function afterUpdate(block) {
+                return new Promise(function(resolve) {
+                    let timer = null;
+                    const observer = new MutationObserver(function() {
+                        clearTimeout(timer);
+                        timer = setTimeout(done, 50);
+                    });
+                    function done() {
+                        observer.disconnect();
+                        resolve();
+                    }
+                    observer.observe(block, { childList: true, subtree: true, characterData: true });
+                    timer = setTimeout(done, 1000);
+                });
+            }
+
+            function send(block, action) {
+                const client = window.tinkerdownClient;
+                if (client) client.send(block.dataset.blockId, action, {});
+            }
+
+            function cleanup() {
+                if (!printing) return;
+                document.body.classList.remove('printing-block');
+                document.querySelectorAll('.print-target, .print-path').forEach(function(el) {
+                    el.classList.remove('print-target', 'print-path');
+                });
+                printing.header.remove();
+                if (printing.all) send(printing.block, 'PrintDone');
+                printing = null;
+            }
+
+            function printBlock(block, all) {
+                cleanup();
+                const header = document.createElement('p');
+                header.className = 'block-print-header print-target';
+                header.textContent = document.title + ' · ' + new Date().toLocaleString();
+                block.parentNode.insertBefore(header, block);
+                printing = { block: block, header: header, all: all };
+
+                document.body.classList.add('printing-block');
+                block.classList.add('print-target');
+                for (let el = block.parentElement; el && el !== document.body; el = el.parentElement) {
+                    el.classList.add('print-path');
+                }
+
+                if (!all) {
+                    window.print();
+                    return;
+                }
+                const updated = afterUpdate(block);
+                send(block, 'PrintAll');
+                updated.then(function() { window.print(); });
+            }
+
+            function addPrintButton(block) {
+                const bar = document.createElement('div');
+                bar.className = 'block-print';
+
+                const button = document.createElement('button');
+                button.type = 'button';
+                button.className = 'block-print-button';
+                button.textContent = 'Print';
+                button.setAttribute('aria-label', 'Print this block');
+                bar.appendChild(button);
+
+                if (!block.querySelector('table')) {
+                    button.addEventListener('click', function() { printBlock(block, false); });
+                    block.appendChild(bar);
+                    return;
+                }
+
+                const menu = document.createElement('div');
+                menu.className = 'block-print-menu';
+                menu.hidden = true;
+                [['This view', false], ['All rows', true]].forEach(function(choice) {
+                    const item = document.createElement('button');
+                    item.type = 'button';
+                    item.textContent = choice[0];
+                    item.addEventListener('click', function() {
+                        menu.hidden = true;
+                        printBlock(block, choice[1]);
+                    });
+                    menu.appendChild(item);
+                });
+                button.setAttribute('aria-haspopup', 'true');
+                button.addEventListener('click', function() {
+                    menu.hidden = !menu.hidden;
+                });
+                bar.appendChild(menu);
+                block.appendChild(bar);
+            }
+
+            window.addEventListener('afterprint', cleanup);
+            document.addEventListener('DOMContentLoaded', function() {
+                document.querySelectorAll('.tinkerdown-interactive-block[data-block-id]').forEach(addPrintButton);
+            });
+        })();
+    </script>`
+
+// renderBlockPrintScript returns blockPrintScript when the page has
+// interactive blocks.
+func renderBlockPrintScript(content string) string {
+	if !strings.Contains(content, "tinkerdown-interactive-block") {
+		return ""
+	}
+	return blockPrintScript
+}
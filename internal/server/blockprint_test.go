@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderBlockPrintScript(t *testing.T) {
+	if got := renderBlockPrintScript("<p>No blocks here</p>"); got != "" {
+		t.Errorf("renderBlockPrintScript() without interactive blocks = %q, want empty", got)
+	}
+	got := renderBlockPrintScript(`<div class="tinkerdown-interactive-block" data-block-id="lvt-0"></div>`)
+	for _, want := range []string{
+		"className = 'block-print'",
+		"'PrintAll'",
+		"'PrintDone'",
+		"'afterprint'",
+		"classList.add('printing-block')",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("print script missing %q", want)
+		}
+	}
+}
+
+func TestRenderPageBlockPrint(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "---\nsources:\n  users:\n    type: json\n    file: users.json\n---\n# Home\n\n```lvt\n<table lvt-source=\"users\"></table>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	body := w.Body.String()
+
+	if !strings.Contains(body, blockPrintScript) {
+		t.Error("page with an interactive block should include the print script")
+	}
+	if !hasCSS(pageStylesheet(t, srv), "body.printing-block *:not(.print-path, .print-target, .print-target *)") {
+		t.Error("print stylesheet should hide everything but the printed block")
+	}
+}
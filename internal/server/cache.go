@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Cache-Control values for pages and assets.
+const (
+	// cacheRevalidate lets browsers keep a copy but check its ETag on every
+	// request, so edits show up immediately in watch mode.
+	cacheRevalidate = "no-cache"
+	// cachePage is for rendered pages when files aren't being watched.
+	cachePage = "public, max-age=60"
+	// cacheClientAsset is for the client bundle, whose URL doesn't change
+	// between releases.
+	cacheClientAsset = "public, max-age=3600"
+	// cacheImmutable is for assets served under fingerprinted names.
+	cacheImmutable = "public, max-age=31536000, immutable"
+	// cacheVendorAsset is for embedded third-party libraries, which only
+	// change when the binary is upgraded.
+	cacheVendorAsset = "public, max-age=31536000"
+)
+
+// serveCacheable writes body with an ETag computed from its content and the
+// given Cache-Control header. When the request's If-None-Match already names
+// that ETag, it answers 304 Not Modified without a body.
+func serveCacheable(w http.ResponseWriter, r *http.Request, contentType, cacheControl string, body []byte) {
+	if notModified(w, r, contentETag(body), cacheControl) {
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+// contentETag returns the ETag of a response with body.
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// notModified sets the ETag and Cache-Control headers of a response. When
+// the request's If-None-Match already names etag, it answers 304 Not
+// Modified without a body and returns true.
+func notModified(w http.ResponseWriter, r *http.Request, etag, cacheControl string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	// No body, so nothing encoded. The browser's copy keeps its
+	// Content-Security-Policy too, as a page's names the nonce its scripts
+	// carry.
+	w.Header().Del("Content-Encoding")
+	w.Header().Del("Content-Security-Policy")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header value names etag,
+// using the weak comparison required for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// watching reports whether the file watcher is running.
+func (s *Server) watching() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.watcher != nil
+}
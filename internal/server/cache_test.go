@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestETagRevalidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	handler := WithCompression(srv)
+
+	get := func(path, etag string, gzip bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if gzip {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		path         string
+		cacheControl string
+	}{
+		{"/", cachePage},
+		{"/assets/tinkerdown-client.js", cacheClientAsset},
+		{"/assets/pico.css", cacheVendorAsset},
+	}
+	for _, tt := range tests {
+		for _, gzip := range []bool{false, true} {
+			first := get(tt.path, "", gzip)
+			etag := first.Header().Get("ETag")
+			if first.Code != http.StatusOK || etag == "" {
+				t.Fatalf("GET %s (gzip=%v) = %d with ETag %q, want 200 with an ETag", tt.path, gzip, first.Code, etag)
+			}
+			if got := first.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("GET %s Cache-Control = %q, want %q", tt.path, got, tt.cacheControl)
+			}
+
+			repeat := get(tt.path, etag, gzip)
+			if repeat.Code != http.StatusNotModified {
+				t.Errorf("GET %s (gzip=%v) with If-None-Match %s = %d, want 304", tt.path, gzip, etag, repeat.Code)
+			}
+			if repeat.Body.Len() != 0 || repeat.Header().Get("Content-Encoding") != "" {
+				t.Errorf("GET %s (gzip=%v) 304 has body %q and Content-Encoding %q, want neither",
+					tt.path, gzip, repeat.Body.String(), repeat.Header().Get("Content-Encoding"))
+			}
+
+			if stale := get(tt.path, `"stale"`, gzip); stale.Code != http.StatusOK {
+				t.Errorf("GET %s with a stale ETag = %d, want 200", tt.path, stale.Code)
+			}
+		}
+	}
+
+	// While watching, pages and the client bundle are revalidated every time
+	if err := srv.EnableWatch(false); err != nil {
+		t.Fatalf("EnableWatch() error: %v", err)
+	}
+	defer srv.StopWatch()
+	for _, path := range []string{"/", "/assets/tinkerdown-client.css"} {
+		if got := get(path, "", false).Header().Get("Cache-Control"); got != cacheRevalidate {
+			t.Errorf("GET %s in watch mode Cache-Control = %q, want %q", path, got, cacheRevalidate)
+		}
+	}
+}
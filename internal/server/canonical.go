@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// Canonical URLs follow the routes mdToPattern generates: a directory's
+// index page ends in "/" ("/guides/" for guides/index.md) and any other page
+// doesn't ("/guides/intro" for guides/intro.md). The other spelling of a
+// page's path redirects permanently to its route, so each page has exactly
+// one URL.
+
+// toggleTrailingSlash returns p with its trailing slash removed, or added
+// when it has none. It returns "" for the root, which has no other spelling.
+func toggleTrailingSlash(p string) string {
+	if p == "/" || p == "" {
+		return ""
+	}
+	if strings.HasSuffix(p, "/") {
+		return strings.TrimSuffix(p, "/")
+	}
+	return p + "/"
+}
+
+// redirectCanonical permanently redirects the request to pattern, keeping
+// its query string.
+func redirectCanonical(w http.ResponseWriter, r *http.Request, pattern string) {
+	target := pattern
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// canonicalLink returns the <head> link naming pagePath's canonical URL
+// under base_url, or "" when the site has no base_url.
+func (s *Server) canonicalLink(pagePath string) string {
+	base := strings.TrimRight(strings.TrimSpace(s.config.BaseURL), "/")
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n    <link rel=\"canonical\" href=\"%s\">", html.EscapeString(base+pagePath))
+}
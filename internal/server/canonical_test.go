@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// canonicalSiteFiles is a site with a section that has an index page.
+var canonicalSiteFiles = map[string]string{
+	"index.md":        "# Home",
+	"about.md":        "# About",
+	"guides/index.md": "# Guides",
+	"guides/intro.md": "# Intro",
+}
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	srv := newTestServer(t, canonicalSiteFiles, nil)
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"/about/", "/about"},
+		{"/guides", "/guides/"},
+		{"/guides/intro/", "/guides/intro"},
+		{"/guides/intro/?step=2", "/guides/intro?step=2"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+
+		if w.Code != 301 {
+			t.Errorf("GET %s: status = %d, want 301", tt.path, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("GET %s: Location = %q, want %q", tt.path, got, tt.location)
+		}
+	}
+
+	for _, path := range []string{"/", "/about", "/guides/", "/guides/intro"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Errorf("GET %s: status = %d, want 200", path, w.Code)
+		}
+	}
+}
+
+func TestRenderPageCanonicalLink(t *testing.T) {
+	render := func(srv *Server, path string) string {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	srv := newTestServer(t, canonicalSiteFiles, func(cfg *config.Config) {
+		cfg.BaseURL = "https://docs.example.com/"
+	})
+	for path, want := range map[string]string{
+		"/":             `<link rel="canonical" href="https://docs.example.com/">`,
+		"/about":        `<link rel="canonical" href="https://docs.example.com/about">`,
+		"/guides/":      `<link rel="canonical" href="https://docs.example.com/guides/">`,
+		"/guides/intro": `<link rel="canonical" href="https://docs.example.com/guides/intro">`,
+	} {
+		if body := render(srv, path); !strings.Contains(body, want) {
+			t.Errorf("GET %s: page missing %s", path, want)
+		}
+	}
+	if body := render(srv, "/about?tab=2"); !strings.Contains(body, `href="https://docs.example.com/about">`) {
+		t.Error("canonical link should leave out the query string")
+	}
+
+	srv = newTestServer(t, canonicalSiteFiles, nil)
+	if body := render(srv, "/about"); strings.Contains(body, `rel="canonical"`) {
+		t.Error("no canonical link should be emitted without base_url")
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+// codeLinesScript keeps highlighted lines and line numbers after Prism runs.
+// Prism replaces a block's markup with its tokens, dropping the per-line
+// <span class="code-line"> wrappers the renderer emitted, so this hook splits
+// the highlighted HTML back into lines (closing and reopening any token spans
+// that cross a line break) and wraps them again from the <pre data-line> spec.
+// It must load after prism.js and before Prism.highlightAll runs.
+const codeLinesScript = `
+    <script>
+        Prism.hooks.add('after-highlight', function(env) {
+            const pre = env.element.parentElement;
+            if (!pre || !pre.classList.contains('code-lines')) return;
+
+            const marked = new Set();
+            (pre.getAttribute('data-line') || '').split(',').forEach(function(part) {
+                const bounds = part.split('-').map(Number);
+                for (let n = bounds[0]; n <= (bounds[1] || bounds[0]); n++) marked.add(n);
+            });
+
+            const lines = [];
+            const open = [];
+            let current = '';
+            env.element.innerHTML.split(/(<span[^>]*>|<\/span>|\n)/).forEach(function(part) {
+                if (part === '\n') {
+                    lines.push(current + '</span>'.repeat(open.length));
+                    current = open.join('');
+                } else {
+                    if (part.startsWith('<span')) open.push(part);
+                    else if (part === '</span>') open.pop();
+                    current += part;
+                }
+            });
+            if (current !== '') lines.push(current);
+
+            env.element.innerHTML = lines.map(function(line, i) {
+                const cls = marked.has(i + 1) ? 'code-line highlighted' : 'code-line';
+                return '<span class="' + cls + '" data-line-number="' + (i + 1) + '">' + line + '</span>\n';
+            }).join('');
+        });
+    </script>`
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCombinedSourcesRender(t *testing.T) {
+	tmpDir := t.TempDir()
+	page := "---\nsources:\n  orders:\n    type: json\n    file: orders.json\n  rates:\n    type: json\n    file: rates.json\n---\n# Dashboard\n\n" +
+		"```lvt id=\"side\"\n<div lvt-source=\"orders, rates\"><p>{{len .Orders.Data}} orders in {{len .Rates.Data}} currencies</p></div>\n```\n\n" +
+		"```lvt id=\"joined\"\n<div lvt-source=\"orders,rates\" lvt-join=\"currency\">{{range .Joined}}<p>{{.Id}}={{.amount}}x{{.rate}}</p>{{end}}</div>\n```\n"
+	files := map[string]string{
+		"index.md":    page,
+		"orders.json": `[{"id":"o1","currency":"EUR","amount":10},{"id":"o2","currency":"JPY","amount":5},{"id":"o3","currency":"CHF","amount":1}]`,
+		"rates.json":  `[{"currency":"EUR","rate":1.1},{"currency":"JPY","rate":0.007}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+
+	trees := make(map[string]string)
+	for i := 0; i < 2; i++ {
+		msg, err := client.receive()
+		if err != nil {
+			t.Fatalf("receive initial tree %d: %v", i, err)
+		}
+		if msg.Action == "tree" {
+			trees[msg.BlockID] = string(msg.Data)
+		}
+	}
+
+	for _, want := range []string{`"3"`, `"2"`} {
+		if !strings.Contains(trees["side"], want) {
+			t.Errorf("side-by-side tree = %s, want it to contain %s", trees["side"], want)
+		}
+	}
+	joined := trees["joined"]
+	for _, want := range []string{"o1", "1.1", "o2", "0.007"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("joined tree = %s, want it to contain %s", joined, want)
+		}
+	}
+	if strings.Contains(joined, "o3") {
+		t.Errorf("joined tree = %s, want o3 dropped (no CHF rate)", joined)
+	}
+}
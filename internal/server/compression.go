@@ -13,10 +13,28 @@ type gzipResponseWriter struct {
 	io.Writer
 	http.ResponseWriter
 	wroteHeader bool
+	noBody      bool // 304 Not Modified responses carry no body to compress
+	passThrough bool // The handler encoded the body itself
 }
 
 func (w *gzipResponseWriter) WriteHeader(status int) {
 	w.wroteHeader = true
+	switch {
+	case w.Header().Get("Content-Encoding") != "":
+		// Already compressed, like precompressed assets
+		w.passThrough = true
+	case status == http.StatusNotModified:
+		w.noBody = true
+		w.Header().Add("Vary", "Accept-Encoding")
+	default:
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		// The gzipped body isn't byte-identical to the one the ETag was
+		// computed from, so only claim weak equivalence
+		if etag := w.Header().Get("ETag"); strings.HasPrefix(etag, `"`) {
+			w.Header().Set("ETag", "W/"+etag)
+		}
+	}
 	w.ResponseWriter.WriteHeader(status)
 }
 
@@ -24,9 +42,17 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	if !w.wroteHeader {
 		w.WriteHeader(http.StatusOK)
 	}
+	if w.passThrough {
+		return w.ResponseWriter.Write(b)
+	}
 	return w.Writer.Write(b)
 }
 
+// acceptsGzip reports whether the client accepts gzipped responses.
+func acceptsGzip(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+}
+
 // gzipWriterPool reuses gzip writers to reduce GC pressure
 var gzipWriterPool = sync.Pool{
 	New: func() interface{} {
@@ -38,7 +64,7 @@ var gzipWriterPool = sync.Pool{
 func compressionMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check if client accepts gzip
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		if !acceptsGzip(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -49,19 +75,8 @@ func compressionMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Only compress certain content types
-		// We'll set this based on the response
-		w.Header().Set("Content-Encoding", "gzip")
-		w.Header().Add("Vary", "Accept-Encoding")
-
 		// Get gzip writer from pool
 		gz := gzipWriterPool.Get().(*gzip.Writer)
-		defer func() {
-			gz.Close()
-			gz.Reset(io.Discard)
-			gzipWriterPool.Put(gz)
-		}()
-
 		gz.Reset(w)
 
 		// Wrap response writer
@@ -70,6 +85,18 @@ func compressionMiddleware(next http.Handler) http.Handler {
 			ResponseWriter: w,
 		}
 
+		defer func() {
+			if !gzw.wroteHeader {
+				gzw.WriteHeader(http.StatusOK)
+			}
+			if gzw.noBody || gzw.passThrough {
+				gz.Reset(io.Discard)
+			}
+			gz.Close()
+			gz.Reset(io.Discard)
+			gzipWriterPool.Put(gz)
+		}()
+
 		next.ServeHTTP(gzw, r)
 	})
 }
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// defaultCSP allows self, inline scripts and styles (needed for PicoCSS and
+// LVT rendering), unsafe-eval (needed for Mermaid.js and expressions), and
+// data: URIs for fonts/images. connect-src 'self' covers same-origin
+// WebSocket (ws/wss) connections.
+const defaultCSP = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' 'unsafe-eval'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data: https:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"frame-ancestors 'none'"
+
+// strictCSP is defaultCSP with inline scripts allowed only when they carry
+// the response's nonce, so injected markup can't run scripts. Every script
+// and stylesheet the server uses is served from /assets, so nothing else
+// needs allowing.
+const strictCSP = "default-src 'self'; " +
+	"script-src 'self' 'nonce-" + cspNoncePlaceholder + "' 'unsafe-eval'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data: https:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"frame-ancestors 'none'"
+
+// cspNoncePlaceholder stands for the response's nonce in a policy.
+const cspNoncePlaceholder = "{nonce}"
+
+// cspNonceSourceRegex matches a nonce source in a policy, for responses
+// without inline scripts.
+var cspNonceSourceRegex = regexp.MustCompile(`\s*'nonce-` + regexp.QuoteMeta(cspNoncePlaceholder) + `'`)
+
+// scriptTagRegex matches the start of a script element.
+var scriptTagRegex = regexp.MustCompile(`<script\b`)
+
+// scriptNonceMark is the attribute nonceScripts puts on the server's own
+// script elements, for applyCSPNonce to fill in. Its value is random per
+// process, so page content can't carry it to get a nonce for its scripts.
+var scriptNonceMark = ` nonce="` + newCSPNonce() + `"`
+
+// nonceScripts marks the script elements of html, which must be generated
+// by the server rather than come from page content, to get the response's
+// nonce.
+func nonceScripts(html string) string {
+	return scriptTagRegex.ReplaceAllString(html, `<script`+scriptNonceMark)
+}
+
+// cspPolicy returns the configured Content-Security-Policy, which may hold
+// the nonce placeholder.
+func (s *Server) cspPolicy() string {
+	if s.config == nil {
+		return defaultCSP
+	}
+	switch {
+	case strings.TrimSpace(s.config.CSP.Policy) != "":
+		return strings.TrimSpace(s.config.CSP.Policy)
+	case s.config.CSP.Strict:
+		return strictCSP
+	}
+	return defaultCSP
+}
+
+// cspHeader returns policy with nonce in place of the placeholder, or without
+// its nonce sources when nonce is empty.
+func cspHeader(policy, nonce string) string {
+	if nonce == "" {
+		return cspNonceSourceRegex.ReplaceAllString(policy, "")
+	}
+	return strings.ReplaceAll(policy, cspNoncePlaceholder, nonce)
+}
+
+// newCSPNonce returns 128 random bits, base64-encoded.
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// applyCSPNonce sets the Content-Security-Policy header of an HTML response
+// and returns html with the header's nonce on the script elements marked by
+// nonceScripts. Other scripts, such as any in page content, get none.
+// Policies without the nonce placeholder just drop the marks.
+func (s *Server) applyCSPNonce(w http.ResponseWriter, html string) string {
+	policy := s.cspPolicy()
+	if !strings.Contains(policy, cspNoncePlaceholder) {
+		return strings.ReplaceAll(html, scriptNonceMark, "")
+	}
+	nonce := newCSPNonce()
+	w.Header().Set("Content-Security-Policy", cspHeader(policy, nonce))
+	return strings.ReplaceAll(html, scriptNonceMark, ` nonce="`+nonce+`"`)
+}
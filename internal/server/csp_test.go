@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// cspSiteFiles is a page with a highlighted code block, so it has scripts.
+var cspSiteFiles = map[string]string{"index.md": "# Home\n\n```go\nfmt.Println(1)\n```\n"}
+
+// withCSP sets the site's content security policy config.
+func withCSP(csp config.CSPConfig) func(*config.Config) {
+	return func(cfg *config.Config) { cfg.CSP = csp }
+}
+
+func TestCSPNonceMatchesScripts(t *testing.T) {
+	srv := newTestServer(t, cspSiteFiles, withCSP(config.CSPConfig{Strict: true}))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	header := rec.Header().Get("Content-Security-Policy")
+	match := regexp.MustCompile(`script-src 'self' 'nonce-([A-Za-z0-9+/=]+)'`).FindStringSubmatch(header)
+	if match == nil {
+		t.Fatalf("Content-Security-Policy = %q, want a script nonce", header)
+	}
+	if strings.Contains(header, "'unsafe-inline' 'unsafe-eval'") {
+		t.Errorf("strict policy should not allow inline scripts: %q", header)
+	}
+
+	body := rec.Body.String()
+	scripts := regexp.MustCompile(`<script\b[^>]*>`).FindAllString(body, -1)
+	if len(scripts) == 0 {
+		t.Fatal("page has no scripts")
+	}
+	for _, script := range scripts {
+		if !strings.Contains(script, `nonce="`+match[1]+`"`) {
+			t.Errorf("script %s lacks the header's nonce", script)
+		}
+	}
+
+	// Each response gets its own nonce
+	rec2 := httptest.NewRecorder()
+	srv.ServeHTTP(rec2, httptest.NewRequest("GET", "/", nil))
+	if rec2.Header().Get("Content-Security-Policy") == header {
+		t.Error("two responses share a nonce")
+	}
+
+	// Responses without inline scripts drop the nonce source
+	rec3 := httptest.NewRecorder()
+	srv.ServeHTTP(rec3, httptest.NewRequest("GET", "/robots.txt", nil))
+	if got := rec3.Header().Get("Content-Security-Policy"); strings.Contains(got, "nonce") || !strings.Contains(got, "script-src 'self' 'unsafe-eval'") {
+		t.Errorf("robots.txt Content-Security-Policy = %q, want no nonce", got)
+	}
+}
+
+func TestCSPPolicy(t *testing.T) {
+	srv := newTestServer(t, cspSiteFiles, withCSP(config.CSPConfig{}))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got := rec.Header().Get("Content-Security-Policy"); got != defaultCSP {
+		t.Errorf("default Content-Security-Policy = %q", got)
+	}
+	if strings.Contains(rec.Body.String(), "nonce=") {
+		t.Error("scripts should not get a nonce without a policy that uses one")
+	}
+
+	custom := "default-src 'self'; script-src 'nonce-{nonce}'"
+	srv = newTestServer(t, cspSiteFiles, withCSP(config.CSPConfig{Strict: true, Policy: custom}))
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got := rec.Header().Get("Content-Security-Policy"); !regexp.MustCompile(`^default-src 'self'; script-src 'nonce-[A-Za-z0-9+/=]+'$`).MatchString(got) {
+		t.Errorf("custom Content-Security-Policy = %q", got)
+	}
+	if got := cspHeader(custom, ""); got != "default-src 'self'; script-src" {
+		t.Errorf("cspHeader() without a nonce = %q", got)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d", rec.Code)
+	}
+}
+
+func TestCSPNonceETagRevalidation(t *testing.T) {
+	for _, csp := range []config.CSPConfig{
+		{Strict: true},
+		{Policy: "default-src 'self'; script-src 'nonce-{nonce}'"},
+	} {
+		srv := newTestServer(t, cspSiteFiles, withCSP(csp))
+		first := httptest.NewRecorder()
+		srv.ServeHTTP(first, httptest.NewRequest("GET", "/", nil))
+		etag := first.Header().Get("ETag")
+		if first.Code != http.StatusOK || etag == "" || !strings.Contains(first.Body.String(), "nonce=") {
+			t.Fatalf("GET / with %+v = %d with ETag %q, want 200 with an ETag and nonces", csp, first.Code, etag)
+		}
+
+		// The nonce differs on every response, but the page hasn't changed
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", etag)
+		repeat := httptest.NewRecorder()
+		srv.ServeHTTP(repeat, req)
+		if repeat.Code != http.StatusNotModified || repeat.Body.Len() != 0 {
+			t.Errorf("GET / with %+v and If-None-Match %s = %d, want 304 without a body", csp, etag, repeat.Code)
+		}
+		// The cached copy's policy must keep naming the nonce its scripts carry
+		if got := repeat.Header().Get("Content-Security-Policy"); got != "" {
+			t.Errorf("304 Content-Security-Policy = %q, want none", got)
+		}
+	}
+}
+
+func TestCSPNonceSkipsContentScripts(t *testing.T) {
+	// Markdown drops raw HTML, so put the script straight into the page
+	page := tinkerdown.New("injected")
+	page.Title = "Injected"
+	page.StaticHTML = "<h1>Injected</h1>\n<script>alert(1)</script>\n"
+
+	for _, strict := range []bool{true, false} {
+		srv := newTestServer(t, cspSiteFiles, withCSP(config.CSPConfig{Strict: strict}))
+		rec := httptest.NewRecorder()
+		body := srv.applyCSPNonce(rec, srv.renderPage(context.Background(), page, "/", "localhost"))
+		if !strings.Contains(body, "<script>alert(1)</script>") {
+			t.Errorf("strict=%v: content script should be left without a nonce", strict)
+		}
+		if strings.Contains(body, scriptNonceMark) {
+			t.Errorf("strict=%v: page still carries the nonce mark", strict)
+		}
+		if got := strings.Contains(body, `<script nonce="`); got != strict {
+			t.Errorf("strict=%v: server scripts have a nonce = %v", strict, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/livetemplate/tinkerdown"
+)
+
+// errorPagesDir holds a site's error pages, named by status: _errors/404.md
+// and _errors/500.md. Like other directories starting with _, it isn't
+// served as pages.
+const errorPagesDir = "_errors"
+
+// defaultErrorPages are the built-in error pages, for sites without their
+// own. Unknown paths without a 404 page are redirected to the first page
+// instead.
+var defaultErrorPages = map[int]string{
+	http.StatusInternalServerError: "---\ntitle: Something went wrong\n---\n\n# Something went wrong\n\nThe server couldn't show this page. Try again in a moment.\n",
+}
+
+// customErrorPage parses the site's page for status from _errors, or returns
+// nil if there's none. It's read on each use, so edits apply without a
+// restart. The lookup can't leave _errors, even through symlinks.
+func (s *Server) customErrorPage(status int) *tinkerdown.Page {
+	dir := filepath.Join(s.rootDir, errorPagesDir)
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil
+	}
+	defer root.Close()
+
+	name := fmt.Sprintf("%d.md", status)
+	if _, err := root.Stat(name); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			serverLog.Warnf("Error page %s/%s: %v", errorPagesDir, name, err)
+		}
+		return nil
+	}
+	page, err := s.markdown.ParseFile(filepath.Join(dir, name))
+	if err != nil {
+		serverLog.Warnf("Failed to parse %s/%s: %v", errorPagesDir, name, err)
+		return nil
+	}
+	return page
+}
+
+// serveErrorPage answers with status and page, the site's error page for
+// it, or the built-in one when page is nil. It's rendered like any other
+// page, with the site's theme and navigation, but never cached or indexed.
+// The caller holds s.mu.
+func (s *Server) serveErrorPage(w http.ResponseWriter, r *http.Request, status int, page *tinkerdown.Page) {
+	if page == nil {
+		var err error
+		content, ok := defaultErrorPages[status]
+		if ok {
+			page, err = s.markdown.ParseString(content)
+		}
+		if !ok || err != nil {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+	page.NoIndex = true
+	html := s.applyCSPNonce(w, s.renderPage(r.Context(), page, r.URL.Path, r.Host))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write([]byte(html))
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomNotFoundPage(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	// Without a 404 page, unknown paths go to the first page
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("status without a 404 page = %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, errorPagesDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notFound := "---\ntitle: Lost\n---\n\n# Nothing here\n\nTry the search.\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, errorPagesDir, "404.md"), []byte(notFound), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if !strings.Contains(body, "Nothing here") || !strings.Contains(body, "<title>Lost") {
+		t.Errorf("body should be the rendered 404 page, got: %.300s", body)
+	}
+	if !strings.Contains(body, `<meta name="robots" content="noindex">`) {
+		t.Error("error pages should not be indexed")
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+	}
+
+	// The error page isn't a route of its own
+	for _, route := range srv.Routes() {
+		if strings.Contains(route.Pattern, errorPagesDir) {
+			t.Errorf("route %s serves an error page", route.Pattern)
+		}
+	}
+}
+
+func TestErrorPageOutsideErrorsDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret.md")
+	if err := os.WriteFile(outside, []byte("# Secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, errorPagesDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(tmpDir, errorPagesDir, "404.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if page := New(tmpDir).customErrorPage(http.StatusNotFound); page != nil {
+		t.Error("customErrorPage() should not follow a symlink out of _errors")
+	}
+}
+
+func TestServeErrorPageDefault(t *testing.T) {
+	srv := New(t.TempDir())
+	rec := httptest.NewRecorder()
+	srv.serveErrorPage(rec, httptest.NewRequest("GET", "/broken", nil), http.StatusInternalServerError, nil)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "Something went wrong") {
+		t.Errorf("got %d %.200s, want the built-in 500 page", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/livetemplate/tinkerdown/internal/assets"
+)
+
+// clientAsset is a client bundle that pages load from a fingerprinted URL,
+// such as /assets/tinkerdown-client.1a2b3c4d.js, so browsers can cache it
+// forever and still pick up a new bundle after an upgrade. It's gzipped once,
+// when first loaded, for clients that accept it.
+type clientAsset struct {
+	name        string // Unversioned file name, e.g. "tinkerdown-client.js"
+	ext         string
+	contentType string
+	load        func() ([]byte, error)
+
+	once       sync.Once
+	content    []byte
+	gzipped    []byte // content, gzipped; nil if compressing failed
+	hashedName string
+	err        error
+}
+
+var (
+	clientJS = &clientAsset{
+		name:        "tinkerdown-client.js",
+		ext:         ".js",
+		contentType: "application/javascript",
+		load:        assets.GetClientJS,
+	}
+	clientCSS = &clientAsset{
+		name:        "tinkerdown-client.css",
+		ext:         ".css",
+		contentType: "text/css",
+		load:        assets.GetClientCSS,
+	}
+	// pageCSS styles the page layout, toolbar, sidebar and content; it's
+	// minified from internal/assets/page
+	pageCSS = &clientAsset{
+		name:        "tinkerdown-page.css",
+		ext:         ".css",
+		contentType: "text/css",
+		load:        assets.GetPageCSS,
+	}
+	clientAssets = []*clientAsset{clientJS, clientCSS, pageCSS}
+)
+
+// fingerprint returns the first 8 hex digits of content's SHA-256.
+func fingerprint(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:4])
+}
+
+func (a *clientAsset) get() ([]byte, error) {
+	a.once.Do(func() {
+		a.content, a.err = a.load()
+		if a.err == nil {
+			base := a.name[:len(a.name)-len(a.ext)]
+			a.hashedName = base + "." + fingerprint(a.content) + a.ext
+			a.gzipped = gzipBytes(a.content)
+		}
+	})
+	return a.content, a.err
+}
+
+// gzipBytes returns b compressed at the best level, or nil on failure.
+func gzipBytes(b []byte) []byte {
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil
+	}
+	if _, err := gz.Write(b); err != nil {
+		return nil
+	}
+	if err := gz.Close(); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// URL returns the path pages should load the asset from: the fingerprinted
+// name, or the unversioned one if the asset can't be read.
+func (a *clientAsset) URL() string {
+	if _, err := a.get(); err != nil {
+		return "/assets/" + a.name
+	}
+	return "/assets/" + a.hashedName
+}
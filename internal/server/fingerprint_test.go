@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/assets"
+)
+
+func TestFingerprintedClientAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	page := rec.Body.String()
+
+	tests := []struct {
+		pattern string
+		load    func() ([]byte, error)
+	}{
+		{`<script src="(/assets/tinkerdown-client\.([0-9a-f]+)\.js)">`, assets.GetClientJS},
+		{`<link rel="stylesheet" href="(/assets/tinkerdown-client\.([0-9a-f]+)\.css)">`, assets.GetClientCSS},
+		{`<link rel="stylesheet" href="(/assets/tinkerdown-page\.([0-9a-f]+)\.css)">`, assets.GetPageCSS},
+	}
+	for _, tt := range tests {
+		m := regexp.MustCompile(tt.pattern).FindStringSubmatch(page)
+		if m == nil {
+			t.Fatalf("page has no tag matching %s", tt.pattern)
+		}
+		url, hash := m[1], m[2]
+
+		content, err := tt.load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(content)
+		if want := hex.EncodeToString(sum[:])[:len(hash)]; hash != want || len(hash) < 8 {
+			t.Errorf("%s hash = %q, want a prefix of the content's SHA-256 %q", url, hash, want)
+		}
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), content) {
+			t.Errorf("GET %s = %d, want 200 with the asset content", url, rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != cacheImmutable {
+			t.Errorf("GET %s Cache-Control = %q, want %q", url, got, cacheImmutable)
+		}
+	}
+}
+
+func TestPageStylesheetPrecompressed(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	want, err := assets.GetPageCSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The page no longer inlines it
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if strings.Contains(rec.Body.String(), ".page-toolbar {") {
+		t.Error("page should link the stylesheet instead of inlining it")
+	}
+
+	for _, handler := range []http.Handler{srv, WithCompression(srv)} {
+		// Clients that accept gzip get the precompressed copy, once
+		req := httptest.NewRequest(http.MethodGet, pageCSS.URL(), nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("Content-Encoding = %q, want gzip", got)
+		}
+		if !bytes.Equal(rec.Body.Bytes(), pageCSS.gzipped) {
+			t.Error("gzip clients should get the precompressed stylesheet as is")
+		}
+		gz, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		got, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatalf("decompressing: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Error("decompressed stylesheet differs from the minified source")
+		}
+		if !strings.Contains(rec.Header().Get("Vary"), "Accept-Encoding") {
+			t.Error("response should vary on Accept-Encoding")
+		}
+
+		// Other clients get it uncompressed
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, pageCSS.URL(), nil))
+		if rec.Header().Get("Content-Encoding") != "" || !bytes.Equal(rec.Body.Bytes(), want) {
+			t.Errorf("uncompressed response has Content-Encoding %q and %d bytes, want none and %d", rec.Header().Get("Content-Encoding"), rec.Body.Len(), len(want))
+		}
+	}
+}
+
+// pageStylesheet returns the page stylesheet srv serves.
+func pageStylesheet(t *testing.T, srv *Server) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, pageCSS.URL(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d, want 200", pageCSS.URL(), rec.Code)
+	}
+	return rec.Body.String()
+}
+
+// hasCSS reports whether the minified stylesheet css holds rule, written
+// as in the source.
+func hasCSS(css, rule string) bool {
+	return strings.Contains(css, strings.TrimSuffix(string(assets.MinifyCSS([]byte(rule))), ";"))
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// imageVariantPrefix is the URL prefix of resized image variants, as in
+// /_images/480/screenshots/editor.png.
+const imageVariantPrefix = "/_images/"
+
+// imageSizes tells browsers how wide content images are displayed: the full
+// viewport on small screens, otherwise at most the 900px content column.
+const imageSizes = "(max-width: 900px) 100vw, 900px"
+
+// imgTagPattern matches an <img> tag.
+var imgTagPattern = regexp.MustCompile(`<img\b[^>]*>`)
+
+// imgSrcPattern captures the src attribute of an <img> tag.
+var imgSrcPattern = regexp.MustCompile(`\ssrc="([^"]*)"`)
+
+// responsiveImages rewrites the local PNG and JPEG images in content to load
+// lazily and offer resized variants through srcset. pageFile is the page's
+// .md file, which relative image paths are resolved against. Remote images,
+// images that already have a srcset and files outside the site root are left
+// alone.
+func (s *Server) responsiveImages(content, pageFile string, widths []int) string {
+	return imgTagPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		m := imgSrcPattern.FindStringSubmatch(tag)
+		if m == nil || strings.Contains(tag, " srcset=") {
+			return tag
+		}
+		src := html.UnescapeString(m[1])
+		relPath, ok := s.localImagePath(src, filepath.Dir(pageFile))
+		if !ok {
+			return tag
+		}
+		cfg, err := decodeImageConfig(filepath.Join(s.rootDir, relPath))
+		if err != nil {
+			return tag
+		}
+
+		var attrs string
+		if !strings.Contains(tag, " loading=") {
+			attrs = ` loading="lazy"`
+		}
+		if srcset := imageSrcset(src, relPath, cfg.Width, widths); srcset != "" {
+			attrs += fmt.Sprintf(` srcset="%s" sizes="%s"`, html.EscapeString(srcset), imageSizes)
+		}
+		end := strings.TrimSuffix(strings.TrimSuffix(tag, ">"), "/")
+		return strings.TrimRight(end, " ") + attrs + tag[len(end):]
+	})
+}
+
+// localImagePath resolves an image src to a slash-separated path under the
+// site root. It reports false for remote or data URLs, formats that can't be
+// resized and paths that escape the root.
+func (s *Server) localImagePath(src, pageDir string) (string, bool) {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	return s.resolveImagePath(u.Path, pageDir)
+}
+
+// resolveImagePath resolves a URL path, absolute or relative to pageDir, to
+// a slash-separated path under the site root. It reports false for formats
+// that can't be resized and paths that escape the root.
+func (s *Server) resolveImagePath(urlPath, pageDir string) (string, bool) {
+	if !isResizableImage(urlPath) {
+		return "", false
+	}
+
+	var abs string
+	if strings.HasPrefix(urlPath, "/") {
+		abs = filepath.Join(s.rootDir, filepath.FromSlash(urlPath))
+	} else {
+		abs = filepath.Join(pageDir, filepath.FromSlash(urlPath))
+	}
+	rel, err := filepath.Rel(s.rootDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// isResizableImage reports whether path names a PNG or JPEG image.
+func isResizableImage(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
+
+// imageSrcset lists a variant for each width smaller than the original
+// (width pixels wide), followed by the original src itself. It returns ""
+// when no variant would be smaller than the original.
+func imageSrcset(src, relPath string, width int, widths []int) string {
+	var candidates []string
+	for _, w := range widths {
+		if w >= width {
+			break
+		}
+		variant := (&url.URL{Path: fmt.Sprintf("%s%d/%s", imageVariantPrefix, w, relPath)}).EscapedPath()
+		candidates = append(candidates, fmt.Sprintf("%s %dw", variant, w))
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return strings.Join(append(candidates, fmt.Sprintf("%s %dw", src, width)), ", ")
+}
+
+func decodeImageConfig(path string) (image.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	return cfg, err
+}
+
+// imageVariant is a resized image kept in memory.
+type imageVariant struct {
+	contentType string
+	data        []byte
+}
+
+// maxCachedImageVariants bounds a server's variant cache; it is cleared when
+// full.
+const maxCachedImageVariants = 256
+
+// serveImageVariant serves /_images/<width>/<path>: the image at path under
+// the site root, scaled down to one of the configured widths.
+func (s *Server) serveImageVariant(w http.ResponseWriter, r *http.Request) {
+	widthStr, relPath, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, imageVariantPrefix), "/")
+	width, err := strconv.Atoi(widthStr)
+	if !ok || err != nil || !slices.Contains(s.config.Images.GetWidths(), width) {
+		http.NotFound(w, r)
+		return
+	}
+	relPath, ok = s.resolveImagePath("/"+relPath, s.rootDir)
+	if !ok || isHiddenPath(relPath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	path := filepath.Join(s.rootDir, filepath.FromSlash(relPath))
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Keyed by the absolute path and mtime, so an edited image or another
+	// site's image at the same relative path is never served from the cache.
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	key := fmt.Sprintf("%d:%d:%s", width, info.ModTime().UnixNano(), path)
+	s.imageVariantsMu.Lock()
+	variant := s.imageVariants[key]
+	s.imageVariantsMu.Unlock()
+
+	if variant == nil {
+		variant, err = resizeImageFile(path, width)
+		if err != nil {
+			serverLog.Warnf("Failed to resize %s: %v", relPath, err)
+			http.Error(w, "Failed to resize image", http.StatusInternalServerError)
+			return
+		}
+
+		s.imageVariantsMu.Lock()
+		if s.imageVariants == nil || len(s.imageVariants) >= maxCachedImageVariants {
+			s.imageVariants = make(map[string]*imageVariant)
+		}
+		s.imageVariants[key] = variant
+		s.imageVariantsMu.Unlock()
+	}
+
+	cacheControl := cachePage
+	if s.watching() {
+		cacheControl = cacheRevalidate
+	}
+	serveCacheable(w, r, variant.contentType, cacheControl, variant.data)
+}
+
+// isHiddenPath reports whether any element of a slash-separated path starts
+// with "_" or ".".
+func isHiddenPath(relPath string) bool {
+	for _, part := range strings.Split(relPath, "/") {
+		if strings.HasPrefix(part, "_") || strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// resizeImageFile scales the PNG or JPEG at path down to width pixels wide,
+// keeping its aspect ratio and format. Images no wider than width are
+// re-encoded at their original size.
+func resizeImageFile(path string, width int) (*imageVariant, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, format, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if b := img.Bounds(); b.Dx() > width {
+		height := max(1, b.Dy()*width/b.Dx())
+		img = scaleDown(img, width, height)
+	}
+
+	var buf bytes.Buffer
+	variant := &imageVariant{}
+	switch format {
+	case "png":
+		variant.contentType = "image/png"
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		variant.contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	default:
+		return nil, fmt.Errorf("unsupported image format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	variant.data = buf.Bytes()
+	return variant, nil
+}
+
+// scaleDown resizes src to width x height (both no larger than src) by
+// averaging the source pixels that each destination pixel covers.
+func scaleDown(src image.Image, width, height int) *image.RGBA {
+	b := src.Bounds()
+	in := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(in, in.Bounds(), src, b.Min, draw.Src)
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*b.Dy()/height, (y+1)*b.Dy()/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*b.Dx()/width, (x+1)*b.Dx()/width
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := in.Pix[sy*in.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					bl += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+			o := out.PixOffset(x, y)
+			out.Pix[o] = uint8(r / n)
+			out.Pix[o+1] = uint8(g / n)
+			out.Pix[o+2] = uint8(bl / n)
+			out.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestPNG writes a width x height PNG to path, creating its directory.
+func writeTestPNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResponsiveImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestPNG(t, filepath.Join(tmpDir, "guides", "img", "shot.png"), 2000, 1000)
+	writeTestPNG(t, filepath.Join(tmpDir, "guides", "icon.png"), 300, 300)
+	page := "# Guide\n\n![Shot](img/shot.png)\n\n![Icon](icon.png)\n\n![Remote](https://example.com/a.png)\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "guides", "intro.md"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	body := get("/guides/intro").Body.String()
+
+	wantShot := `<img src="img/shot.png" alt="Shot" loading="lazy" srcset="/_images/480/guides/img/shot.png 480w, /_images/960/guides/img/shot.png 960w, /_images/1600/guides/img/shot.png 1600w, img/shot.png 2000w" sizes="` + imageSizes + `">`
+	if !strings.Contains(body, wantShot) {
+		t.Errorf("page is missing %s", wantShot)
+	}
+	// Smaller than every variant: lazy loading only
+	if want := `<img src="icon.png" alt="Icon" loading="lazy">`; !strings.Contains(body, want) {
+		t.Errorf("page is missing %s", want)
+	}
+	// Remote images are left alone
+	if want := `<img src="https://example.com/a.png" alt="Remote">`; !strings.Contains(body, want) {
+		t.Errorf("page is missing %s", want)
+	}
+
+	rec := get("/_images/480/guides/img/shot.png")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("GET variant = %d (%s), want a PNG", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("decoding variant: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 480 || b.Dy() != 240 {
+		t.Errorf("variant size = %dx%d, want 480x240", b.Dx(), b.Dy())
+	}
+
+	for _, path := range []string{
+		"/_images/500/guides/img/shot.png", // Not a configured width
+		"/_images/480/../outside.png",
+		"/_images/480/guides/intro.md",
+	} {
+		if code := get(path).Code; code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, code)
+		}
+	}
+}
+
+func TestImageVariantsArePerServer(t *testing.T) {
+	// Two sites with an image at the same relative path and mtime but
+	// different aspect ratios; each server must resize its own.
+	heights := []int{1000, 500}
+	mtime := time.Now().Add(-time.Hour)
+	var servers []*Server
+	for _, h := range heights {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "img", "shot.png")
+		writeTestPNG(t, path, 2000, h)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		servers = append(servers, New(tmpDir))
+	}
+
+	for i, srv := range servers {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_images/480/img/shot.png", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("server %d: GET variant = %d, want 200", i, rec.Code)
+		}
+		img, err := png.Decode(rec.Body)
+		if err != nil {
+			t.Fatalf("server %d: decoding variant: %v", i, err)
+		}
+		if want := heights[i] * 480 / 2000; img.Bounds().Dy() != want {
+			t.Errorf("server %d: variant height = %d, want %d", i, img.Bounds().Dy(), want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+// jumpNavHTML is a floating group of buttons for long pages: previous
+// section, back to top and next section, jumping between the content's H2
+// headings. It appears once the reader has scrolled past the first screen,
+// follows prefers-reduced-motion, and is hidden in presentation mode and
+// when printing.
+const jumpNavHTML = `
+    <nav class="jump-nav" aria-label="Page navigation" hidden>
+        <button type="button" class="jump-prev" title="Previous section" aria-label="Previous section">
+            <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"><polyline points="18 15 12 9 6 15"/></svg>
+        </button>
+        <button type="button" class="jump-top" title="Back to top" aria-label="Back to top">
+            <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"><line x1="5" y1="4" x2="19" y2="4"/><polyline points="18 14 12 8 6 14"/><line x1="12" y1="8" x2="12" y2="21"/></svg>
+        </button>
+        <button type="button" class="jump-next" title="Next section" aria-label="Next section">
+            <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"><polyline points="6 9 12 15 18 9"/></svg>
+        </button>
+    </nav>
+    <script>
+        (function() {
+            const nav = document.querySelector('.jump-nav');
+            if (!nav) return;
+            const reducedMotion = window.matchMedia('(prefers-reduced-motion: reduce)');
+            let pending = false;
+
+            function headings() {
+                return Array.from(document.querySelectorAll('.content-wrapper h2[id]'));
+            }
+
+            // Where a heading sits relative to the spot a jump lands it at,
+            // just below the toolbar (its scroll-margin-top)
+            function offset(h) {
+                return h.getBoundingClientRect().top - (parseFloat(getComputedStyle(h).scrollMarginTop) || 0);
+            }
+
+            function previousHeading() {
+                return headings().filter(h => offset(h) < -5).pop();
+            }
+
+            function nextHeading() {
+                return headings().find(h => offset(h) > 5);
+            }
+
+            function behavior() {
+                return reducedMotion.matches ? 'auto' : 'smooth';
+            }
+
+            function update() {
+                pending = false;
+                nav.hidden = window.scrollY < window.innerHeight;
+                nav.querySelector('.jump-prev').disabled = !previousHeading();
+                nav.querySelector('.jump-next').disabled = !nextHeading();
+            }
+
+            function schedule() {
+                if (!pending) {
+                    pending = true;
+                    window.requestAnimationFrame(update);
+                }
+            }
+
+            nav.querySelector('.jump-top').addEventListener('click', () => {
+                window.scrollTo({ top: 0, behavior: behavior() });
+            });
+            nav.querySelector('.jump-prev').addEventListener('click', () => {
+                const h = previousHeading();
+                if (h) h.scrollIntoView({ behavior: behavior(), block: 'start' });
+            });
+            nav.querySelector('.jump-next').addEventListener('click', () => {
+                const h = nextHeading();
+                if (h) h.scrollIntoView({ behavior: behavior(), block: 'start' });
+            });
+
+            window.addEventListener('scroll', schedule, { passive: true });
+            window.addEventListener('resize', schedule);
+            update();
+        })();
+    </script>`
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestRenderPageJumpNav(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n\n## One\n\n## Two\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var srv *Server
+	render := func(cfg *config.Config) string {
+		srv = NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := render(config.DefaultConfig())
+	for _, want := range []string{
+		`<nav class="jump-nav" aria-label="Page navigation" hidden>`,
+		"prefers-reduced-motion: reduce",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if !hasCSS(pageStylesheet(t, srv), "body.presentation-mode .jump-nav {") {
+		t.Error("stylesheet should hide the jump buttons in presentation mode")
+	}
+
+	off := false
+	cfg := config.DefaultConfig()
+	cfg.JumpNav.Enabled = &off
+	if body := render(cfg); strings.Contains(body, `<nav class="jump-nav"`) {
+		t.Error("jump_nav.enabled: false should hide the jump buttons")
+	}
+}
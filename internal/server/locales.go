@@ -0,0 +1,158 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// Sites written in several languages list them under locales, and keep
+// each language's pages in a top-level directory named after its code:
+//
+//	locales:
+//	  - code: en
+//	    label: English
+//	  - code: fr
+//	    label: Français
+//
+// en/intro.md is served at /en/intro and its translation fr/intro.md at
+// /fr/intro. Pages at the same path under two locale directories are
+// translations of each other, which is what the toolbar's language switcher
+// links between. A path outside the locale directories (say / or /intro)
+// redirects to the same path under the reader's language, picked from
+// Accept-Language, or the first locale.
+
+// groupTranslations indexes the routes under locale directories by their
+// path within the locale. The caller must hold s.mu.
+func (s *Server) groupTranslations() {
+	s.translations = make(map[string]map[string]string)
+	for _, route := range s.routes {
+		locale := s.config.PathLocale(route.Pattern)
+		if locale == "" {
+			continue
+		}
+		key := strings.TrimPrefix(route.Pattern, "/"+locale)
+		if s.translations[key] == nil {
+			s.translations[key] = make(map[string]string)
+		}
+		s.translations[key][locale] = route.Pattern
+	}
+}
+
+// pageLang returns the language of the page at currentPath for the <html>
+// lang attribute.
+func (s *Server) pageLang(currentPath string) string {
+	if locale := s.config.PathLocale(currentPath); locale != "" {
+		return locale
+	}
+	return "en"
+}
+
+// renderLocaleSwitcher returns the toolbar's links to the page at
+// currentPath in each locale. A locale without a translation of the page
+// links to its home page instead.
+func (s *Server) renderLocaleSwitcher(currentPath string) string {
+	if len(s.config.Locales) == 0 {
+		return ""
+	}
+	locale := s.config.PathLocale(currentPath)
+	var pages map[string]string
+	if locale != "" {
+		pages = s.translations[strings.TrimPrefix(currentPath, "/"+locale)]
+	}
+
+	var b strings.Builder
+	b.WriteString(`
+        <nav class="locale-switcher" aria-label="Language">`)
+	for _, l := range s.config.Locales {
+		href, ok := pages[l.Code]
+		if !ok {
+			href = "/" + l.Code + "/"
+		}
+		current := ""
+		if l.Code == locale {
+			current = ` aria-current="page"`
+		}
+		code := html.EscapeString(l.Code)
+		fmt.Fprintf(&b, "\n            <a href=\"%s\" hreflang=\"%s\" lang=\"%s\" title=\"%s\"%s>%s</a>",
+			html.EscapeString(href), code, code, html.EscapeString(l.GetLabel()), current, code)
+	}
+	b.WriteString(`
+        </nav>`)
+	return b.String()
+}
+
+// localeRedirect returns where to send a request for a path outside every
+// locale directory: the same path under the reader's preferred locale, or
+// under the first locale when the preferred one lacks the page. The caller
+// must hold s.mu.
+func (s *Server) localeRedirect(r *http.Request) (string, bool) {
+	if len(s.config.Locales) == 0 || s.config.PathLocale(r.URL.Path) != "" {
+		return "", false
+	}
+	for _, locale := range []string{preferredLocale(s.config.Locales, r.Header.Get("Accept-Language")), s.config.Locales[0].Code} {
+		target := "/" + locale + r.URL.Path
+		for _, route := range s.routes {
+			if route.Pattern == target {
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
+
+// preferredLocale returns the locale that best matches an Accept-Language
+// header, or the first locale when none does.
+func preferredLocale(locales []config.LocaleConfig, acceptLanguage string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if q <= bestQ {
+			continue
+		}
+		if code := matchLocale(locales, strings.TrimSpace(tag)); code != "" {
+			best, bestQ = code, q
+		}
+	}
+	if best == "" {
+		return locales[0].Code
+	}
+	return best
+}
+
+// matchLocale returns the locale for a language tag: one with the same tag
+// or, failing that, the same language ("fr-CA" matches "fr", "pt" matches
+// "pt-BR").
+func matchLocale(locales []config.LocaleConfig, tag string) string {
+	if tag == "" || tag == "*" {
+		return ""
+	}
+	for _, l := range locales {
+		if strings.EqualFold(l.Code, tag) {
+			return l.Code
+		}
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	for _, l := range locales {
+		code, _, _ := strings.Cut(l.Code, "-")
+		if strings.EqualFold(code, lang) {
+			return l.Code
+		}
+	}
+	return ""
+}
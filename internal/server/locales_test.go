@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// localeSiteFiles is a site translated into English, French and Portuguese.
+var localeSiteFiles = map[string]string{
+	"en/index.md":    "# Home",
+	"en/intro.md":    "# Intro",
+	"en/setup.md":    "# Setup",
+	"fr/index.md":    "# Accueil",
+	"fr/intro.md":    "# Introduction",
+	"pt-BR/index.md": "# Início",
+}
+
+// withLocales configures the locales of localeSiteFiles for a siteType site.
+func withLocales(siteType string) func(*config.Config) {
+	return func(cfg *config.Config) {
+		cfg.Type = siteType
+		cfg.Locales = []config.LocaleConfig{
+			{Code: "en", Label: "English"},
+			{Code: "fr", Label: "Français"},
+			{Code: "pt-BR"},
+		}
+	}
+}
+
+func TestDiscoverGroupsTranslations(t *testing.T) {
+	for _, siteType := range []string{"tutorial", "site"} {
+		srv := newTestServer(t, localeSiteFiles, withLocales(siteType))
+
+		intro := srv.translations["/intro"]
+		if intro["en"] != "/en/intro" || intro["fr"] != "/fr/intro" || len(intro) != 2 {
+			t.Errorf("%s: translations of /intro = %v", siteType, intro)
+		}
+		if home := srv.translations["/"]; len(home) != 3 || home["pt-BR"] != "/pt-BR/" {
+			t.Errorf("%s: translations of / = %v", siteType, home)
+		}
+		if setup := srv.translations["/setup"]; len(setup) != 1 {
+			t.Errorf("%s: translations of /setup = %v", siteType, setup)
+		}
+	}
+}
+
+func TestLocaleSwitcherLinks(t *testing.T) {
+	srv := newTestServer(t, localeSiteFiles, withLocales("site"))
+	render := func(path string) string {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("GET %s: status = %d, want 200", path, w.Code)
+		}
+		return w.Body.String()
+	}
+
+	body := render("/fr/intro")
+	for _, want := range []string{
+		`<html lang="fr">`,
+		`<nav class="locale-switcher" aria-label="Language">`,
+		`<a href="/en/intro" hreflang="en" lang="en" title="English">en</a>`,
+		`<a href="/fr/intro" hreflang="fr" lang="fr" title="Français" aria-current="page">fr</a>`,
+		// No Portuguese translation: link to its home page
+		`<a href="/pt-BR/" hreflang="pt-BR" lang="pt-BR" title="pt-BR">pt-BR</a>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /fr/intro: page missing %q", want)
+		}
+	}
+
+	// The sidebar only lists pages in the page's locale
+	body = render("/en/setup")
+	if !strings.Contains(body, `href="/en/intro"`) || strings.Contains(body, `class="nav-page-link">Introduction`) {
+		t.Error("sidebar of an English page should list English pages only")
+	}
+	if !strings.Contains(body, `<a href="/fr/" hreflang="fr"`) {
+		t.Error("switcher should link a page without a translation to the locale's home")
+	}
+}
+
+func TestLocaleRedirect(t *testing.T) {
+	srv := newTestServer(t, localeSiteFiles, withLocales("site"))
+
+	tests := []struct {
+		path           string
+		acceptLanguage string
+		location       string
+	}{
+		{"/", "", "/en/"},
+		{"/", "fr-CA,fr;q=0.9,en;q=0.8", "/fr/"},
+		{"/", "de, pt;q=0.5", "/pt-BR/"},
+		{"/intro", "fr", "/fr/intro"},
+		{"/intro?x=1", "en;q=0.2, fr;q=0.7", "/fr/intro?x=1"},
+		// No French version: fall back to the first locale
+		{"/setup", "fr", "/en/setup"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+
+		if w.Code != 302 {
+			t.Errorf("GET %s (%s): status = %d, want 302", tt.path, tt.acceptLanguage, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("GET %s (%s): Location = %q, want %q", tt.path, tt.acceptLanguage, got, tt.location)
+		}
+		if w.Header().Get("Vary") != "Accept-Language" {
+			t.Errorf("GET %s: redirect should vary on Accept-Language", tt.path)
+		}
+	}
+}
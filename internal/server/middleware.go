@@ -7,7 +7,6 @@ import (
 	"crypto/subtle"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"os"
@@ -84,17 +83,7 @@ func SecurityHeadersMiddleware() func(http.Handler) http.Handler {
 			w.Header().Set("X-Frame-Options", "DENY")
 			w.Header().Set("X-Content-Type-Options", "nosniff")
 			w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-			// CSP: allow self, inline styles (needed for PicoCSS and LVT rendering),
-			// unsafe-eval (needed for Mermaid.js), and data: URIs for fonts/images.
-			// connect-src 'self' covers same-origin WebSocket (ws/wss) connections.
-			w.Header().Set("Content-Security-Policy",
-				"default-src 'self'; "+
-					"script-src 'self' 'unsafe-inline' 'unsafe-eval'; "+
-					"style-src 'self' 'unsafe-inline'; "+
-					"img-src 'self' data: https:; "+
-					"font-src 'self' data:; "+
-					"connect-src 'self'; "+
-					"frame-ancestors 'none'")
+			w.Header().Set("Content-Security-Policy", defaultCSP)
 
 			next.ServeHTTP(w, r)
 		})
@@ -208,7 +197,7 @@ func rateLimitMiddlewareInternal(
 						delete(items, evicted.ip)
 						evictCount++
 						if time.Since(lastEvictLog) >= evictLogInterval {
-							log.Printf("[RateLimit] Evicted %d least-recent IP(s) (at capacity: %d IPs)", evictCount, maxIPs)
+							rateLimitLog.Warnf("Evicted %d least-recent IP(s) (at capacity: %d IPs)", evictCount, maxIPs)
 							lastEvictLog = time.Now()
 							evictCount = 0
 						}
@@ -366,7 +355,7 @@ func shardedRateLimitMiddlewareInternal(
 						delete(s.items, evicted.ip)
 						s.evictCount++
 						if time.Since(s.lastEvictLog) >= sl.evictLogInterval {
-							log.Printf("[RateLimit] Evicted %d least-recent IP(s) (shard capacity: %d, total: %d IPs)",
+							rateLimitLog.Warnf("Evicted %d least-recent IP(s) (shard capacity: %d, total: %d IPs)",
 								s.evictCount, s.maxIPs, sl.totalMaxIPs)
 							s.lastEvictLog = time.Now()
 							s.evictCount = 0
@@ -462,7 +451,7 @@ func AuthMiddleware(authCfg *config.AuthConfig) func(http.Handler) http.Handler
 		for i := range apiKeys {
 			expanded := os.ExpandEnv(apiKeys[i].Key)
 			if expanded == "" {
-				log.Printf("[Auth] Warning: key %q expanded to empty string (check env var config)", apiKeys[i].Name)
+				authLog.Warnf("key %q expanded to empty string (check env var config)", apiKeys[i].Name)
 			}
 			keys[i] = expandedKey{
 				value:  expanded,
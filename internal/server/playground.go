@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"crypto/rand"
 	"embed"
 	"encoding/hex"
@@ -80,7 +81,7 @@ func (h *PlaygroundHandler) ServePlaygroundPage(w http.ResponseWriter, r *http.R
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write(content)
+	w.Write([]byte(h.server.applyCSPNonce(w, nonceScripts(string(content)))))
 }
 
 // RenderRequest is the JSON request body for /playground/render.
@@ -123,7 +124,7 @@ func (h *PlaygroundHandler) HandleRender(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Parse the markdown
-	page, err := tinkerdown.ParseString(req.Markdown)
+	page, err := h.server.markdown.ParseString(req.Markdown)
 	if err != nil {
 		h.jsonError(w, fmt.Sprintf("Failed to parse markdown: %v", err), http.StatusBadRequest)
 		return
@@ -170,7 +171,7 @@ func (h *PlaygroundHandler) HandlePreview(w http.ResponseWriter, r *http.Request
 	}
 
 	// Render the page
-	html := h.renderPage(session.Page, r.Host)
+	html := h.server.applyCSPNonce(w, h.renderPage(r.Context(), session.Page, r.Host))
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write([]byte(html))
@@ -200,9 +201,9 @@ func (h *PlaygroundHandler) HandlePreviewWS(w http.ResponseWriter, r *http.Reque
 }
 
 // renderPage renders a page to HTML using the server's rendering logic.
-func (h *PlaygroundHandler) renderPage(page *tinkerdown.Page, host string) string {
+func (h *PlaygroundHandler) renderPage(ctx context.Context, page *tinkerdown.Page, host string) string {
 	// Use the server's renderPage method for consistent output
-	return h.server.renderPage(page, "/playground/preview", host)
+	return h.server.renderPage(ctx, page, "/playground/preview", host)
 }
 
 // jsonError sends a JSON error response.
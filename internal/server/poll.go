@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// pollAttributes adds data-poll, in milliseconds, to the container of each
+// interactive block bound to a source with options["poll"]. The client sends
+// the block a Refresh at that interval while the tab is visible. A block
+// combining several polled sources uses the shortest interval.
+func (s *Server) pollAttributes(content string, page *tinkerdown.Page) string {
+	for id, block := range page.InteractiveBlocks {
+		sb, ok := page.ServerBlocks[block.StateRef]
+		if !ok {
+			continue
+		}
+		var interval time.Duration
+		for _, name := range tinkerdown.SourceNames(sb.Metadata["lvt-source"]) {
+			if d := s.sourcePollInterval(page, name); d > 0 && (interval == 0 || d < interval) {
+				interval = d
+			}
+		}
+		if interval == 0 {
+			continue
+		}
+		attr := fmt.Sprintf(`data-block-id="%s"`, html.EscapeString(id))
+		content = strings.Replace(content, attr, fmt.Sprintf(`%s data-poll="%d"`, attr, interval.Milliseconds()), 1)
+	}
+	return content
+}
+
+// sourcePollInterval returns the poll interval of a source, looking in the
+// page's frontmatter first and then tinkerdown.yaml, like block state does.
+func (s *Server) sourcePollInterval(page *tinkerdown.Page, name string) time.Duration {
+	if src, ok := page.Config.Sources[name]; ok {
+		return config.SourceConfig{Options: src.Options}.GetPollInterval()
+	}
+	if s.config != nil {
+		if src, ok := s.config.Sources[name]; ok {
+			return src.GetPollInterval()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestPollAttributes(t *testing.T) {
+	tmpDir := t.TempDir()
+	page := "---\nsources:\n  status:\n    type: json\n    file: status.json\n    options:\n      poll: 30s\n  items:\n    type: json\n    file: status.json\n---\n# Status\n\n" +
+		"```lvt id=\"polled\"\n<div lvt-source=\"status\">{{len .Data}}</div>\n```\n\n" +
+		"```lvt id=\"static\"\n<div lvt-source=\"items\">{{len .Data}}</div>\n```\n\n" +
+		"```lvt id=\"site\"\n<div lvt-source=\"metrics\">{{len .Data}}</div>\n```\n\n" +
+		"```lvt id=\"combined\"\n<div lvt-source=\"status,metrics\">{{len .Status.Data}}</div>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "status.json"), []byte(`[{"ok":true}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Sources = map[string]config.SourceConfig{
+		"metrics": {Type: "json", File: "status.json", Options: map[string]string{"poll": "5s"}},
+	}
+	srv := NewWithConfig(tmpDir, cfg)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`data-block-id="polled" data-poll="30000"`,
+		`data-block-id="site" data-poll="5000"`,
+		`data-block-id="combined" data-poll="5000"`, // Shortest of its sources
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page is missing %s", want)
+		}
+	}
+	if !strings.Contains(body, `data-block-id="static"`) || strings.Contains(body, `data-block-id="static" data-poll`) {
+		t.Error("block without a polled source has data-poll")
+	}
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// presetVars are the page's CSS variables a theme preset sets, in the order
+// they're written.
+var presetVars = []string{
+	"bg-primary", "bg-secondary", "text-primary", "text-secondary", "text-heading",
+	"border-color", "code-bg", "code-border", "pre-bg", "pre-text",
+	"card-bg", "card-border", "card-shadow", "accent",
+	"admonition-note", "admonition-warning", "admonition-tip",
+}
+
+// themePresets are the built-in color presets, in the order and with the
+// names of config.BuiltinPresets. Each sets every variable in presetVars for
+// both light and dark mode, so the light and dark toggle keeps working on top
+// of a preset.
+var themePresets = []struct {
+	name        string
+	label       string
+	light, dark map[string]string
+}{
+	{
+		name:  "solarized",
+		label: "Solarized",
+		light: map[string]string{
+			"bg-primary":         "#fdf6e3",
+			"bg-secondary":       "linear-gradient(135deg, #fdf6e3 0%, #eee8d5 100%)",
+			"text-primary":       "#586e75",
+			"text-secondary":     "#657b83",
+			"text-heading":       "#073642",
+			"border-color":       "#e0d9c4",
+			"code-bg":            "#eee8d5",
+			"code-border":        "#e0d9c4",
+			"pre-bg":             "#002b36",
+			"pre-text":           "#93a1a1",
+			"card-bg":            "#fdf6e3",
+			"card-border":        "rgba(88,110,117,0.15)",
+			"card-shadow":        "rgba(0,43,54,0.08)",
+			"accent":             "#268bd2",
+			"admonition-note":    "#268bd2",
+			"admonition-warning": "#b58900",
+			"admonition-tip":     "#859900",
+		},
+		dark: map[string]string{
+			"bg-primary":         "#002b36",
+			"bg-secondary":       "linear-gradient(135deg, #002b36 0%, #073642 100%)",
+			"text-primary":       "#93a1a1",
+			"text-secondary":     "#839496",
+			"text-heading":       "#eee8d5",
+			"border-color":       "#18404a",
+			"code-bg":            "#073642",
+			"code-border":        "#18404a",
+			"pre-bg":             "#00212b",
+			"pre-text":           "#93a1a1",
+			"card-bg":            "#073642",
+			"card-border":        "rgba(147,161,161,0.15)",
+			"card-shadow":        "rgba(0,0,0,0.3)",
+			"accent":             "#268bd2",
+			"admonition-note":    "#268bd2",
+			"admonition-warning": "#b58900",
+			"admonition-tip":     "#859900",
+		},
+	},
+	{
+		name:  "nord",
+		label: "Nord",
+		light: map[string]string{
+			"bg-primary":         "#eceff4",
+			"bg-secondary":       "linear-gradient(135deg, #eceff4 0%, #e5e9f0 100%)",
+			"text-primary":       "#2e3440",
+			"text-secondary":     "#4c566a",
+			"text-heading":       "#2e3440",
+			"border-color":       "#d8dee9",
+			"code-bg":            "#e5e9f0",
+			"code-border":        "#d8dee9",
+			"pre-bg":             "#2e3440",
+			"pre-text":           "#d8dee9",
+			"card-bg":            "#f3f5f8",
+			"card-border":        "rgba(46,52,64,0.08)",
+			"card-shadow":        "rgba(46,52,64,0.08)",
+			"accent":             "#5e81ac",
+			"admonition-note":    "#5e81ac",
+			"admonition-warning": "#d08770",
+			"admonition-tip":     "#a3be8c",
+		},
+		dark: map[string]string{
+			"bg-primary":         "#2e3440",
+			"bg-secondary":       "linear-gradient(135deg, #2e3440 0%, #3b4252 100%)",
+			"text-primary":       "#e5e9f0",
+			"text-secondary":     "#d8dee9",
+			"text-heading":       "#eceff4",
+			"border-color":       "#4c566a",
+			"code-bg":            "#3b4252",
+			"code-border":        "#4c566a",
+			"pre-bg":             "#242933",
+			"pre-text":           "#d8dee9",
+			"card-bg":            "#3b4252",
+			"card-border":        "rgba(216,222,233,0.1)",
+			"card-shadow":        "rgba(0,0,0,0.3)",
+			"accent":             "#88c0d0",
+			"admonition-note":    "#88c0d0",
+			"admonition-warning": "#ebcb8b",
+			"admonition-tip":     "#a3be8c",
+		},
+	},
+	{
+		name:  "high-contrast",
+		label: "High contrast",
+		light: map[string]string{
+			"bg-primary":         "#ffffff",
+			"bg-secondary":       "#ffffff",
+			"text-primary":       "#000000",
+			"text-secondary":     "#000000",
+			"text-heading":       "#000000",
+			"border-color":       "#000000",
+			"code-bg":            "#f0f0f0",
+			"code-border":        "#000000",
+			"pre-bg":             "#000000",
+			"pre-text":           "#ffffff",
+			"card-bg":            "#ffffff",
+			"card-border":        "#000000",
+			"card-shadow":        "transparent",
+			"accent":             "#0000ee",
+			"admonition-note":    "#0000ee",
+			"admonition-warning": "#8a4b00",
+			"admonition-tip":     "#006400",
+		},
+		dark: map[string]string{
+			"bg-primary":         "#000000",
+			"bg-secondary":       "#000000",
+			"text-primary":       "#ffffff",
+			"text-secondary":     "#ffffff",
+			"text-heading":       "#ffffff",
+			"border-color":       "#ffffff",
+			"code-bg":            "#1a1a1a",
+			"code-border":        "#ffffff",
+			"pre-bg":             "#000000",
+			"pre-text":           "#ffffff",
+			"card-bg":            "#000000",
+			"card-border":        "#ffffff",
+			"card-shadow":        "transparent",
+			"accent":             "#ffff00",
+			"admonition-note":    "#ffff00",
+			"admonition-warning": "#ffb000",
+			"admonition-tip":     "#00ff7f",
+		},
+	},
+}
+
+// presetCSS holds the rules for every built-in preset. All of them go into
+// each page so the picker can switch presets without a reload.
+var presetCSS = renderPresetCSS()
+
+// renderPresetCSS returns a [data-preset="name"] rule with the light values
+// of each built-in preset and a more specific one with its dark values.
+func renderPresetCSS() string {
+	var b strings.Builder
+	for _, p := range themePresets {
+		writePresetRule(&b, fmt.Sprintf(`[data-preset=%q]`, p.name), p.light)
+		writePresetRule(&b, fmt.Sprintf(`[data-preset=%q][data-theme="dark"]`, p.name), p.dark)
+	}
+	return b.String()
+}
+
+func writePresetRule(b *strings.Builder, selector string, values map[string]string) {
+	fmt.Fprintf(b, "\n        %s {", selector)
+	for _, v := range presetVars {
+		if value, ok := values[v]; ok {
+			fmt.Fprintf(b, "\n            --%s: %s;", v, value)
+		}
+	}
+	b.WriteString("\n        }")
+}
+
+// customPresets returns the valid, distinct names of the site's own presets
+// (styling.presets) that don't shadow a built-in one.
+func customPresets(styling config.StylingConfig) []string {
+	var names []string
+	for _, name := range styling.Presets {
+		if config.ValidPresetName(name) && !isBuiltinPreset(name) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isBuiltinPreset(name string) bool {
+	for _, p := range themePresets {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sitePreset returns the preset pages start with (styling.preset), or ""
+// for the default colors when it is unset or unknown.
+func sitePreset(styling config.StylingConfig) string {
+	if isBuiltinPreset(styling.Preset) || slices.Contains(customPresets(styling), styling.Preset) {
+		return styling.Preset
+	}
+	return ""
+}
+
+// renderPresetStyle returns the <head> markup for theme presets: the
+// built-in preset rules and, when styling.custom_css is set, a link to the
+// site's stylesheet, which comes last so it can override any variable and
+// define the site's own presets.
+func renderPresetStyle(styling config.StylingConfig) string {
+	style := "\n    <style>" + presetCSS + "\n    </style>"
+	if css := strings.TrimSpace(styling.CustomCSS); css != "" {
+		href := css
+		if !strings.Contains(css, "://") {
+			href = path.Clean("/" + css)
+		}
+		style += fmt.Sprintf("\n    <link rel=\"stylesheet\" href=\"%s\">", html.EscapeString(href))
+	}
+	return style
+}
+
+// renderPresetPicker returns the toolbar's preset menu, with selected (the
+// site preset) chosen until the reader picks another.
+func renderPresetPicker(styling config.StylingConfig, selected string) string {
+	var b strings.Builder
+	b.WriteString(`
+            <select id="theme-preset" title="Color preset" aria-label="Color preset">`)
+	option := func(value, label string) {
+		attr := ""
+		if value == selected {
+			attr = " selected"
+		}
+		fmt.Fprintf(&b, "\n                <option value=\"%s\"%s>%s</option>", value, attr, html.EscapeString(label))
+	}
+	option("", "Default")
+	for _, p := range themePresets {
+		option(p.name, p.label)
+	}
+	for _, name := range customPresets(styling) {
+		option(name, name)
+	}
+	b.WriteString(`
+            </select>`)
+	return b.String()
+}
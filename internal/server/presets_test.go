@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestThemePresetsSetEveryVariable(t *testing.T) {
+	for _, p := range themePresets {
+		for _, v := range presetVars {
+			if p.light[v] == "" || p.dark[v] == "" {
+				t.Errorf("preset %s: --%s must be set for light and dark mode", p.name, v)
+			}
+		}
+		if len(p.light) != len(presetVars) || len(p.dark) != len(presetVars) {
+			t.Errorf("preset %s sets variables missing from presetVars", p.name)
+		}
+	}
+}
+
+func TestThemePresetsMatchConfig(t *testing.T) {
+	var names []string
+	for _, p := range themePresets {
+		names = append(names, p.name)
+	}
+	if !slices.Equal(names, config.BuiltinPresets) {
+		t.Errorf("themePresets = %v, want config.BuiltinPresets %v", names, config.BuiltinPresets)
+	}
+}
+
+func TestRenderPagePresets(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	render := func(styling config.StylingConfig) string {
+		cfg := config.DefaultConfig()
+		cfg.Styling.Preset = styling.Preset
+		cfg.Styling.Presets = styling.Presets
+		cfg.Styling.CustomCSS = styling.CustomCSS
+		srv := NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := render(config.StylingConfig{Preset: "nord"})
+	for _, want := range []string{
+		`<html lang="en" data-preset="nord">`,
+		`[data-preset="nord"] {`,
+		"--bg-primary: #eceff4;",
+		`[data-preset="nord"][data-theme="dark"] {`,
+		"--bg-primary: #2e3440;",
+		`<option value="nord" selected>Nord</option>`,
+		`<option value="solarized">Solarized</option>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	body = render(config.StylingConfig{})
+	if !strings.Contains(body, `<html lang="en">`) || !strings.Contains(body, `<option value="" selected>Default</option>`) {
+		t.Error("page without a preset should use the default colors")
+	}
+
+	body = render(config.StylingConfig{Preset: "unknown"})
+	if strings.Contains(body, `data-preset="unknown"`) {
+		t.Error("unknown preset should be ignored")
+	}
+
+	body = render(config.StylingConfig{Preset: "sepia", Presets: []string{"sepia", "Bad Name"}, CustomCSS: "static/theme.css"})
+	for _, want := range []string{
+		`<html lang="en" data-preset="sepia">`,
+		`<option value="sepia" selected>sepia</option>`,
+		`<link rel="stylesheet" href="/static/theme.css">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if strings.Contains(body, "Bad Name") {
+		t.Error("invalid preset names should be dropped")
+	}
+	if strings.Index(body, `href="/static/theme.css"`) < strings.Index(body, `[data-preset="nord"] {`) {
+		t.Error("custom_css should load after the built-in preset rules")
+	}
+}
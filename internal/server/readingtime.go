@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// readingSkipPattern matches elements that aren't prose: scripts, styles and code blocks.
+var readingSkipPattern = regexp.MustCompile(`(?is)<(script|style|pre)\b.*?</(?:script|style|pre)>`)
+
+// readingTagPattern matches any HTML tag.
+var readingTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// estimateReadingTime counts the words in rendered page HTML, skipping code
+// blocks, scripts and styles, and returns the minutes needed to read them at
+// wpm (> 0) words per minute, rounded up with a minimum of one.
+func estimateReadingTime(htmlStr string, wpm int) (words, minutes int) {
+	text := readingSkipPattern.ReplaceAllString(htmlStr, " ")
+	text = readingTagPattern.ReplaceAllString(text, " ")
+	words = len(strings.Fields(text))
+
+	minutes = (words + wpm - 1) / wpm
+	if minutes < 1 {
+		minutes = 1
+	}
+	return words, minutes
+}
+
+// readingProgressHTML is a thin bar at the top of the page that fills as the
+// reader scrolls. The bar is hidden in presentation mode.
+const readingProgressHTML = `<div class="reading-progress" aria-hidden="true"><div class="reading-progress-bar"></div></div>
+<script>
+    (function() {
+        const bar = document.querySelector('.reading-progress-bar');
+        if (!bar) return;
+        let pending = false;
+
+        function update() {
+            pending = false;
+            const doc = document.documentElement;
+            const max = doc.scrollHeight - window.innerHeight;
+            const progress = max > 0 ? Math.min(1, window.scrollY / max) : 0;
+            bar.style.transform = 'scaleX(' + progress + ')';
+        }
+
+        function schedule() {
+            if (!pending) {
+                pending = true;
+                window.requestAnimationFrame(update);
+            }
+        }
+
+        window.addEventListener('scroll', schedule, { passive: true });
+        window.addEventListener('resize', schedule);
+        update();
+    })();
+</script>`
+
+// readingTimeHTML formats the estimated reading time shown under the title.
+func readingTimeHTML(minutes int) string {
+	return fmt.Sprintf(`<p class="reading-time">%d min read</p>`, minutes)
+}
+
+// insertAfterTitle places snippet right after the page's first <h1>, or at
+// the start of the content when there is none.
+func insertAfterTitle(content, snippet string) string {
+	if i := strings.Index(content, "</h1>"); i != -1 {
+		i += len("</h1>")
+		return content[:i] + "\n" + snippet + content[i:]
+	}
+	return snippet + "\n" + content
+}
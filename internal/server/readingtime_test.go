@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestEstimateReadingTime(t *testing.T) {
+	prose := strings.Repeat("word ", 450)
+
+	tests := []struct {
+		name        string
+		html        string
+		wpm         int
+		wantWords   int
+		wantMinutes int
+	}{
+		{"empty page", "", 200, 0, 1},
+		{"tags are not words", "<h1>Hello</h1>\n<p>Hello <strong>there</strong> world</p>", 200, 4, 1},
+		{"rounds up", "<p>" + prose + "</p>", 200, 450, 3},
+		{"custom speed", "<p>" + prose + "</p>", 150, 450, 3},
+		{"faster reader", "<p>" + prose + "</p>", 500, 450, 1},
+		{
+			name:        "skips code, scripts and styles",
+			html:        "<p>one two</p><pre><code>x := 1\ny := 2</code></pre><script>var a = 1;</script><style>p { color: red }</style>",
+			wpm:         200,
+			wantWords:   2,
+			wantMinutes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, minutes := estimateReadingTime(tt.html, tt.wpm)
+			if words != tt.wantWords || minutes != tt.wantMinutes {
+				t.Errorf("estimateReadingTime() = %d words, %d min; want %d words, %d min",
+					words, minutes, tt.wantWords, tt.wantMinutes)
+			}
+		})
+	}
+}
+
+func TestRenderPageReadingTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"long.md":   "# Long\n\n" + strings.Repeat("word ", 599) + "\n",
+		"hidden.md": "---\nreading_time: false\n---\n# Hidden\n\nShort page.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	render := func(cfg *config.Config, path string) string {
+		srv := NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := render(config.DefaultConfig(), "/long")
+	if !strings.Contains(body, "</h1>\n<p class=\"reading-time\">3 min read</p>") {
+		t.Error("reading time should follow the page title")
+	}
+	if !strings.Contains(body, `<div class="reading-progress" aria-hidden="true">`) {
+		t.Error("page should include the reading progress bar")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ReadingTime.WPM = 100
+	if body := render(cfg, "/long"); !strings.Contains(body, "6 min read") {
+		t.Error("reading time should use the configured words per minute")
+	}
+
+	body = render(config.DefaultConfig(), "/hidden")
+	if strings.Contains(body, `class="reading-time"`) || strings.Contains(body, `class="reading-progress"`) {
+		t.Error("reading_time: false should hide reading time and progress bar")
+	}
+
+	off := false
+	cfg = config.DefaultConfig()
+	cfg.ReadingTime.Enabled = &off
+	if body := render(cfg, "/long"); strings.Contains(body, `class="reading-time"`) {
+		t.Error("reading_time.enabled: false should hide reading time site-wide")
+	}
+}
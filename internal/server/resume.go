@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// resumeScript remembers where the reader was: the path, title and scroll
+// offset of the last page they saw, in localStorage under a key scoped by
+// site, so two sites served from the same origin (say localhost:8080) don't
+// share it.
+//
+// The offset is restored when the same page is reloaded, which includes the
+// reload the live-reload watcher triggers after a page's file changes, so
+// editing a page doesn't throw the reader back to the top. Source refreshes
+// patch blocks in place without a reload and leave scrolling alone. Once the
+// reader scrolls, clicks or presses a key, nothing is restored, so a late
+// restore never fights them.
+//
+// Arriving at the home page from elsewhere offers a "Resume where you left
+// off" link back to the last page, which lands at the remembered offset.
+const resumeScript = `
+    <script>
+        (function() {
+            const KEY = 'tinkerdown-position:' + %s;
+            const path = location.pathname;
+
+            function read(storage) {
+                try {
+                    return JSON.parse(storage.getItem(KEY) || 'null');
+                } catch (e) {
+                    return null;
+                }
+            }
+
+            const last = read(localStorage);
+            const resume = read(sessionStorage); // Set by the resume link
+            sessionStorage.removeItem(KEY);
+
+            let saveTimer = null;
+            function save() {
+                clearTimeout(saveTimer);
+                try {
+                    localStorage.setItem(KEY, JSON.stringify({ path: path, title: document.title, y: Math.round(window.scrollY) }));
+                } catch (e) {
+                    // Storage full or disabled: nothing to remember
+                }
+            }
+            window.addEventListener('scroll', function() {
+                clearTimeout(saveTimer);
+                saveTimer = setTimeout(save, 250);
+            }, { passive: true });
+            window.addEventListener('pagehide', save);
+
+            let interacted = false;
+            ['wheel', 'touchstart', 'keydown', 'mousedown'].forEach(function(type) {
+                window.addEventListener(type, function() { interacted = true; }, { passive: true, once: true });
+            });
+
+            // Blocks render over the WebSocket after load and can grow the
+            // page, so the offset is applied again shortly after
+            function restore(y) {
+                function apply() {
+                    if (!interacted && Math.abs(window.scrollY - y) > 1) {
+                        window.scrollTo({ top: y, behavior: 'instant' });
+                    }
+                }
+                function schedule() {
+                    apply();
+                    setTimeout(apply, 500);
+                }
+                if (document.readyState === 'complete') {
+                    schedule();
+                } else {
+                    window.addEventListener('load', schedule, { once: true });
+                }
+            }
+
+            function fromOtherSite() {
+                try {
+                    return !document.referrer || new URL(document.referrer).origin !== location.origin;
+                } catch (e) {
+                    return true;
+                }
+            }
+
+            function showResume(last) {
+                const banner = document.createElement('div');
+                banner.className = 'resume-banner';
+                banner.setAttribute('role', 'status');
+
+                const link = document.createElement('a');
+                link.href = last.path;
+                link.append('Resume where you left off: ');
+                const title = document.createElement('strong');
+                title.textContent = last.title || last.path;
+                link.appendChild(title);
+                link.addEventListener('click', function() {
+                    sessionStorage.setItem(KEY, JSON.stringify(last));
+                });
+
+                const dismiss = document.createElement('button');
+                dismiss.type = 'button';
+                dismiss.className = 'resume-dismiss';
+                dismiss.setAttribute('aria-label', 'Dismiss');
+                dismiss.textContent = '×';
+                dismiss.addEventListener('click', function() { banner.remove(); });
+
+                banner.append(link, dismiss);
+                document.body.appendChild(banner);
+            }
+
+            const nav = performance.getEntriesByType('navigation')[0];
+            if (resume && resume.path === path) {
+                restore(resume.y);
+            } else if (nav && nav.type === 'reload' && last && last.path === path) {
+                restore(last.y);
+            } else if (path === '/' && last && last.path !== path && typeof last.path === 'string' && /^\/(?!\/)/.test(last.path) && fromOtherSite()) {
+                document.addEventListener('DOMContentLoaded', function() { showResume(last); });
+            }
+        })();
+    </script>`
+
+// renderResumeScript returns resumeScript with its storage scoped to siteKey.
+func renderResumeScript(siteKey string) string {
+	key, err := json.Marshal(siteKey)
+	if err != nil {
+		key = []byte(`""`)
+	}
+	return fmt.Sprintf(resumeScript, key)
+}
+
+// siteKey identifies the site in the reader's browser storage: its title, or
+// the name of its directory when it has none.
+func (s *Server) siteKey() string {
+	if s.config.Title != "" {
+		return s.config.Title
+	}
+	if abs, err := filepath.Abs(s.rootDir); err == nil {
+		return filepath.Base(abs)
+	}
+	return s.rootDir
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestRenderResumeScript(t *testing.T) {
+	got := renderResumeScript(`Docs "</script>`)
+	if !strings.Contains(got, `const KEY = 'tinkerdown-position:' + "Docs \"\u003c/script\u003e";`) {
+		t.Errorf("site key should be a JSON string safe inside <script>, got:\n%s", got)
+	}
+	for _, want := range []string{
+		"nav.type === 'reload'",
+		"'Resume where you left off: '",
+		"window.addEventListener('pagehide', save);",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("resume script missing %q", want)
+		}
+	}
+}
+
+func TestRenderPageResume(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	render := func(title string) string {
+		cfg := config.DefaultConfig()
+		cfg.Title = title
+		srv := NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	if body := render("Team Docs"); !strings.Contains(body, renderResumeScript("Team Docs")) {
+		t.Error("page should include the resume script scoped by the site title")
+	}
+	if body := render(""); !strings.Contains(body, renderResumeScript(filepath.Base(tmpDir))) {
+		t.Error("site without a title should be scoped by its directory name")
+	}
+}
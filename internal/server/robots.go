@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/livetemplate/tinkerdown"
+)
+
+// serveRobots handles /robots.txt, letting crawlers index the whole site
+// unless the config says robots: disallow (which serve --staging sets).
+func (s *Server) serveRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if s.config.RobotsAllowed() {
+		_, _ = w.Write([]byte("User-agent: *\nAllow: /\n"))
+		return
+	}
+	_, _ = w.Write([]byte("User-agent: *\nDisallow: /\n"))
+}
+
+// robotsMeta returns the <head> tag asking search engines not to index a
+// page with noindex: true in its frontmatter.
+func robotsMeta(page *tinkerdown.Page) string {
+	if !page.NoIndex {
+		return ""
+	}
+	return "\n    <meta name=\"robots\" content=\"noindex\">"
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestServeRobots(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		robots string
+		want   string
+	}{
+		{"", "User-agent: *\nAllow: /\n"},
+		{"allow", "User-agent: *\nAllow: /\n"},
+		{"disallow", "User-agent: *\nDisallow: /\n"},
+		{"Disallow", "User-agent: *\nDisallow: /\n"},
+	}
+	for _, tt := range tests {
+		cfg := config.DefaultConfig()
+		cfg.Robots = tt.robots
+		srv := NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/robots.txt", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+
+		if w.Code != 200 {
+			t.Errorf("robots %q: status = %d, want 200", tt.robots, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("robots %q: Content-Type = %q, want text/plain", tt.robots, ct)
+		}
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("robots %q: body = %q, want %q", tt.robots, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPageNoIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md": "# Home",
+		"draft.md": "---\ntitle: Draft\nnoindex: true\n---\n\n# Draft",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	srv := NewWithConfig(tmpDir, config.DefaultConfig())
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	render := func(path string) string {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	const meta = `<meta name="robots" content="noindex">`
+	if body := render("/draft"); !strings.Contains(body, meta) {
+		t.Errorf("page with noindex: true should include %s", meta)
+	}
+	if body := render("/"); strings.Contains(body, meta) {
+		t.Error("page without noindex should not include the robots meta tag")
+	}
+}
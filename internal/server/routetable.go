@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatRouteTable lists routes one per line with their full URL under
+// baseURL, their source file and how many interactive blocks they have, in
+// the order given (Routes returns them in sidebar order). With hyperlinks set,
+// URLs are wrapped in OSC 8 escape sequences so terminals make them clickable.
+func FormatRouteTable(routes []*Route, baseURL string, hyperlinks bool) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	urlWidth, fileWidth := 0, 0
+	for _, route := range routes {
+		urlWidth = max(urlWidth, len(baseURL+route.Pattern))
+		fileWidth = max(fileWidth, len(route.FilePath))
+	}
+
+	var b strings.Builder
+	for _, route := range routes {
+		url := baseURL + route.Pattern
+		link := url
+		if hyperlinks {
+			link = "\x1b]8;;" + url + "\x1b\\" + url + "\x1b]8;;\x1b\\"
+		}
+		// Pad by the URL's visible width, not the escape sequences'
+		fmt.Fprintf(&b, "  %s%s  ", link, strings.Repeat(" ", urlWidth-len(url)))
+
+		var blocks int
+		if route.Page != nil {
+			blocks = len(route.Page.InteractiveBlocks)
+		}
+		switch blocks {
+		case 0:
+			b.WriteString(route.FilePath)
+		case 1:
+			fmt.Fprintf(&b, "%-*s  1 interactive block", fileWidth, route.FilePath)
+		default:
+			fmt.Fprintf(&b, "%-*s  %d interactive blocks", fileWidth, route.FilePath, blocks)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
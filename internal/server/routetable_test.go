@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/livetemplate/tinkerdown"
+)
+
+func TestFormatRouteTable(t *testing.T) {
+	blocks := func(n int) *tinkerdown.Page {
+		page := &tinkerdown.Page{InteractiveBlocks: map[string]*tinkerdown.InteractiveBlock{}}
+		for i := 0; i < n; i++ {
+			page.InteractiveBlocks[string(rune('a'+i))] = &tinkerdown.InteractiveBlock{}
+		}
+		return page
+	}
+	routes := []*Route{
+		{Pattern: "/", FilePath: "index.md", Page: blocks(2)},
+		{Pattern: "/guides/intro", FilePath: "guides/intro.md", Page: blocks(1)},
+		{Pattern: "/about", FilePath: "about.md", Page: blocks(0)},
+	}
+
+	got := FormatRouteTable(routes, "http://localhost:8080/", false)
+	want := "" +
+		"  http://localhost:8080/              index.md         2 interactive blocks\n" +
+		"  http://localhost:8080/guides/intro  guides/intro.md  1 interactive block\n" +
+		"  http://localhost:8080/about         about.md\n"
+	if got != want {
+		t.Errorf("FormatRouteTable() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = FormatRouteTable(routes[2:], "http://localhost:8080", true)
+	want = "  \x1b]8;;http://localhost:8080/about\x1b\\http://localhost:8080/about\x1b]8;;\x1b\\  about.md\n"
+	if got != want {
+		t.Errorf("FormatRouteTable() with hyperlinks = %q, want %q", got, want)
+	}
+}
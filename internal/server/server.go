@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
-	"log"
 	"net/http"
 	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -17,8 +19,11 @@ import (
 	"github.com/livetemplate/tinkerdown"
 	"github.com/livetemplate/tinkerdown/internal/assets"
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/logging"
+	"github.com/livetemplate/tinkerdown/internal/metrics"
 	"github.com/livetemplate/tinkerdown/internal/schedule"
 	"github.com/livetemplate/tinkerdown/internal/site"
+	"github.com/livetemplate/tinkerdown/internal/tracing"
 )
 
 // Route represents a discovered page route.
@@ -28,6 +33,20 @@ type Route struct {
 	Page     *tinkerdown.Page // Parsed page
 }
 
+// Component loggers; the component is shown as a "[Name]" prefix.
+var (
+	serverLog    = logging.New("Server")
+	wsLog        = logging.New("WS")
+	watchLog     = logging.New("Watch")
+	apiLog       = logging.New("API")
+	authLog      = logging.New("Auth")
+	rateLimitLog = logging.New("RateLimit")
+	scheduleLog  = logging.New("Schedule")
+	webhookLog   = logging.New("Webhook")
+	healthLog    = logging.New("Health")
+	actionLog    = logging.New("Action")
+)
+
 // Server is the tinkerdown development server.
 type Server struct {
 	rootDir            string
@@ -37,6 +56,7 @@ type Server struct {
 	mu                 sync.RWMutex
 	connections        map[*websocket.Conn]*WebSocketHandler // Track connected WebSocket clients with their handlers
 	connMu             sync.RWMutex                          // Separate mutex for connections
+	connSlots          int                                   // WebSocket slots taken under server.max_connections, guarded by connMu
 	watcher            *Watcher                              // File watcher for live reload
 	playground         *PlaygroundHandler                    // Playground for testing AI-generated apps
 	apiHandler         *APIHandler                           // REST API handler for sources
@@ -47,6 +67,10 @@ type Server struct {
 	rateLimitDone      <-chan struct{}                        // Closed when rate limiter goroutine exits
 	recentSourceWrites map[string]time.Time                  // Files recently written by source actions
 	sourceWriteMu      sync.Mutex                            // Protects recentSourceWrites
+	translations       map[string]map[string]string          // Route path without its locale -> locale -> route pattern
+	markdown           tinkerdown.MarkdownOptions            // Renderer extensions of this server's pages
+	imageVariants      map[string]*imageVariant              // Resized images by width, source path and mtime
+	imageVariantsMu    sync.Mutex                            // Protects imageVariants
 }
 
 // New creates a new server for the given root directory.
@@ -57,6 +81,8 @@ func New(rootDir string) *Server {
 		routes:             make([]*Route, 0),
 		connections:        make(map[*websocket.Conn]*WebSocketHandler),
 		recentSourceWrites: make(map[string]time.Time),
+		imageVariants:      make(map[string]*imageVariant),
+		markdown:           tinkerdown.DefaultMarkdownOptions(),
 	}
 	srv.playground = NewPlaygroundHandler(srv)
 	return srv
@@ -70,6 +96,8 @@ func NewWithConfig(rootDir string, cfg *config.Config) *Server {
 		routes:             make([]*Route, 0),
 		connections:        make(map[*websocket.Conn]*WebSocketHandler),
 		recentSourceWrites: make(map[string]time.Time),
+		imageVariants:      make(map[string]*imageVariant),
+		markdown:           site.MarkdownOptions(cfg),
 	}
 
 	// Initialize site manager if in site mode
@@ -118,7 +146,7 @@ func NewWithConfig(rootDir string, cfg *config.Config) *Server {
 
 		srv.apiRoutes = handler
 
-		log.Printf("[API] Middleware: auth=%v cors=%v rate_limit=%.1f rps (burst %d)",
+		apiLog.Infof("Middleware: auth=%v cors=%v rate_limit=%.1f rps (burst %d)",
 			cfg.API.IsAuthEnabled(),
 			len(cfg.API.GetCORSOrigins()) > 0,
 			cfg.API.GetRateLimitRPS(),
@@ -149,7 +177,7 @@ func (s *Server) executeScheduledAction(pageID, actionName string, args []string
 		return fmt.Errorf("action %q not found", actionName)
 	}
 
-	log.Printf("[Schedule] Executing action %q from page %s", actionName, pageID)
+	scheduleLog.Infof("Executing action %q from page %s", actionName, pageID)
 
 	// Create an executor for the scheduled action
 	executor := newWebhookActionExecutor(s.config, s.rootDir)
@@ -159,7 +187,7 @@ func (s *Server) executeScheduledAction(pageID, actionName string, args []string
 		if idx := strings.Index(arg, "="); idx > 0 {
 			params[arg[:idx]] = arg[idx+1:]
 		} else {
-			log.Printf("[Schedule] Warning: skipping invalid arg format %q (expected key=value)", arg)
+			scheduleLog.Warnf("skipping invalid arg format %q (expected key=value)", arg)
 		}
 	}
 	// Add message to params for use in action templates (e.g., {{.Message}})
@@ -171,7 +199,7 @@ func (s *Server) executeScheduledAction(pageID, actionName string, args []string
 
 // handleScheduledNotification handles notifications triggered by schedules.
 func (s *Server) handleScheduledNotification(pageID, message string) error {
-	log.Printf("[Schedule] Notification from page %s: %s", pageID, message)
+	scheduleLog.Infof("Notification from page %s: %s", pageID, message)
 	// In headless mode, notifications are logged. In future, could send to webhook/API.
 	return nil
 }
@@ -199,8 +227,9 @@ func (s *Server) Discover() error {
 			s.routes = append(s.routes, route)
 		}
 
-		// Sort routes
-		sortRoutes(s.routes)
+		// Sort routes to match the sidebar order
+		sortRoutes(s.routes, configNavOrder(s.config))
+		s.groupTranslations()
 
 		// Parse schedules from all discovered pages
 		s.parseSchedulesFromRoutes()
@@ -243,9 +272,9 @@ func (s *Server) Discover() error {
 		pattern := mdToPattern(relPath)
 
 		// Parse the page
-		page, err := tinkerdown.ParseFile(path)
+		page, err := s.markdown.ParseFile(path)
 		if err != nil {
-			log.Printf("Warning: Failed to parse %s: %v", relPath, err)
+			serverLog.Warnf("Failed to parse %s: %v", relPath, err)
 			return nil // Continue with other files
 		}
 
@@ -263,8 +292,9 @@ func (s *Server) Discover() error {
 		return fmt.Errorf("failed to walk directory: %w", err)
 	}
 
-	// Sort routes (index routes first)
-	sortRoutes(s.routes)
+	// Sort routes the same way as in site mode
+	sortRoutes(s.routes, configNavOrder(s.config))
+	s.groupTranslations()
 
 	// Parse schedules from all discovered pages
 	s.parseSchedulesFromRoutes()
@@ -303,7 +333,7 @@ func (s *Server) parseSchedulesFromRoutes() {
 	}
 
 	if totalSchedules > 0 {
-		log.Printf("[Schedule] Registered %d scheduled jobs from pages", totalSchedules)
+		scheduleLog.Infof("Registered %d scheduled jobs from pages", totalSchedules)
 	}
 }
 
@@ -336,19 +366,55 @@ func (s *Server) Routes() []*Route {
 
 // ServeHTTP implements http.Handler.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !metrics.Enabled() {
+		s.serveHTTP(w, r)
+		return
+	}
+	// Checked before pages, like /health, so no page can shadow it
+	if r.URL.Path == metrics.Path {
+		metrics.Handler().ServeHTTP(w, r)
+		return
+	}
+	start := time.Now()
+	rec := metrics.NewStatusRecorder(w)
+	s.serveHTTP(rec, r)
+	metrics.ObserveRequest(s.metricsRoute(r.URL.Path), rec.Status(), time.Since(start))
+}
+
+// metricsRoute names the route of path for request metrics: a page's
+// pattern, or the endpoint or prefix of a built-in handler. Other paths are
+// "other", so unknown URLs can't add labels without bound.
+func (s *Server) metricsRoute(path string) string {
+	switch path {
+	case "/health", "/ws", actionPath, "/robots.txt", "/search-index.json",
+		"/playground", "/playground/render", "/playground/ws":
+		return path
+	}
+	for _, prefix := range []string{"/api/sources/", "/webhook/", "/assets/", imageVariantPrefix, "/playground/preview/", tagsPath} {
+		if strings.HasPrefix(path, prefix) {
+			return prefix
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, route := range s.routes {
+		if route.Pattern == path {
+			return route.Pattern
+		}
+	}
+	return "other"
+}
+
+// serveHTTP routes a request to its handler.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	// Security headers applied via SecurityHeadersMiddleware on API routes.
 	// Apply same headers to all other routes for consistency.
 	w.Header().Set("X-Frame-Options", "DENY")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-	w.Header().Set("Content-Security-Policy",
-		"default-src 'self'; "+
-			"script-src 'self' 'unsafe-inline' 'unsafe-eval'; "+
-			"style-src 'self' 'unsafe-inline'; "+
-			"img-src 'self' data: https:; "+
-			"font-src 'self' data:; "+
-			"connect-src 'self'; "+
-			"frame-ancestors 'none'")
+	// Pages replace it with one carrying their nonce, when it has one
+	w.Header().Set("Content-Security-Policy", cspHeader(s.cspPolicy(), ""))
 
 	// Health endpoint (always available, especially important in headless mode)
 	if r.URL.Path == "/health" {
@@ -382,6 +448,18 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Serve actions that carry files
+	if r.URL.Path == actionPath {
+		s.serveAction(w, r)
+		return
+	}
+
+	// Serve crawler rules
+	if r.URL.Path == "/robots.txt" {
+		s.serveRobots(w, r)
+		return
+	}
+
 	// Serve search index for site mode
 	if r.URL.Path == "/search-index.json" && s.siteManager != nil {
 		s.serveSearchIndex(w, r)
@@ -394,6 +472,12 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Serve resized content images
+	if strings.HasPrefix(r.URL.Path, imageVariantPrefix) && s.config.Images.IsResponsive() {
+		s.serveImageVariant(w, r)
+		return
+	}
+
 	// Serve playground routes
 	if r.URL.Path == "/playground" {
 		s.playground.ServePlaygroundPage(w, r)
@@ -412,18 +496,36 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.serveRoute(w, r) {
+		return
+	}
+
+	// Serve tag index pages for site mode
+	if s.siteManager != nil && s.serveTagPage(w, r) {
+		return
+	}
+
+	// Serve other files under the site root (images, downloads)
+	if s.serveStaticFile(w, r) {
+		return
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Find matching route
-	for _, route := range s.routes {
-		if route.Pattern == r.URL.Path {
-			s.servePage(w, r, route)
-			return
-		}
+	// Send paths outside the locale directories to the reader's language
+	if target, ok := s.localeRedirect(r); ok {
+		w.Header().Add("Vary", "Accept-Language")
+		http.Redirect(w, r, target, http.StatusFound)
+		return
 	}
 
-	// No route found - redirect to first available route instead of 404
+	// No route found - the site's 404 page if it has one, otherwise redirect
+	// to the first available route
+	if page := s.customErrorPage(http.StatusNotFound); page != nil {
+		s.serveErrorPage(w, r, http.StatusNotFound, page)
+		return
+	}
 	if len(s.routes) > 0 {
 		http.Redirect(w, r, s.routes[0].Pattern, http.StatusSeeOther)
 	} else {
@@ -431,6 +533,31 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveRoute serves the page whose pattern matches the request path and
+// reports whether there was one.
+func (s *Server) serveRoute(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, route := range s.routes {
+		if route.Pattern == r.URL.Path {
+			s.servePage(w, r, route)
+			return true
+		}
+	}
+
+	// The same page with the trailing slash added or removed
+	if alt := toggleTrailingSlash(r.URL.Path); alt != "" {
+		for _, route := range s.routes {
+			if route.Pattern == alt {
+				redirectCanonical(w, r, route.Pattern)
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // serveHealth handles the /health endpoint.
 func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -454,7 +581,7 @@ func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[Health] Error encoding health response: %v", err)
+		healthLog.Errorf("Error encoding health response: %v", err)
 	}
 }
 
@@ -466,10 +593,11 @@ func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 		pagePath = "/" // Default to home page
 	}
 
+	// Only hold the lock while picking the route; the connection lives
+	// much longer and rediscovery or shutdown must not wait for it.
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	if len(s.routes) == 0 {
+		s.mu.RUnlock()
 		http.Error(w, "No pages available", http.StatusNotFound)
 		return
 	}
@@ -485,17 +613,26 @@ func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	// Fall back to first route if page not found
 	if route == nil {
-		log.Printf("[WS] Page %q not found, falling back to first route", pagePath)
+		wsLog.Warnf("Page %q not found, falling back to first route", pagePath)
 		route = s.routes[0]
 	}
+	s.mu.RUnlock()
+
+	// Hold a slot from before the upgrade until the connection ends
+	if !s.reserveConnection() {
+		wsLog.Warnf("Rejecting WebSocket connection: limit of %d reached", s.config.Server.MaxConnections)
+		http.Error(w, "Too many connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.releaseConnection()
 
-	log.Printf("[WS] WebSocket connection for page: %s (pattern: %s)", pagePath, route.Pattern)
+	wsLog.Infof("WebSocket connection for page: %s (pattern: %s)", pagePath, route.Pattern)
 
 	// Create a new WebSocketHandler instance for this connection.
 	// NOTE: Each WebSocket connection (e.g., each browser tab) gets its own
 	// handler with isolated state. Interactive state is intentionally NOT
 	// synchronized across multiple connections to the same page.
-	wsHandler := NewWebSocketHandler(route.Page, s, true, s.rootDir, s.config)
+	wsHandler := NewWebSocketHandler(route.Page, s, logging.Enabled(logging.LevelDebug), s.rootDir, s.config)
 	wsHandler.ServeHTTP(w, r)
 }
 
@@ -503,27 +640,32 @@ func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/assets/")
 
-	// Serve client JS
-	if path == "tinkerdown-client.js" {
-		js, err := assets.GetClientJS()
-		if err != nil {
-			http.Error(w, "Asset not found", http.StatusNotFound)
-			return
-		}
-		w.Header().Set("Content-Type", "application/javascript")
-		w.Write(js)
-		return
+	// Client bundles are immutable under their fingerprinted names; the
+	// unversioned names still work for pages rendered by older versions
+	clientCache := cacheClientAsset
+	if s.watching() {
+		clientCache = cacheRevalidate
 	}
-
-	// Serve client CSS
-	if path == "tinkerdown-client.css" {
-		css, err := assets.GetClientCSS()
+	for _, asset := range clientAssets {
+		content, err := asset.get()
+		if path != asset.name && path != asset.hashedName {
+			continue
+		}
 		if err != nil {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "text/css")
-		w.Write(css)
+		cacheControl := clientCache
+		if path == asset.hashedName {
+			cacheControl = cacheImmutable
+		}
+		// The precompressed copy; WithCompression passes it through as is
+		w.Header().Add("Vary", "Accept-Encoding")
+		if asset.gzipped != nil && acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			content = asset.gzipped
+		}
+		serveCacheable(w, r, asset.contentType, cacheControl, content)
 		return
 	}
 
@@ -534,9 +676,7 @@ func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "application/javascript")
-		w.Header().Set("Cache-Control", "public, max-age=31536000")
-		w.Write(js)
+		serveCacheable(w, r, "application/javascript", cacheVendorAsset, js)
 		return
 	}
 
@@ -547,9 +687,7 @@ func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "text/css")
-		w.Header().Set("Cache-Control", "public, max-age=31536000")
-		w.Write(css)
+		serveCacheable(w, r, "text/css", cacheVendorAsset, css)
 		return
 	}
 
@@ -561,9 +699,7 @@ func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "application/javascript")
-		w.Header().Set("Cache-Control", "public, max-age=31536000")
-		w.Write(js)
+		serveCacheable(w, r, "application/javascript", cacheVendorAsset, js)
 		return
 	}
 
@@ -574,9 +710,7 @@ func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "application/javascript")
-		w.Header().Set("Cache-Control", "public, max-age=31536000")
-		w.Write(js)
+		serveCacheable(w, r, "application/javascript", cacheVendorAsset, js)
 		return
 	}
 
@@ -587,9 +721,7 @@ func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "application/javascript")
-		w.Header().Set("Cache-Control", "public, max-age=31536000")
-		w.Write(js)
+		serveCacheable(w, r, "application/javascript", cacheVendorAsset, js)
 		return
 	}
 
@@ -600,9 +732,7 @@ func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Asset not found", http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Type", "text/css")
-		w.Header().Set("Cache-Control", "public, max-age=31536000")
-		w.Write(css)
+		serveCacheable(w, r, "text/css", cacheVendorAsset, css)
 		return
 	}
 
@@ -632,21 +762,76 @@ func (s *Server) serveSearchIndex(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// servePage serves a page.
+// servePage serves a page. The caller holds s.mu.
 func (s *Server) servePage(w http.ResponseWriter, r *http.Request, route *Route) {
-	// For now, just serve the static HTML
-	// TODO: Add WebSocket support for interactivity
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// Pages are revalidated on every request while watching, so edits show
+	// up immediately; the ETag still saves re-sending unchanged pages.
+	cacheControl := cachePage
+	if s.watcher != nil {
+		cacheControl = cacheRevalidate
+	}
+
+	ctx, span := tracing.Start(r.Context(), "servePage",
+		tracing.AttrRoute.String(route.Pattern), tracing.AttrPage.String(route.FilePath))
+	defer span.End()
+
+	// A page that fails to render gets the 500 page, not a dropped connection
+	defer func() {
+		if err := recover(); err != nil {
+			serverLog.Errorf("Failed to render %s: %v", route.FilePath, err)
+			s.serveErrorPage(w, r, http.StatusInternalServerError, s.customErrorPage(http.StatusInternalServerError))
+		}
+	}()
 
-	html := s.renderPage(route.Page, r.URL.Path, r.Host)
+	// The ETag is of the page before applyCSPNonce, whose nonce is new on
+	// every response, so unchanged pages still get a 304
+	html := s.renderPage(ctx, route.Page, r.URL.Path, r.Host)
+	if notModified(w, r, contentETag([]byte(html)), cacheControl) {
+		return
+	}
+	html = s.applyCSPNonce(w, html)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write([]byte(html))
 }
 
-// renderPage renders a page to HTML.
-func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host string) string {
+// renderPage renders a page to HTML, in a span that's a child of any in ctx.
+func (s *Server) renderPage(ctx context.Context, page *tinkerdown.Page, currentPath string, host string) string {
+	_, span := tracing.Start(ctx, "renderPage", tracing.AttrRoute.String(currentPath))
+	defer span.End()
+
 	// Render code blocks with metadata for client discovery
 	content := s.renderContent(page)
 
+	// Lazy-load local images and offer resized variants
+	if s.config.Images.IsResponsive() {
+		content = s.responsiveImages(content, page.SourceFile, s.config.Images.GetWidths())
+	}
+
+	// Estimated reading time under the title, plus a scroll progress bar
+	readingProgress := ""
+	if s.config.ReadingTime.IsEnabled() && (page.ReadingTime == nil || *page.ReadingTime) {
+		_, minutes := estimateReadingTime(page.StaticHTML, s.config.ReadingTime.GetWPM())
+		content = insertAfterTitle(content, readingTimeHTML(minutes))
+		readingProgress = nonceScripts(readingProgressHTML)
+	}
+
+	// Links to the page's tag pages under the title
+	if s.siteManager != nil && len(page.Tags) > 0 {
+		content = insertAfterTitle(content, tagChipsHTML(page.Tags))
+	}
+
+	// Floating back-to-top and section jump buttons
+	jumpNav := ""
+	if s.config.JumpNav.IsEnabled() {
+		jumpNav = jumpNavHTML
+	}
+
+	// Canonical URL for search engines (playground previews aren't site pages)
+	canonical := ""
+	if !strings.HasPrefix(currentPath, "/playground/") {
+		canonical = s.canonicalLink(currentPath)
+	}
+
 	// Determine effective sidebar setting (page-level overrides site-level)
 	showSidebar := s.config.Features.Sidebar
 	if page.Sidebar != nil {
@@ -669,128 +854,94 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
 
 	// Wrap content with breadcrumbs and prev/next
 	contentWithNav := fmt.Sprintf(`
-		%s
+		%s%s
 		<div class="content-wrapper">
 			%s
 		</div>
 		%s
-	`, breadcrumbsHTML, content, prevNextHTML)
+	`, readingProgress, breadcrumbsHTML, content, prevNextHTML)
 
 	// Build WebSocket URL from host with page path for multi-page routing
 	wsURL := fmt.Sprintf("ws://%s/ws?page=%s", host, url.QueryEscape(currentPath))
 
-	// Conditionally include Chart.js for pages with chart annotations
+	// Conditionally include Chart.js for pages with chart annotations or lvt-source charts
 	chartScript := ""
 	if page.HasCharts {
 		chartScript = `
     <!-- Chart.js for data visualization (embedded) -->
     <script src="/assets/chart.js"></script>
     <script>
-        document.addEventListener('DOMContentLoaded', function() {
-            var isDark = document.documentElement.classList.contains('theme-dark');
-            var colors = [
-                'rgba(54, 162, 235, 0.8)',
-                'rgba(255, 99, 132, 0.8)',
-                'rgba(75, 192, 192, 0.8)',
-                'rgba(255, 205, 86, 0.8)',
-                'rgba(153, 102, 255, 0.8)',
-                'rgba(255, 159, 64, 0.8)',
-                'rgba(201, 203, 207, 0.8)'
-            ];
-            var borderColors = colors.map(function(c) { return c.replace('0.8', '1'); });
-
-            document.querySelectorAll('.tinkerdown-chart').forEach(function(container) {
-                var canvas = container.querySelector('canvas');
-                var chartType = container.dataset.chartType || 'bar';
-                var chartTitle = container.dataset.chartTitle || '';
-                var chartData, opts;
-                try {
-                    chartData = JSON.parse(container.dataset.chartData || '{}');
-                    opts = container.dataset.chartOptions ? JSON.parse(container.dataset.chartOptions) : {};
-                } catch(e) {
-                    console.warn('[Tinkerdown] Failed to parse chart data:', e);
-                    return;
+        // The server builds each chart's config (internal/runtime/charts.go);
+        // only the colors that follow the theme are set here
+        function themeChartColors(options, isDark) {
+            options.plugins.title.color = isDark ? '#e0e0e0' : '#333';
+            options.plugins.legend.labels = options.plugins.legend.labels || {};
+            options.plugins.legend.labels.color = isDark ? '#e0e0e0' : '#333';
+            ['x', 'y'].forEach(function(axis) {
+                var scale = options.scales && options.scales[axis];
+                if (scale) {
+                    scale.ticks = scale.ticks || {};
+                    scale.grid = scale.grid || {};
+                    scale.ticks.color = isDark ? '#b0b0b0' : '#555';
+                    scale.grid.color = isDark ? '#404040' : '#e1e4e8';
                 }
+            });
+        }
 
-                // Custom colors from frontmatter or defaults
-                var chartColors = (opts.colors && opts.colors.length) ? opts.colors : colors;
-                var chartBorderColors = (opts.colors && opts.colors.length) ? opts.colors.map(function(c) {
-                    return c.startsWith('rgba') ? c.replace(/[\d.]+\)$/, '1)') : c;
-                }) : borderColors;
-
-                chartData.datasets.forEach(function(dataset, i) {
-                    if (chartType === 'pie' || chartType === 'doughnut') {
-                        dataset.backgroundColor = chartColors.slice(0, dataset.data.length);
-                        dataset.borderColor = chartBorderColors.slice(0, dataset.data.length);
-                    } else {
-                        dataset.backgroundColor = chartColors[i % chartColors.length];
-                        dataset.borderColor = chartBorderColors[i % chartBorderColors.length];
-                    }
-                    dataset.borderWidth = 1;
-                });
-
-                // Horizontal bar: use indexAxis 'y'
-                var indexAxis = (opts.horizontal && chartType === 'bar') ? 'y' : 'x';
-
-                // Legend visibility (default: show)
-                var showLegend = (opts.legend === false) ? false : true;
-
-                // Stacking
-                var stacked = !!opts.stacked;
-
-                var isPie = (chartType === 'pie' || chartType === 'doughnut');
-                var scalesConfig = isPie ? {} : {
-                    x: {
-                        stacked: stacked,
-                        ticks: { color: isDark ? '#b0b0b0' : '#555' },
-                        grid: { color: isDark ? '#404040' : '#e1e4e8' }
-                    },
-                    y: {
-                        stacked: stacked,
-                        ticks: { color: isDark ? '#b0b0b0' : '#555' },
-                        grid: { color: isDark ? '#404040' : '#e1e4e8' }
-                    }
-                };
+        // renderChart draws the chart in container from its config, replacing
+        // the chart already drawn there. Charts of an lvt-source get a new
+        // config each time the source refreshes.
+        function renderChart(container) {
+            var canvas = container.querySelector('canvas');
+            if (!canvas || !container.dataset.chartConfig) {
+                return;
+            }
+            var config;
+            try {
+                config = JSON.parse(container.dataset.chartConfig);
+            } catch(e) {
+                console.warn('[Tinkerdown] Failed to parse chart config:', e);
+                return;
+            }
+            var existing = Chart.getChart(canvas);
+            if (existing) {
+                existing.destroy();
+            }
+            themeChartColors(config.options, document.documentElement.getAttribute('data-theme') === 'dark');
+            new Chart(canvas, config);
+        }
 
-                new Chart(canvas, {
-                    type: chartType,
-                    data: chartData,
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: true,
-                        indexAxis: indexAxis,
-                        plugins: {
-                            title: {
-                                display: !!chartTitle,
-                                text: chartTitle,
-                                color: isDark ? '#e0e0e0' : '#333'
-                            },
-                            legend: {
-                                display: showLegend,
-                                labels: { color: isDark ? '#e0e0e0' : '#333' }
-                            }
-                        },
-                        scales: scalesConfig
+        document.addEventListener('DOMContentLoaded', function() {
+            document.querySelectorAll('.tinkerdown-chart').forEach(renderChart);
+
+            // Live charts: redraw when an update changes a config, or adds a chart
+            new MutationObserver(function(mutations) {
+                var changed = new Set();
+                mutations.forEach(function(m) {
+                    if (m.type === 'attributes') {
+                        changed.add(m.target);
+                        return;
                     }
+                    m.addedNodes.forEach(function(node) {
+                        if (node.nodeType !== Node.ELEMENT_NODE) {
+                            return;
+                        }
+                        var container = node.closest('.tinkerdown-chart');
+                        if (container) {
+                            changed.add(container);
+                        }
+                        node.querySelectorAll('.tinkerdown-chart').forEach(function(c) { changed.add(c); });
+                    });
                 });
-            });
+                changed.forEach(renderChart);
+            }).observe(document.body, {childList: true, subtree: true, attributes: true, attributeFilter: ['data-chart-config']});
         });
 
         document.addEventListener('themeChanged', function(e) {
             document.querySelectorAll('.tinkerdown-chart canvas').forEach(function(canvas) {
                 var chart = Chart.getChart(canvas);
                 if (chart) {
-                    var isDark = e.detail.theme === 'dark';
-                    chart.options.plugins.title.color = isDark ? '#e0e0e0' : '#333';
-                    chart.options.plugins.legend.labels.color = isDark ? '#e0e0e0' : '#333';
-                    if (chart.options.scales && chart.options.scales.x) {
-                        chart.options.scales.x.ticks.color = isDark ? '#b0b0b0' : '#555';
-                        chart.options.scales.x.grid.color = isDark ? '#404040' : '#e1e4e8';
-                    }
-                    if (chart.options.scales && chart.options.scales.y) {
-                        chart.options.scales.y.ticks.color = isDark ? '#b0b0b0' : '#555';
-                        chart.options.scales.y.grid.color = isDark ? '#404040' : '#e1e4e8';
-                    }
+                    themeChartColors(chart.options, e.detail.theme === 'dark');
                     chart.update();
                 }
             });
@@ -798,1336 +949,212 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
     </script>`
 	}
 
+	// Only load syntax highlighting and diagram assets the page actually uses
+	syntax := detectSyntaxAssets(content)
+	prismCSS := ""
+	syntaxScripts := ""
+	if syntax.Prism {
+		prismCSS = `
+    <!-- Prism.js for syntax highlighting (embedded) -->
+    <link href="/assets/prism.css" rel="stylesheet" />`
+		var b strings.Builder
+		b.WriteString(`
+    <!-- Prism.js for syntax highlighting (embedded) -->
+    <script src="/assets/prism.js"></script>`)
+		for _, lang := range syntax.PrismLanguages {
+			fmt.Fprintf(&b, "\n    <script src=\"/assets/prism-%s.js\"></script>", lang)
+		}
+		if strings.Contains(content, `<pre class="code-lines`) {
+			b.WriteString(codeLinesScript)
+		}
+		b.WriteString(`
+    <script>
+        // Highlight all code blocks on page load
+        document.addEventListener('DOMContentLoaded', function() {
+            Prism.highlightAll();
+        });
+    </script>`)
+		syntaxScripts += b.String()
+	}
+	if syntax.Mermaid {
+		syntaxScripts += `
+    <!-- Mermaid.js for diagrams (embedded) -->
+    <script src="/assets/mermaid.js"></script>
+    <script>
+        // Initialize Mermaid for diagram rendering
+        mermaid.initialize({
+            startOnLoad: false, // We'll trigger manually after conversion
+            theme: document.documentElement.getAttribute('data-theme') === 'dark' ? 'dark' : 'default',
+            flowchart: {
+                useMaxWidth: true,
+                htmlLabels: true,
+                curve: 'basis'
+            },
+            sequence: {
+                diagramMarginX: 50,
+                diagramMarginY: 10,
+                actorMargin: 50,
+                width: 150,
+                height: 65,
+                boxMargin: 10,
+                boxTextMargin: 5,
+                noteMargin: 10,
+                messageMargin: 35,
+                mirrorActors: true,
+                useMaxWidth: true
+            }
+        });
+
+        // Convert mermaid code blocks to rendered diagrams
+        document.addEventListener('DOMContentLoaded', function() {
+            // Find all code blocks with language-mermaid class
+            const mermaidBlocks = document.querySelectorAll('code.language-mermaid');
+
+            mermaidBlocks.forEach(function(codeBlock) {
+                // Get the mermaid code
+                const code = codeBlock.textContent;
+
+                // Create a new div for the rendered diagram
+                const mermaidDiv = document.createElement('div');
+                mermaidDiv.className = 'mermaid';
+                mermaidDiv.textContent = code;
+                // Keep the source so diagrams can be re-rendered on theme change
+                mermaidDiv.setAttribute('data-mermaid-source', code);
+
+                // Replace the pre>code structure with just the mermaid div
+                const preBlock = codeBlock.parentElement;
+                preBlock.parentNode.replaceChild(mermaidDiv, preBlock);
+            });
+
+            // Now render all mermaid diagrams
+            mermaid.run();
+        });
+
+        // Re-initialize Mermaid when theme changes
+        document.addEventListener('themeChanged', function(e) {
+            mermaid.initialize({
+                theme: e.detail.theme === 'dark' ? 'dark' : 'default'
+            });
+            // mermaid.run() skips processed diagrams, so restore their source first
+            document.querySelectorAll('.mermaid[data-mermaid-source]').forEach(function(el) {
+                el.textContent = el.getAttribute('data-mermaid-source');
+                el.removeAttribute('data-processed');
+            });
+            mermaid.run();
+        });
+    </script>`
+	}
+
+	// Site-wide anchor scroll offset override (styling.scroll_offset)
+	scrollOffsetStyle := ""
+	if offset := s.config.Styling.GetScrollOffset(); offset != "" {
+		scrollOffsetStyle = fmt.Sprintf("\n    <style>:root { --scroll-offset: %s; }</style>", offset)
+	}
+
+	// Color presets (styling.preset), which readers can switch in the toolbar
+	preset := sitePreset(s.config.Styling)
+	presetAttr := ""
+	if preset != "" {
+		presetAttr = fmt.Sprintf(` data-preset="%s"`, preset)
+	}
+	presetStyle := renderPresetStyle(s.config.Styling)
+	presetPicker := renderPresetPicker(s.config.Styling, preset)
+
+	// Keyboard shortcuts (configurable via the shortcuts: config map)
+	keymap := s.config.GetShortcuts()
+	shortcutScript := renderShortcutScript(keymap)
+	shortcutOverlay := renderShortcutOverlay(keymap)
+
 	// Basic HTML wrapper with the static content
-	html := fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+	// Only the server's own scripts get the CSP nonce, not any in content
+	html := fmt.Sprintf(nonceScripts(`<!DOCTYPE html>
+<html lang="%s"%s>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <meta name="tinkerdown-ws-url" content="%s">
     <meta name="tinkerdown-debug" content="true">
-    <meta name="tinkerdown-sidebar" content="%t">
+    <meta name="tinkerdown-sidebar" content="%t">%s
     <title>%s</title>
+%s
     <!-- PicoCSS - Semantic/Classless CSS Framework (embedded) -->
     <link rel="stylesheet" href="/assets/pico.css">
-    <link rel="stylesheet" href="/assets/tinkerdown-client.css">
-    <style>
-        /* Theme Variables */
-        :root {
-            --bg-primary: #ffffff;
-            --bg-secondary: linear-gradient(135deg, #f5f7fa 0%%, #e8ecf1 100%%);
-            --text-primary: #333;
-            --text-secondary: #555;
-            --text-heading: #2c3e50;
-            --border-color: #e1e4e8;
-            --code-bg: #f4f4f4;
-            --code-border: #e1e4e8;
-            --pre-bg: #282c34;
-            --pre-text: #abb2bf;
-            --card-bg: #ffffff;
-            --card-border: rgba(0,0,0,0.06);
-            --card-shadow: rgba(0,0,0,0.08);
-            --accent: #0066cc;
-
-            /* PicoCSS size overrides - reduce by ~25%% */
-            --pico-font-size: 87.5%%;
-            --pico-spacing: 0.75rem;
-            --pico-form-element-spacing-vertical: 0.5rem;
-            --pico-form-element-spacing-horizontal: 0.75rem;
-        }
+    <link rel="stylesheet" href="%s">
+    <link rel="stylesheet" href="%s">
 
-        [data-theme="dark"] {
-            --bg-primary: #1a1a1a;
-            --bg-secondary: linear-gradient(135deg, #1a1a1a 0%%, #2d2d2d 100%%);
-            --text-primary: #e0e0e0;
-            --text-secondary: #b0b0b0;
-            --text-heading: #f0f0f0;
-            --border-color: #404040;
-            --code-bg: #2d2d2d;
-            --code-border: #404040;
-            --pre-bg: #1e1e1e;
-            --pre-text: #d4d4d4;
-            --card-bg: #242424;
-            --card-border: rgba(255,255,255,0.1);
-            --card-shadow: rgba(0,0,0,0.3);
-            --accent: #4da6ff;
-        }
-
-        /* Theme transition */
-        * {
-            transition: background-color 0.3s ease, color 0.3s ease, border-color 0.3s ease;
-        }
-
-        /* Base styles */
-        * {
-            box-sizing: border-box;
-        }
+%s
+</head>
+<body>
+    <!-- Unified Toolbar -->
+    <div class="page-toolbar">%s
+        <!-- Presentation Mode Toggle -->
+        <button id="presentation-toggle" class="presentation-btn" title="Toggle presentation mode (F key)" aria-label="Toggle presentation mode">
+            <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
+                <rect x="2" y="7" width="20" height="15" rx="2" ry="2"/>
+                <polyline points="17 2 12 7 7 2"/>
+            </svg>
+        </button>
 
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, "Helvetica Neue", Arial, sans-serif;
-            line-height: 1.7;
-            max-width: 1200px;
-            margin: 0 auto;
-            padding: 2rem 1.5rem;
-            color: var(--text-primary);
-            background: var(--bg-secondary);
-            min-height: 100vh;
-        }
+        <!-- Theme Toggle -->
+        <div class="theme-toggle">
+            <button id="theme-light" title="Light theme" aria-label="Light theme">
+                <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
+                    <circle cx="12" cy="12" r="5"/>
+                    <line x1="12" y1="1" x2="12" y2="3"/>
+                    <line x1="12" y1="21" x2="12" y2="23"/>
+                    <line x1="4.22" y1="4.22" x2="5.64" y2="5.64"/>
+                    <line x1="18.36" y1="18.36" x2="19.78" y2="19.78"/>
+                    <line x1="1" y1="12" x2="3" y2="12"/>
+                    <line x1="21" y1="12" x2="23" y2="12"/>
+                    <line x1="4.22" y1="19.78" x2="5.64" y2="18.36"/>
+                    <line x1="18.36" y1="5.64" x2="19.78" y2="4.22"/>
+                </svg>
+            </button>
+            <button id="theme-dark" title="Dark theme" aria-label="Dark theme">
+                <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
+                    <path d="M21 12.79A9 9 0 1 1 11.21 3 7 7 0 0 0 21 12.79z"/>
+                </svg>
+            </button>
+            <button id="theme-auto" title="Auto theme (system preference)" aria-label="Auto theme">
+                <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
+                    <rect x="2" y="3" width="20" height="14" rx="2" ry="2"/>
+                    <line x1="8" y1="21" x2="16" y2="21"/>
+                    <line x1="12" y1="17" x2="12" y2="21"/>
+                </svg>
+            </button>%s
+        </div>
+    </div>
 
-        /* Content wrapper for readable line lengths */
-        .content-wrapper {
-            max-width: 900px;
-            margin: 0 auto;
-            padding: 2rem 3rem;
-        }
+    %s
+    %s
 
-        /* Typography */
-        h1, h2, h3 {
-            color: var(--text-heading);
-            letter-spacing: -0.02em;
-            line-height: 1.4;
-        }
+    <script>
+        // Theme management
+        (function() {
+            const STORAGE_KEY = 'tinkerdown-theme';
+            const html = document.documentElement;
 
-        h1 {
-            font-size: 2.25rem !important;
-            font-weight: 900 !important;
-            margin-bottom: 1.5rem !important;
-            margin-top: 0 !important;
-            padding-bottom: 0.75rem !important;
-            border-bottom: 3px solid var(--accent) !important;
-            color: var(--text-heading) !important;
-            line-height: 1.2 !important;
-            letter-spacing: -0.025em !important;
-        }
+            // Get current theme from localStorage or default to 'auto'
+            function getStoredTheme() {
+                return localStorage.getItem(STORAGE_KEY) || 'auto';
+            }
 
-        /* Subtitle/description that follows H1 */
-        h1 + p {
-            font-size: 1rem;
-            margin-top: 0;
-            padding-top: 1rem;
-            margin-bottom: 2rem;
-            color: var(--text-secondary);
-            line-height: 1.6;
-        }
-
-        h2 {
-            font-size: 1.4rem !important;
-            font-weight: 700 !important;
-            margin-top: 2.5rem !important;
-            margin-bottom: 1rem !important;
-            padding-bottom: 0.5rem !important;
-            border-bottom: 2px solid var(--border-color) !important;
-            color: var(--text-heading) !important;
-            line-height: 1.3 !important;
-            letter-spacing: -0.02em !important;
-        }
-
-        h3 {
-            font-size: 1.125rem !important;
-            font-weight: 700 !important;
-            margin-top: 1.75rem !important;
-            margin-bottom: 0.75rem !important;
-            color: var(--text-heading) !important;
-            line-height: 1.4 !important;
-        }
-
-        /* Space content after H2 to prevent sticking to border */
-        h2 + p, h2 + ul, h2 + ol, h2 + pre, h2 + h3 {
-            padding-top: 1rem;
-        }
-
-        /* Space content after H3 */
-        h3 + p, h3 + ul, h3 + ol, h3 + pre {
-            padding-top: 0.5rem;
-        }
-
-        p {
-            margin-bottom: 1.25rem;
-            margin-top: 0;
-            color: var(--text-secondary);
-            line-height: 1.7;
-        }
-
-        /* Better list styling */
-        ul, ol {
-            margin: 1rem 0 1.5rem 0;
-            padding-left: 1.5rem;
-        }
-
-        li {
-            margin-bottom: 0.5rem;
-            line-height: 1.7;
-            color: var(--text-secondary);
-        }
-
-        li:last-child {
-            margin-bottom: 0;
-        }
-
-        /* Add spacing after lists before next heading */
-        ul + h2, ol + h2,
-        ul + h3, ol + h3 {
-            margin-top: 3rem;
-        }
-
-        /* Content links */
-        .content-wrapper a:not(.prev-next-link) {
-            color: var(--accent);
-            text-decoration: none;
-            border-bottom: 1px solid rgba(0, 102, 204, 0.3);
-            transition: all 0.2s ease;
-            font-weight: 500;
-        }
-
-        .content-wrapper a:not(.prev-next-link):hover {
-            border-bottom-color: var(--accent);
-            background: rgba(0, 102, 204, 0.05);
-            padding: 0 0.2rem;
-            margin: 0 -0.2rem;
-        }
-
-        .content-wrapper a:not(.prev-next-link):visited {
-            color: rgb(117, 55, 184);
-            border-bottom-color: rgba(117, 55, 184, 0.3);
-        }
-
-        .content-wrapper a:not(.prev-next-link):visited:hover {
-            border-bottom-color: rgb(117, 55, 184);
-            background: rgba(117, 55, 184, 0.05);
-        }
-
-        [data-theme="dark"] .content-wrapper a:not(.prev-next-link):visited {
-            color: rgb(196, 181, 253);
-            border-bottom-color: rgba(196, 181, 253, 0.3);
-        }
-
-        /* Code blocks */
-        code {
-            background: var(--code-bg);
-            padding: 0.2rem 0.4rem;
-            border-radius: 4px;
-            font-size: 0.9em;
-            font-family: 'Monaco', 'Menlo', 'Ubuntu Mono', monospace;
-            border: 1px solid var(--code-border);
-            color: var(--text-primary);
-        }
-
-        pre {
-            background: var(--pre-bg);
-            color: var(--pre-text);
-            padding: 1.25rem 1rem;
-            border-radius: 8px;
-            overflow-x: auto;
-            margin: 1.5rem calc((800px - 100%%) / 2 * -1);
-            max-width: 1000px;
-            box-shadow: 0 4px 12px rgba(0,0,0,0.15);
-            border: 1px solid var(--border-color);
-        }
-
-        pre code {
-            background: none;
-            border: none;
-            padding: 0;
-            color: inherit;
-        }
-
-        /* Interactive blocks */
-        .tinkerdown-wasm-block,
-        .tinkerdown-interactive-block {
-            margin: 2rem calc((800px - 100%%) / 2 * -1);
-            max-width: 1000px;
-            padding: 1.5rem;
-            background: var(--card-bg);
-            border-radius: 16px;
-            box-shadow: 0 4px 16px var(--card-shadow);
-            border: 1px solid var(--card-border);
-            transition: transform 0.2s ease, box-shadow 0.2s ease;
-        }
-
-        .tinkerdown-wasm-block:hover,
-        .tinkerdown-interactive-block:hover {
-            transform: translateY(-2px);
-            box-shadow: 0 8px 24px var(--card-shadow);
-        }
-
-        /* Chart containers */
-        .tinkerdown-chart {
-            margin: 1.5rem 0;
-            padding: 1rem;
-            background: var(--card-bg);
-            border-radius: 8px;
-            border: 1px solid var(--card-border);
-            box-shadow: 0 1px 3px var(--card-shadow);
-            max-width: 100%%;
-        }
-
-        .tinkerdown-chart canvas {
-            max-width: 100%%;
-            height: auto !important;
-        }
-
-        .tinkerdown-chart-table {
-            margin: 0.5rem 0 1.5rem;
-            font-size: 0.875rem;
-        }
-
-        .tinkerdown-chart-table summary {
-            cursor: pointer;
-            color: var(--text-secondary);
-            font-size: 0.8rem;
-            padding: 0.25rem 0;
-        }
-
-        .tinkerdown-chart-table table {
-            margin-top: 0.5rem;
-        }
-
-        /* Buttons - Let PicoCSS handle default styling */
-
-        /* Counter display */
-        .counter-display {
-            font-size: 3rem;
-            font-weight: 700;
-            text-align: center;
-            margin: 2rem 0;
-            padding: 1.5rem;
-            background: linear-gradient(135deg, #f5f7fa 0%%, #ffffff 100%%);
-            border-radius: 16px;
-            transition: all 0.3s cubic-bezier(0.4, 0, 0.2, 1);
-            border: 2px solid #e1e4e8;
-        }
-
-        .counter-display.positive {
-            color: #10b981;
-            border-color: #10b981;
-            box-shadow: 0 0 0 3px rgba(16, 185, 129, 0.1);
-        }
-
-        .counter-display.negative {
-            color: #ef4444;
-            border-color: #ef4444;
-            box-shadow: 0 0 0 3px rgba(239, 68, 68, 0.1);
-        }
-
-        .counter-display.zero {
-            color: #6b7280;
-            border-color: #d1d5db;
-        }
-
-        /* Number transition animation */
-        @keyframes numberPulse {
-            0%%, 100%% { transform: scale(1); }
-            50%% { transform: scale(1.1); }
-        }
-
-        .counter-display.changed {
-            animation: numberPulse 0.3s ease;
-        }
-
-        /* Button groups */
-        .button-group {
-            display: flex;
-            justify-content: center;
-            flex-wrap: wrap;
-            gap: 0.5rem;
-            margin: 1rem 0;
-        }
-
-        /* Responsive design */
-        @media (max-width: 768px) {
-            body {
-                padding: 1rem;
-            }
-
-            h1 {
-                font-size: 1.875rem !important;
-                font-weight: 900 !important;
-            }
-
-            h2 {
-                font-size: 1.25rem !important;
-                font-weight: 700 !important;
-            }
-
-            h3 {
-                font-size: 1rem !important;
-                font-weight: 700 !important;
-            }
-
-            .tinkerdown-wasm-block,
-            .tinkerdown-interactive-block {
-                padding: 1rem;
-                border-radius: 10px;
-            }
-
-            .counter-display {
-                font-size: 1.875rem;
-                padding: 1rem;
-            }
-        }
-
-        @media (max-width: 480px) {
-            body {
-                padding: 0.5rem;
-            }
-
-            h1 {
-                font-size: 1.5rem !important;
-                font-weight: 900 !important;
-            }
-
-            h2 {
-                font-size: 1.125rem !important;
-                font-weight: 700 !important;
-            }
-
-            h3 {
-                font-size: 1rem !important;
-                font-weight: 700 !important;
-            }
-
-            .tinkerdown-wasm-block,
-            .tinkerdown-interactive-block {
-                padding: 1rem;
-                margin: 1rem 0;
-            }
-
-            .counter-display {
-                font-size: 2rem;
-                padding: 1rem;
-            }
-
-            .button-group {
-                flex-direction: column;
-            }
-        }
-
-        /* Unified Page Toolbar */
-        .page-toolbar {
-            position: fixed;
-            top: 1rem;
-            right: 1.5rem;
-            z-index: 1000;
-            display: flex;
-            gap: 0.5rem;
-            align-items: center;
-            background: var(--card-bg);
-            padding: 0.35rem;
-            border-radius: 8px;
-            box-shadow: 0 2px 8px var(--card-shadow);
-            border: 1px solid var(--card-border);
-            opacity: 0.6;
-            transition: opacity 0.3s ease;
-        }
-
-        .page-toolbar:hover {
-            opacity: 1;
-            box-shadow: 0 4px 12px var(--card-shadow);
-        }
-
-        /* Presentation Mode Button */
-        .presentation-btn {
-            background: transparent;
-            border: 1px solid var(--border-color);
-            color: var(--text-primary);
-            padding: 0.5rem;
-            margin: 0;
-            border-radius: 6px;
-            width: 2.25rem;
-            height: 2.25rem;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            cursor: pointer;
-            transition: all 0.2s ease;
-            flex-shrink: 0;
-        }
-
-        .presentation-btn svg {
-            width: 1.1rem;
-            height: 1.1rem;
-            display: block;
-        }
-
-        .presentation-btn:hover {
-            background: var(--code-bg);
-        }
-
-        .presentation-btn.active {
-            background: var(--accent);
-            color: white;
-            border-color: var(--accent);
-        }
-
-        .presentation-btn:active {
-            transform: scale(0.95);
-        }
-
-        /* Theme Toggle */
-        .theme-toggle {
-            display: flex;
-            gap: 0.35rem;
-            align-items: center;
-        }
-
-        .theme-toggle button {
-            background: transparent;
-            border: 1px solid var(--border-color);
-            color: var(--text-primary);
-            padding: 0.5rem;
-            margin: 0;
-            border-radius: 6px;
-            font-size: 1rem;
-            width: 2.25rem;
-            height: 2.25rem;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            box-shadow: none;
-            transition: all 0.2s ease;
-            flex-shrink: 0;
-        }
-
-        .theme-toggle button svg {
-            width: 1.1rem;
-            height: 1.1rem;
-            display: block;
-        }
-
-        .theme-toggle button:hover {
-            background: var(--code-bg);
-            transform: none;
-            box-shadow: none;
-        }
-
-        .theme-toggle button.active {
-            background: var(--accent);
-            color: white;
-            border-color: var(--accent);
-        }
-
-        .theme-toggle button:active {
-            transform: scale(0.95);
-        }
-
-        /* Presentation Mode Styles */
-        body.presentation-mode {
-            margin-left: 0 !important;
-            max-width: 100%% !important;
-            width: 100%% !important;
-            padding: 0 !important;
-        }
-
-        body.presentation-mode .tinkerdown-nav-sidebar {
-            display: none;
-        }
-
-        body.presentation-mode .tinkerdown-nav-bottom {
-            left: 0;
-            width: 100%%;
-        }
-
-        body.presentation-mode .theme-toggle,
-        body.presentation-mode .presentation-btn {
-            opacity: 0.3;
-            transition: opacity 0.3s ease;
-        }
-
-        body.presentation-mode .theme-toggle:hover,
-        body.presentation-mode .presentation-btn:hover {
-            opacity: 1;
-        }
-
-        /* Hide all H2 sections except current in presentation mode */
-        body.presentation-mode .content-wrapper > * {
-            display: none;
-        }
-
-        body.presentation-mode .presentation-current-section {
-            display: block !important;
-        }
-
-        body.presentation-mode .content-wrapper {
-            max-width: 100%%;
-            width: 100%%;
-            padding: 2rem 4rem;
-            margin: 0 auto;
-        }
-
-        body.presentation-mode h2 {
-            font-size: 2.5rem;
-            margin-bottom: 2rem;
-        }
-
-        body.presentation-mode p,
-        body.presentation-mode li {
-            font-size: 1.25rem;
-            line-height: 1.8;
-        }
-
-        body.presentation-mode code {
-            font-size: 1.1rem;
-        }
-
-        body.presentation-mode pre {
-            font-size: 1rem;
-        }
-
-        /* Toolbar position in presentation mode - top-right corner */
-        body.presentation-mode .page-toolbar {
-            position: fixed !important;
-            top: 1rem !important;
-            right: 1rem !important;
-            bottom: auto !important;
-            left: auto !important;
-            z-index: 1001;
-        }
-
-        /* Tutorial Navigation - Sidebar TOC */
-        .tinkerdown-nav-sidebar {
-            position: fixed;
-            left: 0;
-            top: 0;
-            bottom: 0;
-            width: 360px;
-            background: var(--card-bg);
-            border-right: 1px solid var(--card-border);
-            box-shadow: 2px 0 8px var(--card-shadow);
-            z-index: 900;
-            display: flex;
-            flex-direction: column;
-            overflow: hidden;
-        }
-
-        .nav-sidebar-header {
-            padding: 1.5rem;
-            border-bottom: 1px solid var(--border-color);
-            background: var(--bg-secondary);
-        }
-
-        .nav-sidebar-header h3 {
-            margin: 0;
-            font-size: 1rem;
-            font-weight: 600;
-            color: var(--text-heading);
-            text-transform: uppercase;
-            letter-spacing: 0.5px;
-        }
-
-        .nav-sidebar-steps {
-            flex: 1;
-            overflow-y: auto;
-            padding: 0;
-            margin: 0;
-            list-style: none;
-            /* Override PicoCSS nav > ol horizontal layout */
-            display: flex;
-            flex-direction: column;
-            flex-wrap: nowrap;
-        }
-
-        .nav-step {
-            border-bottom: 1px solid var(--border-color);
-            /* Override PicoCSS nav > ol > li horizontal layout */
-            display: block;
-            width: 100%%;
-        }
-
-        .nav-step a {
-            display: flex;
-            align-items: center;
-            justify-content: flex-start;
-            gap: 1rem;
-            padding: 1rem 1.5rem;
-            text-decoration: none;
-            color: var(--text-secondary);
-            transition: all 0.2s ease;
-            width: 100%%;
-            text-align: left;
-        }
-
-        .nav-step:hover a {
-            background: var(--code-bg);
-            color: var(--text-primary);
-        }
-
-        .nav-step.active a {
-            background: var(--accent);
-            color: white;
-            font-weight: 500;
-        }
-
-        .step-number {
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            width: 28px;
-            height: 28px;
-            border-radius: 50%%;
-            background: var(--code-bg);
-            color: var(--text-primary);
-            font-size: 0.875rem;
-            font-weight: 600;
-            flex-shrink: 0;
-        }
-
-        .nav-step.active .step-number {
-            background: rgba(255, 255, 255, 0.2);
-            color: white;
-        }
-
-        .step-title {
-            flex: 1;
-            font-size: 0.9rem;
-            line-height: 1.4;
-        }
-
-        /* Site Navigation Styles */
-        .nav-header {
-            padding: 2rem 2.5rem;
-            border-bottom: 1px solid var(--border-color);
-        }
-
-        .nav-header h2 {
-            margin: 0;
-            font-size: 1.2rem;
-            font-weight: 600;
-            color: var(--text-heading);
-        }
-
-        .nav-section {
-            border-bottom: 1px solid var(--border-color);
-        }
-
-        .nav-section-title {
-            padding: 1rem 2.5rem;
-            font-size: 0.9rem;
-            font-weight: 600;
-            color: var(--text-secondary);
-            text-transform: uppercase;
-            letter-spacing: 0.5px;
-            background: var(--code-bg);
-        }
-
-        .nav-pages {
-            list-style: none;
-            margin: 0;
-            padding: 0;
-        }
-
-        .nav-pages li a {
-            display: block;
-            padding: 0.85rem 2.5rem 0.85rem 3rem;
-            color: var(--text-secondary);
-            text-decoration: none;
-            font-size: 0.95rem;
-            transition: all 0.2s ease;
-            border-left: 4px solid transparent;
-            margin: 2px 0;
-        }
-
-        .nav-pages li a:hover {
-            background: var(--code-bg);
-            color: var(--text-primary);
-        }
-
-        .nav-pages li a.active {
-            background: rgba(0, 102, 204, 0.1);
-            color: var(--accent);
-            border-left-color: var(--accent);
-            font-weight: 600;
-        }
-
-        [data-theme="dark"] .nav-pages li a.active {
-            background: rgba(77, 166, 255, 0.15);
-        }
-
-        /* Sidebar Footer - for toolbar when inside sidebar */
-        .nav-sidebar-footer {
-            padding: 1rem;
-            border-top: 1px solid var(--border-color);
-            background: var(--bg-primary);
-            margin-top: auto;
-        }
-
-        .nav-sidebar-footer .page-toolbar {
-            position: static;
-            width: 100%%;
-            justify-content: center;
-            opacity: 1;
-            background: transparent;
-            box-shadow: none;
-            border: none;
-            padding: 0;
-        }
-
-        /* Breadcrumbs */
-        .breadcrumbs {
-            padding: 1rem 0;
-            margin-bottom: 1.5rem;
-            border-bottom: 1px solid var(--border-color);
-        }
-
-        .breadcrumbs ol {
-            list-style: none;
-            margin: 0;
-            padding: 0;
-            display: flex;
-            align-items: baseline;
-            flex-wrap: wrap;
-            gap: 0;
-        }
-
-        .breadcrumbs li {
-            display: inline-flex;
-            align-items: baseline;
-        }
-
-        .breadcrumbs a,
-        .breadcrumbs .current,
-        .breadcrumbs .separator {
-            font-size: 0.9rem;
-            line-height: 1.5;
-            vertical-align: baseline;
-        }
-
-        .breadcrumbs a {
-            color: var(--accent);
-            text-decoration: none;
-            cursor: pointer;
-            transition: all 0.2s ease;
-        }
-
-        .breadcrumbs a:hover {
-            text-decoration: underline;
-        }
-
-        .breadcrumbs .separator {
-            color: var(--text-secondary);
-            margin: 0 0.5rem;
-        }
-
-        .breadcrumbs .current {
-            color: var(--text-primary);
-            font-weight: 500;
-        }
-
-        /* Page Navigation (Prev/Next) */
-        .page-nav {
-            display: flex;
-            justify-content: space-between;
-            gap: 1rem;
-            margin-top: 3rem;
-            padding-top: 2rem;
-            border-top: 1px solid var(--border-color);
-        }
-
-        .page-nav a {
-            display: flex;
-            align-items: center;
-            gap: 0.5rem;
-            padding: 1rem;
-            background: var(--card-bg);
-            border: 1px solid var(--card-border);
-            border-radius: 8px;
-            text-decoration: none;
-            color: var(--text-primary);
-            transition: all 0.2s ease;
-            flex: 1;
-            max-width: 45%%;
-        }
-
-        .page-nav a:hover {
-            background: var(--code-bg);
-            border-color: var(--accent);
-            transform: translateY(-2px);
-            box-shadow: 0 4px 8px var(--card-shadow);
-        }
-
-        .page-nav-prev {
-            justify-content: flex-start;
-        }
-
-        .page-nav-next {
-            justify-content: flex-end;
-            margin-left: auto;
-        }
-
-        .page-nav .arrow {
-            font-size: 1.2rem;
-            color: var(--accent);
-        }
-
-        .page-nav .label {
-            font-size: 0.9rem;
-            font-weight: 500;
-        }
-
-        .page-nav-spacer {
-            flex: 1;
-        }
-
-        /* Tutorial Navigation - Bottom Bar */
-        .tinkerdown-nav-bottom {
-            position: fixed;
-            bottom: 0;
-            left: 360px;
-            right: 0;
-            height: 60px;
-            background: var(--card-bg);
-            border-top: 1px solid var(--card-border);
-            box-shadow: 0 -2px 8px var(--card-shadow);
-            z-index: 900;
-            display: flex;
-            align-items: center;
-            justify-content: space-between;
-            padding: 0 2rem;
-            gap: 1rem;
-        }
-
-        .nav-btn {
-            display: flex;
-            align-items: center;
-            gap: 0.5rem;
-            padding: 0.75rem 1.5rem;
-            background: var(--accent);
-            color: white;
-            border: none;
-            border-radius: 8px;
-            font-size: 1rem;
-            font-weight: 500;
-            cursor: pointer;
-            transition: all 0.2s ease;
-            box-shadow: 0 2px 8px rgba(0, 102, 204, 0.3);
-        }
-
-        .nav-btn:hover:not(:disabled) {
-            background: #0052a3;
-            transform: translateY(-1px);
-            box-shadow: 0 4px 12px rgba(0, 102, 204, 0.4);
-        }
-
-        .nav-btn:active:not(:disabled) {
-            transform: translateY(0);
-        }
-
-        .nav-btn:disabled {
-            background: var(--code-bg);
-            color: var(--text-secondary);
-            cursor: not-allowed;
-            box-shadow: none;
-        }
-
-        .nav-arrow {
-            font-size: 1.2rem;
-            line-height: 1;
-        }
-
-        .nav-progress {
-            font-size: 0.9rem;
-            color: var(--text-secondary);
-            font-weight: 500;
-        }
-
-        .current-step,
-        .total-steps {
-            color: var(--accent);
-            font-weight: 600;
-        }
-
-        /* Adjust main content to make room for navigation */
-        body:has(.tinkerdown-nav-sidebar) {
-            margin-left: 360px;
-            margin-right: 0;
-            margin-bottom: 60px;
-            max-width: none;
-        }
-
-        /* Responsive Navigation */
-        @media (max-width: 1024px) {
-            .tinkerdown-nav-sidebar {
-                width: 320px;
-            }
-
-            .tinkerdown-nav-bottom {
-                left: 320px;
-            }
-
-            body:has(.tinkerdown-nav-sidebar) {
-                margin-left: 320px;
-            }
-        }
-
-        @media (max-width: 768px) {
-            /* Hide sidebar on mobile, show hamburger menu */
-            .tinkerdown-nav-sidebar {
-                transform: translateX(-100%%);
-                transition: transform 0.3s ease;
-            }
-
-            .tinkerdown-nav-sidebar.open {
-                transform: translateX(0);
-            }
-
-            .tinkerdown-nav-bottom {
-                left: 0;
-                padding: 0 1rem;
-            }
-
-            body:has(.tinkerdown-nav-sidebar) {
-                margin-left: 0;
-            }
-
-            .nav-btn {
-                padding: 0.5rem 1rem;
-                font-size: 0.9rem;
-            }
-
-            .nav-label {
-                display: none;
-            }
-
-            .nav-arrow {
-                font-size: 1.5rem;
-            }
-
-            .nav-progress {
-                font-size: 0.85rem;
-            }
-        }
-
-        @media (max-width: 480px) {
-            .tinkerdown-nav-bottom {
-                height: 60px;
-                padding: 0 0.75rem;
-            }
-
-            body:has(.tinkerdown-nav-sidebar) {
-                margin-bottom: 60px;
-            }
-
-            .nav-btn {
-                padding: 0.5rem 0.75rem;
-                min-width: 40px;
-            }
-
-            .nav-progress {
-                font-size: 0.75rem;
-            }
-        }
-
-        /* Counter Variations Styling */
-        .counter-container {
-            background: var(--card-bg);
-            border: 1px solid var(--card-border);
-            border-radius: 12px;
-            padding: 1.5rem;
-            margin: 1rem 0;
-        }
-
-        .counter-header {
-            display: flex;
-            justify-content: space-between;
-            margin-bottom: 1rem;
-        }
-
-        .bounds-label {
-            font-size: 0.875rem;
-            color: var(--text-secondary);
-            font-weight: 500;
-        }
-
-        .counter-display.at-max {
-            background: #fef3c7;
-            border-color: #f59e0b;
-            color: #78350f;
-        }
-
-        .counter-display.at-min {
-            background: #fee2e2;
-            border-color: #ef4444;
-            color: #7f1d1d;
-        }
-
-        .counter-display.in-range {
-            background: var(--accent);
-            color: white;
-        }
-
-        .bounds-bar {
-            width: 100%%;
-            height: 6px;
-            background: var(--code-bg);
-            border-radius: 3px;
-            margin-top: 1rem;
-            overflow: hidden;
-        }
-
-        .bounds-progress {
-            height: 100%%;
-            background: var(--accent);
-            transition: width 0.3s ease;
-        }
-
-        /* Step Counter */
-        .step-buttons {
-            display: flex;
-            flex-direction: column;
-            gap: 0.75rem;
-        }
-
-        .button-row {
-            display: flex;
-            align-items: center;
-            gap: 0.75rem;
-        }
-
-        .row-label {
-            font-weight: 500;
-            min-width: 80px;
-            color: var(--text-secondary);
-        }
-
-        .step-btn {
-            flex: 1;
-            padding: 0.5rem 1rem;
-            font-size: 0.9rem;
-        }
-
-        .reset-btn {
-            width: 100%%;
-            margin-top: 0.5rem;
-        }
-
-        /* Dual Counter */
-        .dual-counter-container {
-            display: grid;
-            grid-template-columns: 1fr 1fr;
-            gap: 1.5rem;
-            margin: 1.5rem 0;
-        }
-
-        .dual-counter-item {
-            background: var(--card-bg);
-            border: 1px solid var(--card-border);
-            border-radius: 12px;
-            padding: 1.5rem;
-        }
-
-        .counter-label {
-            font-size: 0.875rem;
-            font-weight: 600;
-            color: var(--text-secondary);
-            margin-bottom: 1rem;
-            text-transform: uppercase;
-            letter-spacing: 0.5px;
-        }
-
-        @media (max-width: 768px) {
-            .dual-counter-container {
-                grid-template-columns: 1fr;
-            }
-        }
-
-        /* Shopping Cart Product */
-        .product-card {
-            background: var(--card-bg);
-            border: 1px solid var(--card-border);
-            border-radius: 12px;
-            padding: 1.5rem;
-            margin: 1.5rem 0;
-            box-shadow: 0 2px 8px var(--card-shadow);
-        }
-
-        .product-header {
-            display: flex;
-            justify-content: space-between;
-            align-items: center;
-            margin-bottom: 1rem;
-            padding-bottom: 1rem;
-            border-bottom: 1px solid var(--border-color);
-        }
-
-        .product-header h4 {
-            margin: 0;
-            color: var(--text-heading);
-        }
-
-        .product-price {
-            font-size: 1.25rem;
-            font-weight: 600;
-            color: var(--accent);
-        }
-
-        .quantity-selector {
-            display: flex;
-            justify-content: space-between;
-            align-items: center;
-            margin: 1rem 0;
-            padding: 0.75rem;
-            background: var(--code-bg);
-            border-radius: 8px;
-        }
-
-        .quantity-label {
-            font-weight: 500;
-            color: var(--text-secondary);
-        }
-
-        .quantity-controls {
-            display: flex;
-            align-items: center;
-            gap: 1rem;
-        }
-
-        .qty-btn {
-            width: 36px;
-            height: 36px;
-            padding: 0;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            font-size: 1.25rem;
-            border-radius: 50%%;
-        }
-
-        .quantity-display {
-            min-width: 40px;
-            text-align: center;
-            font-weight: 600;
-            font-size: 1.125rem;
-        }
-
-        .product-total {
-            display: flex;
-            justify-content: space-between;
-            align-items: center;
-            padding: 1rem;
-            margin: 1rem 0;
-            background: var(--bg-secondary);
-            border-radius: 8px;
-        }
-
-        .total-label {
-            font-weight: 500;
-            color: var(--text-secondary);
-        }
-
-        .total-amount {
-            font-size: 1.5rem;
-            font-weight: 700;
-            color: var(--accent);
-        }
-
-        .remove-btn {
-            background: transparent;
-            border: 1px solid #ef4444;
-            color: #ef4444;
-            box-shadow: none;
-        }
-
-        .remove-btn:hover {
-            background: #ef4444;
-            color: white;
-        }
-
-        .removed-message {
-            text-align: center;
-            padding: 2rem;
-            color: var(--text-secondary);
-            font-style: italic;
-        }
-
-        /* Prism.js syntax highlighting overrides */
-        pre[class*="language-"] {
-            margin: 1.5rem 0;
-            padding: 1rem;
-            border-radius: 8px;
-            overflow-x: auto;
-        }
-
-        code[class*="language-"],
-        pre[class*="language-"] {
-            font-family: 'Consolas', 'Monaco', 'Andale Mono', 'Ubuntu Mono', monospace;
-            font-size: 0.9rem;
-            line-height: 1.5;
-        }
-
-        /* Ensure code blocks are styled properly */
-        :not(pre) > code[class*="language-"] {
-            padding: 0.1em 0.3em;
-            border-radius: 0.3em;
-        }
-    </style>
-
-    <!-- Prism.js for syntax highlighting (embedded) -->
-    <link href="/assets/prism.css" rel="stylesheet" />
-</head>
-<body>
-    <!-- Unified Toolbar -->
-    <div class="page-toolbar">
-        <!-- Presentation Mode Toggle -->
-        <button id="presentation-toggle" class="presentation-btn" title="Toggle presentation mode (F key)" aria-label="Toggle presentation mode">
-            <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
-                <rect x="2" y="7" width="20" height="15" rx="2" ry="2"/>
-                <polyline points="17 2 12 7 7 2"/>
-            </svg>
-        </button>
-
-        <!-- Theme Toggle -->
-        <div class="theme-toggle">
-            <button id="theme-light" title="Light theme" aria-label="Light theme">
-                <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
-                    <circle cx="12" cy="12" r="5"/>
-                    <line x1="12" y1="1" x2="12" y2="3"/>
-                    <line x1="12" y1="21" x2="12" y2="23"/>
-                    <line x1="4.22" y1="4.22" x2="5.64" y2="5.64"/>
-                    <line x1="18.36" y1="18.36" x2="19.78" y2="19.78"/>
-                    <line x1="1" y1="12" x2="3" y2="12"/>
-                    <line x1="21" y1="12" x2="23" y2="12"/>
-                    <line x1="4.22" y1="19.78" x2="5.64" y2="18.36"/>
-                    <line x1="18.36" y1="5.64" x2="19.78" y2="4.22"/>
-                </svg>
-            </button>
-            <button id="theme-dark" title="Dark theme" aria-label="Dark theme">
-                <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
-                    <path d="M21 12.79A9 9 0 1 1 11.21 3 7 7 0 0 0 21 12.79z"/>
-                </svg>
-            </button>
-            <button id="theme-auto" title="Auto theme (system preference)" aria-label="Auto theme">
-                <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">
-                    <rect x="2" y="3" width="20" height="14" rx="2" ry="2"/>
-                    <line x1="8" y1="21" x2="16" y2="21"/>
-                    <line x1="12" y1="17" x2="12" y2="21"/>
-                </svg>
-            </button>
-        </div>
-    </div>
-
-    %s
-    %s
-
-    <script>
-        // Theme management
-        (function() {
-            const STORAGE_KEY = 'tinkerdown-theme';
-            const html = document.documentElement;
-
-            // Get current theme from localStorage or default to 'auto'
-            function getStoredTheme() {
-                return localStorage.getItem(STORAGE_KEY) || 'auto';
-            }
-
-            // Get system preference
-            function getSystemTheme() {
-                return window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light';
-            }
+            // Get system preference
+            function getSystemTheme() {
+                return window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light';
+            }
 
             // Apply theme to HTML element
             function applyTheme(theme) {
                 const effectiveTheme = theme === 'auto' ? getSystemTheme() : theme;
+                const changed = html.getAttribute('data-theme') !== effectiveTheme;
                 html.setAttribute('data-theme', effectiveTheme);
 
+                // Let charts and diagrams restyle themselves
+                if (changed) {
+                    document.dispatchEvent(new CustomEvent('themeChanged', { detail: { theme: effectiveTheme } }));
+                }
+
                 // Update button states
                 document.querySelectorAll('.theme-toggle button').forEach(btn => {
                     btn.classList.remove('active');
@@ -2148,6 +1175,35 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
             const storedTheme = getStoredTheme();
             applyTheme(storedTheme);
 
+            // Color presets: the site's preset is on <html> as rendered; a
+            // reader's pick is kept in localStorage ('' means the default colors)
+            const PRESET_KEY = 'tinkerdown-preset';
+            const sitePreset = html.getAttribute('data-preset') || '';
+
+            function applyPreset(preset) {
+                if (preset) {
+                    html.setAttribute('data-preset', preset);
+                } else {
+                    html.removeAttribute('data-preset');
+                }
+                const picker = document.getElementById('theme-preset');
+                if (picker) {
+                    picker.value = preset;
+                }
+            }
+
+            // A stored preset the site no longer offers falls back to the site's
+            function getStoredPreset() {
+                const stored = localStorage.getItem(PRESET_KEY);
+                const picker = document.getElementById('theme-preset');
+                if (stored === null || (picker && !Array.from(picker.options).some(o => o.value === stored))) {
+                    return sitePreset;
+                }
+                return stored;
+            }
+
+            applyPreset(getStoredPreset());
+
             // Listen for system theme changes when in auto mode
             window.matchMedia('(prefers-color-scheme: dark)').addEventListener('change', (e) => {
                 if (getStoredTheme() === 'auto') {
@@ -2160,10 +1216,14 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                 document.getElementById('theme-light').addEventListener('click', () => setTheme('light'));
                 document.getElementById('theme-dark').addEventListener('click', () => setTheme('dark'));
                 document.getElementById('theme-auto').addEventListener('click', () => setTheme('auto'));
+                document.getElementById('theme-preset').addEventListener('change', (e) => {
+                    localStorage.setItem(PRESET_KEY, e.target.value);
+                    applyPreset(e.target.value);
+                });
 
-                // Keyboard shortcut: Ctrl+Shift+D
+                // Keyboard shortcut: theme (default Ctrl+Shift+D)
                 document.addEventListener('keydown', (e) => {
-                    if (e.ctrlKey && e.shiftKey && e.key === 'D') {
+                    if (window.tinkerdownShortcut(e, 'theme')) {
                         e.preventDefault();
                         const current = getStoredTheme();
                         const next = current === 'light' ? 'dark' : current === 'dark' ? 'auto' : 'light';
@@ -2214,6 +1274,39 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
             observer.observe(document.body, { childList: true, subtree: true });
         })();
 
+        // Anchor scroll offset for browsers without scroll-margin-top support
+        (function() {
+            if (window.CSS && CSS.supports && CSS.supports('scroll-margin-top', '1px')) return;
+
+            function offsetPx() {
+                const probe = document.createElement('div');
+                probe.style.cssText = 'position:absolute;visibility:hidden;height:var(--scroll-offset)';
+                document.body.appendChild(probe);
+                const px = probe.offsetHeight;
+                probe.remove();
+                return px;
+            }
+
+            function scrollToHash(hash, smooth) {
+                const target = hash.length > 1 && document.getElementById(decodeURIComponent(hash.slice(1)));
+                if (!target) return false;
+                const top = target.getBoundingClientRect().top + window.scrollY - offsetPx();
+                window.scrollTo({ top: top, behavior: smooth ? 'smooth' : 'auto' });
+                return true;
+            }
+
+            document.addEventListener('click', (e) => {
+                const link = e.target.closest('a[href^="#"]');
+                if (link && scrollToHash(link.getAttribute('href'), true)) {
+                    e.preventDefault();
+                    history.pushState(null, '', link.getAttribute('href'));
+                }
+            });
+            window.addEventListener('load', () => {
+                if (location.hash) scrollToHash(location.hash, false);
+            });
+        })();
+
         // Presentation Mode
         (function() {
             let presentationMode = false;
@@ -2225,24 +1318,58 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                 isActive: () => presentationMode
             };
 
+            // The presenter view (?presenter) shows each slide's speaker notes
+            // and keeps the audience's window on the same slide
+            const params = new URLSearchParams(location.search);
+            const presenterView = params.has('presenter');
+            const channel = 'BroadcastChannel' in window ? new BroadcastChannel('tinkerdown-presentation:' + location.pathname) : null;
+
+            // collectSection gathers the children of parent that lie between
+            // start (the section's H2) and end (the next one, or null) in
+            // document order. Children wholly inside go in elements; children
+            // holding either heading, such as a container the H2 sits in, go
+            // in ancestors and are searched the same way, so wrapped content
+            // stays with its section.
+            function collectSection(parent, start, end, section) {
+                Array.from(parent.children).forEach(child => {
+                    if ((child !== start && child.contains(start)) || (end && child !== end && child.contains(end))) {
+                        section.ancestors.push(child);
+                        collectSection(child, start, end, section);
+                        return;
+                    }
+                    const afterStart = child === start || (start.compareDocumentPosition(child) & Node.DOCUMENT_POSITION_FOLLOWING);
+                    const beforeEnd = !end || (child.compareDocumentPosition(end) & Node.DOCUMENT_POSITION_FOLLOWING);
+                    if (afterStart && beforeEnd) {
+                        section.elements.push(child);
+                    }
+                });
+            }
+
             function getSections() {
-                // Get all H2 elements (tutorial sections)
-                const h2Elements = document.querySelectorAll('.content-wrapper h2');
+                const wrapper = document.querySelector('.content-wrapper');
                 sections = [];
+                if (!wrapper) return sections;
+
+                // Every H2 starts a slide, at any depth, except headings an
+                // interactive block renders itself
+                const h2Elements = Array.from(wrapper.querySelectorAll('h2')).filter(h2 =>
+                    !h2.closest('.tinkerdown-interactive-block, .tinkerdown-wasm-block'));
 
                 h2Elements.forEach((h2, index) => {
                     const section = {
                         heading: h2,
-                        elements: [h2],
+                        elements: [],
+                        ancestors: [],
                         index: index
                     };
+                    collectSection(wrapper, h2, h2Elements[index + 1] || null, section);
 
-                    // Collect all elements until the next H2
-                    let nextElement = h2.nextElementSibling;
-                    while (nextElement && nextElement.tagName !== 'H2') {
-                        section.elements.push(nextElement);
-                        nextElement = nextElement.nextElementSibling;
-                    }
+                    // Speaker notes from <!-- notes: ... --> comments
+                    section.notes = [];
+                    section.elements.forEach(el => {
+                        const templates = el.matches('template.speaker-notes') ? [el] : el.querySelectorAll('template.speaker-notes');
+                        templates.forEach(tpl => section.notes.push(tpl.content.textContent));
+                    });
 
                     sections.push(section);
                 });
@@ -2250,21 +1377,22 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                 return sections;
             }
 
-            function showSection(index) {
+            function showSection(index, fromChannel) {
                 if (sections.length === 0) return;
 
                 currentSectionIndex = Math.max(0, Math.min(index, sections.length - 1));
 
                 // Remove current section class from all
-                document.querySelectorAll('.presentation-current-section').forEach(el => {
-                    el.classList.remove('presentation-current-section');
-                });
+                clearSectionClasses();
 
-                // Add class to current section elements
+                // Add class to current section elements and the containers they're in
                 const section = sections[currentSectionIndex];
                 section.elements.forEach(el => {
                     el.classList.add('presentation-current-section');
                 });
+                section.ancestors.forEach(el => {
+                    el.classList.add('presentation-section-ancestor');
+                });
 
                 // Update step counter in bottom navigation
                 const currentStepEl = document.querySelector('.current-step');
@@ -2284,6 +1412,61 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
 
                 // Scroll to section
                 section.heading.scrollIntoView({ behavior: 'smooth', block: 'start' });
+
+                updatePresenterNotes();
+                if (channel && !fromChannel) {
+                    channel.postMessage({ section: currentSectionIndex });
+                }
+            }
+
+            function clearSectionClasses() {
+                document.querySelectorAll('.presentation-current-section, .presentation-section-ancestor').forEach(el => {
+                    el.classList.remove('presentation-current-section', 'presentation-section-ancestor');
+                });
+            }
+
+            function updatePresenterNotes() {
+                if (!presenterView) return;
+                let panel = document.getElementById('presenter-notes');
+                if (!panel) {
+                    panel = document.createElement('aside');
+                    panel.id = 'presenter-notes';
+                    panel.className = 'presenter-notes';
+                    panel.setAttribute('aria-label', 'Speaker notes');
+                    document.body.appendChild(panel);
+                }
+
+                function line(className, text) {
+                    const p = document.createElement('p');
+                    p.className = className;
+                    p.textContent = text;
+                    panel.appendChild(p);
+                }
+                const section = sections[currentSectionIndex];
+                const next = sections[currentSectionIndex + 1];
+                panel.replaceChildren();
+                line('presenter-meta', 'Slide ' + (currentSectionIndex + 1) + ' of ' + sections.length);
+                if (section.notes.length === 0) {
+                    line('presenter-meta', 'No notes for this slide.');
+                }
+                section.notes.forEach(note => line('presenter-note', note));
+                line('presenter-meta', next ? 'Next: ' + next.heading.textContent : 'Last slide');
+            }
+
+            // Opens the presenter view on the current slide, in a window of its own
+            function openPresenterView() {
+                if (!presentationMode) {
+                    togglePresentationMode();
+                }
+                window.open(location.pathname + '?presenter=' + currentSectionIndex, 'tinkerdown-presenter', 'width=1100,height=700');
+            }
+
+            if (channel) {
+                channel.onmessage = (e) => {
+                    if (presentationMode && e.data && typeof e.data.section === 'number') {
+                        showSection(e.data.section, true);
+                    }
+                };
             }
 
             function togglePresentationMode() {
@@ -2293,6 +1476,13 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                 const sidebarFooter = document.querySelector('.nav-sidebar-footer');
 
                 if (presentationMode) {
+                    // A page without H2 sections has no slides; leave its content alone
+                    if (getSections().length === 0) {
+                        presentationMode = false;
+                        showNoSlidesNotice();
+                        return;
+                    }
+
                     // Enter presentation mode
                     document.body.classList.add('presentation-mode');
                     btn.classList.add('active');
@@ -2302,8 +1492,6 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                         document.body.appendChild(toolbar);
                     }
 
-                    // Get sections and show first one
-                    getSections();
                     showSection(0);
                 } else {
                     // Exit presentation mode
@@ -2316,10 +1504,28 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                     }
 
                     // Remove all presentation classes
-                    document.querySelectorAll('.presentation-current-section').forEach(el => {
-                        el.classList.remove('presentation-current-section');
-                    });
+                    clearSectionClasses();
+
+                    const panel = document.getElementById('presenter-notes');
+                    if (panel) {
+                        panel.remove();
+                    }
+                }
+            }
+
+            function showNoSlidesNotice() {
+                let notice = document.getElementById('presentation-notice');
+                if (!notice) {
+                    notice = document.createElement('div');
+                    notice.id = 'presentation-notice';
+                    notice.className = 'presentation-notice';
+                    notice.setAttribute('role', 'status');
+                    notice.addEventListener('click', () => notice.remove());
+                    document.body.appendChild(notice);
                 }
+                notice.textContent = 'No slides on this page. Presentation mode shows one ## section at a time.';
+                clearTimeout(notice.hideTimer);
+                notice.hideTimer = setTimeout(() => notice.remove(), 3000);
             }
 
             function nextSection() {
@@ -2341,17 +1547,26 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
                     btn.addEventListener('click', togglePresentationMode);
                 }
 
+                if (presenterView) {
+                    document.body.classList.add('presenter-view');
+                    togglePresentationMode();
+                    showSection(parseInt(params.get('presenter'), 10) || 0);
+                }
+
                 // Keyboard shortcuts - use capture phase to intercept before TutorialNavigation
                 document.addEventListener('keydown', (e) => {
-                    // 'f' key to toggle presentation mode
-                    if (e.key === 'f' && !e.ctrlKey && !e.metaKey && !e.altKey) {
-                        // Only if not typing in an input
-                        if (document.activeElement.tagName !== 'INPUT' &&
-                            document.activeElement.tagName !== 'TEXTAREA') {
-                            e.preventDefault();
-                            e.stopImmediatePropagation(); // Prevent other handlers
-                            togglePresentationMode();
-                        }
+                    // Presentation shortcut (default 'f'; ignored while typing in an input)
+                    if (window.tinkerdownShortcut(e, 'presentation')) {
+                        e.preventDefault();
+                        e.stopImmediatePropagation(); // Prevent other handlers
+                        togglePresentationMode();
+                    }
+
+                    // Presenter view shortcut (default 'n')
+                    if (window.tinkerdownShortcut(e, 'presenter')) {
+                        e.preventDefault();
+                        e.stopImmediatePropagation();
+                        openPresenterView();
                     }
 
                     // Arrow keys for navigation in presentation mode
@@ -2397,96 +1612,96 @@ func (s *Server) renderPage(page *tinkerdown.Page, currentPath string, host stri
         })();
     </script>
 
-    <script src="/assets/tinkerdown-client.js"></script>
-
-    <!-- Prism.js for syntax highlighting (embedded) -->
-    <script src="/assets/prism.js"></script>
-    <script src="/assets/prism-go.js"></script>
-    <script src="/assets/prism-javascript.js"></script>
-    <script src="/assets/prism-jsx.js"></script>
-    <script src="/assets/prism-markup.js"></script>
-    <script src="/assets/prism-css.js"></script>
-    <script src="/assets/prism-yaml.js"></script>
-    <script src="/assets/prism-json.js"></script>
-    <script src="/assets/prism-bash.js"></script>
-    <script src="/assets/prism-markdown.js"></script>
-    <script>
-        // Highlight all code blocks on page load
-        document.addEventListener('DOMContentLoaded', function() {
-            Prism.highlightAll();
-        });
-    </script>
+    <script src="%s"></script>
 
-    <!-- Mermaid.js for diagrams (embedded) -->
-    <script src="/assets/mermaid.js"></script>
-    <script>
-        // Initialize Mermaid for diagram rendering
-        mermaid.initialize({
-            startOnLoad: false, // We'll trigger manually after conversion
-            theme: document.documentElement.classList.contains('theme-dark') ? 'dark' : 'default',
-            flowchart: {
-                useMaxWidth: true,
-                htmlLabels: true,
-                curve: 'basis'
-            },
-            sequence: {
-                diagramMarginX: 50,
-                diagramMarginY: 10,
-                actorMargin: 50,
-                width: 150,
-                height: 65,
-                boxMargin: 10,
-                boxTextMargin: 5,
-                noteMargin: 10,
-                messageMargin: 35,
-                mirrorActors: true,
-                useMaxWidth: true
-            }
-        });
+%s
+%s
+%s
+</body>
+</html>`), s.pageLang(currentPath), presetAttr, wsURL, showSidebar, robotsMeta(page)+canonical, page.Title, nonceScripts(shortcutScript), clientCSS.URL(), pageCSS.URL(), prismCSS+scrollOffsetStyle+presetStyle, s.renderLocaleSwitcher(currentPath), presetPicker, sidebar, contentWithNav, clientJS.URL(), nonceScripts(syntaxScripts), nonceScripts(chartScript), nonceScripts(shortcutOverlay+renderBlockPrintScript(content)+renderResumeScript(s.siteKey())+jumpNav))
 
-        // Convert mermaid code blocks to rendered diagrams
-        document.addEventListener('DOMContentLoaded', function() {
-            // Find all code blocks with language-mermaid class
-            const mermaidBlocks = document.querySelectorAll('code.language-mermaid');
+	return html
+}
 
-            mermaidBlocks.forEach(function(codeBlock) {
-                // Get the mermaid code
-                const code = codeBlock.textContent;
+// codeLanguagePattern matches the language class goldmark puts on fenced code blocks.
+var codeLanguagePattern = regexp.MustCompile(`<code class="language-([\w-]+)"`)
+
+// prismLanguageAliases maps fenced code languages to the Prism component that
+// highlights them. markup, css and javascript ship in prism.js itself.
+var prismLanguageAliases = map[string]string{
+	"html":  "markup",
+	"xml":   "markup",
+	"svg":   "markup",
+	"js":    "javascript",
+	"sh":    "bash",
+	"shell": "bash",
+	"yml":   "yaml",
+	"md":    "markdown",
+}
 
-                // Create a new div for the rendered diagram
-                const mermaidDiv = document.createElement('div');
-                mermaidDiv.className = 'mermaid';
-                mermaidDiv.textContent = code;
+// prismDependencies lists components a Prism component needs loaded first.
+var prismDependencies = map[string][]string{
+	"jsx":      {"markup", "javascript"},
+	"markdown": {"markup"},
+}
 
-                // Replace the pre>code structure with just the mermaid div
-                const preBlock = codeBlock.parentElement;
-                preBlock.parentNode.replaceChild(mermaidDiv, preBlock);
-            });
+// prismLoadOrder is the order language components are emitted in, so
+// dependencies always load before the components that extend them.
+var prismLoadOrder = []string{"markup", "css", "javascript", "jsx", "go", "yaml", "json", "bash", "markdown"}
 
-            // Now render all mermaid diagrams
-            mermaid.run();
-        });
+// syntaxAssets describes the highlighting and diagram assets a page needs.
+type syntaxAssets struct {
+	Prism          bool     // page has code blocks (Prism core and theme)
+	PrismLanguages []string // extra language components, in load order
+	Mermaid        bool     // page has mermaid diagrams
+}
 
-        // Re-initialize Mermaid when theme changes
-        document.addEventListener('themeChanged', function(e) {
-            mermaid.initialize({
-                theme: e.detail.theme === 'dark' ? 'dark' : 'default'
-            });
-            // Re-render all diagrams
-            mermaid.run();
-        });
-    </script>
-%s
-</body>
-</html>`, wsURL, showSidebar, page.Title, sidebar, contentWithNav, chartScript)
+// detectSyntaxAssets scans rendered page HTML for fenced code blocks and
+// reports which syntax highlighting and diagram assets the page needs.
+// Languages without a bundled component still get the Prism theme; pages
+// without code blocks need no assets at all.
+func detectSyntaxAssets(html string) syntaxAssets {
+	var sa syntaxAssets
+	needed := make(map[string]bool)
+	for _, m := range codeLanguagePattern.FindAllStringSubmatch(html, -1) {
+		lang := strings.ToLower(m[1])
+		if lang == "mermaid" {
+			sa.Mermaid = true
+			continue
+		}
+		sa.Prism = true
+		if alias, ok := prismLanguageAliases[lang]; ok {
+			lang = alias
+		}
+		if !assets.SupportedPrismLanguages[lang] {
+			continue
+		}
+		needed[lang] = true
+		for _, dep := range prismDependencies[lang] {
+			needed[dep] = true
+		}
+	}
 
-	return html
+	for _, lang := range prismLoadOrder {
+		if needed[lang] {
+			sa.PrismLanguages = append(sa.PrismLanguages, lang)
+		}
+	}
+	return sa
 }
 
 // renderContent renders the page content with code blocks
 func (s *Server) renderContent(page *tinkerdown.Page) string {
 	content := page.StaticHTML
 
+	// Replace {{ site.name }} placeholders from the site config's vars map
+	if s.config != nil {
+		content = tinkerdown.SubstituteSiteVars(content, s.config.Vars)
+	}
+
+	// Blocks bound to sources with options.poll refresh on a client timer
+	content = s.pollAttributes(content, page)
+
 	// TODO: Enhance markdown parser to add data attributes to code blocks
 	// For now, the client will need to discover blocks by parsing the HTML
 	// In Phase 4.5, we'll improve this to inject proper data attributes during parsing
@@ -2520,41 +1735,109 @@ func mdToPattern(relPath string) string {
 	return "/" + path
 }
 
-// sortRoutes sorts routes with index routes first.
-func sortRoutes(routes []*Route) {
-	// Simple sort: / first, then /foo/, then /foo
-	// This is a basic implementation; could be more sophisticated
-	for i := 0; i < len(routes); i++ {
-		for j := i + 1; j < len(routes); j++ {
-			if shouldSwap(routes[i], routes[j]) {
-				routes[i], routes[j] = routes[j], routes[i]
-			}
+// sortRoutes orders routes the way the sidebar lists them, by these keys:
+//
+//  1. path depth;
+//  2. position in the site config's navigation, for routes navOrder has,
+//     which come before those it doesn't;
+//  3. directory;
+//  4. frontmatter nav_order, the fallback for pages the config doesn't
+//     order, with unordered pages after ordered ones;
+//  5. title, then pattern.
+//
+// Index routes sort with their directory, so "/guides/" precedes
+// "/guides/intro".
+func sortRoutes(routes []*Route, navOrder map[string]int) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i].Pattern, routes[j].Pattern
+
+		if da, db := routeDepth(a), routeDepth(b); da != db {
+			return da < db
 		}
-	}
+
+		oa, aOK := navOrder[a]
+		ob, bOK := navOrder[b]
+		if aOK && bOK && oa != ob {
+			return oa < ob
+		}
+		if aOK != bOK {
+			return aOK // pages listed in the nav come first
+		}
+
+		if pa, pb := path.Dir(strings.TrimSuffix(a, "/")), path.Dir(strings.TrimSuffix(b, "/")); pa != pb {
+			return pa < pb
+		}
+
+		oa, aOK = routeNavOrder(routes[i])
+		ob, bOK = routeNavOrder(routes[j])
+		if aOK != bOK {
+			return aOK
+		}
+		if aOK && oa != ob {
+			return oa < ob
+		}
+
+		if ta, tb := strings.ToLower(routeNavTitle(routes[i])), strings.ToLower(routeNavTitle(routes[j])); ta != tb {
+			return ta < tb
+		}
+		return a < b
+	})
 }
 
-func shouldSwap(a, b *Route) bool {
-	// Root path comes first
-	if a.Pattern == "/" {
-		return false
+// routeNavOrder returns the frontmatter nav_order of a route's page, and
+// whether it has one.
+func routeNavOrder(route *Route) (int, bool) {
+	if route.Page == nil || route.Page.NavOrder == nil {
+		return 0, false
 	}
-	if b.Pattern == "/" {
-		return true
+	return *route.Page.NavOrder, true
+}
+
+// routeNavTitle returns the title a route's page has in the sidebar: its
+// nav_title, its title, or else its last path segment as words.
+func routeNavTitle(route *Route) string {
+	if route.Page != nil {
+		if route.Page.NavTitle != "" {
+			return route.Page.NavTitle
+		}
+		if route.Page.Title != "" {
+			return route.Page.Title
+		}
 	}
+	name := path.Base(strings.TrimSuffix(route.Pattern, "/"))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(name)
+}
 
-	// Directory index paths come before other paths
-	aIsIndex := strings.HasSuffix(a.Pattern, "/")
-	bIsIndex := strings.HasSuffix(b.Pattern, "/")
+// routeDepth returns the number of path segments in a route pattern.
+// "/" has depth 0; "/guides/" and "/counter" have depth 1.
+func routeDepth(pattern string) int {
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return 0
+	}
+	return strings.Count(trimmed, "/") + 1
+}
 
-	if aIsIndex && !bIsIndex {
-		return false
+// configNavOrder maps route patterns to their position in the site config's
+// navigation. Returns nil when the config doesn't define a navigation.
+func configNavOrder(cfg *config.Config) map[string]int {
+	if cfg == nil || len(cfg.Navigation) == 0 {
+		return nil
 	}
-	if !aIsIndex && bIsIndex {
-		return true
+	order := make(map[string]int)
+	for _, section := range cfg.Navigation {
+		for _, page := range section.Pages {
+			filePath := page.Path
+			if !strings.HasSuffix(filePath, ".md") {
+				filePath += ".md"
+			}
+			pattern := mdToPattern(filePath)
+			if _, exists := order[pattern]; !exists {
+				order[pattern] = len(order)
+			}
+		}
 	}
-
-	// Alphabetical otherwise
-	return a.Pattern > b.Pattern
+	return order
 }
 
 // RegisterConnection adds a WebSocket connection to the tracked connections.
@@ -2562,7 +1845,29 @@ func (s *Server) RegisterConnection(conn *websocket.Conn, handler *WebSocketHand
 	s.connMu.Lock()
 	defer s.connMu.Unlock()
 	s.connections[conn] = handler
-	log.Printf("[Server] WebSocket connection registered: %d active connections", len(s.connections))
+	metrics.SetWebSocketConnections(len(s.connections))
+	serverLog.Infof("WebSocket connection registered: %d active connections", len(s.connections))
+}
+
+// reserveConnection takes a WebSocket slot, checking server.max_connections
+// and counting the slot under one lock so concurrent upgrades can't exceed
+// it. It reports false when no slot is free; a reserved slot is given back
+// with releaseConnection.
+func (s *Server) reserveConnection() bool {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.config != nil && s.config.Server.MaxConnections > 0 && s.connSlots >= s.config.Server.MaxConnections {
+		return false
+	}
+	s.connSlots++
+	return true
+}
+
+// releaseConnection gives back a slot taken by reserveConnection.
+func (s *Server) releaseConnection() {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	s.connSlots--
 }
 
 // UnregisterConnection removes a WebSocket connection from tracked connections.
@@ -2570,7 +1875,8 @@ func (s *Server) UnregisterConnection(conn *websocket.Conn) {
 	s.connMu.Lock()
 	defer s.connMu.Unlock()
 	delete(s.connections, conn)
-	log.Printf("[Server] WebSocket connection unregistered: %d active connections", len(s.connections))
+	metrics.SetWebSocketConnections(len(s.connections))
+	serverLog.Infof("WebSocket connection unregistered: %d active connections", len(s.connections))
 }
 
 // BroadcastReload sends a reload message to all connected WebSocket clients.
@@ -2589,11 +1895,11 @@ func (s *Server) BroadcastReload(filePath string) {
 
 	data, err := json.Marshal(msg)
 	if err != nil {
-		log.Printf("[Server] Failed to marshal reload message: %v", err)
+		serverLog.Errorf("Failed to marshal reload message: %v", err)
 		return
 	}
 
-	log.Printf("[Server] Broadcasting reload for %s to %d connections", filePath, len(s.connections))
+	serverLog.Infof("Broadcasting reload for %s to %d connections", filePath, len(s.connections))
 
 	for conn, handler := range s.connections {
 		if handler != nil {
@@ -2602,11 +1908,11 @@ func (s *Server) BroadcastReload(filePath string) {
 			err := conn.WriteMessage(websocket.TextMessage, data)
 			handler.writeMu.Unlock()
 			if err != nil {
-				log.Printf("[Server] Failed to send reload to connection: %v", err)
+				serverLog.Errorf("Failed to send reload to connection: %v", err)
 			}
 		} else {
 			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-				log.Printf("[Server] Failed to send reload to connection: %v", err)
+				serverLog.Errorf("Failed to send reload to connection: %v", err)
 			}
 		}
 	}
@@ -2615,7 +1921,7 @@ func (s *Server) BroadcastReload(filePath string) {
 // EnableWatch enables file watching for live reload.
 func (s *Server) EnableWatch(debug bool) error {
 	watcher, err := NewWatcher(s.rootDir, func(filePath string) error {
-		log.Printf("[Watch] File changed: %s", filePath)
+		watchLog.Infof("File changed: %s", filePath)
 
 		// Check if this is a page file or a source file
 		isPageFile := s.isPageFile(filePath)
@@ -2653,7 +1959,7 @@ func (s *Server) EnableWatch(debug bool) error {
 	s.watcher = watcher
 	s.watcher.Start()
 
-	log.Printf("[Watch] File watcher started for %s", s.rootDir)
+	watchLog.Infof("File watcher started for %s", s.rootDir)
 	return nil
 }
 
@@ -2721,7 +2027,7 @@ func (s *Server) RefreshSourcesForFile(filePath string) {
 		return
 	}
 
-	log.Printf("[Server] Refreshing sources for file: %s (%d connections)", filePath, len(s.connections))
+	serverLog.Infof("Refreshing sources for file: %s (%d connections)", filePath, len(s.connections))
 
 	for _, handler := range s.connections {
 		if handler != nil {
@@ -2732,12 +2038,48 @@ func (s *Server) RefreshSourcesForFile(filePath string) {
 
 // StopWatch stops the file watcher if it's running.
 func (s *Server) StopWatch() error {
-	if s.watcher != nil {
-		return s.watcher.Stop()
+	s.mu.Lock()
+	w := s.watcher
+	s.watcher = nil
+	s.mu.Unlock()
+	if w != nil {
+		return w.Stop()
 	}
 	return nil
 }
 
+// CloseConnections sends a close frame to every connected WebSocket client,
+// closes its connection and releases the sources of its blocks.
+func (s *Server) CloseConnections() {
+	s.connMu.Lock()
+	handlers := make(map[*websocket.Conn]*WebSocketHandler, len(s.connections))
+	for conn, h := range s.connections {
+		handlers[conn] = h
+	}
+	s.connMu.Unlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for conn, h := range handlers {
+		if err := conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second)); err != nil {
+			serverLog.Debugf("Failed to send close frame: %v", err)
+		}
+		conn.Close()
+		h.Close()
+	}
+}
+
+// Close releases everything the server holds open outside of HTTP requests:
+// the file watcher, WebSocket connections and the sources they and the REST
+// API use. Call it after the http.Server has shut down.
+func (s *Server) Close() error {
+	err := s.StopWatch()
+	s.CloseConnections()
+	if s.apiHandler != nil {
+		s.apiHandler.Close()
+	}
+	return err
+}
+
 // StartSchedules starts the schedule runner for timed job execution.
 // This should be called after Discover() to ensure all page schedules are registered.
 func (s *Server) StartSchedules(ctx context.Context) error {
@@ -2780,7 +2122,7 @@ func (s *Server) renderSidebar(currentPath string) string {
 		return ""
 	}
 
-	nav := s.siteManager.GetNavigation()
+	nav := s.siteManager.NavigationFor(currentPath)
 	if len(nav) == 0 {
 		return ""
 	}
@@ -2793,10 +2135,40 @@ func (s *Server) renderSidebar(currentPath string) string {
 		html.WriteString(fmt.Sprintf(`<div class="nav-header"><h2>%s</h2></div>`, s.config.Title))
 	}
 
+	// Page filter (revealed by sidebarFilterScript, so it stays hidden without JS)
+	html.WriteString(`<div class="nav-filter" hidden><input type="search" class="nav-filter-input" placeholder="Filter pages (/)" aria-label="Filter pages" autocomplete="off"></div>`)
+
+	// Sections marked collapsed in the config navigation start closed
+	collapsed := make(map[string]bool)
+	for _, ns := range s.config.Navigation {
+		collapsed["/"+ns.Path] = ns.Collapsed
+	}
+
 	// Navigation sections
 	for _, section := range nav {
-		html.WriteString(`<div class="nav-section">`)
-		html.WriteString(fmt.Sprintf(`<div class="nav-section-title">%s</div>`, section.Title))
+		collapsible := s.config.Features.SidebarCollapsible && len(section.Children) > 0
+		if collapsible {
+			current := false
+			for _, page := range section.Children {
+				if page.Path == currentPath {
+					current = true
+				}
+			}
+			open := current || (!collapsed[section.Path] && len(section.Children) <= sidebarOpenSectionSize)
+
+			attrs := fmt.Sprintf(` data-nav-section="%s"`, section.Path)
+			if current {
+				attrs += ` data-nav-current`
+			}
+			if open {
+				attrs += ` open`
+			}
+			html.WriteString(fmt.Sprintf(`<details class="nav-section"%s>`, attrs))
+			html.WriteString(fmt.Sprintf(`<summary class="nav-section-title">%s</summary>`, section.Title))
+		} else {
+			html.WriteString(`<div class="nav-section">`)
+			html.WriteString(fmt.Sprintf(`<div class="nav-section-title">%s</div>`, section.Title))
+		}
 
 		if len(section.Children) > 0 {
 			html.WriteString(`<ul class="nav-pages">`)
@@ -2810,13 +2182,122 @@ func (s *Server) renderSidebar(currentPath string) string {
 			html.WriteString(`</ul>`)
 		}
 
-		html.WriteString(`</div>`)
+		if collapsible {
+			html.WriteString(`</details>`)
+		} else {
+			html.WriteString(`</div>`)
+		}
 	}
 
 	html.WriteString(`</nav>`)
+	html.WriteString(nonceScripts(sidebarFilterScript))
+	if s.config.Features.SidebarCollapsible {
+		html.WriteString(nonceScripts(sidebarCollapseScript))
+	}
 	return html.String()
 }
 
+// sidebarFilterScript live-filters sidebar links by substring. The filter
+// shortcut (default Ctrl+/) focuses it, Escape clears it and Enter opens the
+// first match. Sections with no matching pages are hidden while filtering.
+const sidebarFilterScript = `
+<script>
+    (function() {
+        const sidebar = document.querySelector('.tinkerdown-nav-sidebar');
+        const box = sidebar && sidebar.querySelector('.nav-filter');
+        if (!box) return;
+        const input = box.querySelector('input');
+        box.hidden = false;
+
+        function matches(el, query) {
+            return el.textContent.toLowerCase().includes(query);
+        }
+
+        function applyFilter() {
+            const query = input.value.trim().toLowerCase();
+            sidebar.classList.toggle('nav-filtering', query !== '');
+
+            sidebar.querySelectorAll('.nav-section').forEach(function(section) {
+                const items = section.querySelectorAll('.nav-pages li');
+                let visible = 0;
+                items.forEach(function(li) {
+                    li.hidden = query !== '' && !matches(li, query);
+                    if (!li.hidden) visible++;
+                });
+                if (items.length === 0) {
+                    const title = section.querySelector('.nav-section-title');
+                    visible = query === '' || (title && matches(title, query)) ? 1 : 0;
+                }
+                section.hidden = visible === 0;
+
+                // Expand collapsed sections while filtering, then restore them
+                if (section.tagName === 'DETAILS') {
+                    if (query !== '') {
+                        if (!('filterWasOpen' in section.dataset)) {
+                            section.dataset.filterWasOpen = section.open ? '1' : '';
+                        }
+                        section.open = true;
+                    } else if ('filterWasOpen' in section.dataset) {
+                        section.open = section.dataset.filterWasOpen === '1';
+                        delete section.dataset.filterWasOpen;
+                    }
+                }
+            });
+        }
+
+        input.addEventListener('input', applyFilter);
+        input.addEventListener('keydown', function(e) {
+            if (e.key === 'Escape') {
+                e.preventDefault();
+                input.value = '';
+                applyFilter();
+                input.blur();
+            } else if (e.key === 'Enter') {
+                const first = sidebar.querySelector('.nav-pages li:not([hidden]) a');
+                if (first) {
+                    e.preventDefault();
+                    window.location.href = first.href;
+                }
+            }
+        });
+
+        document.addEventListener('keydown', function(e) {
+            if (!window.tinkerdownShortcut(e, 'filter')) return;
+            e.preventDefault();
+            input.focus();
+        });
+    })();
+</script>`
+
+// sidebarOpenSectionSize is the largest collapsible section that starts open.
+const sidebarOpenSectionSize = 5
+
+// sidebarCollapseScript restores and persists the open/closed state of
+// collapsible sidebar sections. The current page's section always stays open.
+const sidebarCollapseScript = `
+<script>
+    (function() {
+        const STORAGE_KEY = 'tinkerdown-nav-sections';
+        let state = {};
+        try {
+            state = JSON.parse(localStorage.getItem(STORAGE_KEY)) || {};
+        } catch (e) {}
+
+        document.querySelectorAll('details.nav-section[data-nav-section]').forEach(function(section) {
+            const id = section.getAttribute('data-nav-section');
+            if (!section.hasAttribute('data-nav-current') && id in state) {
+                section.open = state[id];
+            }
+            section.addEventListener('toggle', function() {
+                // Sections opened by the page filter aren't user choices
+                if (section.closest('.nav-filtering')) return;
+                state[id] = section.open;
+                localStorage.setItem(STORAGE_KEY, JSON.stringify(state));
+            });
+        });
+    })();
+</script>`
+
 // renderBreadcrumbs renders breadcrumb navigation
 func (s *Server) renderBreadcrumbs(currentPath string) string {
 	if s.siteManager == nil {
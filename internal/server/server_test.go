@@ -2,15 +2,43 @@ package server
 
 import (
 	"bytes"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/metrics"
+	"github.com/livetemplate/tinkerdown/internal/testutil"
+	"github.com/livetemplate/tinkerdown/internal/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+// newTestServer writes files to a temp site and returns a server for it,
+// discovered with the default config after configure (if non-nil) has
+// changed it.
+func newTestServer(t *testing.T, files map[string]string, configure func(*config.Config)) *Server {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	if configure != nil {
+		configure(cfg)
+	}
+	srv := NewWithConfig(testutil.WriteFiles(t, files), cfg)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	return srv
+}
+
 func TestMdToPattern(t *testing.T) {
 	tests := []struct {
 		input string
@@ -171,6 +199,149 @@ func TestServerNotFound(t *testing.T) {
 	}
 }
 
+func TestThemeDetectionUsesDataTheme(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := "# Diagram\n\n```mermaid\ngraph TD\n  A --> B\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	body := w.Body.String()
+
+	// The theme toggle sets data-theme on <html>; there is no theme-dark class
+	if strings.Contains(body, "classList.contains('theme-dark')") {
+		t.Error("theme detection should read the data-theme attribute, not a theme-dark class")
+	}
+	if !strings.Contains(body, "theme: document.documentElement.getAttribute('data-theme') === 'dark'") {
+		t.Error("Mermaid init should read the data-theme attribute")
+	}
+	if !strings.Contains(body, "new CustomEvent('themeChanged'") {
+		t.Error("theme toggle should dispatch a themeChanged event")
+	}
+	if !strings.Contains(body, "removeAttribute('data-processed')") {
+		t.Error("themeChanged handler should reset processed Mermaid diagrams before re-rendering")
+	}
+}
+
+func TestRenderPageSyntaxAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"text.md":    "# Text\n\nJust prose with `inline code`.\n",
+		"code.md":    "# Code\n\n```go\npackage main\n```\n\n```tsx\nconst a = 1\n```\n",
+		"jsx.md":     "# JSX\n\n```jsx\n<App />\n```\n",
+		"diagram.md": "# Diagram\n\n```mermaid\ngraph TD\n  A --> B\n```\n",
+		"lines.md":   "# Lines\n\n```go {2}\na := 1\nb := 2\n```\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{
+			path:    "/text",
+			notWant: []string{"/assets/prism.css", "/assets/prism.js", "/assets/prism-", "/assets/mermaid.js"},
+		},
+		{
+			path:    "/code",
+			want:    []string{"/assets/prism.css", "/assets/prism.js", "/assets/prism-go.js", "Prism.highlightAll()"},
+			notWant: []string{"/assets/prism-yaml.js", "/assets/prism-tsx.js", "/assets/mermaid.js", "Prism.hooks.add('after-highlight'"},
+		},
+		{
+			path: "/lines",
+			want: []string{`<pre class="code-lines" data-line="2">`, "Prism.hooks.add('after-highlight'"},
+		},
+		{
+			path: "/jsx",
+			want: []string{"/assets/prism-markup.js", "/assets/prism-javascript.js", "/assets/prism-jsx.js"},
+		},
+		{
+			path:    "/diagram",
+			want:    []string{"/assets/mermaid.js", "mermaid.run()"},
+			notWant: []string{"/assets/prism.js"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			body := w.Body.String()
+
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("page %s should include %q", tt.path, s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(body, s) {
+					t.Errorf("page %s should not include %q", tt.path, s)
+				}
+			}
+		})
+	}
+
+	// Dependencies load before the components that extend them
+	req := httptest.NewRequest("GET", "/jsx", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	body := w.Body.String()
+	if strings.Index(body, "/assets/prism-markup.js") > strings.Index(body, "/assets/prism-jsx.js") {
+		t.Error("prism-markup.js should load before prism-jsx.js")
+	}
+}
+
+func TestRenderPageNoExternalAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "# Everything\n\n```go\npackage main\n```\n\n```mermaid\ngraph TD\n  A --> B\n```\n\n" +
+		"## Sales {chart:bar}\n\n| Region | Q1 |\n|--------|----|\n| North | 10 |\n| South | 20 |\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	for _, asset := range []string{"/assets/prism.js", "/assets/mermaid.js", "/assets/chart.js", "/assets/pico.css"} {
+		if !strings.Contains(body, asset) {
+			t.Errorf("page should load %s", asset)
+		}
+	}
+
+	// Scripts, stylesheets and fonts all come from the server, so pages
+	// work offline and don't reach third parties
+	external := regexp.MustCompile(`(?:src|href)\s*=\s*["'](?:https?:)?//|url\(\s*["']?(?:https?:)?//|@import`)
+	if match := external.FindString(body); match != "" {
+		i := strings.Index(body, match)
+		t.Errorf("page loads an external resource: %.120s", body[i:])
+	}
+}
+
 func TestSortRoutes(t *testing.T) {
 	routes := []*Route{
 		{Pattern: "/counter"},
@@ -180,7 +351,7 @@ func TestSortRoutes(t *testing.T) {
 		{Pattern: "/advanced"},
 	}
 
-	sortRoutes(routes)
+	sortRoutes(routes, nil)
 
 	// Root should be first
 	if routes[0].Pattern != "/" {
@@ -210,6 +381,348 @@ func TestSortRoutes(t *testing.T) {
 	}
 }
 
+func TestSortRoutesHierarchy(t *testing.T) {
+	patterns := []string{
+		"/tutorials/advanced/state",
+		"/zeta",
+		"/guides/styling",
+		"/tutorials/intro",
+		"/",
+		"/guides/",
+		"/about",
+		"/tutorials/advanced/",
+		"/guides/data-sources",
+		"/tutorials/",
+		"/tutorials/advanced/actions",
+		"/changelog",
+	}
+	newRoutes := func() []*Route {
+		routes := make([]*Route, len(patterns))
+		for i, p := range patterns {
+			routes[i] = &Route{Pattern: p}
+		}
+		return routes
+	}
+	got := func(routes []*Route) []string {
+		out := make([]string, len(routes))
+		for i, r := range routes {
+			out[i] = r.Pattern
+		}
+		return out
+	}
+
+	t.Run("depth then alphabetical", func(t *testing.T) {
+		routes := newRoutes()
+		sortRoutes(routes, nil)
+		want := []string{
+			"/",
+			"/about",
+			"/changelog",
+			"/guides/",
+			"/tutorials/",
+			"/zeta",
+			"/guides/data-sources",
+			"/guides/styling",
+			"/tutorials/advanced/",
+			"/tutorials/intro",
+			"/tutorials/advanced/actions",
+			"/tutorials/advanced/state",
+		}
+		if strings.Join(got(routes), " ") != strings.Join(want, " ") {
+			t.Errorf("sortRoutes() =\n%v\nwant\n%v", got(routes), want)
+		}
+	})
+
+	t.Run("nav order within a depth", func(t *testing.T) {
+		cfg := &config.Config{Navigation: []config.NavSection{
+			{Title: "Guides", Pages: []config.NavPage{
+				{Path: "guides/styling.md"},
+				{Path: "guides/data-sources"},
+			}},
+			{Title: "Meta", Pages: []config.NavPage{
+				{Path: "zeta.md"},
+				{Path: "about.md"},
+			}},
+		}}
+		routes := newRoutes()
+		sortRoutes(routes, configNavOrder(cfg))
+		want := []string{
+			"/",
+			"/zeta",
+			"/about",
+			"/changelog",
+			"/guides/",
+			"/tutorials/",
+			"/guides/styling",
+			"/guides/data-sources",
+			"/tutorials/advanced/",
+			"/tutorials/intro",
+			"/tutorials/advanced/actions",
+			"/tutorials/advanced/state",
+		}
+		if strings.Join(got(routes), " ") != strings.Join(want, " ") {
+			t.Errorf("sortRoutes() =\n%v\nwant\n%v", got(routes), want)
+		}
+	})
+
+	t.Run("frontmatter nav order then title", func(t *testing.T) {
+		order := func(n int) *int { return &n }
+		routes := []*Route{
+			{Pattern: "/alpha", Page: &tinkerdown.Page{Title: "Zulu"}},
+			{Pattern: "/beta", Page: &tinkerdown.Page{Title: "Yankee", NavOrder: order(2)}},
+			{Pattern: "/gamma", Page: &tinkerdown.Page{Title: "Alpha"}},
+			{Pattern: "/delta", Page: &tinkerdown.Page{Title: "Xray", NavOrder: order(1)}},
+			{Pattern: "/", Page: &tinkerdown.Page{Title: "Home"}},
+		}
+		sortRoutes(routes, nil)
+		want := []string{"/", "/delta", "/beta", "/gamma", "/alpha"}
+		if strings.Join(got(routes), " ") != strings.Join(want, " ") {
+			t.Errorf("sortRoutes() =\n%v\nwant\n%v", got(routes), want)
+		}
+	})
+
+	t.Run("config nav order before frontmatter nav order", func(t *testing.T) {
+		order := func(n int) *int { return &n }
+		routes := []*Route{
+			{Pattern: "/alpha", Page: &tinkerdown.Page{Title: "Alpha", NavOrder: order(1)}},
+			{Pattern: "/beta", Page: &tinkerdown.Page{Title: "Beta"}},
+			{Pattern: "/gamma", Page: &tinkerdown.Page{Title: "Gamma", NavOrder: order(3)}},
+			{Pattern: "/delta", Page: &tinkerdown.Page{Title: "Delta", NavOrder: order(2)}},
+		}
+		// The config lists gamma and beta; nav_order only orders the rest
+		sortRoutes(routes, map[string]int{"/gamma": 0, "/beta": 1})
+		want := []string{"/gamma", "/beta", "/alpha", "/delta"}
+		if strings.Join(got(routes), " ") != strings.Join(want, " ") {
+			t.Errorf("sortRoutes() =\n%v\nwant\n%v", got(routes), want)
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		first := newRoutes()
+		sortRoutes(first, nil)
+		for i := 0; i < 10; i++ {
+			routes := newRoutes()
+			// Rotate the input so each run starts from a different order
+			routes = append(routes[i%len(routes):], routes[:i%len(routes)]...)
+			sortRoutes(routes, nil)
+			if strings.Join(got(routes), " ") != strings.Join(got(first), " ") {
+				t.Fatalf("run %d: sortRoutes() = %v, want %v", i, got(routes), got(first))
+			}
+		}
+	})
+}
+
+func TestRenderSidebarCollapsible(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"index.md":           "# Home",
+		"guides/intro.md":    "---\ntitle: Intro\n---\n# Intro",
+		"guides/setup.md":    "---\ntitle: Setup\n---\n# Setup",
+		"reference/a.md":     "# A",
+		"reference/b.md":     "# B",
+		"reference/c.md":     "# C",
+		"reference/d.md":     "# D",
+		"reference/e.md":     "# E",
+		"reference/f.md":     "# F",
+		"reference/index.md": "# Reference",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	newServer := func(collapsible bool) *Server {
+		cfg := config.DefaultConfig()
+		cfg.Type = "site"
+		cfg.Features.SidebarCollapsible = collapsible
+		srv := NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		return srv
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		sidebar := newServer(false).renderSidebar("/guides/intro")
+		if strings.Contains(sidebar, "<details") || strings.Contains(sidebar, "tinkerdown-nav-sections") {
+			t.Errorf("sidebar should not be collapsible unless enabled:\n%s", sidebar)
+		}
+		if !strings.Contains(sidebar, `<div class="nav-section-title">Guides</div>`) {
+			t.Errorf("sidebar missing plain section title:\n%s", sidebar)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		srv := newServer(true)
+
+		sidebar := srv.renderSidebar("/reference/a")
+		for _, want := range []string{
+			// Small sections default open
+			`<details class="nav-section" data-nav-section="/guides" open><summary class="nav-section-title">Guides</summary>`,
+			// Large sections open when they contain the current page
+			`<details class="nav-section" data-nav-section="/reference" data-nav-current open>`,
+			"localStorage.setItem(STORAGE_KEY",
+		} {
+			if !strings.Contains(sidebar, want) {
+				t.Errorf("sidebar missing %q:\n%s", want, sidebar)
+			}
+		}
+
+		// Large sections start closed elsewhere
+		sidebar = srv.renderSidebar("/guides/intro")
+		if !strings.Contains(sidebar, `<details class="nav-section" data-nav-section="/reference">`) {
+			t.Errorf("large section should start closed:\n%s", sidebar)
+		}
+	})
+}
+
+func TestRenderSidebarFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	for path, content := range map[string]string{
+		"index.md":        "# Home",
+		"guides/intro.md": "# Intro",
+	} {
+		fullPath := filepath.Join(tmpDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Type = "site"
+	cfg.Features.Sidebar = true
+	srv := NewWithConfig(tmpDir, cfg)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/guides/intro", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	body := w.Body.String()
+
+	for _, want := range []string{
+		// Hidden until the script reveals it, so it never shows without JS
+		`<div class="nav-filter" hidden><input type="search" class="nav-filter-input"`,
+		"box.hidden = false;",
+		"if (!window.tinkerdownShortcut(e, 'filter')) return;",
+		"if (e.key === 'Escape') {",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if !hasCSS(pageStylesheet(t, srv), "width: 100%;") {
+		t.Error("stylesheet should size the filter input")
+	}
+}
+
+func TestRenderPageShortcuts(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	render := func(shortcuts map[string]string) string {
+		cfg := config.DefaultConfig()
+		cfg.Shortcuts = shortcuts
+		srv := NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		body := render(nil)
+		for _, want := range []string{
+			`const keymap = {"filter":"Ctrl+/","help":"?","presentation":"f","presenter":"n","search":"/","theme":"Ctrl+Shift+D"};`,
+			`<div id="tinkerdown-shortcuts" class="shortcuts-overlay" hidden>`,
+			"<tr><td><kbd>Ctrl</kbd>+<kbd>Shift</kbd>+<kbd>D</kbd></td><td>Cycle theme (light, dark, auto)</td></tr>",
+			"window.tinkerdownShortcut(e, 'theme')",
+			"window.tinkerdownShortcut(e, 'presentation')",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("page missing %q", want)
+			}
+		}
+		// Handlers read the keymap instead of hardcoding keys
+		for _, unwanted := range []string{"e.key === 'D'", "e.key === 'f'"} {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("page should not hardcode %q", unwanted)
+			}
+		}
+	})
+
+	t.Run("remapped and disabled", func(t *testing.T) {
+		body := render(map[string]string{"presentation": "p", "help": "none"})
+		if !strings.Contains(body, `const keymap = {"filter":"Ctrl+/","presentation":"p","presenter":"n","search":"/","theme":"Ctrl+Shift+D"};`) {
+			t.Error("keymap should reflect the shortcuts config")
+		}
+		if strings.Contains(body, `id="tinkerdown-shortcuts"`) {
+			t.Error("overlay should not render when the help shortcut is disabled")
+		}
+	})
+}
+
+func TestRenderPageScrollOffset(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n\n## Section"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	var srv *Server
+	render := func(offset string) string {
+		cfg := config.DefaultConfig()
+		cfg.Styling.ScrollOffset = offset
+		srv = NewWithConfig(tmpDir, cfg)
+		if err := srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	body := render("")
+	if !strings.Contains(body, "CSS.supports('scroll-margin-top', '1px')") {
+		t.Error("page missing the scroll-margin-top fallback")
+	}
+	css := pageStylesheet(t, srv)
+	for _, want := range []string{
+		"--scroll-offset: 4.5rem;",
+		"scroll-margin-top: var(--scroll-offset);",
+		"scroll-behavior: smooth;",
+	} {
+		if !hasCSS(css, want) {
+			t.Errorf("stylesheet missing %q", want)
+		}
+	}
+	if strings.Contains(body, "<style>:root { --scroll-offset:") {
+		t.Error("default page should not override --scroll-offset")
+	}
+
+	if body := render("96"); !strings.Contains(body, "<style>:root { --scroll-offset: 96px; }</style>") {
+		t.Error("scroll_offset should override --scroll-offset")
+	}
+	if body := render("1rem}</style><script>"); strings.Contains(body, "<style>:root { --scroll-offset:") {
+		t.Error("invalid scroll_offset should be ignored")
+	}
+}
+
 func TestWebSocketURLContainsPage(t *testing.T) {
 	// Create temp directory with multiple test pages
 	tmpDir := t.TempDir()
@@ -466,3 +979,142 @@ func TestServeWebSocketEmptyRoutes(t *testing.T) {
 		t.Errorf("Expected 'No pages available' error, got: %s", body)
 	}
 }
+
+func TestServePageTracing(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tracing.Enable(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer tracing.Disable()
+
+	for i := 0; i < 2; i++ {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+
+	var serves, renders []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "servePage":
+			serves = append(serves, span)
+		case "renderPage":
+			renders = append(renders, span)
+		}
+	}
+	if len(serves) != 2 || len(renders) != 2 {
+		t.Fatalf("got %d servePage and %d renderPage spans, want one of each per request", len(serves), len(renders))
+	}
+	if renders[0].Parent().SpanID() != serves[0].SpanContext().SpanID() {
+		t.Error("renderPage should be a child of servePage")
+	}
+	var route string
+	for _, attr := range serves[0].Attributes() {
+		if attr.Key == tracing.AttrRoute {
+			route = attr.Value.AsString()
+		}
+	}
+	if route != "/" {
+		t.Errorf("servePage route = %q, want /", route)
+	}
+}
+
+func TestMetricsCountPageRequests(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	metrics.Enable()
+	defer metrics.Disable()
+
+	// requests returns the count of successful requests for the home page
+	requests := func() int {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", metrics.Path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want 200", metrics.Path, rec.Code)
+		}
+		match := regexp.MustCompile(`tinkerdown_http_requests_total\{code="200",route="/"\} (\d+)`).FindStringSubmatch(rec.Body.String())
+		if match == nil {
+			return 0
+		}
+		n, _ := strconv.Atoi(match[1])
+		return n
+	}
+
+	before := requests()
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got := requests(); got != before+1 {
+		t.Errorf("home page requests = %d, want %d", got, before+1)
+	}
+
+	// Unknown paths share one label
+	if got := srv.metricsRoute("/no/such/page"); got != "other" {
+		t.Errorf("metricsRoute() of an unknown path = %q, want other", got)
+	}
+	if got := srv.metricsRoute("/api/sources/tasks"); got != "/api/sources/" {
+		t.Errorf("metricsRoute() of an API path = %q, want its prefix", got)
+	}
+}
+
+// Pages are sent without waiting for their sources: each block is a
+// placeholder until its data arrives over the WebSocket.
+func TestServePageDoesNotWaitForSources(t *testing.T) {
+	const delay = time.Second
+	ts := newSlowSourcesServer(t, 3, delay)
+
+	start := time.Now()
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= delay/2 {
+		t.Errorf("page took %s, want it sent before its %s sources load", elapsed, delay)
+	}
+	if n := strings.Count(string(body), "data-interactive-content"); n != 3 {
+		t.Errorf("page has %d block placeholders, want 3", n)
+	}
+}
+
+func TestMarkdownOptionsPerServer(t *testing.T) {
+	newServer := func(strikethrough bool) *Server {
+		tmpDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n\n~~old~~ text\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.DefaultConfig()
+		cfg.Markdown.Strikethrough = &strikethrough
+		return NewWithConfig(tmpDir, cfg)
+	}
+
+	// Creating the second server must not change how the first parses
+	plain := newServer(false)
+	gfm := newServer(true)
+	for _, tt := range []struct {
+		srv  *Server
+		want bool
+	}{{plain, false}, {gfm, true}} {
+		if err := tt.srv.Discover(); err != nil {
+			t.Fatalf("Discover() error: %v", err)
+		}
+		html := tt.srv.Routes()[0].Page.StaticHTML
+		if got := strings.Contains(html, "<del>old</del>"); got != tt.want {
+			t.Errorf("strikethrough %v: rendered <del> = %v:\n%s", tt.want, got, html)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// shortcutLabels lists the configurable shortcut actions in display order.
+var shortcutLabels = []struct {
+	action string
+	label  string
+}{
+	{"help", "Show keyboard shortcuts"},
+	{"theme", "Cycle theme (light, dark, auto)"},
+	{"presentation", "Toggle presentation mode"},
+	{"presenter", "Open the presenter view with speaker notes"},
+	{"search", "Search all pages (also Ctrl+K or ⌘K)"},
+	{"filter", "Filter sidebar pages"},
+}
+
+// renderShortcutScript returns the <head> script that exposes the keymap and
+// window.tinkerdownShortcut(event, action), which the page's keyboard
+// handlers use instead of hardcoding keys. Shortcuts without Ctrl, Alt or
+// Meta never fire while the user is typing in a form field.
+func renderShortcutScript(keymap map[string]string) string {
+	data, err := json.Marshal(keymap)
+	if err != nil {
+		data = []byte("{}")
+	}
+
+	return fmt.Sprintf(`
+    <script>
+        (function() {
+            const keymap = %s;
+
+            window.tinkerdownShortcuts = keymap;
+            window.tinkerdownShortcut = function(e, action) {
+                const combo = keymap[action];
+                if (!combo) return false;
+
+                const parts = combo.split('+');
+                const key = parts.pop();
+                const mods = parts.map(function(p) { return p.toLowerCase(); });
+                const ctrl = mods.includes('ctrl');
+                const alt = mods.includes('alt');
+                const meta = mods.includes('meta') || mods.includes('cmd');
+                const shift = mods.includes('shift');
+                if (e.ctrlKey !== ctrl || e.altKey !== alt || e.metaKey !== meta) return false;
+
+                // Symbols like "?" imply Shift, so only letters must match it exactly
+                if (/^[a-z]$/i.test(key) ? e.shiftKey !== shift : (shift && !e.shiftKey)) return false;
+                if (e.key.toLowerCase() !== key.toLowerCase()) return false;
+
+                if (!ctrl && !alt && !meta) {
+                    const el = document.activeElement;
+                    if (el && (el.tagName === 'INPUT' || el.tagName === 'TEXTAREA' ||
+                        el.tagName === 'SELECT' || el.isContentEditable)) return false;
+                }
+                return true;
+            };
+        })();
+    </script>`, data)
+}
+
+// renderShortcutOverlay returns the keyboard shortcuts dialog and the script
+// that opens it with the "help" shortcut. Returns "" when help is disabled.
+func renderShortcutOverlay(keymap map[string]string) string {
+	if keymap["help"] == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`
+    <div id="tinkerdown-shortcuts" class="shortcuts-overlay" hidden>
+        <div class="shortcuts-dialog" role="dialog" aria-modal="true" aria-labelledby="tinkerdown-shortcuts-title">
+            <h2 id="tinkerdown-shortcuts-title">Keyboard shortcuts</h2>
+            <table class="shortcuts-table"><tbody>`)
+	for _, sc := range shortcutLabels {
+		key, ok := keymap[sc.action]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\n                <tr><td>%s</td><td>%s</td></tr>", formatShortcutKeys(key), sc.label)
+	}
+	b.WriteString(`
+                <tr><td><kbd>←</kbd> <kbd>→</kbd></td><td>Previous/next section (presentation mode)</td></tr>
+                <tr><td><kbd>Esc</kbd></td><td>Close this dialog or exit presentation mode</td></tr>
+            </tbody></table>
+            <button type="button" class="shortcuts-close">Close</button>
+        </div>
+    </div>
+    <script>
+        (function() {
+            const overlay = document.getElementById('tinkerdown-shortcuts');
+            if (!overlay) return;
+            const closeBtn = overlay.querySelector('.shortcuts-close');
+            let lastFocus = null;
+
+            function open() {
+                lastFocus = document.activeElement;
+                overlay.hidden = false;
+                closeBtn.focus();
+            }
+
+            function close() {
+                overlay.hidden = true;
+                if (lastFocus && lastFocus.focus) lastFocus.focus();
+            }
+
+            // Capture phase so Escape closes the dialog before other handlers see it
+            document.addEventListener('keydown', function(e) {
+                if (!overlay.hidden && e.key === 'Escape') {
+                    e.preventDefault();
+                    e.stopImmediatePropagation();
+                    close();
+                } else if (window.tinkerdownShortcut(e, 'help')) {
+                    e.preventDefault();
+                    overlay.hidden ? open() : close();
+                }
+            }, true);
+
+            overlay.addEventListener('click', function(e) {
+                if (e.target === overlay) close();
+            });
+            closeBtn.addEventListener('click', close);
+        })();
+    </script>`)
+	return b.String()
+}
+
+// formatShortcutKeys renders a key combo like "Ctrl+Shift+D" as <kbd> elements.
+func formatShortcutKeys(combo string) string {
+	parts := strings.Split(combo, "+")
+	for i, p := range parts {
+		parts[i] = "<kbd>" + html.EscapeString(p) + "</kbd>"
+	}
+	return strings.Join(parts, "+")
+}
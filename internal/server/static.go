@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// staticFileExts are the extensions of files under the site root that are
+// served: images, fonts, media, stylesheets, scripts and downloads. Anything
+// else, such as page sources, data files, databases and config, is not.
+var staticFileExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+	".avif": true, ".svg": true, ".ico": true, ".bmp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true,
+	".mp3": true, ".m4a": true, ".wav": true, ".ogg": true, ".oga": true,
+	".mp4": true, ".ogv": true, ".webm": true, ".mov": true,
+	".css": true, ".js": true, ".mjs": true, ".map": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tgz": true, ".tar": true,
+}
+
+// serveStaticFile serves the file at the request path under the site root,
+// such as an image or download referenced from a page, and reports whether
+// it did. Only files with an extension in staticFileExts are served, and
+// never ones in "_" or "." directories, ones read by a configured source or
+// anything resolving outside the root.
+func (s *Server) serveStaticFile(w http.ResponseWriter, r *http.Request) bool {
+	rel := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	sourceFiles := s.sourceFiles()
+	if !isStaticPath(rel, sourceFiles) {
+		return false
+	}
+
+	// Resolve symlinks so a link can't expose files outside the root, or
+	// ones inside it that aren't served
+	root, err := filepath.EvalSymlinks(s.rootDir)
+	if err != nil {
+		return false
+	}
+	full, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil || !strings.HasPrefix(full, root+string(filepath.Separator)) {
+		return false
+	}
+	if target, err := filepath.Rel(root, full); err != nil || !isStaticPath(filepath.ToSlash(target), sourceFiles) {
+		return false
+	}
+
+	f, err := os.Open(full)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	cacheControl := cachePage
+	if s.watching() {
+		cacheControl = cacheRevalidate
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	return true
+}
+
+// isStaticPath reports whether the file at root-relative path rel may be
+// served as a static file.
+func isStaticPath(rel string, sourceFiles map[string]bool) bool {
+	if rel == "" || isHiddenPath(rel) || sourceFiles[rel] {
+		return false
+	}
+	return staticFileExts[strings.ToLower(path.Ext(rel))]
+}
+
+// sourceFiles returns the root-relative paths of the files the site's and
+// its pages' sources read: data files, databases, query files and the
+// relative paths in exec commands.
+func (s *Server) sourceFiles() map[string]bool {
+	var sources []config.SourceConfig
+	if s.config != nil {
+		for _, src := range s.config.Sources {
+			sources = append(sources, src)
+		}
+	}
+	s.mu.RLock()
+	for _, route := range s.routes {
+		if route.Page == nil {
+			continue
+		}
+		for _, src := range route.Page.Config.Sources {
+			sources = append(sources, pageSourceConfig(src))
+		}
+	}
+	s.mu.RUnlock()
+
+	files := make(map[string]bool)
+	add := func(dir, p string) {
+		if p == "" || filepath.IsAbs(p) || strings.HasPrefix(p, "-") {
+			return
+		}
+		files[path.Join(filepath.ToSlash(dir), filepath.ToSlash(p))] = true
+	}
+	for _, src := range sources {
+		for _, p := range []string{src.File, src.DB, src.Path, src.QueryFile} {
+			add("", p)
+		}
+		for _, field := range strings.Fields(src.Cmd) {
+			add("", field)
+			add(src.Options["cwd"], field)
+		}
+	}
+	return files
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestServeStaticFiles(t *testing.T) {
+	baseDir := t.TempDir()
+	rootDir := filepath.Join(baseDir, "site")
+	files := map[string]string{
+		"index.md":              "---\nsources:\n  list:\n    type: exec\n    cmd: ./bin/list.js --all\n---\n# Home\n\n![Shot](screenshots/x.png)\n",
+		"screenshots/x.png":     "\x89PNG\r\n\x1a\nfake",
+		"downloads/sample.zip":  "PK zip",
+		"static/theme.css":      "body {}",
+		"_private/notes.txt":    "hidden",
+		".env":                  "SECRET=1",
+		"tasks.db":              "sqlite",
+		"tasks.db-wal":          "sqlite wal",
+		"tasks.db-journal":      "sqlite journal",
+		"tasks.db-shm":          "sqlite shm",
+		"data/items.csv":        "name\nsecret",
+		"scripts/stats.js":      "exec source script",
+		"bin/list.js":           "page exec source script",
+		"tinkerdown.yaml":       "title: Site\n",
+		"TinkerDown.YAML":       "title: Site\n",
+		"site.yaml":             "title: Site from --config\n",
+		"notes.txt":             "not a static asset",
+		"../secret.txt":         "outside the root",
+		"../outside/linked.txt": "outside via symlink",
+	}
+	for name, content := range files {
+		path := filepath.Join(rootDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(baseDir, "outside"), filepath.Join(rootDir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(rootDir, "tasks.db"), filepath.Join(rootDir, "tasks.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Sources = map[string]config.SourceConfig{
+		"items": {Type: "csv", File: "data/items.csv"},
+		"tasks": {Type: "sqlite", DB: "tasks.db", Table: "tasks"},
+		"stats": {Type: "exec", Cmd: "node stats.js", Options: map[string]string{"cwd": "scripts"}},
+	}
+	srv := NewWithConfig(rootDir, cfg)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/screenshots/x.png")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("GET image = %d (%s), want 200 image/png", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !bytes.Equal(rec.Body.Bytes(), []byte(files["screenshots/x.png"])) {
+		t.Errorf("GET image body = %q", rec.Body.String())
+	}
+	if rec := get("/static/theme.css"); rec.Code != http.StatusOK {
+		t.Errorf("GET stylesheet = %d, want 200", rec.Code)
+	}
+	if rec := get("/downloads/sample.zip"); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Errorf("GET zip = %d (%s), want 200 application/zip", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	// Pages still win over files
+	if rec := get("/"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<h1") {
+		t.Errorf("GET / = %d, want the home page", rec.Code)
+	}
+
+	for _, path := range []string{
+		"/../secret.txt",
+		"/../../etc/passwd",
+		"/linked/linked.txt",
+		"/_private/notes.txt",
+		"/.env",
+		"/index.md",
+		"/tasks.db",
+		"/tasks.db-wal", // SQLite sidecars
+		"/tasks.db-journal",
+		"/tasks.db-shm",
+		"/tasks.png",        // Symlink to the database
+		"/data/items.csv",   // Source data file
+		"/scripts/stats.js", // Site exec source script, under its cwd
+		"/bin/list.js",      // Page exec source script
+		"/tinkerdown.yaml",
+		"/TinkerDown.YAML",
+		"/site.yaml", // A config passed with --config
+		"/notes.txt",
+		"/screenshots",
+	} {
+		rec := get(path)
+		if rec.Code == http.StatusOK {
+			t.Errorf("GET %s = 200 %q, want it not served", path, rec.Body.String())
+		}
+	}
+}
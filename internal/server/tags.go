@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown"
+	"github.com/livetemplate/tinkerdown/internal/site"
+)
+
+// tagsPath is the index of a site's tags; each tag's pages are listed under
+// it at /tags/<slug>. Pages of the site at these paths take precedence.
+const tagsPath = "/tags/"
+
+// serveTagPage serves the tag index or a tag's page for site mode, and
+// reports whether the request was for one. Unknown tags are left to the
+// not-found handling.
+func (s *Server) serveTagPage(w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.Path == strings.TrimSuffix(tagsPath, "/") {
+		redirectCanonical(w, r, tagsPath)
+		return true
+	}
+	slug, ok := strings.CutPrefix(r.URL.Path, tagsPath)
+	if !ok || strings.Contains(slug, "/") {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var page *tinkerdown.Page
+	if slug == "" {
+		page = tagIndexPage(s.siteManager.Tags())
+	} else {
+		tag, ok := s.siteManager.Tag(slug)
+		if !ok {
+			return false
+		}
+		page = tagPage(tag)
+	}
+	body := s.applyCSPNonce(w, s.renderPage(r.Context(), page, r.URL.Path, r.Host))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(body))
+	return true
+}
+
+// tagIndexPage lists every tag with the number of pages carrying it.
+func tagIndexPage(tags []site.Tag) *tinkerdown.Page {
+	var b strings.Builder
+	b.WriteString("<h1>Tags</h1>\n")
+	if len(tags) == 0 {
+		b.WriteString("<p>No pages are tagged yet.</p>\n")
+	} else {
+		b.WriteString(`<ul class="tag-index">`)
+		for _, tag := range tags {
+			fmt.Fprintf(&b, `<li><a href="%s" class="tag-chip">%s</a> <span class="tag-count">%d</span></li>`,
+				tagPath(tag.Slug), html.EscapeString(tag.Name), len(tag.Pages))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	page := tinkerdown.New("tags")
+	page.Title = "Tags"
+	page.StaticHTML = b.String()
+	return page
+}
+
+// tagPage lists the pages carrying tag.
+func tagPage(tag site.Tag) *tinkerdown.Page {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h1>Tagged “%s”</h1>\n", html.EscapeString(tag.Name))
+	b.WriteString(`<ul class="tag-pages">`)
+	for _, node := range tag.Pages {
+		fmt.Fprintf(&b, `<li><a href="%s">%s</a></li>`, html.EscapeString(node.Path), html.EscapeString(node.Title))
+	}
+	b.WriteString("</ul>\n")
+	fmt.Fprintf(&b, `<p><a href="%s">All tags</a></p>`+"\n", tagsPath)
+
+	page := tinkerdown.New("tags-" + tag.Slug)
+	page.Title = "Tagged “" + tag.Name + "”"
+	page.StaticHTML = b.String()
+	return page
+}
+
+// tagPath returns the URL of the page listing a tag's pages.
+func tagPath(slug string) string {
+	return tagsPath + slug
+}
+
+// tagChipsHTML renders a page's tags as links to their tag pages, for under
+// its title.
+func tagChipsHTML(tags []string) string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, name := range tags {
+		slug := site.TagSlug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		fmt.Fprintf(&b, `<a href="%s" class="tag-chip">%s</a>`, tagPath(slug), html.EscapeString(strings.TrimSpace(name)))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return `<p class="page-tags" aria-label="Tags">` + b.String() + `</p>`
+}
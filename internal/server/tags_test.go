@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// taggedSiteFiles is a site whose pages share some tags.
+var taggedSiteFiles = map[string]string{
+	"index.md":        "# Home\n",
+	"guides/intro.md": "---\ntitle: Intro\ntags: [go, basics]\n---\n# Intro\n",
+	"guides/setup.md": "---\ntitle: Setup\ntags: [go]\n---\n# Setup\n",
+	"reference/a.md":  "---\ntitle: A & B\ntags: [reference]\n---\n# A & B\n",
+}
+
+// siteMode makes the test server a multi-page site.
+func siteMode(cfg *config.Config) { cfg.Type = "site" }
+
+// tagPageLinks returns the page links listed on a tag page.
+func tagPageLinks(body string) []string {
+	list := regexp.MustCompile(`(?s)<ul class="tag-pages">(.*?)</ul>`).FindStringSubmatch(body)
+	if list == nil {
+		return nil
+	}
+	var links []string
+	for _, m := range regexp.MustCompile(`href="([^"]+)"`).FindAllStringSubmatch(list[1], -1) {
+		links = append(links, m[1])
+	}
+	return links
+}
+
+func TestTagPage(t *testing.T) {
+	srv := newTestServer(t, taggedSiteFiles, siteMode)
+
+	for tag, want := range map[string][]string{
+		"go":        {"/guides/intro", "/guides/setup"},
+		"basics":    {"/guides/intro"},
+		"reference": {"/reference/a"},
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", "/tags/"+tag, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("/tags/%s status = %d, want 200", tag, rec.Code)
+			continue
+		}
+		got := tagPageLinks(rec.Body.String())
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("/tags/%s lists %v, want %v", tag, got, want)
+		}
+	}
+
+	// Titles are escaped
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/tags/reference", nil))
+	if !strings.Contains(rec.Body.String(), `<a href="/reference/a">A &amp; B</a>`) {
+		t.Errorf("tag page should escape page titles:\n%s", rec.Body.String())
+	}
+
+	// Unknown tags aren't pages
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/tags/missing", nil))
+	if rec.Code == http.StatusOK {
+		t.Error("unknown tag should not be served")
+	}
+}
+
+func TestTagIndex(t *testing.T) {
+	srv := newTestServer(t, taggedSiteFiles, siteMode)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/tags/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	items := regexp.MustCompile(`<li><a href="(/tags/[^"]+)" class="tag-chip">([^<]+)</a> <span class="tag-count">(\d+)</span></li>`).FindAllStringSubmatch(body, -1)
+	var got []string
+	for _, item := range items {
+		got = append(got, item[2]+"="+item[3]+" "+item[1])
+	}
+	want := []string{"basics=1 /tags/basics", "go=2 /tags/go", "reference=1 /tags/reference"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("tag index lists %v, want %v", got, want)
+	}
+
+	// The path without the slash redirects to the index
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/tags", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/tags/" {
+		t.Errorf("/tags = %d to %q, want a redirect to /tags/", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestTagChips(t *testing.T) {
+	srv := newTestServer(t, taggedSiteFiles, siteMode)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/guides/intro", nil))
+	want := `<p class="page-tags" aria-label="Tags"><a href="/tags/go" class="tag-chip">go</a><a href="/tags/basics" class="tag-chip">basics</a></p>`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("page missing tag chips %q", want)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if strings.Contains(rec.Body.String(), `class="page-tags"`) {
+		t.Error("untagged page should have no tag chips")
+	}
+	if !hasCSS(pageStylesheet(t, srv), ".tag-chip") {
+		t.Error("stylesheet should style tag chips")
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Warmup builds the templates of every interactive block on the discovered
+// pages, so the first visit to a page doesn't pay for parsing them. Blocks are
+// built concurrently by a bounded pool of workers (NumCPU when workers <= 0).
+// A block that fails to build doesn't stop the others; its error is returned
+// prefixed with the page's file path.
+func (s *Server) Warmup(workers int) []error {
+	type job struct {
+		file                         string
+		blockID, sourceName, content string
+	}
+
+	s.mu.RLock()
+	var jobs []job
+	for _, route := range s.routes {
+		if route.Page == nil {
+			continue
+		}
+		for blockID, block := range route.Page.InteractiveBlocks {
+			var sourceName string
+			if sb, ok := route.Page.ServerBlocks[block.StateRef]; ok {
+				sourceName = sb.Metadata["lvt-source"]
+			}
+			jobs = append(jobs, job{route.FilePath, blockID, sourceName, block.Content})
+		}
+	}
+	s.mu.RUnlock()
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   []error
+		queued = make(chan job)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queued {
+				if _, err := sharedBlockTemplate(j.blockID, j.sourceName, j.content); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", j.file, err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, j := range jobs {
+		queued <- j
+	}
+	close(queued)
+	wg.Wait()
+
+	return errs
+}
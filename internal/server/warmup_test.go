@@ -0,0 +1,49 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServerWarmup(t *testing.T) {
+	tmpDir := t.TempDir()
+	frontmatter := "---\nsources:\n  items:\n    type: json\n    file: items.json\n---\n"
+	pages := map[string]string{
+		"index.md":  frontmatter + "# Home\n\n```lvt\n<div lvt-source=\"items\">{{len .Data}}</div>\n```\n\n```lvt\n<div lvt-source=\"items\">{{range .Data}}<p>{{.name}}</p>{{end}}</div>\n```\n",
+		"broken.md": frontmatter + "# Broken\n\n```lvt\n<div lvt-source=\"items\">{{len .Data}</div>\n```\n",
+	}
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	// The broken block is reported without stopping the others
+	errs := srv.Warmup(2)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.md") {
+		t.Fatalf("Warmup() errors = %v, want one error for broken.md", errs)
+	}
+
+	blockTemplatesMu.Lock()
+	defer blockTemplatesMu.Unlock()
+	for _, route := range srv.Routes() {
+		if filepath.Base(route.FilePath) != "index.md" {
+			continue
+		}
+		if len(route.Page.InteractiveBlocks) != 2 {
+			t.Fatalf("index.md has %d interactive blocks, want 2", len(route.Page.InteractiveBlocks))
+		}
+		for id, block := range route.Page.InteractiveBlocks {
+			if _, ok := blockTemplates[blockTemplateKey(id, block.Content)]; !ok {
+				t.Errorf("block %s was not warmed up", id)
+			}
+		}
+	}
+}
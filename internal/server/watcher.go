@@ -1,7 +1,6 @@
 package server
 
 import (
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -62,9 +61,7 @@ func (w *Watcher) addDirectoryRecursive(dir string) error {
 				return err
 			}
 
-			if w.debug {
-				log.Printf("[Watch] Added directory: %s", path)
-			}
+			watchLog.Debugf("Added directory: %s", path)
 		}
 
 		return nil
@@ -89,12 +86,10 @@ func (w *Watcher) Start() {
 							relPath = event.Name
 						}
 
-						if w.debug {
-							log.Printf("[Watch] File changed: %s", relPath)
-						}
+						watchLog.Debugf("File changed: %s", relPath)
 
 						if err := w.onReload(relPath); err != nil {
-							log.Printf("[Watch] Reload failed for %s: %v", relPath, err)
+							watchLog.Errorf("Reload failed for %s: %v", relPath, err)
 						}
 					}
 				}
@@ -103,7 +98,7 @@ func (w *Watcher) Start() {
 				if !ok {
 					return
 				}
-				log.Printf("[Watch] Error: %v", err)
+				watchLog.Errorf("Error: %v", err)
 
 			case <-w.done:
 				return
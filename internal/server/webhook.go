@@ -96,7 +96,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os/exec"
 	"strconv"
@@ -426,9 +425,9 @@ func (h *WebhookHandler) auditLog(webhookName, actionName string, r *http.Reques
 	}
 
 	if success {
-		log.Printf("[Webhook] %s -> %s from %s (success)", webhookName, actionName, r.RemoteAddr)
+		webhookLog.Infof("%s -> %s from %s (success)", webhookName, actionName, r.RemoteAddr)
 	} else {
-		log.Printf("[Webhook] %s -> %s from %s (failed: %s)", webhookName, actionName, r.RemoteAddr, errMsg)
+		webhookLog.Errorf("%s -> %s from %s (failed: %s)", webhookName, actionName, r.RemoteAddr, errMsg)
 	}
 }
 
@@ -722,6 +721,11 @@ func (e *webhookActionExecutor) executeExecAction(action *config.Action, data ma
 	if err := e.sanitizeExecCommand(cmdStr); err != nil {
 		return err
 	}
+	if fields := strings.Fields(cmdStr); len(fields) > 0 {
+		if err := config.CheckExecutable(fields[0], e.rootDir); err != nil {
+			return err
+		}
+	}
 
 	// Create command with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -2,16 +2,21 @@ package server
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"text/template/parse"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/livetemplate/lvt/components/base"
@@ -19,8 +24,10 @@ import (
 	"github.com/livetemplate/livetemplate"
 	"github.com/livetemplate/tinkerdown"
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/metrics"
 	"github.com/livetemplate/tinkerdown/internal/runtime"
 	"github.com/livetemplate/tinkerdown/internal/source"
+	"github.com/livetemplate/tinkerdown/internal/tracing"
 )
 
 // newUpgrader creates a WebSocket upgrader with origin validation.
@@ -82,11 +89,12 @@ type MessageEnvelope struct {
 
 // ExecMeta contains execution state for exec source blocks
 type ExecMeta struct {
-	Status   string `json:"status"`
-	Duration int64  `json:"duration,omitempty"`
-	Output   string `json:"output,omitempty"`
-	Stderr   string `json:"stderr,omitempty"`
-	Command  string `json:"command,omitempty"`
+	Status         string `json:"status"`
+	Duration       int64  `json:"duration,omitempty"`
+	Output         string `json:"output,omitempty"`
+	Stderr         string `json:"stderr,omitempty"`
+	Command        string `json:"command,omitempty"`
+	ConfirmMessage string `json:"confirm_message,omitempty"` // Question shown while status is "confirm"
 }
 
 // CacheMeta contains cache state for cached source blocks
@@ -107,7 +115,8 @@ type WebSocketHandler struct {
 	sourceFiles    map[string][]string            // blockID -> source file paths (for file watching)
 	debug          bool
 	server         *Server                        // Reference to server for connection tracking
-	stateFactories map[string]func() runtime.Store // State factories for lvt-source blocks
+	stateFactories map[string]func() (runtime.Store, error) // State factories for lvt-source blocks
+	blockErrors    map[string]error                         // Why a server block has no factory, shown in place of its blocks
 	rootDir        string                          // Site root directory for database path
 	config         *config.Config                  // Site configuration with sources
 	conn           *websocket.Conn                 // Current connection for this handler
@@ -125,11 +134,9 @@ type BlockInstance struct {
 
 // NewWebSocketHandler creates a new WebSocket handler for a page.
 func NewWebSocketHandler(page *tinkerdown.Page, server *Server, debug bool, rootDir string, cfg *config.Config) *WebSocketHandler {
-	if debug {
-		log.Printf("[WS] Creating WebSocket handler for page: %s", page.ID)
-		log.Printf("[WS] Page has %d server blocks", len(page.ServerBlocks))
-		log.Printf("[WS] Page has %d interactive blocks", len(page.InteractiveBlocks))
-	}
+	wsLog.Debugf("Creating WebSocket handler for page: %s", page.ID)
+	wsLog.Debugf("Page has %d server blocks", len(page.ServerBlocks))
+	wsLog.Debugf("Page has %d interactive blocks", len(page.InteractiveBlocks))
 
 	h := &WebSocketHandler{
 		page:           page,
@@ -137,7 +144,8 @@ func NewWebSocketHandler(page *tinkerdown.Page, server *Server, debug bool, root
 		sourceFiles:    make(map[string][]string),
 		debug:          debug,
 		server:         server,
-		stateFactories: make(map[string]func() runtime.Store),
+		stateFactories: make(map[string]func() (runtime.Store, error)),
+		blockErrors:    make(map[string]error),
 		rootDir:        rootDir,
 		config:         cfg,
 		actionSources:  make(map[string]source.Source),
@@ -154,23 +162,21 @@ func (h *WebSocketHandler) Close() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if h.debug {
-		log.Printf("[WS] Closing WebSocket handler, cleaning up %d instances", len(h.instances))
-	}
+	wsLog.Debugf("Closing WebSocket handler, cleaning up %d instances", len(h.instances))
 
 	// Close all state instances
 	for blockID, instance := range h.instances {
 		if instance.state != nil {
-			if err := instance.state.Close(); err != nil && h.debug {
-				log.Printf("[WS] Error closing state for block %s: %v", blockID, err)
+			if err := instance.state.Close(); err != nil {
+				wsLog.Debugf("Error closing state for block %s: %v", blockID, err)
 			}
 		}
 	}
 
 	// Close cached action sources
 	for name, src := range h.actionSources {
-		if err := src.Close(); err != nil && h.debug {
-			log.Printf("[WS] Error closing action source %s: %v", name, err)
+		if err := src.Close(); err != nil {
+			wsLog.Debugf("Error closing action source %s: %v", name, err)
 		}
 	}
 
@@ -188,33 +194,45 @@ func (h *WebSocketHandler) initializeSourceBlocks() {
 		for id := range h.page.ServerBlocks {
 			blockIDs = append(blockIDs, id)
 		}
-		log.Printf("[WS] All server block IDs: %v", blockIDs)
+		wsLog.Debugf("All server block IDs: %v", blockIDs)
 	}
 
 	for blockID, block := range h.page.ServerBlocks {
-		if h.debug {
-			log.Printf("[WS] Processing server block: %s (metadata: %v)", blockID, block.Metadata)
-		}
+		wsLog.Debugf("Processing server block: %s (metadata: %v)", blockID, block.Metadata)
 
 		// Only lvt-source blocks are supported
 		sourceName := block.Metadata["lvt-source"]
 		if sourceName == "" {
 			// Regular server blocks (Go code) are no longer supported
-			log.Printf("[WS] ERROR: Server block %s is not an lvt-source block. Go code blocks are no longer supported.", blockID)
-			log.Printf("[WS] Please migrate to lvt-source by defining a source in frontmatter or tinkerdown.yaml")
+			wsLog.Errorf("Server block %s is not an lvt-source block. Go code blocks are no longer supported.", blockID)
+			wsLog.Errorf("Please migrate to lvt-source by defining a source in frontmatter or tinkerdown.yaml")
+			h.blockErrors[blockID] = fmt.Errorf("server block %s is not an lvt-source block; Go code blocks are no longer supported", blockID)
 			continue
 		}
 
 		// lvt-source block - use runtime.GenericState (no compilation needed!)
 		// Check page-level sources first (from frontmatter), then site-level (from tinkerdown.yaml)
-		sourceCfg, found := h.getEffectiveSource(sourceName)
-		if !found {
-			log.Printf("[WS] Source %q not found (checked frontmatter and tinkerdown.yaml) for block %s", sourceName, blockID)
+		names := tinkerdown.SourceNames(sourceName)
+		sourceCfgs := make([]config.SourceConfig, len(names))
+		var missing string
+		for i, name := range names {
+			cfg, found := h.getEffectiveSource(name)
+			if !found {
+				missing = name
+				break
+			}
+			sourceCfgs[i] = cfg
+		}
+		if missing != "" {
+			wsLog.Warnf("Source %q not found (checked frontmatter and tinkerdown.yaml) for block %s", missing, blockID)
+			h.blockErrors[blockID] = fmt.Errorf("source %q is not defined in frontmatter or tinkerdown.yaml", missing)
 			continue
 		}
-		if h.debug {
-			log.Printf("[WS] Creating runtime state for lvt-source block: %s (source: %s, type: %s)", blockID, sourceName, sourceCfg.Type)
+		if len(names) > 1 && block.Metadata["lvt-element"] != "div" {
+			h.blockErrors[blockID] = fmt.Errorf("lvt-source %q combines sources, which needs a custom template; a %s binds a single source", sourceName, block.Metadata["lvt-element"])
+			continue
 		}
+		wsLog.Debugf("Creating runtime state for lvt-source block: %s (sources: %v)", blockID, names)
 		// Pass the current markdown file path for same-file markdown sources
 		currentFile := ""
 		if h.page != nil {
@@ -223,7 +241,7 @@ func (h *WebSocketHandler) initializeSourceBlocks() {
 
 		// Create runtime state factory
 		// Capture variables for closure
-		srcName, srcCfg, rootDir, curFile := sourceName, sourceCfg, h.rootDir, currentFile
+		rootDir, curFile := h.rootDir, currentFile
 		// Copy metadata for closure
 		blockMeta := make(map[string]string)
 		for k, v := range block.Metadata {
@@ -233,7 +251,7 @@ func (h *WebSocketHandler) initializeSourceBlocks() {
 		// Build page-level actions map (convert from parser types to config types)
 		pageActions := h.getPageActions()
 
-		factory := func() runtime.Store {
+		newState := func(srcName string, srcCfg config.SourceConfig) (*runtime.GenericState, error) {
 			var state *runtime.GenericState
 			var err error
 
@@ -244,8 +262,8 @@ func (h *WebSocketHandler) initializeSourceBlocks() {
 				state, err = runtime.NewGenericStateWithMetadata(srcName, srcCfg, rootDir, curFile, blockMeta)
 			}
 			if err != nil {
-				log.Printf("[WS] Failed to create runtime state for %s: %v", srcName, err)
-				return nil
+				wsLog.Errorf("Failed to create runtime state for %s: %v", srcName, err)
+				return nil, err
 			}
 
 			// Configure page-level settings for custom actions
@@ -253,45 +271,35 @@ func (h *WebSocketHandler) initializeSourceBlocks() {
 				state.SetPageConfig(pageActions, h.lookupSource)
 			}
 
-			return state
+			return state, nil
 		}
 
-		h.stateFactories[blockID] = factory
-
-		// Track source files for markdown sources (for live refresh)
-		if sourceCfg.Type == "markdown" {
-			var sourceFilePath string
-			if sourceCfg.File != "" {
-				// External file - resolve relative to root or current file
-				if filepath.IsAbs(sourceCfg.File) {
-					sourceFilePath = sourceCfg.File
-				} else {
-					// Try relative to current file first, then root
-					if currentFile != "" {
-						sourceFilePath = filepath.Join(filepath.Dir(currentFile), sourceCfg.File)
-					} else {
-						sourceFilePath = filepath.Join(h.rootDir, sourceCfg.File)
-					}
-				}
-			} else {
-				// Same-file source
-				sourceFilePath = currentFile
+		factory := func() (runtime.Store, error) {
+			if len(names) == 1 {
+				return newState(names[0], sourceCfgs[0])
 			}
-			if sourceFilePath != "" {
-				// Make path relative to rootDir for consistent matching with watcher events
-				if relPath, err := filepath.Rel(h.rootDir, sourceFilePath); err == nil {
-					sourceFilePath = relPath
-				}
-				h.sourceFiles[blockID] = append(h.sourceFiles[blockID], sourceFilePath)
-				if h.debug {
-					log.Printf("[WS] Block %s tracks source file: %s", blockID, sourceFilePath)
+			states := make([]*runtime.GenericState, 0, len(names))
+			for i, name := range names {
+				state, err := newState(name, sourceCfgs[i])
+				if err != nil {
+					for _, created := range states {
+						created.Close()
+					}
+					return nil, err
 				}
+				states = append(states, state)
 			}
+			return runtime.NewCombinedState(names, states, blockMeta["lvt-join"]), nil
 		}
 
-		if h.debug {
-			log.Printf("[WS] Successfully initialized block: %s", blockID)
+		h.stateFactories[blockID] = factory
+
+		// Track source files for markdown sources (for live refresh)
+		for _, sourceCfg := range sourceCfgs {
+			h.trackSourceFile(blockID, sourceCfg, currentFile)
 		}
+
+		wsLog.Debugf("Successfully initialized block: %s", blockID)
 	}
 
 	// Debug: list all state factories
@@ -300,8 +308,54 @@ func (h *WebSocketHandler) initializeSourceBlocks() {
 		for id := range h.stateFactories {
 			factoryIDs = append(factoryIDs, id)
 		}
-		log.Printf("[WS] State factories: %v", factoryIDs)
+		wsLog.Debugf("State factories: %v", factoryIDs)
+	}
+}
+
+// pageSourceConfig converts a source declared in page frontmatter to the
+// config form that sources are created from.
+func pageSourceConfig(src tinkerdown.SourceConfig) config.SourceConfig {
+	return config.SourceConfig{
+		Type:        src.Type,
+		Cmd:         src.Cmd,
+		Query:       src.Query,
+		From:        src.From,
+		File:        src.File,
+		Anchor:      src.Anchor,
+		DB:          src.DB,
+		Table:       src.Table,
+		Path:        src.Path,
+		QueryFile:   src.QueryFile,
+		Variables:   src.Variables,
+		Headers:     src.Headers,
+		QueryParams: src.QueryParams,
+		ResultPath:  src.ResultPath,
+		Readonly:    src.Readonly,
+		Options:     src.Options,
+		Manual:      src.Manual,
+		Confirm:     src.Confirm,
+		ConfirmMsg:  src.ConfirmMsg,
+		Format:      src.Format,
+		Delimiter:   src.Delimiter,
+		Env:         src.Env,
+		Timeout:     src.Timeout,
+		GroupBy:     src.GroupBy,
+		Aggregate:   src.Aggregate,
+		Filter:      src.Filter,
+		Fields:      fieldRules(src.Fields),
+	}
+}
+
+// fieldRules converts frontmatter field rules to their config form.
+func fieldRules(rules map[string]tinkerdown.FieldRule) map[string]config.FieldRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	out := make(map[string]config.FieldRule, len(rules))
+	for field, r := range rules {
+		out[field] = config.FieldRule{Required: r.Required, MaxLen: r.MaxLen, Pattern: r.Pattern}
 	}
+	return out
 }
 
 // getEffectiveSource looks up a source by name, checking page-level sources first
@@ -310,33 +364,7 @@ func (h *WebSocketHandler) getEffectiveSource(name string) (config.SourceConfig,
 	// Check page-level sources first (from frontmatter)
 	if h.page != nil && h.page.Config.Sources != nil {
 		if src, ok := h.page.Config.Sources[name]; ok {
-			// Convert tinkerdown.SourceConfig to config.SourceConfig
-			return config.SourceConfig{
-				Type:        src.Type,
-				Cmd:         src.Cmd,
-				Query:       src.Query,
-				From:        src.From,
-				File:        src.File,
-				Anchor:      src.Anchor,
-				DB:          src.DB,
-				Table:       src.Table,
-				Path:        src.Path,
-				QueryFile:   src.QueryFile,
-				Variables:   src.Variables,
-				Headers:     src.Headers,
-				QueryParams: src.QueryParams,
-				ResultPath:  src.ResultPath,
-				Readonly:    src.Readonly,
-				Options:     src.Options,
-				Manual:      src.Manual,
-				Format:      src.Format,
-				Delimiter:   src.Delimiter,
-				Env:         src.Env,
-				Timeout:     src.Timeout,
-				GroupBy:     src.GroupBy,
-				Aggregate:   src.Aggregate,
-				Filter:      src.Filter,
-			}, true
+			return pageSourceConfig(src), true
 		}
 	}
 
@@ -362,7 +390,7 @@ func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Upgrade connection
 	conn, err := wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("[WS] Failed to upgrade connection: %v", err)
+		wsLog.Errorf("Failed to upgrade connection: %v", err)
 		return
 	}
 
@@ -375,6 +403,7 @@ func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.server.UnregisterConnection(conn)
 		}
 		conn.Close()
+		h.Close()
 	}()
 
 	// Register connection for reload broadcasts (with handler for source refresh)
@@ -382,45 +411,136 @@ func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.server.RegisterConnection(conn, h)
 	}
 
-	if h.debug {
-		log.Printf("[WS] Client connected: %s", conn.RemoteAddr())
-	}
+	wsLog.Debugf("Client connected: %s", conn.RemoteAddr())
 
 	// Initialize instances for all interactive blocks
 	h.initializeInstances(conn)
 
+	// Ping the client regularly; a client that neither answers nor sends
+	// anything within the idle timeout is dropped.
+	idleTimeout := h.idleTimeout()
+	conn.SetReadDeadline(time.Now().Add(idleTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(idleTimeout))
+	})
+	stopPings := make(chan struct{})
+	defer close(stopPings)
+	go pingConnection(conn, idleTimeout/2, stopPings)
+
 	// Handle messages
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("[WS] Unexpected close: %v", err)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				wsLog.Infof("Dropping idle connection: %s", conn.RemoteAddr())
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				wsLog.Warnf("Unexpected close: %v", err)
 			}
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
 
-		if h.debug {
-			log.Printf("[WS] Received: %s", message)
-		}
+		wsLog.Debugf("Received: %s", message)
 
 		h.handleMessage(conn, message)
 	}
 
-	if h.debug {
-		log.Printf("[WS] Client disconnected: %s", conn.RemoteAddr())
+	wsLog.Debugf("Client disconnected: %s", conn.RemoteAddr())
+}
+
+// idleTimeout returns how long the connection may stay silent (see
+// server.idle_timeout).
+func (h *WebSocketHandler) idleTimeout() time.Duration {
+	if h.config == nil {
+		return config.ServerConfig{}.GetIdleTimeout()
 	}
+	return h.config.Server.GetIdleTimeout()
 }
 
+// pingConnection sends a ping every interval until stop is closed or a ping
+// can't be written.
+func pingConnection(conn *websocket.Conn, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// rootRelative returns path relative to rootDir, the form source files are
+// tracked and watcher events reported in, or path itself if it has none.
+func rootRelative(rootDir, path string) string {
+	if rel, err := filepath.Rel(rootDir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// trackSourceFile records the file a markdown source reads, or the directory
+// an upload source stores files in, relative to the root, so changes to it
+// refresh the block.
+func (h *WebSocketHandler) trackSourceFile(blockID string, sourceCfg config.SourceConfig, currentFile string) {
+	if sourceCfg.Type == "upload" {
+		if dir, err := source.UploadDir(sourceCfg, h.rootDir); err == nil {
+			h.sourceFiles[blockID] = append(h.sourceFiles[blockID], rootRelative(h.rootDir, dir))
+		}
+		return
+	}
+	if sourceCfg.Type != "markdown" {
+		return
+	}
+	var sourceFilePath string
+	if sourceCfg.File != "" {
+		// External file - resolve relative to root or current file
+		if filepath.IsAbs(sourceCfg.File) {
+			sourceFilePath = sourceCfg.File
+		} else {
+			// Try relative to current file first, then root
+			if currentFile != "" {
+				sourceFilePath = filepath.Join(filepath.Dir(currentFile), sourceCfg.File)
+			} else {
+				sourceFilePath = filepath.Join(h.rootDir, sourceCfg.File)
+			}
+		}
+	} else {
+		// Same-file source
+		sourceFilePath = currentFile
+	}
+	if sourceFilePath != "" {
+		// Make path relative to rootDir for consistent matching with watcher events
+		sourceFilePath = rootRelative(h.rootDir, sourceFilePath)
+		h.sourceFiles[blockID] = append(h.sourceFiles[blockID], sourceFilePath)
+		wsLog.Debugf("Block %s tracks source file: %s", blockID, sourceFilePath)
+	}
+}
+
+// maxConcurrentBlockInits bounds how many blocks of a page load their
+// sources at once.
+const maxConcurrentBlockInits = 8
+
+// blockInitDeadline is how long a connection waits for its blocks' sources.
+// Blocks still loading then show an error card, and their state is closed
+// when it arrives. It's a variable so tests can shorten it.
+var blockInitDeadline = 30 * time.Second
+
 // initializeInstances creates LiveTemplate instances for each interactive block.
+// Blocks load their sources concurrently, so a page with several slow sources
+// takes about as long as the slowest one rather than the sum of them.
 func (h *WebSocketHandler) initializeInstances(conn *websocket.Conn) {
-	// Collect block info under lock, then create instances outside lock.
-	// Factory calls (especially for computed sources) may call lookupSource
-	// which also acquires h.mu — so we must not hold the lock during factory calls.
 	type blockInfo struct {
 		blockID    string
 		block      *tinkerdown.InteractiveBlock
 		stateBlock *tinkerdown.ServerBlock
-		factory    func() runtime.Store
+		factory    func() (runtime.Store, error)
+		err        error // Set instead of factory when the block can't start
 	}
 	var toInit []blockInfo
 
@@ -428,81 +548,120 @@ func (h *WebSocketHandler) initializeInstances(conn *websocket.Conn) {
 	for blockID, block := range h.page.InteractiveBlocks {
 		stateBlock, ok := h.page.ServerBlocks[block.StateRef]
 		if !ok {
-			log.Printf("[WS] Interactive block %s references unknown state %s", blockID, block.StateRef)
+			wsLog.Warnf("Interactive block %s references unknown state %s", blockID, block.StateRef)
 			continue
 		}
 		factory, ok := h.stateFactories[block.StateRef]
 		if !ok {
-			log.Printf("[WS] No compiled factory for state %s", block.StateRef)
+			err, ok := h.blockErrors[block.StateRef]
+			if !ok {
+				wsLog.Warnf("No compiled factory for state %s", block.StateRef)
+				continue
+			}
+			toInit = append(toInit, blockInfo{blockID: blockID, block: block, stateBlock: stateBlock, err: err})
 			continue
 		}
-		toInit = append(toInit, blockInfo{blockID, block, stateBlock, factory})
+		toInit = append(toInit, blockInfo{blockID: blockID, block: block, stateBlock: stateBlock, factory: factory})
 	}
 	h.mu.Unlock()
 
-	// Create instances under lock, temporarily releasing it during each factory call
-	// (computed source factories call lookupSource which also acquires h.mu).
-	var instances []*BlockInstance
-	func() {
-		h.mu.Lock()
-		defer h.mu.Unlock()
-
-		for _, bi := range toInit {
-			blockID := bi.blockID
-			block := bi.block
-			stateBlock := bi.stateBlock
-
-			// Create state instance outside the lock — factory calls may acquire h.mu
-			// (e.g., computed sources call lookupSource which locks h.mu).
-			// Use a helper to ensure the lock is always re-acquired, even on panic.
-			state := func() runtime.Store {
-				h.mu.Unlock()
-				defer h.mu.Lock()
-				return bi.factory()
+	// Build templates and states without holding h.mu: factory calls may
+	// acquire it (computed sources call lookupSource).
+	type blockResult struct {
+		state runtime.Store
+		tmpl  *livetemplate.Template
+		err   error
+	}
+	results := make([]chan blockResult, len(toInit))
+	sem := make(chan struct{}, maxConcurrentBlockInits)
+	for i, bi := range toInit {
+		results[i] = make(chan blockResult, 1)
+		go func(done chan<- blockResult) {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			wsLog.Debugf("Block %s template content:\n%s", bi.blockID, bi.block.Content)
+			var r blockResult
+			// A source that panics fails its block, not the server
+			defer func() {
+				if p := recover(); p != nil {
+					r = blockResult{err: fmt.Errorf("block %s failed to load: %v", bi.blockID, p)}
+					done <- r
+				}
 			}()
-
-			// Create template from inline content
-			// Since livetemplate.New() requires template files, we use a workaround:
-			// Write content to a temp file, parse it, then delete
-			tmpFile := fmt.Sprintf("/tmp/lvt-%s.tmpl", blockID)
-			if h.debug {
-				log.Printf("[WS] Block %s template content:\n%s", blockID, block.Content)
+			r.err = bi.err
+			if r.err == nil {
+				r.tmpl, r.err = cachedBlockTemplate(bi.blockID, bi.stateBlock.Metadata["lvt-source"], bi.block.Content)
 			}
-			if err := os.WriteFile(tmpFile, []byte(block.Content), 0644); err != nil {
-				log.Printf("[WS] Failed to write temp template for block %s: %v", blockID, err)
-				continue
-			}
-			defer os.Remove(tmpFile)
-
-			tmpl, err := livetemplate.New(blockID,
-				livetemplate.WithComponentTemplates(getComponentTemplates()...),
-				livetemplate.WithParseFiles(tmpFile))
-			if err != nil {
-				log.Printf("[WS] Failed to create template for block %s: %v", blockID, err)
-				continue
+			if r.err == nil {
+				r.state, r.err = bi.factory()
 			}
+			done <- r
+		}(results[i])
+	}
 
-			// Register component-specific template functions for tree generation
-			// These are needed because WithComponentTemplates adds funcs to t.tmpl but not t.funcs
-			tmpl.Funcs(getComponentFuncs())
+	deadline := time.NewTimer(blockInitDeadline)
+	defer deadline.Stop()
+	timedOut := false
 
-			instance := &BlockInstance{
-				blockID:  blockID,
-				state:    state,
-				template: tmpl,
-				conn:     conn,
+	var instances []*BlockInstance
+	for i, bi := range toInit {
+		blockID := bi.blockID
+		sourceName := bi.stateBlock.Metadata["lvt-source"]
+
+		var r blockResult
+		if !timedOut {
+			select {
+			case r = <-results[i]:
+			case <-deadline.C:
+				timedOut = true
 			}
+		}
+		if timedOut {
+			select {
+			case r = <-results[i]:
+			default:
+				// Close the state once it arrives, as no instance will
+				go func(late <-chan blockResult) {
+					if r := <-late; r.state != nil {
+						r.state.Close()
+					}
+				}(results[i])
+				r = blockResult{err: fmt.Errorf("lvt-source %q didn't load within %s", sourceName, blockInitDeadline)}
+			}
+		}
 
-			h.instances[blockID] = instance
-			instances = append(instances, instance)
-
-			if h.debug {
-				log.Printf("[WS] Initialized block: %s (state ref: %s)", blockID, block.StateRef)
+		state, tmpl, err := r.state, r.tmpl, r.err
+		if err != nil {
+			// Show the error where the block would be instead of leaving it empty
+			wsLog.Errorf("%v", err)
+			state = newBlockError(sourceName, err, h.debug)
+			if tmpl, err = cachedBlockTemplate(blockID, "", blockErrorTemplate); err != nil {
+				wsLog.Errorf("%v", err)
+				continue
 			}
+		}
 
-			_ = stateBlock // Mark as used
+		instance := &BlockInstance{
+			blockID:  blockID,
+			state:    state,
+			template: tmpl,
+			conn:     conn,
 		}
-	}()
+		// Streaming exec sources re-render as their output arrives
+		if s, ok := state.(interface{ SetOnUpdate(func()) }); ok {
+			s.SetOnUpdate(func() { h.sendUpdate(instance) })
+		}
+		instances = append(instances, instance)
+
+		wsLog.Debugf("Initialized block: %s (state ref: %s)", blockID, bi.block.StateRef)
+	}
+
+	h.mu.Lock()
+	for _, instance := range instances {
+		h.instances[instance.blockID] = instance
+	}
+	h.mu.Unlock()
 
 	// Send initial states (outside the h.mu lock)
 	for _, instance := range instances {
@@ -513,6 +672,109 @@ func (h *WebSocketHandler) initializeInstances(conn *websocket.Conn) {
 	h.evaluateAndSendExpressions(conn)
 }
 
+// maxCachedBlockTemplates bounds the block template cache. Editing a page in
+// dev mode adds an entry per save, so the cache is reset when it fills up.
+const maxCachedBlockTemplates = 256
+
+var (
+	blockTemplatesMu sync.Mutex
+	blockTemplates   = make(map[[sha256.Size]byte]*livetemplate.Template)
+)
+
+// cachedBlockTemplate returns a per-connection copy of the block's template.
+// Templates are built once per block ID and content, so only the first
+// connection to a page pays for parsing it and the component templates.
+func cachedBlockTemplate(blockID, sourceName, content string) (*livetemplate.Template, error) {
+	tmpl, err := sharedBlockTemplate(blockID, sourceName, content)
+	if err != nil {
+		return nil, err
+	}
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy template for block %s: %w", blockID, err)
+	}
+	return clone, nil
+}
+
+// blockTemplateKey identifies a block's template in the cache.
+func blockTemplateKey(blockID, content string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(blockID + "\x00" + content))
+}
+
+// sharedBlockTemplate returns the cached template for a block, building it
+// on first use. The result must be cloned before it is executed.
+func sharedBlockTemplate(blockID, sourceName, content string) (*livetemplate.Template, error) {
+	key := blockTemplateKey(blockID, content)
+
+	blockTemplatesMu.Lock()
+	tmpl, ok := blockTemplates[key]
+	blockTemplatesMu.Unlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	_, span := tracing.Start(context.Background(), "block.compile",
+		tracing.AttrBlock.String(blockID), tracing.AttrSource.String(sourceName))
+	start := time.Now()
+	tmpl, err := newBlockTemplate(blockID, sourceName, content)
+	metrics.ObserveBlockCompile(time.Since(start))
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	blockTemplatesMu.Lock()
+	if len(blockTemplates) >= maxCachedBlockTemplates {
+		blockTemplates = make(map[[sha256.Size]byte]*livetemplate.Template)
+	}
+	blockTemplates[key] = tmpl
+	blockTemplatesMu.Unlock()
+	return tmpl, nil
+}
+
+// newBlockTemplate creates the LiveTemplate for an interactive block.
+// The content is parsed on its own first so that a syntax error names the
+// block, its source and the offending line instead of a temp file.
+func newBlockTemplate(blockID, sourceName, content string) (*livetemplate.Template, error) {
+	desc := "block " + blockID
+	if sourceName != "" {
+		desc += fmt.Sprintf(" (lvt-source %q)", sourceName)
+	}
+
+	// Functions are checked when the full template is built
+	tree := parse.New(blockID)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(content, "", "", make(map[string]*parse.Tree)); err != nil {
+		return nil, fmt.Errorf("invalid template in %s: %w", desc, err)
+	}
+
+	// Since livetemplate.New() requires template files, we use a workaround:
+	// Write content to a temp file, parse it, then delete
+	tmpFile, err := os.CreateTemp("", "lvt-*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temp template for %s: %w", desc, err)
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.WriteString(content)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temp template for %s: %w", desc, err)
+	}
+
+	tmpl, err := livetemplate.New(blockID,
+		livetemplate.WithComponentTemplates(getComponentTemplates()...),
+		livetemplate.WithParseFiles(tmpFile.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template for %s: %w", desc, err)
+	}
+
+	// Register component-specific template functions for tree generation
+	// These are needed because WithComponentTemplates adds funcs to t.tmpl but not t.funcs
+	tmpl.Funcs(getComponentFuncs())
+	return tmpl, nil
+}
+
 // sendInitialState sends the initial tree update to the client.
 func (h *WebSocketHandler) sendInitialState(instance *BlockInstance) {
 	// Get state data and render under instance lock
@@ -534,26 +796,22 @@ func (h *WebSocketHandler) sendInitialState(instance *BlockInstance) {
 			var err error
 			stateData, err = getter.GetStateAsInterface()
 			if err != nil {
-				log.Printf("[WS] Failed to get state for %s: %v", instance.blockID, err)
+				wsLog.Errorf("Failed to get state for %s: %v", instance.blockID, err)
 				return
 			}
 			// Hydrate datatable structs so template methods work
 			stateData = hydrateDataTableState(stateData)
-			if h.debug {
-				log.Printf("[WS] RPC state for %s: %+v (type: %T)", instance.blockID, stateData, stateData)
-			}
+			wsLog.Debugf("RPC state for %s: %+v (type: %T)", instance.blockID, stateData, stateData)
 		} else {
 			// Regular in-process state
 			stateData = instance.state
-			if h.debug {
-				log.Printf("[WS] Direct state for %s: %+v (type: %T)", instance.blockID, stateData, stateData)
-			}
+			wsLog.Debugf("Direct state for %s: %+v (type: %T)", instance.blockID, stateData, stateData)
 		}
 
 		// Render tree update using ExecuteUpdates (follows LiveTemplate tree-update specification)
 		var buf bytes.Buffer
 		if err := instance.template.ExecuteUpdates(&buf, stateData); err != nil {
-			log.Printf("[WS] Failed to render initial state for %s: %v", instance.blockID, err)
+			wsLog.Errorf("Failed to render initial state for %s: %v", instance.blockID, err)
 			return
 		}
 
@@ -578,7 +836,7 @@ func (h *WebSocketHandler) sendInitialState(instance *BlockInstance) {
 func (h *WebSocketHandler) handleMessage(conn *websocket.Conn, message []byte) {
 	var envelope MessageEnvelope
 	if err := json.Unmarshal(message, &envelope); err != nil {
-		log.Printf("[WS] Failed to parse message: %v", err)
+		wsLog.Errorf("Failed to parse message: %v", err)
 		return
 	}
 
@@ -587,13 +845,17 @@ func (h *WebSocketHandler) handleMessage(conn *websocket.Conn, message []byte) {
 	h.mu.RUnlock()
 
 	if !ok {
-		log.Printf("[WS] Unknown block ID: %s", envelope.BlockID)
+		wsLog.Warnf("Unknown block ID: %s", envelope.BlockID)
 		return
 	}
 
 	// Handle action
 	if err := h.handleAction(instance, envelope.Action, envelope.Data); err != nil {
-		log.Printf("[WS] Error handling action: %v", err)
+		wsLog.Errorf("Error handling action: %v", err)
+		// Tell the client, so it can undo optimistic updates and fire lvt:error,
+		// then re-render so the block shows the server's state again
+		h.sendActionError(instance, envelope.Action, err)
+		h.sendUpdate(instance)
 		return
 	}
 
@@ -623,11 +885,22 @@ func (h *WebSocketHandler) handleMessage(conn *websocket.Conn, message []byte) {
 	}
 }
 
+// sendActionError reports a failed action to the client for its block.
+func (h *WebSocketHandler) sendActionError(instance *BlockInstance, action string, err error) {
+	data, _ := json.Marshal(map[string]string{"action": action, "message": err.Error()})
+	h.sendMessage(instance.conn, MessageEnvelope{
+		BlockID: instance.blockID,
+		Action:  "error",
+		Data:    data,
+	})
+}
+
 // refreshDependentComputedSources finds computed source blocks whose parent
-// matches the modified block's source, refreshes their data, and sends updates.
+// is one of the modified block's sources, refreshes their data, and sends
+// updates. Blocks bound to several sources (lvt-source="a,b") count as each.
 func (h *WebSocketHandler) refreshDependentComputedSources(modified *BlockInstance, conn *websocket.Conn) {
-	// Get the source name of the modified block
-	modifiedSource := ""
+	// Get the source names of the modified block
+	modifiedSources := make(map[string]bool)
 	h.mu.RLock()
 	for _, sb := range h.page.ServerBlocks {
 		if sb.Metadata["lvt-source"] != "" {
@@ -635,7 +908,9 @@ func (h *WebSocketHandler) refreshDependentComputedSources(modified *BlockInstan
 			for _, ib := range h.page.InteractiveBlocks {
 				if ib.StateRef == sb.ID {
 					if inst, ok := h.instances[ib.ID]; ok && inst == modified {
-						modifiedSource = sb.Metadata["lvt-source"]
+						for _, name := range tinkerdown.SourceNames(sb.Metadata["lvt-source"]) {
+							modifiedSources[name] = true
+						}
 					}
 				}
 			}
@@ -643,27 +918,29 @@ func (h *WebSocketHandler) refreshDependentComputedSources(modified *BlockInstan
 	}
 	h.mu.RUnlock()
 
-	if modifiedSource == "" {
+	if len(modifiedSources) == 0 {
 		return
 	}
 
-	// Find and refresh computed sources that depend on this source
+	// Find and refresh computed sources that depend on these sources
+	type refresh struct {
+		inst   *BlockInstance
+		source string
+	}
 	h.mu.RLock()
-	var toRefresh []*BlockInstance
+	var toRefresh []refresh
 	for _, sb := range h.page.ServerBlocks {
-		srcName := sb.Metadata["lvt-source"]
-		if srcName == "" {
-			continue
-		}
-		// Check if this is a computed source with From == modifiedSource
-		if src, ok := h.page.Config.Sources[srcName]; ok {
-			if src.Type == "computed" && src.From == modifiedSource {
-				// Find the instance for this block
-				for _, ib := range h.page.InteractiveBlocks {
-					if ib.StateRef == sb.ID {
-						if inst, ok := h.instances[ib.ID]; ok {
-							toRefresh = append(toRefresh, inst)
-						}
+		for _, srcName := range tinkerdown.SourceNames(sb.Metadata["lvt-source"]) {
+			// Check if this is a computed source whose parent was modified
+			src, ok := h.getEffectiveSource(srcName)
+			if !ok || src.Type != "computed" || !modifiedSources[src.From] {
+				continue
+			}
+			// Find the instance for this block
+			for _, ib := range h.page.InteractiveBlocks {
+				if ib.StateRef == sb.ID {
+					if inst, ok := h.instances[ib.ID]; ok {
+						toRefresh = append(toRefresh, refresh{inst, srcName})
 					}
 				}
 			}
@@ -672,16 +949,19 @@ func (h *WebSocketHandler) refreshDependentComputedSources(modified *BlockInstan
 	h.mu.RUnlock()
 
 	// Refresh each dependent computed source
-	for _, inst := range toRefresh {
+	for _, r := range toRefresh {
 		// Trigger a Refresh action to re-fetch from parent
 		func() {
-			inst.mu.Lock()
-			defer inst.mu.Unlock()
-			if store, ok := inst.state.(*runtime.GenericState); ok {
+			r.inst.mu.Lock()
+			defer r.inst.mu.Unlock()
+			switch store := r.inst.state.(type) {
+			case *runtime.GenericState:
 				store.HandleAction("Refresh", nil)
+			case *runtime.CombinedState:
+				store.HandleAction("Refresh", map[string]interface{}{"source": r.source})
 			}
 		}()
-		h.sendUpdate(inst)
+		h.sendUpdate(r.inst)
 	}
 }
 
@@ -705,9 +985,7 @@ func (h *WebSocketHandler) handleAction(instance *BlockInstance, action string,
 		return fmt.Errorf("action failed: %w", err)
 	}
 
-	if h.debug {
-		log.Printf("[WS] Executed action %s on block %s", action, instance.blockID)
-	}
+	wsLog.Debugf("Executed action %s on block %s", action, instance.blockID)
 
 	return nil
 }
@@ -733,7 +1011,7 @@ func (h *WebSocketHandler) sendUpdate(instance *BlockInstance) {
 			var err error
 			stateData, err = getter.GetStateAsInterface()
 			if err != nil {
-				log.Printf("[WS] Failed to get state for %s: %v", instance.blockID, err)
+				wsLog.Errorf("Failed to get state for %s: %v", instance.blockID, err)
 				return
 			}
 			// Hydrate datatable structs so template methods work
@@ -747,7 +1025,7 @@ func (h *WebSocketHandler) sendUpdate(instance *BlockInstance) {
 		// ExecuteUpdates returns only changed dynamics after the first render
 		var buf bytes.Buffer
 		if err := instance.template.ExecuteUpdates(&buf, stateData); err != nil {
-			log.Printf("[WS] Failed to render update for %s: %v", instance.blockID, err)
+			wsLog.Errorf("Failed to render update for %s: %v", instance.blockID, err)
 			return
 		}
 
@@ -775,7 +1053,7 @@ func (h *WebSocketHandler) sendUpdate(instance *BlockInstance) {
 func (h *WebSocketHandler) sendMessage(conn *websocket.Conn, envelope MessageEnvelope) {
 	data, err := json.Marshal(envelope)
 	if err != nil {
-		log.Printf("[WS] Failed to marshal response: %v", err)
+		wsLog.Errorf("Failed to marshal response: %v", err)
 		return
 	}
 
@@ -784,46 +1062,38 @@ func (h *WebSocketHandler) sendMessage(conn *websocket.Conn, envelope MessageEnv
 	h.writeMu.Unlock()
 
 	if err != nil {
-		log.Printf("[WS] Failed to send message: %v", err)
+		wsLog.Errorf("Failed to send message: %v", err)
 		return
 	}
 
-	if h.debug {
-		log.Printf("[WS] Sent: %s", data)
-	}
+	wsLog.Debugf("Sent: %s", data)
 }
 
 // evaluateAndSendExpressions evaluates all page expressions and sends updates to the client.
 // This should be called after any block state update.
 func (h *WebSocketHandler) evaluateAndSendExpressions(conn *websocket.Conn) {
 	if h.page.Expressions == nil || len(h.page.Expressions) == 0 {
-		if h.debug {
-			log.Printf("[WS] No expressions to evaluate (expressions=%v)", h.page.Expressions)
-		}
+		wsLog.Debugf("No expressions to evaluate (expressions=%v)", h.page.Expressions)
 		return
 	}
 
-	if h.debug {
-		log.Printf("[WS] Evaluating %d expressions: %v", len(h.page.Expressions), h.page.Expressions)
-	}
+	wsLog.Debugf("Evaluating %d expressions: %v", len(h.page.Expressions), h.page.Expressions)
 
 	// Build evaluation context from all block instances
 	ctx := h.buildEvalContext()
 
-	if h.debug {
-		log.Printf("[WS] Eval context sources: %v", getSourceNames(ctx))
-	}
+	wsLog.Debugf("Eval context sources: %v", getSourceNames(ctx))
 
 	// Evaluate all expressions
 	results := runtime.EvaluateExpressions(h.page.Expressions, ctx)
 
 	if h.debug {
-		log.Printf("[WS] Expression evaluation returned %d results", len(results))
+		wsLog.Debugf("Expression evaluation returned %d results", len(results))
 		for id, result := range results {
 			if result.Error != "" {
-				log.Printf("[WS] Expression %s error: %s", id, result.Error)
+				wsLog.Debugf("Expression %s error: %s", id, result.Error)
 			} else {
-				log.Printf("[WS] Expression %s = %v", id, result.Value)
+				wsLog.Debugf("Expression %s = %v", id, result.Value)
 			}
 		}
 	}
@@ -843,13 +1113,11 @@ func (h *WebSocketHandler) evaluateAndSendExpressions(conn *websocket.Conn) {
 	// Send expression update message
 	exprData, err := json.Marshal(exprValues)
 	if err != nil {
-		log.Printf("[WS] Failed to marshal expression results: %v", err)
+		wsLog.Errorf("Failed to marshal expression results: %v", err)
 		return
 	}
 
-	if h.debug {
-		log.Printf("[WS] Sending expression update: %s", string(exprData))
-	}
+	wsLog.Debugf("Sending expression update: %s", string(exprData))
 
 	response := MessageEnvelope{
 		BlockID: ExpressionsBlockID, // Special block ID for expressions
@@ -859,9 +1127,7 @@ func (h *WebSocketHandler) evaluateAndSendExpressions(conn *websocket.Conn) {
 
 	h.sendMessage(conn, response)
 
-	if h.debug {
-		log.Printf("[WS] Expression update sent successfully")
-	}
+	wsLog.Debugf("Expression update sent successfully")
 }
 
 // buildEvalContext builds an evaluation context from all block instances.
@@ -998,6 +1264,10 @@ func extractExecMeta(stateData interface{}) *ExecMeta {
 		meta.Command = command
 	}
 
+	if msg, ok := stateMap["ConfirmMessage"].(string); ok {
+		meta.ConfirmMessage = msg
+	}
+
 	return meta
 }
 
@@ -1077,17 +1347,17 @@ func hydrateDataTable(tableData map[string]interface{}) *datatable.DataTable {
 	// Re-serialize and deserialize to get proper types
 	tableJSON, err := json.Marshal(tableData)
 	if err != nil {
-		log.Printf("[WS] hydrateDataTable: marshal error: %v", err)
+		wsLog.Errorf("hydrateDataTable: marshal error: %v", err)
 		return nil
 	}
 
 	var dt datatable.DataTable
 	if err := json.Unmarshal(tableJSON, &dt); err != nil {
-		log.Printf("[WS] hydrateDataTable: unmarshal error: %v", err)
+		wsLog.Errorf("hydrateDataTable: unmarshal error: %v", err)
 		return nil
 	}
 
-	log.Printf("[WS] hydrateDataTable: success, ID=%s, Columns=%d, Rows=%d",
+	wsLog.Debugf("hydrateDataTable: success, ID=%s, Columns=%d, Rows=%d",
 		dt.ID(), len(dt.Columns), len(dt.Rows))
 
 	return &dt
@@ -1137,9 +1407,7 @@ func (h *WebSocketHandler) RefreshSourcesForFile(filePath string) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	if h.debug {
-		log.Printf("[WS] RefreshSourcesForFile called for: %s", filePath)
-	}
+	wsLog.Debugf("RefreshSourcesForFile called for: %s", filePath)
 
 	// Find all server blocks that use this file
 	// Note: sourceFiles uses server block IDs (e.g., auto-persist-lvt-0)
@@ -1148,40 +1416,36 @@ func (h *WebSocketHandler) RefreshSourcesForFile(filePath string) {
 	for serverBlockID, files := range h.sourceFiles {
 		for _, sourceFile := range files {
 			if sourceFile == filePath {
-				if h.debug {
-					log.Printf("[WS] Server block %s uses file %s, looking for matching instance", serverBlockID, filePath)
-				}
+				wsLog.Debugf("Server block %s uses file %s, looking for matching instance", serverBlockID, filePath)
 
 				// Find the instance whose StateRef matches this server block ID
 				var instance *BlockInstance
 				for _, block := range h.page.InteractiveBlocks {
 					if block.StateRef == serverBlockID {
 						instance = h.instances[block.ID]
-						if h.debug && instance != nil {
-							log.Printf("[WS] Found instance %s for server block %s", block.ID, serverBlockID)
+						if instance != nil {
+							wsLog.Debugf("Found instance %s for server block %s", block.ID, serverBlockID)
 						}
 						break
 					}
 				}
 
 				if instance == nil {
-					log.Printf("[WS] No instance found for server block %s", serverBlockID)
+					wsLog.Warnf("No instance found for server block %s", serverBlockID)
 					continue
 				}
 
 				// Trigger a Refresh action on the source
 				// This re-fetches data from the markdown file
 				if err := h.handleAction(instance, "Refresh", nil); err != nil {
-					log.Printf("[WS] Failed to refresh block %s: %v", instance.blockID, err)
+					wsLog.Errorf("Failed to refresh block %s: %v", instance.blockID, err)
 					continue
 				}
 
 				// Send the updated state to the client
 				h.sendUpdate(instance)
 
-				if h.debug {
-					log.Printf("[WS] Successfully refreshed block %s", instance.blockID)
-				}
+				wsLog.Debugf("Successfully refreshed block %s", instance.blockID)
 				break // File matched, no need to check other files for this block
 			}
 		}
@@ -1264,7 +1528,7 @@ func (h *WebSocketHandler) lookupSource(name string) (source.Source, bool) {
 
 	src, err := createSourceForAction(name, srcCfg, h.rootDir, currentFile)
 	if err != nil {
-		log.Printf("[WS] Failed to create source %s for action: %v", name, err)
+		wsLog.Errorf("Failed to create source %s for action: %v", name, err)
 		return nil, false
 	}
 
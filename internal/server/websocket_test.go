@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"errors"
 	"encoding/json"
 	"flag"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
@@ -14,6 +17,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/runtime"
 	"github.com/livetemplate/tinkerdown/internal/source"
 )
 
@@ -1005,3 +1010,548 @@ func TestGoldenCacheMeta(t *testing.T) {
 	}
 	compareGolden(t, "cache_meta", envelope)
 }
+
+// TestEmbeddedLvtBlockIncrements verifies an embed-lvt block runs on the
+// embedding page's WebSocket and that actions reach the embedded source.
+func TestEmbeddedLvtBlockIncrements(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md": "---\ntitle: Home\n---\n# Home\n\n```embed-lvt page=\"_widgets/counter.md\" block=\"counter\"\n```\n",
+		"_widgets/counter.md": "---\nsources:\n  clicks:\n    type: markdown\n    anchor: \"#clicks\"\n    readonly: false\n---\n" +
+			"# Counter\n\n## Clicks\n\n- click\n\n```lvt id=\"counter\"\n<div lvt-source=\"clicks\"><p>Count: {{len .Data}}</p></div>\n```\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+
+	// A read timeout breaks the connection, so read exactly the expected messages
+	initial, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive initial tree: %v", err)
+	}
+	if !isTreeFor(initial, "embed-0", `"1"`) {
+		t.Fatalf("expected initial tree for embed-0 with count 1, got %+v", initial)
+	}
+
+	client.send(MessageEnvelope{BlockID: "embed-0", Action: "add", Data: json.RawMessage(`{"text":"click"}`)})
+	update, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive update: %v", err)
+	}
+	if !isTreeFor(update, "embed-0", `"2"`) {
+		t.Fatalf("expected updated tree for embed-0 with count 2, got %+v", update)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "_widgets", "counter.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(content), "- click"); n != 2 {
+		t.Errorf("embedded source file has %d items, want 2:\n%s", n, content)
+	}
+}
+
+// TestCombinedBlockRefreshesComputedSource verifies that an action on a block
+// bound to several sources refreshes computed sources derived from them.
+func TestCombinedBlockRefreshesComputedSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	page := "---\nsources:\n  clicks:\n    type: markdown\n    file: data.md\n    anchor: \"#clicks\"\n    readonly: false\n" +
+		"  notes:\n    type: markdown\n    file: data.md\n    anchor: \"#notes\"\n" +
+		"  summary:\n    type: computed\n    from: clicks\n    aggregate:\n      total: count()\n---\n# Home\n\n" +
+		"```lvt id=\"both\"\n<div lvt-source=\"clicks,notes\"><p>Clicks: {{len .Clicks.Data}}</p></div>\n```\n\n" +
+		"```lvt id=\"summary\"\n<div lvt-source=\"summary\">{{range .Data}}<p>Total: {{.total}}</p>{{end}}</div>\n```\n"
+	data := "# Data\n\n## Clicks\n\n- click\n\n## Notes\n\n- note\n"
+	for name, content := range map[string]string{"index.md": page, "data.md": data} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+	trees := receiveTrees(t, client, 2)
+	var combinedID, summaryID string
+	for id, tree := range trees {
+		if strings.Contains(tree, "Clicks") {
+			combinedID = id
+		} else {
+			summaryID = id
+		}
+	}
+
+	client.send(MessageEnvelope{BlockID: combinedID, Action: "add", Data: json.RawMessage(`{"source":"clicks","text":"click"}`)})
+	updates := receiveTrees(t, client, 2)
+	if tree := updates[summaryID]; !strings.Contains(tree, `"2"`) {
+		t.Errorf("summary tree after the action = %q, want total 2", tree)
+	}
+}
+
+func TestFailedActionSendsErrorThenTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md": "---\nsources:\n  tasks:\n    type: markdown\n    file: tasks.md\n    anchor: \"#tasks\"\n    readonly: true\n---\n# Todo\n\n" +
+			"```lvt id=\"tasks\"\n<ul lvt-source=\"tasks\">{{range .Data}}<li>{{.Text}}</li>{{end}}</ul>\n```\n",
+		"tasks.md": "# Tasks\n\n## Tasks\n\n- [ ] Write docs <!-- id:t1 -->\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+
+	initial, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive initial tree: %v", err)
+	}
+	if !isTreeFor(initial, "tasks", "Write docs") {
+		t.Fatalf("expected initial tree for tasks, got %+v", initial)
+	}
+
+	// The client rolls back optimistic updates on the error, then renders the
+	// state from the tree that follows it (an empty diff, as nothing changed).
+	client.send(MessageEnvelope{BlockID: "tasks", Action: "Toggle", Data: json.RawMessage(`{"id":"t1"}`)})
+	errMsg, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive error: %v", err)
+	}
+	if errMsg.BlockID != "tasks" || errMsg.Action != "error" {
+		t.Fatalf("expected error for tasks, got %+v", errMsg)
+	}
+	var data struct {
+		Action  string `json:"action"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(errMsg.Data, &data); err != nil {
+		t.Fatalf("error data %s: %v", errMsg.Data, err)
+	}
+	if data.Action != "Toggle" || data.Message == "" {
+		t.Errorf("error data = %+v, want the Toggle action and a message", data)
+	}
+
+	update, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive tree after error: %v", err)
+	}
+	if update.BlockID != "tasks" || update.Action != "tree" {
+		t.Errorf("expected tree for tasks after the error, got %+v", update)
+	}
+}
+
+func TestFrontmatterFieldRulesRejectAdd(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"index.md": "---\nsources:\n  tasks:\n    type: markdown\n    file: tasks.md\n    anchor: \"#tasks\"\n    readonly: false\n" +
+			"    fields:\n      text:\n        required: true\n---\n# Todo\n\n" +
+			"```lvt id=\"tasks\"\n<ul lvt-source=\"tasks\">{{if .Error}}<p>{{.Error}}</p>{{end}}{{range .Data}}<li>{{.Text}}</li>{{end}}</ul>\n```\n",
+		"tasks.md": "# Tasks\n\n## Tasks\n\n- [ ] Write docs <!-- id:t1 -->\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+
+	if _, err := client.receive(); err != nil {
+		t.Fatalf("receive initial tree: %v", err)
+	}
+
+	client.send(MessageEnvelope{BlockID: "tasks", Action: "Add", Data: json.RawMessage(`{"text":""}`)})
+	errMsg, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive error: %v", err)
+	}
+	if errMsg.Action != "error" || !strings.Contains(string(errMsg.Data), "text is required") {
+		t.Fatalf("expected a validation error, got %+v", errMsg)
+	}
+	update, err := client.receive()
+	if err != nil {
+		t.Fatalf("receive tree after error: %v", err)
+	}
+	if !isTreeFor(update, "tasks", "text is required") {
+		t.Errorf("expected tree showing the validation error, got %+v", update)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "tasks.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != files["tasks.md"] {
+		t.Errorf("rejected Add changed the source file:\n%s", content)
+	}
+}
+
+// isTreeFor reports whether msg is a tree update for blockID whose data includes want.
+func isTreeFor(msg MessageEnvelope, blockID, want string) bool {
+	return msg.BlockID == blockID && msg.Action == "tree" && strings.Contains(string(msg.Data), want)
+}
+
+func TestNewBlockTemplateReportsSyntaxErrors(t *testing.T) {
+	if _, err := newBlockTemplate("lvt-0", "users", `<ul>{{range .Data}}<li>{{.Name}}</li>{{end}}</ul>`); err != nil {
+		t.Fatalf("newBlockTemplate() error = %v, want nil for a valid template", err)
+	}
+
+	// Unclosed action, as a broken auto-generated template would produce
+	broken := "<ul>\n{{range .Data}}\n<li>{{.Name}</li>\n{{end}}\n</ul>"
+	_, err := newBlockTemplate("lvt-0", "users", broken)
+	if err == nil {
+		t.Fatal("newBlockTemplate() error = nil, want a syntax error")
+	}
+	msg := err.Error()
+	for _, want := range []string{`invalid template in block lvt-0 (lvt-source "users")`, "lvt-0:3", "bad character"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q does not contain %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, ".tmpl") {
+		t.Errorf("error %q mentions the temp file", msg)
+	}
+}
+
+func TestCachedBlockTemplate(t *testing.T) {
+	content := `<ul>{{range .Data}}<li>{{.Name}}</li>{{end}}</ul>`
+	key := blockTemplateKey("lvt-cache-test", content)
+
+	first, err := cachedBlockTemplate("lvt-cache-test", "users", content)
+	if err != nil {
+		t.Fatalf("cachedBlockTemplate() error = %v", err)
+	}
+	second, err := cachedBlockTemplate("lvt-cache-test", "users", content)
+	if err != nil {
+		t.Fatalf("cachedBlockTemplate() error = %v", err)
+	}
+	if first == second {
+		t.Error("cachedBlockTemplate() returned the same instance twice, want a copy per connection")
+	}
+
+	blockTemplatesMu.Lock()
+	cached := blockTemplates[key]
+	blockTemplatesMu.Unlock()
+	if cached == nil || cached == first || cached == second {
+		t.Errorf("cached template = %p, want a shared template distinct from the copies", cached)
+	}
+
+	// Changed content builds a new template
+	if _, err := cachedBlockTemplate("lvt-cache-test", "users", content+"<p></p>"); err != nil {
+		t.Fatalf("cachedBlockTemplate() error = %v", err)
+	}
+	blockTemplatesMu.Lock()
+	_, ok := blockTemplates[blockTemplateKey("lvt-cache-test", content+"<p></p>")]
+	blockTemplatesMu.Unlock()
+	if !ok {
+		t.Error("changed content was not cached under its own key")
+	}
+}
+
+// closeRecorder is a Store that records whether it was closed.
+type closeRecorder struct {
+	closed atomic.Bool
+}
+
+func (c *closeRecorder) HandleAction(action string, data map[string]interface{}) error { return nil }
+func (c *closeRecorder) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestServerCloseShutsDownConnections(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte("# Home\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+
+	// Wait for the server to register the connection, then give its
+	// handler a block whose source must be closed on shutdown
+	var handler *WebSocketHandler
+	for deadline := time.Now().Add(time.Second); handler == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		srv.connMu.RLock()
+		for _, h := range srv.connections {
+			handler = h
+		}
+		srv.connMu.RUnlock()
+	}
+	if handler == nil {
+		t.Fatal("connection was not registered")
+	}
+	store := &closeRecorder{}
+	handler.mu.Lock()
+	handler.instances["lvt-0"] = &BlockInstance{blockID: "lvt-0", state: store}
+	handler.mu.Unlock()
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if !store.closed.Load() {
+		t.Error("block source was not closed")
+	}
+	_, err := client.receive()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("receive() error = %v, want a going-away close frame", err)
+	}
+}
+
+// waitForConnections waits until the server tracks want connections.
+func waitForConnections(t *testing.T, srv *Server, want int, timeout time.Duration) {
+	t.Helper()
+	var got int
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		srv.connMu.RLock()
+		got = len(srv.connections)
+		srv.connMu.RUnlock()
+		if got == want {
+			return
+		}
+	}
+	t.Fatalf("server has %d connections, want %d", got, want)
+}
+
+func TestIdleConnectionsAreReaped(t *testing.T) {
+	srv := newTestServer(t, map[string]string{"index.md": "# Home\n"}, func(cfg *config.Config) {
+		cfg.Server = config.ServerConfig{IdleTimeout: "200ms"}
+	})
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	// A client that reads answers pings; one that never reads doesn't.
+	active := newWSTestClient(t, ts)
+	defer active.close()
+	go func() {
+		for {
+			if _, _, err := active.conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	silent := newWSTestClient(t, ts)
+	defer silent.close()
+	waitForConnections(t, srv, 2, time.Second)
+
+	waitForConnections(t, srv, 1, 2*time.Second)
+	time.Sleep(500 * time.Millisecond) // Several idle timeouts
+	srv.connMu.RLock()
+	defer srv.connMu.RUnlock()
+	if len(srv.connections) != 1 {
+		t.Fatalf("server has %d connections, want only the active one", len(srv.connections))
+	}
+	for conn := range srv.connections {
+		if conn.RemoteAddr().String() != active.conn.LocalAddr().String() {
+			t.Error("the active connection was dropped instead of the silent one")
+		}
+	}
+}
+
+func TestMaxConnections(t *testing.T) {
+	srv := newTestServer(t, map[string]string{"index.md": "# Home\n"}, func(cfg *config.Config) {
+		cfg.Server = config.ServerConfig{MaxConnections: 1}
+	})
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	first := newWSTestClient(t, ts)
+	waitForConnections(t, srv, 1, time.Second)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("second connection was accepted, want it rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second connection response = %v, want 503", resp)
+	}
+
+	// Closing the first connection frees its slot
+	first.close()
+	waitForConnections(t, srv, 0, time.Second)
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("connection after a slot freed up: %v", err)
+	}
+	conn.Close()
+}
+
+func TestReserveConnectionConcurrent(t *testing.T) {
+	srv := New(t.TempDir())
+	srv.config.Server.MaxConnections = 5
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if srv.reserveConnection() {
+				reserved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := reserved.Load(); n != 5 {
+		t.Fatalf("reserved %d slots, want 5", n)
+	}
+
+	srv.releaseConnection()
+	if !srv.reserveConnection() {
+		t.Error("a released slot should be reservable again")
+	}
+	if srv.reserveConnection() {
+		t.Error("reserved a slot beyond the limit")
+	}
+}
+
+// newSlowSourcesServer serves a page whose blocks each read their own REST
+// source, answered after delay.
+func newSlowSourcesServer(t *testing.T, blocks int, delay time.Duration) *httptest.Server {
+	t.Helper()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name":"a"},{"name":"b"}]`))
+	}))
+	t.Cleanup(api.Close)
+
+	var sources, body strings.Builder
+	for i := 0; i < blocks; i++ {
+		fmt.Fprintf(&sources, "  api%d:\n    type: rest\n    from: %s/items/%d\n", i, api.URL, i)
+		fmt.Fprintf(&body, "```lvt id=\"block%d\"\n<div lvt-source=\"api%d\"><p>Count: {{len .Data}}</p></div>\n```\n\n", i, i)
+	}
+	page := "---\nsources:\n" + sources.String() + "---\n# Home\n\n" + body.String()
+	ts := httptest.NewServer(newTestServer(t, map[string]string{"index.md": page}, nil))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// receiveTrees reads the initial trees of n blocks, by block ID.
+func receiveTrees(t *testing.T, client *wsTestClient, n int) map[string]string {
+	t.Helper()
+	trees := make(map[string]string)
+	for len(trees) < n {
+		msg, err := client.receive()
+		if err != nil {
+			t.Fatalf("receive initial trees (got %d of %d): %v", len(trees), n, err)
+		}
+		if msg.Action == "tree" {
+			trees[msg.BlockID] = string(msg.Data)
+		}
+	}
+	return trees
+}
+
+func TestSlowSourcesLoadConcurrently(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	ts := newSlowSourcesServer(t, 3, delay)
+
+	start := time.Now()
+	client := newWSTestClient(t, ts)
+	defer client.close()
+	client.timeout = 5 * time.Second
+	trees := receiveTrees(t, client, 3)
+	elapsed := time.Since(start)
+
+	for id, tree := range trees {
+		if !strings.Contains(tree, `"2"`) || strings.Contains(tree, "tinkerdown-block-error") {
+			t.Errorf("%s tree = %s, want count 2", id, tree)
+		}
+	}
+	// Fetched one after another, the sources would take 900ms
+	if elapsed >= 2*delay {
+		t.Errorf("three %s sources loaded in %s, want about %s", delay, elapsed, delay)
+	}
+}
+
+func TestSourcesPastDeadlineRenderErrorCards(t *testing.T) {
+	defer func(d time.Duration) { blockInitDeadline = d }(blockInitDeadline)
+	blockInitDeadline = 100 * time.Millisecond
+	ts := newSlowSourcesServer(t, 2, 500*time.Millisecond)
+
+	client := newWSTestClient(t, ts)
+	defer client.close()
+	for id, tree := range receiveTrees(t, client, 2) {
+		if !strings.Contains(tree, "tinkerdown-block-error") || !strings.Contains(tree, "didn") {
+			t.Errorf("%s tree = %s, want an error card saying the source didn't load", id, tree)
+		}
+	}
+}
+
+func TestPanickingSourceRendersErrorCard(t *testing.T) {
+	ts := newSlowSourcesServer(t, 2, 0)
+	srv := ts.Config.Handler.(*Server)
+	page := srv.routes[0].Page
+
+	// Replace one block's factory with one that panics
+	upgrader := websocket.Upgrader{}
+	panicky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		h := NewWebSocketHandler(page, srv, false, srv.rootDir, srv.config)
+		defer h.Close()
+		h.stateFactories[page.InteractiveBlocks["block0"].StateRef] = func() (runtime.Store, error) { panic("source exploded") }
+		h.initializeInstances(conn)
+		conn.ReadMessage() // Wait for the client to hang up
+	}))
+	defer panicky.Close()
+
+	client := newWSTestClient(t, panicky)
+	defer client.close()
+	trees := receiveTrees(t, client, 2)
+	if tree := trees["block0"]; !strings.Contains(tree, "tinkerdown-block-error") || !strings.Contains(tree, "source exploded") {
+		t.Errorf("block0 tree = %s, want an error card with the panic", tree)
+	}
+	if tree := trees["block1"]; strings.Contains(tree, "tinkerdown-block-error") {
+		t.Errorf("block1 tree = %s, want it loaded", tree)
+	}
+}
@@ -2,9 +2,13 @@ package site
 
 import (
 	"fmt"
+	"html"
 	"io/fs"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/livetemplate/tinkerdown"
 	"github.com/livetemplate/tinkerdown/internal/config"
@@ -22,20 +26,35 @@ type PageNode struct {
 
 // Manager handles multi-page site discovery and navigation
 type Manager struct {
-	rootDir string
-	config  *config.Config
-	pages   map[string]*PageNode // Maps URL path to PageNode
-	nav     []*PageNode          // Navigation tree (top-level nodes)
-	home    *PageNode            // Home page
+	rootDir   string
+	config    *config.Config
+	pages     map[string]*PageNode   // Maps URL path to PageNode
+	nav       []*PageNode            // Navigation tree (top-level nodes)
+	localeNav map[string][]*PageNode // Navigation tree of each locale directory
+	home      *PageNode              // Home page
+	markdown  tinkerdown.MarkdownOptions
 }
 
 // New creates a new site manager
 func New(rootDir string, cfg *config.Config) *Manager {
 	return &Manager{
-		rootDir: rootDir,
-		config:  cfg,
-		pages:   make(map[string]*PageNode),
-		nav:     make([]*PageNode, 0),
+		rootDir:  rootDir,
+		config:   cfg,
+		pages:    make(map[string]*PageNode),
+		nav:      make([]*PageNode, 0),
+		markdown: MarkdownOptions(cfg),
+	}
+}
+
+// MarkdownOptions returns the renderer extensions cfg enables, which the
+// site's pages are parsed with.
+func MarkdownOptions(cfg *config.Config) tinkerdown.MarkdownOptions {
+	return tinkerdown.MarkdownOptions{
+		Footnotes:     cfg.Markdown.IsFootnotesEnabled(),
+		Strikethrough: cfg.Markdown.IsStrikethroughEnabled(),
+		Autolinks:     cfg.Markdown.IsAutolinksEnabled(),
+		TaskLists:     cfg.Markdown.IsTaskListsEnabled(),
+		LineNumbers:   cfg.Markdown.LineNumbers,
 	}
 }
 
@@ -70,7 +89,7 @@ func (m *Manager) discoverFromConfig() error {
 			absPath := filepath.Join(m.rootDir, filePath)
 
 			// Parse the page
-			parsed, err := tinkerdown.ParseFile(absPath)
+			parsed, err := m.markdown.ParseFile(absPath)
 			if err != nil {
 				return fmt.Errorf("failed to parse %s: %w", filePath, err)
 			}
@@ -78,8 +97,17 @@ func (m *Manager) discoverFromConfig() error {
 			// Generate URL path
 			urlPath := mdToURLPath(filePath)
 
+			// Config title wins, then nav_title, then the page's own title
+			title := page.Title
+			if title == "" {
+				title = parsed.NavTitle
+			}
+			if title == "" {
+				title = parsed.Title
+			}
+
 			pageNode := &PageNode{
-				Title:    page.Title,
+				Title:    title,
 				Path:     urlPath,
 				FilePath: filePath,
 				Page:     parsed,
@@ -91,7 +119,10 @@ func (m *Manager) discoverFromConfig() error {
 				m.home = pageNode
 			}
 
-			sectionNode.Children = append(sectionNode.Children, pageNode)
+			// Hidden pages stay routable but are left out of the sidebar
+			if !parsed.NavHidden {
+				sectionNode.Children = append(sectionNode.Children, pageNode)
+			}
 			m.pages[urlPath] = pageNode
 		}
 
@@ -103,7 +134,7 @@ func (m *Manager) discoverFromConfig() error {
 		homePath := m.config.Site.Home
 		absPath := filepath.Join(m.rootDir, homePath)
 
-		parsed, err := tinkerdown.ParseFile(absPath)
+		parsed, err := m.markdown.ParseFile(absPath)
 		if err != nil {
 			return fmt.Errorf("failed to parse home page %s: %w", homePath, err)
 		}
@@ -163,7 +194,7 @@ func (m *Manager) discoverFromFiles() error {
 		}
 
 		// Parse the page
-		parsed, err := tinkerdown.ParseFile(path)
+		parsed, err := m.markdown.ParseFile(path)
 		if err != nil {
 			return fmt.Errorf("failed to parse %s: %w", relPath, err)
 		}
@@ -171,8 +202,11 @@ func (m *Manager) discoverFromFiles() error {
 		// Generate URL path
 		urlPath := mdToURLPath(relPath)
 
-		// Determine title (from frontmatter or filename)
-		title := parsed.Title
+		// Determine title (nav_title override, frontmatter title, or filename)
+		title := parsed.NavTitle
+		if title == "" {
+			title = parsed.Title
+		}
 		if title == "" {
 			// Use filename as title
 			title = strings.TrimSuffix(filepath.Base(relPath), ".md")
@@ -212,21 +246,45 @@ func (m *Manager) discoverFromFiles() error {
 	return nil
 }
 
-// buildNavigationTree organizes flat pages into a hierarchical navigation tree
+// buildNavigationTree organizes flat pages into a hierarchical navigation
+// tree. Pages under a locale directory get a tree of their own, so the
+// sidebar of a French page lists only French pages.
 func (m *Manager) buildNavigationTree() {
+	byLocale := make(map[string][]*PageNode)
+	for _, page := range m.pages {
+		locale := m.config.PathLocale(page.Path)
+		byLocale[locale] = append(byLocale[locale], page)
+	}
+
+	m.nav = m.buildNav(byLocale[""], "")
+	m.localeNav = make(map[string][]*PageNode)
+	for _, l := range m.config.Locales {
+		if pages, ok := byLocale[l.Code]; ok {
+			m.localeNav[l.Code] = m.buildNav(pages, l.Code)
+		}
+	}
+}
+
+// buildNav builds the navigation tree of pages, which all live under the
+// directory root ("" for the site root). The root's index page is its home
+// and stays out of the tree.
+func (m *Manager) buildNav(pages []*PageNode, root string) []*PageNode {
 	// Create a map of directory -> pages
 	sections := make(map[string]*PageNode)
 	topLevel := make([]*PageNode, 0)
 
-	for _, page := range m.pages {
-		// Skip home page
-		if page.IsHome {
+	for _, page := range pages {
+		// Skip home page and pages hidden from navigation (they stay routable)
+		if page.IsHome || (page.Page != nil && page.Page.NavHidden) {
+			continue
+		}
+		if root != "" && page.FilePath == filepath.Join(root, "index.md") {
 			continue
 		}
 
 		// Get directory path
 		dir := filepath.Dir(page.FilePath)
-		if dir == "." {
+		if dir == "." || dir == root {
 			// Top-level page
 			topLevel = append(topLevel, page)
 		} else {
@@ -253,13 +311,53 @@ func (m *Manager) buildNavigationTree() {
 		}
 	}
 
-	// Add sections to nav
+	// Sections and top-level pages share one ordering
 	for _, section := range sections {
-		m.nav = append(m.nav, section)
+		sortNavNodes(section.Children)
+		topLevel = append(topLevel, section)
 	}
+	sortNavNodes(topLevel)
 
-	// Add top-level pages to nav
-	m.nav = append(m.nav, topLevel...)
+	return topLevel
+}
+
+// navOrder returns the nav_order of a page node. A section takes the lowest
+// nav_order among its pages. The second result is false when there is none.
+func navOrder(node *PageNode) (int, bool) {
+	if node.Page != nil {
+		if node.Page.NavOrder == nil {
+			return 0, false
+		}
+		return *node.Page.NavOrder, true
+	}
+
+	order, ok := 0, false
+	for _, child := range node.Children {
+		if o, childOK := navOrder(child); childOK && (!ok || o < order) {
+			order, ok = o, true
+		}
+	}
+	return order, ok
+}
+
+// sortNavNodes orders nodes by nav_order, with unordered nodes after ordered
+// ones. Ties and unordered nodes sort alphabetically by title, then path.
+func sortNavNodes(nodes []*PageNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		oi, iOK := navOrder(nodes[i])
+		oj, jOK := navOrder(nodes[j])
+		if iOK != jOK {
+			return iOK
+		}
+		if iOK && oi != oj {
+			return oi < oj
+		}
+		ti, tj := strings.ToLower(nodes[i].Title), strings.ToLower(nodes[j].Title)
+		if ti != tj {
+			return ti < tj
+		}
+		return nodes[i].Path < nodes[j].Path
+	})
 }
 
 // GetPage returns a page by its URL path
@@ -278,6 +376,15 @@ func (m *Manager) GetNavigation() []*PageNode {
 	return m.nav
 }
 
+// NavigationFor returns the navigation tree urlPath belongs to: its locale's
+// tree for a page under a locale directory, the site's otherwise.
+func (m *Manager) NavigationFor(urlPath string) []*PageNode {
+	if nav, ok := m.localeNav[m.config.PathLocale(urlPath)]; ok {
+		return nav
+	}
+	return m.nav
+}
+
 // AllPages returns all pages (flat list)
 func (m *Manager) AllPages() []*PageNode {
 	pages := make([]*PageNode, 0, len(m.pages))
@@ -287,10 +394,13 @@ func (m *Manager) AllPages() []*PageNode {
 	return pages
 }
 
-// GetPrevNext returns the previous and next pages for navigation
+// GetPrevNext returns the previous and next pages for navigation, in sidebar
+// order. Hidden and directory index pages are skipped; from an index page the
+// neighbors are the nearest regular pages. With site.nav_wrap enabled, the
+// last page links forward to the first and vice versa.
 func (m *Manager) GetPrevNext(currentPath string) (prev, next *PageNode) {
 	// Build a flat ordered list from navigation tree
-	ordered := m.flattenNav(m.nav)
+	ordered := m.flattenNav(m.NavigationFor(currentPath))
 
 	// Find current page index
 	currentIdx := -1
@@ -305,16 +415,45 @@ func (m *Manager) GetPrevNext(currentPath string) (prev, next *PageNode) {
 		return nil, nil
 	}
 
-	if currentIdx > 0 {
-		prev = ordered[currentIdx-1]
+	for i := currentIdx - 1; i >= 0 && prev == nil; i-- {
+		if isPrevNextTarget(ordered[i]) {
+			prev = ordered[i]
+		}
 	}
-	if currentIdx < len(ordered)-1 {
-		next = ordered[currentIdx+1]
+	for i := currentIdx + 1; i < len(ordered) && next == nil; i++ {
+		if isPrevNextTarget(ordered[i]) {
+			next = ordered[i]
+		}
+	}
+
+	if m.config.Site != nil && m.config.Site.NavWrap {
+		var targets []*PageNode
+		for _, page := range ordered {
+			if isPrevNextTarget(page) {
+				targets = append(targets, page)
+			}
+		}
+		if len(targets) > 1 {
+			if prev == nil && targets[len(targets)-1].Path != currentPath {
+				prev = targets[len(targets)-1]
+			}
+			if next == nil && targets[0].Path != currentPath {
+				next = targets[0]
+			}
+		}
 	}
 
 	return prev, next
 }
 
+// isPrevNextTarget reports whether prev/next links may point at page.
+func isPrevNextTarget(page *PageNode) bool {
+	if page.Page != nil && page.Page.NavHidden {
+		return false
+	}
+	return filepath.Base(page.FilePath) != "index.md"
+}
+
 // flattenNav converts navigation tree to flat ordered list
 func (m *Manager) flattenNav(nodes []*PageNode) []*PageNode {
 	result := make([]*PageNode, 0)
@@ -334,13 +473,18 @@ func (m *Manager) flattenNav(nodes []*PageNode) []*PageNode {
 func (m *Manager) GetBreadcrumbs(urlPath string) []*PageNode {
 	breadcrumbs := make([]*PageNode, 0)
 
-	// Always start with home if it exists
-	if m.home != nil {
-		breadcrumbs = append(breadcrumbs, m.home)
+	// Always start with home if it exists (a locale's own home page for
+	// pages under a locale directory)
+	home := m.home
+	if locale := m.config.PathLocale(urlPath); locale != "" {
+		home = m.pages["/"+locale+"/"]
+	}
+	if home != nil {
+		breadcrumbs = append(breadcrumbs, home)
 	}
 
 	// If this is the home page, we're done
-	if urlPath == "/" || urlPath == m.home.Path {
+	if urlPath == "/" || (home != nil && urlPath == home.Path) {
 		return breadcrumbs
 	}
 
@@ -353,7 +497,7 @@ func (m *Manager) GetBreadcrumbs(urlPath string) []*PageNode {
 			// This is a section
 			currentPath += "/" + segment
 			// Try to find a section node
-			for _, navNode := range m.nav {
+			for _, navNode := range m.NavigationFor(urlPath) {
 				if navNode.Path == currentPath {
 					breadcrumbs = append(breadcrumbs, navNode)
 					break
@@ -389,26 +533,82 @@ func (m *Manager) Reload(filePath string) error {
 	}
 
 	// Re-parse the file
-	parsed, err := tinkerdown.ParseFile(filePath)
+	parsed, err := m.markdown.ParseFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to parse %s: %w", relPath, err)
 	}
 
 	// Update the page node
 	pageNode.Page = parsed
-	if parsed.Title != "" {
+	if len(m.config.Navigation) > 0 {
+		if parsed.Title != "" {
+			pageNode.Title = parsed.Title
+		}
+		return nil
+	}
+
+	// Auto-discovered navigation depends on frontmatter, so rebuild it
+	if parsed.NavTitle != "" {
+		pageNode.Title = parsed.NavTitle
+	} else if parsed.Title != "" {
 		pageNode.Title = parsed.Title
 	}
+	m.buildNavigationTree()
 
 	return nil
 }
 
 // SearchEntry represents a single entry in the search index
 type SearchEntry struct {
-	Title   string `json:"title"`
-	Path    string `json:"path"`
-	Content string `json:"content"`
-	Section string `json:"section,omitempty"`
+	Title    string          `json:"title"`
+	Path     string          `json:"path"`
+	Content  string          `json:"content"`
+	Section  string          `json:"section,omitempty"`
+	Headings []SearchHeading `json:"headings,omitempty"`
+}
+
+// SearchHeading is an H2 or H3 heading of a page, which search results link
+// to when the query matches it or its section. Content is the start of the
+// section's text, for result snippets.
+type SearchHeading struct {
+	Text    string `json:"text"`
+	ID      string `json:"id"`
+	Content string `json:"content,omitempty"`
+}
+
+// Search index text limits, in bytes, which keep the index small enough to
+// load with every page: the start of each page, and of each of its sections.
+const (
+	searchContentLength = 500
+	searchSectionLength = 300
+)
+
+// searchHeadingPattern matches the H2 and H3 headings of a page's HTML,
+// which carry their anchor IDs.
+var searchHeadingPattern = regexp.MustCompile(`(?s)<h([23]) id="([^"]+)"[^>]*>(.*?)</h[23]>`)
+
+// extractHeadings returns the H2 and H3 headings of a page in order, each
+// with the text of its section up to the next one.
+func extractHeadings(page *tinkerdown.Page) []SearchHeading {
+	var headings []SearchHeading
+	src := page.StaticHTML
+	matches := searchHeadingPattern.FindAllStringSubmatchIndex(src, -1)
+	for i, m := range matches {
+		text := plainText(src[m[6]:m[7]])
+		if text == "" {
+			continue
+		}
+		end := len(src)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		headings = append(headings, SearchHeading{
+			Text:    text,
+			ID:      html.UnescapeString(src[m[4]:m[5]]),
+			Content: truncateText(plainText(src[m[1]:end]), searchSectionLength),
+		})
+	}
+	return headings
 }
 
 // GenerateSearchIndex creates a search index from all pages
@@ -425,7 +625,7 @@ func (m *Manager) GenerateSearchIndex() []SearchEntry {
 
 		// Find the section this page belongs to
 		section := ""
-		for _, navNode := range m.nav {
+		for _, navNode := range m.NavigationFor(page.Path) {
 			for _, child := range navNode.Children {
 				if child.Path == page.Path {
 					section = navNode.Title
@@ -438,10 +638,11 @@ func (m *Manager) GenerateSearchIndex() []SearchEntry {
 		}
 
 		entries = append(entries, SearchEntry{
-			Title:   page.Title,
-			Path:    page.Path,
-			Content: content,
-			Section: section,
+			Title:    page.Title,
+			Path:     page.Path,
+			Content:  content,
+			Section:  section,
+			Headings: extractHeadings(page.Page),
 		})
 	}
 
@@ -458,10 +659,15 @@ func extractTextContent(page *tinkerdown.Page) string {
 		content.WriteString(" ")
 	}
 
-	// Extract text from HTML content
-	// Simple approach: strip HTML tags
-	htmlContent := page.StaticHTML
+	content.WriteString(plainText(page.StaticHTML))
+
+	// Limit content length for search index
+	return truncateText(content.String(), searchContentLength)
+}
 
+// plainText returns the text of an HTML fragment on one line, without
+// scripts, styles and code blocks, and with entities decoded.
+func plainText(htmlContent string) string {
 	// Remove script and style tags completely
 	htmlContent = removeTagContent(htmlContent, "script")
 	htmlContent = removeTagContent(htmlContent, "style")
@@ -472,23 +678,18 @@ func extractTextContent(page *tinkerdown.Page) string {
 	// Remove all HTML tags but keep the text
 	htmlContent = stripHTMLTags(htmlContent)
 
-	// Remove extra whitespace
-	lines := strings.Split(htmlContent, "\n")
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" {
-			content.WriteString(trimmed)
-			content.WriteString(" ")
-		}
-	}
+	return html.UnescapeString(strings.Join(strings.Fields(htmlContent), " "))
+}
 
-	// Limit content length for search index (first ~500 chars)
-	result := content.String()
-	if len(result) > 500 {
-		result = result[:500]
+// truncateText cuts s to at most n bytes without splitting a character.
+func truncateText(s string, n int) string {
+	if len(s) <= n {
+		return s
 	}
-
-	return result
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
 }
 
 // removeTagContent removes a tag and its content
@@ -0,0 +1,352 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/testutil"
+)
+
+func discover(t *testing.T, dir string, cfg *config.Config) *Manager {
+	t.Helper()
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	m := New(dir, cfg)
+	if err := m.Discover(); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	return m
+}
+
+func navTitles(nodes []*PageNode) []string {
+	titles := make([]string, len(nodes))
+	for i, n := range nodes {
+		titles[i] = n.Title
+	}
+	return titles
+}
+
+func assertTitles(t *testing.T, got []*PageNode, want ...string) {
+	t.Helper()
+	titles := navTitles(got)
+	if len(titles) != len(want) {
+		t.Fatalf("nav = %v, want %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Fatalf("nav = %v, want %v", titles, want)
+		}
+	}
+}
+
+func TestNavigationOrder(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":            "---\ntitle: Home\n---\n# Home\n",
+		"zebra.md":            "---\ntitle: Zebra\nnav_order: 1\n---\n# Zebra\n",
+		"apple.md":            "---\ntitle: Apple\n---\n# Apple\n",
+		"mango.md":            "---\ntitle: Mango\nnav_order: 2\n---\n# Mango\n",
+		"banana.md":           "---\ntitle: Banana\n---\n# Banana\n",
+		"guides/setup.md":     "---\ntitle: Setup\nnav_order: 2\n---\n# Setup\n",
+		"guides/advanced.md":  "---\ntitle: Advanced\n---\n# Advanced\n",
+		"guides/overview.md":  "---\ntitle: Overview\nnav_order: 1\n---\n# Overview\n",
+		"reference/config.md": "---\ntitle: Config\n---\n# Config\n",
+	})
+
+	for i := 0; i < 5; i++ {
+		m := discover(t, dir, nil)
+		nav := m.GetNavigation()
+
+		// Ordered pages first (guides takes its lowest page order, 1, tying
+		// with Zebra and sorting by title), then unordered alphabetically.
+		assertTitles(t, nav, "Guides", "Zebra", "Mango", "Apple", "Banana", "Reference")
+		assertTitles(t, nav[0].Children, "Overview", "Setup", "Advanced")
+	}
+}
+
+func TestNavigationHidden(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":       "---\ntitle: Home\n---\n# Home\n",
+		"visible.md":     "---\ntitle: Visible\n---\n# Visible\n",
+		"secret.md":      "---\ntitle: Secret\nnav_hidden: true\n---\n# Secret\n",
+		"docs/intro.md":  "---\ntitle: Intro\n---\n# Intro\n",
+		"docs/drafts.md": "---\ntitle: Drafts\nnav_hidden: true\n---\n# Drafts\n",
+	})
+
+	m := discover(t, dir, nil)
+	nav := m.GetNavigation()
+	assertTitles(t, nav, "Docs", "Visible")
+	assertTitles(t, nav[0].Children, "Intro")
+
+	// Hidden pages are still routable
+	for _, path := range []string{"/secret", "/docs/drafts"} {
+		if _, ok := m.GetPage(path); !ok {
+			t.Errorf("GetPage(%q) not found, hidden pages should stay routable", path)
+		}
+	}
+
+	// ...but are skipped by prev/next
+	if _, next := m.GetPrevNext("/docs/intro"); next == nil || next.Title != "Visible" {
+		t.Errorf("GetPrevNext(/docs/intro) next = %v, want Visible", next)
+	}
+}
+
+func TestNavigationTitleOverride(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":   "---\ntitle: Home\n---\n# Home\n",
+		"install.md": "---\ntitle: Installing Tinkerdown on Your Machine\nnav_title: Install\n---\n# Install\n",
+	})
+
+	m := discover(t, dir, nil)
+	assertTitles(t, m.GetNavigation(), "Install")
+
+	page, _ := m.GetPage("/install")
+	if page.Page.Title != "Installing Tinkerdown on Your Machine" {
+		t.Errorf("page title = %q, nav_title should only change the nav label", page.Page.Title)
+	}
+
+	// Config navigation titles take precedence over nav_title
+	cfg := config.DefaultConfig()
+	cfg.Navigation = []config.NavSection{
+		{Title: "Start", Path: "start", Pages: []config.NavPage{
+			{Path: "install.md"},
+			{Title: "Welcome", Path: "index.md"},
+		}},
+	}
+	m = discover(t, dir, cfg)
+	assertTitles(t, m.GetNavigation()[0].Children, "Install", "Welcome")
+}
+
+func TestNavigationReload(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md": "---\ntitle: Home\n---\n# Home\n",
+		"a.md":     "---\ntitle: A\n---\n# A\n",
+		"b.md":     "---\ntitle: B\n---\n# B\n",
+	})
+
+	m := discover(t, dir, nil)
+	assertTitles(t, m.GetNavigation(), "A", "B")
+
+	path := filepath.Join(dir, "b.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: B\nnav_order: 1\nnav_title: First\n---\n# B\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(path); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	assertTitles(t, m.GetNavigation(), "First", "A")
+}
+
+func TestPrevNextSkipsHiddenAndIndexPages(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":         "---\ntitle: Home\n---\n# Home\n",
+		"guides/index.md":  "---\ntitle: Guides\nnav_order: 1\n---\n# Guides\n",
+		"guides/intro.md":  "---\ntitle: Intro\nnav_order: 2\n---\n# Intro\n",
+		"guides/secret.md": "---\ntitle: Secret\nnav_order: 3\nnav_hidden: true\n---\n# Secret\n",
+		"guides/setup.md":  "---\ntitle: Setup\nnav_order: 4\n---\n# Setup\n",
+		"reference.md":     "---\ntitle: Reference\nnav_order: 5\n---\n# Reference\n",
+	})
+	m := discover(t, dir, nil)
+
+	tests := []struct {
+		path       string
+		prev, next string
+	}{
+		{"/guides/intro", "", "Setup"},          // index page before it is skipped
+		{"/guides/setup", "Intro", "Reference"}, // hidden page between is skipped
+		{"/reference", "Setup", ""},
+		{"/guides/", "", "Intro"}, // index pages still get neighbors
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			prev, next := m.GetPrevNext(tt.path)
+			if got := titleOf(prev); got != tt.prev {
+				t.Errorf("prev = %q, want %q", got, tt.prev)
+			}
+			if got := titleOf(next); got != tt.next {
+				t.Errorf("next = %q, want %q", got, tt.next)
+			}
+		})
+	}
+}
+
+func TestPrevNextWrap(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md": "---\ntitle: Home\n---\n# Home\n",
+		"a.md":     "---\ntitle: A\nnav_order: 1\n---\n# A\n",
+		"b.md":     "---\ntitle: B\nnav_order: 2\n---\n# B\n",
+		"c.md":     "---\ntitle: C\nnav_order: 3\n---\n# C\n",
+	})
+
+	// Default: no wrap-around
+	m := discover(t, dir, nil)
+	if prev, _ := m.GetPrevNext("/a"); prev != nil {
+		t.Errorf("prev of first page = %q, want none", prev.Title)
+	}
+	if _, next := m.GetPrevNext("/c"); next != nil {
+		t.Errorf("next of last page = %q, want none", next.Title)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Site = &config.SiteConfig{NavWrap: true}
+	m = discover(t, dir, cfg)
+	if prev, next := m.GetPrevNext("/a"); titleOf(prev) != "C" || titleOf(next) != "B" {
+		t.Errorf("GetPrevNext(/a) = %q, %q, want C, B", titleOf(prev), titleOf(next))
+	}
+	if prev, next := m.GetPrevNext("/c"); titleOf(prev) != "B" || titleOf(next) != "A" {
+		t.Errorf("GetPrevNext(/c) = %q, %q, want B, A", titleOf(prev), titleOf(next))
+	}
+}
+
+func titleOf(node *PageNode) string {
+	if node == nil {
+		return ""
+	}
+	return node.Title
+}
+
+func TestLocaleNavigation(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"en/index.md":        "---\ntitle: Home\n---\n# Home\n",
+		"en/intro.md":        "---\ntitle: Intro\nnav_order: 1\n---\n# Intro\n",
+		"en/setup.md":        "---\ntitle: Setup\nnav_order: 2\n---\n# Setup\n",
+		"en/guides/first.md": "---\ntitle: First Steps\n---\n# First Steps\n",
+		"fr/index.md":        "---\ntitle: Accueil\n---\n# Accueil\n",
+		"fr/intro.md":        "---\ntitle: Introduction\n---\n# Introduction\n",
+	})
+	cfg := config.DefaultConfig()
+	cfg.Locales = []config.LocaleConfig{{Code: "en"}, {Code: "fr"}}
+
+	m := discover(t, dir, cfg)
+	for _, path := range []string{"/en/", "/en/intro", "/en/guides/first", "/fr/", "/fr/intro"} {
+		if _, ok := m.GetPage(path); !ok {
+			t.Errorf("GetPage(%q) not found", path)
+		}
+	}
+
+	// Each locale's pages form their own tree, rooted at its directory
+	en := m.NavigationFor("/en/intro")
+	assertTitles(t, en, "Intro", "Setup", "Guides")
+	if en[2].Path != "/en/guides" {
+		t.Errorf("section path = %q, want /en/guides", en[2].Path)
+	}
+	assertTitles(t, m.NavigationFor("/fr/"), "Introduction")
+	if len(m.GetNavigation()) != 0 {
+		t.Errorf("site nav = %v, locale pages belong to their locale's tree", navTitles(m.GetNavigation()))
+	}
+
+	// Prev/next stays within the locale
+	if prev, next := m.GetPrevNext("/fr/intro"); prev != nil || next != nil {
+		t.Errorf("GetPrevNext(/fr/intro) = %v, %v, want no neighbors", prev, next)
+	}
+	if _, next := m.GetPrevNext("/en/intro"); next == nil || next.Path != "/en/setup" {
+		t.Errorf("GetPrevNext(/en/intro) next = %v, want /en/setup", next)
+	}
+}
+
+func TestSearchIndexHeadings(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":  "# Home\n\nWelcome.\n",
+		"deploy.md": "---\ntitle: Deploying\n---\n# Deploying\n\n## Build & ship\n\nText.\n\n### With `Docker`\n\nMore.\n\n#### Too deep\n",
+	})
+	m := discover(t, dir, nil)
+
+	var entry *SearchEntry
+	for _, e := range m.GenerateSearchIndex() {
+		if e.Path == "/deploy" {
+			entry = &e
+		}
+	}
+	if entry == nil {
+		t.Fatal("search index has no /deploy entry")
+	}
+	want := []SearchHeading{
+		{Text: "Build & ship", ID: "build--ship", Content: "Text."},
+		{Text: "With Docker", ID: "with-docker", Content: "More. Too deep"},
+	}
+	if len(entry.Headings) != len(want) {
+		t.Fatalf("headings = %+v, want %+v", entry.Headings, want)
+	}
+	for i := range want {
+		if entry.Headings[i] != want[i] {
+			t.Errorf("heading %d = %+v, want %+v", i, entry.Headings[i], want[i])
+		}
+	}
+}
+
+func TestSearchIndexSectionText(t *testing.T) {
+	long := strings.Repeat("é", searchSectionLength)
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md": "# Home\n\nIntro about <b>widgets</b> &amp; gadgets.\n\n" +
+			"## Install\n\nRun the installer, then restart.\n\n```sh\nmake install\n```\n\n" +
+			"## Long\n\n" + long + "\n",
+	})
+	m := discover(t, dir, nil)
+
+	index := m.GenerateSearchIndex()
+	if len(index) != 1 {
+		t.Fatalf("search index has %d entries, want 1", len(index))
+	}
+	entry := index[0]
+	if !strings.Contains(entry.Content, "Intro about widgets & gadgets.") {
+		t.Errorf("content = %q, want the intro as plain text", entry.Content)
+	}
+
+	sections := make(map[string]string)
+	for _, h := range entry.Headings {
+		sections[h.ID] = h.Content
+	}
+	if got := sections["install"]; got != "Run the installer, then restart." {
+		t.Errorf("install section = %q, want its text without the code block", got)
+	}
+	got := sections["long"]
+	if len(got) > searchSectionLength || !utf8.ValidString(got) || !strings.HasPrefix(long, got) {
+		t.Errorf("long section = %d bytes, want at most %d of whole characters", len(got), searchSectionLength)
+	}
+}
+
+func TestTags(t *testing.T) {
+	dir := testutil.WriteFiles(t, map[string]string{
+		"index.md":       "# Home\n",
+		"guides/a.md":    "---\ntitle: Beta\ntags: [Go, testing]\n---\n# Beta\n",
+		"guides/b.md":    "---\ntitle: Alpha\ntags: [go, Deploy Guides]\n---\n# Alpha\n",
+		"reference/c.md": "---\ntitle: Gamma\ntags: [testing, testing, '!!']\n---\n# Gamma\n",
+	})
+	m := discover(t, dir, nil)
+
+	got := make(map[string][]string)
+	names := make(map[string]string)
+	var slugs []string
+	for _, tag := range m.Tags() {
+		slugs = append(slugs, tag.Slug)
+		names[tag.Slug] = tag.Name
+		for _, page := range tag.Pages {
+			got[tag.Slug] = append(got[tag.Slug], page.Path)
+		}
+	}
+
+	if want := []string{"deploy-guides", "go", "testing"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("tag slugs = %v, want %v", slugs, want)
+	}
+	want := map[string][]string{
+		"deploy-guides": {"/guides/b"},
+		// Case-insensitive, sorted by title
+		"go":      {"/guides/b", "/guides/a"},
+		"testing": {"/guides/a", "/reference/c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tag pages = %v, want %v", got, want)
+	}
+	if names["go"] != "Go" || names["deploy-guides"] != "Deploy Guides" {
+		t.Errorf("tag names = %v, want the first spelling of each", names)
+	}
+
+	if _, ok := m.Tag("missing"); ok {
+		t.Error("Tag(missing) found a tag no page carries")
+	}
+}
@@ -0,0 +1,88 @@
+package site
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tag is a tag from page frontmatter with the pages carrying it.
+type Tag struct {
+	Name  string      // As first written in frontmatter
+	Slug  string      // URL segment of its index page, /tags/<slug>
+	Pages []*PageNode // Sorted by title
+}
+
+// TagSlug returns the URL segment of a tag: lowercase letters and digits,
+// with each run of other characters as a single dash. Tags differing only
+// in case or punctuation share a slug, and so an index page.
+func TagSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Tags returns the site's tags sorted by slug. Tags without letters or
+// digits are ignored.
+func (m *Manager) Tags() []Tag {
+	paths := make([]string, 0, len(m.pages))
+	for path := range m.pages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	bySlug := make(map[string]*Tag)
+	for _, path := range paths {
+		node := m.pages[path]
+		if node.Page == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, name := range node.Page.Tags {
+			slug := TagSlug(name)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			tag, ok := bySlug[slug]
+			if !ok {
+				tag = &Tag{Name: strings.TrimSpace(name), Slug: slug}
+				bySlug[slug] = tag
+			}
+			tag.Pages = append(tag.Pages, node)
+		}
+	}
+
+	tags := make([]Tag, 0, len(bySlug))
+	for _, tag := range bySlug {
+		sort.SliceStable(tag.Pages, func(i, j int) bool {
+			return strings.ToLower(tag.Pages[i].Title) < strings.ToLower(tag.Pages[j].Title)
+		})
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Slug < tags[j].Slug
+	})
+	return tags
+}
+
+// Tag returns the tag with slug, and whether any page carries it.
+func (m *Manager) Tag(slug string) (Tag, bool) {
+	for _, tag := range m.Tags() {
+		if tag.Slug == slug {
+			return tag, true
+		}
+	}
+	return Tag{}, false
+}
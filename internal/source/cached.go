@@ -3,12 +3,12 @@ package source
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/livetemplate/tinkerdown/internal/cache"
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/logging"
 )
 
 // CacheInfoProvider is implemented by sources that expose cache metadata
@@ -162,7 +162,7 @@ func (s *CachedSource) revalidateInBackground() {
 	if err != nil {
 		// Don't log if cancelled due to shutdown
 		if s.cancelCtx.Err() == nil {
-			log.Printf("[cache/%s] Background revalidation failed: %v", s.name, err)
+			logging.New("cache/"+s.name).Warnf("Background revalidation failed: %v", err)
 		}
 	}
 }
@@ -284,7 +284,7 @@ func estimateSize(data []map[string]interface{}) int {
 	b, err := json.Marshal(data)
 	if err != nil {
 		// Log error as it could indicate data serialization issues affecting cache storage
-		log.Printf("[cache] estimateSize marshal error: %v", err)
+		logging.New("cache").Errorf("estimateSize marshal error: %v", err)
 		return 0
 	}
 	return len(b)
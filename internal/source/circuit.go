@@ -2,9 +2,10 @@ package source
 
 import (
 	"context"
-	"log"
 	"sync"
 	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/logging"
 )
 
 // CircuitState represents the state of a circuit breaker
@@ -182,7 +183,7 @@ func (cb *CircuitBreaker) transitionTo(newState CircuitState) {
 	cb.successes = 0
 
 	if cb.config.EnableLog {
-		log.Printf("[circuit/%s] State changed: %s -> %s", cb.name, oldState, newState)
+		logging.New("circuit/"+cb.name).Infof("State changed: %s -> %s", oldState, newState)
 	}
 }
 
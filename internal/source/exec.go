@@ -5,12 +5,15 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/livetemplate/tinkerdown/internal/config"
@@ -21,12 +24,20 @@ type ExecSource struct {
 	name      string
 	cmd       string
 	siteDir   string
+	dir       string            // working directory: siteDir, or options["cwd"] within it
 	format    string            // "json" (default), "lines", "csv"
 	delimiter string            // for csv format, default ","
 	env       map[string]string // environment variables (already expanded)
 	timeout   time.Duration     // command timeout (default 30s)
+	stream    bool              // output is read line by line with Stream
+	maxOutput int64             // bytes of stdout and of stderr kept (0 = no limit)
+	truncated atomic.Bool       // the last run's output went past maxOutput
 }
 
+// killGrace is how long a cancelled or timed-out command has to exit after
+// SIGTERM before it and everything it started are killed.
+const killGrace = 2 * time.Second
+
 // NewExecSource creates a new exec source (legacy constructor for backwards compatibility)
 func NewExecSource(name, cmd, siteDir string) (*ExecSource, error) {
 	if cmd == "" {
@@ -36,6 +47,7 @@ func NewExecSource(name, cmd, siteDir string) (*ExecSource, error) {
 		name:      name,
 		cmd:       cmd,
 		siteDir:   siteDir,
+		dir:       siteDir,
 		format:    "json",
 		delimiter: ",",
 		timeout:   30 * time.Second,
@@ -68,6 +80,21 @@ func NewExecSourceWithConfig(name string, cfg config.SourceConfig, siteDir strin
 		}
 	}
 
+	dir, err := workDir(siteDir, cfg.Options["cwd"])
+	if err != nil {
+		return nil, fmt.Errorf("exec source %q: %w", name, err)
+	}
+	parts, err := SplitCommand(cfg.Cmd)
+	if err != nil {
+		return nil, fmt.Errorf("exec source %q: %w", name, err)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("exec source %q: empty command", name)
+	}
+	if err := config.CheckExecutable(parts[0], dir); err != nil {
+		return nil, &ValidationError{Source: name, Field: "cmd", Reason: err.Error()}
+	}
+
 	// Expand environment variables in env map
 	env := make(map[string]string)
 	for k, v := range cfg.Env {
@@ -78,13 +105,71 @@ func NewExecSourceWithConfig(name string, cfg config.SourceConfig, siteDir strin
 		name:      name,
 		cmd:       cfg.Cmd,
 		siteDir:   siteDir,
+		dir:       dir,
 		format:    format,
 		delimiter: delimiter,
 		env:       env,
 		timeout:   timeout,
+		stream:    cfg.IsStreaming(),
+		maxOutput: cfg.GetMaxOutput(),
 	}, nil
 }
 
+// workDir resolves the cwd option, relative to siteDir or absolute, to the
+// directory commands run in. It must be an existing directory inside the
+// site directory once symlinks are followed, so a page can't run commands
+// elsewhere on the machine. An empty cwd means siteDir.
+func workDir(siteDir, cwd string) (string, error) {
+	if cwd == "" {
+		return siteDir, nil
+	}
+	dir := cwd
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(siteDir, dir)
+	}
+
+	resolved, err := resolveInSiteDir(siteDir, dir)
+	if errors.Is(err, errOutsideSiteDir) {
+		return "", fmt.Errorf("cwd %q is outside the site directory", cwd)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cwd %q: %w", cwd, err)
+	}
+	if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("cwd %q is not a directory", cwd)
+	}
+	return resolved, nil
+}
+
+// errOutsideSiteDir is returned by resolveInSiteDir for paths that leave the
+// site directory.
+var errOutsideSiteDir = errors.New("outside the site directory")
+
+// resolveInSiteDir returns path with symlinks resolved, or errOutsideSiteDir
+// when it resolves to somewhere outside siteDir.
+func resolveInSiteDir(siteDir, path string) (string, error) {
+	root, err := filepath.Abs(siteDir)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errOutsideSiteDir
+	}
+	return resolved, nil
+}
+
 // Name returns the source identifier
 func (s *ExecSource) Name() string {
 	return s.name
@@ -92,25 +177,67 @@ func (s *ExecSource) Name() string {
 
 // Fetch executes the command and parses output according to format
 func (s *ExecSource) Fetch(ctx context.Context) ([]map[string]interface{}, error) {
-	// Parse command - split by spaces (simple parsing)
-	parts := strings.Fields(s.cmd)
+	parts, err := SplitCommand(s.cmd)
+	if err != nil {
+		return nil, fmt.Errorf("exec source %q: %w", s.name, err)
+	}
 	if len(parts) == 0 {
 		return nil, fmt.Errorf("exec source %q: empty command", s.name)
 	}
+	return s.run(ctx, parts[0], parts[1:])
+}
+
+// run executes cmdName with args in the site directory, with the source's
+// timeout and environment, and parses the output according to format.
+func (s *ExecSource) run(ctx context.Context, cmdName string, args []string) ([]map[string]interface{}, error) {
+	cmd, cmdCtx, done := s.command(ctx, cmdName, args)
+	defer done()
+
+	// Execute and capture output, up to max_output
+	stdout := &cappedBuffer{max: s.maxOutput}
+	stderr := &cappedBuffer{max: s.maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	s.truncated.Store(stdout.truncated || stderr.truncated)
+	if err != nil {
+		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("exec source %q: command timed out after %s", s.name, s.timeoutOrDefault())
+		}
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("exec source %q: command failed: %s\nstderr: %s",
+				s.name, err, stderr.String())
+		}
+		return nil, fmt.Errorf("exec source %q: %w", s.name, err)
+	}
 
-	cmdName := parts[0]
-	args := parts[1:]
+	// Parse output according to format
+	rows, err := s.parseOutput(stdout.Bytes())
+	if err != nil && stdout.truncated {
+		return nil, fmt.Errorf("%w (output was cut off at max_output, %d bytes)", err, s.maxOutput)
+	}
+	return rows, err
+}
 
-	// Create command with context and timeout
-	timeout := s.timeout
-	if timeout == 0 {
-		timeout = 30 * time.Second
+// timeoutOrDefault returns the command timeout (default 30s).
+func (s *ExecSource) timeoutOrDefault() time.Duration {
+	if s.timeout == 0 {
+		return 30 * time.Second
 	}
-	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	return s.timeout
+}
 
-	cmd := exec.CommandContext(cmdCtx, cmdName, args...)
-	cmd.Dir = s.siteDir
+// command prepares cmdName with args to run in the site directory, with the
+// source's timeout and environment, in its own process group where the
+// platform allows. Cancelling ctx or reaching the timeout stops the command
+// and the processes it started. done must be called once the command has
+// finished; it kills any of those processes still left and releases the
+// timeout.
+func (s *ExecSource) command(ctx context.Context, cmdName string, args []string) (cmd *exec.Cmd, cmdCtx context.Context, done func()) {
+	cmdCtx, cancel := context.WithTimeout(ctx, s.timeoutOrDefault())
+
+	cmd = exec.CommandContext(cmdCtx, cmdName, args...)
+	cmd.Dir = s.dir
 
 	// Set environment: inherit current + add custom
 	cmd.Env = os.Environ()
@@ -118,18 +245,130 @@ func (s *ExecSource) Fetch(ctx context.Context) ([]map[string]interface{}, error
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	// Execute and capture output
-	output, err := cmd.Output()
+	killGroup := useProcessGroup(cmd)
+	return cmd, cmdCtx, func() {
+		if cmdCtx.Err() != nil {
+			killGroup()
+		}
+		cancel()
+	}
+}
+
+// OutputTruncated reports whether the last run printed more than
+// options["max_output"], so only the first max_output bytes were kept.
+func (s *ExecSource) OutputTruncated() bool {
+	return s.truncated.Load()
+}
+
+// Streams reports whether the source was configured with options["stream"],
+// so its output should be read with Stream rather than Fetch.
+func (s *ExecSource) Streams() bool {
+	return s.stream
+}
+
+// Stream runs the command like FetchWithArgs (or Fetch, for nil args) but
+// hands each line of stdout to onLine as soon as it is written, without
+// parsing the output. It returns once the command exits; cancelling ctx
+// kills the command.
+func (s *ExecSource) Stream(ctx context.Context, args map[string]string, onLine func(line string)) error {
+	parts, err := SplitCommand(s.cmd)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("exec source %q: command failed: %s\nstderr: %s",
-				s.name, err, string(exitErr.Stderr))
+		return fmt.Errorf("exec source %q: %w", s.name, err)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("exec source %q: empty command", s.name)
+	}
+	cmdArgs := parts[1:]
+	if args != nil {
+		cmdArgs = argList(args)
+	}
+
+	cmd, cmdCtx, done := s.command(ctx, parts[0], cmdArgs)
+	defer done()
+	lines := &lineWriter{onLine: onLine, max: s.maxOutput}
+	stderr := &cappedBuffer{max: s.maxOutput}
+	cmd.Stdout = lines
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	lines.flush()
+	s.truncated.Store(lines.truncated || stderr.truncated)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("exec source %q: %w", s.name, ctx.Err())
 		}
-		return nil, fmt.Errorf("exec source %q: %w", s.name, err)
+		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("exec source %q: command timed out after %s", s.name, s.timeoutOrDefault())
+		}
+		return fmt.Errorf("exec source %q: command failed: %s\nstderr: %s", s.name, err, stderr.String())
 	}
+	return nil
+}
 
-	// Parse output according to format
-	return s.parseOutput(output)
+// cappedBuffer keeps the first max bytes written to it (all of them when
+// max is 0) and discards the rest, so a command printing without end can't
+// exhaust memory. Writes always succeed so the command isn't stopped by a
+// broken pipe.
+type cappedBuffer struct {
+	buf       bytes.Buffer // Not embedded: io.Copy would use its ReadFrom
+	max       int64
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.max > 0 {
+		room := b.max - int64(b.buf.Len())
+		if room < int64(len(p)) {
+			b.truncated = true
+			p = p[:max(room, 0)]
+		}
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+func (b *cappedBuffer) Bytes() []byte  { return b.buf.Bytes() }
+func (b *cappedBuffer) String() string { return b.buf.String() }
+
+// lineWriter passes each complete line written to it to onLine, without the
+// line ending. Like cappedBuffer, it stops after max bytes when max is set.
+type lineWriter struct {
+	onLine    func(line string)
+	partial   []byte
+	max       int64
+	written   int64
+	truncated bool
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if w.max > 0 {
+		room := w.max - w.written
+		if room < int64(len(p)) {
+			w.truncated = true
+			p = p[:max(room, 0)]
+		}
+		w.written += int64(len(p))
+	}
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(string(bytes.TrimSuffix(w.partial[:i], []byte("\r"))))
+		w.partial = w.partial[i+1:]
+	}
+	return n, nil
+}
+
+// flush passes on a last line that has no line ending.
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.onLine(string(w.partial))
+		w.partial = nil
+	}
 }
 
 // parseOutput dispatches to the appropriate parser based on format
@@ -258,6 +497,84 @@ func (s *ExecSource) parseJSON(data []byte) ([]map[string]interface{}, error) {
 	return nil, fmt.Errorf("exec source %q: could not parse output as JSON", s.name)
 }
 
+// SplitCommand splits an exec command into the executable and its arguments.
+// Commands don't run through a shell, so only quoting is interpreted: single
+// quotes keep their content as-is, double quotes allow \" and \\ escapes,
+// and outside quotes a backslash escapes a space, quote or backslash. Other
+// backslashes are literal, so regexes and Windows paths don't need doubling,
+// and backticks, $ and | have no special meaning.
+func SplitCommand(cmd string) ([]string, error) {
+	var parts []string
+	var current strings.Builder
+	inWord := false
+	var quote rune // 0, '\'' or '"'
+
+	runes := []rune(cmd)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes) && strings.ContainsRune(" \t'\"\\", runes[i+1]):
+			i++
+			current.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				parts = append(parts, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command", quote)
+	}
+	if inWord {
+		parts = append(parts, current.String())
+	}
+	return parts, nil
+}
+
+// JoinCommand is the inverse of SplitCommand: it joins parts into a command,
+// quoting the ones that contain spaces, quotes or backslashes.
+func JoinCommand(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		switch {
+		case part == "":
+			quoted[i] = `""`
+		case !strings.ContainsAny(part, " \t\n\r'\"\\"):
+			quoted[i] = part
+		case !strings.Contains(part, "'"):
+			quoted[i] = "'" + part + "'"
+		default:
+			quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(part) + `"`
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
 // Close is a no-op for exec sources
 func (s *ExecSource) Close() error {
 	return nil
@@ -267,42 +584,36 @@ func (s *ExecSource) Close() error {
 // The args map contains argument name -> value pairs that override the defaults
 func (s *ExecSource) FetchWithArgs(ctx context.Context, args map[string]string) ([]map[string]interface{}, error) {
 	// Parse original command to get executable
-	parts := strings.Fields(s.cmd)
+	parts, err := SplitCommand(s.cmd)
+	if err != nil {
+		return nil, fmt.Errorf("exec source %q: %w", s.name, err)
+	}
 	if len(parts) == 0 {
 		return nil, fmt.Errorf("exec source %q: empty command", s.name)
 	}
 
-	cmdName := parts[0]
+	return s.run(ctx, parts[0], argList(args))
+}
 
-	// Build new arguments from the provided map
-	var newArgs []string
-	for name, value := range args {
+// argList turns argument name -> value pairs into --name value arguments,
+// in a stable order.
+func argList(args map[string]string) []string {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list []string
+	for _, name := range names {
+		value := args[name]
 		// Handle boolean args specially - convert "on" to "true"
 		if value == "on" {
 			value = "true"
 		}
-		newArgs = append(newArgs, "--"+name, value)
-	}
-
-	// Create command with context and timeout
-	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(cmdCtx, cmdName, newArgs...)
-	cmd.Dir = s.siteDir
-
-	// Execute and capture output
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("exec source %q: command failed: %s\nstderr: %s",
-				s.name, err, string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("exec source %q: %w", s.name, err)
+		list = append(list, "--"+name, value)
 	}
-
-	// Parse JSON output
-	return s.parseJSON(output)
+	return list
 }
 
 // resolvePath makes a path absolute relative to siteDir
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package source
+
+import "os/exec"
+
+// useProcessGroup only bounds how long Wait waits for a killed command here:
+// without process groups, processes the command started may outlive it.
+func useProcessGroup(cmd *exec.Cmd) (killGroup func()) {
+	cmd.WaitDelay = killGrace
+	return func() {}
+}
@@ -434,3 +434,256 @@ echo "line three"
 	assert.Equal(t, "line three", data[2]["line"])
 	assert.Equal(t, 2, data[2]["index"])
 }
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want []string
+	}{
+		{"plain", "./script.sh --name World", []string{"./script.sh", "--name", "World"}},
+		{"extra whitespace", "  ls \t -la  ", []string{"ls", "-la"}},
+		{"double quotes", `echo "hello world"`, []string{"echo", "hello world"}},
+		{"single quotes keep backslashes", `grep -E '^a\d+$' data.txt`, []string{"grep", "-E", `^a\d+$`, "data.txt"}},
+		{"regex without quotes", `grep ^a\d+ data.txt`, []string{"grep", `^a\d+`, "data.txt"}},
+		{"windows path", `C:\tools\report.exe --out C:\tmp\out.json`, []string{`C:\tools\report.exe`, "--out", `C:\tmp\out.json`}},
+		{"escaped quote in double quotes", `echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{"escaped backslash in double quotes", `echo "a\\b\n"`, []string{"echo", `a\b\n`}},
+		{"escaped space", `cat my\ file.txt`, []string{"cat", "my file.txt"}},
+		{"backticks and dollars are literal", "echo `whoami` $HOME", []string{"echo", "`whoami`", "$HOME"}},
+		{"shell operators are literal", "echo a | wc; rm -rf /", []string{"echo", "a", "|", "wc;", "rm", "-rf", "/"}},
+		{"adjacent quotes join", `--query='SELECT "name"'"s"`, []string{`--query=SELECT "name"s`}},
+		{"empty quoted argument", `./script.sh --name ""`, []string{"./script.sh", "--name", ""}},
+		{"empty", "   ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitCommand(tt.cmd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, cmd := range []string{`echo "unterminated`, `echo 'unterminated`} {
+		_, err := SplitCommand(cmd)
+		assert.Error(t, err, "SplitCommand(%q)", cmd)
+	}
+}
+
+func TestJoinCommandRoundTrip(t *testing.T) {
+	parts := []string{"./script.sh", "--name", "Jane Doe", "--path", `C:\tmp\x`, "--quote", `it's "quoted"`, "--empty", "", "--tick", "`date`"}
+	got, err := SplitCommand(JoinCommand(parts))
+	require.NoError(t, err)
+	assert.Equal(t, parts, got)
+}
+
+func TestExecSourceQuotedArguments(t *testing.T) {
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "args.sh")
+	scriptContent := `#!/bin/bash
+for arg in "$@"; do
+  echo "$arg"
+done
+`
+	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
+	require.NoError(t, err)
+
+	cfg := config.SourceConfig{
+		Type:   "exec",
+		Cmd:    `./args.sh "two words" '^a\d+$' ` + "`x`",
+		Format: "lines",
+	}
+	src, err := NewExecSourceWithConfig("test", cfg, tmpDir)
+	require.NoError(t, err)
+
+	data, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, data, 3)
+	assert.Equal(t, "two words", data[0]["line"])
+	assert.Equal(t, `^a\d+$`, data[1]["line"])
+	assert.Equal(t, "`x`", data[2]["line"])
+}
+
+func TestExecSourceFetchWithArgsMatchesFetch(t *testing.T) {
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "args.sh")
+	scriptContent := `#!/bin/bash
+echo "env=$MY_VAR"
+for arg in "$@"; do
+  echo "$arg"
+done
+`
+	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
+	require.NoError(t, err)
+
+	cfg := config.SourceConfig{
+		Type:   "exec",
+		Cmd:    "./args.sh --name World --count 1",
+		Format: "lines",
+		Env:    map[string]string{"MY_VAR": "set"},
+	}
+	src, err := NewExecSourceWithConfig("test", cfg, tmpDir)
+	require.NoError(t, err)
+
+	data, err := src.FetchWithArgs(context.Background(), map[string]string{"name": "Jane Doe", "count": "2", "verbose": "on"})
+	require.NoError(t, err)
+
+	// Output is parsed with the source's format, env is set and args are in a stable order
+	var lines []string
+	for _, row := range data {
+		lines = append(lines, row["line"].(string))
+	}
+	assert.Equal(t, []string{"env=set", "--count", "2", "--name", "Jane Doe", "--verbose", "true"}, lines)
+}
+
+func TestExecSourceFetchWithArgsTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "slow.sh"), []byte("#!/bin/bash\nexec sleep 5\n"), 0755)
+	require.NoError(t, err)
+
+	cfg := config.SourceConfig{Type: "exec", Cmd: "./slow.sh", Timeout: "100ms"}
+	src, err := NewExecSourceWithConfig("test", cfg, tmpDir)
+	require.NoError(t, err)
+
+	// The configured timeout applies to runs with arguments, not a fixed 30s
+	start := time.Now()
+	_, err = src.FetchWithArgs(context.Background(), map[string]string{"name": "Jane"})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestExecSourceStream(t *testing.T) {
+	tmpDir := t.TempDir()
+	script := "#!/bin/sh\necho \"building $2\"\necho step 1\nprintf done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "build.sh"), []byte(script), 0755))
+
+	cfg := config.SourceConfig{Type: "exec", Cmd: "./build.sh --target all", Options: map[string]string{"stream": "true"}}
+	src, err := NewExecSourceWithConfig("build", cfg, tmpDir)
+	require.NoError(t, err)
+	assert.True(t, src.Streams())
+
+	var lines []string
+	err = src.Stream(context.Background(), map[string]string{"target": "web"}, func(line string) {
+		lines = append(lines, line)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"building web", "step 1", "done"}, lines)
+}
+
+func TestExecSourceStreamCancel(t *testing.T) {
+	tmpDir := t.TempDir()
+	// The sleep is a child of the shell, so it still holds stdout after the
+	// shell is killed
+	script := "#!/bin/sh\necho started\nsleep 10\necho never\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "slow.sh"), []byte(script), 0755))
+
+	src, err := NewExecSourceWithConfig("slow", config.SourceConfig{Type: "exec", Cmd: "./slow.sh"}, tmpDir)
+	require.NoError(t, err)
+
+	// The first line arrives while the command is still running
+	ctx, cancel := context.WithCancel(context.Background())
+	var lines []string
+	start := time.Now()
+	err = src.Stream(ctx, nil, func(line string) {
+		lines = append(lines, line)
+		cancel()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"started"}, lines)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestExecSourceCwd(t *testing.T) {
+	siteDir := t.TempDir()
+	repoDir := filepath.Join(siteDir, "checkout", "app")
+	require.NoError(t, os.MkdirAll(repoDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "where.sh"), []byte("#!/bin/sh\nprintf '{\"dir\":\"%s\"}' \"$(pwd -P)\"\n"), 0755))
+	wantDir, err := filepath.EvalSymlinks(repoDir)
+	require.NoError(t, err)
+
+	// Relative to the site directory or absolute, the command runs there
+	for _, cwd := range []string{"checkout/app", "./checkout/../checkout/app", repoDir} {
+		cfg := config.SourceConfig{Type: "exec", Cmd: "./where.sh", Options: map[string]string{"cwd": cwd}}
+		src, err := NewExecSourceWithConfig("where", cfg, siteDir)
+		require.NoError(t, err, "cwd %q", cwd)
+		rows, err := src.Fetch(context.Background())
+		require.NoError(t, err, "cwd %q", cwd)
+		assert.Equal(t, wantDir, rows[0]["dir"], "cwd %q", cwd)
+	}
+}
+
+func TestExecSourceCwdOutsideSite(t *testing.T) {
+	baseDir := t.TempDir()
+	siteDir := filepath.Join(baseDir, "site")
+	require.NoError(t, os.MkdirAll(filepath.Join(siteDir, "docs"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(baseDir, "secrets"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(baseDir, "secrets"), filepath.Join(siteDir, "link")))
+
+	for _, cwd := range []string{"..", "../secrets", "docs/../../secrets", filepath.Join(baseDir, "secrets"), "link", "/"} {
+		cfg := config.SourceConfig{Type: "exec", Cmd: "ls", Options: map[string]string{"cwd": cwd}}
+		_, err := NewExecSourceWithConfig("escape", cfg, siteDir)
+		if assert.Error(t, err, "cwd %q", cwd) {
+			assert.Contains(t, err.Error(), "outside the site directory", "cwd %q", cwd)
+		}
+	}
+
+	cfg := config.SourceConfig{Type: "exec", Cmd: "ls", Options: map[string]string{"cwd": "missing"}}
+	_, err := NewExecSourceWithConfig("missing", cfg, siteDir)
+	assert.Error(t, err)
+}
+
+func TestExecSourceAllowlist(t *testing.T) {
+	defer config.SetExecAllowlist(nil)
+	siteDir := t.TempDir()
+	config.SetExecAllowlist([]string{"echo", filepath.Join(siteDir, "deploy.sh")})
+
+	for _, cmd := range []string{"echo hi", "./deploy.sh --env prod"} {
+		_, err := NewExecSourceWithConfig("allowed", config.SourceConfig{Type: "exec", Cmd: cmd}, siteDir)
+		assert.NoError(t, err, cmd)
+	}
+
+	_, err := NewExecSourceWithConfig("denied", config.SourceConfig{Type: "exec", Cmd: "rm -rf data"}, siteDir)
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, `source "denied": invalid cmd: executable "rm" is not in exec_allowlist`, err.Error())
+	}
+}
+
+func TestExecSourceMaxOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	// 100 lines of "line NN" (8 bytes each with the newline)
+	script := "#!/bin/sh\ni=10\nwhile [ $i -lt 110 ]; do echo \"line $i\"; i=$((i+1)); done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "noisy.sh"), []byte(script), 0755))
+
+	cfg := config.SourceConfig{Type: "exec", Cmd: "./noisy.sh", Format: "lines", Options: map[string]string{"max_output": "20B"}}
+	src, err := NewExecSourceWithConfig("noisy", cfg, tmpDir)
+	require.NoError(t, err)
+
+	rows, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.True(t, src.OutputTruncated())
+	require.Len(t, rows, 3)
+	assert.Equal(t, "line 10", rows[0]["line"])
+	assert.Equal(t, "line", rows[2]["line"])
+
+	var lines []string
+	require.NoError(t, src.Stream(context.Background(), nil, func(line string) { lines = append(lines, line) }))
+	assert.Equal(t, []string{"line 10", "line 11", "line"}, lines)
+
+	// JSON cut off mid-value says why it doesn't parse
+	cfg = config.SourceConfig{Type: "exec", Cmd: `echo '[{"name":"a long value"}]'`, Options: map[string]string{"max_output": "10"}}
+	src, err = NewExecSourceWithConfig("json", cfg, tmpDir)
+	require.NoError(t, err)
+	_, err = src.Fetch(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cut off at max_output")
+
+	// Without max_output everything is kept
+	cfg.Options = nil
+	src, err = NewExecSourceWithConfig("json", cfg, tmpDir)
+	require.NoError(t, err)
+	rows, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.False(t, src.OutputTruncated())
+	assert.Len(t, rows, 1)
+}
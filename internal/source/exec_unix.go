@@ -0,0 +1,30 @@
+//go:build unix
+
+package source
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// useProcessGroup starts cmd in a process group of its own. Cancelling cmd
+// sends SIGTERM to the whole group, and Wait gives up on the group after
+// killGrace. The returned function kills whatever is left of the group.
+func useProcessGroup(cmd *exec.Cmd) (killGroup func()) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		err := syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+	cmd.WaitDelay = killGrace
+	return func() {
+		if cmd.Process != nil {
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+	}
+}
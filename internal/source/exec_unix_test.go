@@ -0,0 +1,56 @@
+//go:build unix
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processRunning reports whether pid is alive, treating a zombie waiting to
+// be reaped as gone.
+func processRunning(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true // No /proc: trust kill
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
+
+func TestExecSourceTimeoutKillsProcessGroup(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Both the script and the child it starts ignore SIGTERM
+	script := "#!/bin/sh\ntrap '' TERM\nsleep 30 &\necho $! > child.pid\nwait\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "stubborn.sh"), []byte(script), 0755))
+
+	cfg := config.SourceConfig{Type: "exec", Cmd: "./stubborn.sh", Timeout: "200ms"}
+	src, err := NewExecSourceWithConfig("stubborn", cfg, tmpDir)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = src.Fetch(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 200ms")
+	assert.Less(t, time.Since(start), killGrace+2*time.Second)
+
+	pidText, err := os.ReadFile(filepath.Join(tmpDir, "child.pid"))
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidText)))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !processRunning(pid) }, time.Second, 20*time.Millisecond,
+		"child %d survived the timeout", pid)
+}
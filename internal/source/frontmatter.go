@@ -0,0 +1,139 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontmatterSource reads structured data from a page's YAML frontmatter.
+// options["key"] selects the field to expose; dotted keys (e.g. "team.members")
+// select nested fields. The source is read-only.
+type FrontmatterSource struct {
+	name        string
+	filePath    string // empty means current file
+	key         string
+	siteDir     string
+	currentFile string
+}
+
+// NewFrontmatterSource creates a new frontmatter source
+func NewFrontmatterSource(name, file, siteDir, currentFile string, options map[string]string) (*FrontmatterSource, error) {
+	key := strings.TrimSpace(options["key"])
+	if key == "" {
+		return nil, fmt.Errorf("frontmatter source %q: options.key is required", name)
+	}
+	if file == "" && currentFile == "" {
+		return nil, fmt.Errorf("frontmatter source %q: 'file' is required when not used from a page", name)
+	}
+
+	return &FrontmatterSource{
+		name:        name,
+		filePath:    file,
+		key:         key,
+		siteDir:     siteDir,
+		currentFile: currentFile,
+	}, nil
+}
+
+// Name returns the source identifier
+func (s *FrontmatterSource) Name() string {
+	return s.name
+}
+
+// Fetch reads the file's frontmatter and returns the selected field.
+// A list of maps yields one row per map, a map yields a single row, and
+// a list of scalars yields rows of the form {"value": x}.
+func (s *FrontmatterSource) Fetch(ctx context.Context) ([]map[string]interface{}, error) {
+	path, err := s.resolvePath()
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter source %q: %w", s.name, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter source %q: failed to read file: %w", s.name, err)
+	}
+
+	// Split the way the page parser does, so the source sees the same
+	// frontmatter as the page
+	raw, _, ok, err := frontmatter.Split([]byte(normalizeLineEndings(string(content))))
+	if err != nil {
+		return nil, fmt.Errorf("frontmatter source %q: %w", s.name, err)
+	}
+	if !ok {
+		return []map[string]interface{}{}, nil
+	}
+
+	var fm map[string]interface{}
+	if err := yaml.Unmarshal(raw, &fm); err != nil {
+		return nil, fmt.Errorf("frontmatter source %q: invalid frontmatter: %w", s.name, err)
+	}
+
+	// Walk dotted key path
+	var value interface{} = fm
+	for _, part := range strings.Split(s.key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return []map[string]interface{}{}, nil
+		}
+		if value, ok = m[part]; !ok {
+			return []map[string]interface{}{}, nil
+		}
+	}
+
+	return s.toRows(value)
+}
+
+// toRows converts the selected frontmatter value into source rows
+func (s *FrontmatterSource) toRows(value interface{}) ([]map[string]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return []map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, nil
+	case []interface{}:
+		results := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				results = append(results, m)
+			} else {
+				results = append(results, map[string]interface{}{"value": item})
+			}
+		}
+		return results, nil
+	default:
+		return nil, fmt.Errorf("frontmatter source %q: key %q must be a list or a map, got %T", s.name, s.key, value)
+	}
+}
+
+// Close is a no-op for file sources
+func (s *FrontmatterSource) Close() error {
+	return nil
+}
+
+// resolvePath determines which file to read. A configured file must be
+// inside the site directory once symlinks are followed, as exec sources'
+// cwd must.
+func (s *FrontmatterSource) resolvePath() (string, error) {
+	if s.filePath == "" {
+		return s.currentFile, nil
+	}
+	path := s.filePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.siteDir, path)
+	}
+	resolved, err := resolveInSiteDir(s.siteDir, path)
+	if errors.Is(err, errOutsideSiteDir) {
+		return "", fmt.Errorf("file %q is outside the site directory", s.filePath)
+	}
+	if err != nil {
+		return "", fmt.Errorf("file %q: %w", s.filePath, err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,178 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFrontmatterPage(t *testing.T, dir string) string {
+	t.Helper()
+	content := `---
+title: Team
+members:
+  - name: Alice
+    role: Lead
+  - name: Bob
+    role: Engineer
+tags:
+  - go
+  - docs
+settings:
+  theme: dark
+  limits:
+    max: 10
+---
+
+# Team
+`
+	path := filepath.Join(dir, "team.md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	return path
+}
+
+func TestNewFrontmatterSource(t *testing.T) {
+	if _, err := NewFrontmatterSource("team", "", "/site", "/site/index.md", nil); err == nil {
+		t.Error("expected error for missing options.key")
+	}
+	if _, err := NewFrontmatterSource("team", "", "/site", "", map[string]string{"key": "members"}); err == nil {
+		t.Error("expected error when neither file nor current file is set")
+	}
+	if _, err := NewFrontmatterSource("team", "", "/site", "/site/index.md", map[string]string{"key": "members"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFrontmatterSourceFetch(t *testing.T) {
+	tmpDir := t.TempDir()
+	pagePath := writeFrontmatterPage(t, tmpDir)
+
+	tests := []struct {
+		name  string
+		key   string
+		check func(t *testing.T, rows []map[string]interface{})
+	}{
+		{"list of maps", "members", func(t *testing.T, rows []map[string]interface{}) {
+			if len(rows) != 2 || rows[0]["name"] != "Alice" || rows[1]["role"] != "Engineer" {
+				t.Errorf("rows = %v", rows)
+			}
+		}},
+		{"map", "settings", func(t *testing.T, rows []map[string]interface{}) {
+			if len(rows) != 1 || rows[0]["theme"] != "dark" {
+				t.Errorf("rows = %v", rows)
+			}
+		}},
+		{"list of scalars", "tags", func(t *testing.T, rows []map[string]interface{}) {
+			if len(rows) != 2 || rows[0]["value"] != "go" || rows[1]["value"] != "docs" {
+				t.Errorf("rows = %v", rows)
+			}
+		}},
+		{"dotted key", "settings.limits", func(t *testing.T, rows []map[string]interface{}) {
+			if len(rows) != 1 || rows[0]["max"] != 10 {
+				t.Errorf("rows = %v", rows)
+			}
+		}},
+		{"missing key", "nope", func(t *testing.T, rows []map[string]interface{}) {
+			if len(rows) != 0 {
+				t.Errorf("expected no rows, got %v", rows)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Same-file source (no 'file' option) reads the current page
+			src, err := NewFrontmatterSource("data", "", tmpDir, pagePath, map[string]string{"key": tt.key})
+			if err != nil {
+				t.Fatalf("NewFrontmatterSource() error = %v", err)
+			}
+			rows, err := src.Fetch(context.Background())
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			tt.check(t, rows)
+		})
+	}
+}
+
+func TestFrontmatterSourceScalarKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFrontmatterPage(t, tmpDir)
+
+	src, err := NewFrontmatterSource("data", "team.md", tmpDir, "", map[string]string{"key": "title"})
+	if err != nil {
+		t.Fatalf("NewFrontmatterSource() error = %v", err)
+	}
+	_, err = src.Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "must be a list or a map") {
+		t.Errorf("expected scalar key error, got %v", err)
+	}
+}
+
+func TestFrontmatterSourceNoFrontmatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "plain.md")
+	if err := os.WriteFile(path, []byte("# No frontmatter\n"), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewFrontmatterSource("data", "plain.md", tmpDir, "", map[string]string{"key": "members"})
+	if err != nil {
+		t.Fatalf("NewFrontmatterSource() error = %v", err)
+	}
+	rows, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows, got %v", rows)
+	}
+}
+
+func TestFrontmatterSourceIsReadonly(t *testing.T) {
+	var src Source = &FrontmatterSource{}
+	if _, ok := src.(WritableSource); ok {
+		t.Error("frontmatter source must not be writable")
+	}
+}
+
+func TestFrontmatterSourceStaysInSiteDir(t *testing.T) {
+	outside := t.TempDir()
+	writeFrontmatterPage(t, outside)
+	siteDir := t.TempDir()
+	if err := os.Symlink(filepath.Join(outside, "team.md"), filepath.Join(siteDir, "link.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	for _, file := range []string{
+		filepath.Join(outside, "team.md"),
+		"../" + filepath.Base(outside) + "/team.md",
+		"link.md",
+	} {
+		src, err := NewFrontmatterSource("data", file, siteDir, "", map[string]string{"key": "members"})
+		if err != nil {
+			t.Fatalf("NewFrontmatterSource() error = %v", err)
+		}
+		if _, err := src.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "outside the site directory") {
+			t.Errorf("Fetch(%s) error = %v, want outside the site directory", file, err)
+		}
+	}
+}
+
+func TestFrontmatterSourceUnclosed(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "open.md"), []byte("---\nmembers: [a]\n# Open\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := NewFrontmatterSource("data", "open.md", tmpDir, "", map[string]string{"key": "members"})
+	if err != nil {
+		t.Fatalf("NewFrontmatterSource() error = %v", err)
+	}
+	if _, err := src.Fetch(context.Background()); err == nil {
+		t.Error("unclosed frontmatter should be an error, as it is for pages")
+	}
+}
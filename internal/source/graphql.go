@@ -7,13 +7,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/logging"
 )
 
 // GraphQLSource fetches data from a GraphQL API endpoint
@@ -293,7 +293,7 @@ func extractPath(data map[string]interface{}, path string) ([]map[string]interfa
 	}
 
 	if skippedCount > 0 {
-		log.Printf("[graphql] warning: extractPath skipped %d non-object items at path '%s'", skippedCount, path)
+		logging.New("graphql").Warnf("extractPath skipped %d non-object items at path '%s'", skippedCount, path)
 	}
 
 	return result, nil
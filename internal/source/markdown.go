@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"os"
@@ -13,6 +16,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/livetemplate/tinkerdown/internal/slug"
 )
@@ -40,6 +44,7 @@ type MarkdownSource struct {
 	// Concurrency control
 	mu       sync.RWMutex
 	lastMtime time.Time // mtime of file when last read
+	lastHash  string    // SHA-256 of file content when last read
 }
 
 // NewMarkdownSource creates a new markdown source
@@ -87,9 +92,10 @@ func (s *MarkdownSource) Fetch(ctx context.Context) ([]map[string]interface{}, e
 		return nil, fmt.Errorf("markdown source %q: failed to read file: %w", s.name, err)
 	}
 
-	// Store mtime for conflict detection on writes
+	// Store mtime and content hash for conflict detection on writes
 	s.mu.Lock()
 	s.lastMtime = info.ModTime()
+	s.lastHash = contentHash(content)
 	s.mu.Unlock()
 
 	return s.parseSection(string(content))
@@ -121,8 +127,14 @@ func (s *MarkdownSource) resolvePath() string {
 func (s *MarkdownSource) parseSection(content string) ([]map[string]interface{}, error) {
 	anchorName := strings.TrimPrefix(s.anchor, "#")
 
+	// Normalize CRLF so parsed text doesn't carry stray \r characters
+	content = normalizeLineEndings(content)
+
 	// Try to find the section header - explicit {#anchor} first, then text-based
-	matches := s.findSectionHeader(content, anchorName)
+	matches, err := s.findSectionHeader(content, anchorName)
+	if err != nil {
+		return nil, err
+	}
 	if matches == nil {
 		return []map[string]interface{}{}, nil // No section found, return empty
 	}
@@ -148,11 +160,18 @@ func (s *MarkdownSource) parseSection(content string) ([]map[string]interface{},
 // findSectionHeader finds a section header by anchor name.
 // Tries explicit {#anchor} syntax first, falls back to matching heading text (slugified).
 // Headings with explicit anchors are excluded from text-based matching.
-func (s *MarkdownSource) findSectionHeader(content, anchorName string) []int {
+// Duplicate heading slugs are disambiguated GitHub-style: the second "Setup"
+// heading is #setup-1, the third #setup-2, and so on.
+// Returns an error if the anchor matches more than one heading.
+func (s *MarkdownSource) findSectionHeader(content, anchorName string) ([]int, error) {
 	// Pattern 1: Explicit {#anchor} syntax - takes precedence
 	explicitPattern := regexp.MustCompile(`(?m)^(#{1,6})\s+(.+?)\s*\{#` + regexp.QuoteMeta(anchorName) + `\}\s*$`)
-	if matches := explicitPattern.FindStringSubmatchIndex(content); matches != nil {
-		return matches
+	explicitMatches := explicitPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(explicitMatches) > 1 {
+		return nil, fmt.Errorf("markdown source %q: anchor %q is ambiguous: %d headings declare {#%s}", s.name, s.anchor, len(explicitMatches), anchorName)
+	}
+	if len(explicitMatches) == 1 {
+		return explicitMatches[0], nil
 	}
 
 	// Pattern 2: Match heading text (slugified) - fallback
@@ -160,18 +179,33 @@ func (s *MarkdownSource) findSectionHeader(content, anchorName string) []int {
 	headingPattern := regexp.MustCompile(`(?m)^(#{1,6})\s+(.+?)\s*$`)
 	explicitAnchorPattern := regexp.MustCompile(`\{#[^}]+\}\s*$`)
 	allMatches := headingPattern.FindAllStringSubmatchIndex(content, -1)
+	slugCounts := make(map[string]int)
+	var found [][]int
 	for _, match := range allMatches {
 		headingText := content[match[4]:match[5]]
 		// Skip headings that have explicit anchors (they should only match their explicit anchor)
 		if explicitAnchorPattern.MatchString(headingText) {
 			continue
 		}
-		if slug.Heading(headingText) == anchorName {
-			return match
+		base := slug.Heading(headingText)
+		id := base
+		if n := slugCounts[base]; n > 0 {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		slugCounts[base]++
+		if id == anchorName {
+			found = append(found, match)
 		}
 	}
 
-	return nil
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("markdown source %q: anchor %q is ambiguous: %d headings match; add an explicit {#anchor} to the intended heading", s.name, s.anchor, len(found))
+	}
 }
 
 // detectAndParse auto-detects the format and parses accordingly
@@ -225,17 +259,103 @@ func (s *MarkdownSource) parseTaskList(lines []string) ([]map[string]interface{}
 		if id == "" {
 			id = generateContentID(text)
 		}
+		text = unescapeItemText(text)
 
-		results = append(results, map[string]interface{}{
+		item := map[string]interface{}{
 			"id":   id,
 			"text": text,
 			"done": done,
-		})
+		}
+
+		// Extract inline metadata; raw text is kept as-is, title is the cleaned text
+		title, due, priority := parseTaskMetadata(text)
+		item["title"] = title
+		if due != "" {
+			item["due"] = due
+		}
+		if priority != "" {
+			item["priority"] = priority
+		}
+
+		results = append(results, item)
 	}
 
 	return results, nil
 }
 
+var (
+	// taskDuePattern matches @due(YYYY-MM-DD) tokens in task text
+	taskDuePattern = regexp.MustCompile(`^@due\((\d{4}-\d{2}-\d{2})\)$`)
+	// taskPriorityPattern matches !high, !medium, and !low tokens in task text
+	taskPriorityPattern = regexp.MustCompile(`^!(high|medium|low)$`)
+	// taskTokenPattern matches the whitespace-separated tokens of task text
+	taskTokenPattern = regexp.MustCompile(`\S+`)
+)
+
+// parseTaskMetadata extracts @due(...) and !priority tokens from task text.
+// Returns the text with recognized tokens, and the space before each, removed;
+// the rest of the text, including its spacing, is left as written.
+func parseTaskMetadata(text string) (title, due, priority string) {
+	var kept strings.Builder
+	last := 0
+	for _, loc := range taskTokenPattern.FindAllStringIndex(text, -1) {
+		token := text[loc[0]:loc[1]]
+		recognized := false
+		if m := taskDuePattern.FindStringSubmatch(token); m != nil && due == "" {
+			if _, err := time.Parse("2006-01-02", m[1]); err == nil {
+				due = m[1]
+				recognized = true
+			}
+		}
+		if m := taskPriorityPattern.FindStringSubmatch(token); !recognized && m != nil && priority == "" {
+			priority = m[1]
+			recognized = true
+		}
+		if !recognized {
+			continue
+		}
+		start := loc[0]
+		for start > last && unicode.IsSpace(rune(text[start-1])) {
+			start--
+		}
+		kept.WriteString(text[last:start])
+		last = loc[1]
+	}
+	kept.WriteString(text[last:])
+	return strings.TrimSpace(kept.String()), due, priority
+}
+
+// formatTaskText builds task text with inline metadata from the given data.
+// Values for "due" and "priority" in data override tokens already present in text;
+// an empty string removes the token.
+func formatTaskText(text string, data map[string]interface{}) (string, error) {
+	title, due, priority := parseTaskMetadata(text)
+
+	if v, ok := data["due"]; ok {
+		due = strings.TrimSpace(fmt.Sprintf("%v", v))
+		if due != "" {
+			if _, err := time.Parse("2006-01-02", due); err != nil {
+				return "", fmt.Errorf("invalid due date %q: expected YYYY-MM-DD", due)
+			}
+		}
+	}
+	if v, ok := data["priority"]; ok {
+		priority = strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", v)))
+		if priority != "" && !taskPriorityPattern.MatchString("!"+priority) {
+			return "", fmt.Errorf("invalid priority %q: expected high, medium, or low", priority)
+		}
+	}
+
+	result := title
+	if due != "" {
+		result += " @due(" + due + ")"
+	}
+	if priority != "" {
+		result += " !" + priority
+	}
+	return strings.TrimSpace(result), nil
+}
+
 // parseBulletList parses - item <!-- id:xxx --> format
 func (s *MarkdownSource) parseBulletList(lines []string) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
@@ -263,7 +383,7 @@ func (s *MarkdownSource) parseBulletList(lines []string) ([]map[string]interface
 
 		results = append(results, map[string]interface{}{
 			"id":   id,
-			"text": text,
+			"text": unescapeItemText(text),
 		})
 	}
 
@@ -333,19 +453,76 @@ func (s *MarkdownSource) parseTable(lines []string) ([]map[string]interface{}, e
 	return results, nil
 }
 
-// parseTableCells splits a table row into cells
+// parseTableCells splits a table row into cells.
+// Escaped pipes (\|) are kept as literal pipes within a cell.
 func (s *MarkdownSource) parseTableCells(row string) []string {
-	parts := strings.Split(row, "|")
 	var cells []string
-	for _, part := range parts {
-		cell := strings.TrimSpace(part)
+	var current strings.Builder
+	flush := func() {
+		cell := strings.TrimSpace(current.String())
 		if cell != "" {
 			cells = append(cells, cell)
 		}
+		current.Reset()
+	}
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case row[i] == '|':
+			flush()
+		default:
+			current.WriteByte(row[i])
+		}
 	}
+	flush()
 	return cells
 }
 
+// listMarkerPattern matches text that would be parsed as a list marker or task checkbox
+var listMarkerPattern = regexp.MustCompile(`^([-*+]|\d+[.)]|\[[ xX]\])(\s|$)`)
+
+// sanitizeInlineText collapses newlines and removes HTML comment delimiters so
+// user input can't split an item across lines or inject/terminate an id comment.
+func sanitizeInlineText(text string) string {
+	text = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+	// Loop because removing one delimiter can form another (e.g. "<!<!----")
+	for strings.Contains(text, "<!--") || strings.Contains(text, "-->") {
+		text = strings.ReplaceAll(text, "<!--", "")
+		text = strings.ReplaceAll(text, "-->", "")
+	}
+	return strings.TrimSpace(text)
+}
+
+// sanitizeItemText makes user input safe to write as the text of a list item.
+// Leading list markers are escaped so the text stays part of a single item.
+func sanitizeItemText(text string) string {
+	text = sanitizeInlineText(text)
+	if listMarkerPattern.MatchString(text) {
+		text = `\` + text
+	}
+	return text
+}
+
+// unescapeItemText reverses the list marker escaping applied by sanitizeItemText
+func unescapeItemText(text string) string {
+	if strings.HasPrefix(text, `\`) && listMarkerPattern.MatchString(text[1:]) {
+		return text[1:]
+	}
+	return text
+}
+
+// sanitizeTableCell makes user input safe to write as a table cell
+func sanitizeTableCell(text string) string {
+	return strings.ReplaceAll(sanitizeInlineText(text), "|", `\|`)
+}
+
+// escapeReplacement escapes $ so text can be used literally in a regexp replacement
+func escapeReplacement(text string) string {
+	return strings.ReplaceAll(text, "$", "$$")
+}
+
 // generateID creates a random 8-character ID
 func generateID() string {
 	bytes := make([]byte, 4)
@@ -371,33 +548,84 @@ func (s *MarkdownSource) GetAnchor() string {
 	return s.anchor
 }
 
+// errItemNotFound is wrapped by errors for an ID that matches no item
+var errItemNotFound = errors.New("not found")
+
 // WriteItem adds, updates, or deletes an item in the markdown source
-// Supported actions: add, toggle, delete, update
-// Returns ConflictError if the file was modified externally since last read
+// Supported actions: add, toggle, delete, update, import
+// Returns ConflictError if the file content changed externally since last read
 func (s *MarkdownSource) WriteItem(ctx context.Context, action string, data map[string]interface{}) error {
+	return s.write(func(content string) (string, error) {
+		return s.applyAction(content, action, data)
+	})
+}
+
+// WriteItems applies toggle or delete to each item in ids with a single
+// read and write of the file. IDs that match no item are skipped and
+// returned; any other error leaves the file unchanged.
+func (s *MarkdownSource) WriteItems(ctx context.Context, action string, ids []string) ([]string, error) {
+	if action != "toggle" && action != "delete" {
+		return nil, fmt.Errorf("bulk %s is not supported", action)
+	}
+	var missing []string
+	err := s.write(func(content string) (string, error) {
+		for _, id := range ids {
+			next, err := s.applyAction(content, action, map[string]interface{}{"id": id})
+			if errors.Is(err, errItemNotFound) {
+				missing = append(missing, id)
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			content = next
+		}
+		return content, nil
+	})
+	return missing, err
+}
+
+// write applies change to the LF-normalized file content and writes the
+// result back, unless the file changed since it was last read.
+func (s *MarkdownSource) write(change func(content string) (string, error)) error {
 	if s.readonly {
 		return fmt.Errorf("markdown source %q is read-only", s.name)
 	}
 
 	path := s.resolvePath()
 
-	// Check current mtime before reading
-	info, err := os.Stat(path)
+	// Hold the locks for the whole read-compare-write so concurrent writers
+	// in this process can't interleave, including other sources of the file
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer lockFile(path)()
+
+	// Read current content
+	contentBytes, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
+		return fmt.Errorf("failed to read file: %w", err)
 	}
-	currentMtime := info.ModTime()
+	content := string(contentBytes)
 
-	// Check for conflict (file modified since last read)
-	s.mu.RLock()
-	lastMtime := s.lastMtime
-	s.mu.RUnlock()
+	// Work on LF-normalized content and restore the file's line ending style afterwards
+	lineEnding := detectLineEnding(content)
+	newContent, err := change(normalizeLineEndings(content))
+	if err == nil && lineEnding != "\n" {
+		newContent = strings.ReplaceAll(newContent, "\n", lineEnding)
+	}
 
-	if !lastMtime.IsZero() && !currentMtime.Equal(lastMtime) {
-		// File was modified externally - create conflict copy
-		conflictPath, err := s.createConflictCopy(path)
+	// Check for conflict (content changed since last read). Comparing hashes
+	// rather than mtimes catches edits within the filesystem's mtime resolution.
+	if s.lastHash != "" && contentHash(contentBytes) != s.lastHash {
+		// Save the user's change to a conflict copy instead of clobbering the
+		// external edit. If the change no longer applies, keep the current content.
+		conflictContent := newContent
 		if err != nil {
-			return fmt.Errorf("failed to create conflict copy: %w", err)
+			conflictContent = content
+		}
+		conflictPath, copyErr := s.createConflictCopy(path, []byte(conflictContent))
+		if copyErr != nil {
+			return fmt.Errorf("failed to create conflict copy: %w", copyErr)
 		}
 		return &ConflictError{
 			OriginalPath: path,
@@ -406,17 +634,30 @@ func (s *MarkdownSource) WriteItem(ctx context.Context, action string, data map[
 		}
 	}
 
-	// Read current content
-	contentBytes, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return err
 	}
-	content := string(contentBytes)
 
+	// Write back to file
+	if err := writeFileAtomic(path, []byte(newContent)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// Update stored mtime and hash after successful write
+	s.lastHash = contentHash([]byte(newContent))
+	if newInfo, err := os.Stat(path); err == nil {
+		s.lastMtime = newInfo.ModTime()
+	}
+
+	return nil
+}
+
+// applyAction performs a write action on the file content and returns the new content
+func (s *MarkdownSource) applyAction(content, action string, data map[string]interface{}) (string, error) {
 	// Find section boundaries
-	sectionStart, sectionEnd, headerLevel, err := s.findSectionBoundaries(content)
+	sectionStart, sectionEnd, _, err := s.findSectionBoundaries(content)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	sectionContent := content[sectionStart:sectionEnd]
@@ -435,42 +676,98 @@ func (s *MarkdownSource) WriteItem(ctx context.Context, action string, data map[
 		newSectionContent, err = s.deleteItem(sectionContent, format, data)
 	case "update":
 		newSectionContent, err = s.updateItem(sectionContent, format, data)
+	case "import":
+		newSectionContent, err = s.importRows(sectionContent, format, data)
 	default:
-		return fmt.Errorf("unknown action: %s", action)
+		return "", fmt.Errorf("unknown action: %s", action)
 	}
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Reconstruct file content
-	newContent := content[:sectionStart] + newSectionContent + content[sectionEnd:]
+	return content[:sectionStart] + newSectionContent + content[sectionEnd:], nil
+}
 
-	// Write back to file
-	if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+// fileLocks holds a mutex per markdown file, by absolute path. Every
+// connection has its own source for a block, and CSV imports use another,
+// so the sources of a file share its lock for writes.
+var fileLocks sync.Map
+
+// lockFile locks the file at path against writes by other markdown sources
+// and returns the function that unlocks it.
+func lockFile(path string) func() {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
 	}
+	mu, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
 
-	// Update stored mtime after successful write
-	if newInfo, err := os.Stat(path); err == nil {
-		s.mu.Lock()
-		s.lastMtime = newInfo.ModTime()
-		s.mu.Unlock()
+// writeFileAtomic replaces the file at path with content by writing a
+// temporary file next to it and renaming it into place, so readers and the
+// file watcher never see a partial write. The file keeps its permissions, and
+// a file that isn't writable is refused rather than replaced.
+func writeFileAtomic(path string, content []byte) error {
+	// Replace the target of a symlink, not the link itself
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	f.Close()
+	if err != nil {
+		return err
 	}
 
-	_ = headerLevel // Used in section boundary detection
-	return nil
-}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
 
-// createConflictCopy creates a conflict copy of the current pending changes
-// Returns the path to the conflict file
-func (s *MarkdownSource) createConflictCopy(originalPath string) (string, error) {
-	// Read the current file content (what the user was trying to modify)
-	content, err := os.ReadFile(originalPath)
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to read original file: %w", err)
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// detectLineEnding returns the dominant line ending in content: "\r\n" or "\n"
+func detectLineEnding(content string) string {
+	crlf := strings.Count(content, "\r\n")
+	lf := strings.Count(content, "\n") - crlf
+	if crlf > lf {
+		return "\r\n"
 	}
+	return "\n"
+}
+
+// normalizeLineEndings converts CRLF line endings to LF
+func normalizeLineEndings(content string) string {
+	return strings.ReplaceAll(content, "\r\n", "\n")
+}
+
+// contentHash returns the hex-encoded SHA-256 of the content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
 
+// createConflictCopy writes the given content to a conflict file next to the original
+// Returns the path to the conflict file
+func (s *MarkdownSource) createConflictCopy(originalPath string, content []byte) (string, error) {
 	// Generate conflict filename: file.conflict-{timestamp}.md
 	ext := filepath.Ext(originalPath)
 	base := strings.TrimSuffix(originalPath, ext)
@@ -490,7 +787,10 @@ func (s *MarkdownSource) findSectionBoundaries(content string) (start, end, head
 	anchorName := strings.TrimPrefix(s.anchor, "#")
 
 	// Use the same header finding logic as parseSection
-	matches := s.findSectionHeader(content, anchorName)
+	matches, err := s.findSectionHeader(content, anchorName)
+	if err != nil {
+		return 0, 0, 0, err
+	}
 	if matches == nil {
 		return 0, 0, 0, fmt.Errorf("section %q not found", s.anchor)
 	}
@@ -541,6 +841,10 @@ func (s *MarkdownSource) addItem(sectionContent, format string, data map[string]
 	switch format {
 	case "task":
 		text, _ := data["text"].(string)
+		text, err := formatTaskText(sanitizeItemText(text), data)
+		if err != nil {
+			return "", err
+		}
 		done, _ := data["done"].(bool)
 		checkbox := "[ ]"
 		if done {
@@ -550,7 +854,7 @@ func (s *MarkdownSource) addItem(sectionContent, format string, data map[string]
 
 	case "bullet":
 		text, _ := data["text"].(string)
-		newLine = fmt.Sprintf("- %s <!-- id:%s -->", text, id)
+		newLine = fmt.Sprintf("- %s <!-- id:%s -->", sanitizeItemText(text), id)
 
 	case "table":
 		// For tables, we need to find the headers first
@@ -562,7 +866,7 @@ func (s *MarkdownSource) addItem(sectionContent, format string, data map[string]
 		for _, h := range headers {
 			val := ""
 			if v, ok := data[h]; ok {
-				val = fmt.Sprintf("%v", v)
+				val = sanitizeTableCell(fmt.Sprintf("%v", v))
 			}
 			cells = append(cells, val)
 		}
@@ -577,6 +881,82 @@ func (s *MarkdownSource) addItem(sectionContent, format string, data map[string]
 	return trimmed + "\n" + newLine + "\n", nil
 }
 
+// importRows appends a table row for each record of the CSV text in
+// data["csv"]. The first CSV record names the columns, each of which must
+// match a table header (ignoring case and surrounding spaces); table columns
+// the CSV lacks are left blank. All rows are added in one write.
+func (s *MarkdownSource) importRows(sectionContent, format string, data map[string]interface{}) (string, error) {
+	if format != "table" {
+		return "", fmt.Errorf("cannot import: section %s is not a table", s.anchor)
+	}
+	headers := s.extractTableHeaders(sectionContent)
+	if len(headers) == 0 {
+		return "", fmt.Errorf("cannot import: no headers found")
+	}
+
+	text, _ := data["csv"].(string)
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(text, "\ufeff")))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", &ValidationError{Source: s.name, Field: "csv", Reason: err.Error()}
+	}
+	if len(records) < 2 {
+		return "", &ValidationError{Source: s.name, Field: "csv", Reason: "expected a header row followed by at least one row"}
+	}
+
+	// Map each CSV column to the table column it fills
+	headerIndex := make(map[string]int, len(headers))
+	for i, h := range headers {
+		headerIndex[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	columns := make([]int, len(records[0]))
+	filled := make(map[int]bool, len(records[0]))
+	var unknown []string
+	for i, name := range records[0] {
+		col, ok := headerIndex[strings.ToLower(strings.TrimSpace(name))]
+		switch {
+		case !ok:
+			unknown = append(unknown, fmt.Sprintf("%q", name))
+		case filled[col]:
+			return "", &ValidationError{Source: s.name, Field: "csv", Reason: fmt.Sprintf("column %q appears more than once", name)}
+		}
+		columns[i] = col
+		filled[col] = true
+	}
+	if len(unknown) > 0 {
+		return "", &ValidationError{Source: s.name, Field: "csv", Reason: fmt.Sprintf(
+			"column %s does not match a table header (%s)", strings.Join(unknown, ", "), strings.Join(headers, ", "))}
+	}
+
+	// Random IDs could collide within a large import, so check them against
+	// each other and the rows already in the table
+	used := make(map[string]bool)
+	for _, m := range itemIDPattern.FindAllStringSubmatch(sectionContent, -1) {
+		used[m[1]] = true
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(sectionContent, "\n"))
+	b.WriteString("\n")
+	for _, record := range records[1:] {
+		cells := make([]string, len(headers))
+		for i, value := range record {
+			cells[columns[i]] = sanitizeTableCell(value)
+		}
+		id := generateID()
+		for used[id] {
+			id = generateID()
+		}
+		used[id] = true
+		b.WriteString("| " + strings.Join(cells, " | ") + " | <!-- id:" + id + " -->\n")
+	}
+	return b.String(), nil
+}
+
+// itemIDPattern matches an item's id comment, capturing the ID
+var itemIDPattern = regexp.MustCompile(`<!--\s*id:(\w+)\s*-->`)
+
 // toggleItem toggles the done state of a task list item
 func (s *MarkdownSource) toggleItem(sectionContent, format string, data map[string]interface{}) (string, error) {
 	if format != "task" {
@@ -618,7 +998,7 @@ func (s *MarkdownSource) toggleItem(sectionContent, format string, data map[stri
 	}
 
 	if !found {
-		return "", fmt.Errorf("item with id %q not found", id)
+		return "", fmt.Errorf("item with id %q %w", id, errItemNotFound)
 	}
 
 	return strings.Join(lines, "\n"), nil
@@ -667,7 +1047,7 @@ func (s *MarkdownSource) deleteItem(sectionContent, format string, data map[stri
 	}
 
 	if !found {
-		return "", fmt.Errorf("item with id %q not found", id)
+		return "", fmt.Errorf("item with id %q %w", id, errItemNotFound)
 	}
 
 	return strings.Join(newLines, "\n"), nil
@@ -732,16 +1112,33 @@ func (s *MarkdownSource) updateItem(sectionContent, format string, data map[stri
 
 		switch format {
 		case "task":
-			// Update text and/or done state
-			if text, ok := data["text"].(string); ok {
+			// Update text (including due/priority metadata) and/or done state
+			text, hasText := data["text"].(string)
+			_, hasDue := data["due"]
+			_, hasPriority := data["priority"]
+			if hasText || hasDue || hasPriority {
+				if hasText {
+					text = sanitizeItemText(text)
+				} else {
+					// Keep the existing text, only rewrite the metadata tokens
+					taskPattern := regexp.MustCompile(`^\s*-\s+\[[ xX]\]\s+(.+?)(?:\s*<!--\s*id:\w+\s*-->)?$`)
+					if m := taskPattern.FindStringSubmatch(line); m != nil {
+						text = strings.TrimSpace(m[1])
+					}
+				}
+				var err error
+				text, err = formatTaskText(text, data)
+				if err != nil {
+					return "", err
+				}
 				if hasExplicitID {
 					// Replace text between checkbox and ID comment
 					taskPattern := regexp.MustCompile(`^(\s*-\s+\[[ xX]\]\s+)(.+?)(\s*<!--\s*id:\w+\s*-->)`)
-					lines[i] = taskPattern.ReplaceAllString(line, "${1}"+text+"${3}")
+					lines[i] = taskPattern.ReplaceAllString(line, "${1}"+escapeReplacement(text)+"${3}")
 				} else {
 					// No ID comment - just replace the text
 					taskPattern := regexp.MustCompile(`^(\s*-\s+\[[ xX]\]\s+)(.+)$`)
-					lines[i] = taskPattern.ReplaceAllString(line, "${1}"+text)
+					lines[i] = taskPattern.ReplaceAllString(line, "${1}"+escapeReplacement(text))
 				}
 			}
 			if done, ok := data["done"].(bool); ok {
@@ -756,6 +1153,7 @@ func (s *MarkdownSource) updateItem(sectionContent, format string, data map[stri
 		case "bullet":
 			// Update text
 			if text, ok := data["text"].(string); ok {
+				text = escapeReplacement(sanitizeItemText(text))
 				if hasExplicitID {
 					bulletPattern := regexp.MustCompile(`^(\s*-\s+)(.+?)(\s*<!--\s*id:\w+\s*-->)`)
 					lines[i] = bulletPattern.ReplaceAllString(line, "${1}"+text+"${3}")
@@ -768,12 +1166,20 @@ func (s *MarkdownSource) updateItem(sectionContent, format string, data map[stri
 		case "table":
 			// Update table cells by header name
 			headers := s.extractTableHeaders(sectionContent)
-			cells := s.parseTableCells(line)
+			tableRowPattern := regexp.MustCompile(`^\s*\|(.+)\|(?:\s*<!--\s*id:\w+\s*-->)?`)
+			rowMatch := tableRowPattern.FindStringSubmatch(line)
+			if rowMatch == nil {
+				return "", fmt.Errorf("item with id %q is not a table row", id)
+			}
+			cells := s.parseTableCells(rowMatch[1])
 
-			// Rebuild cells with updates
+			// Rebuild cells with updates, re-escaping existing cell content
+			for j := range cells {
+				cells[j] = sanitizeTableCell(cells[j])
+			}
 			for j, h := range headers {
 				if val, ok := data[h]; ok && j < len(cells) {
-					cells[j] = fmt.Sprintf("%v", val)
+					cells[j] = sanitizeTableCell(fmt.Sprintf("%v", val))
 				}
 			}
 
@@ -788,7 +1194,7 @@ func (s *MarkdownSource) updateItem(sectionContent, format string, data map[stri
 	}
 
 	if !found {
-		return "", fmt.Errorf("item with id %q not found", id)
+		return "", fmt.Errorf("item with id %q %w", id, errItemNotFound)
 	}
 
 	return strings.Join(lines, "\n"), nil
@@ -931,3 +1337,140 @@ func AddIDsToItems(content string) (string, bool) {
 
 	return strings.TrimSuffix(result.String(), "\n"), modified
 }
+
+var (
+	// idCommentPattern matches anything that looks like an item ID comment
+	idCommentPattern = regexp.MustCompile(`(?i)<!--\s*id\s*:.*?(?:-->|$)`)
+	// canonicalIDCommentPattern matches the exact form writes look items up by
+	canonicalIDCommentPattern = regexp.MustCompile(`^<!-- id:\w+ -->$`)
+	// repairableIDCommentPattern matches ID comments whose ID can be kept
+	repairableIDCommentPattern = regexp.MustCompile(`(?i)^<!--\s*id\s*:\s*(\w+)\s*-->$`)
+)
+
+// MarkdownIDIssue is a problem with the item IDs in a markdown data section.
+type MarkdownIDIssue struct {
+	Line    int    // Line number in the file (1-indexed)
+	Message string // What's wrong
+	Warning bool   // The section still works, but writes may hit the wrong item
+}
+
+// CheckMarkdownIDs checks the item IDs in the section of content at anchor.
+// Writes find items by their <!-- id:xxx --> comment, so duplicate IDs and
+// comments not in exactly that form are errors. Items without an ID fall back
+// to a content-based ID, which collides when two items have the same text;
+// they're reported as warnings unless the source is readonly.
+func CheckMarkdownIDs(name, content, anchor string, readonly bool) ([]MarkdownIDIssue, error) {
+	content = normalizeLineEndings(content)
+	s := &MarkdownSource{name: name, anchor: normalizeAnchor(anchor)}
+	start, end, _, err := s.findSectionBoundaries(content)
+	if err != nil {
+		return nil, err
+	}
+	firstLine := strings.Count(content[:start], "\n") + 1
+	section := content[start:end]
+	lines := strings.Split(section, "\n")
+
+	var issues []MarkdownIDIssue
+	seen := make(map[string]int)
+	malformed := make(map[int]bool)
+	for i, line := range lines {
+		lineNum := firstLine + i
+		for _, comment := range idCommentPattern.FindAllString(line, -1) {
+			if !canonicalIDCommentPattern.MatchString(comment) {
+				malformed[i] = true
+				issues = append(issues, MarkdownIDIssue{
+					Line:    lineNum,
+					Message: fmt.Sprintf("malformed ID comment %q, expected <!-- id:xxx -->", comment),
+				})
+			}
+		}
+		for _, id := range ScanMarkdownForIDs(line) {
+			if first, ok := seen[id]; ok {
+				issues = append(issues, MarkdownIDIssue{
+					Line:    lineNum,
+					Message: fmt.Sprintf("duplicate ID %q (first used on line %d)", id, first),
+				})
+				continue
+			}
+			seen[id] = lineNum
+		}
+	}
+
+	if !readonly {
+		withIDs, _ := addSectionIDs(section)
+		for i, line := range strings.Split(withIDs, "\n") {
+			if i < len(lines) && line != lines[i] && !malformed[i] {
+				issues = append(issues, MarkdownIDIssue{
+					Line:    firstLine + i,
+					Message: "item has no ID comment; items with the same text share a content-based ID",
+					Warning: true,
+				})
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// FixMarkdownIDs repairs the item IDs in the section of content at anchor:
+// malformed ID comments are rewritten (keeping the ID when it's usable),
+// duplicates get new IDs (EnsureUniqueIDs) and, unless the source is readonly,
+// items without an ID get one (AddIDsToItems). It returns the new content and
+// a description of each kind of fix made.
+func FixMarkdownIDs(name, content, anchor string, readonly bool) (string, []string, error) {
+	lineEnding := detectLineEnding(content)
+	content = normalizeLineEndings(content)
+	s := &MarkdownSource{name: name, anchor: normalizeAnchor(anchor)}
+	start, end, _, err := s.findSectionBoundaries(content)
+	if err != nil {
+		return "", nil, err
+	}
+	section := content[start:end]
+	var fixes []string
+
+	rewritten := 0
+	section = idCommentPattern.ReplaceAllStringFunc(section, func(comment string) string {
+		if canonicalIDCommentPattern.MatchString(comment) {
+			return comment
+		}
+		rewritten++
+		if m := repairableIDCommentPattern.FindStringSubmatch(comment); m != nil {
+			return "<!-- id:" + m[1] + " -->"
+		}
+		return "<!-- id:" + generateID() + " -->"
+	})
+	if rewritten > 0 {
+		fixes = append(fixes, fmt.Sprintf("Rewrote %d malformed ID comment(s) in %s", rewritten, s.anchor))
+	}
+
+	var modified bool
+	if section, modified = EnsureUniqueIDs(section); modified {
+		fixes = append(fixes, "Replaced duplicate IDs in "+s.anchor)
+	}
+	if !readonly {
+		if section, modified = addSectionIDs(section); modified {
+			fixes = append(fixes, "Added IDs to items without one in "+s.anchor)
+		}
+	}
+
+	content = content[:start] + section + content[end:]
+	if lineEnding == "\r\n" {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	return content, fixes, nil
+}
+
+// addSectionIDs runs AddIDsToItems on a section, keeping its trailing newlines.
+func addSectionIDs(section string) (string, bool) {
+	body := strings.TrimRight(section, "\n")
+	withIDs, modified := AddIDsToItems(body)
+	return withIDs + section[len(body):], modified
+}
+
+// normalizeAnchor adds the leading # that anchors are stored with.
+func normalizeAnchor(anchor string) string {
+	if !strings.HasPrefix(anchor, "#") {
+		return "#" + anchor
+	}
+	return anchor
+}
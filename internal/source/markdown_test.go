@@ -2,9 +2,12 @@ package source
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -1052,6 +1055,198 @@ func TestWriteItemDeleteTableRow(t *testing.T) {
 	}
 }
 
+func TestWriteItemImportTableRows(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Products {#products}
+
+| Name | Price |
+|------|-------|
+| Widget | $10 | <!-- id:p1 -->
+| Gadget | $20 | <!-- id:p2 -->
+
+# Notes
+
+Keep this.
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("products", "test.md", "#products", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	// Columns may be in any order and case; quoted cells keep commas and pipes
+	csvText := "\ufeffprice, name\n$5,Sprocket\n\"$1,000\",\"Big | Heavy\"\n$0,Freebie\n"
+	if err := src.WriteItem(context.Background(), "import", map[string]interface{}{"csv": csvText}); err != nil {
+		t.Fatalf("WriteItem(import) error = %v", err)
+	}
+
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 products after import, got %d", len(results))
+	}
+	ids := make(map[interface{}]bool)
+	for _, r := range results {
+		if ids[r["id"]] {
+			t.Errorf("duplicate id %v", r["id"])
+		}
+		ids[r["id"]] = true
+	}
+	if results[2]["Name"] != "Sprocket" || results[2]["Price"] != "$5" {
+		t.Errorf("first imported row = %v", results[2])
+	}
+	if results[3]["Name"] != "Big | Heavy" || results[3]["Price"] != "$1,000" {
+		t.Errorf("second imported row = %v", results[3])
+	}
+
+	content, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "# Notes\n\nKeep this.\n") {
+		t.Errorf("import disturbed the following section:\n%s", content)
+	}
+	if info, err := os.Stat(mdPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file mode after import = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("expected only test.md in the directory, got %d entries", len(entries))
+	}
+}
+
+func TestWriteItemImportRejectsBadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want string
+	}{
+		{"unknown column", "Name,Qty\nSprocket,3\n", `column "Qty" does not match a table header (Name, Price)`},
+		{"duplicate column", "Name,name\nSprocket,Spr\n", `column "name" appears more than once`},
+		{"header only", "Name,Price\n", "expected a header row followed by at least one row"},
+		{"ragged rows", "Name,Price\nSprocket\n", "wrong number of fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			mdContent := "# Products {#products}\n\n| Name | Price |\n|------|-------|\n| Widget | $10 | <!-- id:p1 -->\n"
+			mdPath := filepath.Join(tmpDir, "test.md")
+			if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+				t.Fatalf("Failed to write temp file: %v", err)
+			}
+			src, err := NewMarkdownSource("products", "test.md", "#products", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+			if err != nil {
+				t.Fatalf("NewMarkdownSource() error = %v", err)
+			}
+
+			err = src.WriteItem(context.Background(), "import", map[string]interface{}{"csv": tt.csv})
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("WriteItem(import) error = %v, want a ValidationError containing %q", err, tt.want)
+			}
+			if content, _ := os.ReadFile(mdPath); string(content) != mdContent {
+				t.Errorf("rejected import changed the file:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestWriteItemSourcesOfOneFileDontInterleave(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := "# Products {#products}\n\n| Name | Price |\n|------|-------|\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.md"), []byte(mdContent), 0600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	// Each writer has its own source, as connections and CSV imports do
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src, err := NewMarkdownSource("products", "test.md", "#products", tmpDir, "", false)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			csvText := fmt.Sprintf("Name,Price\nItem %d,$%d\n", i, i)
+			if err := src.WriteItem(context.Background(), "import", map[string]interface{}{"csv": csvText}); err != nil {
+				t.Errorf("WriteItem(import) error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	src, err := NewMarkdownSource("products", "test.md", "#products", tmpDir, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != writers {
+		t.Errorf("got %d rows after %d concurrent imports, want every row kept", len(results), writers)
+	}
+}
+
+func TestWriteItemsBulk(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Tasks {#tasks}
+
+- [ ] First <!-- id:t1 -->
+- [ ] Second <!-- id:t2 -->
+- [ ] Third <!-- id:t3 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	missing, err := src.WriteItems(context.Background(), "delete", []string{"t1", "gone", "t3"})
+	if err != nil {
+		t.Fatalf("WriteItems(delete) error = %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "gone" {
+		t.Errorf("WriteItems(delete) missing = %v, want [gone]", missing)
+	}
+
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 1 || results[0]["id"] != "t2" {
+		t.Fatalf("expected only t2 after bulk delete, got %v", results)
+	}
+
+	if _, err := src.WriteItems(context.Background(), "toggle", []string{"t2"}); err != nil {
+		t.Fatalf("WriteItems(toggle) error = %v", err)
+	}
+	results, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if results[0]["done"] != true {
+		t.Errorf("t2 done = %v after bulk toggle, want true", results[0]["done"])
+	}
+
+	if _, err := src.WriteItems(context.Background(), "update", []string{"t2"}); err == nil {
+		t.Error("WriteItems(update) should be rejected")
+	}
+}
+
 func TestWriteItemNotFoundError(t *testing.T) {
 	tmpDir := t.TempDir()
 	mdContent := `# Tasks {#tasks}
@@ -1337,6 +1532,114 @@ func TestConflictCopyContainsOriginalContent(t *testing.T) {
 	os.Remove(conflictErr.ConflictPath)
 }
 
+func TestWriteItemConflictDetectionByContentHash(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Tasks {#tasks}
+
+- [ ] Task 1 <!-- id:t1 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	_, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	info, err := os.Stat(mdPath)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	// External edit with the mtime restored, so only the content differs
+	externalContent := `# Tasks {#tasks}
+
+- [ ] Task 1 <!-- id:t1 -->
+- [ ] External task <!-- id:ext1 -->
+`
+	if err := os.WriteFile(mdPath, []byte(externalContent), 0644); err != nil {
+		t.Fatalf("Failed to write external modification: %v", err)
+	}
+	if err := os.Chtimes(mdPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	err = src.WriteItem(context.Background(), "add", map[string]interface{}{
+		"text": "My new task",
+	})
+
+	conflictErr, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected *ConflictError, got %T: %v", err, err)
+	}
+	defer os.Remove(conflictErr.ConflictPath)
+
+	matches, _ := filepath.Glob(filepath.Join(tmpDir, "test.conflict-*.md"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one conflict copy, found %v", matches)
+	}
+
+	// The conflict copy holds the user's change on top of the external edit
+	conflictContent, err := os.ReadFile(conflictErr.ConflictPath)
+	if err != nil {
+		t.Fatalf("Failed to read conflict file: %v", err)
+	}
+	if !strings.Contains(string(conflictContent), "My new task") {
+		t.Errorf("conflict file should contain the user's change:\n%s", conflictContent)
+	}
+	if !strings.Contains(string(conflictContent), "External task") {
+		t.Errorf("conflict file should contain the external edit:\n%s", conflictContent)
+	}
+
+	// The original file is not clobbered
+	current, _ := os.ReadFile(mdPath)
+	if string(current) != externalContent {
+		t.Errorf("original file was modified:\n%s", current)
+	}
+}
+
+func TestWriteItemNoConflictWhenOnlyMtimeChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Tasks {#tasks}
+
+- [ ] Task 1 <!-- id:t1 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	_, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// Touch the file without changing its content
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(mdPath, later, later); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	err = src.WriteItem(context.Background(), "add", map[string]interface{}{
+		"text": "Task 2",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(add) error = %v, want no conflict for unchanged content", err)
+	}
+}
+
 func TestSlugify(t *testing.T) {
 	tests := []struct {
 		input string
@@ -1487,3 +1790,705 @@ func TestTextBasedAnchorMatching(t *testing.T) {
 		}
 	})
 }
+
+// ============ Task metadata (@due, !priority) ============
+
+func TestParseTaskMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantTitle    string
+		wantDue      string
+		wantPriority string
+	}{
+		{"no metadata", "Buy milk", "Buy milk", "", ""},
+		{"due only", "Pay rent @due(2024-06-01)", "Pay rent", "2024-06-01", ""},
+		{"priority only", "Fix bug !high", "Fix bug", "", "high"},
+		{"both", "Ship release !medium @due(2024-07-15)", "Ship release", "2024-07-15", "medium"},
+		{"metadata in middle", "Call @due(2024-01-02) mom", "Call mom", "2024-01-02", ""},
+		{"invalid date stays in text", "Plan @due(2024-13-45)", "Plan @due(2024-13-45)", "", ""},
+		{"non-date due stays in text", "Plan @due(tomorrow)", "Plan @due(tomorrow)", "", ""},
+		{"unknown priority stays in text", "Relax !urgent", "Relax !urgent", "", ""},
+		{"exclamation in prose", "Wow!high five", "Wow!high five", "", ""},
+		{"spacing kept", "Call  mom\tat noon @due(2024-01-02)", "Call  mom\tat noon", "2024-01-02", ""},
+		{"leading token", "!low  Water plants", "Water plants", "", "low"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, due, priority := parseTaskMetadata(tt.text)
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+			if due != tt.wantDue {
+				t.Errorf("due = %q, want %q", due, tt.wantDue)
+			}
+			if priority != tt.wantPriority {
+				t.Errorf("priority = %q, want %q", priority, tt.wantPriority)
+			}
+		})
+	}
+}
+
+func TestFormatTaskTextKeepsText(t *testing.T) {
+	got, err := formatTaskText("Call  mom @due(2024-01-02)  today", map[string]interface{}{"priority": "high"})
+	if err != nil {
+		t.Fatalf("formatTaskText() error = %v", err)
+	}
+	if want := "Call  mom  today @due(2024-01-02) !high"; got != want {
+		t.Errorf("formatTaskText() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownSourceParseTaskMetadata(t *testing.T) {
+	content := `# Tasks {#tasks}
+
+- [ ] Pay rent @due(2024-06-01) !high <!-- id:t1 -->
+- [ ] Plain task <!-- id:t2 -->
+`
+	src := &MarkdownSource{anchor: "#tasks"}
+	results, err := src.parseSection(content)
+	if err != nil {
+		t.Fatalf("parseSection() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(results))
+	}
+
+	if results[0]["text"] != "Pay rent @due(2024-06-01) !high" {
+		t.Errorf("task 0 text = %q, want raw text preserved", results[0]["text"])
+	}
+	if results[0]["title"] != "Pay rent" {
+		t.Errorf("task 0 title = %q, want %q", results[0]["title"], "Pay rent")
+	}
+	if results[0]["due"] != "2024-06-01" {
+		t.Errorf("task 0 due = %v, want %q", results[0]["due"], "2024-06-01")
+	}
+	if results[0]["priority"] != "high" {
+		t.Errorf("task 0 priority = %v, want %q", results[0]["priority"], "high")
+	}
+
+	if results[1]["title"] != "Plain task" {
+		t.Errorf("task 1 title = %q, want %q", results[1]["title"], "Plain task")
+	}
+	if _, ok := results[1]["due"]; ok {
+		t.Error("task 1 should not have a due field")
+	}
+	if _, ok := results[1]["priority"]; ok {
+		t.Error("task 1 should not have a priority field")
+	}
+}
+
+func TestWriteItemTaskMetadataRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Tasks {#tasks}
+
+- [ ] Existing task <!-- id:t1 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	// Add with metadata fields
+	err = src.WriteItem(context.Background(), "add", map[string]interface{}{
+		"text":     "File taxes",
+		"due":      "2024-04-15",
+		"priority": "high",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(add) error = %v", err)
+	}
+
+	content, _ := os.ReadFile(mdPath)
+	if !strings.Contains(string(content), "- [ ] File taxes @due(2024-04-15) !high <!-- id:") {
+		t.Errorf("expected inline metadata in file, got:\n%s", content)
+	}
+
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	added := results[1]
+	if added["title"] != "File taxes" || added["due"] != "2024-04-15" || added["priority"] != "high" {
+		t.Errorf("round-trip mismatch: %v", added)
+	}
+
+	// Update only the priority - title and due date are preserved
+	err = src.WriteItem(context.Background(), "update", map[string]interface{}{
+		"id":       added["id"],
+		"priority": "low",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(update) error = %v", err)
+	}
+
+	results, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	updated := results[1]
+	if updated["title"] != "File taxes" || updated["due"] != "2024-04-15" || updated["priority"] != "low" {
+		t.Errorf("update mismatch: %v", updated)
+	}
+
+	// Clearing the due date removes the token
+	err = src.WriteItem(context.Background(), "update", map[string]interface{}{
+		"id":  added["id"],
+		"due": "",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(update) error = %v", err)
+	}
+
+	results, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if _, ok := results[1]["due"]; ok {
+		t.Errorf("expected due to be cleared, got %v", results[1]["due"])
+	}
+	if results[1]["text"] != "File taxes !low" {
+		t.Errorf("task text = %q, want %q", results[1]["text"], "File taxes !low")
+	}
+}
+
+func TestWriteItemTaskMetadataInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Tasks {#tasks}
+
+- [ ] Existing task <!-- id:t1 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	err = src.WriteItem(context.Background(), "add", map[string]interface{}{
+		"text": "Bad date",
+		"due":  "next week",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid due date") {
+		t.Errorf("expected invalid due date error, got %v", err)
+	}
+
+	err = src.WriteItem(context.Background(), "update", map[string]interface{}{
+		"id":       "t1",
+		"priority": "urgent",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid priority") {
+		t.Errorf("expected invalid priority error, got %v", err)
+	}
+}
+
+// ============ Write sanitization ============
+
+func TestSanitizeItemText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Buy milk", "Buy milk"},
+		{"comment delimiters", "a <!-- b --> c", "a  b  c"},
+		{"nested delimiters", "x <!<!---- y", "x  y"},
+		{"newlines", "line1\nline2\r\nline3", "line1 line2 line3"},
+		{"leading dash", "- sneaky", `\- sneaky`},
+		{"leading checkbox", "[x] sneaky", `\[x] sneaky`},
+		{"leading ordered marker", "1. sneaky", `\1. sneaky`},
+		{"dash inside text", "well - fine", "well - fine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeItemText(tt.in); got != tt.want {
+				t.Errorf("sanitizeItemText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteItemAdversarialTaskText(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Tasks {#tasks}
+
+- [ ] Existing task <!-- id:t1 -->
+
+# Other
+
+- [ ] Not in section <!-- id:other -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	inputs := []string{
+		"Fake id <!-- id:t1 -->",
+		"Close early --> trailing",
+		"- [ ] Nested task",
+		"Two\n- [x] lines\n## Heading",
+		"Price is $1 and ${1}",
+	}
+	for _, text := range inputs {
+		if err := src.WriteItem(context.Background(), "add", map[string]interface{}{"text": text}); err != nil {
+			t.Fatalf("WriteItem(add, %q) error = %v", text, err)
+		}
+	}
+
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 1+len(inputs) {
+		t.Fatalf("expected %d tasks, got %d: %v", 1+len(inputs), len(results), results)
+	}
+
+	// Every item must keep a unique ID; the injected id:t1 must not duplicate the existing one
+	seen := make(map[interface{}]bool)
+	for _, r := range results {
+		if seen[r["id"]] {
+			t.Errorf("duplicate id %v in results", r["id"])
+		}
+		seen[r["id"]] = true
+	}
+
+	if results[3]["text"] != "- [ ] Nested task" {
+		t.Errorf("nested task text = %q, want %q", results[3]["text"], "- [ ] Nested task")
+	}
+	if results[4]["text"] != "Two - [x] lines ## Heading" {
+		t.Errorf("multi-line text = %q, want collapsed to one line", results[4]["text"])
+	}
+	if results[5]["text"] != "Price is $1 and ${1}" {
+		t.Errorf("dollar text = %q, want literal", results[5]["text"])
+	}
+
+	content, _ := os.ReadFile(mdPath)
+	if got := strings.Count(string(content), "<!-- id:t1 -->"); got != 1 {
+		t.Errorf("expected exactly one id:t1 comment, got %d:\n%s", got, content)
+	}
+	if !strings.Contains(string(content), "# Other\n\n- [ ] Not in section <!-- id:other -->") {
+		t.Errorf("following section was corrupted:\n%s", content)
+	}
+
+	// Updating with adversarial text keeps the original ID
+	err = src.WriteItem(context.Background(), "update", map[string]interface{}{
+		"id":   "t1",
+		"text": "Renamed <!-- id:evil --> $2",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(update) error = %v", err)
+	}
+	results, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	// Only the comment delimiters are removed; the text's spacing stays
+	if results[0]["id"] != "t1" || results[0]["text"] != "Renamed  id:evil  $2" {
+		t.Errorf("updated task = %v, want id t1 with sanitized text", results[0])
+	}
+}
+
+func TestWriteItemAdversarialTableCells(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `# Items {#items}
+
+| name | qty |
+|------|-----|
+| Apple | 5 | <!-- id:r1 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("items", "test.md", "#items", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	err = src.WriteItem(context.Background(), "add", map[string]interface{}{
+		"name": "| broken | row",
+		"qty":  "1 <!-- id:r1 -->",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(add) error = %v", err)
+	}
+
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 rows, got %d: %v", len(results), results)
+	}
+	if results[1]["name"] != "| broken | row" {
+		t.Errorf("name = %q, want pipes preserved in a single cell", results[1]["name"])
+	}
+	if results[1]["qty"] != "1  id:r1" {
+		t.Errorf("qty = %q, want comment delimiters stripped", results[1]["qty"])
+	}
+	if results[1]["id"] == "r1" {
+		t.Error("injected id comment must not take over the row id")
+	}
+
+	// Update the original row with a pipe; the row keeps its ID and column count
+	err = src.WriteItem(context.Background(), "update", map[string]interface{}{
+		"id":   "r1",
+		"name": "Apple | Pear",
+	})
+	if err != nil {
+		t.Fatalf("WriteItem(update) error = %v", err)
+	}
+	results, err = src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if results[0]["id"] != "r1" || results[0]["name"] != "Apple | Pear" || results[0]["qty"] != "5" {
+		t.Errorf("updated row = %v", results[0])
+	}
+
+	content, _ := os.ReadFile(mdPath)
+	if got := strings.Count(string(content), "<!-- id:r1 -->"); got != 1 {
+		t.Errorf("expected exactly one id:r1 comment, got %d:\n%s", got, content)
+	}
+}
+
+// ============ Duplicate heading anchors ============
+
+func TestDuplicateHeadingAnchors(t *testing.T) {
+	content := `# Setup
+
+- [ ] First setup task
+
+# Setup
+
+- [ ] Second setup task
+- [ ] Another second task
+
+# Setup
+
+- [ ] Third setup task
+`
+
+	tests := []struct {
+		anchor    string
+		wantCount int
+		wantText  string
+	}{
+		{"#setup", 1, "First setup task"},
+		{"#setup-1", 2, "Second setup task"},
+		{"#setup-2", 1, "Third setup task"},
+		{"#setup-3", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.anchor, func(t *testing.T) {
+			src := &MarkdownSource{anchor: tt.anchor}
+			results, err := src.parseSection(content)
+			if err != nil {
+				t.Fatalf("parseSection() error = %v", err)
+			}
+			if len(results) != tt.wantCount {
+				t.Fatalf("expected %d tasks, got %d", tt.wantCount, len(results))
+			}
+			if tt.wantCount > 0 && results[0]["text"] != tt.wantText {
+				t.Errorf("first task = %q, want %q", results[0]["text"], tt.wantText)
+			}
+		})
+	}
+}
+
+func TestDuplicateHeadingAnchorsWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := `## Setup
+
+- [ ] First <!-- id:a1 -->
+
+## Setup
+
+- [ ] Second <!-- id:b1 -->
+`
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("setup", "test.md", "#setup-1", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	if err := src.WriteItem(context.Background(), "add", map[string]interface{}{"text": "Added"}); err != nil {
+		t.Fatalf("WriteItem(add) error = %v", err)
+	}
+
+	content, _ := os.ReadFile(mdPath)
+	if !strings.Contains(string(content), "- [ ] Second <!-- id:b1 -->\n- [ ] Added <!-- id:") {
+		t.Errorf("item should be added to the second Setup section:\n%s", content)
+	}
+	if strings.Contains(string(content), "- [ ] First <!-- id:a1 -->\n- [ ] Added") {
+		t.Errorf("item was added to the first Setup section:\n%s", content)
+	}
+}
+
+func TestAmbiguousAnchorError(t *testing.T) {
+	t.Run("colliding suffix", func(t *testing.T) {
+		// "Setup 1" slugifies to setup-1, as does the second "Setup"
+		content := `# Setup
+
+- [ ] A
+
+# Setup
+
+- [ ] B
+
+# Setup 1
+
+- [ ] C
+`
+		src := &MarkdownSource{name: "tasks", anchor: "#setup-1"}
+		_, err := src.parseSection(content)
+		if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+			t.Errorf("expected ambiguous anchor error, got %v", err)
+		}
+
+		// The unsuffixed anchor is still unambiguous
+		src = &MarkdownSource{name: "tasks", anchor: "#setup"}
+		results, err := src.parseSection(content)
+		if err != nil {
+			t.Fatalf("parseSection() error = %v", err)
+		}
+		if len(results) != 1 || results[0]["text"] != "A" {
+			t.Errorf("expected first section, got %v", results)
+		}
+	})
+
+	t.Run("duplicate explicit anchors", func(t *testing.T) {
+		content := `# One {#todos}
+
+- [ ] A
+
+# Two {#todos}
+
+- [ ] B
+`
+		src := &MarkdownSource{name: "tasks", anchor: "#todos"}
+		_, err := src.parseSection(content)
+		if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+			t.Errorf("expected ambiguous anchor error, got %v", err)
+		}
+
+		src = &MarkdownSource{name: "tasks", anchor: "#todos"}
+		if _, _, _, err := src.findSectionBoundaries(content); err == nil {
+			t.Error("expected findSectionBoundaries to report ambiguous anchor")
+		}
+	})
+}
+
+// ============ CRLF line endings ============
+
+func TestDetectLineEnding(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"a\nb\n", "\n"},
+		{"a\r\nb\r\n", "\r\n"},
+		{"a\r\nb\r\nc\n", "\r\n"},
+		{"a\nb\nc\r\n", "\n"},
+		{"", "\n"},
+	}
+	for _, tt := range tests {
+		if got := detectLineEnding(tt.content); got != tt.want {
+			t.Errorf("detectLineEnding(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownSourceParseCRLF(t *testing.T) {
+	content := "# Tasks {#tasks}\r\n\r\n- [ ] Buy milk <!-- id:t1 -->\r\n- [x] Walk dog\r\n\r\n# Items {#items}\r\n\r\n| name | qty |\r\n|------|-----|\r\n| Apple | 3 |\r\n"
+
+	src := &MarkdownSource{anchor: "#tasks"}
+	results, err := src.parseSection(content)
+	if err != nil {
+		t.Fatalf("parseSection() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(results))
+	}
+	if results[0]["text"] != "Buy milk" || results[0]["id"] != "t1" {
+		t.Errorf("task 0 = %v, want clean text and id", results[0])
+	}
+	if results[1]["text"] != "Walk dog" {
+		t.Errorf("task 1 text = %q, want no trailing \\r", results[1]["text"])
+	}
+
+	src = &MarkdownSource{anchor: "#items"}
+	results, err = src.parseSection(content)
+	if err != nil {
+		t.Fatalf("parseSection() error = %v", err)
+	}
+	if len(results) != 1 || results[0]["qty"] != "3" {
+		t.Errorf("table rows = %v, want qty 3", results)
+	}
+}
+
+func TestWriteItemPreservesCRLF(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdContent := "# Tasks {#tasks}\r\n\r\n- [ ] Task 1 <!-- id:t1 -->\r\n- [ ] Task 2 <!-- id:t2 -->\r\n\r\n# Notes\r\n\r\nSome text.\r\n"
+	mdPath := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdPath, []byte(mdContent), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	src, err := NewMarkdownSource("tasks", "test.md", "#tasks", tmpDir, filepath.Join(tmpDir, "index.md"), false)
+	if err != nil {
+		t.Fatalf("NewMarkdownSource() error = %v", err)
+	}
+
+	if _, err := src.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	actions := []struct {
+		action string
+		data   map[string]interface{}
+	}{
+		{"add", map[string]interface{}{"text": "Task 3"}},
+		{"toggle", map[string]interface{}{"id": "t1"}},
+		{"update", map[string]interface{}{"id": "t2", "text": "Task 2 renamed"}},
+		{"delete", map[string]interface{}{"id": "t1"}},
+	}
+	for _, a := range actions {
+		if err := src.WriteItem(context.Background(), a.action, a.data); err != nil {
+			t.Fatalf("WriteItem(%s) error = %v", a.action, err)
+		}
+	}
+
+	content, _ := os.ReadFile(mdPath)
+	lf := strings.Count(string(content), "\n")
+	crlf := strings.Count(string(content), "\r\n")
+	if lf != crlf {
+		t.Errorf("expected all line endings to be CRLF, got %d LF and %d CRLF:\n%q", lf, crlf, content)
+	}
+	if strings.Contains(string(content), "\r\r") {
+		t.Errorf("found doubled carriage returns:\n%q", content)
+	}
+
+	results, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 2 || results[0]["text"] != "Task 2 renamed" || results[1]["text"] != "Task 3" {
+		t.Errorf("results after CRLF round-trip = %v", results)
+	}
+}
+
+func TestCheckMarkdownIDs(t *testing.T) {
+	content := "# Tasks {#tasks}\n\n" +
+		"- [ ] Buy milk <!-- id:a1 -->\n" +
+		"- [ ] Walk dog <!-- id:a1 -->\n" +
+		"- [ ] Call mom <!--id: b2-->\n" +
+		"- [x] Pay rent\n" +
+		"\n# Notes\n\n- Not data <!-- id:a1 -->\n"
+
+	issues, err := CheckMarkdownIDs("tasks", content, "tasks", false)
+	if err != nil {
+		t.Fatalf("CheckMarkdownIDs() error = %v", err)
+	}
+	want := []MarkdownIDIssue{
+		{Line: 4, Message: `duplicate ID "a1" (first used on line 3)`},
+		{Line: 5, Message: `malformed ID comment "<!--id: b2-->", expected <!-- id:xxx -->`},
+		{Line: 6, Message: "item has no ID comment; items with the same text share a content-based ID", Warning: true},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("CheckMarkdownIDs() = %v, want %v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+
+	// Readonly sources never write, so missing IDs don't matter
+	issues, _ = CheckMarkdownIDs("tasks", content, "#tasks", true)
+	for _, issue := range issues {
+		if issue.Warning {
+			t.Errorf("readonly source reported %+v", issue)
+		}
+	}
+
+	if _, err := CheckMarkdownIDs("tasks", content, "#missing", false); err == nil {
+		t.Error("CheckMarkdownIDs() with unknown anchor should fail")
+	}
+}
+
+func TestFixMarkdownIDs(t *testing.T) {
+	content := "# Tasks {#tasks}\r\n\r\n" +
+		"- [ ] Buy milk <!-- id:a1 -->\r\n" +
+		"- [ ] Walk dog <!-- id:a1 -->\r\n" +
+		"- [ ] Call mom <!--id: b2-->\r\n" +
+		"- [ ] Water plants <!-- id:bad-id -->\r\n" +
+		"- [x] Pay rent\r\n" +
+		"\r\n# Notes\r\n\r\n- Not data\r\n"
+
+	fixed, fixes, err := FixMarkdownIDs("tasks", content, "#tasks", false)
+	if err != nil {
+		t.Fatalf("FixMarkdownIDs() error = %v", err)
+	}
+	if len(fixes) != 3 {
+		t.Errorf("fixes = %v, want malformed, duplicate and missing ID fixes", fixes)
+	}
+	if strings.Count(fixed, "\n") != strings.Count(fixed, "\r\n") {
+		t.Errorf("line endings not preserved:\n%q", fixed)
+	}
+	if !strings.Contains(fixed, "Call mom <!-- id:b2 -->") {
+		t.Errorf("malformed comment not normalized:\n%s", fixed)
+	}
+	if !strings.Contains(fixed, "- Not data\r\n") {
+		t.Errorf("item outside the section was changed:\n%s", fixed)
+	}
+
+	issues, err := CheckMarkdownIDs("tasks", fixed, "#tasks", false)
+	if err != nil {
+		t.Fatalf("CheckMarkdownIDs() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("issues after fix = %v\n%s", issues, fixed)
+	}
+
+	src := &MarkdownSource{anchor: "#tasks"}
+	results, err := src.parseSection(fixed)
+	if err != nil {
+		t.Fatalf("parseSection() error = %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected 5 tasks after fix, got %d", len(results))
+	}
+	if results[0]["id"] != "a1" || results[1]["id"] == "a1" {
+		t.Errorf("duplicate not resolved: %v, %v", results[0]["id"], results[1]["id"])
+	}
+
+	again, fixes, _ := FixMarkdownIDs("tasks", fixed, "#tasks", false)
+	if again != fixed || len(fixes) != 0 {
+		t.Errorf("second FixMarkdownIDs() made fixes %v", fixes)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -118,6 +119,42 @@ func TestRestSource_HeadersEnvExpansion(t *testing.T) {
 	}
 }
 
+func TestRestSource_EnvFileExpansion(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envPath, []byte("TEST_DOTENV_TOKEN=token-from-dotenv\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.LoadEnvFile(envPath); err != nil {
+		t.Fatalf("LoadEnvFile failed: %v", err)
+	}
+	defer os.Unsetenv("TEST_DOTENV_TOKEN")
+
+	var receivedAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"ok": true}})
+	}))
+	defer server.Close()
+
+	cfg := config.SourceConfig{
+		Type:    "rest",
+		From:    server.URL,
+		Headers: map[string]string{"Authorization": "Bearer ${TEST_DOTENV_TOKEN}"},
+	}
+	src, err := NewRestSourceWithConfig("test", cfg)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if _, err := src.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if receivedAuth != "Bearer token-from-dotenv" {
+		t.Errorf("Expected Authorization header from .env, got %q", receivedAuth)
+	}
+}
+
 func TestRestSource_WithQueryParams(t *testing.T) {
 	var receivedLimit string
 	var receivedStatus string
@@ -3,10 +3,11 @@ package source
 import (
 	"context"
 	"errors"
-	"log"
 	"math"
 	"math/rand"
 	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/logging"
 )
 
 // RetryConfig configures retry behavior
@@ -45,7 +46,7 @@ func WithRetry(ctx context.Context, source string, cfg RetryConfig, fn Retryable
 		result, err := fn(ctx)
 		if err == nil {
 			if attempt > 0 && cfg.EnableLog {
-				log.Printf("[source/%s] Succeeded on attempt %d", source, attempt+1)
+				logging.New("source/"+source).Infof("Succeeded on attempt %d", attempt+1)
 			}
 			return result, nil
 		}
@@ -55,7 +56,7 @@ func WithRetry(ctx context.Context, source string, cfg RetryConfig, fn Retryable
 		// Check if error is retryable
 		if !shouldRetry(err) {
 			if cfg.EnableLog {
-				log.Printf("[source/%s] Non-retryable error: %v", source, err)
+				logging.New("source/"+source).Warnf("Non-retryable error: %v", err)
 			}
 			return nil, err
 		}
@@ -64,7 +65,7 @@ func WithRetry(ctx context.Context, source string, cfg RetryConfig, fn Retryable
 		if attempt < cfg.MaxRetries {
 			delay := calculateDelay(attempt, cfg)
 			if cfg.EnableLog {
-				log.Printf("[source/%s] Attempt %d failed (%v), retrying in %v...", source, attempt+1, err, delay)
+				logging.New("source/"+source).Warnf("Attempt %d failed (%v), retrying in %v...", attempt+1, err, delay)
 			}
 
 			select {
@@ -77,7 +78,7 @@ func WithRetry(ctx context.Context, source string, cfg RetryConfig, fn Retryable
 	}
 
 	if cfg.EnableLog {
-		log.Printf("[source/%s] All %d attempts failed", source, cfg.MaxRetries+1)
+		logging.New("source/"+source).Errorf("All %d attempts failed", cfg.MaxRetries+1)
 	}
 
 	// If lastErr is already a SourceError, update its Retryable flag instead of wrapping
@@ -3,6 +3,7 @@ package source
 import (
 	"database/sql"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -13,7 +14,7 @@ import (
 // given table. This is a standalone function for use at parse time, before
 // a full SQLiteSource is created.
 //
-// Returns nil (no error) if the table doesn't exist or the DB can't be opened.
+// Returns nil (no error) if the table or DB doesn't exist or can't be opened.
 // This allows graceful degradation — auto-tables will use text inputs as fallback.
 func QuerySQLiteSchema(dbPath, table, siteDir string) []ColumnInfo {
 	if dbPath == "" || table == "" {
@@ -29,6 +30,11 @@ func QuerySQLiteSchema(dbPath, table, siteDir string) []ColumnInfo {
 		dbPath = filepath.Join(siteDir, dbPath)
 	}
 
+	// Opening a missing database would create an empty file
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil
+	}
+
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return nil
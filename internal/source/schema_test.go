@@ -2,6 +2,7 @@ package source
 
 import (
 	"database/sql"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -86,6 +87,16 @@ func TestQuerySQLiteSchema_NonexistentDB(t *testing.T) {
 	}
 }
 
+func TestQuerySQLiteSchema_MissingDBNotCreated(t *testing.T) {
+	dir := t.TempDir()
+	if schema := QuerySQLiteSchema("./missing.db", "items", dir); schema != nil {
+		t.Errorf("expected nil for missing DB, got %v", schema)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.db")); !os.IsNotExist(err) {
+		t.Error("QuerySQLiteSchema should not create a missing database")
+	}
+}
+
 func TestQuerySQLiteSchema_Required(t *testing.T) {
 	dir := t.TempDir()
 	dbPath := filepath.Join(dir, "test.db")
@@ -40,6 +40,16 @@ type WritableSource interface {
 	IsReadonly() bool
 }
 
+// BulkWritableSource is a WritableSource that can apply one action to many
+// items in a single write.
+type BulkWritableSource interface {
+	WritableSource
+
+	// WriteItems applies action ("toggle" or "delete") to each item in ids.
+	// IDs that match no item are skipped and returned.
+	WriteItems(ctx context.Context, action string, ids []string) (missing []string, err error)
+}
+
 // ColumnInfo describes a column in a data source's schema.
 type ColumnInfo struct {
 	Name     string // Column name (e.g., "amount")
@@ -229,12 +239,16 @@ func createSource(name string, cfg config.SourceConfig, siteDir, currentFile str
 	case "markdown":
 		// Use IsReadonly() which defaults to true if not specified
 		return NewMarkdownSource(name, cfg.File, cfg.Anchor, siteDir, currentFile, cfg.IsReadonly())
+	case "frontmatter":
+		return NewFrontmatterSource(name, cfg.File, siteDir, currentFile, cfg.Options)
 	case "sqlite":
 		return NewSQLiteSource(name, cfg.DB, cfg.Table, siteDir, cfg.IsReadonly())
 	case "wasm":
 		return wasm.NewWasmSource(name, cfg.Path, siteDir, cfg.Options)
 	case "graphql":
 		return NewGraphQLSource(name, cfg, siteDir)
+	case "upload":
+		return NewUploadSource(name, cfg, siteDir)
 	default:
 		return nil, &UnsupportedSourceError{Type: cfg.Type}
 	}
@@ -0,0 +1,295 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+// ErrUploadTooLarge is returned by UploadSource.Store for a file over the
+// source's max_size.
+var ErrUploadTooLarge = errors.New("file is too large")
+
+// ErrUploadType is returned by UploadSource.Store for a file whose type is
+// not in the source's types.
+var ErrUploadType = errors.New("file type is not allowed")
+
+// ErrUploadDir is returned by NewUploadSource and UploadDir for a path that
+// isn't a relative directory inside a "_" directory of the site, which
+// would let uploads be served as pages or static files.
+var ErrUploadDir = errors.New("upload path must be a relative directory inside one starting with _")
+
+// UploadFields are the fields of each row an UploadSource returns.
+var UploadFields = []string{"id", "modified", "name", "size", "type"}
+
+// maxUploadNameLen bounds stored file names, extension included.
+const maxUploadNameLen = 100
+
+// unsafeUploadExts are extensions an upload source without types refuses:
+// files a browser would render as a page, or the site as one.
+var unsafeUploadExts = map[string]bool{
+	".html": true,
+	".htm":  true,
+	".svg":  true,
+	".md":   true,
+}
+
+// uploadTypes adds types for common upload extensions that Go's built-in
+// MIME table lacks, so they don't depend on the host's mime.types.
+var uploadTypes = map[string]string{
+	".csv": "text/csv",
+	".md":  "text/markdown",
+	".txt": "text/plain",
+}
+
+// UploadSource stores files sent to the /action endpoint in a directory and
+// lists them, newest first, with one row of metadata per file: id and name
+// (the stored file name), size in bytes, type (MIME type) and modified
+// (RFC 3339).
+type UploadSource struct {
+	name    string
+	dir     string
+	maxSize int64
+	types   []string
+}
+
+// NewUploadSource creates an upload source storing files in cfg's upload
+// directory, relative to siteDir.
+func NewUploadSource(name string, cfg config.SourceConfig, siteDir string) (*UploadSource, error) {
+	dir, err := UploadDir(cfg, siteDir)
+	if err != nil {
+		return nil, fmt.Errorf("upload source %q: %w", name, err)
+	}
+	return &UploadSource{
+		name:    name,
+		dir:     dir,
+		maxSize: cfg.GetUploadMaxSize(),
+		types:   cfg.GetUploadTypes(),
+	}, nil
+}
+
+// UploadDir returns the absolute directory an upload source stores files in.
+// The path must be relative to siteDir, stay inside it and be within a
+// directory starting with "_", such as the default _uploads or
+// data/_files, so stored files are never discovered as pages or served.
+func UploadDir(cfg config.SourceConfig, siteDir string) (string, error) {
+	dir := filepath.ToSlash(cfg.GetUploadDir())
+	if path.IsAbs(dir) || filepath.IsAbs(dir) {
+		return "", fmt.Errorf("%w: %q", ErrUploadDir, dir)
+	}
+	hidden := false
+	for _, segment := range strings.Split(dir, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrUploadDir, dir)
+		}
+		hidden = hidden || strings.HasPrefix(segment, "_")
+	}
+	if !hidden {
+		return "", fmt.Errorf("%w: %q", ErrUploadDir, dir)
+	}
+	return filepath.Join(siteDir, filepath.FromSlash(dir)), nil
+}
+
+// Name returns the source identifier
+func (s *UploadSource) Name() string {
+	return s.name
+}
+
+// Fetch lists the stored files. A directory that doesn't exist yet has no
+// files.
+func (s *UploadSource) Fetch(ctx context.Context) ([]map[string]interface{}, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to list files: %w", s.name, err)
+	}
+
+	var infos []os.FileInfo
+	for _, entry := range entries {
+		// Skip in-progress uploads and anything that isn't a plain file
+		if strings.HasPrefix(entry.Name(), ".") || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed since ReadDir
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].ModTime().Equal(infos[j].ModTime()) {
+			return infos[i].ModTime().After(infos[j].ModTime())
+		}
+		return infos[i].Name() < infos[j].Name()
+	})
+
+	rows := make([]map[string]interface{}, len(infos))
+	for i, info := range infos {
+		rows[i] = fileRow(info)
+	}
+	return rows, nil
+}
+
+// Store saves r as a new file named after filename and returns its row. The
+// name is reduced to a safe base name and made unique, so a client can't
+// write outside the directory or replace an earlier upload. Nothing is kept
+// when the file is too large or of a type the source doesn't accept.
+func (s *UploadSource) Store(filename string, r io.Reader) (map[string]interface{}, error) {
+	name := sanitizeFilename(filename)
+	if !s.accepts(name) {
+		accepted := strings.Join(s.types, ", ")
+		if accepted == "" {
+			accepted = "anything but .html, .htm, .svg and .md"
+		}
+		return nil, fmt.Errorf("upload source %q: %w: %s (accepted: %s)", s.name, ErrUploadType, name, accepted)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to create directory: %w", s.name, err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to create file: %w", s.name, err)
+	}
+	defer os.Remove(tmp.Name()) // Stored under its new name by then
+
+	// Read one byte past the limit to tell a full-size file from an oversized one
+	n, err := io.Copy(tmp, io.LimitReader(r, s.maxSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to write file: %w", s.name, err)
+	}
+	if n > s.maxSize {
+		return nil, fmt.Errorf("upload source %q: %w (limit %d bytes)", s.name, ErrUploadTooLarge, s.maxSize)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to write file: %w", s.name, err)
+	}
+
+	dest, err := s.claimName(tmp.Name(), name)
+	if err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to store file: %w", s.name, err)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return nil, fmt.Errorf("upload source %q: failed to store file: %w", s.name, err)
+	}
+	return fileRow(info), nil
+}
+
+// Close is a no-op for upload sources
+func (s *UploadSource) Close() error {
+	return nil
+}
+
+// accepts reports whether a file named name is of one of the source's types.
+// Without types, any file but those in unsafeUploadExts is accepted.
+func (s *UploadSource) accepts(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if len(s.types) == 0 {
+		return !unsafeUploadExts[ext]
+	}
+	mimeType := fileType(name)
+	for _, t := range s.types {
+		switch {
+		case strings.HasPrefix(t, "."):
+			if t == ext {
+				return true
+			}
+		case strings.HasSuffix(t, "/*"):
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(t, "*")) {
+				return true
+			}
+		case t == mimeType:
+			return true
+		}
+	}
+	return false
+}
+
+// claimName links the file at tmp into the directory as name, or as name
+// with a -1, -2, ... suffix before its extension if a file by that name
+// already exists, and returns its path. Linking fails rather than replacing
+// an existing file, so concurrent uploads of the same name can't overwrite
+// each other.
+func (s *UploadSource) claimName(tmp, name string) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; ; i++ {
+		dest := filepath.Join(s.dir, candidate)
+		err := os.Link(tmp, dest)
+		if err == nil {
+			return dest, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// sanitizeFilename reduces a client-supplied file name to a base name of
+// ASCII letters, digits, '.', '-' and '_'. Directories are dropped, other
+// characters become '-', and leading dots and dashes are removed so the
+// file is neither hidden nor mistaken for a flag.
+func sanitizeFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, `\`, "/"))
+	var b strings.Builder
+	for _, r := range filename {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	name := strings.TrimLeft(b.String(), ".-")
+	if name == "" {
+		return "upload"
+	}
+	if len(name) > maxUploadNameLen {
+		ext := filepath.Ext(name)
+		if len(ext) > maxUploadNameLen/2 {
+			ext = ""
+		}
+		name = name[:maxUploadNameLen-len(ext)] + ext
+	}
+	return name
+}
+
+// fileType returns the MIME type for a file name's extension.
+func fileType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := uploadTypes[ext]; ok {
+		return t
+	}
+	if t, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func fileRow(info os.FileInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       info.Name(),
+		"name":     info.Name(),
+		"size":     info.Size(),
+		"type":     fileType(info.Name()),
+		"modified": info.ModTime().UTC().Format(time.RFC3339),
+	}
+}
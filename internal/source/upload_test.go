@@ -0,0 +1,193 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/config"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"report.csv", "report.csv"},
+		{"../../etc/passwd", "passwd"},
+		{`..\..\windows\win.ini`, "win.ini"},
+		{"/abs/path/photo.png", "photo.png"},
+		{".env", "env"},
+		{"--help.txt", "help.txt"},
+		{"my report (final).csv", "my-report--final-.csv"},
+		{"résumé.pdf", "r-sum-.pdf"},
+		{"..", "upload"},
+		{"", "upload"},
+		{strings.Repeat("a", 150) + ".csv", strings.Repeat("a", 96) + ".csv"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.in); got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUploadSourceStore(t *testing.T) {
+	siteDir := t.TempDir()
+	cfg := config.SourceConfig{Type: "upload", Options: map[string]string{"max_size": "16", "types": "text/csv, image/*, .pdf"}}
+	src, err := NewUploadSource("files", cfg, siteDir)
+	if err != nil {
+		t.Fatalf("NewUploadSource() error = %v", err)
+	}
+	dir := filepath.Join(siteDir, "_uploads")
+
+	// Nothing uploaded yet
+	rows, err := src.Fetch(context.Background())
+	if err != nil || len(rows) != 0 {
+		t.Fatalf("Fetch() before upload = %v, %v, want no rows", rows, err)
+	}
+
+	row, err := src.Store("../data.csv", strings.NewReader("a,b\n1,2\n"))
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if row["name"] != "data.csv" || row["size"] != int64(8) || row["type"] != "text/csv" {
+		t.Errorf("Store() row = %v", row)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data.csv")); err != nil {
+		t.Errorf("stored file missing: %v", err)
+	}
+
+	// A second file by the same name doesn't replace the first
+	row, err = src.Store("data.csv", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Store() duplicate error = %v", err)
+	}
+	if row["name"] != "data-1.csv" {
+		t.Errorf("duplicate stored as %v, want data-1.csv", row["name"])
+	}
+
+	if _, err := src.Store("photo.png", strings.NewReader("png")); err != nil {
+		t.Errorf("Store() image/* error = %v", err)
+	}
+	if _, err := src.Store("big.csv", strings.NewReader(strings.Repeat("x", 17))); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("Store() oversized error = %v, want ErrUploadTooLarge", err)
+	}
+	if _, err := src.Store("script.sh", strings.NewReader("rm -rf /")); !errors.Is(err, ErrUploadType) {
+		t.Errorf("Store() disallowed type error = %v, want ErrUploadType", err)
+	}
+
+	// Rejected files leave nothing behind, including temporary files
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "data-1.csv,data.csv,photo.png" {
+		t.Errorf("upload dir = %v, want only the accepted files", names)
+	}
+
+	rows, err = src.Fetch(context.Background())
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("Fetch() = %v, %v, want 3 rows", rows, err)
+	}
+}
+
+func TestUploadSourceConcurrentSameName(t *testing.T) {
+	siteDir := t.TempDir()
+	cfg := config.SourceConfig{Type: "upload"}
+
+	// Each request builds its own source, as the /action endpoint does
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			src, err := NewUploadSource("files", cfg, siteDir)
+			if err == nil {
+				_, err = src.Store("same.txt", strings.NewReader(fmt.Sprint(i)))
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(siteDir, "_uploads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make(map[string]bool)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(siteDir, "_uploads", e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[string(data)] = true
+	}
+	if len(entries) != n || len(contents) != n {
+		t.Errorf("stored %d files with %d distinct contents, want %d of each", len(entries), len(contents), n)
+	}
+}
+
+func TestUploadDir(t *testing.T) {
+	siteDir := t.TempDir()
+	tests := []struct {
+		path string
+		want string // Relative to siteDir; empty for ErrUploadDir
+	}{
+		{"", "_uploads"},
+		{"_files", "_files"},
+		{"data/_files", "data/_files"},
+		{"_files/2024", "_files/2024"},
+		{"uploads", ""},
+		{"/tmp/_uploads", ""},
+		{"../_uploads", ""},
+		{"_uploads/../../x", ""},
+	}
+	for _, tt := range tests {
+		got, err := UploadDir(config.SourceConfig{Type: "upload", Path: tt.path}, siteDir)
+		if tt.want == "" {
+			if !errors.Is(err, ErrUploadDir) {
+				t.Errorf("UploadDir(%q) = %q, %v, want ErrUploadDir", tt.path, got, err)
+			}
+			continue
+		}
+		if err != nil || got != filepath.Join(siteDir, filepath.FromSlash(tt.want)) {
+			t.Errorf("UploadDir(%q) = %q, %v, want %s under the site", tt.path, got, err, tt.want)
+		}
+	}
+
+	if _, err := NewUploadSource("files", config.SourceConfig{Type: "upload", Path: "public"}, siteDir); !errors.Is(err, ErrUploadDir) {
+		t.Errorf("NewUploadSource() with a served path error = %v, want ErrUploadDir", err)
+	}
+}
+
+func TestUploadSourceRefusesPagesWithoutTypes(t *testing.T) {
+	src, err := NewUploadSource("files", config.SourceConfig{Type: "upload"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUploadSource() error = %v", err)
+	}
+	for _, name := range []string{"page.html", "page.HTM", "logo.svg", "notes.md"} {
+		if _, err := src.Store(name, strings.NewReader("<script>alert(1)</script>")); !errors.Is(err, ErrUploadType) {
+			t.Errorf("Store(%q) error = %v, want ErrUploadType", name, err)
+		}
+	}
+	if _, err := src.Store("report.pdf", strings.NewReader("%PDF")); err != nil {
+		t.Errorf("Store(report.pdf) error = %v", err)
+	}
+}
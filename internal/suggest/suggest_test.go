@@ -0,0 +1,19 @@
+package suggest
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"site", "title", "type", "server", "sources"}
+	tests := map[string]string{
+		"titel":   "title",
+		"Sever":   "server",
+		"source":  "sources",
+		"sreVer":  "server",
+		"styling": "",
+	}
+	for name, want := range tests {
+		if got := Closest(name, candidates); got != want {
+			t.Errorf("Closest(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package tracing wraps OpenTelemetry spans around page renders, source
+// fetches and block template builds.
+//
+// Tracing is off unless OTEL_EXPORTER_OTLP_ENDPOINT is set, in which case
+// Setup installs a tracer provider that exports spans over OTLP/HTTP. The
+// other standard OTEL_* variables, such as OTEL_SERVICE_NAME and
+// OTEL_EXPORTER_OTLP_HEADERS, are honored by the exporter. While off, Start
+// returns the span already in the context without calling into OpenTelemetry,
+// so instrumented code pays for a single atomic load.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EndpointEnv is the variable that turns tracing on.
+const EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// instrumentationName names the tracer, as OpenTelemetry recommends, after
+// the instrumented module.
+const instrumentationName = "github.com/livetemplate/tinkerdown"
+
+// Attribute keys of tinkerdown's spans.
+const (
+	AttrRoute      = attribute.Key("http.route")
+	AttrPage       = attribute.Key("tinkerdown.page")
+	AttrSource     = attribute.Key("tinkerdown.source")
+	AttrSourceType = attribute.Key("tinkerdown.source.type")
+	AttrBlock      = attribute.Key("tinkerdown.block")
+	AttrCacheHit   = attribute.Key("tinkerdown.cache.hit")
+	AttrRows       = attribute.Key("tinkerdown.rows")
+)
+
+var enabled atomic.Bool
+
+// Setup exports spans to the OTLP endpoint named by OTEL_EXPORTER_OTLP_ENDPOINT.
+// Without it, tracing stays off and Setup does nothing. The returned function
+// flushes buffered spans and stops the exporter; it's never nil.
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if os.Getenv(EndpointEnv) == "" {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, err
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(serviceName())))
+	if err != nil {
+		return noop, errors.Join(err, exporter.Shutdown(ctx))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	Enable(provider)
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// serviceName is OTEL_SERVICE_NAME, or "tinkerdown".
+func serviceName() string {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return "tinkerdown"
+}
+
+// Enable sends spans to provider, as Setup does with its exporter. Tests use
+// it with a recording span processor.
+func Enable(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	enabled.Store(true)
+}
+
+// Disable turns tracing off again.
+func Disable() {
+	enabled.Store(false)
+}
+
+// Enabled reports whether spans are being recorded.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Start starts a span named name as a child of any span in ctx. While tracing
+// is off, it returns ctx unchanged and a span that does nothing, so that
+// ending it can't end a span of the caller's.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, when not nil, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	t.Setenv(EndpointEnv, "")
+	shutdown, err := Setup(context.Background())
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if Enabled() {
+		t.Error("tracing should stay off without an endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+
+	ctx := context.Background()
+	got, span := Start(ctx, "noop")
+	if got != ctx || span.IsRecording() {
+		t.Error("Start() while off should return the context and a span that records nothing")
+	}
+
+	// A span already in the context is left alone
+	recorder := tracetest.NewSpanRecorder()
+	ctx, parent := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(ctx, "parent")
+	got, span = Start(ctx, "noop")
+	End(span, errors.New("failed"))
+	if got != ctx || span.IsRecording() || !parent.IsRecording() {
+		t.Error("Start() while off should not return or end the span in the context")
+	}
+	parent.End()
+	if spans := recorder.Ended(); len(spans) != 1 || len(spans[0].Events()) != 0 {
+		t.Errorf("parent span = %v, want it ended once with no error recorded", spans)
+	}
+}
+
+func TestStartRecordsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	Enable(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer Disable()
+
+	ctx, parent := Start(context.Background(), "parent", AttrRoute.String("/"))
+	_, child := Start(ctx, "child", AttrSource.String("tasks"))
+	End(child, errors.New("fetch failed"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	child0, parent0 := spans[0], spans[1]
+	if child0.Parent().SpanID() != parent0.SpanContext().SpanID() {
+		t.Error("child span should have the parent from the context")
+	}
+	if child0.Status().Description != "fetch failed" || len(child0.Events()) != 1 {
+		t.Errorf("child span status = %+v, want the error recorded", child0.Status())
+	}
+	if attrs := parent0.Attributes(); len(attrs) != 1 || attrs[0].Value.AsString() != "/" {
+		t.Errorf("parent attributes = %v, want the route", attrs)
+	}
+}
@@ -0,0 +1,106 @@
+package tinkerdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Speaker notes for presentation mode:
+//
+//	## Pricing
+//
+//	<!-- notes:
+//	Mention the discount for yearly plans.
+//	-->
+//
+// A notes comment on its own lines renders as an inert
+// <template class="speaker-notes"> in place, so the notes stay with their H2
+// section but are never shown to the audience; the presenter view reads them
+// from there. Other HTML comments are omitted as before.
+
+// speakerNotesPattern matches a whole notes comment, capturing the notes.
+var speakerNotesPattern = regexp.MustCompile(`(?s)^<!--\s*notes:(.*?)-->$`)
+
+// speakerNotesRenderer renders HTML blocks, turning notes comments into
+// speaker-notes templates.
+type speakerNotesRenderer struct{}
+
+func (r *speakerNotesRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+}
+
+func (r *speakerNotesRenderer) renderHTMLBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.HTMLBlock)
+	if notes, ok := speakerNotes(n, source); ok {
+		if entering {
+			_, _ = w.WriteString(`<template class="speaker-notes">` + html.EscapeString(notes) + "</template>\n")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	// Same as goldmark's renderer without WithUnsafe
+	if entering || n.HasClosure() {
+		_, _ = w.WriteString("<!-- raw HTML omitted -->\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+// speakerNotes returns the notes in n if it is a notes comment, with the
+// common indentation and surrounding blank lines removed.
+func speakerNotes(n *ast.HTMLBlock, source []byte) (string, bool) {
+	if n.HTMLBlockType != ast.HTMLBlockType2 {
+		return "", false
+	}
+	var b strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		b.Write(line.Value(source))
+	}
+	if n.HasClosure() {
+		b.Write(n.ClosureLine.Value(source))
+	}
+	m := speakerNotesPattern.FindStringSubmatch(strings.TrimSpace(b.String()))
+	if m == nil {
+		return "", false
+	}
+	return dedent(m[1]), true
+}
+
+// dedent trims blank lines around text and the indentation its lines share.
+func dedent(text string) string {
+	lines := strings.Split(strings.Trim(text, "\n"), "\n")
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// speakerNotesExtension registers speakerNotesRenderer with goldmark.
+type speakerNotesExtension struct{}
+
+// Extend implements goldmark.Extender.
+func (e *speakerNotesExtension) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&speakerNotesRenderer{}, 500),
+	))
+}
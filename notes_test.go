@@ -0,0 +1,31 @@
+package tinkerdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpeakerNotes(t *testing.T) {
+	content := "# Talk\n\n## Intro\n\nHello\n\n<!-- notes:\n  Welcome everyone.\n  Mention <the> demo & Q&A.\n-->\n\n<!-- not notes -->\n\n## Pricing\n\n<!-- notes: Yearly plans are cheaper. -->\n\nPlans\n"
+	_, _, html, err := ParseMarkdown([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error: %v", err)
+	}
+
+	intro, pricing, ok := strings.Cut(html, `<h2 id="pricing">`)
+	if !ok {
+		t.Fatalf("missing pricing section:\n%s", html)
+	}
+	if want := "<template class=\"speaker-notes\">Welcome everyone.\nMention &lt;the&gt; demo &amp; Q&amp;A.</template>\n"; !strings.Contains(intro, want) {
+		t.Errorf("intro section missing its notes %q:\n%s", want, intro)
+	}
+	if !strings.Contains(pricing, `<template class="speaker-notes">Yearly plans are cheaper.</template>`) {
+		t.Errorf("pricing section missing its notes:\n%s", pricing)
+	}
+	if strings.Contains(html, "notes:") || strings.Contains(intro, "Yearly") {
+		t.Errorf("notes should be parsed out of their comments into their own section:\n%s", html)
+	}
+	if !strings.Contains(intro, "<!-- raw HTML omitted -->") {
+		t.Error("other comments should still be omitted")
+	}
+}
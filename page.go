@@ -9,11 +9,14 @@ import (
 	"strings"
 )
 
-// Pre-compiled regexes for auto-rendering (tables, lists, selects) (performance optimization)
+// Pre-compiled regexes for auto-rendering (tables, lists, selects, charts, heatmaps, stats) (performance optimization)
 var (
 	tableRegex          = regexp.MustCompile(`(?s)<table([^>]*lvt-source="[^"]+[^>]*)>(.*?)</table>`)
 	ulListRegex         = regexp.MustCompile(`(?s)<ul([^>]*lvt-source="[^"]+[^>]*)>(.*?)</ul>`)
 	olListRegex         = regexp.MustCompile(`(?s)<ol([^>]*lvt-source="[^"]+[^>]*)>(.*?)</ol>`)
+	chartCanvasRegex    = regexp.MustCompile(`(?s)<canvas([^>]*lvt-source="[^"]+[^>]*)>(.*?)</canvas>`)
+	heatmapDivRegex     = regexp.MustCompile(`(?s)<div([^>]*\blvt-heatmap\b[^>]*)>(.*?)</div>`)
+	statDivRegex        = regexp.MustCompile(`(?s)<div([^>]*\blvt-stat\b[^>]*)>(.*?)</div>`)
 	lvtSourceRegex      = regexp.MustCompile(`\s*lvt-source="[^"]*"`)
 	lvtColumnsRegex     = regexp.MustCompile(`\s*lvt-columns="[^"]*"`)
 	lvtActionsRegex     = regexp.MustCompile(`\s*lvt-actions="[^"]*"`)
@@ -21,16 +24,33 @@ var (
 	lvtFieldRegex       = regexp.MustCompile(`\s*lvt-field="[^"]*"`)
 	lvtDatatableRegex   = regexp.MustCompile(`\s*lvt-datatable`)
 	columnsAttrRegex    = regexp.MustCompile(`lvt-columns="([^"]+)"`)
+	joinAttrRegex       = regexp.MustCompile(`lvt-join="([^"]+)"`)
 	actionsAttrRegex    = regexp.MustCompile(`lvt-actions="([^"]+)"`)
 	emptyAttrRegex      = regexp.MustCompile(`lvt-empty="([^"]+)"`)
 	fieldAttrRegex      = regexp.MustCompile(`lvt-field="([^"]+)"`)
+	chartAttrRegex      = regexp.MustCompile(`\s*lvt-(chart|x|y)="([^"]*)"`)
+	heatmapAttrRegex    = regexp.MustCompile(`\s*lvt-(date|count|colors|week-start)="([^"]*)"`)
+	lvtHeatmapRegex     = regexp.MustCompile(`\s*lvt-heatmap\b`)
+	statAttrRegex       = regexp.MustCompile(`\s*lvt-(value|label|unit|compare|better)="([^"]*)"`)
+	lvtStatRegex        = regexp.MustCompile(`\s*lvt-stat\b`)
+	classAttrRegex      = regexp.MustCompile(`\bclass="([^"]*)"`)
 	tableDetectRegex    = regexp.MustCompile(`(?i)<table[^>]*lvt-source=`)
 	selectDetectRegex   = regexp.MustCompile(`(?i)<select[^>]*lvt-source=`)
 	listDetectRegex     = regexp.MustCompile(`(?i)<(ul|ol)[^>]*lvt-source=`)
+	chartDetectRegex    = regexp.MustCompile(`(?i)<canvas[^>]*lvt-source=`)
+	heatmapDetectRegex  = regexp.MustCompile(`(?i)<div[^>]*\blvt-heatmap\b`)
+	statDetectRegex     = regexp.MustCompile(`(?i)<div[^>]*\blvt-stat\b`)
 )
 
-// ParseFile parses a markdown file and creates a Page.
+// ParseFile parses a markdown file and creates a Page, rendered with the
+// default MarkdownOptions.
 func ParseFile(path string) (*Page, error) {
+	return parseFile(path, nil, DefaultMarkdownOptions())
+}
+
+// parseFile parses a markdown file. embedStack lists the pages whose
+// embed-lvt blocks led here, outermost first, for cycle detection.
+func parseFile(path string, embedStack []string, opts MarkdownOptions) (*Page, error) {
 	// Read file
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -61,7 +81,7 @@ func ParseFile(path string) (*Page, error) {
 
 	// Parse markdown with partial support
 	baseDir := filepath.Dir(absPath)
-	fm, codeBlocks, staticHTML, err := ParseMarkdownWithPartials(processedContent, baseDir)
+	fm, codeBlocks, staticHTML, err := parseMarkdownWithPartials(processedContent, baseDir, opts)
 	if err != nil {
 		// Wrap with file context
 		return nil, NewParseError(absPath, 1, fmt.Sprintf("Failed to parse markdown: %v", err))
@@ -73,7 +93,15 @@ func ParseFile(path string) (*Page, error) {
 	page.Type = fm.Type
 	page.StaticHTML = staticHTML
 	page.SourceFile = absPath // Track source file
+	page.embedStack = append(embedStack[:len(embedStack):len(embedStack)], absPath)
+	page.markdown = opts
 	page.Sidebar = fm.Sidebar // Page-level sidebar override
+	page.NavOrder = fm.NavOrder
+	page.NavHidden = fm.NavHidden
+	page.NavTitle = fm.NavTitle
+	page.ReadingTime = fm.ReadingTime
+	page.NoIndex = fm.NoIndex
+	page.Tags = fm.Tags
 	page.Config = PageConfig{
 		Persist:   fm.Persist,
 		MultiStep: fm.Steps > 0,
@@ -173,13 +201,19 @@ func (pc *PageConfig) MergeFromFrontmatter(fm *Frontmatter) {
 	}
 }
 
-// ParseString parses markdown content from a string and creates a Page.
+// ParseString parses markdown content from a string and creates a Page,
+// rendered with the default MarkdownOptions.
 // This is useful for the playground where content comes from user input.
 func ParseString(content string) (*Page, error) {
+	return parseString(content, DefaultMarkdownOptions())
+}
+
+// parseString parses markdown content from a string with opts.
+func parseString(content string, opts MarkdownOptions) (*Page, error) {
 	// Note: preprocessAutoTasks is skipped here — playground input uses explicit
 	// lvt blocks rather than auto-detected task sections from file-based markdown.
 	// Parse markdown (no partials support for string input)
-	fm, codeBlocks, staticHTML, err := ParseMarkdownWithPartials([]byte(content), "")
+	fm, codeBlocks, staticHTML, err := parseMarkdownWithPartials([]byte(content), "", opts)
 	if err != nil {
 		return nil, NewParseError("playground", 1, fmt.Sprintf("Failed to parse markdown: %v", err))
 	}
@@ -233,6 +267,12 @@ func BuildPage(id, sourceFile string, content []byte) (*Page, error) {
 	page.StaticHTML = staticHTML
 	page.SourceFile = sourceFile
 	page.Sidebar = fm.Sidebar
+	page.NavOrder = fm.NavOrder
+	page.NavHidden = fm.NavHidden
+	page.NavTitle = fm.NavTitle
+	page.ReadingTime = fm.ReadingTime
+	page.NoIndex = fm.NoIndex
+	page.Tags = fm.Tags
 	page.Config = PageConfig{
 		Persist:   fm.Persist,
 		MultiStep: fm.Steps > 0,
@@ -304,10 +344,17 @@ func (p *Page) buildBlocks(codeBlocks []*CodeBlock, sourceFile string) error {
 			columns := getTableColumns(cb.Content)
 			actions := getTableActions(cb.Content)
 
-			// Apply smart template generation for tables/selects/lists with lvt-source
+			chartAttrs := getChartAttrs(cb.Content)
+			heatmapAttrs := getHeatmapAttrs(cb.Content)
+			statAttrs := getStatAttrs(cb.Content)
+
+			// Apply smart template generation for tables/selects/lists/charts with lvt-source
 			processedContent := autoGenerateTableTemplate(cb.Content)
 			processedContent = autoGenerateSelectTemplate(processedContent)
 			processedContent = autoGenerateListTemplate(processedContent)
+			processedContent = autoGenerateChartTemplate(processedContent)
+			processedContent = autoGenerateHeatmapTemplate(processedContent)
+			processedContent = autoGenerateStatTemplate(processedContent)
 
 			if stateRef == "" && sourceName != "" {
 				// Create auto-generated server block for lvt-source
@@ -319,6 +366,9 @@ func (p *Page) buildBlocks(codeBlocks []*CodeBlock, sourceFile string) error {
 					"lvt-source":  sourceName,
 					"lvt-element": elementType,
 				}
+				if join := getLvtJoin(cb.Content); join != "" {
+					metadata["lvt-join"] = join
+				}
 				if elementType == "table" {
 					// Pass column and action info for datatable generation
 					if columns != "" {
@@ -328,6 +378,25 @@ func (p *Page) buildBlocks(codeBlocks []*CodeBlock, sourceFile string) error {
 						metadata["lvt-actions"] = actions
 					}
 				}
+				if elementType == "chart" {
+					// Pass the chart type and fields for building its config
+					for name, value := range chartAttrs {
+						metadata[name] = value
+					}
+					p.HasCharts = true
+				}
+				if elementType == "heatmap" {
+					// Pass the date and count fields and the colors
+					for name, value := range heatmapAttrs {
+						metadata[name] = value
+					}
+				}
+				if elementType == "stat" {
+					// Pass the value and compared fields, the label and the unit
+					for name, value := range statAttrs {
+						metadata[name] = value
+					}
+				}
 
 				// Create a marker ServerBlock that will be compiled
 				block := &ServerBlock{
@@ -350,9 +419,14 @@ func (p *Page) buildBlocks(codeBlocks []*CodeBlock, sourceFile string) error {
 			}
 			p.InteractiveBlocks[block.ID] = block
 
+		case "embed":
+			if err := p.resolveEmbed(cb, i, sourceFile); err != nil {
+				return err
+			}
+
 		default:
 			return NewParseError(sourceFile, cb.Line, fmt.Sprintf("Unknown block type: %s", cb.Type)).
-				WithHint(fmt.Sprintf("Valid block types are: server, wasm, lvt"))
+				WithHint(fmt.Sprintf("Valid block types are: server, wasm, lvt, embed-lvt"))
 		}
 	}
 
@@ -434,8 +508,29 @@ func getLvtSource(content string) string {
 	return ""
 }
 
+// SourceNames splits an lvt-source value into the names of the sources it
+// binds: one for most blocks, several for lvt-source="orders,rates".
+func SourceNames(lvtSource string) []string {
+	var names []string
+	for _, name := range strings.Split(lvtSource, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// getLvtJoin extracts the lvt-join attribute, the field a block bound to
+// several sources merges their rows on. Returns empty string if not found.
+func getLvtJoin(content string) string {
+	if match := joinAttrRegex.FindStringSubmatch(content); match != nil {
+		return strings.TrimSpace(match[1])
+	}
+	return ""
+}
+
 // getLvtSourceElementType detects what kind of element has the lvt-source attribute
-// Returns "table", "select", "list", or "div" (default)
+// Returns "table", "select", "list", "chart", "heatmap", "stat", or "div" (default)
 func getLvtSourceElementType(content string) string {
 	if tableDetectRegex.MatchString(content) {
 		return "table"
@@ -446,9 +541,32 @@ func getLvtSourceElementType(content string) string {
 	if listDetectRegex.MatchString(content) {
 		return "list"
 	}
+	if chartDetectRegex.MatchString(content) {
+		return "chart"
+	}
+	if heatmapDetectRegex.MatchString(content) {
+		return "heatmap"
+	}
+	if statDetectRegex.MatchString(content) {
+		return "stat"
+	}
 	return "div"
 }
 
+// getChartAttrs extracts lvt-chart, lvt-x and lvt-y from a chart canvas
+// Returns them keyed by attribute name, like {"lvt-chart": "line"}
+func getChartAttrs(content string) map[string]string {
+	match := chartCanvasRegex.FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+	attrs := make(map[string]string)
+	for _, m := range chartAttrRegex.FindAllStringSubmatch(match[1], -1) {
+		attrs["lvt-"+m[1]] = m[2]
+	}
+	return attrs
+}
+
 // getTableColumns extracts lvt-columns from a table element
 // Returns a comma-separated list like "name:Name,email:Email"
 func getTableColumns(content string) string {
@@ -469,6 +587,18 @@ func getTableActions(content string) string {
 	return ""
 }
 
+// optimisticAttr returns the lvt-optimistic hint for a generated action
+// button, so the client shows a Delete or Toggle before the server confirms it.
+func optimisticAttr(action string) string {
+	switch strings.ToLower(action) {
+	case "delete":
+		return ` lvt-optimistic="delete"`
+	case "toggle":
+		return ` lvt-optimistic="toggle"`
+	}
+	return ""
+}
+
 // autoGenerateTableTemplate transforms <table lvt-source="..."> into generated HTML.
 //
 // Two modes:
@@ -544,10 +674,12 @@ func autoGenerateTableTemplate(content string) string {
 
 // generateSimpleTable generates simple inline table HTML with thead/tbody
 func generateSimpleTable(w *strings.Builder, columns, actions, emptyMessage string) {
-	// Parse columns: "field:Label,field2:Label2" or "field,field2"
+	// Parse columns: "field:Label,field2:Label2" or "field,field2".
+	// A ":sparkline" suffix draws the field's series as a sparkline.
 	var cols []struct {
-		field string
-		label string
+		field     string
+		label     string
+		sparkline bool
 	}
 
 	if columns != "" {
@@ -555,16 +687,18 @@ func generateSimpleTable(w *strings.Builder, columns, actions, emptyMessage stri
 			parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
 			field := parts[0]
 			label := field
+			sparkline := false
 			if len(parts) > 1 {
-				label = parts[1]
+				label, sparkline = strings.CutSuffix(parts[1], ":sparkline")
 			} else {
 				// Auto-titlecase the field name for label
 				label = titleCase(field)
 			}
 			cols = append(cols, struct {
-				field string
-				label string
-			}{field, label})
+				field     string
+				label     string
+				sparkline bool
+			}{field, label, sparkline})
 		}
 	}
 
@@ -610,8 +744,8 @@ func generateSimpleTable(w *strings.Builder, columns, actions, emptyMessage stri
 			w.WriteString("      <td>\n")
 			for _, act := range acts {
 				// HTML-escape action label to prevent XSS
-				w.WriteString(fmt.Sprintf("        <button name=\"%s\" data-id=\"{{.Id}}\">%s</button>\n",
-					html.EscapeString(act.action), html.EscapeString(act.label)))
+				w.WriteString(fmt.Sprintf("        <button name=\"%s\" data-id=\"{{.Id}}\"%s>%s</button>\n",
+					html.EscapeString(act.action), optimisticAttr(act.action), html.EscapeString(act.label)))
 			}
 			w.WriteString("      </td>\n")
 		}
@@ -654,6 +788,11 @@ func generateSimpleTable(w *strings.Builder, columns, actions, emptyMessage stri
 	w.WriteString("  <tbody>\n")
 	w.WriteString("    {{range .Data}}\n    <tr>\n")
 	for _, col := range cols {
+		if col.sparkline {
+			// The state renders the SVG for each row (internal/runtime/sparkline.go)
+			w.WriteString(fmt.Sprintf("      <td>{{index . %q}}</td>\n", col.field+"_sparkline"))
+			continue
+		}
 		// Use titlecase field name for Go template access
 		w.WriteString(fmt.Sprintf("      <td>{{.%s}}</td>\n", titleCase(col.field)))
 	}
@@ -661,8 +800,8 @@ func generateSimpleTable(w *strings.Builder, columns, actions, emptyMessage stri
 		w.WriteString("      <td>\n")
 		for _, act := range acts {
 			// HTML-escape action label to prevent XSS
-			w.WriteString(fmt.Sprintf("        <button name=\"%s\" data-id=\"{{.Id}}\">%s</button>\n",
-				html.EscapeString(act.action), html.EscapeString(act.label)))
+			w.WriteString(fmt.Sprintf("        <button name=\"%s\" data-id=\"{{.Id}}\"%s>%s</button>\n",
+				html.EscapeString(act.action), optimisticAttr(act.action), html.EscapeString(act.label)))
 		}
 		w.WriteString("      </td>\n")
 	}
@@ -839,8 +978,8 @@ func autoGenerateListTemplate(content string) string {
 			if len(parts) == 2 {
 				action := strings.TrimSpace(parts[0])
 				label := strings.TrimSpace(parts[1])
-				generated.WriteString(fmt.Sprintf("    <button name=\"%s\" data-id=\"{{.Id}}\">%s</button>\n",
-					html.EscapeString(action), html.EscapeString(label)))
+				generated.WriteString(fmt.Sprintf("    <button name=\"%s\" data-id=\"{{.Id}}\"%s>%s</button>\n",
+					html.EscapeString(action), optimisticAttr(action), html.EscapeString(label)))
 			}
 		}
 	}
@@ -886,3 +1025,134 @@ func validateAction(name string, action Action) error {
 	}
 	return nil
 }
+
+// getHeatmapAttrs extracts lvt-date, lvt-count, lvt-colors and lvt-week-start
+// from a heatmap div. Returns them keyed by attribute name, like {"lvt-date": "day"}
+func getHeatmapAttrs(content string) map[string]string {
+	match := heatmapDivRegex.FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+	attrs := make(map[string]string)
+	for _, m := range heatmapAttrRegex.FindAllStringSubmatch(match[1], -1) {
+		attrs["lvt-"+m[1]] = m[2]
+	}
+	return attrs
+}
+
+// getStatAttrs extracts lvt-value, lvt-label, lvt-unit, lvt-compare and
+// lvt-better from a stat div. Returns them keyed by attribute name, like {"lvt-unit": "$"}
+func getStatAttrs(content string) map[string]string {
+	match := statDivRegex.FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+	attrs := make(map[string]string)
+	for _, m := range statAttrRegex.FindAllStringSubmatch(match[1], -1) {
+		attrs["lvt-"+m[1]] = m[2]
+	}
+	return attrs
+}
+
+// autoGenerateChartTemplate transforms <canvas lvt-source="..."> into a chart
+// container whose config comes from the source's state. The client draws the
+// chart, and redraws it each time the source refreshes.
+//
+// Supported attributes:
+//   - lvt-chart="line" - Chart type: bar, line, pie, doughnut or auto (default)
+//   - lvt-x="day" - Field that labels each point
+//   - lvt-y="value" - Field (or comma-separated fields) to plot
+func autoGenerateChartTemplate(content string) string {
+	match := chartCanvasRegex.FindStringSubmatch(content)
+	if match == nil {
+		return content
+	}
+
+	chartType := ""
+	if m := regexp.MustCompile(`lvt-chart="([^"]*)"`).FindStringSubmatch(match[1]); m != nil {
+		chartType = m[1]
+	}
+
+	// Build cleaned attributes (remove lvt-* attributes)
+	cleanedAttrs := lvtSourceRegex.ReplaceAllString(match[1], "")
+	cleanedAttrs = chartAttrRegex.ReplaceAllString(cleanedAttrs, "")
+
+	var generated strings.Builder
+	fmt.Fprintf(&generated, "<div class=\"tinkerdown-chart\" data-chart-type=\"%s\" data-chart-config=\"{{.Chart}}\">\n", html.EscapeString(chartType))
+	generated.WriteString("  {{if .Error}}<p class=\"error\">{{.Error}}</p>{{end}}\n")
+	fmt.Fprintf(&generated, "  <canvas%s></canvas>\n", cleanedAttrs)
+	generated.WriteString("</div>")
+
+	// Use ReplaceAllLiteralString to avoid special chars being interpreted as backreferences
+	return chartCanvasRegex.ReplaceAllLiteralString(content, generated.String())
+}
+
+// autoGenerateHeatmapTemplate transforms <div lvt-source="..." lvt-heatmap>
+// into a calendar heatmap of the source's dates, drawn by its state.
+//
+// Supported attributes:
+//   - lvt-date="day" - Field holding each row's date (default "date")
+//   - lvt-count="minutes" - Field holding each row's count (default "count";
+//     each row counts one when rows have no count)
+//   - lvt-colors="#eee,#9be9a8,#216e39" - Colors from no count to the highest
+//   - lvt-week-start="monday" - First day of each week column (default sunday)
+func autoGenerateHeatmapTemplate(content string) string {
+	match := heatmapDivRegex.FindStringSubmatch(content)
+	if match == nil || !strings.Contains(match[1], "lvt-source=") {
+		return content
+	}
+
+	// Build cleaned attributes (remove lvt-* attributes)
+	cleanedAttrs := lvtSourceRegex.ReplaceAllString(match[1], "")
+	cleanedAttrs = heatmapAttrRegex.ReplaceAllString(cleanedAttrs, "")
+	cleanedAttrs = lvtHeatmapRegex.ReplaceAllString(cleanedAttrs, "")
+
+	var generated strings.Builder
+	fmt.Fprintf(&generated, "<div%s>\n", cleanedAttrs)
+	generated.WriteString("  {{if .Error}}<p class=\"error\">{{.Error}}</p>{{end}}\n")
+	generated.WriteString("  {{.Heatmap}}\n")
+	generated.WriteString("</div>")
+
+	// Use ReplaceAllLiteralString to avoid special chars being interpreted as backreferences
+	return heatmapDivRegex.ReplaceAllLiteralString(content, generated.String())
+}
+
+// autoGenerateStatTemplate transforms <div lvt-source="..." lvt-stat> into a
+// stat card: one number from the source's first row, shown large, with its
+// label and its change from a compared number.
+//
+// Supported attributes:
+//   - lvt-value="revenue" - Field holding the number (default "value")
+//   - lvt-label="Revenue" - Text above the number (default the row's label
+//     field, or the value field's name)
+//   - lvt-unit="$" - Unit shown before a currency number, after any other
+//   - lvt-compare="last_month" - Field to compare with, for the delta
+//   - lvt-better="lower" - Color a fall as good news, as for latency or costs
+func autoGenerateStatTemplate(content string) string {
+	match := statDivRegex.FindStringSubmatch(content)
+	if match == nil || !strings.Contains(match[1], "lvt-source=") {
+		return content
+	}
+
+	// Build cleaned attributes (remove lvt-* attributes), adding the card's
+	// class to any the div already has
+	cleanedAttrs := lvtSourceRegex.ReplaceAllString(match[1], "")
+	cleanedAttrs = statAttrRegex.ReplaceAllString(cleanedAttrs, "")
+	cleanedAttrs = lvtStatRegex.ReplaceAllString(cleanedAttrs, "")
+	if classAttrRegex.MatchString(cleanedAttrs) {
+		cleanedAttrs = classAttrRegex.ReplaceAllString(cleanedAttrs, `class="tinkerdown-stat $1"`)
+	} else {
+		cleanedAttrs = ` class="tinkerdown-stat"` + cleanedAttrs
+	}
+
+	var generated strings.Builder
+	fmt.Fprintf(&generated, "<div%s>\n", cleanedAttrs)
+	generated.WriteString("  {{if .Error}}<p class=\"error\">{{.Error}}</p>{{end}}\n")
+	generated.WriteString("  <div class=\"tinkerdown-stat-label\">{{.Stat.Label}}</div>\n")
+	generated.WriteString("  <div class=\"tinkerdown-stat-value\">{{.Stat.Prefix}}{{.Stat.Value}}{{if .Stat.Suffix}}<span class=\"tinkerdown-stat-unit\">{{.Stat.Suffix}}</span>{{end}}</div>\n")
+	generated.WriteString("  {{if .Stat.Delta}}<div class=\"tinkerdown-stat-delta {{.Stat.Trend}} {{.Stat.Tone}}\">{{.Stat.Delta}}{{if .Stat.Percent}} ({{.Stat.Percent}}){{end}}</div>{{end}}\n")
+	generated.WriteString("</div>")
+
+	// Use ReplaceAllLiteralString to avoid special chars being interpreted as backreferences
+	return statDivRegex.ReplaceAllLiteralString(content, generated.String())
+}
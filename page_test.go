@@ -3,6 +3,7 @@ package tinkerdown
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -284,3 +285,111 @@ steps: 5
 		})
 	}
 }
+
+func TestLiveChartBlock(t *testing.T) {
+	page, err := ParseString("---\ntitle: test\nsources:\n  metrics:\n    type: json\n    file: metrics.json\n---\n" +
+		"```lvt\n<canvas lvt-source=\"metrics\" lvt-chart=\"line\" lvt-x=\"day\" lvt-y=\"value,target\" height=\"120\"></canvas>\n```\n")
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	if !page.HasCharts {
+		t.Error("HasCharts = false, want true so Chart.js is loaded")
+	}
+
+	var block *InteractiveBlock
+	for _, b := range page.InteractiveBlocks {
+		block = b
+	}
+	server := page.ServerBlocks[block.StateRef]
+	if server == nil {
+		t.Fatalf("no server block %q for the chart", block.StateRef)
+	}
+	wantMeta := map[string]string{
+		"lvt-source":  "metrics",
+		"lvt-element": "chart",
+		"lvt-chart":   "line",
+		"lvt-x":       "day",
+		"lvt-y":       "value,target",
+	}
+	for key, want := range wantMeta {
+		if got := server.Metadata[key]; got != want {
+			t.Errorf("metadata[%q] = %q, want %q", key, got, want)
+		}
+	}
+
+	for _, want := range []string{
+		`<div class="tinkerdown-chart" data-chart-type="line" data-chart-config="{{.Chart}}">`,
+		`<canvas height="120"></canvas>`,
+	} {
+		if !strings.Contains(block.Content, want) {
+			t.Errorf("generated content = %s\nwant it to contain %s", block.Content, want)
+		}
+	}
+	if strings.Contains(block.Content, "lvt-") {
+		t.Errorf("generated content = %s, want the lvt- attributes removed", block.Content)
+	}
+}
+
+func TestSparklineColumnTemplate(t *testing.T) {
+	page, err := ParseString("---\ntitle: test\nsources:\n  hosts:\n    type: json\n    file: hosts.json\n---\n" +
+		"```lvt\n<table lvt-source=\"hosts\" lvt-columns=\"name:Name,trend:Last hour:sparkline\"></table>\n```\n")
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	for _, block := range page.InteractiveBlocks {
+		for _, want := range []string{`<th>Last hour</th>`, `<td>{{.Name}}</td>`, `<td>{{index . "trend_sparkline"}}</td>`} {
+			if !strings.Contains(block.Content, want) {
+				t.Errorf("generated content = %s\nwant it to contain %s", block.Content, want)
+			}
+		}
+		if got := page.ServerBlocks[block.StateRef].Metadata["lvt-columns"]; got != "name:Name,trend:Last hour:sparkline" {
+			t.Errorf("lvt-columns = %q, want the column kind passed to the state", got)
+		}
+	}
+}
+
+func TestHeatmapBlock(t *testing.T) {
+	page, err := ParseString("---\ntitle: test\nsources:\n  habits:\n    type: json\n    file: habits.json\n---\n" +
+		"```lvt\n<div class=\"habits\" lvt-heatmap lvt-source=\"habits\" lvt-date=\"day\" lvt-week-start=\"monday\"></div>\n```\n")
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	for _, block := range page.InteractiveBlocks {
+		if !strings.Contains(block.Content, "<div class=\"habits\">\n") || !strings.Contains(block.Content, "{{.Heatmap}}") {
+			t.Errorf("generated content = %s, want the div drawing .Heatmap", block.Content)
+		}
+		if strings.Contains(block.Content, "lvt-") {
+			t.Errorf("generated content = %s, want the lvt- attributes removed", block.Content)
+		}
+		meta := page.ServerBlocks[block.StateRef].Metadata
+		if meta["lvt-element"] != "heatmap" || meta["lvt-date"] != "day" || meta["lvt-week-start"] != "monday" {
+			t.Errorf("metadata = %v, want a heatmap of day, weeks from monday", meta)
+		}
+	}
+}
+
+func TestStatBlock(t *testing.T) {
+	page, err := ParseString("---\ntitle: test\nsources:\n  mrr:\n    type: json\n    file: mrr.json\n---\n" +
+		"```lvt\n<div class=\"wide\" lvt-stat lvt-source=\"mrr\" lvt-value=\"mrr\" lvt-unit=\"$\" lvt-compare=\"last_month\" lvt-better=\"higher\"></div>\n```\n")
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	for _, block := range page.InteractiveBlocks {
+		for _, want := range []string{
+			"<div class=\"tinkerdown-stat wide\">\n",
+			"{{.Stat.Prefix}}{{.Stat.Value}}",
+			"<div class=\"tinkerdown-stat-delta {{.Stat.Trend}} {{.Stat.Tone}}\">",
+		} {
+			if !strings.Contains(block.Content, want) {
+				t.Errorf("generated content = %s, want it to contain %s", block.Content, want)
+			}
+		}
+		if strings.Contains(block.Content, "lvt-") {
+			t.Errorf("generated content = %s, want the lvt- attributes removed", block.Content)
+		}
+		meta := page.ServerBlocks[block.StateRef].Metadata
+		if meta["lvt-element"] != "stat" || meta["lvt-value"] != "mrr" || meta["lvt-unit"] != "$" || meta["lvt-compare"] != "last_month" || meta["lvt-better"] != "higher" {
+			t.Errorf("metadata = %v, want a stat of mrr against last_month", meta)
+		}
+	}
+}
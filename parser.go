@@ -8,15 +8,15 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/livetemplate/tinkerdown/internal/frontmatter"
+	"github.com/livetemplate/tinkerdown/internal/runtime"
 	"github.com/livetemplate/tinkerdown/internal/schedule"
 	"github.com/livetemplate/tinkerdown/internal/slug"
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 	"gopkg.in/yaml.v3"
@@ -32,7 +32,7 @@ type SourceConfig struct {
 	Anchor      string            `yaml:"anchor,omitempty"`       // For markdown: section anchor (e.g., "#todos")
 	DB          string            `yaml:"db,omitempty"`           // For sqlite: database file path
 	Table       string            `yaml:"table,omitempty"`        // For sqlite: table name
-	Path        string            `yaml:"path,omitempty"`         // For wasm: path to .wasm file
+	Path        string            `yaml:"path,omitempty"`         // For wasm: path to .wasm file; for upload: storage directory
 	QueryFile   string                 `yaml:"query_file,omitempty"`   // For graphql: path to .graphql file
 	Variables   map[string]interface{} `yaml:"variables,omitempty"`    // For graphql: query variables
 	Headers     map[string]string      `yaml:"headers,omitempty"`      // For rest: HTTP headers (env vars expanded)
@@ -46,6 +46,11 @@ type SourceConfig struct {
 	Env         map[string]string `yaml:"env,omitempty"`       // For exec: environment variables (env vars expanded)
 	Timeout     string            `yaml:"timeout,omitempty"`   // For exec/rest: timeout (e.g., "30s", "1m")
 	AutoBind    *bool             `yaml:"auto_bind,omitempty"` // Set to false to exclude from auto-table matching
+	Fields      map[string]FieldRule `yaml:"fields,omitempty"` // For markdown/sqlite: constraints checked on Add and Update
+
+	// For exec sources with side effects: never auto-run, and ask before each Run
+	Confirm    bool   `yaml:"confirm,omitempty"`
+	ConfirmMsg string `yaml:"confirm_message,omitempty"` // Question asked before running (default: "Run this command?")
 
 	// For computed sources
 	GroupBy   string            `yaml:"group_by,omitempty"`   // Field to group by
@@ -53,6 +58,13 @@ type SourceConfig struct {
 	Filter    string            `yaml:"filter,omitempty"`     // Optional filter expression
 }
 
+// FieldRule constrains a field written by Add and Update actions.
+type FieldRule struct {
+	Required bool   `yaml:"required,omitempty"`
+	MaxLen   int    `yaml:"maxlen,omitempty"`
+	Pattern  string `yaml:"pattern,omitempty"`
+}
+
 // StylingConfig represents styling/theme configuration.
 type StylingConfig struct {
 	Theme        string `yaml:"theme"`
@@ -104,9 +116,26 @@ type Frontmatter struct {
 	// Top-level convenience options
 	Sidebar *bool `yaml:"sidebar,omitempty"` // Show navigation sidebar (overrides features.sidebar)
 
+	// Site navigation options
+	NavOrder  *int   `yaml:"nav_order,omitempty"`  // Position in the sidebar (unordered pages sort after ordered ones)
+	NavHidden bool   `yaml:"nav_hidden,omitempty"` // Omit from the sidebar (the page stays routable)
+	NavTitle  string `yaml:"nav_title,omitempty"`  // Label shown in navigation instead of the title
+
+	// ReadingTime set to false hides the reading time and progress bar on this page
+	ReadingTime *bool `yaml:"reading_time,omitempty"`
+
+	// NoIndex asks search engines not to index this page
+	NoIndex bool `yaml:"noindex,omitempty"`
+
+	// Tags list the page under /tags/ in site mode
+	Tags []string `yaml:"tags,omitempty"`
+
 	// Chart customization (keyed by heading slug)
 	Charts map[string]ChartOptions `yaml:"charts,omitempty"`
 
+	// Heatmap customization (keyed by heading slug)
+	Heatmaps map[string]HeatmapOptions `yaml:"heatmaps,omitempty"`
+
 	// Config options (can override livemdtools.yaml)
 	Sources  map[string]SourceConfig `yaml:"sources,omitempty"`
 	Actions  map[string]Action       `yaml:"actions,omitempty"`
@@ -133,7 +162,7 @@ type Frontmatter struct {
 
 // CodeBlock represents a code block extracted from markdown.
 type CodeBlock struct {
-	Type     string            // "server", "wasm", "lvt"
+	Type     string            // "server", "wasm", "lvt", "embed"
 	Language string            // "go", etc.
 	Flags    []string          // "readonly", "editable"
 	Metadata map[string]string // id, state, etc.
@@ -141,24 +170,25 @@ type CodeBlock struct {
 	Line     int // Line number in source file
 }
 
-// ParseMarkdown parses a markdown file and extracts frontmatter and code blocks.
+// ParseMarkdown parses a markdown file and extracts frontmatter and code
+// blocks, rendering it with the default MarkdownOptions.
 func ParseMarkdown(content []byte) (*Frontmatter, []*CodeBlock, string, error) {
+	return parseMarkdown(content, DefaultMarkdownOptions())
+}
+
+// parseMarkdown parses a markdown file with opts.
+func parseMarkdown(content []byte, opts MarkdownOptions) (*Frontmatter, []*CodeBlock, string, error) {
 	// Extract frontmatter
 	frontmatter, remaining, err := extractFrontmatter(content)
 	if err != nil {
 		return nil, nil, "", fmt.Errorf("failed to parse frontmatter: %w", err)
 	}
 
-	// Parse markdown with goldmark
-	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-	)
+	// Parse markdown with goldmark (GFM extensions per MarkdownOptions)
+	md := newMarkdown(opts)
 
 	reader := text.NewReader(remaining)
-	doc := md.Parser().Parse(reader)
+	doc := md.Parser().Parse(reader, parser.WithContext(newParserContext()))
 
 	// Extract and collect livemdtools code blocks (but don't remove from AST)
 	var codeBlocks []*CodeBlock
@@ -218,6 +248,15 @@ func ParseMarkdown(content []byte) (*Frontmatter, []*CodeBlock, string, error) {
 		frontmatter.HasCharts = true
 	}
 
+	// Process heatmap headings (## Title {heatmap} followed by a table of dates)
+	html = processHeatmaps(html, frontmatter.Heatmaps)
+
+	// Draw {sparkline} table cells as inline SVG
+	html = processSparklines(html)
+
+	// Add permalink anchors to h2/h3 headings
+	html = addHeadingAnchors(html)
+
 	// Parse schedule tokens and imperatives from markdown content
 	schedules, scheduleWarnings := parseScheduleTokens(remaining)
 	if len(schedules) > 0 {
@@ -239,7 +278,11 @@ func ParseMarkdown(content []byte) (*Frontmatter, []*CodeBlock, string, error) {
 // extractFrontmatter extracts YAML frontmatter from the beginning of content.
 // Returns the parsed frontmatter and the remaining content.
 func extractFrontmatter(content []byte) (*Frontmatter, []byte, error) {
-	if !bytes.HasPrefix(content, []byte("---\n")) {
+	yamlContent, remaining, ok, err := frontmatter.Split(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
 		// No frontmatter, use defaults
 		return &Frontmatter{
 			Type:    "tutorial",
@@ -247,15 +290,6 @@ func extractFrontmatter(content []byte) (*Frontmatter, []byte, error) {
 		}, content, nil
 	}
 
-	// Find the closing ---
-	endIdx := bytes.Index(content[4:], []byte("\n---\n"))
-	if endIdx == -1 {
-		return nil, nil, fmt.Errorf("unclosed frontmatter")
-	}
-
-	yamlContent := content[4 : 4+endIdx]
-	remaining := content[4+endIdx+5:] // Skip "\n---\n"
-
 	var fm Frontmatter
 	if err := yaml.Unmarshal(yamlContent, &fm); err != nil {
 		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
@@ -295,8 +329,9 @@ func injectBlockAttributes(html string, blocks []*CodeBlock, sources map[string]
 			blockID = fmt.Sprintf("%s-%d", block.Type, i)
 		}
 
-		// For interactive (lvt) blocks, replace with a container div instead of code block
-		if block.Type == "lvt" {
+		// For interactive (lvt) blocks, replace with a container div instead of code block.
+		// Embedded blocks render the same way; the client doesn't need to know the difference.
+		if block.Type == "lvt" || block.Type == "embed" {
 			// Build container div with data attributes
 			container := fmt.Sprintf(
 				`<div class="tinkerdown-interactive-block" data-tinkerdown-block data-block-id="%s" data-block-type="lvt" data-language="lvt"`,
@@ -689,28 +724,8 @@ var (
 	chartTdPattern = regexp.MustCompile(`<td>(.*?)</td>`)
 )
 
-var validChartTypes = map[string]bool{
-	"bar": true, "line": true, "pie": true, "doughnut": true, "auto": true, "": true,
-}
-
 // ChartOptions holds per-chart customization from frontmatter.
-type ChartOptions struct {
-	Colors     []string `yaml:"colors,omitempty" json:"colors,omitempty"`
-	Stacked    bool     `yaml:"stacked,omitempty" json:"stacked,omitempty"`
-	Horizontal bool     `yaml:"horizontal,omitempty" json:"horizontal,omitempty"`
-	Legend     *bool    `yaml:"legend,omitempty" json:"legend,omitempty"`
-}
-
-// chartData is the JSON structure passed to Chart.js via data attributes.
-type chartData struct {
-	Labels   []string     `json:"labels"`
-	Datasets []chartDataset `json:"datasets"`
-}
-
-type chartDataset struct {
-	Label string    `json:"label"`
-	Data  []float64 `json:"data"`
-}
+type ChartOptions = runtime.ChartOptions
 
 // processCharts detects {chart:type} headings followed by tables and transforms
 // them into chart container elements with JSON data attributes for Chart.js.
@@ -740,7 +755,7 @@ func processCharts(htmlStr string, chartOpts map[string]ChartOptions) (string, b
 		headingClose := htmlStr[m[10]:m[11]] // </h2>
 
 		// Validate chart type
-		if !validChartTypes[chartType] {
+		if !runtime.IsChartType(chartType) {
 			continue
 		}
 
@@ -778,98 +793,26 @@ func processCharts(htmlStr string, chartOpts map[string]ChartOptions) (string, b
 			continue
 		}
 
-		// Determine which columns are numeric
-		numericCols := make([]bool, len(headers))
-		for col := range headers {
-			numericCols[col] = true
-			for _, row := range rows {
-				if col < len(row) {
-					_, err := strconv.ParseFloat(strings.TrimSpace(row[col]), 64)
-					if err != nil {
-						numericCols[col] = false
-						break
-					}
-				}
-			}
-		}
-
-		// Find label column (first non-numeric) and data columns
-		labelCol := -1
-		var dataCols []int
-		for col := range headers {
-			if !numericCols[col] {
-				if labelCol < 0 {
-					labelCol = col
-				}
-			} else {
-				dataCols = append(dataCols, col)
-			}
-		}
-
-		if len(dataCols) == 0 {
-			continue // No numeric columns, skip chart
-		}
-		if labelCol < 0 {
-			labelCol = 0 // All numeric, use first column as labels
-			dataCols = dataCols[1:]
-			if len(dataCols) == 0 {
-				continue
-			}
-		}
-
-		// Build chart data
-		labels := make([]string, len(rows))
-		for r, row := range rows {
-			if labelCol < len(row) {
-				labels[r] = strings.TrimSpace(html.UnescapeString(row[labelCol]))
-			}
-		}
-
-		datasets := make([]chartDataset, len(dataCols))
-		for di, col := range dataCols {
-			datasets[di].Label = headers[col]
-			datasets[di].Data = make([]float64, len(rows))
-			for r, row := range rows {
-				if col < len(row) {
-					val, _ := strconv.ParseFloat(strings.TrimSpace(row[col]), 64)
-					datasets[di].Data[r] = val
-				}
-			}
-		}
-
-		// Auto-detect chart type
-		if chartType == "" || chartType == "auto" {
-			if len(dataCols) == 1 && len(labels) <= 8 {
-				chartType = "pie"
-			} else {
-				chartType = "bar"
-			}
+		// Build the Chart.js config: labels from the first text column, a
+		// dataset for each numeric one
+		cleanTitle := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(headingText, "")))
+		cleanID := slug.Heading(cleanTitle)
+		cfg, err := runtime.NewChartConfig(chartType, cleanTitle, runtime.ChartTable{Headers: headers, Rows: rows}, chartOpts[cleanID])
+		if err != nil {
+			continue // Nothing to chart
 		}
-
-		// Build JSON
-		cd := chartData{Labels: labels, Datasets: datasets}
-		jsonBytes, err := json.Marshal(cd)
+		configJSON, err := json.Marshal(cfg)
 		if err != nil {
 			continue
 		}
 
-		// Build replacement HTML — strip any inline HTML tags from heading text
-		cleanTitle := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(headingText, "")))
-		cleanID := slug.Heading(cleanTitle)
+		// Build replacement HTML
 		headingLevel := htmlStr[m[4]:m[5]]
 
-		// Look up per-chart options from frontmatter
-		optionsAttr := ""
-		if opts, ok := chartOpts[cleanID]; ok {
-			if optJSON, err := json.Marshal(opts); err == nil && string(optJSON) != "{}" {
-				optionsAttr = fmt.Sprintf(` data-chart-options="%s"`, html.EscapeString(string(optJSON)))
-			}
-		}
-
 		var buf strings.Builder
 		fmt.Fprintf(&buf, "<h%s id=\"%s\">%s%s\n", headingLevel, cleanID, cleanTitle, headingClose)
-		fmt.Fprintf(&buf, "<div class=\"tinkerdown-chart\" data-chart-type=\"%s\" data-chart-title=\"%s\" data-chart-data=\"%s\"%s>\n",
-			chartType, html.EscapeString(cleanTitle), html.EscapeString(string(jsonBytes)), optionsAttr)
+		fmt.Fprintf(&buf, "<div class=\"tinkerdown-chart\" data-chart-type=\"%s\" data-chart-title=\"%s\" data-chart-config=\"%s\">\n",
+			cfg.Type, html.EscapeString(cleanTitle), html.EscapeString(string(configJSON)))
 		buf.WriteString("  <canvas></canvas>\n")
 		buf.WriteString("</div>\n")
 		buf.WriteString("<details class=\"tinkerdown-chart-table\">\n")
@@ -885,6 +828,67 @@ func processCharts(htmlStr string, chartOpts map[string]ChartOptions) (string, b
 	return htmlStr, found
 }
 
+// heatmapAnnotationPattern matches headings with {heatmap} at the end.
+var heatmapAnnotationPattern = regexp.MustCompile(
+	`(<h([1-6])\s+id="[^"]*"[^>]*>)(.*?)\s*\{heatmap\}\s*(</h[1-6]>)`,
+)
+
+// HeatmapOptions holds per-heatmap customization from frontmatter.
+type HeatmapOptions = runtime.HeatmapOptions
+
+// processHeatmaps detects {heatmap} headings followed by a table of dates
+// and draws the table as a calendar heatmap. The SVG is built here, so it
+// needs no JavaScript. heatmapOpts provides per-heatmap customization from
+// frontmatter (keyed by heading slug).
+func processHeatmaps(htmlStr string, heatmapOpts map[string]HeatmapOptions) string {
+	matches := heatmapAnnotationPattern.FindAllStringSubmatchIndex(htmlStr, -1)
+
+	// Process matches in reverse order to preserve indices
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		fullMatchStart, fullMatchEnd := m[0], m[1]
+		headingLevel := htmlStr[m[4]:m[5]]
+		headingClose := htmlStr[m[8]:m[9]]
+		cleanTitle := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(htmlStr[m[6]:m[7]], "")))
+		cleanID := slug.Heading(cleanTitle)
+		heading := fmt.Sprintf("<h%s id=\"%s\">%s%s\n", headingLevel, cleanID, html.EscapeString(cleanTitle), headingClose)
+
+		// Without a table of dates, just remove the annotation from the heading
+		remaining := htmlStr[fullMatchEnd:]
+		trimmed := strings.TrimLeft(remaining, " \t\n\r")
+		tableMatch := chartTablePattern.FindStringIndex(trimmed)
+		if tableMatch == nil || tableMatch[0] != 0 {
+			htmlStr = htmlStr[:fullMatchStart] + heading + htmlStr[fullMatchEnd:]
+			continue
+		}
+		tableHTML := trimmed[:tableMatch[1]]
+		table := runtime.ChartTable{Headers: parseChartTableHeaders(tableHTML), Rows: parseChartTableRows(tableHTML)}
+		days, err := runtime.HeatmapDays(table)
+		if err != nil {
+			htmlStr = htmlStr[:fullMatchStart] + heading + htmlStr[fullMatchEnd:]
+			continue
+		}
+		svg, err := runtime.NewHeatmapSVG(days, heatmapOpts[cleanID])
+		if err != nil {
+			htmlStr = htmlStr[:fullMatchStart] + heading + htmlStr[fullMatchEnd:]
+			continue
+		}
+
+		var buf strings.Builder
+		buf.WriteString(heading)
+		fmt.Fprintf(&buf, "<div class=\"tinkerdown-heatmap-container\">%s</div>\n", svg)
+		buf.WriteString("<details class=\"tinkerdown-chart-table\">\n")
+		buf.WriteString("  <summary>View data</summary>\n")
+		buf.WriteString("  ")
+		buf.WriteString(tableHTML)
+		buf.WriteString("</details>\n")
+
+		tableEndAbs := fullMatchEnd + (len(remaining) - len(trimmed)) + tableMatch[1]
+		htmlStr = htmlStr[:fullMatchStart] + buf.String() + htmlStr[tableEndAbs:]
+	}
+	return htmlStr
+}
+
 // parseChartTableHeaders extracts column headers from a GFM table's <thead>.
 // Strips HTML tags and unescapes entities to produce clean text labels.
 func parseChartTableHeaders(tableHTML string) []string {
@@ -897,7 +901,7 @@ func parseChartTableHeaders(tableHTML string) []string {
 	return headers
 }
 
-// parseChartTableRows extracts row data from a GFM table's <tbody>.
+// parseChartTableRows extracts row data from a GFM table's <tbody>, as text.
 func parseChartTableRows(tableHTML string) [][]string {
 	tbodyIdx := strings.Index(tableHTML, "<tbody>")
 	if tbodyIdx < 0 {
@@ -911,13 +915,65 @@ func parseChartTableRows(tableHTML string) [][]string {
 		tdMatches := chartTdPattern.FindAllStringSubmatch(tr[1], -1)
 		row := make([]string, len(tdMatches))
 		for j, td := range tdMatches {
-			row[j] = td[1]
+			row[j] = strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(td[1], "")))
 		}
 		rows[i] = row
 	}
 	return rows
 }
 
+// Regexes for {sparkline} table cells.
+var (
+	sparklineTablePattern = regexp.MustCompile(`(?s)<table[^>]*>.*?</table>`)
+	sparklineRowPattern   = regexp.MustCompile(`(?s)<tr>.*?</tr>`)
+	sparklineCellPattern  = regexp.MustCompile(`(?s)<(t[hd])([^>]*)>(.*?)</t[hd]>`)
+)
+
+// sparklineMarker marks a table column, in its header, or a single cell, as
+// a series of comma-separated numbers to draw as a sparkline.
+const sparklineMarker = "{sparkline}"
+
+// processSparklines replaces the cells of markdown tables marked with
+// {sparkline} with inline SVG sparklines of their numbers. The marker can
+// follow a header, for the whole column, or start a cell. The SVG is built
+// here, so sparklines need no JavaScript and work in static exports. Cells
+// that aren't a list of numbers are left as they are.
+func processSparklines(htmlStr string) string {
+	if !strings.Contains(htmlStr, sparklineMarker) {
+		return htmlStr
+	}
+	return sparklineTablePattern.ReplaceAllStringFunc(htmlStr, func(table string) string {
+		if !strings.Contains(table, sparklineMarker) {
+			return table
+		}
+		columns := make(map[int]bool)
+		return sparklineRowPattern.ReplaceAllStringFunc(table, func(row string) string {
+			col := -1
+			return sparklineCellPattern.ReplaceAllStringFunc(row, func(cell string) string {
+				col++
+				m := sparklineCellPattern.FindStringSubmatch(cell)
+				tag, attrs, content := m[1], m[2], m[3]
+				if tag == "th" {
+					if strings.Contains(content, sparklineMarker) {
+						columns[col] = true
+						content = strings.TrimSpace(strings.Replace(content, sparklineMarker, "", 1))
+					}
+					return fmt.Sprintf("<th%s>%s</th>", attrs, content)
+				}
+				series, marked := strings.CutPrefix(strings.TrimSpace(content), sparklineMarker)
+				if !marked && !columns[col] {
+					return cell
+				}
+				values, ok := runtime.ParseSeries(html.UnescapeString(htmlTagPattern.ReplaceAllString(series, "")))
+				if !ok {
+					return fmt.Sprintf("<td%s>%s</td>", attrs, strings.TrimSpace(series))
+				}
+				return fmt.Sprintf("<td%s>%s</td>", attrs, runtime.SparklineSVG(values))
+			})
+		})
+	})
+}
+
 // parseCodeBlock parses a fenced code block and extracts livemdtools metadata.
 // Code block info string format: "go server readonly id=counter"
 func parseCodeBlock(fenced *ast.FencedCodeBlock, source []byte, lineOffset int) (*CodeBlock, error) {
@@ -947,6 +1003,11 @@ func parseCodeBlock(fenced *ast.FencedCodeBlock, source []byte, lineOffset int)
 		blockType = "lvt"
 	}
 
+	// embed-lvt renders an lvt block defined on another page
+	if language == "embed-lvt" {
+		blockType = "embed"
+	}
+
 	for _, part := range remaining {
 		if strings.Contains(part, "=") {
 			// Metadata: key=value
@@ -983,13 +1044,19 @@ func parseCodeBlock(fenced *ast.FencedCodeBlock, source []byte, lineOffset int)
 		buf.Write(line.Value(source))
 	}
 
+	// Empty blocks (e.g. embed-lvt) have no content lines; fall back to the info string
+	start := fenced.Info.Segment.Start
+	if lines.Len() > 0 {
+		start = lines.At(0).Start
+	}
+
 	return &CodeBlock{
 		Type:     blockType,
 		Language: language,
 		Flags:    flags,
 		Metadata: metadata,
 		Content:  buf.String(),
-		Line:     lineOffset + fenced.Lines().At(0).Start,
+		Line:     lineOffset + start,
 	}, nil
 }
 
@@ -1000,78 +1067,172 @@ func (p *Page) ParseFiles(files ...string) error {
 	return fmt.Errorf("not implemented")
 }
 
-// partialRegex matches {{partial "filename"}} or {{partial "filename.md"}}
-var partialRegex = regexp.MustCompile(`\{\{\s*partial\s+"([^"]+)"\s*\}\}`)
-
-// ProcessPartials recursively processes {{partial "file.md"}} directives in content.
-// baseDir is the directory to resolve relative paths from.
-// seen tracks already-included files to prevent circular dependencies.
+// partialRegex matches {{partial "file.md"}} and {{< include "file.md" >}}
+// directives. The path is in the first group for partial, the second for
+// include.
+var partialRegex = regexp.MustCompile(`\{\{\s*partial\s+"([^"]+)"\s*\}\}|\{\{<\s*include\s+"([^"]+)"\s*>\}\}`)
+
+// codeFenceRegex matches the opening or closing line of a fenced code block.
+// Directives inside code blocks are left as-is so docs can show the syntax.
+var codeFenceRegex = regexp.MustCompile("^ {0,3}(```|~~~)")
+
+// maxPartialDepth limits how deeply partials may nest.
+const maxPartialDepth = 10
+
+// siteConfigFiles are the config file names that mark a site root.
+var siteConfigFiles = []string{"tinkerdown.yaml", "lmt.yaml", "livemdtools.yaml"}
+
+// ProcessPartials inlines {{partial "file.md"}} and {{< include "file.md" >}}
+// directives in content. Paths resolve relative to baseDir, the including
+// file's directory, and must stay within the site root once symlinks are
+// followed. Partials may include other partials, and the same file may be
+// included more than once; cycles and nesting deeper than maxPartialDepth are
+// errors. seen holds the absolute paths of files that may not be included,
+// such as the page being processed, and may be nil. Frontmatter in partials
+// is dropped.
 func ProcessPartials(content []byte, baseDir string, seen map[string]bool) ([]byte, error) {
-	if seen == nil {
-		seen = make(map[string]bool)
+	dir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve partial directory '%s': %w", baseDir, err)
 	}
+	root := findSiteRoot(dir)
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return processPartials(content, dir, root, nil, seen)
+}
 
-	// Find all partial directives
-	matches := partialRegex.FindAllSubmatchIndex(content, -1)
-	if len(matches) == 0 {
+// processPartials expands the directives in content from a file in dir.
+// stack holds the chain of partials being included, outermost first.
+func processPartials(content []byte, dir, root string, stack []string, seen map[string]bool) ([]byte, error) {
+	if !partialRegex.Match(content) {
 		return content, nil
 	}
 
-	// Process in reverse order to maintain correct positions
-	result := make([]byte, len(content))
-	copy(result, content)
+	var out bytes.Buffer
+	inFence := false
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if codeFenceRegex.Match(line) {
+			inFence = !inFence
+		}
+		if inFence || !partialRegex.Match(line) {
+			out.Write(line)
+			continue
+		}
 
-	for i := len(matches) - 1; i >= 0; i-- {
-		match := matches[i]
-		fullMatchStart := match[0]
-		fullMatchEnd := match[1]
-		filenameStart := match[2]
-		filenameEnd := match[3]
+		var expandErr error
+		expanded := partialRegex.ReplaceAllFunc(line, func(match []byte) []byte {
+			if expandErr != nil {
+				return match
+			}
+			groups := partialRegex.FindSubmatch(match)
+			target := string(groups[1])
+			if target == "" {
+				target = string(groups[2])
+			}
+			body, err := loadPartial(target, dir, root, stack, seen)
+			if err != nil {
+				expandErr = err
+				return match
+			}
+			return bytes.TrimRight(body, "\n")
+		})
+		if expandErr != nil {
+			return nil, expandErr
+		}
+		out.Write(expanded)
+	}
 
-		filename := string(content[filenameStart:filenameEnd])
+	return out.Bytes(), nil
+}
 
-		// Resolve path relative to baseDir
-		partialPath := filepath.Join(baseDir, filename)
-		absPath, err := filepath.Abs(partialPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve partial path '%s': %w", filename, err)
-		}
+// loadPartial reads and recursively expands a single partial.
+func loadPartial(target, dir, root string, stack []string, seen map[string]bool) ([]byte, error) {
+	where := ""
+	if len(stack) > 0 {
+		where = " in " + relToRoot(root, stack[len(stack)-1])
+	}
+	if filepath.IsAbs(target) {
+		return nil, fmt.Errorf("include %q%s: path must be relative", target, where)
+	}
 
-		// Check for circular dependency
-		if seen[absPath] {
-			return nil, fmt.Errorf("circular dependency detected: %s", absPath)
-		}
-		seen[absPath] = true
+	absPath := filepath.Join(dir, filepath.FromSlash(target))
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	if !withinRoot(root, absPath) {
+		return nil, fmt.Errorf("include %q%s: path escapes the site root", target, where)
+	}
 
-		// Read the partial file
-		partialContent, err := os.ReadFile(absPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read partial '%s': %w", filename, err)
+	if seen[absPath] || slices.Contains(stack, absPath) {
+		chain := make([]string, 0, len(stack)+1)
+		for _, s := range stack {
+			chain = append(chain, relToRoot(root, s))
 		}
+		chain = append(chain, relToRoot(root, absPath))
+		return nil, fmt.Errorf("include cycle detected: %s", strings.Join(chain, " -> "))
+	}
+	if len(stack) >= maxPartialDepth {
+		return nil, fmt.Errorf("include %q%s: includes nested more than %d levels deep", target, where, maxPartialDepth)
+	}
 
-		// Strip frontmatter from partial (partials don't contribute frontmatter)
-		_, partialBody, err := extractFrontmatter(partialContent)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse partial '%s': %w", filename, err)
-		}
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("include %q%s: %w", target, where, err)
+	}
 
-		// Recursively process partials in the included content
-		partialDir := filepath.Dir(absPath)
-		processedPartial, err := ProcessPartials(partialBody, partialDir, seen)
-		if err != nil {
-			return nil, fmt.Errorf("error processing partial '%s': %w", filename, err)
-		}
+	// Partials don't contribute frontmatter
+	_, body, err := extractFrontmatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("include %q%s: %w", target, where, err)
+	}
 
-		// Replace the directive with the partial content
-		result = append(result[:fullMatchStart], append(processedPartial, result[fullMatchEnd:]...)...)
+	return processPartials(body, filepath.Dir(absPath), root, append(stack[:len(stack):len(stack)], absPath), seen)
+}
+
+// withinRoot reports whether path is root or a descendant of it.
+func withinRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// relToRoot formats path relative to the site root for error messages.
+func relToRoot(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return filepath.ToSlash(rel)
 	}
+	return path
+}
 
-	return result, nil
+// findSiteRoot walks up from dir looking for a site config file.
+// Falls back to dir itself when none is found.
+func findSiteRoot(dir string) string {
+	for d := dir; ; {
+		for _, name := range siteConfigFiles {
+			if _, err := os.Stat(filepath.Join(d, name)); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
 }
 
-// ParseMarkdownWithPartials parses markdown with partial file support.
+// ParseMarkdownWithPartials parses markdown with partial file support,
+// rendering it with the default MarkdownOptions.
 // baseDir is used to resolve relative paths in {{partial "file.md"}} directives.
 func ParseMarkdownWithPartials(content []byte, baseDir string) (*Frontmatter, []*CodeBlock, string, error) {
+	return parseMarkdownWithPartials(content, baseDir, DefaultMarkdownOptions())
+}
+
+// parseMarkdownWithPartials parses markdown with partials and opts.
+func parseMarkdownWithPartials(content []byte, baseDir string, opts MarkdownOptions) (*Frontmatter, []*CodeBlock, string, error) {
 	// First, extract frontmatter before processing partials
 	frontmatter, remaining, err := extractFrontmatter(content)
 	if err != nil {
@@ -1087,16 +1248,11 @@ func ParseMarkdownWithPartials(content []byte, baseDir string) (*Frontmatter, []
 	// Now parse the processed content (without frontmatter since we already extracted it)
 	// We need to reconstruct the content for ParseMarkdown or parse directly here
 
-	// Parse markdown with goldmark
-	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-	)
+	// Parse markdown with goldmark (GFM extensions per MarkdownOptions)
+	md := newMarkdown(opts)
 
 	reader := text.NewReader(processed)
-	doc := md.Parser().Parse(reader)
+	doc := md.Parser().Parse(reader, parser.WithContext(newParserContext()))
 
 	// Extract and collect livemdtools code blocks
 	var codeBlocks []*CodeBlock
@@ -1154,6 +1310,15 @@ func ParseMarkdownWithPartials(content []byte, baseDir string) (*Frontmatter, []
 		frontmatter.HasCharts = true
 	}
 
+	// Process heatmap headings (## Title {heatmap} followed by a table of dates)
+	htmlStr = processHeatmaps(htmlStr, frontmatter.Heatmaps)
+
+	// Draw {sparkline} table cells as inline SVG
+	htmlStr = processSparklines(htmlStr)
+
+	// Add permalink anchors to h2/h3 headings
+	htmlStr = addHeadingAnchors(htmlStr)
+
 	// Parse schedule tokens and imperatives from markdown content
 	schedules, scheduleWarnings := parseScheduleTokens(processed)
 	if len(schedules) > 0 {
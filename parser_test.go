@@ -1,8 +1,15 @@
 package tinkerdown
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/slug"
+	"github.com/livetemplate/tinkerdown/internal/source"
 )
 
 func TestParseFrontmatter(t *testing.T) {
@@ -1073,6 +1080,82 @@ title: "Chart Test"
 	}
 }
 
+func TestParseMarkdownWithSparklines(t *testing.T) {
+	content := []byte(`---
+title: "Sparklines"
+---
+
+| Host | Load {sparkline} | Note |
+|------|-----------------:|------|
+| web  | 1, 3, 2          | {sparkline} 4,4,8 |
+| db   | n/a              | quiet |
+`)
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		`<th style="text-align:right">Load</th>`,
+		`<td style="text-align:right"><svg class="tinkerdown-sparkline"`,
+		`<path d="M0,19L50,1L100,10"`,
+		`<path d="M0,19L50,19L100,1"`,
+		`<td style="text-align:right">n/a</td>`,
+		`<td>quiet</td>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %q in HTML, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "{sparkline}") {
+		t.Error("sparkline markers should be removed from HTML")
+	}
+}
+
+func TestParseMarkdownWithHeatmap(t *testing.T) {
+	content := []byte(`---
+title: "Habits"
+heatmaps:
+  reading:
+    colors: ["#fff", "#00f"]
+---
+
+## Reading {heatmap}
+
+| Date       | Count |
+|------------|-------|
+| 2024-03-01 | 2     |
+| 2024-03-04 | 1     |
+
+## Not a calendar {heatmap}
+
+| Name  | Count |
+|-------|-------|
+| Alice | 2     |
+`)
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		`<h2 id="reading">Reading<a class="heading-anchor"`,
+		`<div class="tinkerdown-heatmap-container"><svg class="tinkerdown-heatmap"`,
+		`fill="#00f"><title>2024-03-04: 1</title>`,
+		`<summary>View data</summary>`,
+		`<h2 id="not-a-calendar">Not a calendar<a class="heading-anchor"`,
+		`<td>Alice</td>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %q in HTML, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "{heatmap}") {
+		t.Error("heatmap annotations should be removed from HTML")
+	}
+	if strings.Count(html, "<svg") != 1 {
+		t.Error("only the table of dates should be drawn")
+	}
+}
+
 func TestProcessChartsWithOptions(t *testing.T) {
 	tableHTML := "<h2 id=\"sales-chartbar\">Sales {chart:bar}</h2>\n<table>\n<thead>\n<tr>\n<th>X</th>\n<th>Y</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>A</td>\n<td>1</td>\n</tr>\n</tbody>\n</table>\n"
 
@@ -1089,8 +1172,8 @@ func TestProcessChartsWithOptions(t *testing.T) {
 	if !hasCharts {
 		t.Fatal("Expected chart to be found")
 	}
-	if !strings.Contains(result, `data-chart-options`) {
-		t.Error("Expected data-chart-options attribute")
+	if !strings.Contains(result, `data-chart-config`) {
+		t.Error("Expected data-chart-config attribute")
 	}
 	if !strings.Contains(result, `#ff0000`) {
 		t.Error("Expected custom color in options")
@@ -1098,7 +1181,7 @@ func TestProcessChartsWithOptions(t *testing.T) {
 	if !strings.Contains(result, `stacked`) {
 		t.Error("Expected stacked option")
 	}
-	if !strings.Contains(result, `horizontal`) {
+	if !strings.Contains(result, `&#34;indexAxis&#34;:&#34;y&#34;`) {
 		t.Error("Expected horizontal option")
 	}
 }
@@ -1134,8 +1217,8 @@ charts:
 	if _, ok := fm.Charts["sales-by-region"]; !ok {
 		t.Error("Expected sales-by-region in Charts map")
 	}
-	if !strings.Contains(html, "data-chart-options") {
-		t.Error("Expected data-chart-options in HTML")
+	if !strings.Contains(html, "data-chart-config") {
+		t.Error("Expected data-chart-config in HTML")
 	}
 	if !strings.Contains(html, "#ff6384") {
 		t.Error("Expected custom color in chart options")
@@ -1143,3 +1226,145 @@ charts:
 }
 
 func boolPtr(b bool) *bool { return &b }
+
+func TestParseMarkdownExtensionsConfigurable(t *testing.T) {
+	content := []byte("Old ~~text~~ at https://example.com[^1]\n\n[^1]: Note.\n")
+
+	// Defaults enable all GFM extensions
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	for _, want := range []string{"<del>text</del>", `<a href="https://example.com">`, `class="footnotes"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("default HTML missing %q:\n%s", want, html)
+		}
+	}
+
+	// Disabling extensions falls back to plain markdown rendering
+	_, _, html, err = parseMarkdown(content, MarkdownOptions{})
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	for _, unwanted := range []string{"<del>", `<a href="https://example.com">`, `class="footnotes"`} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("HTML with extensions disabled should not contain %q:\n%s", unwanted, html)
+		}
+	}
+}
+
+func TestParseMarkdownGFMWithLvtBlocks(t *testing.T) {
+	content := []byte("# Demo\n\nSome ~~old~~ text[^1].\n\n```lvt\n<p>{{.Title}}</p>\n```\n\n[^1]: Footnote.\n")
+
+	_, blocks, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Type != "lvt" {
+		t.Fatalf("expected 1 lvt block, got %v", blocks)
+	}
+	if !strings.Contains(html, "<del>old</del>") || !strings.Contains(html, `class="footnotes"`) {
+		t.Errorf("GFM output missing alongside lvt block:\n%s", html)
+	}
+}
+
+func TestParseMarkdownHeadingAnchors(t *testing.T) {
+	content := []byte("# Guide\n\n## Setup\n\nFirst.\n\n## Setup\n\nSecond.\n\n### Hello World (v2)!\n\n## Tasks {#todos}\n\n## Setup\n\nThird.\n")
+
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	tests := []struct {
+		tag string
+		id  string
+	}{
+		{"h1", "guide"},
+		{"h2", "setup"},
+		{"h2", "setup-1"},
+		{"h3", slug.Heading("Hello World (v2)!")},
+		{"h2", "todos"},
+		{"h2", "setup-2"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(html, fmt.Sprintf(`<%s id="%s">`, tt.tag, tt.id)) {
+			t.Errorf("HTML missing <%s id=%q>:\n%s", tt.tag, tt.id, html)
+		}
+	}
+
+	// h2/h3 headings get a permalink; h1 does not
+	if !strings.Contains(html, `<a class="heading-anchor" href="#setup-1"`) {
+		t.Errorf("HTML missing permalink for #setup-1:\n%s", html)
+	}
+	if !strings.Contains(html, `<a class="heading-anchor" href="#hello-world-v2"`) {
+		t.Errorf("HTML missing permalink for h3:\n%s", html)
+	}
+	if strings.Contains(html, `href="#guide"`) {
+		t.Errorf("h1 should not get a permalink:\n%s", html)
+	}
+}
+
+func TestHeadingAnchorsMatchMarkdownSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.md")
+	content := "## Setup\n\n- first\n\n## Setup\n\n- second\n\n## Setup\n\n- third\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, html, err := ParseMarkdown([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	// Every rendered heading ID must resolve to the same section in a markdown source
+	want := map[string]string{"setup": "first", "setup-1": "second", "setup-2": "third"}
+	for id, text := range want {
+		if !strings.Contains(html, fmt.Sprintf(`id="%s"`, id)) {
+			t.Fatalf("HTML missing id %q:\n%s", id, html)
+		}
+		src, err := source.NewMarkdownSource("s", path, id, dir, path, true)
+		if err != nil {
+			t.Fatalf("NewMarkdownSource(%q) error = %v", id, err)
+		}
+		rows, err := src.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch(%q) error = %v", id, err)
+		}
+		if len(rows) != 1 || rows[0]["text"] != text {
+			t.Errorf("anchor %q rows = %v, want single item %q", id, rows, text)
+		}
+	}
+}
+
+func TestParseMarkdownContainers(t *testing.T) {
+	content := []byte("::::note\nOuter\n\n:::details Show <answer>\nInner **bold**\n:::\n\nStill outer\n::::\n\n:::unknown\ntext\n:::\n")
+
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	want := "<div class=\"admonition admonition-note\">\n<p class=\"admonition-title\">Note</p>\n<p>Outer</p>\n" +
+		"<details class=\"tinkerdown-details\">\n<summary>Show &lt;answer&gt;</summary>\n<p>Inner <strong>bold</strong></p>\n</details>\n" +
+		"<p>Still outer</p>\n</div>\n"
+	if !strings.HasPrefix(html, want) {
+		t.Errorf("nested containers HTML =\n%s\nwant prefix\n%s", html, want)
+	}
+
+	// Unknown container kinds are left as plain text
+	if !strings.Contains(html, "<p>:::unknown\ntext\n:::</p>") {
+		t.Errorf("unknown container should render as a paragraph:\n%s", html)
+	}
+}
+
+func TestParseMarkdownStatsContainer(t *testing.T) {
+	_, _, html, err := ParseMarkdown([]byte(":::stats\nRevenue\n:::\n"))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if want := "<div class=\"tinkerdown-stats\">\n<p>Revenue</p>\n</div>\n"; !strings.HasPrefix(html, want) {
+		t.Errorf("stats container HTML =\n%s\nwant prefix\n%s", html, want)
+	}
+}
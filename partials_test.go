@@ -0,0 +1,217 @@
+package tinkerdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livetemplate/tinkerdown/internal/testutil"
+)
+
+// writeSiteFiles writes files (relative path → content) under a new site root
+// containing a tinkerdown.yaml, and returns the root.
+func writeSiteFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	files["tinkerdown.yaml"] = "title: Test\n"
+	return testutil.WriteFiles(t, files)
+}
+
+func TestParseFileInclude(t *testing.T) {
+	root := writeSiteFiles(t, map[string]string{
+		"index.md":             "# Home\n\n{{< include \"_partials/warning.md\" >}}\n\nAfter.\n",
+		"_partials/warning.md": "---\ntitle: ignored\n---\n> **Warning:** shared snippet\n",
+	})
+
+	page, err := ParseFile(filepath.Join(root, "index.md"))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if page.Title != "" && page.Title != "Home" {
+		t.Errorf("included frontmatter leaked into page title: %q", page.Title)
+	}
+	if !strings.Contains(page.StaticHTML, "<strong>Warning:</strong> shared snippet") {
+		t.Errorf("included content missing:\n%s", page.StaticHTML)
+	}
+	if strings.Contains(page.StaticHTML, "include") {
+		t.Errorf("include directive not resolved:\n%s", page.StaticHTML)
+	}
+}
+
+func TestParseFileNestedInclude(t *testing.T) {
+	root := writeSiteFiles(t, map[string]string{
+		"docs/page.md":               "---\nsources:\n  items:\n    type: json\n    file: items.json\n---\n# Page\n\n{{< include \"../_partials/outer.md\" >}}\n",
+		"_partials/outer.md":         "Outer text.\n\n{{< include \"inner/counter.md\" >}}\n",
+		"_partials/inner/counter.md": "Inner text.\n\n```lvt\n<ul lvt-source=\"items\"></ul>\n```\n",
+	})
+
+	page, err := ParseFile(filepath.Join(root, "docs", "page.md"))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	for _, want := range []string{"Outer text.", "Inner text."} {
+		if !strings.Contains(page.StaticHTML, want) {
+			t.Errorf("HTML missing %q:\n%s", want, page.StaticHTML)
+		}
+	}
+	if len(page.InteractiveBlocks) != 1 {
+		t.Errorf("expected lvt block from nested include, got %d interactive blocks", len(page.InteractiveBlocks))
+	}
+}
+
+func TestParseFileIncludeSameFileTwice(t *testing.T) {
+	root := writeSiteFiles(t, map[string]string{
+		"index.md": "{{< include \"_note.md\" >}}\n\n{{< include \"_note.md\" >}}\n",
+		"_note.md": "Repeated note.\n",
+	})
+
+	page, err := ParseFile(filepath.Join(root, "index.md"))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if n := strings.Count(page.StaticHTML, "Repeated note."); n != 2 {
+		t.Errorf("expected note twice, got %d:\n%s", n, page.StaticHTML)
+	}
+}
+
+func TestParseFileIncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		page    string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"index.md": "{{< include \"_a.md\" >}}\n",
+				"_a.md":    "{{< include \"_b.md\" >}}\n",
+				"_b.md":    "{{< include \"_a.md\" >}}\n",
+			},
+			page:    "index.md",
+			wantErr: "include cycle detected: _a.md -> _b.md -> _a.md",
+		},
+		{
+			name: "escape site root",
+			files: map[string]string{
+				"docs/index.md": "{{< include \"../../secret.md\" >}}\n",
+			},
+			page:    "docs/index.md",
+			wantErr: "path escapes the site root",
+		},
+		{
+			name: "absolute path",
+			files: map[string]string{
+				"index.md": "{{partial \"/etc/hosts\"}}\n",
+			},
+			page:    "index.md",
+			wantErr: "path must be relative",
+		},
+		{
+			name: "partial escapes site root",
+			files: map[string]string{
+				"docs/index.md": "{{partial \"../../secret.md\"}}\n",
+			},
+			page:    "docs/index.md",
+			wantErr: "path escapes the site root",
+		},
+		{
+			name: "mixed syntax cycle",
+			files: map[string]string{
+				"index.md": "{{partial \"_a.md\"}}\n",
+				"_a.md":    "{{< include \"_b.md\" >}}\n",
+				"_b.md":    "{{partial \"_a.md\"}}\n",
+			},
+			page:    "index.md",
+			wantErr: "include cycle detected: _a.md -> _b.md -> _a.md",
+		},
+		{
+			name: "missing nested file",
+			files: map[string]string{
+				"index.md": "{{< include \"_a.md\" >}}\n",
+				"_a.md":    "{{partial \"_missing.md\"}}\n",
+			},
+			page:    "index.md",
+			wantErr: `include "_missing.md" in _a.md`,
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"index.md": "{{< include \"_missing.md\" >}}\n",
+			},
+			page:    "index.md",
+			wantErr: `include "_missing.md"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeSiteFiles(t, tt.files)
+			_, err := ParseFile(filepath.Join(root, filepath.FromSlash(tt.page)))
+			if err == nil {
+				t.Fatal("ParseFile() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFilePartialSameFileTwice(t *testing.T) {
+	root := writeSiteFiles(t, map[string]string{
+		"index.md": "{{partial \"_note.md\"}}\n\n{{< include \"_note.md\" >}}\n",
+		"_note.md": "Repeated note.\n",
+	})
+
+	page, err := ParseFile(filepath.Join(root, "index.md"))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if n := strings.Count(page.StaticHTML, "Repeated note."); n != 2 {
+		t.Errorf("expected note twice, got %d:\n%s", n, page.StaticHTML)
+	}
+}
+
+func TestProcessPartialsDepthLimit(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i <= maxPartialDepth+1; i++ {
+		files[filepath.ToSlash(filepath.Join("_chain", string(rune('a'+i))+".md"))] =
+			"{{< include \"" + string(rune('a'+i+1)) + ".md\" >}}\n"
+	}
+	root := writeSiteFiles(t, files)
+
+	content, _ := os.ReadFile(filepath.Join(root, "_chain", "a.md"))
+	_, err := ProcessPartials(content, filepath.Join(root, "_chain"), nil)
+	if err == nil || !strings.Contains(err.Error(), "nested more than") {
+		t.Errorf("ProcessPartials() error = %v, want depth limit error", err)
+	}
+}
+
+func TestProcessPartialsSeen(t *testing.T) {
+	root := writeSiteFiles(t, map[string]string{"_note.md": "Note.\n"})
+	note, err := filepath.EvalSymlinks(filepath.Join(root, "_note.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("{{< include \"_note.md\" >}}\n")
+	if _, err := ProcessPartials(content, root, map[string]bool{note: true}); err == nil || !strings.Contains(err.Error(), "include cycle detected") {
+		t.Errorf("ProcessPartials() error = %v, want cycle error for a seen file", err)
+	}
+	got, err := ProcessPartials(content, root, nil)
+	if err != nil || string(got) != "Note.\n" {
+		t.Errorf("ProcessPartials() = %q, %v, want the note", got, err)
+	}
+}
+
+func TestProcessPartialsSkipsCodeFences(t *testing.T) {
+	content := []byte("```markdown\n{{< include \"_partials/x.md\" >}}\n{{partial \"_partials/x.md\"}}\n```\n")
+	got, err := ProcessPartials(content, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("ProcessPartials() error = %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("directive inside code fence was expanded:\n%s", got)
+	}
+}
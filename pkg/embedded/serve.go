@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/livetemplate/tinkerdown/internal/config"
+	"github.com/livetemplate/tinkerdown/internal/logging"
 	"github.com/livetemplate/tinkerdown/internal/server"
 )
 
@@ -100,9 +101,11 @@ func ServeWithOptions(opts Options) error {
 	// Print discovered pages
 	if !opts.Quiet {
 		fmt.Printf("\nPages discovered:\n")
-		for _, route := range srv.Routes() {
-			fmt.Printf("  %-30s %s\n", route.Pattern, route.FilePath)
+		baseURL := "http://" + opts.Addr
+		if strings.HasPrefix(opts.Addr, ":") {
+			baseURL = "http://localhost" + opts.Addr
 		}
+		fmt.Print(server.FormatRouteTable(srv.Routes(), baseURL, logging.IsTerminal(os.Stdout)))
 		fmt.Println()
 	}
 
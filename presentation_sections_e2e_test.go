@@ -0,0 +1,119 @@
+//go:build !ci
+
+package tinkerdown_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/livetemplate/tinkerdown/internal/server"
+)
+
+// servePresentationPage serves a site with index.md holding content, plus
+// any extra files.
+func servePresentationPage(t *testing.T, content string, files ...string) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(files); i += 2 {
+		if err := os.WriteFile(filepath.Join(dir, files[i]), []byte(files[i+1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := server.New(dir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Failed to discover pages: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestPresentationModeWithoutSections reproduces entering presentation mode
+// on a page with no H2 headings, which used to hide the whole page. It should
+// stay out of presentation mode and say there are no slides instead.
+func TestPresentationModeWithoutSections(t *testing.T) {
+	ts := servePresentationPage(t, "# Notes\n\nJust a paragraph, no sections.\n")
+
+	chromeCtx, cleanup := SetupDockerChrome(t, 60*time.Second)
+	defer cleanup()
+	ctx := chromeCtx.Context
+
+	var active, btnActive, contentVisible bool
+	var notice string
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(ConvertURLForDockerChrome(ts.URL)),
+		chromedp.WaitVisible(`#presentation-toggle`, chromedp.ByQuery),
+		chromedp.Evaluate(`document.getElementById('presentation-toggle').click()`, nil),
+		chromedp.Evaluate(`window.tinkerdownPresentationMode.isActive() || document.body.classList.contains('presentation-mode')`, &active),
+		chromedp.Evaluate(`document.getElementById('presentation-toggle').classList.contains('active')`, &btnActive),
+		chromedp.Evaluate(`document.querySelector('.content-wrapper p').offsetParent !== null`, &contentVisible),
+		chromedp.Evaluate(`(document.getElementById('presentation-notice') || {}).textContent || ''`, &notice),
+	)
+	if err != nil {
+		t.Fatalf("Failed to toggle presentation mode: %v", err)
+	}
+
+	if active || btnActive {
+		t.Error("presentation mode should not activate on a page without H2 sections")
+	}
+	if !contentVisible {
+		t.Error("page content should stay visible")
+	}
+	if notice == "" {
+		t.Error("expected a 'No slides on this page' notice")
+	}
+}
+
+// TestPresentationModeWrappedSections checks slides whose content isn't a
+// plain run of siblings after the H2: a live block under the first H2, and a
+// second H2 inside a :::note container. The sibling walk used to put the
+// whole container on the first slide and leave the second slide blank.
+func TestPresentationModeWrappedSections(t *testing.T) {
+	page := "---\nsources:\n  items:\n    type: json\n    file: items.json\n---\n# Demo\n\n" +
+		"## Live\n\n```lvt\n<ul lvt-source=\"items\">{{range .Data}}<li class=\"item\">{{.name}}</li>{{end}}</ul>\n```\n\n" +
+		":::note\nBefore the second slide\n\n## Wrapped\n\nInside the note\n:::\n"
+	ts := servePresentationPage(t, page, "items.json", `[{"name": "alpha"}]`)
+
+	chromeCtx, cleanup := SetupDockerChrome(t, 60*time.Second)
+	defer cleanup()
+	ctx := chromeCtx.Context
+
+	visible := func(selector string) string {
+		return `(function() { const el = document.querySelector('` + selector + `'); return !!el && el.offsetParent !== null; })()`
+	}
+	var liveBlock, wrappedOnFirst, wrappedHeading, wrappedText, liveOnSecond bool
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(ConvertURLForDockerChrome(ts.URL)),
+		chromedp.WaitVisible(`li.item`, chromedp.ByQuery),
+		chromedp.Evaluate(`document.getElementById('presentation-toggle').click()`, nil),
+		chromedp.Evaluate(visible(`li.item`), &liveBlock),
+		chromedp.Evaluate(visible(`#wrapped`), &wrappedOnFirst),
+		chromedp.KeyEvent("ArrowRight"),
+		chromedp.Evaluate(visible(`#wrapped`), &wrappedHeading),
+		chromedp.Evaluate(`Array.from(document.querySelectorAll('.admonition p')).some(p => p.textContent === 'Inside the note' && p.offsetParent !== null)`, &wrappedText),
+		chromedp.Evaluate(visible(`li.item`), &liveOnSecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to step through slides: %v", err)
+	}
+
+	if !liveBlock {
+		t.Error("first slide should show its live block")
+	}
+	if wrappedOnFirst {
+		t.Error("first slide should not show the second slide's heading")
+	}
+	if !wrappedHeading || !wrappedText {
+		t.Error("second slide should show the heading and text inside the container")
+	}
+	if liveOnSecond {
+		t.Error("second slide should not show the first slide's live block")
+	}
+}
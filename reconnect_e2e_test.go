@@ -0,0 +1,77 @@
+//go:build !ci
+
+package tinkerdown_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/livetemplate/tinkerdown/internal/server"
+)
+
+// TestReconnectBanner tests that the client shows a "Reconnecting…" banner
+// while the server is unreachable and reloads the page once it is back.
+func TestReconnectBanner(t *testing.T) {
+	tmpDir := t.TempDir()
+	page := "---\nsources:\n  items:\n    type: json\n    file: items.json\n---\n# Reconnect\n\n```lvt\n<div lvt-source=\"items\">{{len .Data}} items</div>\n```\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "index.md"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "items.json"), []byte(`[{"name":"a"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := server.New(tmpDir)
+	if err := srv.Discover(); err != nil {
+		t.Fatalf("Failed to discover pages: %v", err)
+	}
+
+	// Refuse WebSocket upgrades while "down" to simulate a restarting server
+	var down atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() && r.URL.Path == "/ws" {
+			http.Error(w, "restarting", http.StatusServiceUnavailable)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	chromeCtx, cleanup := SetupDockerChrome(t, 60*time.Second)
+	defer cleanup()
+	ctx := chromeCtx.Context
+
+	connected := `window.tinkerdownClient && window.tinkerdownClient.isConnected === true`
+	banner := `document.getElementById("tinkerdown-reconnect-banner")`
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(ConvertURLForDockerChrome(ts.URL)),
+		waitForDOM(connected, 10*time.Second),
+		// Marks this page load; it is gone after a reload
+		chromedp.Evaluate(`window.beforeRestart = true`, nil),
+	)
+	if err != nil {
+		t.Fatalf("Failed to load page: %v", err)
+	}
+
+	down.Store(true)
+	srv.CloseConnections()
+	err = chromedp.Run(ctx, waitForDOM(banner+` !== null`, 5*time.Second))
+	if err != nil {
+		t.Fatalf("Reconnect banner not shown: %v", err)
+	}
+
+	down.Store(false)
+	err = chromedp.Run(ctx,
+		waitForDOM(`window.beforeRestart === undefined && `+connected, 20*time.Second),
+		waitForDOM(banner+` === null`, time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Page did not reload after the server came back: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package tinkerdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/slug"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// MarkdownOptions controls which optional GitHub-flavored markdown extensions
+// are enabled when rendering pages. Tables are always enabled because
+// markdown sources and charts depend on them, as are :::containers.
+type MarkdownOptions struct {
+	Footnotes     bool // [^1] references and footnote definitions
+	Strikethrough bool // ~~text~~
+	Autolinks     bool // bare URLs become links
+	TaskLists     bool // - [ ] / - [x] checkboxes in prose
+	LineNumbers   bool // Line numbers on every code block (per-block: linenos)
+}
+
+// DefaultMarkdownOptions returns options with all extensions enabled.
+func DefaultMarkdownOptions() MarkdownOptions {
+	return MarkdownOptions{
+		Footnotes:     true,
+		Strikethrough: true,
+		Autolinks:     true,
+		TaskLists:     true,
+	}
+}
+
+// ParseFile parses a markdown file into a Page, rendered with these options.
+// Pages it embeds through embed-lvt are rendered with them too.
+func (opts MarkdownOptions) ParseFile(path string) (*Page, error) {
+	return parseFile(path, nil, opts)
+}
+
+// ParseString parses markdown content, such as playground input, into a
+// Page rendered with these options.
+func (opts MarkdownOptions) ParseString(content string) (*Page, error) {
+	return parseString(content, opts)
+}
+
+// newMarkdown creates a goldmark instance configured with opts.
+func newMarkdown(opts MarkdownOptions) goldmark.Markdown {
+	extensions := []goldmark.Extender{
+		extension.Table,
+		&containerExtension{},
+		&codeLinesExtension{lineNumbers: opts.LineNumbers},
+		&speakerNotesExtension{},
+	}
+	if opts.Strikethrough {
+		extensions = append(extensions, extension.Strikethrough)
+	}
+	if opts.Autolinks {
+		extensions = append(extensions, extension.Linkify)
+	}
+	if opts.TaskLists {
+		extensions = append(extensions, extension.TaskList)
+	}
+	if opts.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+	)
+}
+
+// newParserContext creates a parse context whose heading IDs follow the same
+// rules as markdown source anchors, so #links in the page and lvt-source
+// anchors resolve to the same heading.
+func newParserContext() parser.Context {
+	return parser.NewContext(parser.WithIDs(newHeadingIDs()))
+}
+
+// headingExplicitAnchorPattern matches a trailing {#anchor} on heading text.
+var headingExplicitAnchorPattern = regexp.MustCompile(`\{#([^}]+)\}\s*$`)
+
+// headingIDs generates heading IDs with slug.Heading. Duplicate slugs are
+// disambiguated GitHub-style: the second "Setup" heading is setup-1, the
+// third setup-2. Headings with an explicit {#anchor} use that anchor and
+// don't count towards duplicates.
+type headingIDs struct {
+	counts map[string]int
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{counts: make(map[string]int)}
+}
+
+// Generate implements parser.IDs.
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	text := strings.TrimSpace(string(value))
+	if m := headingExplicitAnchorPattern.FindStringSubmatch(text); m != nil {
+		return []byte(m[1])
+	}
+
+	base := slug.Heading(text)
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	if n := h.counts[base]; n > 0 {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	h.counts[base]++
+	return []byte(id)
+}
+
+// Put implements parser.IDs.
+func (h *headingIDs) Put(value []byte) {
+	h.counts[string(value)]++
+}
+
+// headingAnchorPattern matches plain h2/h3 headings rendered with an id.
+// Headings carrying extra attributes (e.g. tab bars) are left alone.
+var headingAnchorPattern = regexp.MustCompile(`<h([23]) id="([^"]+)">(.*?)</h[23]>`)
+
+// addHeadingAnchors appends a permalink to h2 and h3 headings so readers can
+// copy a link to the section. The "¶" glyph is drawn by CSS so the heading's
+// textContent (used for sidebar and TOC titles) is unchanged.
+func addHeadingAnchors(htmlStr string) string {
+	return headingAnchorPattern.ReplaceAllString(htmlStr,
+		`<h$1 id="$2">$3<a class="heading-anchor" href="#$2" aria-label="Link to this section"></a></h$1>`)
+}
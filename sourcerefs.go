@@ -0,0 +1,262 @@
+package tinkerdown
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/livetemplate/tinkerdown/internal/source"
+	"github.com/livetemplate/tinkerdown/internal/suggest"
+)
+
+// SourceRefIssue is a problem found by CheckSourceRefs.
+type SourceRefIssue struct {
+	Line    int    // Line number in the file (1-indexed)
+	Message string // What's wrong
+	Hint    string // Helpful suggestion (optional)
+	Warning bool   // Warnings don't fail validation
+}
+
+// String formats the issue as "line N: message (hint)".
+func (i SourceRefIssue) String() string {
+	s := fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	if i.Hint != "" {
+		s += " (" + i.Hint + ")"
+	}
+	return s
+}
+
+var (
+	// lvtSourceTagRegex matches an opening tag with an lvt-source attribute.
+	lvtSourceTagRegex = regexp.MustCompile(`<[a-zA-Z][^>]*\blvt-source="([^"]*)"[^>]*>`)
+	// lvtFieldAttrRegex matches the attributes that name fields of the bound source.
+	lvtFieldAttrRegex = regexp.MustCompile(`\blvt-(columns|field|value|label)="([^"]*)"`)
+)
+
+// CheckSourceRefs cross-checks the lvt-source references in a page against the
+// sources it can use: those declared in its frontmatter plus shared (e.g. from
+// tinkerdown.yaml). It reports references to undefined sources and field names
+// in lvt-columns, lvt-field, lvt-value and lvt-label that the source doesn't
+// have, plus frontmatter sources the page never mentions (as warnings).
+//
+// Fields are only checked for sources whose shape is known without running
+// them: SQLite tables, and CSV and JSON files. siteDir resolves their paths.
+// Code blocks other than lvt blocks are skipped, so examples in docs don't count.
+func CheckSourceRefs(content []byte, shared map[string]SourceConfig, siteDir string) []SourceRefIssue {
+	fm, body, err := extractFrontmatter(content)
+	if err != nil {
+		return nil
+	}
+	lineOffset := strings.Count(string(content[:len(content)-len(body)]), "\n")
+	scannable := blankNonLvtFences(string(body))
+
+	declared := make(map[string]SourceConfig)
+	for name, cfg := range shared {
+		declared[name] = cfg
+	}
+	for name, cfg := range fm.Sources {
+		declared[name] = cfg
+	}
+
+	var issues []SourceRefIssue
+	used := make(map[string]bool)
+	fieldCache := make(map[string][]string)
+
+	for _, loc := range lvtSourceTagRegex.FindAllStringSubmatchIndex(scannable, -1) {
+		tag := scannable[loc[0]:loc[1]]
+		line := lineOffset + strings.Count(scannable[:loc[0]], "\n") + 1
+		names := SourceNames(scannable[loc[2]:loc[3]])
+		for _, name := range names {
+			used[name] = true
+			if _, ok := declared[name]; !ok {
+				issues = append(issues, SourceRefIssue{
+					Line:    line,
+					Message: fmt.Sprintf("lvt-source %q is not defined in sources", name),
+					Hint:    suggestName(name, declared),
+				})
+			}
+		}
+
+		// Field attributes only apply to blocks bound to a single source
+		if len(names) != 1 {
+			continue
+		}
+		name := names[0]
+		cfg, ok := declared[name]
+		if !ok {
+			continue
+		}
+
+		fields, cached := fieldCache[name]
+		if !cached {
+			fields = knownSourceFields(cfg, siteDir)
+			fieldCache[name] = fields
+		}
+		if fields == nil {
+			continue
+		}
+		for _, attr := range lvtFieldAttrRegex.FindAllStringSubmatch(tag, -1) {
+			for _, field := range fieldRefs(attr[1], attr[2]) {
+				if !containsFlag(fields, field) {
+					issues = append(issues, SourceRefIssue{
+						Line:    line,
+						Message: fmt.Sprintf("lvt-%s references unknown field %q of source %q", attr[1], field, name),
+						Hint:    "known fields: " + strings.Join(fields, ", "),
+					})
+				}
+			}
+		}
+	}
+
+	// Frontmatter sources can also be used by computed sources, auto-bound
+	// tables (by heading) and expressions, so any mention of the name counts.
+	for _, cfg := range fm.Sources {
+		if cfg.Type == "computed" && cfg.From != "" {
+			used[cfg.From] = true
+		}
+	}
+	matches, _ := matchTablesToSources(detectTableSections(body), fm.Sources)
+	for _, m := range matches {
+		used[m.sourceName] = true
+	}
+	var unused []string
+	for name := range fm.Sources {
+		if !used[name] && !mentionsName(scannable, name) {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	for _, name := range unused {
+		issues = append(issues, SourceRefIssue{
+			Line:    1,
+			Message: fmt.Sprintf("source %q is declared but never used", name),
+			Warning: true,
+		})
+	}
+
+	return issues
+}
+
+// blankNonLvtFences replaces the lines of fenced code blocks that aren't lvt
+// blocks with empty lines, keeping line numbers intact.
+func blankNonLvtFences(body string) string {
+	lines := strings.Split(body, "\n")
+	inFence, keep := false, false
+	for i, line := range lines {
+		if codeFenceRegex.MatchString(line) {
+			if !inFence {
+				info := strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "`~"))
+				keep = len(info) > 0 && (info[0] == "lvt" || containsFlag(info[1:], "lvt"))
+			}
+			inFence = !inFence
+			lines[i] = ""
+			continue
+		}
+		if inFence && !keep {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fieldRefs returns the field names an lvt-* attribute value refers to.
+// lvt-columns lists "field" or "field:Label" entries; the others name one field.
+func fieldRefs(attr, value string) []string {
+	if attr != "columns" {
+		if v := strings.TrimSpace(value); v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+	var fields []string
+	for _, col := range strings.Split(value, ",") {
+		field, _, _ := strings.Cut(col, ":")
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// knownSourceFields returns the sorted field names of a source, or nil when
+// they can't be determined statically.
+func knownSourceFields(cfg SourceConfig, siteDir string) []string {
+	seen := make(map[string]bool)
+	switch cfg.Type {
+	case "sqlite":
+		schema := source.QuerySQLiteSchema(cfg.DB, cfg.Table, siteDir)
+		if len(schema) == 0 {
+			return nil
+		}
+		// QuerySQLiteSchema omits the internal columns, but templates may show them
+		seen["id"], seen["created_at"] = true, true
+		for _, col := range schema {
+			seen[col.Name] = true
+		}
+	case "csv", "json":
+		var rows []map[string]interface{}
+		var err error
+		if cfg.Type == "csv" {
+			var src *source.CSVFileSource
+			if src, err = source.NewCSVFileSource("", cfg.File, siteDir, cfg.Options); err == nil {
+				rows, err = src.Fetch(context.Background())
+			}
+		} else {
+			var src *source.JSONFileSource
+			if src, err = source.NewJSONFileSource("", cfg.File, siteDir); err == nil {
+				rows, err = src.Fetch(context.Background())
+			}
+		}
+		if err != nil || len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			for field := range row {
+				seen[field] = true
+			}
+		}
+	case "upload":
+		for _, field := range source.UploadFields {
+			seen[field] = true
+		}
+	default:
+		return nil
+	}
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// suggestName returns a "did you mean" hint for an undefined source name.
+func suggestName(name string, declared map[string]SourceConfig) string {
+	names := make([]string, 0, len(declared))
+	for n := range declared {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if n := ClosestName(name, names); n != "" {
+		return fmt.Sprintf("did you mean %q?", n)
+	}
+	if len(names) == 0 {
+		return "add it under sources: in the frontmatter or tinkerdown.yaml"
+	}
+	return "available sources: " + strings.Join(names, ", ")
+}
+
+// mentionsName reports whether name appears as a whole word in text, ignoring case.
+func mentionsName(text, name string) bool {
+	re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`)
+	return err == nil && re.MatchString(text)
+}
+
+// ClosestName returns the candidate most similar to name, or "" if none is
+// close enough to be a likely typo (at most two edits, ignoring case).
+func ClosestName(name string, candidates []string) string {
+	return suggest.Closest(name, candidates)
+}
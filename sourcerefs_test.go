@@ -0,0 +1,86 @@
+package tinkerdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSourceRefs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "people.csv"), []byte("name,email\nAda,ada@example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := `---
+title: Team
+sources:
+  people:
+    type: csv
+    file: people.csv
+  unused:
+    type: json
+    file: missing.json
+---
+# Team
+
+` + "```lvt" + `
+<table lvt-source="people" lvt-columns="name:Name,phone">
+</table>
+<ul lvt-source="peple" lvt-field="name"></ul>
+<ul lvt-source="shared"></ul>
+` + "```" + `
+
+` + "```html" + `
+<div lvt-source="example"></div>
+` + "```" + `
+`
+	shared := map[string]SourceConfig{"shared": {Type: "rest"}}
+	issues := CheckSourceRefs([]byte(content), shared, dir)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`line 14: lvt-columns references unknown field "phone" of source "people" (known fields: email, name)`,
+		`line 16: lvt-source "peple" is not defined in sources (did you mean "people"?)`,
+		`line 1: source "unused" is declared but never used`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckSourceRefs() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(issues) == 3 && (issues[0].Warning || issues[1].Warning || !issues[2].Warning) {
+		t.Error("only the unused source should be a warning")
+	}
+}
+
+func TestCheckSourceRefsValid(t *testing.T) {
+	content := "---\nsources:\n  tasks:\n    type: markdown\n    anchor: \"#tasks\"\n---\n" +
+		"## Tasks\n\n- [ ] Write docs\n\n```lvt\n<ul lvt-source=\"tasks\" lvt-field=\"text\"></ul>\n```\n"
+	if issues := CheckSourceRefs([]byte(content), nil, t.TempDir()); len(issues) != 0 {
+		t.Errorf("CheckSourceRefs() = %v, want no issues", issues)
+	}
+}
+
+func TestCheckSourceRefsMissingDatabase(t *testing.T) {
+	dir := t.TempDir()
+	content := "---\nsources:\n  tasks:\n    type: sqlite\n    db: ./tasks.db\n    table: tasks\n---\n" +
+		"```lvt\n<table lvt-source=\"tasks\" lvt-columns=\"title\"></table>\n```\n"
+	if issues := CheckSourceRefs([]byte(content), nil, dir); len(issues) != 0 {
+		t.Errorf("CheckSourceRefs() = %v, want no issues", issues)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks.db")); !os.IsNotExist(err) {
+		t.Error("CheckSourceRefs() should not create a missing database")
+	}
+}
+
+func TestCheckSourceRefsUpload(t *testing.T) {
+	content := "---\nsources:\n  files:\n    type: upload\n---\n" +
+		"```lvt\n<table lvt-source=\"files\" lvt-columns=\"name,size,owner\"></table>\n```\n"
+	issues := CheckSourceRefs([]byte(content), nil, t.TempDir())
+	if len(issues) != 1 || !strings.Contains(issues[0].String(), `unknown field "owner" of source "files" (known fields: id, modified, name, size, type)`) {
+		t.Errorf("CheckSourceRefs() = %v, want only the unknown owner field", issues)
+	}
+}
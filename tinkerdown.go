@@ -11,6 +11,11 @@ type Page struct {
 	Type              string // tutorial, guide, reference, playground
 	SourceFile        string // Absolute path to source .md file (for error messages)
 	Sidebar           *bool  // nil = use default, true/false = explicit override
+	NavOrder          *int   // Sidebar position from frontmatter (nil = unordered)
+	NavHidden         bool   // Omit from site navigation
+	NavTitle          string // Navigation label override
+	ReadingTime       *bool  // nil = use site default, false = hide reading time
+	NoIndex           bool   // Emit <meta name="robots" content="noindex">
 	Config            PageConfig
 	StaticHTML        string
 	ServerBlocks      map[string]*ServerBlock
@@ -31,6 +36,17 @@ type Page struct {
 
 	// HasCharts indicates the page has chart annotations requiring Chart.js
 	HasCharts bool
+
+	// Tags from frontmatter list the page on the site's tag index pages
+	Tags []string
+
+	// embedStack lists the page files being parsed through embed-lvt blocks
+	// (ending with this page), used to detect embed cycles.
+	embedStack []string
+
+	// markdown is the renderer options the page was parsed with, which the
+	// pages it embeds are parsed with too.
+	markdown MarkdownOptions
 }
 
 // PageConfig contains configuration for a page.
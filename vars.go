@@ -0,0 +1,47 @@
+package tinkerdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// siteVarPattern matches {{ site.name }} placeholders in rendered HTML.
+var siteVarPattern = regexp.MustCompile(`\{\{\s*site\.([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// codeSpanPattern matches <pre> and <code> elements, whose text is shown
+// literally.
+var codeSpanPattern = regexp.MustCompile(`(?s)<pre\b.*?</pre>|<code\b.*?</code>`)
+
+// SubstituteSiteVars replaces {{ site.name }} placeholders with values from
+// the site config's vars map. It runs on the rendered StaticHTML, after
+// markdown parsing, so lvt blocks (which are not part of StaticHTML) keep
+// their own template syntax untouched. Placeholders inside <pre> and <code>
+// are left alone, so docs can show the syntax itself. Unknown variables
+// render as a visible {{?name}} marker so typos are easy to spot.
+func SubstituteSiteVars(htmlStr string, vars map[string]string) string {
+	if !siteVarPattern.MatchString(htmlStr) {
+		return htmlStr
+	}
+	var b strings.Builder
+	last := 0
+	for _, span := range codeSpanPattern.FindAllStringIndex(htmlStr, -1) {
+		b.WriteString(substituteSiteVars(htmlStr[last:span[0]], vars))
+		b.WriteString(htmlStr[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(substituteSiteVars(htmlStr[last:], vars))
+	return b.String()
+}
+
+// substituteSiteVars replaces the placeholders in a piece of HTML without
+// code.
+func substituteSiteVars(htmlStr string, vars map[string]string) string {
+	return siteVarPattern.ReplaceAllStringFunc(htmlStr, func(match string) string {
+		name := siteVarPattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return html.EscapeString(value)
+		}
+		return "{{?" + name + "}}"
+	})
+}
@@ -0,0 +1,67 @@
+package tinkerdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubstituteSiteVars(t *testing.T) {
+	vars := map[string]string{
+		"version":       "1.4.2",
+		"support_email": "help@example.com",
+		"tagline":       "Fast & <simple>",
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"defined", "<p>Version {{ site.version }}</p>", "<p>Version 1.4.2</p>"},
+		{"no spaces", "<p>{{site.support_email}}</p>", "<p>help@example.com</p>"},
+		{"inline code", "<p>Write <code>{{ site.version }}</code> for {{ site.version }}</p>", "<p>Write <code>{{ site.version }}</code> for 1.4.2</p>"},
+		{"escaped value", "<p>{{ site.tagline }}</p>", "<p>Fast &amp; &lt;simple&gt;</p>"},
+		{"undefined", "<p>{{ site.verison }}</p>", "<p>{{?verison}}</p>"},
+		{"not a site var", "<p>{{ .Title }}</p>", "<p>{{ .Title }}</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SubstituteSiteVars(tt.input, vars); got != tt.want {
+				t.Errorf("SubstituteSiteVars(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubstituteSiteVarsLeavesLvtBlocks(t *testing.T) {
+	content := []byte("Version {{ site.version }}\n\n```lvt\n<p>{{ site.version }} {{.Count}}</p>\n```\n")
+	_, blocks, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	got := SubstituteSiteVars(html, map[string]string{"version": "2.0"})
+	if want := "<p>Version 2.0</p>"; !strings.HasPrefix(got, want) {
+		t.Errorf("StaticHTML = %q, want prefix %q", got, want)
+	}
+	if len(blocks) != 1 || blocks[0].Content != "<p>{{ site.version }} {{.Count}}</p>\n" {
+		t.Errorf("lvt block content should be untouched, got %+v", blocks)
+	}
+}
+
+func TestSubstituteSiteVarsLeavesFencedCode(t *testing.T) {
+	content := []byte("Version {{ site.version }}\n\n```markdown\nInstall v{{ site.version }}.\n```\n")
+	_, _, html, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	got := SubstituteSiteVars(html, map[string]string{"version": "2.0"})
+	if want := "<p>Version 2.0</p>"; !strings.HasPrefix(got, want) {
+		t.Errorf("StaticHTML = %q, want prefix %q", got, want)
+	}
+	if !strings.Contains(got, "Install v{{ site.version }}.") || strings.Contains(got, "Install v2.0") {
+		t.Errorf("fenced example should stay literal, got %q", got)
+	}
+}